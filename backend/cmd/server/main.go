@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"strings"
 	"time"
 
@@ -39,11 +40,23 @@ func main() {
 		log.Printf("warn: legacy host-worker cleanup failed: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == rotateSecretsKeyCommand {
+		if err := rotateSecretsKey(context.Background(), cfg, repository.NewGormSecretRepository(gormDB), repository.NewGormSettingsRepository(gormDB)); err != nil {
+			log.Fatalf("secrets key rotation failed: %v", err)
+		}
+		return
+	}
+
 	userRepo := repository.NewGormUserRepository(gormDB)
 	projectRepo := repository.NewGormProjectRepository(gormDB)
 	jobRepo := repository.NewGormJobRepository(gormDB)
 	settingsRepo := repository.NewGormSettingsRepository(gormDB)
 	auditRepo := repository.NewGormAuditLogRepository(gormDB)
+	workbenchSnapshotRepo := repository.NewGormWorkbenchSnapshotRepository(gormDB)
+	workbenchRevisionRepo := repository.NewGormWorkbenchRevisionRepository(gormDB)
+	deploymentRepo := repository.NewGormDeploymentRepository(gormDB)
+	secretRepo := repository.NewGormSecretRepository(gormDB)
+	tunnelRepo := repository.NewGormTunnelRepository(gormDB)
 
 	rbacService := service.NewRBACService(cfg, userRepo)
 	if err := rbacService.SeedSuperUser(); err != nil {
@@ -53,7 +66,22 @@ func main() {
 	authService := service.NewAuthService(cfg, userRepo)
 	jobRunner := jobs.NewRunner(jobRepo)
 	jobService := service.NewJobService(jobRepo, jobRunner)
+	secretsService := service.NewSecretsService(cfg, secretRepo)
 	settingsService := service.NewSettingsService(cfg, settingsRepo)
+	settingsService.SetSecretsVault(secretsService)
+	if resealed, err := settingsService.ReencryptSettingsPayload(context.Background()); err != nil {
+		log.Printf("warn: settings payload re-encryption failed: %v", err)
+	} else if resealed {
+		log.Printf("re-encrypted settings payload with the current settings key")
+	}
+	if moved, err := settingsService.MigratePanelSecrets(context.Background()); err != nil {
+		log.Fatalf("panel secrets migration failed: %v", err)
+	} else if moved > 0 {
+		log.Printf("moved %d panel credentials into the secrets vault", moved)
+	}
+	if !secretsService.Enabled() {
+		log.Printf("warn: SECRETS_KEY is not set; secrets vault disabled and panel credentials stay in settings")
+	}
 	userService := service.NewUserService(userRepo)
 	githubService := service.NewGitHubService(cfg, settingsService)
 	cloudflareService := service.NewCloudflareService(settingsService)
@@ -83,6 +111,7 @@ func main() {
 	bridgeClient := infraclient.New(bridgeQueue, cfg.InfraPollInterval, cfg.InfraResultTimeout)
 	dockerRunner := service.NewDockerRunner(bridgeClient)
 	bridgeWorker := infraworker.New(bridgeQueue, cfg.InfraPollInterval, cfg.TemplatesDir, log.Default())
+	bridgeWorker.SetVolumeBackupDir(cfg.VolumeBackupDir)
 	if err := bridgeWorker.ValidateTaskCoverage([]contract.TaskType{
 		contract.TaskTypeRestartTunnel,
 		contract.TaskTypeDockerStopContainer,
@@ -97,30 +126,71 @@ func main() {
 		contract.TaskTypeHostListenTCPPorts,
 		contract.TaskTypeDockerPublishedPorts,
 		contract.TaskTypeComposeUpStack,
+		contract.TaskTypeComposeDownStack,
 		contract.TaskTypeHostRuntimeStats,
 		contract.TaskTypeHostRuntimeStream,
 		contract.TaskTypeProjectFileWriteAtomic,
 		contract.TaskTypeProjectFileCopy,
 		contract.TaskTypeProjectFileRemove,
+		contract.TaskTypeDockerImageDigests,
+		contract.TaskTypeDockerImagePull,
+		contract.TaskTypeDockerImageTag,
+		contract.TaskTypeDockerVolumeBackup,
+		contract.TaskTypeDockerVolumeRestore,
+		contract.TaskTypeVolumeBackupPrune,
+		contract.TaskTypeDockerDBDump,
+		contract.TaskTypeDockerDBRestore,
+		contract.TaskTypeDockerVolumeCopy,
 	}); err != nil {
 		log.Fatalf("infra worker readiness check failed: %v", err)
 	}
 	go bridgeWorker.Run(context.Background())
 
 	hostService := service.NewHostService(cfg.TemplatesDir, projectRepo, bridgeClient)
+	hostService.SetVolumeBackups(cfg.VolumeBackupDir, cfg.VolumeBackupKeep)
+	tunnelService := service.NewTunnelService(tunnelRepo, projectRepo, settingsService)
+	tunnelService.SetSecretsVault(secretsService)
+	if sealed, err := tunnelService.SealStoredTokens(context.Background()); err != nil {
+		log.Fatalf("tunnel token migration failed: %v", err)
+	} else if sealed > 0 {
+		log.Printf("sealed %d plaintext tunnel tokens", sealed)
+	}
 	projectService := service.NewProjectService(cfg, projectRepo, jobService, settingsService, bridgeClient)
-	workbenchService := service.NewWorkbenchServiceWithStorage(cfg.TemplatesDir, projectRepo, settingsRepo, cfg.SessionSecret)
+	projectService.SetTunnels(tunnelService)
+	service.SetWorkbenchEdgeNetwork(cfg.CloudflaredNetwork)
+	workbenchService := service.NewWorkbenchServiceWithStorage(cfg.TemplatesDir, projectRepo, settingsRepo, service.SettingsPayloadKey(cfg))
 	workbenchService.SetPortProbeClient(bridgeClient)
 	workbenchService.SetRuntimeMetaClient(bridgeClient)
 	workbenchService.SetFileMutationClient(bridgeClient)
+	workbenchService.SetSnapshotRepository(workbenchSnapshotRepo)
+	workbenchService.SetRevisionRepository(workbenchRevisionRepo)
+	if migrated, err := workbenchService.MigrateLegacySnapshots(context.Background()); err != nil {
+		log.Fatalf("workbench snapshot migration failed: %v", err)
+	} else if migrated > 0 {
+		log.Printf("migrated %d workbench snapshots out of settings storage", migrated)
+	}
+	if _, err := workbenchService.LoadOptionalServiceCatalog(cfg.WorkbenchCatalogDir); err != nil {
+		log.Fatalf("workbench optional-service catalog load failed: %v", err)
+	}
+	go workbenchService.RunDriftScanner(context.Background(), cfg.WorkbenchDriftScan)
 	projectArchiveService := service.NewProjectArchiveService(cfg, projectRepo, settingsService, jobService, hostService)
+	projectArchiveService.SetDeploymentRepository(deploymentRepo)
+	projectArchiveService.SetTunnels(tunnelService)
 	projectRuntimeService := service.NewProjectRuntimeService(cfg.TemplatesDir, projectRepo, hostService)
 	projectEnvService := service.NewProjectEnvService(cfg.TemplatesDir, projectRepo)
 	projectEnvService.SetRuntimeMetaClient(bridgeClient)
 	projectEnvService.SetFileMutationClient(bridgeClient)
 	healthService := service.NewHealthService(hostService, settingsService, cfg)
+	healthService.SetTunnels(tunnelService)
+	reconcileService := service.NewCloudflareReconcileService(settingsService, projectRepo, projectArchiveService, jobService)
+	reconcileService.SetTunnels(tunnelService)
 
 	workflows := service.NewProjectWorkflows(cfg, projectRepo, settingsService, hostService, auditService, workbenchService, dockerRunner, bridgeClient)
+	workflows.SetDeploymentRepository(deploymentRepo)
+	workflows.SetFileMutationClient(bridgeClient)
+	workflows.SetSecretsVault(secretsService)
+	workflows.SetCloudflareReconcile(reconcileService)
+	workflows.SetTunnels(tunnelService)
 	workflows.Register(jobRunner)
 	dockerWorkflows := service.NewDockerWorkflows(dockerRunner)
 	dockerWorkflows.Register(jobRunner)
@@ -144,7 +214,10 @@ func main() {
 		Audit:           controller.NewAuditController(auditService),
 		Users:           controller.NewUsersController(userService),
 		GitHub:          controller.NewGitHubController(githubService),
-		Cloudflare:      controller.NewCloudflareController(cloudflareService),
+		Cloudflare:      controller.NewCloudflareController(cloudflareService, reconcileService, auditService),
+		Secrets:         controller.NewSecretsController(secretsService, auditService),
+		DNS:             controller.NewDNSController(service.NewDNSService(settingsService), auditService),
+		Tunnels:         controller.NewTunnelsController(tunnelService, auditService),
 		AllowedOrigins:  cfg.AllowedOrigins,
 		AuthMiddleware:  middleware.AuthRequired(sessionManager),
 		UsersMiddleware: middleware.RequireAdmin(sessionManager),
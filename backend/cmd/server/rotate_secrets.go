@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go-notes/internal/config"
+	"go-notes/internal/repository"
+	"go-notes/internal/service"
+)
+
+const rotateSecretsKeyCommand = "rotate-secrets-key"
+
+// rotateSecretsKey re-encrypts every vault version and the settings blob with
+// SECRETS_KEY. Data sealed before the rotation is opened with
+// SECRETS_KEY_PREVIOUS, or SESSION_SECRET for a blob that predates the vault.
+// Run it with both keys set, then drop SECRETS_KEY_PREVIOUS.
+func rotateSecretsKey(ctx context.Context, cfg config.Config, secretRepo repository.SecretRepository, settingsRepo repository.SettingsRepository) error {
+	if cfg.SecretsKey == "" {
+		return fmt.Errorf("SECRETS_KEY is required")
+	}
+	secrets := service.NewSecretsService(cfg, secretRepo)
+	report, err := secrets.Rotate(ctx)
+	if err != nil {
+		return fmt.Errorf("re-encrypt vault secrets: %w", err)
+	}
+	log.Printf("vault key=%s re-encrypted=%d already-current=%d", report.KeyID, report.ReEncrypted, report.Current)
+
+	settings := service.NewSettingsService(cfg, settingsRepo)
+	settings.SetSecretsVault(secrets)
+	resealed, err := settings.ReencryptSettingsPayload(ctx)
+	if err != nil {
+		return fmt.Errorf("re-encrypt settings payload: %w", err)
+	}
+	moved, err := settings.MigratePanelSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("move panel credentials into vault: %w", err)
+	}
+	log.Printf("settings payload re-encrypted=%t panel credentials moved=%d", resealed, moved)
+	return nil
+}
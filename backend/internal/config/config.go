@@ -24,6 +24,8 @@ type Config struct {
 	DBConnMaxLifetime     time.Duration
 	AllowedOrigins        []string
 	SessionSecret         string
+	SecretsKey            string
+	SecretsKeyPrevious    string
 	SessionTTL            time.Duration
 	CookieDomain          string
 	AdminLogin            string
@@ -35,9 +37,14 @@ type Config struct {
 	GitHubTemplateRepo    string
 	GitHubRepoOwner       string
 	GitHubRepoPrivate     bool
+	GitHubWebhookSecret   string
 	SuperUserGitHubName   string
 	SuperUserGitHubID     int64
 	TemplatesDir          string
+	WorkbenchCatalogDir   string
+	WorkbenchDriftScan    time.Duration
+	VolumeBackupDir       string
+	VolumeBackupKeep      int
 	Domain                string
 	CloudflareAPIToken    string
 	CloudflareAccountID   string
@@ -45,6 +52,10 @@ type Config struct {
 	CloudflareTunnelID    string
 	CloudflaredConfig     string
 	CloudflaredTunnel     string
+	// CloudflaredMetrics is the metrics address the tunnel in CloudflaredConfig
+	// is restarted with; empty uses the infra worker default.
+	CloudflaredMetrics    string
+	CloudflaredNetwork    string
 	NetBirdMode           string
 	NetBirdAllowLocalhost bool
 	InfraQueueRoot        string
@@ -78,9 +89,13 @@ func Load() (Config, error) {
 	v.SetDefault("CORS_ALLOWED_ORIGINS", "http://localhost:4173,http://127.0.0.1:4173,http://localhost:5173,http://127.0.0.1:5173")
 	v.SetDefault("SESSION_TTL_HOURS", 12)
 	v.SetDefault("COOKIE_DOMAIN", "")
+	v.SetDefault("SECRETS_KEY", "")
+	v.SetDefault("SECRETS_KEY_PREVIOUS", "")
 	v.SetDefault("ADMIN_LOGIN", "")
 	v.SetDefault("ADMIN_PASSWORD", "")
 	v.SetDefault("TEMPLATES_DIR", "/templates")
+	v.SetDefault("WORKBENCH_CATALOG_DIR", "")
+	v.SetDefault("WORKBENCH_DRIFT_SCAN_MINUTES", 15)
 	v.SetDefault("SUPERUSER_GH_NAME", "")
 	v.SetDefault("SUPER_GH_ID", "")
 	v.SetDefault("GITHUB_REPO_PRIVATE", true)
@@ -91,8 +106,12 @@ func Load() (Config, error) {
 	v.SetDefault("CLOUDFLARE_TUNNEL_ID", "")
 	v.SetDefault("CLOUDFLARED_CONFIG", "~/.cloudflared/config.yml")
 	v.SetDefault("CLOUDFLARED_TUNNEL_NAME", "")
+	v.SetDefault("CLOUDFLARED_METRICS_ADDRESS", "")
+	v.SetDefault("CLOUDFLARED_NETWORK", "gungnr_edge")
 	v.SetDefault("NETBIRD_MODE", "legacy")
 	v.SetDefault("NETBIRD_ALLOW_LOCALHOST", false)
+	v.SetDefault("VOLUME_BACKUP_DIR", "/templates/.backups")
+	v.SetDefault("VOLUME_BACKUP_KEEP", 5)
 	v.SetDefault("INFRA_QUEUE_ROOT", "/templates/.infra")
 	v.SetDefault("INFRA_POLL_INTERVAL_MS", 500)
 	v.SetDefault("INFRA_RESULT_TIMEOUT_SEC", 120)
@@ -123,6 +142,8 @@ func Load() (Config, error) {
 		DBConnMaxLifetime:     time.Duration(v.GetInt("DB_CONN_MAX_LIFETIME_MIN")) * time.Minute,
 		AllowedOrigins:        parseCSV(v.GetString("CORS_ALLOWED_ORIGINS")),
 		SessionSecret:         v.GetString("SESSION_SECRET"),
+		SecretsKey:            strings.TrimSpace(v.GetString("SECRETS_KEY")),
+		SecretsKeyPrevious:    strings.TrimSpace(v.GetString("SECRETS_KEY_PREVIOUS")),
 		SessionTTL:            time.Duration(v.GetInt("SESSION_TTL_HOURS")) * time.Hour,
 		CookieDomain:          strings.TrimSpace(v.GetString("COOKIE_DOMAIN")),
 		AdminLogin:            v.GetString("ADMIN_LOGIN"),
@@ -134,9 +155,12 @@ func Load() (Config, error) {
 		GitHubTemplateRepo:    v.GetString("GITHUB_TEMPLATE_REPO"),
 		GitHubRepoOwner:       v.GetString("GITHUB_REPO_OWNER"),
 		GitHubRepoPrivate:     v.GetBool("GITHUB_REPO_PRIVATE"),
+		GitHubWebhookSecret:   strings.TrimSpace(v.GetString("GITHUB_WEBHOOK_SECRET")),
 		SuperUserGitHubName:   strings.TrimSpace(v.GetString("SUPERUSER_GH_NAME")),
 		SuperUserGitHubID:     parseInt64(v.GetString("SUPER_GH_ID")),
 		TemplatesDir:          v.GetString("TEMPLATES_DIR"),
+		WorkbenchCatalogDir:   strings.TrimSpace(v.GetString("WORKBENCH_CATALOG_DIR")),
+		WorkbenchDriftScan:    time.Duration(v.GetInt("WORKBENCH_DRIFT_SCAN_MINUTES")) * time.Minute,
 		Domain:                v.GetString("DOMAIN"),
 		CloudflareAPIToken:    v.GetString("CLOUDFLARE_API_TOKEN"),
 		CloudflareAccountID:   v.GetString("CLOUDFLARE_ACCOUNT_ID"),
@@ -144,8 +168,12 @@ func Load() (Config, error) {
 		CloudflareTunnelID:    v.GetString("CLOUDFLARE_TUNNEL_ID"),
 		CloudflaredConfig:     v.GetString("CLOUDFLARED_CONFIG"),
 		CloudflaredTunnel:     v.GetString("CLOUDFLARED_TUNNEL_NAME"),
+		CloudflaredMetrics:    strings.TrimSpace(v.GetString("CLOUDFLARED_METRICS_ADDRESS")),
+		CloudflaredNetwork:    strings.TrimSpace(v.GetString("CLOUDFLARED_NETWORK")),
 		NetBirdMode:           v.GetString("NETBIRD_MODE"),
 		NetBirdAllowLocalhost: v.GetBool("NETBIRD_ALLOW_LOCALHOST"),
+		VolumeBackupDir:       v.GetString("VOLUME_BACKUP_DIR"),
+		VolumeBackupKeep:      v.GetInt("VOLUME_BACKUP_KEEP"),
 		InfraQueueRoot:        v.GetString("INFRA_QUEUE_ROOT"),
 		InfraPollInterval:     time.Duration(v.GetInt("INFRA_POLL_INTERVAL_MS")) * time.Millisecond,
 		InfraResultTimeout:    time.Duration(v.GetInt("INFRA_RESULT_TIMEOUT_SEC")) * time.Second,
@@ -163,6 +191,10 @@ func Load() (Config, error) {
 	cfg.InfraIntentMaxAge = clampDuration(cfg.InfraIntentMaxAge, 24*time.Hour, 30*24*time.Hour, 7*24*time.Hour)
 	cfg.InfraResultMaxAge = clampDuration(cfg.InfraResultMaxAge, 24*time.Hour, 30*24*time.Hour, 7*24*time.Hour)
 	cfg.InfraClaimMaxAge = clampDuration(cfg.InfraClaimMaxAge, 5*time.Minute, 24*time.Hour, 60*time.Minute)
+	if cfg.VolumeBackupKeep < 1 {
+		cfg.VolumeBackupKeep = 5
+	}
+	cfg.WorkbenchDriftScan = clampDuration(cfg.WorkbenchDriftScan, time.Minute, 24*time.Hour, 15*time.Minute)
 
 	if cfg.DatabaseURL == "" {
 		return Config{}, fmt.Errorf("DATABASE_URL is required")
@@ -4,17 +4,20 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
 	"go-notes/internal/models"
 	"go-notes/internal/respond"
 	"go-notes/internal/service"
 )
 
 type CloudflareController struct {
-	service *service.CloudflareService
+	service   *service.CloudflareService
+	reconcile *service.CloudflareReconcileService
+	audit     *service.AuditService
 }
 
-func NewCloudflareController(service *service.CloudflareService) *CloudflareController {
-	return &CloudflareController{service: service}
+func NewCloudflareController(service *service.CloudflareService, reconcile *service.CloudflareReconcileService, audit *service.AuditService) *CloudflareController {
+	return &CloudflareController{service: service, reconcile: reconcile, audit: audit}
 }
 
 func (c *CloudflareController) Preflight(ctx *gin.Context) {
@@ -49,3 +52,77 @@ func (c *CloudflareController) Zones(ctx *gin.Context) {
 	}
 	respond.OK(ctx, gin.H{"zones": response})
 }
+
+func (c *CloudflareController) Reconcile(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	if c.reconcile == nil {
+		respond.Err(ctx, errs.New(errs.CodeCloudflareUnavailable, "reconcile service unavailable"), errs.CodeCloudflareUnavailable, "reconcile service unavailable")
+		return
+	}
+	report, err := c.reconcile.Report(ctx.Request.Context())
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeCloudflareReconcile, "failed to build reconcile report")
+		return
+	}
+	respond.OK(ctx, report)
+}
+
+func (c *CloudflareController) ReconcileCleanup(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	if c.reconcile == nil {
+		respond.Err(ctx, errs.New(errs.CodeCloudflareUnavailable, "reconcile service unavailable"), errs.CodeCloudflareUnavailable, "reconcile service unavailable")
+		return
+	}
+	var req models.CloudflareReconcileCleanupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeCloudflareReconcileInvalid, "invalid request body"), errs.CodeCloudflareReconcileInvalid, "invalid request body")
+		return
+	}
+	session, _ := middleware.SessionFromContext(ctx)
+	job, findings, err := c.reconcile.QueueCleanup(ctx.Request.Context(), req.FindingIDs, service.ProjectArchiveActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeCloudflareReconcile, "failed to queue reconcile cleanup")
+		return
+	}
+
+	kinds := map[string]int{}
+	for _, finding := range findings {
+		kinds[finding.Kind]++
+	}
+	c.logAudit(ctx, "cloudflare.reconcile.cleanup", "cloudflare", map[string]any{
+		"jobId":    job.ID,
+		"findings": len(findings),
+		"kinds":    kinds,
+	})
+	respond.Accepted(ctx, gin.H{"job": models.NewJobResponse(*job), "findings": findings})
+}
+
+func (c *CloudflareController) requireAdmin(ctx *gin.Context) bool {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeCloudflareAdminRequired, "admin role required"), errs.CodeCloudflareAdminRequired, "admin role required")
+		return false
+	}
+	return true
+}
+
+func (c *CloudflareController) logAudit(ctx *gin.Context, action, target string, metadata map[string]any) {
+	if c.audit == nil {
+		return
+	}
+	session, _ := middleware.SessionFromContext(ctx)
+	_ = c.audit.Log(ctx.Request.Context(), service.AuditEntry{
+		UserID:    session.UserID,
+		UserLogin: session.Login,
+		Action:    action,
+		Target:    target,
+		Metadata:  metadata,
+	})
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+// DNSController manages DNS records in the configured Cloudflare zones.
+type DNSController struct {
+	service *service.DNSService
+	audit   *service.AuditService
+}
+
+func NewDNSController(service *service.DNSService, audit *service.AuditService) *DNSController {
+	return &DNSController{service: service, audit: audit}
+}
+
+func (c *DNSController) List(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	zones, err := c.service.List(ctx.Request.Context(), ctx.Query("zone"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeDNSFailed, "failed to list DNS records")
+		return
+	}
+	respond.OK(ctx, gin.H{"zones": zones})
+}
+
+func (c *DNSController) Create(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	var req models.DNSRecordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeDNSInvalidBody, "invalid request body"), errs.CodeDNSInvalidBody, "invalid request body")
+		return
+	}
+
+	record, err := c.service.Create(ctx.Request.Context(), req.Zone, dnsRecordInput(req))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeDNSFailed, "failed to create DNS record")
+		return
+	}
+
+	c.logAudit(ctx, "dns.record.create", record.Record.Name, dnsRecordAuditMetadata(record))
+	respond.OK(ctx, gin.H{"record": record})
+}
+
+func (c *DNSController) Update(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	var req models.DNSRecordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeDNSInvalidBody, "invalid request body"), errs.CodeDNSInvalidBody, "invalid request body")
+		return
+	}
+
+	record, err := c.service.Update(ctx.Request.Context(), ctx.Param("zone"), ctx.Param("record"), dnsRecordInput(req))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeDNSFailed, "failed to update DNS record")
+		return
+	}
+
+	c.logAudit(ctx, "dns.record.update", record.Record.Name, dnsRecordAuditMetadata(record))
+	respond.OK(ctx, gin.H{"record": record})
+}
+
+func (c *DNSController) Delete(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	record, err := c.service.Delete(ctx.Request.Context(), ctx.Param("zone"), ctx.Param("record"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeDNSFailed, "failed to delete DNS record")
+		return
+	}
+
+	c.logAudit(ctx, "dns.record.delete", record.Record.Name, dnsRecordAuditMetadata(record))
+	respond.OK(ctx, gin.H{"record": record})
+}
+
+func (c *DNSController) requireAdmin(ctx *gin.Context) bool {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeDNSAdminRequired, "admin role required"), errs.CodeDNSAdminRequired, "admin role required")
+		return false
+	}
+	if c.service == nil {
+		respond.Err(ctx, errs.New(errs.CodeDNSUnavailable, "dns service unavailable"), errs.CodeDNSUnavailable, "dns service unavailable")
+		return false
+	}
+	return true
+}
+
+func (c *DNSController) logAudit(ctx *gin.Context, action, target string, metadata map[string]any) {
+	if c.audit == nil {
+		return
+	}
+	session, _ := middleware.SessionFromContext(ctx)
+	_ = c.audit.Log(ctx.Request.Context(), service.AuditEntry{
+		UserID:    session.UserID,
+		UserLogin: session.Login,
+		Action:    action,
+		Target:    target,
+		Metadata:  metadata,
+	})
+}
+
+func dnsRecordInput(req models.DNSRecordRequest) cloudflare.DNSRecordInput {
+	return cloudflare.DNSRecordInput{
+		Type:     req.Type,
+		Name:     req.Name,
+		Content:  req.Content,
+		TTL:      req.TTL,
+		Proxied:  req.Proxied,
+		Priority: req.Priority,
+		Note:     req.Note,
+	}
+}
+
+func dnsRecordAuditMetadata(record service.DNSZoneRecord) map[string]any {
+	return map[string]any{
+		"zone":     record.ZoneName,
+		"recordId": record.Record.ID,
+		"type":     record.Record.Type,
+		"content":  record.Record.Content,
+		"proxied":  record.Record.Proxied,
+	}
+}
@@ -249,3 +249,12 @@ func (c *HostController) RestartDockerProject(ctx *gin.Context) {
 	})
 	respond.Accepted(ctx, gin.H{"job": models.NewJobResponse(*job)})
 }
+
+func (c *HostController) QuickServiceImageUpdates(ctx *gin.Context) {
+	report, err := c.service.QuickServiceImageUpdates(ctx.Request.Context())
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeHostDockerFailed, "failed to check quick-service image updates")
+		return
+	}
+	respond.OK(ctx, gin.H{"report": report})
+}
@@ -253,3 +253,43 @@ func (s *hostControllerBridgeStub) HostRuntimeStream(_ context.Context, _ string
 func (s *hostControllerBridgeStub) ComposeUpStack(_ context.Context, _ string, _ contract.ComposeUpStackPayload) (contract.Result, error) {
 	return contract.Result{}, nil
 }
+
+func (s *hostControllerBridgeStub) ComposeDownStack(_ context.Context, _ string, _ contract.ComposeDownStackPayload) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerImageDigests(_ context.Context, _ string, _ []string) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerImagePull(_ context.Context, _ string, _ string) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerImageTag(_ context.Context, _ string, _ string, _ string) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerVolumeBackup(_ context.Context, _ string, _ contract.DockerVolumeBackupPayload) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerVolumeRestore(_ context.Context, _ string, _ contract.DockerVolumeRestorePayload) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) VolumeBackupPrune(_ context.Context, _ string, _ string, _ int) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerDBDump(_ context.Context, _ string, _ contract.DockerDBDumpPayload) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerDBRestore(_ context.Context, _ string, _ contract.DockerDBRestorePayload) (contract.Result, error) {
+	return contract.Result{}, nil
+}
+
+func (s *hostControllerBridgeStub) DockerVolumeCopy(_ context.Context, _ string, _ contract.DockerVolumeCopyPayload) (contract.Result, error) {
+	return contract.Result{}, nil
+}
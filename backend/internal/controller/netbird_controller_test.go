@@ -176,6 +176,8 @@ func (*failingProjectRepo) GetByName(context.Context, string) (*models.Project,
 
 func (*failingProjectRepo) Update(context.Context, *models.Project) error { return nil }
 
+func (*failingProjectRepo) Delete(context.Context, uint) error { return nil }
+
 func (f *fakeSettingsRepo) Get(context.Context) (*models.Settings, error) {
 	if f.settings == nil {
 		return nil, repository.ErrNotFound
@@ -385,10 +385,14 @@ func (c *ProjectsController) WriteEnv(ctx *gin.Context) {
 	}
 
 	c.logAudit(ctx, "project.env.write", project, map[string]any{
-		"project":    project,
-		"path":       result.Path,
-		"sizeBytes":  result.SizeBytes,
-		"backupPath": result.BackupPath,
+		"project":     project,
+		"path":        result.Path,
+		"sizeBytes":   result.SizeBytes,
+		"backupPath":  result.BackupPath,
+		"versionId":   result.VersionID,
+		"addedKeys":   result.Changes.Added,
+		"removedKeys": result.Changes.Removed,
+		"changedKeys": result.Changes.Changed,
 	})
 
 	respond.OK(ctx, gin.H{"env": result})
@@ -422,6 +426,9 @@ func (c *ProjectsController) CreateFromTemplate(ctx *gin.Context) {
 		"domain":    req.Domain,
 		"proxyPort": req.ProxyPort,
 		"dbPort":    req.DBPort,
+		"access":    req.Access != nil && req.Access.Enabled,
+		"ingress":   req.Ingress != nil,
+		"tunnel":    req.Tunnel,
 		"jobId":     job.ID,
 	})
 
@@ -450,6 +457,9 @@ func (c *ProjectsController) DeployExisting(ctx *gin.Context) {
 		"subdomain": req.Subdomain,
 		"domain":    req.Domain,
 		"port":      req.Port,
+		"access":    req.Access != nil && req.Access.Enabled,
+		"ingress":   req.Ingress != nil,
+		"tunnel":    req.Tunnel,
 		"jobId":     job.ID,
 	})
 
@@ -478,6 +488,8 @@ func (c *ProjectsController) ForwardLocal(ctx *gin.Context) {
 		"subdomain": req.Subdomain,
 		"domain":    req.Domain,
 		"port":      req.Port,
+		"ingress":   req.Ingress != nil,
+		"tunnel":    req.Tunnel,
 		"jobId":     job.ID,
 	})
 
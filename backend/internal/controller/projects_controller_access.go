@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+)
+
+func (c *ProjectsController) Access(ctx *gin.Context) {
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectAccessFailed, "project access service unavailable"), errs.CodeProjectAccessFailed, "project access service unavailable")
+		return
+	}
+
+	hostnames, err := c.archive.Access(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectAccessFailed, "failed to load project access")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"hostnames": hostnames})
+}
+
+func (c *ProjectsController) UpdateAccess(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectAccessFailed, "project access service unavailable"), errs.CodeProjectAccessFailed, "project access service unavailable")
+		return
+	}
+
+	var req models.ProjectAccessRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	hostnames, err := c.archive.UpdateAccess(ctx.Request.Context(), project, req.Hostname, cloudflare.AccessRules{
+		Emails:       req.Emails,
+		EmailDomains: req.EmailDomains,
+		GitHubOrgs:   req.GitHubOrgs,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectAccessFailed, "failed to update project access")
+		return
+	}
+
+	c.logAudit(ctx, "project.access.update", project, map[string]any{
+		"project":      project,
+		"hostname":     strings.ToLower(strings.TrimSpace(req.Hostname)),
+		"emails":       len(req.Emails),
+		"emailDomains": len(req.EmailDomains),
+		"githubOrgs":   len(req.GitHubOrgs),
+	})
+
+	respond.OK(ctx, gin.H{"hostnames": hostnames})
+}
+
+func (c *ProjectsController) RemoveAccess(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectAccessFailed, "project access service unavailable"), errs.CodeProjectAccessFailed, "project access service unavailable")
+		return
+	}
+
+	hostname := strings.ToLower(strings.TrimSpace(ctx.Query("hostname")))
+	hostnames, err := c.archive.RemoveAccess(ctx.Request.Context(), project, hostname)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectAccessFailed, "failed to remove project access")
+		return
+	}
+
+	c.logAudit(ctx, "project.access.remove", project, map[string]any{
+		"project":  project,
+		"hostname": hostname,
+	})
+
+	respond.OK(ctx, gin.H{"hostnames": hostnames})
+}
@@ -38,6 +38,7 @@ func (c *ProjectsController) ArchivePlan(ctx *gin.Context) {
 		"hostnames":      len(plan.Hostnames),
 		"ingressRules":   len(plan.Ingress),
 		"dnsRecords":     len(plan.DNSRecords),
+		"accessApps":     len(plan.AccessApps),
 		"warningCount":   len(plan.Warnings),
 		"defaultOptions": plan.Defaults,
 	})
@@ -83,10 +84,13 @@ func (c *ProjectsController) Archive(ctx *gin.Context) {
 		"removeVolumes":    options.RemoveVolumes,
 		"removeIngress":    options.RemoveIngress,
 		"removeDns":        options.RemoveDNS,
+		"removeAccess":     options.RemoveAccess,
+		"backupVolumes":    options.BackupVolumes,
 		"targets": map[string]any{
 			"containers": len(targets.Containers),
 			"hostnames":  len(targets.Hostnames),
 			"dnsRecords": len(targets.DNSRecords),
+			"accessApps": len(targets.AccessApps),
 		},
 		"warningCount": len(plan.Warnings),
 	})
@@ -96,3 +100,219 @@ func (c *ProjectsController) Archive(ctx *gin.Context) {
 		"plan": plan,
 	})
 }
+
+func (c *ProjectsController) ChangeHostnames(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectHostnameFailed, "project hostname service unavailable"), errs.CodeProjectHostnameFailed, "project hostname service unavailable")
+		return
+	}
+
+	var req models.ProjectHostnamesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	job, plan, err := c.archive.QueueHostnameChange(ctx.Request.Context(), project, service.ProjectHostnameChangeRequest{
+		Subdomain: req.Subdomain,
+		Domain:    req.Domain,
+	}, service.ProjectArchiveActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectHostnameFailed, "failed to queue hostname change")
+		return
+	}
+
+	c.logAudit(ctx, "project.hostnames.change", plan.Project, map[string]any{
+		"project":           plan.Project,
+		"jobId":             job.ID,
+		"hostname":          plan.Hostname,
+		"previousHostnames": plan.PreviousHostnames,
+		"ingressRules":      len(plan.Ingress),
+		"dnsRecords":        len(plan.DNSRecords),
+		"warningCount":      len(plan.Warnings),
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"plan": plan,
+	})
+}
+
+func (c *ProjectsController) Clone(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectCloneFailed, "project clone service unavailable"), errs.CodeProjectCloneFailed, "project clone service unavailable")
+		return
+	}
+
+	var req models.ProjectCloneRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	job, plan, err := c.archive.QueueClone(ctx.Request.Context(), project, service.ProjectCloneRequest{
+		Name:        req.Name,
+		Subdomain:   req.Subdomain,
+		Domain:      req.Domain,
+		CopyVolumes: req.CopyVolumes,
+	}, service.ProjectArchiveActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectCloneFailed, "failed to queue project clone")
+		return
+	}
+
+	c.logAudit(ctx, "project.clone", plan.Name, map[string]any{
+		"project":      plan.Name,
+		"source":       plan.Project,
+		"jobId":        job.ID,
+		"hostname":     plan.Hostname,
+		"copyVolumes":  plan.CopyVolumes,
+		"volumes":      plan.Volumes,
+		"warningCount": len(plan.Warnings),
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"plan": plan,
+	})
+}
+
+func (c *ProjectsController) Routes(ctx *gin.Context) {
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectRoutesFailed, "project routes service unavailable"), errs.CodeProjectRoutesFailed, "project routes service unavailable")
+		return
+	}
+
+	routes, err := c.archive.Routes(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectRoutesFailed, "failed to load project routes")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"routes": routes})
+}
+
+func (c *ProjectsController) UpdateRoutes(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectRoutesFailed, "project routes service unavailable"), errs.CodeProjectRoutesFailed, "project routes service unavailable")
+		return
+	}
+
+	var req models.ProjectRoutesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	routes := make([]service.ProjectRoute, 0, len(req.Routes))
+	for _, route := range req.Routes {
+		routes = append(routes, service.ProjectRoute{
+			Subdomain: route.Subdomain,
+			Domain:    route.Domain,
+			Path:      route.Path,
+			Port:      route.Port,
+		})
+	}
+	job, plan, err := c.archive.QueueRoutes(ctx.Request.Context(), project, routes, service.ProjectArchiveActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectRoutesFailed, "failed to queue routes update")
+		return
+	}
+
+	c.logAudit(ctx, "project.routes.update", plan.Project, map[string]any{
+		"project":      plan.Project,
+		"jobId":        job.ID,
+		"routes":       len(plan.Routes),
+		"ingressRules": len(plan.Ingress),
+		"dnsRecords":   len(plan.DNSRecords),
+		"warningCount": len(plan.Warnings),
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"plan": plan,
+	})
+}
+
+func (c *ProjectsController) Restore(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectRestoreFailed, "project restore service unavailable"), errs.CodeProjectRestoreFailed, "project restore service unavailable")
+		return
+	}
+
+	job, plan, err := c.archive.QueueRestore(ctx.Request.Context(), project, service.ProjectArchiveActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectRestoreFailed, "failed to queue project restore")
+		return
+	}
+
+	c.logAudit(ctx, "project.restore", plan.Project, map[string]any{
+		"project":      plan.Project,
+		"jobId":        job.ID,
+		"routes":       len(plan.Routes),
+		"dnsRecords":   len(plan.DNSRecords),
+		"restoreEnv":   plan.RestoreEnv,
+		"unrestorable": plan.Unrestorable,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"plan": plan,
+	})
+}
@@ -32,6 +32,7 @@ func workbenchComposeApplyAuditMetadata(
 		"backupSequence":            0,
 		"retainedBackups":           0,
 		"prunedBackups":             0,
+		"mergedChanges":             0,
 		"issueCount":                0,
 		"errorCode":                 "",
 	}
@@ -48,6 +49,9 @@ func workbenchComposeApplyAuditMetadata(
 		metadata["backupSequence"] = result.Backup.Sequence
 		metadata["retainedBackups"] = result.Retention.RetainedCount
 		metadata["prunedBackups"] = result.Retention.PrunedCount
+		if result.Merge != nil {
+			metadata["mergedChanges"] = len(result.Merge.Merged)
+		}
 		return metadata
 	}
 
@@ -161,6 +165,8 @@ func workbenchComposePreviewAuditMetadata(
 		metadata["revision"] = preview.Metadata.Revision
 		metadata["sourceFingerprint"] = preview.Metadata.SourceFingerprint
 		metadata["composeBytes"] = len(preview.Compose)
+		metadata["policyErrorCount"] = preview.Policy.ErrorCount
+		metadata["policyWarningCount"] = preview.Policy.WarningCount
 		return metadata
 	}
 
@@ -237,6 +243,7 @@ func jobTargetsFromPlan(plan service.ProjectArchivePlan, options service.Project
 		ExposureContainers: []string{},
 		ExposureHostnames:  []string{},
 		DNSRecords:         []service.ProjectArchiveDNSDeleteTarget{},
+		AccessApps:         []service.ProjectArchiveAccessDeleteTarget{},
 	}
 	if options.RemoveContainers {
 		for _, container := range plan.Containers {
@@ -276,5 +283,16 @@ func jobTargetsFromPlan(plan service.ProjectArchivePlan, options service.Project
 			})
 		}
 	}
+	if options.RemoveAccess {
+		for _, app := range plan.AccessApps {
+			if !app.DeleteEligible {
+				continue
+			}
+			targets.AccessApps = append(targets.AccessApps, service.ProjectArchiveAccessDeleteTarget{
+				AppID:    app.ID,
+				Hostname: app.Hostname,
+			})
+		}
+	}
 	return targets
 }
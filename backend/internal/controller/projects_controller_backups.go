@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+func (c *ProjectsController) ListBackups(ctx *gin.Context) {
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.host == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectBackupFailed, "volume backup service unavailable"), errs.CodeProjectBackupFailed, "volume backup service unavailable")
+		return
+	}
+
+	backups, err := c.host.ListProjectVolumeBackups(project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectBackupFailed, "failed to list volume backups")
+		return
+	}
+
+	respond.OK(ctx, gin.H{
+		"backups": backups,
+		"retention": gin.H{
+			"project": c.host.ProjectVolumeBackupRetention(ctx.Request.Context(), project),
+			"default": c.host.DefaultVolumeBackupRetention(),
+		},
+	})
+}
+
+func (c *ProjectsController) CreateBackup(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.host == nil || c.jobs == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectBackupFailed, "volume backup service unavailable"), errs.CodeProjectBackupFailed, "volume backup service unavailable")
+		return
+	}
+
+	volumes, err := c.host.ProjectVolumes(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectBackupFailed, "failed to list project volumes")
+		return
+	}
+	if len(volumes) == 0 {
+		respond.Err(ctx, errs.New(errs.CodeProjectBackupNoVolumes, "project has no named volumes to back up"), errs.CodeProjectBackupNoVolumes, "project has no named volumes to back up")
+		return
+	}
+
+	job, err := c.jobs.Create(ctx.Request.Context(), service.JobTypeVolumeBackup, service.ProjectVolumeBackupRequest{
+		Project: project,
+		Reason:  service.VolumeBackupReasonManual,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectBackupFailed, "failed to queue volume backup")
+		return
+	}
+
+	c.logAudit(ctx, "project.backup", project, map[string]any{
+		"project": project,
+		"volumes": volumes,
+		"jobId":   job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":     models.NewJobResponse(*job),
+		"volumes": volumes,
+	})
+}
+
+func (c *ProjectsController) RestoreBackup(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.host == nil || c.jobs == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectBackupFailed, "volume backup service unavailable"), errs.CodeProjectBackupFailed, "volume backup service unavailable")
+		return
+	}
+
+	req := models.ProjectBackupRestoreRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	backup, err := c.host.GetProjectVolumeBackup(project, ctx.Param("backupId"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectBackupFailed, "failed to load volume backup")
+		return
+	}
+
+	archived := map[string]struct{}{}
+	for _, archive := range backup.Volumes {
+		archived[archive.Volume] = struct{}{}
+	}
+	volumes := make([]string, 0, len(req.Volumes))
+	for _, volume := range req.Volumes {
+		trimmed := strings.TrimSpace(volume)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := archived[trimmed]; !ok {
+			message := "volume " + trimmed + " is not part of backup " + backup.BackupID
+			respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, message), errs.CodeProjectInvalidBody, message)
+			return
+		}
+		volumes = append(volumes, trimmed)
+	}
+
+	job, err := c.jobs.Create(ctx.Request.Context(), service.JobTypeVolumeRestore, service.ProjectVolumeRestoreRequest{
+		Project:  project,
+		BackupID: backup.BackupID,
+		Volumes:  volumes,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectBackupFailed, "failed to queue volume restore")
+		return
+	}
+
+	c.logAudit(ctx, "project.backup.restore", project, map[string]any{
+		"project":  project,
+		"backupId": backup.BackupID,
+		"volumes":  volumes,
+		"jobId":    job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":    models.NewJobResponse(*job),
+		"backup": backup,
+	})
+}
+
+func (c *ProjectsController) UpdateBackupPolicy(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.host == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectBackupFailed, "volume backup service unavailable"), errs.CodeProjectBackupFailed, "volume backup service unavailable")
+		return
+	}
+
+	var req models.ProjectBackupRetentionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	record, err := c.host.UpdateVolumeBackupRetention(ctx.Request.Context(), project, req.Retention)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectBackupFailed, "failed to update backup retention")
+		return
+	}
+
+	c.logAudit(ctx, "project.backup.policy", project, map[string]any{
+		"project":   project,
+		"retention": record.BackupRetention,
+	})
+
+	respond.OK(ctx, gin.H{"project": models.NewProjectResponse(*record)})
+}
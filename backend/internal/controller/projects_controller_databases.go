@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+	"go-notes/internal/validate"
+)
+
+func (c *ProjectsController) Databases(ctx *gin.Context) {
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	databases, ok := c.projectDatabases(ctx, project)
+	if !ok {
+		return
+	}
+	dumps, err := c.host.ListProjectDatabaseDumps(project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectDBDumpFailed, "failed to list database dumps")
+		return
+	}
+	respond.OK(ctx, gin.H{
+		"databases": databases,
+		"dumps":     dumps,
+	})
+}
+
+func (c *ProjectsController) CreateDatabaseDump(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.jobs == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectDBDumpFailed, "database dump service unavailable"), errs.CodeProjectDBDumpFailed, "database dump service unavailable")
+		return
+	}
+
+	req := models.ProjectDatabaseDumpRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	databases, ok := c.projectDatabases(ctx, project)
+	if !ok {
+		return
+	}
+	selected := map[string]struct{}{}
+	for _, name := range req.Services {
+		if trimmed := strings.TrimSpace(name); trimmed != "" {
+			selected[trimmed] = struct{}{}
+		}
+	}
+	targets := []service.ProjectDatabaseTarget{}
+	for _, database := range databases {
+		if _, wanted := selected[database.Service]; len(selected) > 0 && !wanted {
+			continue
+		}
+		delete(selected, database.Service)
+		if database.Container == "" {
+			message := "database service " + database.Service + " is not running"
+			respond.Err(ctx, errs.New(errs.CodeProjectDBNotRunning, message), errs.CodeProjectDBNotRunning, message)
+			return
+		}
+		targets = append(targets, service.ProjectDatabaseTarget{Service: database.Service, Engine: database.Engine})
+	}
+	for _, name := range req.Services {
+		if _, missing := selected[strings.TrimSpace(name)]; missing {
+			message := "service " + strings.TrimSpace(name) + " is not a detected database"
+			respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, message), errs.CodeProjectInvalidBody, message)
+			return
+		}
+	}
+	if len(targets) == 0 {
+		respond.Err(ctx, errs.New(errs.CodeProjectNoDatabases, "project has no Postgres or MySQL services"), errs.CodeProjectNoDatabases, "project has no Postgres or MySQL services")
+		return
+	}
+
+	job, err := c.jobs.Create(ctx.Request.Context(), service.JobTypeDBDump, service.ProjectDatabaseDumpRequest{
+		Project:   project,
+		Databases: targets,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectDBDumpFailed, "failed to queue database dump")
+		return
+	}
+
+	c.logAudit(ctx, "project.db.dump", project, map[string]any{
+		"project":   project,
+		"databases": targets,
+		"jobId":     job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":       models.NewJobResponse(*job),
+		"databases": targets,
+	})
+}
+
+func (c *ProjectsController) RestoreDatabaseDump(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.host == nil || c.jobs == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectDBDumpFailed, "database dump service unavailable"), errs.CodeProjectDBDumpFailed, "database dump service unavailable")
+		return
+	}
+
+	req := models.ProjectDatabaseRestoreRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+	target := strings.ToLower(strings.TrimSpace(req.TargetProject))
+	if target == "" {
+		target = project
+	}
+	if err := validate.ProjectName(target); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidName, "target project name must be lowercase alphanumerics or dashes"), errs.CodeProjectInvalidName, "target project name must be lowercase alphanumerics or dashes")
+		return
+	}
+
+	dump, err := c.host.GetProjectDatabaseDump(project, ctx.Param("dumpId"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectDBDumpFailed, "failed to load database dump")
+		return
+	}
+	dumped := map[string]struct{}{}
+	for _, archive := range dump.Dumps {
+		dumped[archive.Service] = struct{}{}
+	}
+	services := make([]string, 0, len(req.Services))
+	for _, name := range req.Services {
+		trimmed := strings.TrimSpace(name)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := dumped[trimmed]; !ok {
+			message := "service " + trimmed + " is not part of dump " + dump.DumpID
+			respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, message), errs.CodeProjectInvalidBody, message)
+			return
+		}
+		services = append(services, trimmed)
+	}
+
+	job, err := c.jobs.Create(ctx.Request.Context(), service.JobTypeDBRestore, service.ProjectDatabaseRestoreRequest{
+		Project:       project,
+		DumpID:        dump.DumpID,
+		TargetProject: target,
+		Services:      services,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectDBDumpFailed, "failed to queue database restore")
+		return
+	}
+
+	c.logAudit(ctx, "project.db.restore", target, map[string]any{
+		"project":       project,
+		"targetProject": target,
+		"dumpId":        dump.DumpID,
+		"services":      services,
+		"jobId":         job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"dump": dump,
+	})
+}
+
+func (c *ProjectsController) projectDatabases(ctx *gin.Context, project string) ([]service.ProjectDatabase, bool) {
+	if c.workbench == nil || c.host == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectDBDumpFailed, "database dump service unavailable"), errs.CodeProjectDBDumpFailed, "database dump service unavailable")
+		return nil, false
+	}
+	stack, err := c.workbench.GetSnapshot(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchReadFailed, "failed to load workbench snapshot")
+		return nil, false
+	}
+	databases, err := c.host.ProjectDatabases(ctx.Request.Context(), stack)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectDBDumpFailed, "failed to detect project databases")
+		return nil, false
+	}
+	return databases, true
+}
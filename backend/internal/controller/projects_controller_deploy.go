@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+func (c *ProjectsController) BlueGreenDeploy(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+	if c.jobs == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectDeployFailed, "blue/green deploy service unavailable"), errs.CodeProjectDeployFailed, "blue/green deploy service unavailable")
+		return
+	}
+
+	req := models.ProjectBlueGreenDeployRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+	if req.HealthTimeoutSeconds < 0 || req.HealthTimeoutSeconds > maxWorkbenchServiceHealthTimeoutSeconds {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "healthTimeoutSeconds must be between 0 and 1800"), errs.CodeProjectInvalidBody, "healthTimeoutSeconds must be between 0 and 1800")
+		return
+	}
+
+	plan, err := c.workbench.PlanBlueGreenDeploy(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectDeployFailed, "failed to plan blue/green deploy")
+		return
+	}
+
+	job, err := c.jobs.Create(ctx.Request.Context(), service.JobTypeBlueGreenDeploy, service.BlueGreenDeployRequest{
+		Project:              plan.ProjectName,
+		HealthTimeoutSeconds: req.HealthTimeoutSeconds,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectDeployFailed, "failed to queue blue/green deploy")
+		return
+	}
+
+	c.logAudit(ctx, "project.deploy.blue_green", project, map[string]any{
+		"project":      project,
+		"proxyService": plan.ProxyService,
+		"proxyPort":    plan.ProxyPort,
+		"services":     plan.Services,
+		"pinned":       plan.Pinned,
+		"revision":     plan.Revision,
+		"jobId":        job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"plan": plan,
+	})
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+)
+
+func (c *ProjectsController) EnvHistory(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.env == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectEnvReadFailed, "project env service unavailable"), errs.CodeProjectEnvReadFailed, "project env service unavailable")
+		return
+	}
+
+	versions, err := c.env.History(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectEnvReadFailed, "failed to list .env history")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"versions": versions})
+}
+
+func (c *ProjectsController) EnvDiff(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.env == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectEnvReadFailed, "project env service unavailable"), errs.CodeProjectEnvReadFailed, "project env service unavailable")
+		return
+	}
+	from := ctx.Query("from")
+	if from == "" {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "from version is required"), errs.CodeProjectInvalidBody, "from version is required")
+		return
+	}
+
+	diff, err := c.env.Diff(ctx.Request.Context(), project, from, ctx.Query("to"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectEnvReadFailed, "failed to diff .env versions")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"diff": diff})
+}
+
+func (c *ProjectsController) ValidateEnv(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.env == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectEnvReadFailed, "project env service unavailable"), errs.CodeProjectEnvReadFailed, "project env service unavailable")
+		return
+	}
+
+	var req models.ProjectEnvValidateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	validation, err := c.env.Validate(ctx.Request.Context(), project, req.Content)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectEnvReadFailed, "failed to validate project .env")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"validation": validation})
+}
+
+func (c *ProjectsController) RestoreEnv(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.env == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectEnvWriteFailed, "project env service unavailable"), errs.CodeProjectEnvWriteFailed, "project env service unavailable")
+		return
+	}
+
+	result, err := c.env.Restore(ctx.Request.Context(), project, ctx.Param("versionId"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectEnvWriteFailed, "failed to restore project .env")
+		return
+	}
+
+	c.logAudit(ctx, "project.env.restore", project, map[string]any{
+		"project":         project,
+		"path":            result.Path,
+		"restoredVersion": result.RestoredVersion,
+		"versionId":       result.VersionID,
+		"addedKeys":       result.Changes.Added,
+		"removedKeys":     result.Changes.Removed,
+		"changedKeys":     result.Changes.Changed,
+	})
+
+	respond.OK(ctx, gin.H{"env": result})
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+const maxGitHubWebhookBodyBytes = 5 << 20
+
+func (c *ProjectsController) GitRedeploy(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.service == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectGitDeployFailed, "git redeploy service unavailable"), errs.CodeProjectGitDeployFailed, "git redeploy service unavailable")
+		return
+	}
+
+	req := models.ProjectGitRedeployRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	job, err := c.service.GitRedeploy(ctx.Request.Context(), project, req.Ref)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectGitDeployFailed, "failed to queue git redeploy")
+		return
+	}
+
+	c.logAudit(ctx, "project.deploy.git", project, map[string]any{
+		"project": project,
+		"ref":     strings.TrimSpace(req.Ref),
+		"trigger": service.GitRedeployTriggerManual,
+		"jobId":   job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{"job": models.NewJobResponse(*job)})
+}
+
+func (c *ProjectsController) UpdateGitDeploySettings(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.service == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectGitDeployFailed, "git redeploy service unavailable"), errs.CodeProjectGitDeployFailed, "git redeploy service unavailable")
+		return
+	}
+
+	var req models.ProjectGitDeploySettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	record, err := c.service.UpdateGitDeploySettings(ctx.Request.Context(), project, service.ProjectGitDeploySettings{
+		Branch:     req.Branch,
+		AutoDeploy: req.AutoDeploy,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectGitDeployFailed, "failed to update git deploy settings")
+		return
+	}
+
+	c.logAudit(ctx, "project.deploy.git.settings", project, map[string]any{
+		"project":    project,
+		"branch":     record.GitBranch,
+		"autoDeploy": record.AutoDeploy,
+	})
+
+	respond.OK(ctx, gin.H{"project": models.NewProjectResponse(*record)})
+}
+
+// GitHubWebhook receives GitHub deliveries. It is mounted outside the session
+// middleware; the X-Hub-Signature-256 HMAC is the only authentication.
+func (c *ProjectsController) GitHubWebhook(ctx *gin.Context) {
+	if c.service == nil {
+		respond.Err(ctx, errs.New(errs.CodeGitHubWebhookOff, "github webhook unavailable"), errs.CodeGitHubWebhookOff, "github webhook unavailable")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxGitHubWebhookBodyBytes))
+	if err != nil {
+		respond.Err(ctx, errs.New(errs.CodeGitHubWebhookBody, "failed to read webhook body"), errs.CodeGitHubWebhookBody, "failed to read webhook body")
+		return
+	}
+	if err := c.service.VerifyGitHubWebhook(body, ctx.GetHeader("X-Hub-Signature-256")); err != nil {
+		respond.Err(ctx, err, errs.CodeGitHubWebhookSig, "invalid webhook signature")
+		return
+	}
+
+	event := strings.TrimSpace(ctx.GetHeader("X-GitHub-Event"))
+	switch event {
+	case "ping":
+		respond.OK(ctx, gin.H{"status": "pong"})
+		return
+	case "push":
+	default:
+		respond.OK(ctx, gin.H{"status": "ignored", "event": event})
+		return
+	}
+
+	var push service.GitHubPushEvent
+	if err := json.Unmarshal(body, &push); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeGitHubWebhookBody, "invalid push payload"), errs.CodeGitHubWebhookBody, "invalid push payload")
+		return
+	}
+	result, err := c.service.HandleGitHubPush(ctx.Request.Context(), push)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectGitDeployFailed, "failed to queue git redeploy")
+		return
+	}
+
+	for _, queued := range result.Queued {
+		c.logAudit(ctx, "project.deploy.git", queued.Project, map[string]any{
+			"project":    queued.Project,
+			"ref":        result.Branch,
+			"commit":     result.Commit,
+			"repository": result.Repository,
+			"delivery":   ctx.GetHeader("X-GitHub-Delivery"),
+			"trigger":    service.GitRedeployTriggerWebhook,
+			"jobId":      queued.JobID,
+		})
+	}
+
+	respond.OK(ctx, gin.H{"status": "processed", "result": result})
+}
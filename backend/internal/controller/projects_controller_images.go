@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+func (c *ProjectsController) ImageUpdates(ctx *gin.Context) {
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	report, ok := c.projectImageUpdateReport(ctx, project)
+	if !ok {
+		return
+	}
+	respond.OK(ctx, gin.H{"report": report})
+}
+
+func (c *ProjectsController) UpdateImages(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+	if c.jobs == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectImageUpdatesFailed, "image update service unavailable"), errs.CodeProjectImageUpdatesFailed, "image update service unavailable")
+		return
+	}
+
+	req := models.ProjectImageUpdateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+	if req.HealthTimeoutSeconds < 0 || req.HealthTimeoutSeconds > maxWorkbenchServiceHealthTimeoutSeconds {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "healthTimeoutSeconds must be between 0 and 1800"), errs.CodeProjectInvalidBody, "healthTimeoutSeconds must be between 0 and 1800")
+		return
+	}
+
+	report, ok := c.projectImageUpdateReport(ctx, project)
+	if !ok {
+		return
+	}
+
+	selected := map[string]struct{}{}
+	for _, image := range req.Images {
+		if trimmed := strings.TrimSpace(image); trimmed != "" {
+			selected[trimmed] = struct{}{}
+		}
+	}
+	targets := []service.ProjectImageUpdateTarget{}
+	for _, image := range report.Images {
+		if image.Status != contract.ImageDigestStatusUpdateAvailable || len(image.Services) == 0 {
+			continue
+		}
+		if _, wanted := selected[image.Image]; len(selected) > 0 && !wanted {
+			continue
+		}
+		targets = append(targets, service.ProjectImageUpdateTarget{
+			Image:           image.Image,
+			Services:        image.Services,
+			PreviousImageID: image.LocalImageID,
+			RemoteDigest:    image.RemoteDigest,
+		})
+	}
+	if len(targets) == 0 {
+		respond.Err(ctx, errs.WithDetails(
+			errs.New(errs.CodeProjectImagesCurrent, "no selected images have updates available"),
+			map[string]any{"project": project, "report": report},
+		), errs.CodeProjectImagesCurrent, "no selected images have updates available")
+		return
+	}
+
+	job, err := c.jobs.Create(ctx.Request.Context(), service.JobTypeImageUpdate, service.ProjectImageUpdateRequest{
+		Project:              project,
+		Revision:             report.Revision,
+		Images:               targets,
+		HealthTimeoutSeconds: req.HealthTimeoutSeconds,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectImageUpdatesFailed, "failed to queue image update")
+		return
+	}
+
+	images := make([]string, 0, len(targets))
+	for _, target := range targets {
+		images = append(images, target.Image)
+	}
+	c.logAudit(ctx, "project.images.update", project, map[string]any{
+		"project": project,
+		"images":  images,
+		"jobId":   job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":     models.NewJobResponse(*job),
+		"targets": targets,
+	})
+}
+
+func (c *ProjectsController) projectImageUpdateReport(ctx *gin.Context, project string) (service.ProjectImageUpdateReport, bool) {
+	if c.workbench == nil || c.host == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectImageUpdatesFailed, "image update service unavailable"), errs.CodeProjectImageUpdatesFailed, "image update service unavailable")
+		return service.ProjectImageUpdateReport{}, false
+	}
+	stack, err := c.workbench.GetSnapshot(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchReadFailed, "failed to load workbench snapshot")
+		return service.ProjectImageUpdateReport{}, false
+	}
+	report, err := c.host.ProjectImageUpdates(ctx.Request.Context(), stack)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectImageUpdatesFailed, "failed to check image updates")
+		return service.ProjectImageUpdateReport{}, false
+	}
+	return report, true
+}
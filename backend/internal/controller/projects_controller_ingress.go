@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+func (c *ProjectsController) Ingress(ctx *gin.Context) {
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectIngressFailed, "project ingress service unavailable"), errs.CodeProjectIngressFailed, "project ingress service unavailable")
+		return
+	}
+
+	rules, warnings, err := c.archive.Ingress(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectIngressFailed, "failed to load project ingress")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"ingressRules": rules, "warnings": warnings})
+}
+
+func (c *ProjectsController) UpdateIngress(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectIngressFailed, "project ingress service unavailable"), errs.CodeProjectIngressFailed, "project ingress service unavailable")
+		return
+	}
+
+	var req service.ProjectIngressUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	job, queued, err := c.archive.QueueIngress(ctx.Request.Context(), project, req, service.ProjectArchiveActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectIngressFailed, "failed to queue ingress update")
+		return
+	}
+
+	c.logAudit(ctx, "project.ingress.update", queued.Project, map[string]any{
+		"project":  queued.Project,
+		"jobId":    job.ID,
+		"hostname": queued.Hostname,
+		"path":     queued.Path,
+		"scheme":   queued.Scheme,
+		"origin":   queued.Origin,
+	})
+
+	respond.Accepted(ctx, gin.H{"job": models.NewJobResponse(*job)})
+}
@@ -128,6 +128,12 @@ func (c *ProjectsController) parseProjectArchiveRequest(ctx *gin.Context) (servi
 	if req.RemoveDNS != nil {
 		options.RemoveDNS = *req.RemoveDNS
 	}
+	if req.RemoveAccess != nil {
+		options.RemoveAccess = *req.RemoveAccess
+	}
+	if req.BackupVolumes != nil {
+		options.BackupVolumes = *req.BackupVolumes
+	}
 	if !options.RemoveContainers && options.RemoveVolumes {
 		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "removeVolumes requires removeContainers=true"), errs.CodeProjectInvalidBody, "removeVolumes requires removeContainers=true")
 		return service.ProjectArchiveOptions{}, false
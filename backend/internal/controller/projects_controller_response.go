@@ -16,6 +16,14 @@ func newProjectResponseFromSummary(project service.ProjectSummary) models.Projec
 		Status:    project.Status,
 		CreatedAt: project.CreatedAt,
 		UpdatedAt: project.UpdatedAt,
+
+		GitBranch:      project.GitBranch,
+		AutoDeploy:     project.AutoDeploy,
+		DeployedCommit: project.DeployedCommit,
+		DeployedAt:     project.DeployedAt,
+
+		BackupRetention: project.BackupRetention,
+		Tunnel:          project.Tunnel,
 	}
 }
 
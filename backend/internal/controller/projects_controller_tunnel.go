@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+func (c *ProjectsController) UpdateTunnel(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.archive == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectTunnelFailed, "project tunnel service unavailable"), errs.CodeProjectTunnelFailed, "project tunnel service unavailable")
+		return
+	}
+
+	var req models.ProjectTunnelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	job, plan, err := c.archive.QueueTunnel(ctx.Request.Context(), project, req.Tunnel, service.ProjectArchiveActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectTunnelFailed, "failed to queue tunnel move")
+		return
+	}
+
+	c.logAudit(ctx, "project.tunnel.update", plan.Project, map[string]any{
+		"project":      plan.Project,
+		"jobId":        job.ID,
+		"from":         plan.From,
+		"to":           plan.To,
+		"rules":        len(plan.Rules),
+		"warningCount": len(plan.Warnings),
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"plan": plan,
+	})
+}
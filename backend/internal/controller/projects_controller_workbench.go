@@ -36,7 +36,7 @@ func (c *ProjectsController) WorkbenchImport(ctx *gin.Context) {
 		return
 	}
 
-	stack, changed, err := c.workbench.ImportComposeSnapshot(ctx.Request.Context(), project, req.Reason)
+	stack, changed, err := c.workbench.ImportComposeSnapshot(workbenchActorContext(ctx), project, req.Reason)
 	if err != nil {
 		respond.Err(ctx, err, errs.CodeProjectWorkbenchImportFailed, "failed to import workbench snapshot")
 		return
@@ -138,6 +138,41 @@ func (c *ProjectsController) WorkbenchCatalog(ctx *gin.Context) {
 	respond.OK(ctx, gin.H{"catalog": catalog})
 }
 
+func (c *ProjectsController) WorkbenchReloadCatalog(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	status, err := c.workbench.ReloadOptionalServiceCatalog(ctx.Request.Context())
+	if err != nil {
+		errorCode, issueCount := workbenchErrorCodeAndIssueCount(err)
+		c.logAudit(ctx, "workbench.catalog.reload", "workbench-catalog", map[string]any{
+			"success":    false,
+			"issueCount": issueCount,
+			"errorCode":  errorCode,
+		})
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchCatalogReloadFailed, "failed to reload workbench optional-service catalog")
+		return
+	}
+
+	c.logAudit(ctx, "workbench.catalog.reload", "workbench-catalog", map[string]any{
+		"success":      true,
+		"sourceDir":    status.SourceDir,
+		"builtinCount": status.BuiltinCount,
+		"fileCount":    status.FileCount,
+		"issueCount":   0,
+		"errorCode":    "",
+	})
+
+	respond.OK(ctx, gin.H{"catalog": status})
+}
+
 func (c *ProjectsController) WorkbenchResolvePorts(ctx *gin.Context) {
 	session, ok := middleware.SessionFromContext(ctx)
 	if !ok || !isAdminRole(session.Role) {
@@ -160,7 +195,7 @@ func (c *ProjectsController) WorkbenchResolvePorts(ctx *gin.Context) {
 		return
 	}
 
-	stack, summary, err := c.workbench.ResolveStoredSnapshotPorts(ctx.Request.Context(), project)
+	stack, summary, err := c.workbench.ResolveStoredSnapshotPorts(workbenchActorContext(ctx), project)
 	if err != nil {
 		errorCode, issueCount := workbenchErrorCodeAndIssueCount(err)
 		c.logAudit(ctx, "project.workbench.ports.resolve", project, map[string]any{
@@ -221,7 +256,7 @@ func (c *ProjectsController) WorkbenchMutatePort(ctx *gin.Context) {
 	}
 
 	stack, summary, err := c.workbench.MutateStoredSnapshotPort(
-		ctx.Request.Context(),
+		workbenchActorContext(ctx),
 		project,
 		service.WorkbenchPortMutationRequest{
 			Selector: service.WorkbenchPortSelector{
@@ -367,7 +402,7 @@ func (c *ProjectsController) WorkbenchMutateResource(ctx *gin.Context) {
 	}
 
 	stack, summary, err := c.workbench.MutateStoredSnapshotResource(
-		ctx.Request.Context(),
+		workbenchActorContext(ctx),
 		project,
 		service.WorkbenchResourceMutationRequest{
 			Selector: service.WorkbenchResourceSelector{
@@ -443,10 +478,11 @@ func (c *ProjectsController) WorkbenchAddService(ctx *gin.Context) {
 	}
 
 	stack, summary, err := c.workbench.AddOptionalService(
-		ctx.Request.Context(),
+		workbenchActorContext(ctx),
 		project,
 		service.WorkbenchOptionalServiceAddRequest{
-			EntryKey: req.EntryKey,
+			EntryKey:   req.EntryKey,
+			AppService: req.AppService,
 		},
 	)
 	if err != nil {
@@ -456,6 +492,7 @@ func (c *ProjectsController) WorkbenchAddService(ctx *gin.Context) {
 			"success":           false,
 			"entryKey":          strings.ToLower(strings.TrimSpace(req.EntryKey)),
 			"serviceName":       "",
+			"appService":        strings.TrimSpace(req.AppService),
 			"changed":           false,
 			"previousCount":     0,
 			"currentCount":      0,
@@ -473,6 +510,8 @@ func (c *ProjectsController) WorkbenchAddService(ctx *gin.Context) {
 		"success":           true,
 		"entryKey":          summary.EntryKey,
 		"serviceName":       summary.ServiceName,
+		"appService":        summary.AppService,
+		"envKeysAdded":      summary.EnvKeysAdded,
 		"changed":           summary.Changed,
 		"previousCount":     summary.PreviousCount,
 		"currentCount":      summary.CurrentCount,
@@ -505,7 +544,7 @@ func (c *ProjectsController) WorkbenchRemoveService(ctx *gin.Context) {
 	}
 
 	serviceName := strings.TrimSpace(ctx.Param("serviceName"))
-	stack, summary, err := c.workbench.RemoveOptionalService(ctx.Request.Context(), project, serviceName)
+	stack, summary, err := c.workbench.RemoveOptionalService(workbenchActorContext(ctx), project, serviceName)
 	if err != nil {
 		errorCode, issueCount := workbenchErrorCodeAndIssueCount(err)
 		c.logAudit(ctx, "project.workbench.services.remove", project, map[string]any{
@@ -568,7 +607,7 @@ func (c *ProjectsController) WorkbenchMutateModule(ctx *gin.Context) {
 	}
 
 	stack, summary, err := c.workbench.MutateLegacyModuleCompatibility(
-		ctx.Request.Context(),
+		workbenchActorContext(ctx),
 		project,
 		service.WorkbenchModuleMutationRequest{
 			Selector: service.WorkbenchModuleSelector{
@@ -619,6 +658,84 @@ func (c *ProjectsController) WorkbenchMutateModule(ctx *gin.Context) {
 	})
 }
 
+func (c *ProjectsController) WorkbenchPolicy(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	stack, report, err := c.workbench.GetPolicyReport(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchPolicyFailed, "failed to lint workbench compose")
+		return
+	}
+
+	respond.OK(ctx, gin.H{
+		"revision": stack.Revision,
+		"policy":   report,
+	})
+}
+
+func (c *ProjectsController) WorkbenchUpdatePolicy(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	req := models.ProjectWorkbenchPolicyUpdateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	stack, err := c.workbench.UpdatePolicyOverrides(workbenchActorContext(ctx), project, service.WorkbenchPolicyUpdateRequest{
+		ExpectedRevision: req.ExpectedRevision,
+		Overrides:        req.Overrides,
+	})
+	if err != nil {
+		errorCode, issueCount := workbenchErrorCodeAndIssueCount(err)
+		c.logAudit(ctx, "project.workbench.policy.update", project, map[string]any{
+			"project":    project,
+			"success":    false,
+			"issueCount": issueCount,
+			"errorCode":  errorCode,
+		})
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchPolicyFailed, "failed to update workbench policy")
+		return
+	}
+
+	c.logAudit(ctx, "project.workbench.policy.update", project, map[string]any{
+		"project":    project,
+		"success":    true,
+		"overrides":  stack.PolicyOverrides,
+		"revision":   stack.Revision,
+		"issueCount": 0,
+		"errorCode":  "",
+	})
+
+	respond.OK(ctx, gin.H{"stack": stack})
+}
+
 func (c *ProjectsController) WorkbenchComposePreview(ctx *gin.Context) {
 	session, ok := middleware.SessionFromContext(ctx)
 	if !ok || !isAdminRole(session.Role) {
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+func (c *ProjectsController) WorkbenchExport(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+
+	bundle, err := c.workbench.ExportBundle(ctx.Request.Context(), project)
+	if err != nil {
+		errorCode, _ := workbenchErrorCodeAndIssueCount(err)
+		c.logAudit(ctx, "project.workbench.export", project, map[string]any{
+			"project":   project,
+			"success":   false,
+			"errorCode": errorCode,
+		})
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchExportFailed, "failed to export workbench bundle")
+		return
+	}
+
+	c.logAudit(ctx, "project.workbench.export", project, map[string]any{
+		"project":          project,
+		"success":          true,
+		"services":         len(bundle.Snapshot.Services),
+		"optionalServices": len(bundle.OptionalServices),
+		"redactedEnvKeys":  bundle.RedactedEnvKeys,
+		"errorCode":        "",
+	})
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", project+".workbench-bundle.json"))
+	respond.OK(ctx, gin.H{"bundle": bundle})
+}
+
+func (c *ProjectsController) WorkbenchImportBundle(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+
+	req := models.ProjectWorkbenchBundleImportRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil || len(req.Bundle) == 0 {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+	bundle := service.WorkbenchBundle{}
+	if err := json.Unmarshal(req.Bundle, &bundle); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid workbench bundle"), errs.CodeProjectInvalidBody, "invalid workbench bundle")
+		return
+	}
+
+	result, err := c.workbench.ImportBundle(workbenchActorContext(ctx), project, service.WorkbenchBundleImportRequest{
+		Bundle:          bundle,
+		KeepManualPorts: req.KeepManualPorts,
+	})
+	if err != nil {
+		errorCode, issueCount := workbenchErrorCodeAndIssueCount(err)
+		c.logAudit(ctx, "project.workbench.bundle.import", project, map[string]any{
+			"project":       project,
+			"sourceProject": bundle.SourceProject,
+			"success":       false,
+			"applied":       false,
+			"issueCount":    issueCount,
+			"errorCode":     errorCode,
+		})
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchBundleImportFailed, "failed to import workbench bundle")
+		return
+	}
+
+	c.logAudit(ctx, "project.workbench.bundle.import", project, map[string]any{
+		"project":        project,
+		"sourceProject":  result.SourceProject,
+		"success":        true,
+		"applied":        result.Applied,
+		"applyErrorCode": result.ApplyErrorCode,
+		"revision":       result.Stack.Revision,
+		"portsAssigned":  result.PortResolution.Assigned,
+		"portIssueCount": len(result.PortIssues),
+		"issueCount":     0,
+		"errorCode":      "",
+	})
+
+	respond.OK(ctx, gin.H{"import": result})
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/respond"
+)
+
+func (c *ProjectsController) WorkbenchDrift(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	status, merge, err := c.workbench.CheckDrift(ctx.Request.Context(), project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchReadFailed, "failed to check workbench drift")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"drift": status, "merge": merge})
+}
+
+func (c *ProjectsController) WorkbenchDriftScan(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	report, ok := c.workbench.LastDriftScan()
+	if !ok || ctx.Query("refresh") == "true" {
+		scanned, err := c.workbench.ScanDrift(ctx.Request.Context())
+		if err != nil {
+			respond.Err(ctx, err, errs.CodeProjectWorkbenchReadFailed, "failed to scan workbench drift")
+			return
+		}
+		report = scanned
+	}
+
+	respond.OK(ctx, gin.H{"scan": report})
+}
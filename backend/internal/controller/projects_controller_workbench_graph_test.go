@@ -51,6 +51,16 @@ func (s *graphTestProjectRepository) Update(_ context.Context, project *models.P
 	return repository.ErrNotFound
 }
 
+func (s *graphTestProjectRepository) Delete(_ context.Context, id uint) error {
+	for index, existing := range s.projects {
+		if existing.ID == id {
+			s.projects = append(s.projects[:index], s.projects[index+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func TestWorkbenchGraphReturnsDedicatedGraphPayload(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
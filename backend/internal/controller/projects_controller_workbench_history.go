@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+	"go-notes/internal/utils/httpx"
+)
+
+func (c *ProjectsController) WorkbenchRevisions(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	limit := httpx.ParsePositiveIntQuery(ctx, "limit", 0)
+	revisions, err := c.workbench.ListRevisions(ctx.Request.Context(), project, limit)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchReadFailed, "failed to load workbench revisions")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"revisions": revisions})
+}
+
+func (c *ProjectsController) WorkbenchRevision(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	revision, ok := parseWorkbenchRevisionParam(ctx)
+	if !ok {
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	detail, err := c.workbench.GetRevision(ctx.Request.Context(), project, revision)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchReadFailed, "failed to load workbench revision")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"revision": detail})
+}
+
+func (c *ProjectsController) WorkbenchRevert(ctx *gin.Context) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	revision, ok := parseWorkbenchRevisionParam(ctx)
+	if !ok {
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	req := models.ProjectWorkbenchRevertRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	stack, err := c.workbench.RevertToRevision(workbenchActorContext(ctx), project, service.WorkbenchRevertRequest{
+		Revision:         revision,
+		ExpectedRevision: req.ExpectedRevision,
+	})
+	if err != nil {
+		errorCode, issueCount := workbenchErrorCodeAndIssueCount(err)
+		c.logAudit(ctx, "project.workbench.revert", project, map[string]any{
+			"project":        project,
+			"success":        false,
+			"targetRevision": revision,
+			"issueCount":     issueCount,
+			"errorCode":      errorCode,
+		})
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchRevertFailed, "failed to revert workbench revision")
+		return
+	}
+
+	c.logAudit(ctx, "project.workbench.revert", project, map[string]any{
+		"project":        project,
+		"success":        true,
+		"targetRevision": revision,
+		"revision":       stack.Revision,
+		"issueCount":     0,
+		"errorCode":      "",
+	})
+
+	respond.OK(ctx, gin.H{"stack": stack})
+}
+
+func parseWorkbenchRevisionParam(ctx *gin.Context) (int, bool) {
+	revision, err := httpx.ParseUintParam(ctx.Param("revision"))
+	if err != nil || revision == 0 {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid revision"), errs.CodeProjectInvalidBody, "invalid revision")
+		return 0, false
+	}
+	return int(revision), true
+}
+
+// workbenchActorContext tags the request context with the session user so
+// snapshot revisions record who made the change.
+func workbenchActorContext(ctx *gin.Context) context.Context {
+	session, _ := middleware.SessionFromContext(ctx)
+	return service.WithWorkbenchActor(ctx.Request.Context(), service.WorkbenchActor{
+		UserID: session.UserID,
+		Login:  session.Login,
+	})
+}
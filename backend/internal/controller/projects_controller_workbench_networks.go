@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+func (c *ProjectsController) WorkbenchCreateNetwork(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+
+	req := models.ProjectWorkbenchNetworkCreateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	stack, summary, err := c.workbench.CreateNetwork(workbenchActorContext(ctx), project, service.WorkbenchNetworkCreateRequest{
+		Name:     req.Name,
+		Driver:   req.Driver,
+		Internal: req.Internal,
+	})
+	c.respondWorkbenchNetworkMutation(ctx, project, stack, summary, err)
+}
+
+func (c *ProjectsController) WorkbenchUpdateNetwork(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+
+	req := models.ProjectWorkbenchNetworkUpdateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	stack, summary, err := c.workbench.UpdateNetwork(workbenchActorContext(ctx), project, strings.TrimSpace(ctx.Param("network")), service.WorkbenchNetworkUpdateRequest{
+		Driver:   req.Driver,
+		Internal: req.Internal,
+	})
+	c.respondWorkbenchNetworkMutation(ctx, project, stack, summary, err)
+}
+
+func (c *ProjectsController) WorkbenchDeleteNetwork(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+
+	stack, summary, err := c.workbench.DeleteNetwork(workbenchActorContext(ctx), project, strings.TrimSpace(ctx.Param("network")))
+	c.respondWorkbenchNetworkMutation(ctx, project, stack, summary, err)
+}
+
+func (c *ProjectsController) WorkbenchMutateServiceNetworks(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+
+	req := models.ProjectWorkbenchServiceNetworksRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+
+	stack, summary, err := c.workbench.MutateServiceNetworks(workbenchActorContext(ctx), project, strings.TrimSpace(ctx.Param("serviceName")), service.WorkbenchServiceNetworksRequest{
+		Attach: req.Attach,
+		Detach: req.Detach,
+	})
+	c.respondWorkbenchNetworkMutation(ctx, project, stack, summary, err)
+}
+
+func (c *ProjectsController) workbenchAdminPreamble(ctx *gin.Context) (string, bool) {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return "", false
+	}
+
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return "", false
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return "", false
+	}
+	return project, true
+}
+
+func (c *ProjectsController) respondWorkbenchNetworkMutation(
+	ctx *gin.Context,
+	project string,
+	stack service.WorkbenchStackSnapshot,
+	summary service.WorkbenchNetworkMutationSummary,
+	err error,
+) {
+	if err != nil {
+		errorCode, issueCount := workbenchErrorCodeAndIssueCount(err)
+		c.logAudit(ctx, "project.workbench.networks.mutate", project, map[string]any{
+			"project":    project,
+			"success":    false,
+			"action":     summary.Action,
+			"network":    summary.Network,
+			"service":    summary.ServiceName,
+			"changed":    false,
+			"revision":   nil,
+			"issueCount": issueCount,
+			"errorCode":  errorCode,
+		})
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchNetworkMutateFailed, "failed to mutate workbench networks")
+		return
+	}
+
+	c.logAudit(ctx, "project.workbench.networks.mutate", project, map[string]any{
+		"project":    project,
+		"success":    true,
+		"action":     summary.Action,
+		"network":    summary.Network,
+		"service":    summary.ServiceName,
+		"attached":   summary.Attached,
+		"detached":   summary.Detached,
+		"changed":    summary.Changed,
+		"revision":   stack.Revision,
+		"issueCount": 0,
+		"errorCode":  "",
+	})
+
+	respond.OK(ctx, gin.H{
+		"stack":    stack,
+		"mutation": summary,
+	})
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+const maxWorkbenchServiceHealthTimeoutSeconds = 30 * 60
+
+func (c *ProjectsController) WorkbenchServiceRestartPlan(ctx *gin.Context) {
+	project, ok := c.parseProjectParam(ctx)
+	if !ok {
+		return
+	}
+	if c.workbench == nil {
+		respond.Err(ctx, errs.New(errs.CodeWorkbenchStorageFailed, "workbench service unavailable"), errs.CodeWorkbenchStorageFailed, "workbench service unavailable")
+		return
+	}
+
+	plan, err := c.workbench.PlanServiceRestart(ctx.Request.Context(), project, strings.TrimSpace(ctx.Param("serviceName")))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectWorkbenchReadFailed, "failed to plan service restart")
+		return
+	}
+
+	respond.OK(ctx, gin.H{"plan": plan})
+}
+
+func (c *ProjectsController) WorkbenchRestartService(ctx *gin.Context) {
+	project, ok := c.workbenchAdminPreamble(ctx)
+	if !ok {
+		return
+	}
+	if c.jobs == nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectStackFailed, "project restart service unavailable"), errs.CodeProjectStackFailed, "project restart service unavailable")
+		return
+	}
+
+	req := models.ProjectWorkbenchServiceRestartRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "invalid request body"), errs.CodeProjectInvalidBody, "invalid request body")
+		return
+	}
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	if mode == "" {
+		mode = service.WorkbenchServiceRestartModeRestart
+	}
+	if mode != service.WorkbenchServiceRestartModeRestart && mode != service.WorkbenchServiceRestartModeRedeploy {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "mode must be restart or redeploy"), errs.CodeProjectInvalidBody, "mode must be restart or redeploy")
+		return
+	}
+	if req.HealthTimeoutSeconds < 0 || req.HealthTimeoutSeconds > maxWorkbenchServiceHealthTimeoutSeconds {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidBody, "healthTimeoutSeconds must be between 0 and 1800"), errs.CodeProjectInvalidBody, "healthTimeoutSeconds must be between 0 and 1800")
+		return
+	}
+
+	plan, err := c.workbench.PlanServiceRestart(ctx.Request.Context(), project, strings.TrimSpace(ctx.Param("serviceName")))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectStackFailed, "failed to plan service restart")
+		return
+	}
+
+	job, err := c.jobs.Create(ctx.Request.Context(), service.JobTypeServiceRestart, service.RestartProjectServicesRequest{
+		Project:              plan.ProjectName,
+		Service:              plan.ServiceName,
+		Mode:                 mode,
+		Revision:             plan.Revision,
+		Tiers:                plan.Tiers,
+		OneShot:              plan.OneShot,
+		HealthTimeoutSeconds: req.HealthTimeoutSeconds,
+	})
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeProjectStackFailed, "failed to queue service restart")
+		return
+	}
+
+	c.logAudit(ctx, "project.workbench.service.restart", project, map[string]any{
+		"project":  project,
+		"service":  plan.ServiceName,
+		"mode":     mode,
+		"services": plan.Services,
+		"tiers":    len(plan.Tiers),
+		"revision": plan.Revision,
+		"jobId":    job.ID,
+	})
+
+	respond.Accepted(ctx, gin.H{
+		"job":  models.NewJobResponse(*job),
+		"plan": plan,
+	})
+}
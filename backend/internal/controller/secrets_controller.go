@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/models"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+	"go-notes/internal/validate"
+)
+
+// SecretsController serves the secrets vault. Responses and audit entries
+// carry secret names and versions, never values.
+type SecretsController struct {
+	service *service.SecretsService
+	audit   *service.AuditService
+}
+
+func NewSecretsController(service *service.SecretsService, audit *service.AuditService) *SecretsController {
+	return &SecretsController{service: service, audit: audit}
+}
+
+func (c *SecretsController) Status(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	status, err := c.service.Status(ctx.Request.Context())
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeSecretsFailed, "failed to load secrets status")
+		return
+	}
+	respond.OK(ctx, gin.H{"secrets": status})
+}
+
+func (c *SecretsController) ListProject(ctx *gin.Context) {
+	project, ok := c.parseProject(ctx)
+	if !ok {
+		return
+	}
+	secrets, err := c.service.List(ctx.Request.Context(), service.SecretScopeProject, project)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeSecretsFailed, "failed to list project secrets")
+		return
+	}
+	respond.OK(ctx, gin.H{"secrets": secrets})
+}
+
+func (c *SecretsController) ProjectVersions(ctx *gin.Context) {
+	project, ok := c.parseProject(ctx)
+	if !ok {
+		return
+	}
+	versions, err := c.service.Versions(ctx.Request.Context(), service.SecretScopeProject, project, ctx.Param("secret"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeSecretsFailed, "failed to list secret versions")
+		return
+	}
+	respond.OK(ctx, gin.H{"versions": versions})
+}
+
+func (c *SecretsController) SetProject(ctx *gin.Context) {
+	project, ok := c.parseProject(ctx)
+	if !ok {
+		return
+	}
+	var req models.SecretSetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeSecretsInvalidBody, "invalid request body"), errs.CodeSecretsInvalidBody, "invalid request body")
+		return
+	}
+
+	secret, err := c.service.Set(ctx.Request.Context(), service.SecretScopeProject, project, ctx.Param("secret"), req.Value, secretActor(ctx))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeSecretsFailed, "failed to store project secret")
+		return
+	}
+
+	c.logAudit(ctx, "project.secret.set", project, map[string]any{
+		"project": project,
+		"name":    secret.Name,
+		"version": secret.Version,
+		"keyId":   secret.KeyID,
+	})
+	respond.OK(ctx, gin.H{"secret": secret})
+}
+
+func (c *SecretsController) RestoreProjectVersion(ctx *gin.Context) {
+	project, ok := c.parseProject(ctx)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(ctx.Param("version")))
+	if err != nil || version < 1 {
+		respond.Err(ctx, errs.New(errs.CodeSecretsInvalidBody, "version must be a positive integer"), errs.CodeSecretsInvalidBody, "version must be a positive integer")
+		return
+	}
+
+	secret, err := c.service.Restore(ctx.Request.Context(), service.SecretScopeProject, project, ctx.Param("secret"), version, secretActor(ctx))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeSecretsFailed, "failed to restore project secret")
+		return
+	}
+
+	c.logAudit(ctx, "project.secret.restore", project, map[string]any{
+		"project":         project,
+		"name":            secret.Name,
+		"restoredVersion": version,
+		"version":         secret.Version,
+	})
+	respond.OK(ctx, gin.H{"secret": secret})
+}
+
+func (c *SecretsController) DeleteProject(ctx *gin.Context) {
+	project, ok := c.parseProject(ctx)
+	if !ok {
+		return
+	}
+	name := ctx.Param("secret")
+	if err := c.service.Delete(ctx.Request.Context(), service.SecretScopeProject, project, name); err != nil {
+		respond.Err(ctx, err, errs.CodeSecretsFailed, "failed to delete project secret")
+		return
+	}
+
+	c.logAudit(ctx, "project.secret.delete", project, map[string]any{
+		"project": project,
+		"name":    name,
+	})
+	respond.OK(ctx, gin.H{"deleted": name})
+}
+
+func (c *SecretsController) requireAdmin(ctx *gin.Context) bool {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeProjectAdminRequired, "admin role required"), errs.CodeProjectAdminRequired, "admin role required")
+		return false
+	}
+	if c.service == nil {
+		respond.Err(ctx, errs.New(errs.CodeSecretsDisabled, "secrets service unavailable"), errs.CodeSecretsDisabled, "secrets service unavailable")
+		return false
+	}
+	return true
+}
+
+func (c *SecretsController) parseProject(ctx *gin.Context) (string, bool) {
+	if !c.requireAdmin(ctx) {
+		return "", false
+	}
+	project := strings.ToLower(strings.TrimSpace(ctx.Param("name")))
+	if err := validate.ProjectName(project); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeProjectInvalidName, "project name must be lowercase alphanumerics or dashes"), errs.CodeProjectInvalidName, "project name must be lowercase alphanumerics or dashes")
+		return "", false
+	}
+	return project, true
+}
+
+func secretActor(ctx *gin.Context) service.ProjectArchiveActor {
+	session, _ := middleware.SessionFromContext(ctx)
+	return service.ProjectArchiveActor{UserID: session.UserID, Login: session.Login}
+}
+
+func (c *SecretsController) logAudit(ctx *gin.Context, action, target string, metadata map[string]any) {
+	if c.audit == nil {
+		return
+	}
+	session, _ := middleware.SessionFromContext(ctx)
+	_ = c.audit.Log(ctx.Request.Context(), service.AuditEntry{
+		UserID:    session.UserID,
+		UserLogin: session.Login,
+		Action:    action,
+		Target:    target,
+		Metadata:  metadata,
+	})
+}
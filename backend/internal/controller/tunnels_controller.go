@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/middleware"
+	"go-notes/internal/respond"
+	"go-notes/internal/service"
+)
+
+// TunnelsController manages the cloudflared tunnels projects can be assigned
+// to besides the one in settings.
+type TunnelsController struct {
+	service *service.TunnelService
+	audit   *service.AuditService
+}
+
+func NewTunnelsController(service *service.TunnelService, audit *service.AuditService) *TunnelsController {
+	return &TunnelsController{service: service, audit: audit}
+}
+
+func (c *TunnelsController) List(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	tunnels, err := c.service.List(ctx.Request.Context())
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeTunnelFailed, "failed to list tunnels")
+		return
+	}
+	respond.OK(ctx, gin.H{"tunnels": tunnels})
+}
+
+func (c *TunnelsController) Create(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	var req service.TunnelInput
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeTunnelInvalidBody, "invalid request body"), errs.CodeTunnelInvalidBody, "invalid request body")
+		return
+	}
+
+	tunnel, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeTunnelFailed, "failed to create tunnel")
+		return
+	}
+
+	c.logAudit(ctx, "tunnel.create", tunnel.Name, tunnelAuditMetadata(tunnel))
+	respond.OK(ctx, gin.H{"tunnel": tunnel})
+}
+
+func (c *TunnelsController) Update(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	var req service.TunnelInput
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respond.Err(ctx, errs.New(errs.CodeTunnelInvalidBody, "invalid request body"), errs.CodeTunnelInvalidBody, "invalid request body")
+		return
+	}
+
+	tunnel, err := c.service.Update(ctx.Request.Context(), ctx.Param("name"), req)
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeTunnelFailed, "failed to update tunnel")
+		return
+	}
+
+	metadata := tunnelAuditMetadata(tunnel)
+	metadata["tokenCleared"] = req.ClearToken
+	c.logAudit(ctx, "tunnel.update", tunnel.Name, metadata)
+	respond.OK(ctx, gin.H{"tunnel": tunnel})
+}
+
+func (c *TunnelsController) Delete(ctx *gin.Context) {
+	if !c.requireAdmin(ctx) {
+		return
+	}
+	tunnel, err := c.service.Delete(ctx.Request.Context(), ctx.Param("name"))
+	if err != nil {
+		respond.Err(ctx, err, errs.CodeTunnelFailed, "failed to delete tunnel")
+		return
+	}
+
+	c.logAudit(ctx, "tunnel.delete", tunnel.Name, tunnelAuditMetadata(tunnel))
+	respond.OK(ctx, gin.H{"tunnel": tunnel})
+}
+
+func (c *TunnelsController) requireAdmin(ctx *gin.Context) bool {
+	session, ok := middleware.SessionFromContext(ctx)
+	if !ok || !isAdminRole(session.Role) {
+		respond.Err(ctx, errs.New(errs.CodeTunnelAdminRequired, "admin role required"), errs.CodeTunnelAdminRequired, "admin role required")
+		return false
+	}
+	if c.service == nil {
+		respond.Err(ctx, errs.New(errs.CodeTunnelUnavailable, "tunnel service unavailable"), errs.CodeTunnelUnavailable, "tunnel service unavailable")
+		return false
+	}
+	return true
+}
+
+func (c *TunnelsController) logAudit(ctx *gin.Context, action, target string, metadata map[string]any) {
+	if c.audit == nil {
+		return
+	}
+	session, _ := middleware.SessionFromContext(ctx)
+	_ = c.audit.Log(ctx.Request.Context(), service.AuditEntry{
+		UserID:    session.UserID,
+		UserLogin: session.Login,
+		Action:    action,
+		Target:    target,
+		Metadata:  metadata,
+	})
+}
+
+func tunnelAuditMetadata(tunnel service.TunnelSummary) map[string]any {
+	return map[string]any{
+		"accountId":      tunnel.AccountID,
+		"tunnel":         tunnel.Tunnel,
+		"configPath":     tunnel.ConfigPath,
+		"metricsAddress": tunnel.MetricsAddress,
+		"tokenSet":       tunnel.TokenSet,
+	}
+}
@@ -46,5 +46,9 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Job{},
 		&models.AuditLog{},
 		&models.Settings{},
+		&models.WorkbenchSnapshot{},
+		&models.WorkbenchSnapshotRevision{},
+		&models.SecretVersion{},
+		&models.CloudflareTunnel{},
 	)
 }
@@ -0,0 +1,13 @@
+package errs
+
+import "net/http"
+
+var (
+	CodeDNSUnavailable   = RegisterHTTPStatus("DNS-500-SERVICE", http.StatusInternalServerError)
+	CodeDNSAdminRequired = RegisterHTTPStatus("DNS-403-ADMIN", http.StatusForbidden)
+	CodeDNSInvalidBody   = RegisterHTTPStatus("DNS-400-BODY", http.StatusBadRequest)
+	CodeDNSInvalidRecord = RegisterHTTPStatus("DNS-400-INVALID", http.StatusBadRequest)
+	CodeDNSZoneNotFound  = RegisterHTTPStatus("DNS-404-ZONE", http.StatusNotFound)
+	CodeDNSUnmanaged     = RegisterHTTPStatus("DNS-409-UNMANAGED", http.StatusConflict)
+	CodeDNSFailed        = RegisterHTTPStatus("DNS-502-FAILED", http.StatusBadGateway)
+)
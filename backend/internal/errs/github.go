@@ -6,4 +6,7 @@ var (
 	CodeGitHubUnavailable  = RegisterHTTPStatus("GH-500-SERVICE", http.StatusInternalServerError)
 	CodeGitHubCatalog      = RegisterHTTPStatus("GH-500-CATALOG", http.StatusInternalServerError)
 	CodeGitHubMissingToken = RegisterHTTPStatus("GH-400-TOKEN", http.StatusBadRequest)
+	CodeGitHubWebhookOff   = RegisterHTTPStatus("GH-503-WEBHOOK", http.StatusServiceUnavailable)
+	CodeGitHubWebhookSig   = RegisterHTTPStatus("GH-401-SIGNATURE", http.StatusUnauthorized)
+	CodeGitHubWebhookBody  = RegisterHTTPStatus("GH-400-WEBHOOK", http.StatusBadRequest)
 )
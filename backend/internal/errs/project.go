@@ -26,15 +26,57 @@ var (
 	CodeProjectWorkbenchServiceMutateFailed  = RegisterHTTPStatus("PROJECT-500-WB-SERVICE-MUTATE", http.StatusInternalServerError)
 	CodeProjectWorkbenchResourceMutateFailed = RegisterHTTPStatus("PROJECT-500-WB-RESOURCE-MUTATE", http.StatusInternalServerError)
 	CodeProjectWorkbenchModuleMutateFailed   = RegisterHTTPStatus("PROJECT-500-WB-MODULE-MUTATE", http.StatusInternalServerError)
+	CodeProjectWorkbenchNetworkMutateFailed  = RegisterHTTPStatus("PROJECT-500-WB-NETWORK-MUTATE", http.StatusInternalServerError)
 	CodeProjectWorkbenchPreviewFailed        = RegisterHTTPStatus("PROJECT-500-WB-PREVIEW", http.StatusInternalServerError)
 	CodeProjectWorkbenchApplyFailed          = RegisterHTTPStatus("PROJECT-500-WB-APPLY", http.StatusInternalServerError)
 	CodeProjectWorkbenchRestoreFailed        = RegisterHTTPStatus("PROJECT-500-WB-RESTORE", http.StatusInternalServerError)
+	CodeProjectWorkbenchCatalogReloadFailed  = RegisterHTTPStatus("PROJECT-500-WB-CATALOG-RELOAD", http.StatusInternalServerError)
+	CodeProjectWorkbenchPolicyFailed         = RegisterHTTPStatus("PROJECT-500-WB-POLICY", http.StatusInternalServerError)
+	CodeProjectWorkbenchRevertFailed         = RegisterHTTPStatus("PROJECT-500-WB-REVERT", http.StatusInternalServerError)
+	CodeProjectWorkbenchExportFailed         = RegisterHTTPStatus("PROJECT-500-WB-EXPORT", http.StatusInternalServerError)
+	CodeProjectWorkbenchBundleImportFailed   = RegisterHTTPStatus("PROJECT-500-WB-BUNDLE-IMPORT", http.StatusInternalServerError)
+	CodeProjectImageUpdatesFailed            = RegisterHTTPStatus("PROJECT-500-IMAGE-UPDATES", http.StatusInternalServerError)
+	CodeProjectImagesCurrent                 = RegisterHTTPStatus("PROJECT-409-IMAGES-CURRENT", http.StatusConflict)
+	CodeProjectGitUnavailable                = RegisterHTTPStatus("PROJECT-409-NO-GIT", http.StatusConflict)
+	CodeProjectGitDeployFailed               = RegisterHTTPStatus("PROJECT-500-GIT-DEPLOY", http.StatusInternalServerError)
 	CodeProjectArchivePlanFailed             = RegisterHTTPStatus("PROJECT-500-ARCHIVE-PLAN", http.StatusInternalServerError)
 	CodeProjectArchiveFailed                 = RegisterHTTPStatus("PROJECT-500-ARCHIVE", http.StatusInternalServerError)
+	CodeProjectHostnameFailed                = RegisterHTTPStatus("PROJECT-500-HOSTNAME", http.StatusInternalServerError)
+	CodeProjectHostnameNoPort                = RegisterHTTPStatus("PROJECT-409-HOSTNAME-PORT", http.StatusConflict)
+	CodeProjectHostnameTaken                 = RegisterHTTPStatus("PROJECT-409-HOSTNAME-TAKEN", http.StatusConflict)
+	CodeProjectHostnameUnchanged             = RegisterHTTPStatus("PROJECT-409-HOSTNAME-UNCHANGED", http.StatusConflict)
+	CodeProjectRoutesInvalid                 = RegisterHTTPStatus("PROJECT-400-ROUTES", http.StatusBadRequest)
+	CodeProjectRoutesFailed                  = RegisterHTTPStatus("PROJECT-500-ROUTES", http.StatusInternalServerError)
+	CodeProjectAccessInvalid                 = RegisterHTTPStatus("PROJECT-400-ACCESS", http.StatusBadRequest)
+	CodeProjectAccessHostname                = RegisterHTTPStatus("PROJECT-404-ACCESS-HOSTNAME", http.StatusNotFound)
+	CodeProjectAccessUnmanaged               = RegisterHTTPStatus("PROJECT-409-ACCESS-UNMANAGED", http.StatusConflict)
+	CodeProjectAccessFailed                  = RegisterHTTPStatus("PROJECT-502-ACCESS", http.StatusBadGateway)
+	CodeProjectIngressInvalid                = RegisterHTTPStatus("PROJECT-400-INGRESS", http.StatusBadRequest)
+	CodeProjectIngressNotFound               = RegisterHTTPStatus("PROJECT-404-INGRESS", http.StatusNotFound)
+	CodeProjectIngressFailed                 = RegisterHTTPStatus("PROJECT-500-INGRESS", http.StatusInternalServerError)
+	CodeProjectTunnelUnchanged               = RegisterHTTPStatus("PROJECT-409-TUNNEL-UNCHANGED", http.StatusConflict)
+	CodeProjectTunnelFailed                  = RegisterHTTPStatus("PROJECT-500-TUNNEL", http.StatusInternalServerError)
+	CodeProjectNotArchived                   = RegisterHTTPStatus("PROJECT-409-NOT-ARCHIVED", http.StatusConflict)
+	CodeProjectRestoreNoManifest             = RegisterHTTPStatus("PROJECT-409-RESTORE-MANIFEST", http.StatusConflict)
+	CodeProjectRestoreBlocked                = RegisterHTTPStatus("PROJECT-409-RESTORE-BLOCKED", http.StatusConflict)
+	CodeProjectRestoreFailed                 = RegisterHTTPStatus("PROJECT-500-RESTORE", http.StatusInternalServerError)
+	CodeProjectBackupFailed                  = RegisterHTTPStatus("PROJECT-500-BACKUP", http.StatusInternalServerError)
+	CodeProjectBackupNotFound                = RegisterHTTPStatus("PROJECT-404-BACKUP", http.StatusNotFound)
+	CodeProjectBackupNoVolumes               = RegisterHTTPStatus("PROJECT-409-BACKUP-NO-VOLUMES", http.StatusConflict)
+	CodeProjectBackupRetention               = RegisterHTTPStatus("PROJECT-400-BACKUP-RETENTION", http.StatusBadRequest)
+	CodeProjectDBDumpFailed                  = RegisterHTTPStatus("PROJECT-500-DB-DUMP", http.StatusInternalServerError)
+	CodeProjectDBDumpNotFound                = RegisterHTTPStatus("PROJECT-404-DB-DUMP", http.StatusNotFound)
+	CodeProjectNoDatabases                   = RegisterHTTPStatus("PROJECT-409-NO-DATABASES", http.StatusConflict)
+	CodeProjectDBNotRunning                  = RegisterHTTPStatus("PROJECT-409-DB-NOT-RUNNING", http.StatusConflict)
+	CodeProjectCloneFailed                   = RegisterHTTPStatus("PROJECT-500-CLONE", http.StatusInternalServerError)
+	CodeProjectCloneExists                   = RegisterHTTPStatus("PROJECT-409-CLONE-EXISTS", http.StatusConflict)
+	CodeProjectCloneBlocked                  = RegisterHTTPStatus("PROJECT-409-CLONE-BLOCKED", http.StatusConflict)
 	CodeProjectStackFailed                   = RegisterHTTPStatus("PROJECT-500-STACK", http.StatusInternalServerError)
 	CodeProjectContainerFailed               = RegisterHTTPStatus("PROJECT-500-CONTAINER", http.StatusInternalServerError)
 	CodeProjectLogsFailed                    = RegisterHTTPStatus("PROJECT-500-LOGS", http.StatusInternalServerError)
 	CodeProjectEnvReadFailed                 = RegisterHTTPStatus("PROJECT-500-ENV-READ", http.StatusInternalServerError)
 	CodeProjectEnvWriteFailed                = RegisterHTTPStatus("PROJECT-500-ENV-WRITE", http.StatusInternalServerError)
 	CodeProjectEnvTooLarge                   = RegisterHTTPStatus("PROJECT-400-ENV-SIZE", http.StatusBadRequest)
+	CodeProjectEnvInvalid                    = RegisterHTTPStatus("PROJECT-400-ENV-INVALID", http.StatusBadRequest)
+	CodeProjectEnvVersionNotFound            = RegisterHTTPStatus("PROJECT-404-ENV-VERSION", http.StatusNotFound)
 )
@@ -0,0 +1,12 @@
+package errs
+
+import "net/http"
+
+var (
+	CodeSecretsDisabled    = RegisterHTTPStatus("SECRETS-503-DISABLED", http.StatusServiceUnavailable)
+	CodeSecretsInvalidBody = RegisterHTTPStatus("SECRETS-400-BODY", http.StatusBadRequest)
+	CodeSecretsInvalidName = RegisterHTTPStatus("SECRETS-400-NAME", http.StatusBadRequest)
+	CodeSecretsNotFound    = RegisterHTTPStatus("SECRETS-404", http.StatusNotFound)
+	CodeSecretsUnknownKey  = RegisterHTTPStatus("SECRETS-409-KEY", http.StatusConflict)
+	CodeSecretsFailed      = RegisterHTTPStatus("SECRETS-500", http.StatusInternalServerError)
+)
@@ -0,0 +1,14 @@
+package errs
+
+import "net/http"
+
+var (
+	CodeTunnelUnavailable   = RegisterHTTPStatus("TUNNEL-500-SERVICE", http.StatusInternalServerError)
+	CodeTunnelAdminRequired = RegisterHTTPStatus("TUNNEL-403-ADMIN", http.StatusForbidden)
+	CodeTunnelInvalidBody   = RegisterHTTPStatus("TUNNEL-400-BODY", http.StatusBadRequest)
+	CodeTunnelInvalid       = RegisterHTTPStatus("TUNNEL-400-INVALID", http.StatusBadRequest)
+	CodeTunnelNotFound      = RegisterHTTPStatus("TUNNEL-404", http.StatusNotFound)
+	CodeTunnelConflict      = RegisterHTTPStatus("TUNNEL-409-CONFLICT", http.StatusConflict)
+	CodeTunnelInUse         = RegisterHTTPStatus("TUNNEL-409-IN-USE", http.StatusConflict)
+	CodeTunnelFailed        = RegisterHTTPStatus("TUNNEL-500", http.StatusInternalServerError)
+)
@@ -14,4 +14,6 @@ var (
 	CodeDomainMissing       = RegisterHTTPStatus("VAL-400-DOMAIN-MISSING", http.StatusBadRequest)
 	CodeDomainNotConfigured = RegisterHTTPStatus("VAL-400-DOMAIN-NOT-CONFIGURED", http.StatusBadRequest)
 	CodeContainerName       = RegisterHTTPStatus("VAL-400-CONTAINER-NAME", http.StatusBadRequest)
+	CodeValidationGitRef    = RegisterHTTPStatus("VAL-400-GIT-REF", http.StatusBadRequest)
+	CodeValidationRoutePath = RegisterHTTPStatus("VAL-400-ROUTE-PATH", http.StatusBadRequest)
 )
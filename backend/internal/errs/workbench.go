@@ -16,4 +16,8 @@ var (
 	CodeWorkbenchBackupWriteFailed     = RegisterHTTPStatus("WB-500-BACKUP-WRITE", http.StatusInternalServerError)
 	CodeWorkbenchBackupRetentionFailed = RegisterHTTPStatus("WB-500-BACKUP-RETENTION", http.StatusInternalServerError)
 	CodeWorkbenchRestoreFailed         = RegisterHTTPStatus("WB-500-RESTORE", http.StatusInternalServerError)
+	CodeWorkbenchCatalogInvalid        = RegisterHTTPStatus("WB-422-CATALOG", http.StatusUnprocessableEntity)
+	CodeWorkbenchRevisionNotFound      = RegisterHTTPStatus("WB-404-REVISION", http.StatusNotFound)
+	CodeWorkbenchBundleInvalid         = RegisterHTTPStatus("WB-422-BUNDLE", http.StatusUnprocessableEntity)
+	CodeWorkbenchProjectExists         = RegisterHTTPStatus("WB-409-PROJECT-EXISTS", http.StatusConflict)
 )
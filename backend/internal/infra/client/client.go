@@ -140,14 +140,20 @@ func (c *Client) LoadResult(ctx context.Context, intentID string) (contract.Resu
 	return result, nil
 }
 
-func (c *Client) RestartTunnel(ctx context.Context, requestID, configPath string) (contract.Result, error) {
+// RestartTunnel restarts the cloudflared process running configPath. An empty
+// metricsAddress uses the worker default.
+func (c *Client) RestartTunnel(ctx context.Context, requestID, configPath, metricsAddress string) (contract.Result, error) {
 	configPath = strings.TrimSpace(configPath)
 	if configPath == "" {
 		return contract.Result{}, fmt.Errorf("cloudflared config path is required")
 	}
-	return c.runTask(ctx, requestID, contract.TaskTypeRestartTunnel, map[string]any{
+	payload := map[string]any{
 		"config_path": configPath,
-	})
+	}
+	if metricsAddress = strings.TrimSpace(metricsAddress); metricsAddress != "" {
+		payload["metrics_address"] = metricsAddress
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeRestartTunnel, payload)
 }
 
 func (c *Client) StopContainer(ctx context.Context, requestID, container string) (contract.Result, error) {
@@ -380,10 +386,172 @@ func (c *Client) ComposeUpStack(ctx context.Context, requestID string, payload c
 	if payload.ForceRecreate {
 		intentPayload["force_recreate"] = true
 	}
+	if len(payload.Services) > 0 {
+		intentPayload["services"] = payload.Services
+	}
+	if payload.NoDeps {
+		intentPayload["no_deps"] = true
+	}
+	if projectName := strings.TrimSpace(payload.ProjectName); projectName != "" {
+		intentPayload["project_name"] = projectName
+	}
 
 	return c.runTask(ctx, requestID, contract.TaskTypeComposeUpStack, intentPayload)
 }
 
+func (c *Client) ComposeDownStack(ctx context.Context, requestID string, payload contract.ComposeDownStackPayload) (contract.Result, error) {
+	payload.Project = strings.TrimSpace(payload.Project)
+	payload.ProjectDir = strings.TrimSpace(payload.ProjectDir)
+	if payload.Project == "" {
+		return contract.Result{}, fmt.Errorf("project is required")
+	}
+
+	intentPayload := map[string]any{
+		"project": payload.Project,
+	}
+	if payload.ProjectDir != "" {
+		intentPayload["project_dir"] = payload.ProjectDir
+	}
+	if len(payload.ConfigFiles) > 0 {
+		intentPayload["config_files"] = payload.ConfigFiles
+	}
+	if projectName := strings.TrimSpace(payload.ProjectName); projectName != "" {
+		intentPayload["project_name"] = projectName
+	}
+	if len(payload.Services) > 0 {
+		intentPayload["services"] = payload.Services
+	}
+
+	return c.runTask(ctx, requestID, contract.TaskTypeComposeDownStack, intentPayload)
+}
+
+func (c *Client) DockerImageDigests(ctx context.Context, requestID string, images []string) (contract.Result, error) {
+	clean := make([]string, 0, len(images))
+	for _, image := range images {
+		if trimmed := strings.TrimSpace(image); trimmed != "" {
+			clean = append(clean, trimmed)
+		}
+	}
+	if len(clean) == 0 {
+		return contract.Result{}, fmt.Errorf("images are required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerImageDigests, map[string]any{
+		"images": clean,
+	})
+}
+
+func (c *Client) DockerImagePull(ctx context.Context, requestID, image string) (contract.Result, error) {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return contract.Result{}, fmt.Errorf("image is required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerImagePull, map[string]any{
+		"image": image,
+	})
+}
+
+func (c *Client) DockerImageTag(ctx context.Context, requestID, source, target string) (contract.Result, error) {
+	source = strings.TrimSpace(source)
+	target = strings.TrimSpace(target)
+	if source == "" || target == "" {
+		return contract.Result{}, fmt.Errorf("source and target are required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerImageTag, map[string]any{
+		"source": source,
+		"target": target,
+	})
+}
+
+func (c *Client) DockerVolumeBackup(ctx context.Context, requestID string, payload contract.DockerVolumeBackupPayload) (contract.Result, error) {
+	project := strings.TrimSpace(payload.Project)
+	backupID := strings.TrimSpace(payload.BackupID)
+	if project == "" || backupID == "" {
+		return contract.Result{}, fmt.Errorf("project and backup id are required")
+	}
+	if len(payload.Volumes) == 0 {
+		return contract.Result{}, fmt.Errorf("volumes are required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerVolumeBackup, map[string]any{
+		"project":   project,
+		"backup_id": backupID,
+		"volumes":   payload.Volumes,
+		"reason":    strings.TrimSpace(payload.Reason),
+	})
+}
+
+func (c *Client) DockerVolumeRestore(ctx context.Context, requestID string, payload contract.DockerVolumeRestorePayload) (contract.Result, error) {
+	project := strings.TrimSpace(payload.Project)
+	backupID := strings.TrimSpace(payload.BackupID)
+	if project == "" || backupID == "" {
+		return contract.Result{}, fmt.Errorf("project and backup id are required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerVolumeRestore, map[string]any{
+		"project":   project,
+		"backup_id": backupID,
+		"volumes":   payload.Volumes,
+	})
+}
+
+func (c *Client) VolumeBackupPrune(ctx context.Context, requestID, project string, keep int) (contract.Result, error) {
+	project = strings.TrimSpace(project)
+	if project == "" {
+		return contract.Result{}, fmt.Errorf("project is required")
+	}
+	if keep < 1 {
+		return contract.Result{}, fmt.Errorf("keep must be at least 1")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeVolumeBackupPrune, map[string]any{
+		"project": project,
+		"keep":    keep,
+	})
+}
+
+func (c *Client) DockerDBDump(ctx context.Context, requestID string, payload contract.DockerDBDumpPayload) (contract.Result, error) {
+	project := strings.TrimSpace(payload.Project)
+	dumpID := strings.TrimSpace(payload.DumpID)
+	if project == "" || dumpID == "" {
+		return contract.Result{}, fmt.Errorf("project and dump id are required")
+	}
+	if len(payload.Targets) == 0 {
+		return contract.Result{}, fmt.Errorf("targets are required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerDBDump, map[string]any{
+		"project": project,
+		"dump_id": dumpID,
+		"targets": payload.Targets,
+	})
+}
+
+func (c *Client) DockerDBRestore(ctx context.Context, requestID string, payload contract.DockerDBRestorePayload) (contract.Result, error) {
+	project := strings.TrimSpace(payload.Project)
+	dumpID := strings.TrimSpace(payload.DumpID)
+	if project == "" || dumpID == "" {
+		return contract.Result{}, fmt.Errorf("project and dump id are required")
+	}
+	if len(payload.Targets) == 0 {
+		return contract.Result{}, fmt.Errorf("targets are required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerDBRestore, map[string]any{
+		"project": project,
+		"dump_id": dumpID,
+		"targets": payload.Targets,
+	})
+}
+
+func (c *Client) DockerVolumeCopy(ctx context.Context, requestID string, payload contract.DockerVolumeCopyPayload) (contract.Result, error) {
+	project := strings.TrimSpace(payload.Project)
+	if project == "" {
+		return contract.Result{}, fmt.Errorf("project is required")
+	}
+	if len(payload.Copies) == 0 {
+		return contract.Result{}, fmt.Errorf("copies are required")
+	}
+	return c.runTask(ctx, requestID, contract.TaskTypeDockerVolumeCopy, map[string]any{
+		"project": project,
+		"copies":  payload.Copies,
+	})
+}
+
 func isValidPort(port int) bool {
 	return port >= 1 && port <= 65535
 }
@@ -161,7 +161,7 @@ func TestRestartTunnelOmitsLegacyHealthURL(t *testing.T) {
 		}
 	}()
 
-	_, err = c.RestartTunnel(ctx, "req-rt", "/tmp/cloudflared/config.yml")
+	_, err = c.RestartTunnel(ctx, "req-rt", "/tmp/cloudflared/config.yml", "")
 	require.NoError(t, err)
 	<-done
 
@@ -174,6 +174,8 @@ func TestRestartTunnelOmitsLegacyHealthURL(t *testing.T) {
 	require.Equal(t, "/tmp/cloudflared/config.yml", intent.Payload["config_path"])
 	_, exists := intent.Payload["health_url"]
 	require.False(t, exists)
+	_, exists = intent.Payload["metrics_address"]
+	require.False(t, exists, "an empty metrics address keeps the worker default")
 }
 
 func TestDockerContainerLogsPayload(t *testing.T) {
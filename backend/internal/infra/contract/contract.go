@@ -33,6 +33,7 @@ const (
 	// Task catalog staged for upcoming host-lifecycle bridge migrations.
 	TaskTypeTunnelRestart          TaskType = "tunnel_restart"
 	TaskTypeComposeUpStack         TaskType = "compose_up_stack"
+	TaskTypeComposeDownStack       TaskType = "compose_down_stack"
 	TaskTypeDockerStopContainer    TaskType = "docker_stop_container"
 	TaskTypeDockerRestartContainer TaskType = "docker_restart_container"
 	TaskTypeDockerRemoveContainer  TaskType = "docker_remove_container"
@@ -51,6 +52,15 @@ const (
 	TaskTypeProjectFileRemove      TaskType = "project_file_remove"
 	TaskTypeHostPortScan           TaskType = "host_port_scan"
 	TaskTypeAPIHealthProbe         TaskType = "api_health_probe"
+	TaskTypeDockerImageDigests     TaskType = "docker_image_digests"
+	TaskTypeDockerImagePull        TaskType = "docker_image_pull"
+	TaskTypeDockerImageTag         TaskType = "docker_image_tag"
+	TaskTypeDockerVolumeBackup     TaskType = "docker_volume_backup"
+	TaskTypeDockerVolumeRestore    TaskType = "docker_volume_restore"
+	TaskTypeVolumeBackupPrune      TaskType = "volume_backup_prune"
+	TaskTypeDockerDBDump           TaskType = "docker_db_dump"
+	TaskTypeDockerDBRestore        TaskType = "docker_db_restore"
+	TaskTypeDockerVolumeCopy       TaskType = "docker_volume_copy"
 )
 
 type Status string
@@ -108,8 +118,17 @@ func (r Result) Terminal() bool {
 	return IsTerminalStatus(r.Status)
 }
 
+// DefaultTunnelMetricsAddress is where a restarted cloudflared serves metrics
+// and readiness when the intent does not name an address.
+const DefaultTunnelMetricsAddress = "127.0.0.1:20241"
+
+// RestartTunnelPayload names the cloudflared process to restart by its config
+// file. MetricsAddress is the loopback address the restarted process serves
+// metrics and readiness on; empty keeps the worker default. Tunnels sharing
+// a host need distinct config files and metrics addresses.
 type RestartTunnelPayload struct {
-	ConfigPath string `json:"config_path"`
+	ConfigPath     string `json:"config_path"`
+	MetricsAddress string `json:"metrics_address,omitempty"`
 }
 
 type ComposeUpStackPayload struct {
@@ -118,6 +137,157 @@ type ComposeUpStackPayload struct {
 	ConfigFiles   []string `json:"config_files,omitempty"`
 	Build         bool     `json:"build,omitempty"`
 	ForceRecreate bool     `json:"force_recreate,omitempty"`
+	// Services limits the run to the named services; NoDeps keeps compose from
+	// also starting their dependencies.
+	Services []string `json:"services,omitempty"`
+	NoDeps   bool     `json:"no_deps,omitempty"`
+	// ProjectName overrides the compose project name, which otherwise comes
+	// from the project directory.
+	ProjectName string `json:"project_name,omitempty"`
+}
+
+// ComposeDownStackPayload removes a compose project's containers. With
+// Services set only those services are stopped and removed; the rest of the
+// project and its networks stay up.
+type ComposeDownStackPayload struct {
+	Project     string   `json:"project"`
+	ProjectDir  string   `json:"project_dir,omitempty"`
+	ConfigFiles []string `json:"config_files,omitempty"`
+	ProjectName string   `json:"project_name,omitempty"`
+	Services    []string `json:"services,omitempty"`
+}
+
+type DockerImageDigestsPayload struct {
+	Images []string `json:"images"`
+}
+
+type DockerImagePullPayload struct {
+	Image string `json:"image"`
+}
+
+type DockerImageTagPayload struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Image digest statuses reported by docker_image_digests.
+const (
+	ImageDigestStatusUpToDate        = "up_to_date"
+	ImageDigestStatusUpdateAvailable = "update_available"
+	ImageDigestStatusNotPulled       = "not_pulled"
+	ImageDigestStatusPinned          = "pinned"
+	ImageDigestStatusUnknown         = "unknown"
+)
+
+// VolumeBackupHelperImage runs tar against the mounted volume; any image
+// with a POSIX shell, find, and tar works.
+const (
+	VolumeBackupHelperImage     = "alpine:3.20"
+	VolumeBackupManifestName    = "manifest.json"
+	VolumeBackupManifestVersion = 1
+)
+
+type DockerVolumeBackupPayload struct {
+	Project  string   `json:"project"`
+	BackupID string   `json:"backup_id"`
+	Volumes  []string `json:"volumes"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+type DockerVolumeRestorePayload struct {
+	Project  string   `json:"project"`
+	BackupID string   `json:"backup_id"`
+	Volumes  []string `json:"volumes,omitempty"`
+}
+
+// VolumeCopy copies Source into a new Destination volume. The destination is
+// created with the source's labels, with the compose project label replaced
+// by the payload's project.
+type VolumeCopy struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+type DockerVolumeCopyPayload struct {
+	Project string       `json:"project"`
+	Copies  []VolumeCopy `json:"copies"`
+}
+
+type VolumeBackupPrunePayload struct {
+	Project string `json:"project"`
+	Keep    int    `json:"keep"`
+}
+
+// VolumeBackupManifest is written next to the volume archives of a backup
+// at <backup dir>/<project>/<backup id>/manifest.json.
+type VolumeBackupManifest struct {
+	Version   int                   `json:"version"`
+	Project   string                `json:"project"`
+	BackupID  string                `json:"backupId"`
+	CreatedAt time.Time             `json:"createdAt"`
+	Reason    string                `json:"reason,omitempty"`
+	Volumes   []VolumeBackupArchive `json:"volumes"`
+}
+
+type VolumeBackupArchive struct {
+	Volume    string            `json:"volume"`
+	File      string            `json:"file"`
+	SizeBytes int64             `json:"sizeBytes"`
+	SHA256    string            `json:"sha256"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// Database engines the worker knows how to dump and restore from inside
+// their own container.
+const (
+	DBEnginePostgres = "postgres"
+	DBEngineMySQL    = "mysql"
+)
+
+// DBDumpDirName is the directory under <backup dir>/<project> holding the
+// project's database dumps, one sub-directory per dump.
+const (
+	DBDumpDirName         = "dumps"
+	DBDumpManifestVersion = 1
+)
+
+// DBDumpTarget names a compose service, its running container, and engine.
+// On restore the container may belong to another project, such as a clone.
+type DBDumpTarget struct {
+	Service   string `json:"service"`
+	Container string `json:"container"`
+	Engine    string `json:"engine"`
+}
+
+type DockerDBDumpPayload struct {
+	Project string         `json:"project"`
+	DumpID  string         `json:"dump_id"`
+	Targets []DBDumpTarget `json:"targets"`
+}
+
+type DockerDBRestorePayload struct {
+	Project string         `json:"project"`
+	DumpID  string         `json:"dump_id"`
+	Targets []DBDumpTarget `json:"targets"`
+}
+
+// DBDumpManifest is written next to the dump files at
+// <backup dir>/<project>/dumps/<dump id>/manifest.json.
+type DBDumpManifest struct {
+	Version   int             `json:"version"`
+	Project   string          `json:"project"`
+	DumpID    string          `json:"dumpId"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Dumps     []DBDumpArchive `json:"dumps"`
+}
+
+type DBDumpArchive struct {
+	Service   string `json:"service"`
+	Engine    string `json:"engine"`
+	Container string `json:"container"`
+	File      string `json:"file"`
+	SizeBytes int64  `json:"sizeBytes"`
+	SHA256    string `json:"sha256"`
 }
 
 type DockerStopContainerPayload struct {
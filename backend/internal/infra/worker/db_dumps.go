@@ -0,0 +1,277 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-notes/internal/infra/contract"
+)
+
+// The dump scripts run inside the database container with the credentials
+// the official images take from their environment, so nothing secret passes
+// through the bridge. "$1" is the dump file path inside the container.
+const (
+	postgresDumpScript = `pg_dump -Fc -U "${POSTGRES_USER:-postgres}" -d "${POSTGRES_DB:-${POSTGRES_USER:-postgres}}" -f "$1"`
+
+	postgresRestoreScript = `pg_restore --clean --if-exists --no-owner -U "${POSTGRES_USER:-postgres}" -d "${POSTGRES_DB:-${POSTGRES_USER:-postgres}}" "$1"`
+
+	mysqlDumpScript = `client=mysqldump; command -v mysqldump >/dev/null 2>&1 || client=mariadb-dump
+export MYSQL_PWD="${MYSQL_ROOT_PASSWORD:-$MARIADB_ROOT_PASSWORD}"
+db="${MYSQL_DATABASE:-$MARIADB_DATABASE}"
+if [ -n "$db" ]; then set -- "$1" --databases "$db"; else set -- "$1" --all-databases; fi
+out="$1"; shift
+"$client" -uroot --single-transaction --routines --triggers "$@" > "$out"`
+
+	mysqlRestoreScript = `client=mysql; command -v mysql >/dev/null 2>&1 || client=mariadb
+export MYSQL_PWD="${MYSQL_ROOT_PASSWORD:-$MARIADB_ROOT_PASSWORD}"
+"$client" -uroot < "$1"`
+)
+
+var dockerContainerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+type dbEngineScripts struct {
+	dump    string
+	restore string
+	suffix  string
+}
+
+var dbEngines = map[string]dbEngineScripts{
+	contract.DBEnginePostgres: {dump: postgresDumpScript, restore: postgresRestoreScript, suffix: ".pgdump"},
+	contract.DBEngineMySQL:    {dump: mysqlDumpScript, restore: mysqlRestoreScript, suffix: ".sql"},
+}
+
+// resolveDBDumpPath returns <backup dir>/<project>/dumps[/<dump id>].
+func (r *Runner) resolveDBDumpPath(project, dumpID string) (string, error) {
+	projectDir, err := r.resolveVolumeBackupPath(project, "")
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(projectDir, contract.DBDumpDirName)
+	if dumpID != "" {
+		if !volumeBackupIDPattern.MatchString(dumpID) {
+			return "", fmt.Errorf("invalid dump id: %q", dumpID)
+		}
+		target = filepath.Join(target, dumpID)
+	}
+	return target, nil
+}
+
+func validateDBDumpTargets(targets []contract.DBDumpTarget) error {
+	if len(targets) == 0 {
+		return fmt.Errorf("targets are required")
+	}
+	seen := make(map[string]struct{}, len(targets))
+	for _, target := range targets {
+		if !composeServiceNamePattern.MatchString(target.Service) {
+			return fmt.Errorf("invalid service name: %q", target.Service)
+		}
+		if !dockerContainerNamePattern.MatchString(target.Container) {
+			return fmt.Errorf("invalid container name: %q", target.Container)
+		}
+		if _, ok := dbEngines[target.Engine]; !ok {
+			return fmt.Errorf("unsupported database engine %q for service %s", target.Engine, target.Service)
+		}
+		if _, dup := seen[target.Service]; dup {
+			return fmt.Errorf("service %s is listed more than once", target.Service)
+		}
+		seen[target.Service] = struct{}{}
+	}
+	return nil
+}
+
+// dbDumpContainerPath is the scratch file used inside the database container.
+func dbDumpContainerPath(dumpID, file string) string {
+	return "/tmp/gungnr-" + dumpID + "-" + file
+}
+
+// handleDockerDBDump runs each engine's dump tool inside its container, copies
+// the result out with docker cp, and records sizes and checksums in the dump
+// manifest. A failed dump removes its partial directory.
+func (r *Runner) handleDockerDBDump(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerDBDumpPayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	if err := validateDBDumpTargets(payload.Targets); err != nil {
+		return taskOutcome{err: err}
+	}
+	dumpID := strings.TrimSpace(payload.DumpID)
+	if dumpID == "" {
+		return taskOutcome{err: fmt.Errorf("dump id is required")}
+	}
+	dumpDir, err := r.resolveDBDumpPath(payload.Project, dumpID)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	if _, err := os.Stat(dumpDir); err == nil {
+		return taskOutcome{err: fmt.Errorf("dump %s already exists", dumpID)}
+	}
+	if err := os.MkdirAll(dumpDir, 0o700); err != nil {
+		return taskOutcome{err: fmt.Errorf("create dump directory: %w", err)}
+	}
+
+	manifest := contract.DBDumpManifest{
+		Version:   contract.DBDumpManifestVersion,
+		Project:   strings.TrimSpace(payload.Project),
+		DumpID:    dumpID,
+		CreatedAt: time.Now().UTC(),
+		Dumps:     make([]contract.DBDumpArchive, 0, len(payload.Targets)),
+	}
+	logLines := make([]string, 0, len(payload.Targets))
+	fail := func(err error, output []byte) taskOutcome {
+		_ = os.RemoveAll(dumpDir)
+		return taskOutcome{err: err, logTail: append(logLines, tailLines(output, 25)...)}
+	}
+	for _, target := range payload.Targets {
+		file := target.Service + dbEngines[target.Engine].suffix
+		scratch := dbDumpContainerPath(dumpID, file)
+		output, err := r.runDockerCommand(ctx, "", "exec", target.Container, "sh", "-c", dbEngines[target.Engine].dump, "sh", scratch)
+		if err != nil {
+			r.removeDBDumpScratch(ctx, target.Container, scratch)
+			return fail(commandError(err, output, "docker exec %s (%s dump)", target.Container, target.Engine), output)
+		}
+		output, err = r.runDockerCommand(ctx, "", "cp", target.Container+":"+scratch, filepath.Join(dumpDir, file))
+		r.removeDBDumpScratch(ctx, target.Container, scratch)
+		if err != nil {
+			return fail(commandError(err, output, "docker cp %s:%s", target.Container, scratch), output)
+		}
+		size, sum, err := fileSHA256(filepath.Join(dumpDir, file))
+		if err != nil {
+			return fail(fmt.Errorf("checksum %s: %w", file, err), nil)
+		}
+		manifest.Dumps = append(manifest.Dumps, contract.DBDumpArchive{
+			Service:   target.Service,
+			Engine:    target.Engine,
+			Container: target.Container,
+			File:      file,
+			SizeBytes: size,
+			SHA256:    sum,
+		})
+		logLines = append(logLines, fmt.Sprintf("%s (%s): %d bytes sha256=%s", target.Service, target.Engine, size, sum))
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fail(fmt.Errorf("encode dump manifest: %w", err), nil)
+	}
+	if _, err := writeFileAtomically(filepath.Join(dumpDir, contract.VolumeBackupManifestName), append(content, '\n'), 0o600, false); err != nil {
+		return fail(fmt.Errorf("write dump manifest: %w", err), nil)
+	}
+	return taskOutcome{
+		logTail: logLines,
+		data:    map[string]any{"manifest": manifest, "path": dumpDir},
+	}
+}
+
+// handleDockerDBRestore verifies every selected dump against the manifest
+// checksum, then copies each into its target container and loads it with the
+// engine's restore tool. Targets are matched to dumps by service name.
+func (r *Runner) handleDockerDBRestore(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerDBRestorePayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	if err := validateDBDumpTargets(payload.Targets); err != nil {
+		return taskOutcome{err: err}
+	}
+	dumpID := strings.TrimSpace(payload.DumpID)
+	if dumpID == "" {
+		return taskOutcome{err: fmt.Errorf("dump id is required")}
+	}
+	dumpDir, err := r.resolveDBDumpPath(payload.Project, dumpID)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	manifest, err := readDBDumpManifest(dumpDir)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+
+	byService := make(map[string]contract.DBDumpArchive, len(manifest.Dumps))
+	for _, archive := range manifest.Dumps {
+		byService[archive.Service] = archive
+	}
+	archives := make([]contract.DBDumpArchive, 0, len(payload.Targets))
+	for _, target := range payload.Targets {
+		archive, ok := byService[target.Service]
+		if !ok {
+			return taskOutcome{err: fmt.Errorf("dump %s does not contain service %s", dumpID, target.Service)}
+		}
+		if archive.Engine != target.Engine {
+			return taskOutcome{err: fmt.Errorf("dump of %s is %s but the target is %s", target.Service, archive.Engine, target.Engine)}
+		}
+		if archive.File != archive.Service+dbEngines[archive.Engine].suffix {
+			return taskOutcome{err: fmt.Errorf("dump manifest has an invalid entry for service %q", archive.Service)}
+		}
+		_, sum, err := fileSHA256(filepath.Join(dumpDir, archive.File))
+		if err != nil {
+			return taskOutcome{err: fmt.Errorf("checksum %s: %w", archive.File, err)}
+		}
+		if sum != archive.SHA256 {
+			return taskOutcome{err: fmt.Errorf("checksum mismatch for %s: manifest=%s actual=%s", archive.File, archive.SHA256, sum)}
+		}
+		archives = append(archives, archive)
+	}
+
+	logLines := make([]string, 0, len(archives))
+	for index, archive := range archives {
+		target := payload.Targets[index]
+		scratch := dbDumpContainerPath(dumpID, archive.File)
+		output, err := r.runDockerCommand(ctx, "", "cp", filepath.Join(dumpDir, archive.File), target.Container+":"+scratch)
+		if err != nil {
+			return taskOutcome{
+				err:     commandError(err, output, "docker cp %s to %s", archive.File, target.Container),
+				logTail: append(logLines, tailLines(output, 25)...),
+			}
+		}
+		output, err = r.runDockerCommand(ctx, "", "exec", target.Container, "sh", "-c", dbEngines[archive.Engine].restore, "sh", scratch)
+		r.removeDBDumpScratch(ctx, target.Container, scratch)
+		if err != nil {
+			return taskOutcome{
+				err:     commandError(err, output, "docker exec %s (%s restore)", target.Container, archive.Engine),
+				logTail: append(logLines, tailLines(output, 25)...),
+			}
+		}
+		logLines = append(logLines, fmt.Sprintf("%s: restored %s into %s", archive.Service, archive.File, target.Container))
+	}
+
+	restored := make([]string, 0, len(archives))
+	for _, archive := range archives {
+		restored = append(restored, archive.Service)
+	}
+	return taskOutcome{
+		logTail: logLines,
+		data:    map[string]any{"services": restored},
+	}
+}
+
+// removeDBDumpScratch deletes the scratch file inside the container. It is
+// best effort: /tmp is cleared when the container is re-created anyway.
+func (r *Runner) removeDBDumpScratch(ctx context.Context, container, scratch string) {
+	_, _ = r.runDockerCommand(ctx, "", "exec", container, "rm", "-f", scratch)
+}
+
+func readDBDumpManifest(dumpDir string) (contract.DBDumpManifest, error) {
+	raw, err := os.ReadFile(filepath.Join(dumpDir, contract.VolumeBackupManifestName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return contract.DBDumpManifest{}, fmt.Errorf("dump %s has no manifest", filepath.Base(dumpDir))
+		}
+		return contract.DBDumpManifest{}, fmt.Errorf("read dump manifest: %w", err)
+	}
+	var manifest contract.DBDumpManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return contract.DBDumpManifest{}, fmt.Errorf("decode dump manifest: %w", err)
+	}
+	if manifest.Version != contract.DBDumpManifestVersion {
+		return contract.DBDumpManifest{}, fmt.Errorf("unsupported dump manifest version %d", manifest.Version)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/infra/queue"
+)
+
+// dumpCopyingExecutor stands in for docker: `docker cp` out of a container
+// writes a fake dump to the destination path.
+type dumpCopyingExecutor struct {
+	fakeExecutor
+	t *testing.T
+}
+
+func (e *dumpCopyingExecutor) Run(ctx context.Context, req commandRequest) ([]byte, error) {
+	output, err := e.fakeExecutor.Run(ctx, req)
+	if len(req.Args) == 3 && req.Args[0] == "cp" && strings.Contains(req.Args[1], ":") {
+		require.NoError(e.t, os.WriteFile(req.Args[2], []byte("dump:"+req.Args[1]), 0o600))
+	}
+	return output, err
+}
+
+func TestDBDumpCopiesDumpsOutAndRestoreLoadsThemIntoTargetContainers(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	backupRoot := t.TempDir()
+	dumpDir := filepath.Join(backupRoot, "demo", contract.DBDumpDirName, "20260102T030405Z")
+	exec := &dumpCopyingExecutor{t: t}
+	r := New(q, 10*time.Millisecond, t.TempDir(), nil)
+	r.dockerTmpDir = t.TempDir()
+	r.exec = exec
+	r.SetVolumeBackupDir(backupRoot)
+
+	result := runVolumeBackupIntent(t, r, q, "intent-dump", contract.TaskTypeDockerDBDump, map[string]any{
+		"project": "demo",
+		"dump_id": "20260102T030405Z",
+		"targets": []map[string]any{
+			{"service": "db", "container": "demo-db-1", "engine": contract.DBEnginePostgres},
+			{"service": "mysql", "container": "demo-mysql-1", "engine": contract.DBEngineMySQL},
+		},
+	})
+	require.Equal(t, contract.StatusSucceeded, result.Status, "error: %#v", result.Error)
+	require.Equal(t, []string{"exec", "demo-db-1", "sh", "-c", postgresDumpScript, "sh", "/tmp/gungnr-20260102T030405Z-db.pgdump"}, exec.calls[0].args)
+	require.Equal(t, []string{"cp", "demo-db-1:/tmp/gungnr-20260102T030405Z-db.pgdump", filepath.Join(dumpDir, "db.pgdump")}, exec.calls[1].args)
+	require.Equal(t, []string{"exec", "demo-db-1", "rm", "-f", "/tmp/gungnr-20260102T030405Z-db.pgdump"}, exec.calls[2].args)
+
+	manifest, err := readDBDumpManifest(dumpDir)
+	require.NoError(t, err)
+	require.Len(t, manifest.Dumps, 2)
+	require.Equal(t, "mysql.sql", manifest.Dumps[1].File)
+	_, sum, err := fileSHA256(filepath.Join(dumpDir, "db.pgdump"))
+	require.NoError(t, err)
+	require.Equal(t, sum, manifest.Dumps[0].SHA256)
+
+	// Restore into a clone's container, matched by service name.
+	calls := len(exec.calls)
+	result = runVolumeBackupIntent(t, r, q, "intent-dump-restore", contract.TaskTypeDockerDBRestore, map[string]any{
+		"project": "demo",
+		"dump_id": "20260102T030405Z",
+		"targets": []map[string]any{
+			{"service": "db", "container": "demo-copy-db-1", "engine": contract.DBEnginePostgres},
+		},
+	})
+	require.Equal(t, contract.StatusSucceeded, result.Status, "error: %#v", result.Error)
+	require.Len(t, exec.calls, calls+3)
+	require.Equal(t, []string{"cp", filepath.Join(dumpDir, "db.pgdump"), "demo-copy-db-1:/tmp/gungnr-20260102T030405Z-db.pgdump"}, exec.calls[calls].args)
+	require.Equal(t, []string{"exec", "demo-copy-db-1", "sh", "-c", postgresRestoreScript, "sh", "/tmp/gungnr-20260102T030405Z-db.pgdump"}, exec.calls[calls+1].args)
+
+	// An engine mismatch or a tampered dump fails before any container is touched.
+	calls = len(exec.calls)
+	result = runVolumeBackupIntent(t, r, q, "intent-dump-restore-engine", contract.TaskTypeDockerDBRestore, map[string]any{
+		"project": "demo",
+		"dump_id": "20260102T030405Z",
+		"targets": []map[string]any{{"service": "db", "container": "demo-db-1", "engine": contract.DBEngineMySQL}},
+	})
+	require.Equal(t, contract.StatusFailed, result.Status)
+	require.Contains(t, result.Error.Message, "dump of db is postgres but the target is mysql")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dumpDir, "mysql.sql"), []byte("tampered"), 0o600))
+	result = runVolumeBackupIntent(t, r, q, "intent-dump-restore-tampered", contract.TaskTypeDockerDBRestore, map[string]any{
+		"project": "demo",
+		"dump_id": "20260102T030405Z",
+		"targets": []map[string]any{{"service": "mysql", "container": "demo-mysql-1", "engine": contract.DBEngineMySQL}},
+	})
+	require.Equal(t, contract.StatusFailed, result.Status)
+	require.Contains(t, result.Error.Message, "checksum mismatch for mysql.sql")
+	require.Len(t, exec.calls, calls)
+}
@@ -0,0 +1,379 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-notes/internal/infra/contract"
+)
+
+const (
+	dockerHubRegistryHost   = "registry-1.docker.io"
+	registryRequestTimeout  = 15 * time.Second
+	maxImageDigestsPerTask  = 64
+	maxRegistryBodyBytes    = 4 << 20
+	registryManifestAccepts = "application/vnd.oci.image.index.v1+json, " +
+		"application/vnd.docker.distribution.manifest.list.v2+json, " +
+		"application/vnd.docker.distribution.manifest.v2+json, " +
+		"application/vnd.oci.image.manifest.v1+json"
+)
+
+var (
+	imageReferencePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/:@-]*$`)
+	bearerParamPattern    = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// imageReference is a parsed image reference. Registry is the host used for
+// API calls (Docker Hub maps to registry-1.docker.io) and Repository includes
+// the implicit "library/" prefix for official images.
+type imageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+func parseImageReference(raw string) (imageReference, error) {
+	ref := strings.TrimSpace(raw)
+	if ref == "" || !imageReferencePattern.MatchString(ref) {
+		return imageReference{}, fmt.Errorf("invalid image reference: %q", raw)
+	}
+
+	parsed := imageReference{}
+	if at := strings.Index(ref, "@"); at >= 0 {
+		parsed.Digest = ref[at+1:]
+		ref = ref[:at]
+		if !strings.HasPrefix(parsed.Digest, "sha256:") {
+			return imageReference{}, fmt.Errorf("invalid image digest: %q", raw)
+		}
+	}
+	if slash, colon := strings.LastIndex(ref, "/"), strings.LastIndex(ref, ":"); colon > slash {
+		parsed.Tag = ref[colon+1:]
+		ref = ref[:colon]
+	}
+
+	first, rest, hasRest := strings.Cut(ref, "/")
+	if hasRest && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		parsed.Registry = first
+		parsed.Repository = rest
+	} else {
+		parsed.Registry = dockerHubRegistryHost
+		parsed.Repository = ref
+	}
+	if parsed.Registry == "docker.io" || parsed.Registry == "index.docker.io" {
+		parsed.Registry = dockerHubRegistryHost
+	}
+	if parsed.Registry == dockerHubRegistryHost && !strings.Contains(parsed.Repository, "/") {
+		parsed.Repository = "library/" + parsed.Repository
+	}
+	if parsed.Repository == "" {
+		return imageReference{}, fmt.Errorf("invalid image reference: %q", raw)
+	}
+	if parsed.Tag == "" && parsed.Digest == "" {
+		parsed.Tag = "latest"
+	}
+	return parsed, nil
+}
+
+func (ref imageReference) sameRepository(other imageReference) bool {
+	return strings.EqualFold(ref.Registry, other.Registry) && ref.Repository == other.Repository
+}
+
+type registryDigestClient struct {
+	httpClient *http.Client
+}
+
+func newRegistryDigestClient() *registryDigestClient {
+	return &registryDigestClient{httpClient: &http.Client{Timeout: registryRequestTimeout}}
+}
+
+// ManifestDigest returns the digest the registry currently serves for the
+// reference's tag. Anonymous bearer tokens are requested when the registry
+// asks for them; private registries needing credentials report an error.
+func (c *registryDigestClient) ManifestDigest(ctx context.Context, ref imageReference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", registryScheme(ref.Registry), ref.Registry, ref.Repository, ref.Tag)
+
+	resp, err := c.manifestRequest(ctx, http.MethodHead, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, tokenErr := c.anonymousToken(ctx, challenge)
+		if tokenErr != nil {
+			return "", tokenErr
+		}
+		resp, err = c.manifestRequest(ctx, http.MethodHead, manifestURL, token)
+		if err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry returned %s for %s", resp.Status, manifestURL)
+	}
+	if digest := strings.TrimSpace(resp.Header.Get("Docker-Content-Digest")); digest != "" {
+		return digest, nil
+	}
+
+	// Some registries omit the digest header on HEAD but send it on GET.
+	getResp, err := c.manifestRequest(ctx, http.MethodGet, manifestURL, strings.TrimPrefix(resp.Request.Header.Get("Authorization"), "Bearer "))
+	if err != nil {
+		return "", err
+	}
+	defer getResp.Body.Close()
+	if getResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry returned %s for %s", getResp.Status, manifestURL)
+	}
+	if digest := strings.TrimSpace(getResp.Header.Get("Docker-Content-Digest")); digest != "" {
+		return digest, nil
+	}
+	// Hashing the body is not a substitute: the registry may serve a
+	// different media type (e.g. a single-platform manifest instead of the
+	// index) than the one behind the local RepoDigest, which would report a
+	// spurious update.
+	return "", fmt.Errorf("registry did not report a manifest digest for %s", manifestURL)
+}
+
+func (c *registryDigestClient) manifestRequest(ctx context.Context, method, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", registryManifestAccepts)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query registry: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *registryDigestClient) anonymousToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(challenge)), "bearer ") {
+		return "", fmt.Errorf("registry requires credentials")
+	}
+	params := map[string]string{}
+	for _, match := range bearerParamPattern.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry auth challenge has no realm")
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("parse registry auth realm: %w", err)
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token endpoint returned %s", resp.Status)
+	}
+	var payload struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRegistryBodyBytes)).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decode registry token: %w", err)
+	}
+	if payload.Token != "" {
+		return payload.Token, nil
+	}
+	if payload.AccessToken != "" {
+		return payload.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry token response is empty")
+}
+
+// registryScheme uses plain HTTP for loopback registries, matching Docker's
+// default treatment of localhost registries as insecure.
+func registryScheme(registry string) string {
+	host := registry
+	if splitHost, _, err := net.SplitHostPort(registry); err == nil {
+		host = splitHost
+	}
+	if host == "localhost" {
+		return "http"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
+
+type imageDigestReport struct {
+	Image        string `json:"image"`
+	LocalDigest  string `json:"local_digest,omitempty"`
+	LocalImageID string `json:"local_image_id,omitempty"`
+	RemoteDigest string `json:"remote_digest,omitempty"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+func (r *Runner) handleDockerImageDigests(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerImageDigestsPayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	if len(payload.Images) == 0 {
+		return taskOutcome{err: fmt.Errorf("images are required")}
+	}
+	if len(payload.Images) > maxImageDigestsPerTask {
+		return taskOutcome{err: fmt.Errorf("at most %d images can be checked per task", maxImageDigestsPerTask)}
+	}
+
+	reports := make([]any, 0, len(payload.Images))
+	logLines := make([]string, 0, len(payload.Images))
+	for _, image := range payload.Images {
+		report := r.inspectImageDigest(ctx, strings.TrimSpace(image))
+		logLines = append(logLines, fmt.Sprintf("%s: %s", report.Image, report.Status))
+		reports = append(reports, report)
+	}
+	return taskOutcome{
+		logTail: logLines,
+		data:    map[string]any{"images": reports},
+	}
+}
+
+func (r *Runner) inspectImageDigest(ctx context.Context, image string) imageDigestReport {
+	report := imageDigestReport{Image: image, Status: contract.ImageDigestStatusUnknown}
+	ref, err := parseImageReference(image)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	args := []string{"image", "inspect", "--format", "{{json .}}", image}
+	output, inspectErr := r.runDockerCommand(ctx, "", args...)
+	if inspectErr == nil {
+		var inspected struct {
+			ID          string   `json:"Id"`
+			RepoDigests []string `json:"RepoDigests"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(string(output))), &inspected); err != nil {
+			report.Error = fmt.Sprintf("decode image inspect: %v", err)
+			return report
+		}
+		report.LocalImageID = inspected.ID
+		for _, repoDigest := range inspected.RepoDigests {
+			name, digest, ok := strings.Cut(repoDigest, "@")
+			if !ok {
+				continue
+			}
+			local, parseErr := parseImageReference(name)
+			if parseErr == nil && local.sameRepository(ref) {
+				report.LocalDigest = digest
+				break
+			}
+		}
+	}
+
+	if ref.Digest != "" {
+		report.RemoteDigest = ref.Digest
+		if inspectErr != nil {
+			report.Status = contract.ImageDigestStatusNotPulled
+		} else {
+			report.Status = contract.ImageDigestStatusPinned
+		}
+		return report
+	}
+
+	remote, err := r.registry.ManifestDigest(ctx, ref)
+	if err != nil {
+		report.Error = err.Error()
+		if inspectErr != nil {
+			report.Status = contract.ImageDigestStatusNotPulled
+		}
+		return report
+	}
+	report.RemoteDigest = remote
+
+	switch {
+	case inspectErr != nil:
+		report.Status = contract.ImageDigestStatusNotPulled
+	case report.LocalDigest == "":
+		// Locally built or retagged images have no registry digest to compare.
+		report.Status = contract.ImageDigestStatusUnknown
+		report.Error = "local image has no registry digest"
+	case report.LocalDigest == remote:
+		report.Status = contract.ImageDigestStatusUpToDate
+	default:
+		report.Status = contract.ImageDigestStatusUpdateAvailable
+	}
+	return report
+}
+
+func (r *Runner) handleDockerImagePull(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerImagePullPayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	image := strings.TrimSpace(payload.Image)
+	if _, err := parseImageReference(image); err != nil {
+		return taskOutcome{err: err}
+	}
+
+	args := []string{"pull", image}
+	output, err := r.runDockerCommand(ctx, "", args...)
+	return taskOutcome{
+		err:     commandError(err, output, "docker %s", strings.Join(args, " ")),
+		logTail: tailLines(output, 25),
+	}
+}
+
+func (r *Runner) handleDockerImageTag(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerImageTagPayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	source := strings.TrimSpace(payload.Source)
+	target := strings.TrimSpace(payload.Target)
+	// Source may be a bare image ID (sha256:...) captured before a pull.
+	if !strings.HasPrefix(source, "sha256:") {
+		if _, err := parseImageReference(source); err != nil {
+			return taskOutcome{err: err}
+		}
+	} else if !imageReferencePattern.MatchString(source) {
+		return taskOutcome{err: fmt.Errorf("invalid image id: %q", source)}
+	}
+	if _, err := parseImageReference(target); err != nil {
+		return taskOutcome{err: err}
+	}
+
+	args := []string{"tag", source, target}
+	output, err := r.runDockerCommand(ctx, "", args...)
+	return taskOutcome{
+		err:     commandError(err, output, "docker %s", strings.Join(args, " ")),
+		logTail: tailLines(output, 25),
+	}
+}
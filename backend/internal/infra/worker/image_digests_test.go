@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/infra/queue"
+)
+
+func TestParseImageReference(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]imageReference{
+		"nginx":                          {Registry: dockerHubRegistryHost, Repository: "library/nginx", Tag: "latest"},
+		"nginx:1.25":                     {Registry: dockerHubRegistryHost, Repository: "library/nginx", Tag: "1.25"},
+		"docker.io/grafana/grafana:11.0": {Registry: dockerHubRegistryHost, Repository: "grafana/grafana", Tag: "11.0"},
+		"ghcr.io/acme/api:v2":            {Registry: "ghcr.io", Repository: "acme/api", Tag: "v2"},
+		"localhost:5000/team/app":        {Registry: "localhost:5000", Repository: "team/app", Tag: "latest"},
+		"postgres:16@sha256:abc":         {Registry: dockerHubRegistryHost, Repository: "library/postgres", Tag: "16", Digest: "sha256:abc"},
+	}
+	for raw, expected := range cases {
+		parsed, err := parseImageReference(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, expected, parsed, raw)
+	}
+
+	for _, raw := range []string{"", "--rm", "nginx latest", "app@md5:abc"} {
+		_, err := parseImageReference(raw)
+		require.Error(t, err, raw)
+	}
+	require.Equal(t, "http", registryScheme("127.0.0.1:5000"))
+	require.Equal(t, "https", registryScheme("ghcr.io"))
+}
+
+func TestProcessOnceResolvesImageDigestsAgainstRegistry(t *testing.T) {
+	t.Parallel()
+
+	// Local registry stand-in: anonymous bearer auth, then manifest digests.
+	var registryURL string
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			require.Equal(t, "repository:team/app:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"anon"}`))
+		case r.Header.Get("Authorization") != "Bearer anon":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+registryURL+`/token",service="stand-in",scope="repository:team/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/team/app/manifests/1.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:new")
+		case r.URL.Path == "/v2/team/app/manifests/1.1":
+			w.Header().Set("Docker-Content-Digest", "sha256:current")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+	registryURL = registry.URL
+	host := strings.TrimPrefix(registry.URL, "http://")
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	intent := contract.Intent{
+		Version:   contract.VersionV1,
+		IntentID:  "intent-image-digests",
+		RequestID: "req-image-digests",
+		TaskType:  contract.TaskTypeDockerImageDigests,
+		Payload: map[string]any{
+			"images": []string{host + "/team/app:1.0", host + "/team/app:1.1", host + "/team/app:2.0"},
+		},
+		CreatedAt: time.Now().UTC().Add(-time.Minute),
+	}
+	_, err = q.WriteIntent(context.Background(), intent)
+	require.NoError(t, err)
+
+	exec := &fakeExecutor{
+		outputs: [][]byte{
+			[]byte(`{"Id":"sha256:img1","RepoDigests":["` + host + `/team/app@sha256:old"]}`),
+			[]byte(`{"Id":"sha256:img2","RepoDigests":["` + host + `/team/app@sha256:current"]}`),
+			[]byte("Error: No such image"),
+		},
+		errs: []error{nil, nil, errors.New("exit status 1")},
+	}
+	r := New(q, 10*time.Millisecond, t.TempDir(), nil)
+	r.dockerTmpDir = t.TempDir()
+	r.exec = exec
+	r.registry.httpClient = registry.Client()
+
+	require.NoError(t, r.ProcessOnce(context.Background()))
+	require.Len(t, exec.calls, 3)
+	require.Equal(t, []string{"image", "inspect", "--format", "{{json .}}", host + "/team/app:1.0"}, exec.calls[0].args)
+
+	result, err := q.ReadResult(context.Background(), intent.IntentID)
+	require.NoError(t, err)
+	require.Equal(t, contract.StatusSucceeded, result.Status)
+	images, ok := result.Data["images"].([]any)
+	require.True(t, ok, "images payload: %#v", result.Data["images"])
+	require.Len(t, images, 3)
+
+	first := images[0].(map[string]any)
+	require.Equal(t, contract.ImageDigestStatusUpdateAvailable, first["status"])
+	require.Equal(t, "sha256:old", first["local_digest"])
+	require.Equal(t, "sha256:new", first["remote_digest"])
+	require.Equal(t, "sha256:img1", first["local_image_id"])
+	require.Equal(t, contract.ImageDigestStatusUpToDate, images[1].(map[string]any)["status"])
+	third := images[2].(map[string]any)
+	require.Equal(t, contract.ImageDigestStatusNotPulled, third["status"])
+	require.NotEmpty(t, third["error"])
+}
+
+func TestInspectImageDigestReportsUnknownWithoutRegistryDigestHeader(t *testing.T) {
+	t.Parallel()
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/team/app/manifests/1.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+		_, _ = w.Write([]byte(`{"schemaVersion":2}`))
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	r := New(q, 10*time.Millisecond, t.TempDir(), nil)
+	r.exec = &fakeExecutor{
+		outputs: [][]byte{[]byte(`{"Id":"sha256:img1","RepoDigests":["` + host + `/team/app@sha256:index"]}`)},
+		errs:    []error{nil},
+	}
+	r.registry.httpClient = registry.Client()
+
+	report := r.inspectImageDigest(context.Background(), host+"/team/app:1.0")
+	require.Equal(t, contract.ImageDigestStatusUnknown, report.Status)
+	require.Equal(t, "sha256:index", report.LocalDigest)
+	require.Empty(t, report.RemoteDigest)
+	require.Contains(t, report.Error, "did not report a manifest digest")
+}
@@ -0,0 +1,455 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/infra/contract"
+)
+
+const volumeBackupArchiveSuffix = ".tar.gz"
+
+var (
+	dockerVolumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	volumeBackupIDPattern   = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// SetVolumeBackupDir sets the directory volume backups are written to. The
+// helper container bind-mounts it, so the path must be the same on the host
+// and inside the API container.
+func (r *Runner) SetVolumeBackupDir(dir string) {
+	r.volumeBackupDir = strings.TrimSpace(dir)
+}
+
+// resolveVolumeBackupPath returns <backup dir>/<project>[/<backup id>].
+func (r *Runner) resolveVolumeBackupPath(project, backupID string) (string, error) {
+	if r.volumeBackupDir == "" {
+		return "", fmt.Errorf("volume backup directory is not configured")
+	}
+	project = strings.TrimSpace(project)
+	if !composeProjectNamePattern.MatchString(project) {
+		return "", fmt.Errorf("invalid project name: %q", project)
+	}
+	base, err := filepath.Abs(r.volumeBackupDir)
+	if err != nil {
+		return "", fmt.Errorf("resolve volume backup directory: %w", err)
+	}
+	target := filepath.Join(base, project)
+	if backupID != "" {
+		if !volumeBackupIDPattern.MatchString(backupID) {
+			return "", fmt.Errorf("invalid backup id: %q", backupID)
+		}
+		target = filepath.Join(target, backupID)
+	}
+	if !pathWithinBase(base, target) {
+		return "", fmt.Errorf("backup path escapes volume backup directory")
+	}
+	return target, nil
+}
+
+func validateVolumeNames(volumes []string) ([]string, error) {
+	clean := make([]string, 0, len(volumes))
+	seen := make(map[string]struct{}, len(volumes))
+	for _, volume := range volumes {
+		volume = strings.TrimSpace(volume)
+		if !dockerVolumeNamePattern.MatchString(volume) {
+			return nil, fmt.Errorf("invalid volume name: %q", volume)
+		}
+		if _, dup := seen[volume]; dup {
+			continue
+		}
+		seen[volume] = struct{}{}
+		clean = append(clean, volume)
+	}
+	return clean, nil
+}
+
+// handleDockerVolumeBackup tars each volume through a throwaway helper
+// container into its own archive, then records sizes and checksums in the
+// backup manifest. A failed backup removes its partial directory.
+func (r *Runner) handleDockerVolumeBackup(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerVolumeBackupPayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	volumes, err := validateVolumeNames(payload.Volumes)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	if len(volumes) == 0 {
+		return taskOutcome{err: fmt.Errorf("volumes are required")}
+	}
+	backupID := strings.TrimSpace(payload.BackupID)
+	if backupID == "" {
+		return taskOutcome{err: fmt.Errorf("backup id is required")}
+	}
+	backupDir, err := r.resolveVolumeBackupPath(payload.Project, backupID)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	if _, err := os.Stat(backupDir); err == nil {
+		return taskOutcome{err: fmt.Errorf("backup %s already exists", backupID)}
+	}
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
+		return taskOutcome{err: fmt.Errorf("create backup directory: %w", err)}
+	}
+
+	manifest := contract.VolumeBackupManifest{
+		Version:   contract.VolumeBackupManifestVersion,
+		Project:   strings.TrimSpace(payload.Project),
+		BackupID:  backupID,
+		CreatedAt: time.Now().UTC(),
+		Reason:    strings.TrimSpace(payload.Reason),
+		Volumes:   make([]contract.VolumeBackupArchive, 0, len(volumes)),
+	}
+	logLines := make([]string, 0, len(volumes))
+	fail := func(err error, output []byte) taskOutcome {
+		_ = os.RemoveAll(backupDir)
+		return taskOutcome{err: err, logTail: append(logLines, tailLines(output, 25)...)}
+	}
+	for _, volume := range volumes {
+		labels, output, err := r.inspectVolumeLabels(ctx, volume)
+		if err != nil {
+			return fail(err, output)
+		}
+		file := volume + volumeBackupArchiveSuffix
+		args := []string{
+			"run", "--rm", "--network", "none",
+			"-v", volume + ":/volume:ro",
+			"-v", backupDir + ":/backup",
+			contract.VolumeBackupHelperImage,
+			"tar", "czf", "/backup/" + file, "-C", "/volume", ".",
+		}
+		output, err = r.runDockerCommand(ctx, "", args...)
+		if err != nil {
+			return fail(commandError(err, output, "docker run %s (backup %s)", contract.VolumeBackupHelperImage, volume), output)
+		}
+		size, sum, err := fileSHA256(filepath.Join(backupDir, file))
+		if err != nil {
+			return fail(fmt.Errorf("checksum %s: %w", file, err), nil)
+		}
+		manifest.Volumes = append(manifest.Volumes, contract.VolumeBackupArchive{
+			Volume:    volume,
+			File:      file,
+			SizeBytes: size,
+			SHA256:    sum,
+			Labels:    labels,
+		})
+		logLines = append(logLines, fmt.Sprintf("%s: %d bytes sha256=%s", volume, size, sum))
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fail(fmt.Errorf("encode backup manifest: %w", err), nil)
+	}
+	if _, err := writeFileAtomically(filepath.Join(backupDir, contract.VolumeBackupManifestName), append(content, '\n'), 0o600, false); err != nil {
+		return fail(fmt.Errorf("write backup manifest: %w", err), nil)
+	}
+	return taskOutcome{
+		logTail: logLines,
+		data:    map[string]any{"manifest": manifest, "path": backupDir},
+	}
+}
+
+func (r *Runner) inspectVolumeLabels(ctx context.Context, volume string) (map[string]string, []byte, error) {
+	args := []string{"volume", "inspect", "--format", "{{json .Labels}}", volume}
+	output, err := r.runDockerCommand(ctx, "", args...)
+	if err != nil {
+		return nil, output, commandError(err, output, "docker %s", strings.Join(args, " "))
+	}
+	labels := map[string]string{}
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed != "" && trimmed != "null" {
+		if err := json.Unmarshal([]byte(trimmed), &labels); err != nil {
+			return nil, output, fmt.Errorf("decode labels of volume %s: %w", volume, err)
+		}
+	}
+	return labels, output, nil
+}
+
+// handleDockerVolumeRestore verifies every selected archive against the
+// manifest checksum before touching any volume, then re-creates missing
+// volumes with their original labels and replaces their contents.
+func (r *Runner) handleDockerVolumeRestore(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerVolumeRestorePayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	backupID := strings.TrimSpace(payload.BackupID)
+	if backupID == "" {
+		return taskOutcome{err: fmt.Errorf("backup id is required")}
+	}
+	backupDir, err := r.resolveVolumeBackupPath(payload.Project, backupID)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	manifest, err := readVolumeBackupManifest(backupDir)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	selected, err := validateVolumeNames(payload.Volumes)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+
+	archives := manifest.Volumes
+	if len(selected) > 0 {
+		byVolume := make(map[string]contract.VolumeBackupArchive, len(manifest.Volumes))
+		for _, archive := range manifest.Volumes {
+			byVolume[archive.Volume] = archive
+		}
+		archives = make([]contract.VolumeBackupArchive, 0, len(selected))
+		for _, volume := range selected {
+			archive, ok := byVolume[volume]
+			if !ok {
+				return taskOutcome{err: fmt.Errorf("backup %s does not contain volume %s", backupID, volume)}
+			}
+			archives = append(archives, archive)
+		}
+	}
+	for _, archive := range archives {
+		if !dockerVolumeNamePattern.MatchString(archive.Volume) || archive.File != archive.Volume+volumeBackupArchiveSuffix {
+			return taskOutcome{err: fmt.Errorf("backup manifest has an invalid entry for volume %q", archive.Volume)}
+		}
+		_, sum, err := fileSHA256(filepath.Join(backupDir, archive.File))
+		if err != nil {
+			return taskOutcome{err: fmt.Errorf("checksum %s: %w", archive.File, err)}
+		}
+		if sum != archive.SHA256 {
+			return taskOutcome{err: fmt.Errorf("checksum mismatch for %s: manifest=%s actual=%s", archive.File, archive.SHA256, sum)}
+		}
+	}
+
+	logLines := make([]string, 0, len(archives))
+	for _, archive := range archives {
+		output, err := r.runDockerCommand(ctx, "", volumeCreateArgs(archive.Volume, archive.Labels)...)
+		if err != nil {
+			return taskOutcome{
+				err:     commandError(err, output, "docker volume create %s", archive.Volume),
+				logTail: append(logLines, tailLines(output, 25)...),
+			}
+		}
+
+		args := []string{
+			"run", "--rm", "--network", "none",
+			"-v", archive.Volume + ":/volume",
+			"-v", backupDir + ":/backup:ro",
+			contract.VolumeBackupHelperImage,
+			"sh", "-c", `find /volume -mindepth 1 -delete && tar xzf "/backup/$1" -C /volume`, "sh", archive.File,
+		}
+		output, err = r.runDockerCommand(ctx, "", args...)
+		if err != nil {
+			return taskOutcome{
+				err:     commandError(err, output, "docker run %s (restore %s)", contract.VolumeBackupHelperImage, archive.Volume),
+				logTail: append(logLines, tailLines(output, 25)...),
+			}
+		}
+		logLines = append(logLines, fmt.Sprintf("%s: restored from %s", archive.Volume, archive.File))
+	}
+
+	restored := make([]string, 0, len(archives))
+	for _, archive := range archives {
+		restored = append(restored, archive.Volume)
+	}
+	return taskOutcome{
+		logTail: logLines,
+		data:    map[string]any{"volumes": restored},
+	}
+}
+
+// volumeCreateArgs builds `docker volume create` with labels in a stable order.
+func volumeCreateArgs(volume string, labels map[string]string) []string {
+	args := []string{"volume", "create"}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--label", key+"="+labels[key])
+	}
+	return append(args, volume)
+}
+
+// handleDockerVolumeCopy copies each source volume into a destination volume
+// that must not exist yet, so a clone never overwrites data. Destinations are
+// labelled for the payload's compose project so compose adopts them. A failed
+// copy removes the destinations this task created.
+func (r *Runner) handleDockerVolumeCopy(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.DockerVolumeCopyPayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	project := strings.TrimSpace(payload.Project)
+	if !composeProjectNamePattern.MatchString(project) {
+		return taskOutcome{err: fmt.Errorf("invalid project name: %q", project)}
+	}
+	if len(payload.Copies) == 0 {
+		return taskOutcome{err: fmt.Errorf("copies are required")}
+	}
+	destinations := make(map[string]struct{}, len(payload.Copies))
+	for _, pair := range payload.Copies {
+		if !dockerVolumeNamePattern.MatchString(pair.Source) {
+			return taskOutcome{err: fmt.Errorf("invalid volume name: %q", pair.Source)}
+		}
+		if !dockerVolumeNamePattern.MatchString(pair.Destination) {
+			return taskOutcome{err: fmt.Errorf("invalid volume name: %q", pair.Destination)}
+		}
+		if pair.Source == pair.Destination {
+			return taskOutcome{err: fmt.Errorf("volume %s cannot be copied onto itself", pair.Source)}
+		}
+		if _, dup := destinations[pair.Destination]; dup {
+			return taskOutcome{err: fmt.Errorf("volume %s is a destination more than once", pair.Destination)}
+		}
+		destinations[pair.Destination] = struct{}{}
+	}
+
+	logLines := make([]string, 0, len(payload.Copies))
+	created := make([]string, 0, len(payload.Copies))
+	fail := func(err error, output []byte) taskOutcome {
+		for _, volume := range created {
+			_, _ = r.runDockerCommand(ctx, "", "volume", "rm", volume)
+		}
+		return taskOutcome{err: err, logTail: append(logLines, tailLines(output, 25)...)}
+	}
+	for _, pair := range payload.Copies {
+		output, err := r.runDockerCommand(ctx, "", "volume", "ls", "--quiet", "--filter", "name=^"+pair.Destination+"$")
+		if err != nil {
+			return fail(commandError(err, output, "docker volume ls %s", pair.Destination), output)
+		}
+		if strings.TrimSpace(string(output)) != "" {
+			return fail(fmt.Errorf("volume %s already exists", pair.Destination), nil)
+		}
+		labels, output, err := r.inspectVolumeLabels(ctx, pair.Source)
+		if err != nil {
+			return fail(err, output)
+		}
+		labels["com.docker.compose.project"] = project
+		output, err = r.runDockerCommand(ctx, "", volumeCreateArgs(pair.Destination, labels)...)
+		if err != nil {
+			return fail(commandError(err, output, "docker volume create %s", pair.Destination), output)
+		}
+		created = append(created, pair.Destination)
+
+		args := []string{
+			"run", "--rm", "--network", "none",
+			"-v", pair.Source + ":/from:ro",
+			"-v", pair.Destination + ":/to",
+			contract.VolumeBackupHelperImage,
+			"cp", "-a", "/from/.", "/to/",
+		}
+		output, err = r.runDockerCommand(ctx, "", args...)
+		if err != nil {
+			return fail(commandError(err, output, "docker run %s (pair %s to %s)", contract.VolumeBackupHelperImage, pair.Source, pair.Destination), output)
+		}
+		logLines = append(logLines, fmt.Sprintf("%s: copied to %s", pair.Source, pair.Destination))
+	}
+	return taskOutcome{
+		logTail: logLines,
+		data:    map[string]any{"volumes": created},
+	}
+}
+
+// handleVolumeBackupPrune keeps the newest Keep backups of a project and
+// removes the rest. Directories without a readable manifest are left alone.
+func (r *Runner) handleVolumeBackupPrune(_ context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.VolumeBackupPrunePayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	if payload.Keep < 1 {
+		return taskOutcome{err: fmt.Errorf("keep must be at least 1")}
+	}
+	projectDir, err := r.resolveVolumeBackupPath(payload.Project, "")
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return taskOutcome{data: map[string]any{"removed": []string{}}}
+		}
+		return taskOutcome{err: fmt.Errorf("read backup directory: %w", err)}
+	}
+
+	manifests := make([]contract.VolumeBackupManifest, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || !volumeBackupIDPattern.MatchString(entry.Name()) {
+			continue
+		}
+		manifest, err := readVolumeBackupManifest(filepath.Join(projectDir, entry.Name()))
+		if err != nil || manifest.BackupID != entry.Name() {
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.SliceStable(manifests, func(i, j int) bool {
+		if !manifests[i].CreatedAt.Equal(manifests[j].CreatedAt) {
+			return manifests[i].CreatedAt.After(manifests[j].CreatedAt)
+		}
+		return manifests[i].BackupID > manifests[j].BackupID
+	})
+
+	removed := make([]string, 0)
+	for index := payload.Keep; index < len(manifests); index++ {
+		backupID := manifests[index].BackupID
+		if err := os.RemoveAll(filepath.Join(projectDir, backupID)); err != nil {
+			return taskOutcome{
+				err:  fmt.Errorf("remove backup %s: %w", backupID, err),
+				data: map[string]any{"removed": removed},
+			}
+		}
+		removed = append(removed, backupID)
+	}
+	logLines := make([]string, 0, len(removed))
+	for _, backupID := range removed {
+		logLines = append(logLines, "removed backup "+backupID)
+	}
+	return taskOutcome{
+		logTail: logLines,
+		data:    map[string]any{"removed": removed},
+	}
+}
+
+func readVolumeBackupManifest(backupDir string) (contract.VolumeBackupManifest, error) {
+	raw, err := os.ReadFile(filepath.Join(backupDir, contract.VolumeBackupManifestName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return contract.VolumeBackupManifest{}, fmt.Errorf("backup %s has no manifest", filepath.Base(backupDir))
+		}
+		return contract.VolumeBackupManifest{}, fmt.Errorf("read backup manifest: %w", err)
+	}
+	var manifest contract.VolumeBackupManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return contract.VolumeBackupManifest{}, fmt.Errorf("decode backup manifest: %w", err)
+	}
+	if manifest.Version != contract.VolumeBackupManifestVersion {
+		return contract.VolumeBackupManifest{}, fmt.Errorf("unsupported backup manifest version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+func fileSHA256(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,210 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/infra/queue"
+)
+
+// tarWritingExecutor stands in for docker: `volume inspect` returns labels and
+// the backup helper container writes a fake archive into the bind mount.
+type tarWritingExecutor struct {
+	fakeExecutor
+	t         *testing.T
+	backupDir string
+}
+
+func (e *tarWritingExecutor) Run(ctx context.Context, req commandRequest) ([]byte, error) {
+	output, err := e.fakeExecutor.Run(ctx, req)
+	if len(req.Args) > 1 && req.Args[0] == "volume" && req.Args[1] == "inspect" {
+		return []byte(`{"com.docker.compose.project":"demo"}` + "\n"), nil
+	}
+	if len(req.Args) > 0 && req.Args[0] == "run" {
+		for index, arg := range req.Args {
+			if arg == "czf" {
+				target := filepath.Join(e.backupDir, strings.TrimPrefix(req.Args[index+1], "/backup/"))
+				require.NoError(e.t, os.WriteFile(target, []byte("archive:"+target), 0o600))
+			}
+		}
+	}
+	return output, err
+}
+
+func runVolumeBackupIntent(t *testing.T, r *Runner, q *queue.Filesystem, id string, taskType contract.TaskType, payload map[string]any) contract.Result {
+	t.Helper()
+	_, err := q.WriteIntent(context.Background(), contract.Intent{
+		Version:   contract.VersionV1,
+		IntentID:  id,
+		RequestID: "req-" + id,
+		TaskType:  taskType,
+		Payload:   payload,
+		CreatedAt: time.Now().UTC().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.NoError(t, r.ProcessOnce(context.Background()))
+	result, err := q.ReadResult(context.Background(), id)
+	require.NoError(t, err)
+	return result
+}
+
+func TestVolumeBackupWritesChecksummedArchivesAndRestoreVerifiesThem(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	backupRoot := t.TempDir()
+	backupDir := filepath.Join(backupRoot, "demo", "20260102T030405Z")
+	exec := &tarWritingExecutor{t: t, backupDir: backupDir}
+	r := New(q, 10*time.Millisecond, t.TempDir(), nil)
+	r.dockerTmpDir = t.TempDir()
+	r.exec = exec
+	r.SetVolumeBackupDir(backupRoot)
+
+	result := runVolumeBackupIntent(t, r, q, "intent-backup", contract.TaskTypeDockerVolumeBackup, map[string]any{
+		"project":   "demo",
+		"backup_id": "20260102T030405Z",
+		"volumes":   []string{"demo_db", "demo_uploads"},
+		"reason":    "manual",
+	})
+	require.Equal(t, contract.StatusSucceeded, result.Status, "error: %#v", result.Error)
+	require.Equal(t, []string{
+		"run", "--rm", "--network", "none",
+		"-v", "demo_db:/volume:ro",
+		"-v", backupDir + ":/backup",
+		contract.VolumeBackupHelperImage,
+		"tar", "czf", "/backup/demo_db.tar.gz", "-C", "/volume", ".",
+	}, exec.calls[1].args)
+
+	manifest, err := readVolumeBackupManifest(backupDir)
+	require.NoError(t, err)
+	require.Equal(t, "manual", manifest.Reason)
+	require.Len(t, manifest.Volumes, 2)
+	require.Equal(t, "demo_db.tar.gz", manifest.Volumes[0].File)
+	require.Equal(t, map[string]string{"com.docker.compose.project": "demo"}, manifest.Volumes[0].Labels)
+	_, sum, err := fileSHA256(filepath.Join(backupDir, "demo_db.tar.gz"))
+	require.NoError(t, err)
+	require.Equal(t, sum, manifest.Volumes[0].SHA256)
+
+	calls := len(exec.calls)
+	result = runVolumeBackupIntent(t, r, q, "intent-restore", contract.TaskTypeDockerVolumeRestore, map[string]any{
+		"project":   "demo",
+		"backup_id": "20260102T030405Z",
+		"volumes":   []string{"demo_db"},
+	})
+	require.Equal(t, contract.StatusSucceeded, result.Status, "error: %#v", result.Error)
+	require.Len(t, exec.calls, calls+2)
+	require.Equal(t, []string{"volume", "create", "--label", "com.docker.compose.project=demo", "demo_db"}, exec.calls[calls].args)
+	require.Equal(t, "demo_db.tar.gz", exec.calls[calls+1].args[len(exec.calls[calls+1].args)-1])
+
+	// A tampered archive fails verification before any volume is touched.
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "demo_uploads.tar.gz"), []byte("tampered"), 0o600))
+	calls = len(exec.calls)
+	result = runVolumeBackupIntent(t, r, q, "intent-restore-tampered", contract.TaskTypeDockerVolumeRestore, map[string]any{
+		"project":   "demo",
+		"backup_id": "20260102T030405Z",
+	})
+	require.Equal(t, contract.StatusFailed, result.Status)
+	require.Contains(t, result.Error.Message, "checksum mismatch for demo_uploads.tar.gz")
+	require.Len(t, exec.calls, calls)
+
+	result = runVolumeBackupIntent(t, r, q, "intent-backup-invalid", contract.TaskTypeDockerVolumeBackup, map[string]any{
+		"project":   "demo",
+		"backup_id": "../escape",
+		"volumes":   []string{"demo_db"},
+	})
+	require.Equal(t, contract.StatusFailed, result.Status)
+}
+
+func TestVolumeBackupPruneKeepsNewestBackups(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	backupRoot := t.TempDir()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for index, backupID := range []string{"b1", "b2", "b3"} {
+		dir := filepath.Join(backupRoot, "demo", backupID)
+		require.NoError(t, os.MkdirAll(dir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, contract.VolumeBackupManifestName), []byte(
+			`{"version":1,"project":"demo","backupId":"`+backupID+`","createdAt":"`+base.Add(time.Duration(index)*time.Hour).Format(time.RFC3339)+`","volumes":[]}`,
+		), 0o600))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(backupRoot, "demo", "partial"), 0o700))
+
+	r := New(q, 10*time.Millisecond, t.TempDir(), nil)
+	r.SetVolumeBackupDir(backupRoot)
+	result := runVolumeBackupIntent(t, r, q, "intent-prune", contract.TaskTypeVolumeBackupPrune, map[string]any{
+		"project": "demo",
+		"keep":    2,
+	})
+	require.Equal(t, contract.StatusSucceeded, result.Status, "error: %#v", result.Error)
+	require.Equal(t, []any{"b1"}, result.Data["removed"])
+
+	entries, err := os.ReadDir(filepath.Join(backupRoot, "demo"))
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.Equal(t, []string{"b2", "b3", "partial"}, names)
+}
+
+func TestVolumeCopyCreatesRelabeledVolumeAndRefusesExistingDestination(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	exec := &fakeExecutor{outputs: [][]byte{
+		nil,
+		[]byte(`{"com.docker.compose.project":"demo","com.docker.compose.volume":"db"}` + "\n"),
+		nil,
+		nil,
+		[]byte("demo-qa_db\n"),
+	}}
+	r := New(q, 10*time.Millisecond, t.TempDir(), nil)
+	r.exec = exec
+
+	copies := []map[string]string{{"source": "demo_db", "destination": "demo-qa_db"}}
+	result := runVolumeBackupIntent(t, r, q, "intent-copy", contract.TaskTypeDockerVolumeCopy, map[string]any{
+		"project": "demo-qa",
+		"copies":  copies,
+	})
+	require.Equal(t, contract.StatusSucceeded, result.Status, "error: %#v", result.Error)
+	require.Len(t, exec.calls, 4)
+	require.Equal(t, []string{
+		"volume", "create",
+		"--label", "com.docker.compose.project=demo-qa",
+		"--label", "com.docker.compose.volume=db",
+		"demo-qa_db",
+	}, exec.calls[2].args)
+	require.Equal(t, []string{
+		"run", "--rm", "--network", "none",
+		"-v", "demo_db:/from:ro",
+		"-v", "demo-qa_db:/to",
+		contract.VolumeBackupHelperImage,
+		"cp", "-a", "/from/.", "/to/",
+	}, exec.calls[3].args)
+
+	result = runVolumeBackupIntent(t, r, q, "intent-copy-exists", contract.TaskTypeDockerVolumeCopy, map[string]any{
+		"project": "demo-qa",
+		"copies":  copies,
+	})
+	require.Equal(t, contract.StatusFailed, result.Status)
+	require.Contains(t, result.Error.Message, "volume demo-qa_db already exists")
+	require.Len(t, exec.calls, 5)
+
+	result = runVolumeBackupIntent(t, r, q, "intent-copy-self", contract.TaskTypeDockerVolumeCopy, map[string]any{
+		"project": "demo",
+		"copies":  []map[string]string{{"source": "demo_db", "destination": "demo_db"}},
+	})
+	require.Equal(t, contract.StatusFailed, result.Status)
+	require.Len(t, exec.calls, 5)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -23,8 +24,7 @@ import (
 
 const defaultPollInterval = 500 * time.Millisecond
 const (
-	tunnelMetricsAddress    = "127.0.0.1:20241"
-	tunnelReadyURL          = "http://127.0.0.1:20241/ready"
+	tunnelMetricsAddress    = contract.DefaultTunnelMetricsAddress
 	tunnelReadyProbeTimeout = 20 * time.Second
 	defaultDockerConfigDir  = "gungnr-docker-config"
 )
@@ -34,7 +34,14 @@ type commandExecutor interface {
 }
 
 type tunnelLifecycle interface {
-	Restart(ctx context.Context, configPath string) (string, []string, error)
+	Restart(ctx context.Context, target tunnelTarget) (string, []string, error)
+}
+
+// tunnelTarget identifies one cloudflared process. Several tunnels can run on
+// a host when each has its own config file and metrics address.
+type tunnelTarget struct {
+	configPath     string
+	metricsAddress string
 }
 
 type defaultCommandExecutor struct{}
@@ -73,6 +80,9 @@ type Runner struct {
 	logger       *log.Logger
 	exec         commandExecutor
 	tunnel       tunnelLifecycle
+	registry     *registryDigestClient
+
+	volumeBackupDir string
 }
 
 func New(q *queue.Filesystem, pollInterval time.Duration, templatesDir string, logger *log.Logger) *Runner {
@@ -93,6 +103,7 @@ func New(q *queue.Filesystem, pollInterval time.Duration, templatesDir string, l
 		logger:       logger,
 		exec:         defaultCommandExecutor{},
 		tunnel:       newCloudflaredTunnelLifecycle(logger),
+		registry:     newRegistryDigestClient(),
 	}
 }
 
@@ -180,11 +191,21 @@ func (r *Runner) supportsTask(taskType contract.TaskType) bool {
 		contract.TaskTypeHostListenTCPPorts,
 		contract.TaskTypeDockerPublishedPorts,
 		contract.TaskTypeComposeUpStack,
+		contract.TaskTypeComposeDownStack,
 		contract.TaskTypeHostRuntimeStats,
 		contract.TaskTypeHostRuntimeStream,
 		contract.TaskTypeProjectFileWriteAtomic,
 		contract.TaskTypeProjectFileCopy,
-		contract.TaskTypeProjectFileRemove:
+		contract.TaskTypeProjectFileRemove,
+		contract.TaskTypeDockerImageDigests,
+		contract.TaskTypeDockerImagePull,
+		contract.TaskTypeDockerImageTag,
+		contract.TaskTypeDockerVolumeBackup,
+		contract.TaskTypeDockerVolumeRestore,
+		contract.TaskTypeVolumeBackupPrune,
+		contract.TaskTypeDockerDBDump,
+		contract.TaskTypeDockerDBRestore,
+		contract.TaskTypeDockerVolumeCopy:
 		return true
 	default:
 		return false
@@ -206,11 +227,21 @@ func (r *Runner) SupportedTasks() []contract.TaskType {
 		contract.TaskTypeHostListenTCPPorts,
 		contract.TaskTypeDockerPublishedPorts,
 		contract.TaskTypeComposeUpStack,
+		contract.TaskTypeComposeDownStack,
 		contract.TaskTypeHostRuntimeStats,
 		contract.TaskTypeHostRuntimeStream,
 		contract.TaskTypeProjectFileWriteAtomic,
 		contract.TaskTypeProjectFileCopy,
 		contract.TaskTypeProjectFileRemove,
+		contract.TaskTypeDockerImageDigests,
+		contract.TaskTypeDockerImagePull,
+		contract.TaskTypeDockerImageTag,
+		contract.TaskTypeDockerVolumeBackup,
+		contract.TaskTypeDockerVolumeRestore,
+		contract.TaskTypeVolumeBackupPrune,
+		contract.TaskTypeDockerDBDump,
+		contract.TaskTypeDockerDBRestore,
+		contract.TaskTypeDockerVolumeCopy,
 	}
 }
 
@@ -277,6 +308,8 @@ func (r *Runner) handleIntent(ctx context.Context, intent contract.Intent) error
 		outcome = r.handleDockerPublishedPorts(ctx, intent)
 	case contract.TaskTypeComposeUpStack:
 		outcome = r.handleComposeUpStack(ctx, intent)
+	case contract.TaskTypeComposeDownStack:
+		outcome = r.handleComposeDownStack(ctx, intent)
 	case contract.TaskTypeHostRuntimeStats:
 		outcome = r.handleHostRuntimeStats(ctx, intent)
 	case contract.TaskTypeHostRuntimeStream:
@@ -287,6 +320,24 @@ func (r *Runner) handleIntent(ctx context.Context, intent contract.Intent) error
 		outcome = r.handleProjectFileCopy(ctx, intent)
 	case contract.TaskTypeProjectFileRemove:
 		outcome = r.handleProjectFileRemove(ctx, intent)
+	case contract.TaskTypeDockerImageDigests:
+		outcome = r.handleDockerImageDigests(ctx, intent)
+	case contract.TaskTypeDockerImagePull:
+		outcome = r.handleDockerImagePull(ctx, intent)
+	case contract.TaskTypeDockerImageTag:
+		outcome = r.handleDockerImageTag(ctx, intent)
+	case contract.TaskTypeDockerVolumeBackup:
+		outcome = r.handleDockerVolumeBackup(ctx, intent)
+	case contract.TaskTypeDockerVolumeRestore:
+		outcome = r.handleDockerVolumeRestore(ctx, intent)
+	case contract.TaskTypeVolumeBackupPrune:
+		outcome = r.handleVolumeBackupPrune(ctx, intent)
+	case contract.TaskTypeDockerDBDump:
+		outcome = r.handleDockerDBDump(ctx, intent)
+	case contract.TaskTypeDockerDBRestore:
+		outcome = r.handleDockerDBRestore(ctx, intent)
+	case contract.TaskTypeDockerVolumeCopy:
+		outcome = r.handleDockerVolumeCopy(ctx, intent)
 	default:
 		outcome.err = fmt.Errorf("unsupported task type: %s", intent.TaskType)
 	}
@@ -330,10 +381,22 @@ func (r *Runner) handleRestartTunnel(ctx context.Context, intent contract.Intent
 	if configPath == "" {
 		return taskOutcome{err: fmt.Errorf("config_path is required")}
 	}
+	metricsAddress := strings.TrimSpace(payload.MetricsAddress)
+	if metricsAddress == "" {
+		metricsAddress = tunnelMetricsAddress
+	}
+	host, _, err := net.SplitHostPort(metricsAddress)
+	if err != nil {
+		return taskOutcome{err: fmt.Errorf("metrics_address %q is invalid: %w", metricsAddress, err)}
+	}
+	// cloudflared serves its metrics unauthenticated, so they never leave the host.
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return taskOutcome{err: fmt.Errorf("metrics_address %q must be on a loopback address", metricsAddress)}
+	}
 	if r.tunnel == nil {
 		return taskOutcome{err: fmt.Errorf("tunnel lifecycle unavailable")}
 	}
-	logPath, tail, err := r.tunnel.Restart(ctx, configPath)
+	logPath, tail, err := r.tunnel.Restart(ctx, tunnelTarget{configPath: configPath, metricsAddress: metricsAddress})
 	return taskOutcome{
 		err:     err,
 		logTail: tail,
@@ -894,6 +957,11 @@ func (r *Runner) handleDockerPublishedPorts(ctx context.Context, _ contract.Inte
 	}
 }
 
+var (
+	composeServiceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	composeProjectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
 func (r *Runner) handleComposeUpStack(ctx context.Context, intent contract.Intent) taskOutcome {
 	var payload contract.ComposeUpStackPayload
 	if err := decodePayload(intent.Payload, &payload); err != nil {
@@ -910,13 +978,9 @@ func (r *Runner) handleComposeUpStack(ctx context.Context, intent contract.Inten
 		return taskOutcome{err: err}
 	}
 
-	args := []string{"compose"}
-	for _, configFile := range payload.ConfigFiles {
-		file := strings.TrimSpace(configFile)
-		if file == "" {
-			continue
-		}
-		args = append(args, "-f", file)
+	args, err := composeBaseArgs(payload.ConfigFiles, payload.ProjectName)
+	if err != nil {
+		return taskOutcome{err: err}
 	}
 	args = append(args, "up")
 	if payload.Build {
@@ -925,7 +989,20 @@ func (r *Runner) handleComposeUpStack(ctx context.Context, intent contract.Inten
 	if payload.ForceRecreate {
 		args = append(args, "--force-recreate")
 	}
+	if payload.NoDeps {
+		args = append(args, "--no-deps")
+	}
 	args = append(args, "-d")
+	for _, service := range payload.Services {
+		service = strings.TrimSpace(service)
+		if service == "" {
+			continue
+		}
+		if !composeServiceNamePattern.MatchString(service) {
+			return taskOutcome{err: fmt.Errorf("invalid compose service name: %q", service)}
+		}
+		args = append(args, service)
+	}
 
 	output, err := r.runDockerCommand(ctx, projectDir, args...)
 	return taskOutcome{
@@ -934,6 +1011,70 @@ func (r *Runner) handleComposeUpStack(ctx context.Context, intent contract.Inten
 	}
 }
 
+func (r *Runner) handleComposeDownStack(ctx context.Context, intent contract.Intent) taskOutcome {
+	var payload contract.ComposeDownStackPayload
+	if err := decodePayload(intent.Payload, &payload); err != nil {
+		return taskOutcome{err: err}
+	}
+	payload.Project = strings.TrimSpace(payload.Project)
+	payload.ProjectDir = strings.TrimSpace(payload.ProjectDir)
+	if payload.Project == "" {
+		return taskOutcome{err: fmt.Errorf("project is required")}
+	}
+
+	projectDir, err := r.resolveProjectDir(payload.Project, payload.ProjectDir)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+
+	args, err := composeBaseArgs(payload.ConfigFiles, payload.ProjectName)
+	if err != nil {
+		return taskOutcome{err: err}
+	}
+	services := make([]string, 0, len(payload.Services))
+	for _, service := range payload.Services {
+		service = strings.TrimSpace(service)
+		if service == "" {
+			continue
+		}
+		if !composeServiceNamePattern.MatchString(service) {
+			return taskOutcome{err: fmt.Errorf("invalid compose service name: %q", service)}
+		}
+		services = append(services, service)
+	}
+	if len(services) > 0 {
+		args = append(args, "rm", "--stop", "--force")
+		args = append(args, services...)
+	} else {
+		// Volumes are kept; a later slot may still mount them.
+		args = append(args, "down", "--remove-orphans")
+	}
+
+	output, err := r.runDockerCommand(ctx, projectDir, args...)
+	return taskOutcome{
+		err:     commandError(err, output, "docker %s", strings.Join(args, " ")),
+		logTail: tailLines(output, 40),
+	}
+}
+
+func composeBaseArgs(configFiles []string, projectName string) ([]string, error) {
+	args := []string{"compose"}
+	for _, configFile := range configFiles {
+		file := strings.TrimSpace(configFile)
+		if file == "" {
+			continue
+		}
+		args = append(args, "-f", file)
+	}
+	if projectName = strings.TrimSpace(projectName); projectName != "" {
+		if !composeProjectNamePattern.MatchString(projectName) {
+			return nil, fmt.Errorf("invalid compose project name: %q", projectName)
+		}
+		args = append(args, "-p", projectName)
+	}
+	return args, nil
+}
+
 func (r *Runner) runCommand(ctx context.Context, dir string, env []string, name string, args ...string) ([]byte, error) {
 	return runExecutorCommand(ctx, r.exec, dir, env, name, args...)
 }
@@ -1291,9 +1432,13 @@ func newCloudflaredTunnelLifecycle(logger *log.Logger) tunnelLifecycle {
 	return &cloudflaredTunnelLifecycle{logger: logger}
 }
 
-func (l *cloudflaredTunnelLifecycle) Restart(ctx context.Context, configPath string) (string, []string, error) {
-	configPath = expandUserPath(configPath)
-	logPath := filepath.Join(filepath.Dir(configPath), "cloudflared-restart-worker.log")
+func (l *cloudflaredTunnelLifecycle) Restart(ctx context.Context, target tunnelTarget) (string, []string, error) {
+	configPath := expandUserPath(target.configPath)
+	metricsAddress := strings.TrimSpace(target.metricsAddress)
+	if metricsAddress == "" {
+		metricsAddress = tunnelMetricsAddress
+	}
+	logPath := tunnelRestartLogPath(configPath)
 	runAs, err := resolveTunnelRunIdentity(configPath, os.Geteuid())
 	if err != nil {
 		return logPath, nil, err
@@ -1312,18 +1457,33 @@ func (l *cloudflaredTunnelLifecycle) Restart(ctx context.Context, configPath str
 	if err := waitForTunnelExit(ctx, configPath, 5*time.Second); err != nil {
 		return logPath, nil, err
 	}
-	if err := startTunnelProcess(configPath, logPath, tunnelMetricsAddress, runAs); err != nil {
+	if err := startTunnelProcess(configPath, logPath, metricsAddress, runAs); err != nil {
 		return logPath, nil, err
 	}
 	if err := waitForTunnelStart(ctx, configPath, 10*time.Second); err != nil {
 		return logPath, readLogTail(logPath, 25), err
 	}
-	if err := waitForTunnelReady(ctx, tunnelReadyURL, tunnelReadyProbeTimeout); err != nil {
+	if err := waitForTunnelReady(ctx, tunnelReadyURL(metricsAddress), tunnelReadyProbeTimeout); err != nil {
 		return logPath, readLogTail(logPath, 25), err
 	}
 	return logPath, readLogTail(logPath, 25), nil
 }
 
+// tunnelRestartLogPath keeps the log of each tunnel apart when several config
+// files share a directory. The usual config.yml keeps the original log name.
+func tunnelRestartLogPath(configPath string) string {
+	dir := filepath.Dir(configPath)
+	stem := strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath))
+	if stem == "" || stem == "config" {
+		return filepath.Join(dir, "cloudflared-restart-worker.log")
+	}
+	return filepath.Join(dir, fmt.Sprintf("cloudflared-restart-worker-%s.log", stem))
+}
+
+func tunnelReadyURL(metricsAddress string) string {
+	return fmt.Sprintf("http://%s/ready", metricsAddress)
+}
+
 func findTunnelPIDs(ctx context.Context, configPath string) ([]int, error) {
 	pattern := fmt.Sprintf("cloudflared.*--config[[:space:]]+%s.*tunnel run", regexp.QuoteMeta(configPath))
 	cmd := exec.CommandContext(ctx, "pgrep", "-f", pattern)
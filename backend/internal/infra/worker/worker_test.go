@@ -66,16 +66,18 @@ func (f *fakeExecutor) Run(_ context.Context, req commandRequest) ([]byte, error
 }
 
 type fakeTunnelLifecycle struct {
-	called     bool
-	configPath string
-	logPath    string
-	logTail    []string
-	err        error
+	called         bool
+	configPath     string
+	metricsAddress string
+	logPath        string
+	logTail        []string
+	err            error
 }
 
-func (f *fakeTunnelLifecycle) Restart(_ context.Context, configPath string) (string, []string, error) {
+func (f *fakeTunnelLifecycle) Restart(_ context.Context, target tunnelTarget) (string, []string, error) {
 	f.called = true
-	f.configPath = configPath
+	f.configPath = target.configPath
+	f.metricsAddress = target.metricsAddress
 	return f.logPath, f.logTail, f.err
 }
 
@@ -660,6 +662,7 @@ func TestProcessOnceHandlesRestartTunnel(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, tunnel.called)
 	require.Equal(t, "/tmp/cloudflared.yml", tunnel.configPath)
+	require.Equal(t, tunnelMetricsAddress, tunnel.metricsAddress)
 
 	result, err := q.ReadResult(context.Background(), intent.IntentID)
 	require.NoError(t, err)
@@ -668,6 +671,78 @@ func TestProcessOnceHandlesRestartTunnel(t *testing.T) {
 	require.Equal(t, []string{"restart ok"}, result.LogTail)
 }
 
+func TestProcessOnceRestartsNamedTunnelOnItsMetricsAddress(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+
+	for _, intent := range []contract.Intent{
+		{
+			IntentID: "intent-restart-edge",
+			Payload:  map[string]any{"config_path": "/etc/cloudflared/edge.yml", "metrics_address": "127.0.0.1:20242"},
+		},
+		{
+			IntentID: "intent-restart-bad-metrics",
+			Payload:  map[string]any{"config_path": "/etc/cloudflared/edge.yml", "metrics_address": "20242"},
+		},
+	} {
+		intent.Version = contract.VersionV1
+		intent.RequestID = "req-" + intent.IntentID
+		intent.TaskType = contract.TaskTypeRestartTunnel
+		intent.CreatedAt = time.Now().UTC().Add(-time.Minute)
+		_, err = q.WriteIntent(context.Background(), intent)
+		require.NoError(t, err)
+	}
+
+	tunnel := &fakeTunnelLifecycle{}
+	r := New(q, 10*time.Millisecond, "", nil)
+	r.tunnel = tunnel
+	require.NoError(t, r.ProcessOnce(context.Background()))
+	require.NoError(t, r.ProcessOnce(context.Background()))
+
+	require.Equal(t, "/etc/cloudflared/edge.yml", tunnel.configPath)
+	require.Equal(t, "127.0.0.1:20242", tunnel.metricsAddress)
+	result, err := q.ReadResult(context.Background(), "intent-restart-edge")
+	require.NoError(t, err)
+	require.Equal(t, contract.StatusSucceeded, result.Status)
+	result, err = q.ReadResult(context.Background(), "intent-restart-bad-metrics")
+	require.NoError(t, err)
+	require.Equal(t, contract.StatusFailed, result.Status)
+
+	require.Equal(t, "/etc/cloudflared/cloudflared-restart-worker.log", tunnelRestartLogPath("/etc/cloudflared/config.yml"))
+	require.Equal(t, "/etc/cloudflared/cloudflared-restart-worker-edge.log", tunnelRestartLogPath("/etc/cloudflared/edge.yml"))
+	require.Equal(t, "http://127.0.0.1:20242/ready", tunnelReadyURL("127.0.0.1:20242"))
+}
+
+func TestProcessOnceRejectsNonLoopbackTunnelMetricsAddress(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+	_, err = q.WriteIntent(context.Background(), contract.Intent{
+		Version:   contract.VersionV1,
+		IntentID:  "intent-restart-public-metrics",
+		RequestID: "req-restart-public-metrics",
+		TaskType:  contract.TaskTypeRestartTunnel,
+		Payload:   map[string]any{"config_path": "/etc/cloudflared/edge.yml", "metrics_address": "0.0.0.0:20242"},
+		CreatedAt: time.Now().UTC().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	tunnel := &fakeTunnelLifecycle{}
+	r := New(q, 10*time.Millisecond, "", nil)
+	r.tunnel = tunnel
+	require.NoError(t, r.ProcessOnce(context.Background()))
+
+	require.Empty(t, tunnel.configPath)
+	result, err := q.ReadResult(context.Background(), "intent-restart-public-metrics")
+	require.NoError(t, err)
+	require.Equal(t, contract.StatusFailed, result.Status)
+	require.NotNil(t, result.Error)
+	require.Contains(t, result.Error.Message, "loopback")
+}
+
 func TestProcessOnceHandlesComposeFailure(t *testing.T) {
 	t.Parallel()
 
@@ -724,6 +799,117 @@ func TestProcessOnceHandlesComposeFailure(t *testing.T) {
 	require.Contains(t, result.Error.Message, "docker compose up --build --force-recreate -d failed")
 }
 
+func TestProcessOnceComposeUpTargetsServices(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+
+	templatesDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(templatesDir+"/demo", 0o755))
+
+	intent := contract.Intent{
+		Version:   contract.VersionV1,
+		IntentID:  "intent-compose-services",
+		RequestID: "req-compose-services",
+		TaskType:  contract.TaskTypeComposeUpStack,
+		Payload: map[string]any{
+			"project":        "demo",
+			"force_recreate": true,
+			"no_deps":        true,
+			"services":       []string{"api", "worker"},
+		},
+		CreatedAt: time.Now().UTC().Add(-time.Minute),
+	}
+	_, err = q.WriteIntent(context.Background(), intent)
+	require.NoError(t, err)
+
+	exec := &fakeExecutor{output: []byte("ok")}
+	r := New(q, 10*time.Millisecond, templatesDir, nil)
+	r.dockerTmpDir = t.TempDir()
+	r.exec = exec
+
+	require.NoError(t, r.ProcessOnce(context.Background()))
+	require.Len(t, exec.calls, 1)
+	require.Equal(t, []string{"compose", "up", "--force-recreate", "--no-deps", "-d", "api", "worker"}, exec.calls[0].args)
+
+	bad := intent
+	bad.IntentID = "intent-compose-bad-service"
+	bad.Payload = map[string]any{"project": "demo", "services": []string{"--remove-orphans"}}
+	_, err = q.WriteIntent(context.Background(), bad)
+	require.NoError(t, err)
+
+	require.NoError(t, r.ProcessOnce(context.Background()))
+	require.Len(t, exec.calls, 1)
+	result, err := q.ReadResult(context.Background(), bad.IntentID)
+	require.NoError(t, err)
+	require.Equal(t, contract.StatusFailed, result.Status)
+}
+
+func TestProcessOnceComposeDownHandlesSlotsAndServices(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+
+	templatesDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(templatesDir+"/demo", 0o755))
+
+	intents := []contract.Intent{
+		{
+			Version:   contract.VersionV1,
+			IntentID:  "intent-compose-down-slot",
+			RequestID: "req-compose-down-slot",
+			TaskType:  contract.TaskTypeComposeDownStack,
+			Payload: map[string]any{
+				"project":      "demo",
+				"config_files": []string{"docker-compose.green.yml"},
+				"project_name": "demo-green",
+			},
+			CreatedAt: time.Now().UTC().Add(-2 * time.Minute),
+		},
+		{
+			Version:   contract.VersionV1,
+			IntentID:  "intent-compose-down-services",
+			RequestID: "req-compose-down-services",
+			TaskType:  contract.TaskTypeComposeDownStack,
+			Payload: map[string]any{
+				"project":  "demo",
+				"services": []string{"web", "worker"},
+			},
+			CreatedAt: time.Now().UTC().Add(-time.Minute),
+		},
+		{
+			Version:   contract.VersionV1,
+			IntentID:  "intent-compose-down-bad-name",
+			RequestID: "req-compose-down-bad-name",
+			TaskType:  contract.TaskTypeComposeDownStack,
+			Payload: map[string]any{
+				"project":      "demo",
+				"project_name": "--all",
+			},
+			CreatedAt: time.Now().UTC(),
+		},
+	}
+	exec := &fakeExecutor{output: []byte("ok")}
+	r := New(q, 10*time.Millisecond, templatesDir, nil)
+	r.dockerTmpDir = t.TempDir()
+	r.exec = exec
+
+	for _, intent := range intents {
+		_, err = q.WriteIntent(context.Background(), intent)
+		require.NoError(t, err)
+		require.NoError(t, r.ProcessOnce(context.Background()))
+	}
+	require.Len(t, exec.calls, 2)
+	require.Equal(t, []string{"compose", "-f", "docker-compose.green.yml", "-p", "demo-green", "down", "--remove-orphans"}, exec.calls[0].args)
+	require.Equal(t, []string{"compose", "rm", "--stop", "--force", "web", "worker"}, exec.calls[1].args)
+
+	result, err := q.ReadResult(context.Background(), "intent-compose-down-bad-name")
+	require.NoError(t, err)
+	require.Equal(t, contract.StatusFailed, result.Status)
+}
+
 func TestProcessOnceSkipsUnsupportedTask(t *testing.T) {
 	t.Parallel()
 
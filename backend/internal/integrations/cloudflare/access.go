@@ -0,0 +1,356 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrMissingAccessRules       = errors.New("at least one Access rule (email, email domain, or GitHub organization) is required")
+	ErrAccessAppNotManaged      = errors.New("access application is not managed by gungnr")
+	ErrMissingGitHubAccessLogin = errors.New("GitHub organization rules require a GitHub login method in Cloudflare Zero Trust")
+)
+
+// AccessAppNamePrefix marks the Access applications gungnr created. Apps
+// without it belong to someone else and are never updated or deleted.
+const AccessAppNamePrefix = "gungnr: "
+
+const (
+	accessPolicyName      = "gungnr allow"
+	accessSessionDuration = "24h"
+)
+
+// AccessRules are the identities allowed through an Access application. A
+// visitor matching any rule is let in.
+type AccessRules struct {
+	Emails       []string `json:"emails"`
+	EmailDomains []string `json:"emailDomains"`
+	GitHubOrgs   []string `json:"githubOrgs"`
+}
+
+func (r AccessRules) Empty() bool {
+	return len(r.Emails) == 0 && len(r.EmailDomains) == 0 && len(r.GitHubOrgs) == 0
+}
+
+// AccessApplication is the self-hosted Access application guarding a
+// hostname. Rules are read from the managed allow policy and are empty for
+// applications gungnr does not manage.
+type AccessApplication struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Domain   string      `json:"domain"`
+	AUD      string      `json:"aud"`
+	Managed  bool        `json:"managed"`
+	PolicyID string      `json:"policyId,omitempty"`
+	Rules    AccessRules `json:"rules"`
+}
+
+type AccessDeleteResult struct {
+	Deleted    bool
+	SkipReason string
+}
+
+type accessApp struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+	AUD    string `json:"aud"`
+	Type   string `json:"type"`
+}
+
+type accessAppRequest struct {
+	Name               string `json:"name"`
+	Domain             string `json:"domain"`
+	Type               string `json:"type"`
+	SessionDuration    string `json:"session_duration"`
+	AppLauncherVisible bool   `json:"app_launcher_visible"`
+}
+
+type accessRule map[string]map[string]any
+
+type accessPolicy struct {
+	ID         string       `json:"id,omitempty"`
+	Name       string       `json:"name"`
+	Decision   string       `json:"decision"`
+	Include    []accessRule `json:"include"`
+	Precedence int          `json:"precedence,omitempty"`
+}
+
+type accessIdentityProvider struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AccessApplicationName is the name gungnr gives the application for hostname.
+func AccessApplicationName(hostname string) string {
+	return AccessAppNamePrefix + strings.ToLower(strings.TrimSpace(hostname))
+}
+
+// AccessApplicationForHostname returns the Access application whose domain is
+// hostname, or nil when the hostname is not behind Access.
+func (c *Client) AccessApplicationForHostname(ctx context.Context, hostname string) (*AccessApplication, error) {
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+	if hostname == "" {
+		return nil, ErrMissingHostname
+	}
+	if err := c.ensureAuth(); err != nil {
+		return nil, err
+	}
+	app, err := c.findAccessApp(ctx, hostname)
+	if err != nil || app == nil {
+		return nil, err
+	}
+	result := describeAccessApp(*app)
+	if !result.Managed {
+		return &result, nil
+	}
+	policy, err := c.findManagedAccessPolicy(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		result.PolicyID = policy.ID
+		result.Rules = accessRulesFromInclude(policy.Include)
+	}
+	return &result, nil
+}
+
+// EnsureAccessApplication puts hostname behind a self-hosted Access
+// application allowing rules, creating the application on first use and
+// replacing the rules of its managed policy after that. A hostname already
+// guarded by an application gungnr did not create is left untouched.
+func (c *Client) EnsureAccessApplication(ctx context.Context, hostname string, rules AccessRules) (AccessApplication, error) {
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+	if hostname == "" {
+		return AccessApplication{}, ErrMissingHostname
+	}
+	if err := c.ensureAuth(); err != nil {
+		return AccessApplication{}, err
+	}
+	rules = normalizeAccessRules(rules)
+	if rules.Empty() {
+		return AccessApplication{}, ErrMissingAccessRules
+	}
+
+	include, err := c.accessInclude(ctx, rules)
+	if err != nil {
+		return AccessApplication{}, err
+	}
+
+	app, err := c.findAccessApp(ctx, hostname)
+	if err != nil {
+		return AccessApplication{}, err
+	}
+	if app != nil && !isManagedAccessApp(*app) {
+		return AccessApplication{}, fmt.Errorf("%s is guarded by Access application %q: %w", hostname, app.Name, ErrAccessAppNotManaged)
+	}
+	if app == nil {
+		created := accessApp{}
+		path := fmt.Sprintf("/accounts/%s/access/apps", c.cfg.CloudflareAccountID)
+		if err := c.do(ctx, http.MethodPost, path, accessAppRequest{
+			Name:            AccessApplicationName(hostname),
+			Domain:          hostname,
+			Type:            "self_hosted",
+			SessionDuration: accessSessionDuration,
+		}, &created); err != nil {
+			return AccessApplication{}, fmt.Errorf("create access application: %w", err)
+		}
+		app = &created
+	}
+
+	policy := accessPolicy{
+		Name:       accessPolicyName,
+		Decision:   "allow",
+		Include:    include,
+		Precedence: 1,
+	}
+	existing, err := c.findManagedAccessPolicy(ctx, app.ID)
+	if err != nil {
+		return AccessApplication{}, err
+	}
+	saved := accessPolicy{}
+	if existing == nil {
+		path := fmt.Sprintf("/accounts/%s/access/apps/%s/policies", c.cfg.CloudflareAccountID, app.ID)
+		if err := c.do(ctx, http.MethodPost, path, policy, &saved); err != nil {
+			return AccessApplication{}, fmt.Errorf("create access policy: %w", err)
+		}
+	} else {
+		path := fmt.Sprintf("/accounts/%s/access/apps/%s/policies/%s", c.cfg.CloudflareAccountID, app.ID, existing.ID)
+		if err := c.do(ctx, http.MethodPut, path, policy, &saved); err != nil {
+			return AccessApplication{}, fmt.Errorf("update access policy: %w", err)
+		}
+	}
+
+	result := describeAccessApp(*app)
+	result.PolicyID = saved.ID
+	result.Rules = rules
+	return result, nil
+}
+
+// DeleteAccessApplication removes the Access application appID guarding
+// hostname. It is skipped when the application is gone, now guards another
+// domain, or was not created by gungnr.
+func (c *Client) DeleteAccessApplication(ctx context.Context, appID, hostname string) (AccessDeleteResult, error) {
+	if err := c.ensureAuth(); err != nil {
+		return AccessDeleteResult{}, err
+	}
+	appID = strings.TrimSpace(appID)
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+	switch {
+	case appID == "":
+		return AccessDeleteResult{SkipReason: "application id metadata is missing"}, nil
+	case hostname == "":
+		return AccessDeleteResult{SkipReason: "hostname metadata is missing"}, nil
+	}
+
+	app, err := c.findAccessApp(ctx, hostname)
+	if err != nil {
+		return AccessDeleteResult{}, err
+	}
+	switch {
+	case app == nil:
+		return AccessDeleteResult{SkipReason: "it no longer exists"}, nil
+	case app.ID != appID:
+		return AccessDeleteResult{SkipReason: fmt.Sprintf("%s is now guarded by application %s", hostname, app.ID)}, nil
+	case !isManagedAccessApp(*app):
+		return AccessDeleteResult{SkipReason: fmt.Sprintf("application %q is not managed by gungnr", app.Name)}, nil
+	}
+
+	path := fmt.Sprintf("/accounts/%s/access/apps/%s", c.cfg.CloudflareAccountID, appID)
+	if err := c.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return AccessDeleteResult{}, err
+	}
+	return AccessDeleteResult{Deleted: true}, nil
+}
+
+func (c *Client) findAccessApp(ctx context.Context, hostname string) (*accessApp, error) {
+	path := fmt.Sprintf("/accounts/%s/access/apps?domain=%s", c.cfg.CloudflareAccountID, url.QueryEscape(hostname))
+	var apps []accessApp
+	if err := c.do(ctx, http.MethodGet, path, nil, &apps); err != nil {
+		return nil, fmt.Errorf("list access applications: %w", err)
+	}
+	for i := range apps {
+		if strings.EqualFold(strings.TrimSpace(apps[i].Domain), hostname) {
+			return &apps[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Client) findManagedAccessPolicy(ctx context.Context, appID string) (*accessPolicy, error) {
+	path := fmt.Sprintf("/accounts/%s/access/apps/%s/policies", c.cfg.CloudflareAccountID, appID)
+	var policies []accessPolicy
+	if err := c.do(ctx, http.MethodGet, path, nil, &policies); err != nil {
+		return nil, fmt.Errorf("list access policies: %w", err)
+	}
+	for i := range policies {
+		if policies[i].Name == accessPolicyName {
+			return &policies[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// accessInclude builds the include list of the allow policy. GitHub
+// organization rules reference the account's GitHub login method, which
+// has to exist already.
+func (c *Client) accessInclude(ctx context.Context, rules AccessRules) ([]accessRule, error) {
+	include := make([]accessRule, 0, len(rules.Emails)+len(rules.EmailDomains)+len(rules.GitHubOrgs))
+	for _, email := range rules.Emails {
+		include = append(include, accessRule{"email": {"email": email}})
+	}
+	for _, domain := range rules.EmailDomains {
+		include = append(include, accessRule{"email_domain": {"domain": domain}})
+	}
+	if len(rules.GitHubOrgs) == 0 {
+		return include, nil
+	}
+
+	path := fmt.Sprintf("/accounts/%s/access/identity_providers", c.cfg.CloudflareAccountID)
+	var providers []accessIdentityProvider
+	if err := c.do(ctx, http.MethodGet, path, nil, &providers); err != nil {
+		return nil, fmt.Errorf("list access login methods: %w", err)
+	}
+	providerID := ""
+	for _, provider := range providers {
+		if strings.EqualFold(strings.TrimSpace(provider.Type), "github") {
+			providerID = provider.ID
+			break
+		}
+	}
+	if providerID == "" {
+		return nil, ErrMissingGitHubAccessLogin
+	}
+	for _, org := range rules.GitHubOrgs {
+		include = append(include, accessRule{"github-organization": {"name": org, "identity_provider_id": providerID}})
+	}
+	return include, nil
+}
+
+func describeAccessApp(app accessApp) AccessApplication {
+	return AccessApplication{
+		ID:      app.ID,
+		Name:    app.Name,
+		Domain:  strings.ToLower(strings.TrimSpace(app.Domain)),
+		AUD:     app.AUD,
+		Managed: isManagedAccessApp(app),
+		Rules:   AccessRules{Emails: []string{}, EmailDomains: []string{}, GitHubOrgs: []string{}},
+	}
+}
+
+func isManagedAccessApp(app accessApp) bool {
+	return strings.HasPrefix(app.Name, AccessAppNamePrefix)
+}
+
+func accessRulesFromInclude(include []accessRule) AccessRules {
+	rules := AccessRules{}
+	for _, rule := range include {
+		if value, ok := rule["email"]["email"].(string); ok {
+			rules.Emails = append(rules.Emails, value)
+		}
+		if value, ok := rule["email_domain"]["domain"].(string); ok {
+			rules.EmailDomains = append(rules.EmailDomains, value)
+		}
+		if value, ok := rule["github-organization"]["name"].(string); ok {
+			rules.GitHubOrgs = append(rules.GitHubOrgs, value)
+		}
+	}
+	return normalizeAccessRules(rules)
+}
+
+// normalizeAccessRules trims, lowercases, dedupes, and sorts each rule list.
+// GitHub organization names keep their case.
+func normalizeAccessRules(rules AccessRules) AccessRules {
+	return AccessRules{
+		Emails:       normalizeAccessValues(rules.Emails, true),
+		EmailDomains: normalizeAccessValues(rules.EmailDomains, true),
+		GitHubOrgs:   normalizeAccessValues(rules.GitHubOrgs, false),
+	}
+}
+
+func normalizeAccessValues(values []string, lower bool) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if lower {
+			value = strings.ToLower(value)
+		}
+		if value == "" {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
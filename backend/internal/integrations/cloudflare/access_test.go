@@ -0,0 +1,286 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+)
+
+// fakeAccessAPI is an in-memory stand-in for the Cloudflare Access endpoints
+// of one account.
+type fakeAccessAPI struct {
+	mu        sync.Mutex
+	apps      map[string]accessApp
+	policies  map[string][]accessPolicy
+	providers []accessIdentityProvider
+	nextID    int
+	requests  []string
+}
+
+func newFakeAccessAPI() *fakeAccessAPI {
+	return &fakeAccessAPI{
+		apps:     map[string]accessApp{},
+		policies: map[string][]accessPolicy{},
+	}
+}
+
+func (f *fakeAccessAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	const prefix = "/accounts/acct-1/access/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		writeFakeAccessError(w, http.StatusNotFound, "unknown path")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "identity_providers" && r.Method == http.MethodGet:
+		writeFakeAccessResult(w, f.providers)
+	case len(parts) == 1 && parts[0] == "apps" && r.Method == http.MethodGet:
+		domain := r.URL.Query().Get("domain")
+		apps := []accessApp{}
+		for _, app := range f.apps {
+			if domain == "" || app.Domain == domain {
+				apps = append(apps, app)
+			}
+		}
+		writeFakeAccessResult(w, apps)
+	case len(parts) == 1 && parts[0] == "apps" && r.Method == http.MethodPost:
+		var req accessAppRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeFakeAccessError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		f.nextID++
+		app := accessApp{ID: fmt.Sprintf("app-%d", f.nextID), Name: req.Name, Domain: req.Domain, AUD: fmt.Sprintf("aud-%d", f.nextID), Type: req.Type}
+		f.apps[app.ID] = app
+		writeFakeAccessResult(w, app)
+	case len(parts) == 2 && parts[0] == "apps" && r.Method == http.MethodDelete:
+		if _, ok := f.apps[parts[1]]; !ok {
+			writeFakeAccessError(w, http.StatusNotFound, "application not found")
+			return
+		}
+		delete(f.apps, parts[1])
+		delete(f.policies, parts[1])
+		writeFakeAccessResult(w, map[string]string{"id": parts[1]})
+	case len(parts) >= 3 && parts[0] == "apps" && parts[2] == "policies":
+		f.servePolicies(w, r, parts[1], parts[3:])
+	default:
+		writeFakeAccessError(w, http.StatusNotFound, "unknown route")
+	}
+}
+
+func (f *fakeAccessAPI) servePolicies(w http.ResponseWriter, r *http.Request, appID string, rest []string) {
+	if _, ok := f.apps[appID]; !ok {
+		writeFakeAccessError(w, http.StatusNotFound, "application not found")
+		return
+	}
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		policies := f.policies[appID]
+		if policies == nil {
+			policies = []accessPolicy{}
+		}
+		writeFakeAccessResult(w, policies)
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		var policy accessPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			writeFakeAccessError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		f.nextID++
+		policy.ID = fmt.Sprintf("policy-%d", f.nextID)
+		f.policies[appID] = append(f.policies[appID], policy)
+		writeFakeAccessResult(w, policy)
+	case len(rest) == 1 && r.Method == http.MethodPut:
+		var policy accessPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			writeFakeAccessError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for i := range f.policies[appID] {
+			if f.policies[appID][i].ID == rest[0] {
+				policy.ID = rest[0]
+				f.policies[appID][i] = policy
+				writeFakeAccessResult(w, policy)
+				return
+			}
+		}
+		writeFakeAccessError(w, http.StatusNotFound, "policy not found")
+	default:
+		writeFakeAccessError(w, http.StatusNotFound, "unknown policy route")
+	}
+}
+
+func (f *fakeAccessAPI) countRequests(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, request := range f.requests {
+		if strings.HasPrefix(request, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func writeFakeAccessResult(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "errors": []any{}, "result": result})
+}
+
+func writeFakeAccessError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success": false,
+		"errors":  []map[string]any{{"code": 7003, "message": message}},
+		"result":  nil,
+	})
+}
+
+func newTestAccessClient(t *testing.T, api *fakeAccessAPI) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return &Client{
+		cfg: config.Config{
+			CloudflareAPIToken:  "token-1",
+			CloudflareAccountID: "acct-1",
+		},
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+}
+
+func TestEnsureAccessApplicationCreatesAppAndPolicy(t *testing.T) {
+	t.Parallel()
+
+	api := newFakeAccessAPI()
+	api.providers = []accessIdentityProvider{
+		{ID: "idp-otp", Name: "One-time PIN", Type: "onetimepin"},
+		{ID: "idp-gh", Name: "GitHub", Type: "github"},
+	}
+	client := newTestAccessClient(t, api)
+
+	app, err := client.EnsureAccessApplication(context.Background(), "App.Example.com", AccessRules{
+		Emails:       []string{" Ops@Example.com ", "ops@example.com"},
+		EmailDomains: []string{"example.com"},
+		GitHubOrgs:   []string{"Acme"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "app.example.com", app.Domain)
+	require.Equal(t, "gungnr: app.example.com", app.Name)
+	require.True(t, app.Managed)
+	require.Equal(t, []string{"ops@example.com"}, app.Rules.Emails)
+
+	policies := api.policies[app.ID]
+	require.Len(t, policies, 1)
+	require.Equal(t, "allow", policies[0].Decision)
+	require.Len(t, policies[0].Include, 3)
+	require.Equal(t, "idp-gh", policies[0].Include[2]["github-organization"]["identity_provider_id"])
+	require.Equal(t, "Acme", policies[0].Include[2]["github-organization"]["name"])
+
+	read, err := client.AccessApplicationForHostname(context.Background(), "app.example.com")
+	require.NoError(t, err)
+	require.NotNil(t, read)
+	require.Equal(t, app.ID, read.ID)
+	require.Equal(t, AccessRules{
+		Emails:       []string{"ops@example.com"},
+		EmailDomains: []string{"example.com"},
+		GitHubOrgs:   []string{"Acme"},
+	}, read.Rules)
+}
+
+func TestEnsureAccessApplicationUpdatesExistingPolicy(t *testing.T) {
+	t.Parallel()
+
+	api := newFakeAccessAPI()
+	client := newTestAccessClient(t, api)
+
+	first, err := client.EnsureAccessApplication(context.Background(), "app.example.com", AccessRules{Emails: []string{"a@example.com"}})
+	require.NoError(t, err)
+	second, err := client.EnsureAccessApplication(context.Background(), "app.example.com", AccessRules{EmailDomains: []string{"example.org"}})
+	require.NoError(t, err)
+
+	require.Equal(t, first.ID, second.ID)
+	require.Equal(t, first.PolicyID, second.PolicyID)
+	require.Len(t, api.apps, 1)
+	require.Equal(t, 1, api.countRequests(http.MethodPost+" /accounts/acct-1/access/apps/"))
+	require.Equal(t, 1, api.countRequests(http.MethodPut+" /accounts/acct-1/access/apps/"))
+
+	read, err := client.AccessApplicationForHostname(context.Background(), "app.example.com")
+	require.NoError(t, err)
+	require.Empty(t, read.Rules.Emails)
+	require.Equal(t, []string{"example.org"}, read.Rules.EmailDomains)
+}
+
+func TestEnsureAccessApplicationRefusesUnmanagedApp(t *testing.T) {
+	t.Parallel()
+
+	api := newFakeAccessAPI()
+	api.apps["app-9"] = accessApp{ID: "app-9", Name: "Team wiki", Domain: "app.example.com"}
+	client := newTestAccessClient(t, api)
+
+	_, err := client.EnsureAccessApplication(context.Background(), "app.example.com", AccessRules{Emails: []string{"a@example.com"}})
+	require.ErrorIs(t, err, ErrAccessAppNotManaged)
+	require.Empty(t, api.policies["app-9"])
+}
+
+func TestEnsureAccessApplicationRequiresGitHubLoginForOrgRules(t *testing.T) {
+	t.Parallel()
+
+	api := newFakeAccessAPI()
+	client := newTestAccessClient(t, api)
+
+	_, err := client.EnsureAccessApplication(context.Background(), "app.example.com", AccessRules{GitHubOrgs: []string{"acme"}})
+	require.ErrorIs(t, err, ErrMissingGitHubAccessLogin)
+	require.Empty(t, api.apps)
+
+	_, err = client.EnsureAccessApplication(context.Background(), "app.example.com", AccessRules{})
+	require.ErrorIs(t, err, ErrMissingAccessRules)
+}
+
+func TestDeleteAccessApplicationOnlyDeletesManagedApps(t *testing.T) {
+	t.Parallel()
+
+	api := newFakeAccessAPI()
+	api.apps["app-9"] = accessApp{ID: "app-9", Name: "Team wiki", Domain: "wiki.example.com"}
+	client := newTestAccessClient(t, api)
+
+	managed, err := client.EnsureAccessApplication(context.Background(), "app.example.com", AccessRules{Emails: []string{"a@example.com"}})
+	require.NoError(t, err)
+
+	result, err := client.DeleteAccessApplication(context.Background(), "app-9", "wiki.example.com")
+	require.NoError(t, err)
+	require.False(t, result.Deleted)
+	require.Contains(t, result.SkipReason, "not managed by gungnr")
+
+	result, err = client.DeleteAccessApplication(context.Background(), "app-9", "app.example.com")
+	require.NoError(t, err)
+	require.False(t, result.Deleted)
+	require.Contains(t, result.SkipReason, "now guarded by application "+managed.ID)
+
+	result, err = client.DeleteAccessApplication(context.Background(), managed.ID, "app.example.com")
+	require.NoError(t, err)
+	require.True(t, result.Deleted)
+	require.NotContains(t, api.apps, managed.ID)
+	require.Contains(t, api.apps, "app-9")
+
+	result, err = client.DeleteAccessApplication(context.Background(), managed.ID, "app.example.com")
+	require.NoError(t, err)
+	require.False(t, result.Deleted)
+	require.Equal(t, "it no longer exists", result.SkipReason)
+}
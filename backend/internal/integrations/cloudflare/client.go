@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -29,9 +30,13 @@ var (
 
 const apiBaseURL = "https://api.cloudflare.com/client/v4"
 
+const dnsRecordsPageSize = 100
+
 type Client struct {
 	cfg    config.Config
 	client *http.Client
+	// baseURL overrides apiBaseURL; tests point it at a local stand-in.
+	baseURL string
 }
 
 type TunnelStatus struct {
@@ -58,11 +63,16 @@ type ZoneAccount struct {
 }
 
 type DNSRecord struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Name    string `json:"name"`
-	Content string `json:"content"`
-	Proxied bool   `json:"proxied"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	Proxied  bool   `json:"proxied"`
+	TTL      int    `json:"ttl,omitempty"`
+	Priority *int   `json:"priority,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	// Managed is set when the comment carries ManagedDNSCommentPrefix.
+	Managed bool `json:"managed"`
 }
 
 type DNSDeleteResult struct {
@@ -70,9 +80,13 @@ type DNSDeleteResult struct {
 	SkipReason string
 }
 
+// IngressRule is a hostname rule from the tunnel ingress list. Path holds the
+// cloudflared path regex as written in the config; empty matches every path.
 type IngressRule struct {
-	Hostname string `json:"hostname"`
-	Service  string `json:"service"`
+	Hostname string         `json:"hostname"`
+	Path     string         `json:"path,omitempty"`
+	Service  string         `json:"service"`
+	Origin   *IngressOrigin `json:"origin,omitempty"`
 }
 
 func NewClient(cfg config.Config) *Client {
@@ -163,17 +177,42 @@ func (c *Client) ListDNSRecordsByName(ctx context.Context, hostname, zoneID stri
 
 	result := make([]DNSRecord, 0, len(records))
 	for _, record := range records {
-		result = append(result, DNSRecord{
-			ID:      record.ID,
-			Type:    record.Type,
-			Name:    record.Name,
-			Content: record.Content,
-			Proxied: record.Proxied,
-		})
+		result = append(result, record.public())
 	}
 	return result, nil
 }
 
+// ListZoneDNSRecords returns every DNS record in zoneID, following the API's
+// pagination.
+func (c *Client) ListZoneDNSRecords(ctx context.Context, zoneID string) ([]DNSRecord, error) {
+	if err := c.ensureToken(); err != nil {
+		return nil, err
+	}
+	zoneID = strings.TrimSpace(zoneID)
+	if zoneID == "" {
+		return nil, ErrMissingZoneID
+	}
+
+	result := make([]DNSRecord, 0)
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("per_page", fmt.Sprintf("%d", dnsRecordsPageSize))
+		query.Set("page", fmt.Sprintf("%d", page))
+		path := fmt.Sprintf("/zones/%s/dns_records?%s", zoneID, query.Encode())
+
+		var records []dnsRecord
+		if err := c.do(ctx, http.MethodGet, path, nil, &records); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			result = append(result, record.public())
+		}
+		if len(records) < dnsRecordsPageSize {
+			return result, nil
+		}
+	}
+}
+
 func (c *Client) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
 	if err := c.ensureToken(); err != nil {
 		return err
@@ -233,6 +272,18 @@ func (c *Client) DeleteTunnelCNAMERecord(ctx context.Context, zoneID, recordID,
 }
 
 func (c *Client) UpdateIngress(ctx context.Context, hostname string, port int) error {
+	return c.UpdateIngressRoute(ctx, hostname, "", port)
+}
+
+// UpdateIngressRoute points the hostname/path rule at localhost:port. path is a
+// cloudflared path regex, usually built with IngressPathPattern.
+func (c *Client) UpdateIngressRoute(ctx context.Context, hostname, path string, port int) error {
+	return c.UpdateIngressRouteOptions(ctx, hostname, path, port, IngressRouteOptions{})
+}
+
+// UpdateIngressRouteOptions is UpdateIngressRoute with a service scheme and
+// originRequest options for the rule.
+func (c *Client) UpdateIngressRouteOptions(ctx context.Context, hostname, path string, port int, opts IngressRouteOptions) error {
 	if strings.TrimSpace(hostname) == "" {
 		return ErrMissingHostname
 	}
@@ -257,8 +308,7 @@ func (c *Client) UpdateIngress(ctx context.Context, hostname string, port int) e
 		return err
 	}
 
-	service := fmt.Sprintf("http://localhost:%d", port)
-	config.Ingress = ensureIngressRule(config.Ingress, hostname, service)
+	config.Ingress = ensureIngressRule(config.Ingress, hostname, path, ingressTarget{port: port, opts: opts})
 
 	return c.updateTunnelConfig(ctx, tunnelID, config)
 }
@@ -540,11 +590,28 @@ func (c *Client) updateTunnelConfig(ctx context.Context, tunnelID string, config
 }
 
 type dnsRecord struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Name    string `json:"name"`
-	Content string `json:"content"`
-	Proxied bool   `json:"proxied"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	Proxied  bool   `json:"proxied"`
+	TTL      int    `json:"ttl"`
+	Priority *int   `json:"priority"`
+	Comment  string `json:"comment"`
+}
+
+func (r dnsRecord) public() DNSRecord {
+	return DNSRecord{
+		ID:       r.ID,
+		Type:     r.Type,
+		Name:     r.Name,
+		Content:  r.Content,
+		Proxied:  r.Proxied,
+		TTL:      r.TTL,
+		Priority: r.Priority,
+		Comment:  r.Comment,
+		Managed:  IsManagedDNSComment(r.Comment),
+	}
 }
 
 type dnsRecordRequest struct {
@@ -645,7 +712,11 @@ func (c *Client) do(ctx context.Context, method, path string, payload any, out a
 		body = nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, apiBaseURL+path, bytes.NewReader(body))
+	baseURL := apiBaseURL
+	if c.baseURL != "" {
+		baseURL = c.baseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
@@ -800,6 +871,7 @@ func ingressRulesFromConfig(ingress []map[string]any) []IngressRule {
 	rules := make([]IngressRule, 0, len(ingress))
 	for _, rule := range ingress {
 		hostname, _ := rule["hostname"].(string)
+		path, _ := rule["path"].(string)
 		service, _ := rule["service"].(string)
 		hostname = strings.TrimSpace(hostname)
 		if hostname == "" {
@@ -807,7 +879,9 @@ func ingressRulesFromConfig(ingress []map[string]any) []IngressRule {
 		}
 		rules = append(rules, IngressRule{
 			Hostname: strings.ToLower(hostname),
+			Path:     strings.TrimSpace(path),
 			Service:  strings.TrimSpace(service),
+			Origin:   ingressOriginFromRule(rule),
 		})
 	}
 	return rules
@@ -832,14 +906,14 @@ func normalizeIngressRuleTargets(targets []IngressRule) map[string]int {
 		if hostname == "" {
 			continue
 		}
-		key := ingressRuleTargetKey(hostname, target.Service)
+		key := ingressRuleTargetKey(hostname, target.Path, target.Service)
 		result[key]++
 	}
 	return result
 }
 
-func ingressRuleTargetKey(hostname, service string) string {
-	return strings.ToLower(strings.TrimSpace(hostname)) + "\x00" + strings.TrimSpace(service)
+func ingressRuleTargetKey(hostname, path, service string) string {
+	return strings.ToLower(strings.TrimSpace(hostname)) + "\x00" + strings.TrimSpace(path) + "\x00" + strings.TrimSpace(service)
 }
 
 func removeIngressRulesByHostname(existing []map[string]any, targets map[string]struct{}) ([]IngressRule, []map[string]any) {
@@ -860,10 +934,13 @@ func removeIngressRulesByHostname(existing []map[string]any, targets map[string]
 		}
 
 		if _, ok := targets[normalizedHostname]; ok {
+			path, _ := rule["path"].(string)
 			service, _ := rule["service"].(string)
 			removed = append(removed, IngressRule{
 				Hostname: normalizedHostname,
+				Path:     strings.TrimSpace(path),
 				Service:  strings.TrimSpace(service),
+				Origin:   ingressOriginFromRule(rule),
 			})
 			continue
 		}
@@ -897,13 +974,16 @@ func removeIngressRulesByExactTarget(existing []map[string]any, targets map[stri
 			continue
 		}
 
+		path, _ := rule["path"].(string)
 		service, _ := rule["service"].(string)
-		key := ingressRuleTargetKey(normalizedHostname, service)
+		key := ingressRuleTargetKey(normalizedHostname, path, service)
 		if remaining[key] > 0 {
 			remaining[key]--
 			removed = append(removed, IngressRule{
 				Hostname: normalizedHostname,
+				Path:     strings.TrimSpace(path),
 				Service:  strings.TrimSpace(service),
+				Origin:   ingressOriginFromRule(rule),
 			})
 			continue
 		}
@@ -934,10 +1014,35 @@ func ensureCatchAllRule(existing []map[string]any) []map[string]any {
 	return rules
 }
 
-func ensureIngressRule(existing []map[string]any, hostname, service string) []map[string]any {
+// ingressTarget is what ensureIngressRule points a rule at. local selects the
+// local YAML encoding of originRequest options.
+type ingressTarget struct {
+	port  int
+	opts  IngressRouteOptions
+	local bool
+}
+
+// apply sets the service and, when opts carries them, the origin options of
+// rule.
+func (t ingressTarget) apply(rule map[string]any) {
+	current, _ := rule["service"].(string)
+	rule["service"] = ingressService(t.opts.Scheme, current, t.port)
+	if t.opts.Origin != nil {
+		applyIngressOrigin(rule, *t.opts.Origin, t.local)
+	}
+}
+
+// ensureIngressRule points the rule matching hostname and path at target,
+// adding it when missing. cloudflared stops at the first matching rule, so a
+// new path rule goes ahead of the hostname's first rule with a shorter path,
+// or without one: "/api/v2" lands before "/api", which would otherwise match
+// it first.
+func ensureIngressRule(existing []map[string]any, hostname, path string, target ingressTarget) []map[string]any {
 	var rules []map[string]any
 	var catchAll map[string]any
 	found := false
+	path = strings.TrimSpace(path)
+	insertAt := -1
 
 	for _, rule := range existing {
 		if isCatchAll(rule) {
@@ -947,18 +1052,32 @@ func ensureIngressRule(existing []map[string]any, hostname, service string) []ma
 			continue
 		}
 		if host, ok := rule["hostname"].(string); ok && strings.EqualFold(host, hostname) {
-			rule["service"] = service
-			found = true
+			rulePath, _ := rule["path"].(string)
+			rulePath = strings.TrimSpace(rulePath)
+			if rulePath == path {
+				target.apply(rule)
+				found = true
+			} else if path != "" && len(rulePath) < len(path) && insertAt < 0 {
+				insertAt = len(rules)
+			}
 		}
 		rules = append(rules, rule)
 	}
 
 	if !found {
-		rules = append(rules, map[string]any{
+		rule := map[string]any{
 			"hostname":      hostname,
-			"service":       service,
 			"originRequest": map[string]any{},
-		})
+		}
+		if path != "" {
+			rule["path"] = path
+		}
+		target.apply(rule)
+		if insertAt >= 0 {
+			rules = append(rules[:insertAt], append([]map[string]any{rule}, rules[insertAt:]...)...)
+		} else {
+			rules = append(rules, rule)
+		}
 	}
 
 	if catchAll == nil {
@@ -968,6 +1087,17 @@ func ensureIngressRule(existing []map[string]any, hostname, service string) []ma
 	return rules
 }
 
+// IngressPathPattern turns a URL path prefix such as "/api" into the anchored
+// cloudflared path regex that matches it and everything below it. The root
+// path maps to "", which matches every path.
+func IngressPathPattern(prefix string) string {
+	prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return ""
+	}
+	return "^" + regexp.QuoteMeta(prefix) + "(/|$)"
+}
+
 func isCatchAll(rule map[string]any) bool {
 	if rule == nil {
 		return false
@@ -3,9 +3,11 @@ package cloudflare
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -112,6 +114,33 @@ func TestDeleteTunnelCNAMERecordSkipsWhenRecordIsNotCNAME(t *testing.T) {
 	require.Equal(t, 0, deleteCalls)
 }
 
+func TestListZoneDNSRecordsFollowsPagination(t *testing.T) {
+	t.Parallel()
+
+	pages := []string{}
+	client := newTestCloudflareClient(t, func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, "/client/v4/zones/zone-1/dns_records", r.URL.Path)
+		require.Empty(t, r.URL.Query().Get("name"))
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		records := []map[string]any{}
+		if page == "1" {
+			for i := 0; i < dnsRecordsPageSize; i++ {
+				records = append(records, map[string]any{"id": fmt.Sprintf("rec-%d", i), "type": "A", "name": "a.example.com", "content": "192.0.2.1"})
+			}
+		} else {
+			records = append(records, map[string]any{"id": "rec-last", "type": "CNAME", "name": "app.example.com", "content": "tunnel-1.cfargotunnel.com", "proxied": true})
+		}
+		return cloudflareSuccessResponse(t, records), nil
+	})
+
+	records, err := client.ListZoneDNSRecords(context.Background(), "zone-1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2"}, pages)
+	require.Len(t, records, dnsRecordsPageSize+1)
+	require.Equal(t, DNSRecord{ID: "rec-last", Type: "CNAME", Name: "app.example.com", Content: "tunnel-1.cfargotunnel.com", Proxied: true}, records[dnsRecordsPageSize])
+}
+
 func TestRemoveIngressRulesByExactTargetRemovesOnlyPlannedRuleOccurrence(t *testing.T) {
 	t.Parallel()
 
@@ -252,3 +281,93 @@ func cloudflareSuccessResponse(t *testing.T, result any) *http.Response {
 		Body: io.NopCloser(strings.NewReader(string(body))),
 	}
 }
+
+func TestEnsureIngressRulePlacesPathRulesBeforeHostnameRule(t *testing.T) {
+	t.Parallel()
+
+	existing := []map[string]any{
+		{"hostname": "app.example.com", "service": "http://localhost:3000"},
+		{"hostname": "other.example.com", "service": "http://localhost:7070"},
+		{"service": "http_status:404"},
+	}
+
+	next := ensureIngressRule(existing, "app.example.com", IngressPathPattern("/api"), ingressTarget{port: 8080})
+	next = ensureIngressRule(next, "app.example.com", "", ingressTarget{port: 3001})
+
+	require.Equal(t, []IngressRule{
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080"},
+		{Hostname: "app.example.com", Service: "http://localhost:3001"},
+		{Hostname: "other.example.com", Service: "http://localhost:7070"},
+	}, ingressRulesFromConfig(next))
+	require.True(t, isCatchAll(next[len(next)-1]))
+
+	next = ensureIngressRule(next, "app.example.com", IngressPathPattern("/api/"), ingressTarget{port: 8081})
+	require.Len(t, ingressRulesFromConfig(next), 3)
+	require.Equal(t, "http://localhost:8081", next[0]["service"])
+}
+
+func TestEnsureIngressRulePlacesLongerPathsBeforeShorterOnes(t *testing.T) {
+	t.Parallel()
+
+	existing := []map[string]any{
+		{"hostname": "app.example.com", "path": "^/api(/|$)", "service": "http://localhost:8080"},
+		{"hostname": "app.example.com", "service": "http://localhost:3000"},
+		{"service": "http_status:404"},
+	}
+
+	next := ensureIngressRule(existing, "app.example.com", IngressPathPattern("/api/v2"), ingressTarget{port: 8082})
+	next = ensureIngressRule(next, "app.example.com", IngressPathPattern("/docs"), ingressTarget{port: 8083})
+
+	require.Equal(t, []IngressRule{
+		{Hostname: "app.example.com", Path: "^/api/v2(/|$)", Service: "http://localhost:8082"},
+		{Hostname: "app.example.com", Path: "^/docs(/|$)", Service: "http://localhost:8083"},
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080"},
+		{Hostname: "app.example.com", Service: "http://localhost:3000"},
+	}, ingressRulesFromConfig(next))
+}
+
+func TestIngressPathPattern(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "", IngressPathPattern(""))
+	require.Equal(t, "", IngressPathPattern("/"))
+	require.Equal(t, `^/v1\.2/api(/|$)`, IngressPathPattern("/v1.2/api/"))
+}
+
+func TestRemoveIngressRulesByExactTargetMatchesPath(t *testing.T) {
+	t.Parallel()
+
+	existing := []map[string]any{
+		{"hostname": "app.example.com", "path": "^/api(/|$)", "service": "http://localhost:8080"},
+		{"hostname": "app.example.com", "service": "http://localhost:8080"},
+		{"service": "http_status:404"},
+	}
+
+	removed, next := removeIngressRulesByExactTarget(existing, normalizeIngressRuleTargets([]IngressRule{
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080"},
+	}))
+	require.Equal(t, []IngressRule{{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080"}}, removed)
+	require.Equal(t, []IngressRule{{Hostname: "app.example.com", Service: "http://localhost:8080"}}, ingressRulesFromConfig(next))
+}
+
+func TestUpdateLocalIngressRouteWritesPathRule(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte(
+		"tunnel: demo\n"+
+			"ingress:\n"+
+			"  - hostname: app.example.com\n"+
+			"    service: http://localhost:3000\n"+
+			"  - service: http_status:404\n",
+	), 0o644))
+
+	require.NoError(t, UpdateLocalIngressRoute(configPath, "app.example.com", IngressPathPattern("/api"), 8080))
+
+	rules, err := ListLocalIngressRules(configPath)
+	require.NoError(t, err)
+	require.Equal(t, []IngressRule{
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080"},
+		{Hostname: "app.example.com", Service: "http://localhost:3000"},
+	}, rules)
+}
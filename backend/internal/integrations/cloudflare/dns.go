@@ -0,0 +1,237 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidDNSRecord    = errors.New("invalid DNS record")
+	ErrDNSRecordNotManaged = errors.New("DNS record is not managed by gungnr")
+)
+
+// ManagedDNSCommentPrefix marks the records gungnr created through the DNS
+// API. Only records whose comment starts with it can be changed or deleted.
+const ManagedDNSCommentPrefix = "gungnr-managed"
+
+const (
+	maxDNSNoteLength   = 80
+	maxTXTContentBytes = 2048
+	dnsTTLAuto         = 1
+)
+
+// ManagedDNSTypes are the record types the DNS API manages.
+var ManagedDNSTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT"}
+
+var dnsRecordNameRe = regexp.MustCompile(`^(\*\.)?([a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?\.)+[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// DNSRecordInput is a record to create or replace. TTL 0 or 1 means automatic;
+// Priority is required for MX and ignored otherwise. Note is appended to the
+// managed marker in the record comment.
+type DNSRecordInput struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl,omitempty"`
+	Proxied  bool   `json:"proxied"`
+	Priority *int   `json:"priority,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+type managedDNSRecordRequest struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl"`
+	Proxied  bool   `json:"proxied"`
+	Priority *int   `json:"priority,omitempty"`
+	Comment  string `json:"comment"`
+}
+
+// ManagedDNSComment returns the record comment for a managed record.
+func ManagedDNSComment(note string) string {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return ManagedDNSCommentPrefix
+	}
+	return ManagedDNSCommentPrefix + ": " + note
+}
+
+// IsManagedDNSComment reports whether comment carries the managed marker.
+func IsManagedDNSComment(comment string) bool {
+	comment = strings.TrimSpace(comment)
+	return comment == ManagedDNSCommentPrefix || strings.HasPrefix(comment, ManagedDNSCommentPrefix+":")
+}
+
+// DNSRecord fetches one record from zoneID.
+func (c *Client) DNSRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error) {
+	if err := c.ensureToken(); err != nil {
+		return DNSRecord{}, err
+	}
+	zoneID = strings.TrimSpace(zoneID)
+	recordID = strings.TrimSpace(recordID)
+	if zoneID == "" {
+		return DNSRecord{}, ErrMissingZoneID
+	}
+	if recordID == "" {
+		return DNSRecord{}, fmt.Errorf("dns record id is required")
+	}
+	var record dnsRecord
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, recordID), nil, &record); err != nil {
+		return DNSRecord{}, err
+	}
+	return record.public(), nil
+}
+
+// CreateManagedDNSRecord creates a record in zoneID marked as managed by
+// gungnr.
+func (c *Client) CreateManagedDNSRecord(ctx context.Context, zoneID string, input DNSRecordInput) (DNSRecord, error) {
+	if err := c.ensureToken(); err != nil {
+		return DNSRecord{}, err
+	}
+	zoneID = strings.TrimSpace(zoneID)
+	if zoneID == "" {
+		return DNSRecord{}, ErrMissingZoneID
+	}
+	req, err := managedDNSRequest(input)
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	var record dnsRecord
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/zones/%s/dns_records", zoneID), req, &record); err != nil {
+		return DNSRecord{}, err
+	}
+	return record.public(), nil
+}
+
+// UpdateManagedDNSRecord replaces a managed record. Records without the
+// managed marker are refused with ErrDNSRecordNotManaged.
+func (c *Client) UpdateManagedDNSRecord(ctx context.Context, zoneID, recordID string, input DNSRecordInput) (DNSRecord, error) {
+	req, err := managedDNSRequest(input)
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	existing, err := c.DNSRecord(ctx, zoneID, recordID)
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	if !existing.Managed {
+		return DNSRecord{}, fmt.Errorf("%w: %s %s", ErrDNSRecordNotManaged, existing.Type, existing.Name)
+	}
+	var record dnsRecord
+	path := fmt.Sprintf("/zones/%s/dns_records/%s", strings.TrimSpace(zoneID), strings.TrimSpace(recordID))
+	if err := c.do(ctx, http.MethodPut, path, req, &record); err != nil {
+		return DNSRecord{}, err
+	}
+	return record.public(), nil
+}
+
+// DeleteManagedDNSRecord deletes a managed record. Records without the
+// managed marker are refused with ErrDNSRecordNotManaged.
+func (c *Client) DeleteManagedDNSRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error) {
+	existing, err := c.DNSRecord(ctx, zoneID, recordID)
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	if !existing.Managed {
+		return DNSRecord{}, fmt.Errorf("%w: %s %s", ErrDNSRecordNotManaged, existing.Type, existing.Name)
+	}
+	if err := c.DeleteDNSRecord(ctx, zoneID, recordID); err != nil {
+		return DNSRecord{}, err
+	}
+	return existing, nil
+}
+
+// managedDNSRequest validates input and builds the API payload.
+func managedDNSRequest(input DNSRecordInput) (managedDNSRecordRequest, error) {
+	recordType := strings.ToUpper(strings.TrimSpace(input.Type))
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(input.Name)), ".")
+	content := strings.TrimSpace(input.Content)
+	note := strings.TrimSpace(input.Note)
+	invalid := func(format string, args ...any) (managedDNSRecordRequest, error) {
+		return managedDNSRecordRequest{}, fmt.Errorf("%w: %s", ErrInvalidDNSRecord, fmt.Sprintf(format, args...))
+	}
+
+	if !IsManagedDNSType(recordType) {
+		return invalid("type must be one of %s", strings.Join(ManagedDNSTypes, ", "))
+	}
+	if len(name) > 253 || !dnsRecordNameRe.MatchString(name) {
+		return invalid("%q is not a valid record name", input.Name)
+	}
+	if content == "" {
+		return invalid("content is required")
+	}
+	switch recordType {
+	case "A":
+		ip := net.ParseIP(content)
+		if ip == nil || ip.To4() == nil {
+			return invalid("A records need an IPv4 address")
+		}
+	case "AAAA":
+		ip := net.ParseIP(content)
+		if ip == nil || ip.To4() != nil {
+			return invalid("AAAA records need an IPv6 address")
+		}
+	case "CNAME", "MX":
+		content = strings.TrimSuffix(strings.ToLower(content), ".")
+		if !dnsRecordNameRe.MatchString(content) || strings.HasPrefix(content, "*.") {
+			return invalid("%s records need a hostname target", recordType)
+		}
+	case "TXT":
+		if len(content) > maxTXTContentBytes {
+			return invalid("TXT content is limited to %d bytes", maxTXTContentBytes)
+		}
+	}
+
+	ttl := input.TTL
+	if ttl == 0 {
+		ttl = dnsTTLAuto
+	}
+	if ttl != dnsTTLAuto && (ttl < 60 || ttl > 86400) {
+		return invalid("ttl must be 1 (automatic) or between 60 and 86400 seconds")
+	}
+	if input.Proxied {
+		if recordType != "A" && recordType != "AAAA" && recordType != "CNAME" {
+			return invalid("only A, AAAA, and CNAME records can be proxied")
+		}
+		ttl = dnsTTLAuto
+	}
+
+	var priority *int
+	if recordType == "MX" {
+		if input.Priority == nil || *input.Priority < 0 || *input.Priority > 65535 {
+			return invalid("MX records need a priority between 0 and 65535")
+		}
+		value := *input.Priority
+		priority = &value
+	}
+	if len(note) > maxDNSNoteLength {
+		return invalid("note is limited to %d characters", maxDNSNoteLength)
+	}
+
+	return managedDNSRecordRequest{
+		Type:     recordType,
+		Name:     name,
+		Content:  content,
+		TTL:      ttl,
+		Proxied:  input.Proxied,
+		Priority: priority,
+		Comment:  ManagedDNSComment(note),
+	}, nil
+}
+
+// IsManagedDNSType reports whether recordType is one of ManagedDNSTypes.
+func IsManagedDNSType(recordType string) bool {
+	recordType = strings.ToUpper(strings.TrimSpace(recordType))
+	for _, candidate := range ManagedDNSTypes {
+		if candidate == recordType {
+			return true
+		}
+	}
+	return false
+}
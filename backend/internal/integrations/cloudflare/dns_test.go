@@ -0,0 +1,181 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+)
+
+// fakeDNSAPI is an in-memory stand-in for the DNS record endpoints of one
+// zone.
+type fakeDNSAPI struct {
+	mu      sync.Mutex
+	records map[string]dnsRecord
+	nextID  int
+	writes  []string
+}
+
+func (f *fakeDNSAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const prefix = "/zones/zone-1/dns_records"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		writeFakeAccessError(w, http.StatusNotFound, "unknown path")
+		return
+	}
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if r.Method != http.MethodGet {
+		f.writes = append(f.writes, r.Method+" "+id)
+	}
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		var req managedDNSRecordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeFakeAccessError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		f.nextID++
+		record := dnsRecord{ID: fmt.Sprintf("rec-%d", f.nextID), Type: req.Type, Name: req.Name, Content: req.Content, Proxied: req.Proxied, TTL: req.TTL, Priority: req.Priority, Comment: req.Comment}
+		f.records[record.ID] = record
+		writeFakeAccessResult(w, record)
+	case id != "" && r.Method == http.MethodGet:
+		record, ok := f.records[id]
+		if !ok {
+			writeFakeAccessError(w, http.StatusNotFound, "record not found")
+			return
+		}
+		writeFakeAccessResult(w, record)
+	case id != "" && r.Method == http.MethodPut:
+		var req managedDNSRecordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeFakeAccessError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		record := dnsRecord{ID: id, Type: req.Type, Name: req.Name, Content: req.Content, Proxied: req.Proxied, TTL: req.TTL, Priority: req.Priority, Comment: req.Comment}
+		f.records[id] = record
+		writeFakeAccessResult(w, record)
+	case id != "" && r.Method == http.MethodDelete:
+		delete(f.records, id)
+		writeFakeAccessResult(w, map[string]string{"id": id})
+	default:
+		writeFakeAccessError(w, http.StatusNotFound, "unknown route")
+	}
+}
+
+func newTestDNSClient(t *testing.T, api *fakeDNSAPI) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return &Client{
+		cfg:     config.Config{CloudflareAPIToken: "token-1"},
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+}
+
+func TestCreateManagedDNSRecordMarksRecordManaged(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeDNSAPI{records: map[string]dnsRecord{}}
+	client := newTestDNSClient(t, api)
+
+	priority := 10
+	record, err := client.CreateManagedDNSRecord(context.Background(), "zone-1", DNSRecordInput{
+		Type:     "mx",
+		Name:     "Example.com.",
+		Content:  "Mail.Example.net.",
+		TTL:      300,
+		Priority: &priority,
+		Note:     "mail",
+	})
+	require.NoError(t, err)
+	require.True(t, record.Managed)
+	require.Equal(t, "gungnr-managed: mail", record.Comment)
+	require.Equal(t, "MX", record.Type)
+	require.Equal(t, "example.com", record.Name)
+	require.Equal(t, "mail.example.net", record.Content)
+	require.Equal(t, 10, *record.Priority)
+
+	txt, err := client.CreateManagedDNSRecord(context.Background(), "zone-1", DNSRecordInput{
+		Type:    "TXT",
+		Name:    "_acme-challenge.example.com",
+		Content: "token-value",
+		TTL:     120,
+		Proxied: false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 120, txt.TTL)
+	require.Equal(t, ManagedDNSCommentPrefix, txt.Comment)
+}
+
+func TestManagedDNSRequestValidatesRecords(t *testing.T) {
+	t.Parallel()
+
+	priority := 5
+	for name, input := range map[string]DNSRecordInput{
+		"unsupported type":  {Type: "SRV", Name: "a.example.com", Content: "x"},
+		"bad name":          {Type: "A", Name: "not a name", Content: "192.0.2.1"},
+		"ipv6 in A":         {Type: "A", Name: "a.example.com", Content: "2001:db8::1"},
+		"ipv4 in AAAA":      {Type: "AAAA", Name: "a.example.com", Content: "192.0.2.1"},
+		"cname to ip-ish":   {Type: "CNAME", Name: "a.example.com", Content: "not a host"},
+		"mx without prio":   {Type: "MX", Name: "example.com", Content: "mail.example.com"},
+		"proxied txt":       {Type: "TXT", Name: "a.example.com", Content: "v=spf1", Proxied: true},
+		"ttl out of range":  {Type: "A", Name: "a.example.com", Content: "192.0.2.1", TTL: 30},
+		"empty content":     {Type: "TXT", Name: "a.example.com"},
+		"long note":         {Type: "A", Name: "a.example.com", Content: "192.0.2.1", Note: strings.Repeat("n", 81)},
+		"mx negative prio":  {Type: "MX", Name: "example.com", Content: "mail.example.com", Priority: func() *int { v := -1; return &v }()},
+		"wildcard mx value": {Type: "MX", Name: "example.com", Content: "*.example.com", Priority: &priority},
+	} {
+		_, err := managedDNSRequest(input)
+		require.ErrorIs(t, err, ErrInvalidDNSRecord, name)
+	}
+
+	req, err := managedDNSRequest(DNSRecordInput{Type: "A", Name: "*.apps.example.com", Content: "192.0.2.1", TTL: 600, Proxied: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, req.TTL, "proxied records always use automatic TTL")
+	require.Nil(t, req.Priority)
+}
+
+func TestUpdateAndDeleteManagedDNSRecordRefuseUnmanagedRecords(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeDNSAPI{records: map[string]dnsRecord{
+		"rec-tunnel": {ID: "rec-tunnel", Type: "CNAME", Name: "app.example.com", Content: "tunnel-1.cfargotunnel.com", Proxied: true},
+		"rec-owned":  {ID: "rec-owned", Type: "A", Name: "direct.example.com", Content: "192.0.2.1", TTL: 1, Comment: "gungnr-managed"},
+		"rec-lookalike": {
+			ID: "rec-lookalike", Type: "TXT", Name: "example.com", Content: "v=spf1 -all", Comment: "gungnr-managed-ish",
+		},
+	}}
+	client := newTestDNSClient(t, api)
+	input := DNSRecordInput{Type: "A", Name: "app.example.com", Content: "192.0.2.9"}
+
+	_, err := client.UpdateManagedDNSRecord(context.Background(), "zone-1", "rec-tunnel", input)
+	require.ErrorIs(t, err, ErrDNSRecordNotManaged)
+	_, err = client.DeleteManagedDNSRecord(context.Background(), "zone-1", "rec-lookalike")
+	require.ErrorIs(t, err, ErrDNSRecordNotManaged)
+	require.Empty(t, api.writes)
+
+	updated, err := client.UpdateManagedDNSRecord(context.Background(), "zone-1", "rec-owned", DNSRecordInput{
+		Type: "A", Name: "direct.example.com", Content: "192.0.2.2", Note: "edge",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "192.0.2.2", updated.Content)
+	require.Equal(t, "gungnr-managed: edge", updated.Comment)
+
+	deleted, err := client.DeleteManagedDNSRecord(context.Background(), "zone-1", "rec-owned")
+	require.NoError(t, err)
+	require.Equal(t, "direct.example.com", deleted.Name)
+	require.Equal(t, []string{"PUT rec-owned", "DELETE rec-owned"}, api.writes)
+	require.Contains(t, api.records, "rec-tunnel")
+}
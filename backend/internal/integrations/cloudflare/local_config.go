@@ -11,6 +11,17 @@ import (
 var ErrMissingConfigPath = fmt.Errorf("cloudflared config path is not set")
 
 func UpdateLocalIngress(configPath, hostname string, port int) error {
+	return UpdateLocalIngressRoute(configPath, hostname, "", port)
+}
+
+// UpdateLocalIngressRoute is UpdateIngressRoute for a locally managed tunnel.
+func UpdateLocalIngressRoute(configPath, hostname, routePath string, port int) error {
+	return UpdateLocalIngressRouteOptions(configPath, hostname, routePath, port, IngressRouteOptions{})
+}
+
+// UpdateLocalIngressRouteOptions is UpdateIngressRouteOptions for a locally
+// managed tunnel.
+func UpdateLocalIngressRouteOptions(configPath, hostname, routePath string, port int, opts IngressRouteOptions) error {
 	if strings.TrimSpace(hostname) == "" {
 		return ErrMissingHostname
 	}
@@ -23,8 +34,7 @@ func UpdateLocalIngress(configPath, hostname string, port int) error {
 	}
 
 	ingress := coerceIngress(payload["ingress"])
-	service := fmt.Sprintf("http://localhost:%d", port)
-	payload["ingress"] = ensureIngressRule(ingress, hostname, service)
+	payload["ingress"] = ensureIngressRule(ingress, hostname, routePath, ingressTarget{port: port, opts: opts, local: true})
 
 	return writeLocalConfigPayload(path, payload)
 }
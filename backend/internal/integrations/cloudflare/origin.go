@@ -0,0 +1,220 @@
+package cloudflare
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrInvalidIngressOrigin = errors.New("invalid ingress origin options")
+
+// IngressSchemes are the local service schemes a project ingress rule can
+// use. tcp and ssh need cloudflared access on the client side.
+var IngressSchemes = []string{"http", "https", "tcp", "ssh"}
+
+const (
+	defaultIngressScheme     = "http"
+	maxIngressConnectTimeout = 300
+)
+
+var (
+	hostHeaderRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*(:[0-9]{1,5})?$`)
+	audTagRe     = regexp.MustCompile(`^[a-f0-9]{64}$`)
+	teamNameRe   = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// IngressOrigin holds the originRequest options gungnr manages on a rule.
+// Other originRequest keys found on a rule are left as they are.
+type IngressOrigin struct {
+	NoTLSVerify bool `json:"noTLSVerify,omitempty"`
+	HTTP2Origin bool `json:"http2Origin,omitempty"`
+	// ConnectTimeout is in seconds; 0 leaves the cloudflared default.
+	ConnectTimeout int                  `json:"connectTimeout,omitempty"`
+	HTTPHostHeader string               `json:"httpHostHeader,omitempty"`
+	Access         *IngressOriginAccess `json:"access,omitempty"`
+}
+
+// IngressOriginAccess makes cloudflared reject requests without a valid
+// Cloudflare Access token for the given team and application audience tags.
+type IngressOriginAccess struct {
+	Required bool     `json:"required"`
+	TeamName string   `json:"teamName"`
+	AudTag   []string `json:"audTag"`
+}
+
+// Empty reports whether no option is set.
+func (o IngressOrigin) Empty() bool {
+	return !o.NoTLSVerify && !o.HTTP2Origin && o.ConnectTimeout == 0 && o.HTTPHostHeader == "" && o.Access == nil
+}
+
+// IngressRouteOptions are the per-rule settings beyond the local port. An
+// empty Scheme keeps the scheme of an existing rule (http for a new one) and
+// a nil Origin keeps its originRequest options, so port-only updates such as
+// blue-green swaps do not reset them.
+type IngressRouteOptions struct {
+	Scheme string
+	Origin *IngressOrigin
+}
+
+// NormalizeIngressScheme lowercases scheme and checks it against
+// IngressSchemes. An empty scheme stays empty.
+func NormalizeIngressScheme(scheme string) (string, error) {
+	scheme = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(scheme), "://")))
+	if scheme == "" {
+		return "", nil
+	}
+	for _, candidate := range IngressSchemes {
+		if candidate == scheme {
+			return scheme, nil
+		}
+	}
+	return "", fmt.Errorf("%w: scheme must be one of %s", ErrInvalidIngressOrigin, strings.Join(IngressSchemes, ", "))
+}
+
+// NormalizeIngressOrigin validates origin and returns it in the form written
+// to the tunnel config.
+func NormalizeIngressOrigin(origin IngressOrigin) (IngressOrigin, error) {
+	invalid := func(format string, args ...any) (IngressOrigin, error) {
+		return IngressOrigin{}, fmt.Errorf("%w: %s", ErrInvalidIngressOrigin, fmt.Sprintf(format, args...))
+	}
+	if origin.ConnectTimeout < 0 || origin.ConnectTimeout > maxIngressConnectTimeout {
+		return invalid("connectTimeout must be between 0 and %d seconds", maxIngressConnectTimeout)
+	}
+	origin.HTTPHostHeader = strings.ToLower(strings.TrimSpace(origin.HTTPHostHeader))
+	if origin.HTTPHostHeader != "" && !hostHeaderRe.MatchString(origin.HTTPHostHeader) {
+		return invalid("%q is not a valid host header", origin.HTTPHostHeader)
+	}
+	if origin.Access != nil {
+		access := IngressOriginAccess{
+			Required: origin.Access.Required,
+			TeamName: strings.ToLower(strings.TrimSpace(origin.Access.TeamName)),
+			AudTag:   []string{},
+		}
+		for _, tag := range origin.Access.AudTag {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" {
+				continue
+			}
+			if !audTagRe.MatchString(tag) {
+				return invalid("%q is not an Access application audience tag", tag)
+			}
+			access.AudTag = append(access.AudTag, tag)
+		}
+		switch {
+		case !access.Required && access.TeamName == "" && len(access.AudTag) == 0:
+			origin.Access = nil
+		case !teamNameRe.MatchString(access.TeamName):
+			return invalid("access needs the Zero Trust team name")
+		case len(access.AudTag) == 0:
+			return invalid("access needs at least one audience tag")
+		default:
+			origin.Access = &access
+		}
+	}
+	return origin, nil
+}
+
+// ingressService builds the service URL for a local port, keeping the scheme
+// of current when scheme is empty and current is a localhost service.
+func ingressService(scheme, current string, port int) string {
+	if scheme == "" {
+		scheme = defaultIngressScheme
+		if prefix, rest, ok := strings.Cut(strings.TrimSpace(current), "://"); ok && strings.HasPrefix(rest, "localhost:") {
+			if normalized, err := NormalizeIngressScheme(prefix); err == nil && normalized != "" {
+				scheme = normalized
+			}
+		}
+	}
+	return fmt.Sprintf("%s://localhost:%d", scheme, port)
+}
+
+// applyIngressOrigin writes origin into the rule's originRequest. Unmanaged
+// keys are kept. The local YAML config takes connectTimeout as a duration
+// string while the API takes seconds.
+func applyIngressOrigin(rule map[string]any, origin IngressOrigin, local bool) {
+	request, _ := rule["originRequest"].(map[string]any)
+	if request == nil {
+		request = map[string]any{}
+	}
+	setOrDelete := func(key string, value any, set bool) {
+		if set {
+			request[key] = value
+		} else {
+			delete(request, key)
+		}
+	}
+	setOrDelete("noTLSVerify", true, origin.NoTLSVerify)
+	setOrDelete("http2Origin", true, origin.HTTP2Origin)
+	if local {
+		setOrDelete("connectTimeout", fmt.Sprintf("%ds", origin.ConnectTimeout), origin.ConnectTimeout > 0)
+	} else {
+		setOrDelete("connectTimeout", origin.ConnectTimeout, origin.ConnectTimeout > 0)
+	}
+	setOrDelete("httpHostHeader", origin.HTTPHostHeader, origin.HTTPHostHeader != "")
+	if origin.Access != nil {
+		audTag := make([]any, 0, len(origin.Access.AudTag))
+		for _, tag := range origin.Access.AudTag {
+			audTag = append(audTag, tag)
+		}
+		request["access"] = map[string]any{
+			"required": origin.Access.Required,
+			"teamName": origin.Access.TeamName,
+			"audTag":   audTag,
+		}
+	} else {
+		delete(request, "access")
+	}
+	rule["originRequest"] = request
+}
+
+// ingressOriginFromRule reads the managed originRequest options of a rule.
+// It returns nil when none are set.
+func ingressOriginFromRule(rule map[string]any) *IngressOrigin {
+	request, ok := rule["originRequest"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	origin := IngressOrigin{}
+	origin.NoTLSVerify, _ = request["noTLSVerify"].(bool)
+	origin.HTTP2Origin, _ = request["http2Origin"].(bool)
+	origin.ConnectTimeout = originTimeoutSeconds(request["connectTimeout"])
+	origin.HTTPHostHeader, _ = request["httpHostHeader"].(string)
+	if access, ok := request["access"].(map[string]any); ok {
+		parsed := IngressOriginAccess{AudTag: []string{}}
+		parsed.Required, _ = access["required"].(bool)
+		parsed.TeamName, _ = access["teamName"].(string)
+		if tags, ok := access["audTag"].([]any); ok {
+			for _, tag := range tags {
+				if value, ok := tag.(string); ok {
+					parsed.AudTag = append(parsed.AudTag, value)
+				}
+			}
+		}
+		origin.Access = &parsed
+	}
+	if origin.Empty() {
+		return nil
+	}
+	return &origin
+}
+
+// originTimeoutSeconds accepts the seconds the API returns, the integers or
+// duration strings a local config may hold, and returns whole seconds.
+func originTimeoutSeconds(value any) int {
+	switch typed := value.(type) {
+	case int:
+		return typed
+	case int64:
+		return int(typed)
+	case float64:
+		return int(math.Round(typed))
+	case string:
+		if duration, err := time.ParseDuration(strings.TrimSpace(typed)); err == nil {
+			return int(duration.Round(time.Second) / time.Second)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package cloudflare
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testAudTag = "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0"
+
+func TestEnsureIngressRuleKeepsSchemeAndOriginOnPortUpdates(t *testing.T) {
+	t.Parallel()
+
+	existing := []map[string]any{
+		{
+			"hostname": "db.example.com",
+			"service":  "tcp://localhost:5432",
+			"originRequest": map[string]any{
+				"noTLSVerify":      true,
+				"connectTimeout":   float64(15),
+				"keepAliveTimeout": "90s",
+			},
+		},
+		{"service": "http_status:404"},
+	}
+
+	next := ensureIngressRule(existing, "db.example.com", "", ingressTarget{port: 5433})
+	rules := ingressRulesFromConfig(next)
+	require.Equal(t, []IngressRule{{
+		Hostname: "db.example.com",
+		Service:  "tcp://localhost:5433",
+		Origin:   &IngressOrigin{NoTLSVerify: true, ConnectTimeout: 15},
+	}}, rules)
+	require.Equal(t, "90s", next[0]["originRequest"].(map[string]any)["keepAliveTimeout"], "unmanaged origin keys are kept")
+
+	origin := IngressOrigin{
+		HTTP2Origin:    true,
+		HTTPHostHeader: "internal.example.com",
+		Access:         &IngressOriginAccess{Required: true, TeamName: "acme", AudTag: []string{testAudTag}},
+	}
+	next = ensureIngressRule(next, "db.example.com", "", ingressTarget{port: 8443, opts: IngressRouteOptions{Scheme: "https", Origin: &origin}})
+	request := next[0]["originRequest"].(map[string]any)
+	require.Equal(t, "https://localhost:8443", next[0]["service"])
+	require.NotContains(t, request, "noTLSVerify")
+	require.NotContains(t, request, "connectTimeout")
+	require.Equal(t, "90s", request["keepAliveTimeout"])
+	require.Equal(t, &origin, ingressRulesFromConfig(next)[0].Origin)
+}
+
+func TestRemoveIngressRulesKeepsOriginOfRemainingRules(t *testing.T) {
+	t.Parallel()
+
+	existing := []map[string]any{
+		{"hostname": "old.example.com", "service": "http://localhost:8080"},
+		{"hostname": "ssh.example.com", "service": "ssh://localhost:22", "originRequest": map[string]any{"connectTimeout": float64(30)}},
+		{"hostname": "api.example.com", "service": "https://localhost:8443", "originRequest": map[string]any{"noTLSVerify": true}},
+		{"service": "http_status:404"},
+	}
+
+	removed, next := removeIngressRulesByExactTarget(existing, normalizeIngressRuleTargets([]IngressRule{
+		{Hostname: "old.example.com", Service: "http://localhost:8080"},
+		{Hostname: "api.example.com", Service: "https://localhost:8443"},
+	}))
+	require.Equal(t, []IngressRule{
+		{Hostname: "old.example.com", Service: "http://localhost:8080"},
+		{Hostname: "api.example.com", Service: "https://localhost:8443", Origin: &IngressOrigin{NoTLSVerify: true}},
+	}, removed)
+	require.Equal(t, []IngressRule{
+		{Hostname: "ssh.example.com", Service: "ssh://localhost:22", Origin: &IngressOrigin{ConnectTimeout: 30}},
+	}, ingressRulesFromConfig(next))
+}
+
+func TestUpdateLocalIngressRouteOptionsWritesOriginRequest(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte(
+		"tunnel: demo\n"+
+			"ingress:\n"+
+			"  - hostname: app.example.com\n"+
+			"    service: http://localhost:3000\n"+
+			"    originRequest:\n"+
+			"      proxyType: socks\n"+
+			"  - service: http_status:404\n",
+	), 0o644))
+
+	origin := IngressOrigin{NoTLSVerify: true, ConnectTimeout: 20}
+	require.NoError(t, UpdateLocalIngressRouteOptions(configPath, "app.example.com", "", 3000, IngressRouteOptions{Scheme: "https", Origin: &origin}))
+
+	raw, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "connectTimeout: 20s")
+	require.Contains(t, string(raw), "proxyType: socks")
+
+	require.NoError(t, UpdateLocalIngressRoute(configPath, "app.example.com", "", 3001))
+	rules, err := ListLocalIngressRules(configPath)
+	require.NoError(t, err)
+	require.Equal(t, []IngressRule{{Hostname: "app.example.com", Service: "https://localhost:3001", Origin: &origin}}, rules)
+}
+
+func TestNormalizeIngressOrigin(t *testing.T) {
+	t.Parallel()
+
+	for name, origin := range map[string]IngressOrigin{
+		"negative timeout": {ConnectTimeout: -1},
+		"long timeout":     {ConnectTimeout: 301},
+		"bad host header":  {HTTPHostHeader: "not a host"},
+		"access no team":   {Access: &IngressOriginAccess{Required: true, AudTag: []string{testAudTag}}},
+		"access no tag":    {Access: &IngressOriginAccess{Required: true, TeamName: "acme"}},
+		"access bad tag":   {Access: &IngressOriginAccess{Required: true, TeamName: "acme", AudTag: []string{"abc"}}},
+	} {
+		_, err := NormalizeIngressOrigin(origin)
+		require.ErrorIs(t, err, ErrInvalidIngressOrigin, name)
+	}
+
+	normalized, err := NormalizeIngressOrigin(IngressOrigin{
+		HTTPHostHeader: " Internal.Example.com:8080 ",
+		Access:         &IngressOriginAccess{AudTag: []string{" "}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, IngressOrigin{HTTPHostHeader: "internal.example.com:8080"}, normalized)
+
+	scheme, err := NormalizeIngressScheme("TCP://")
+	require.NoError(t, err)
+	require.Equal(t, "tcp", scheme)
+	_, err = NormalizeIngressScheme("unix")
+	require.ErrorIs(t, err, ErrInvalidIngressOrigin)
+	require.True(t, strings.HasPrefix(ingressService("", "ssh://localhost:22", 2222), "ssh://"))
+	require.Equal(t, "http://localhost:80", ingressService("", "http_status:404", 80))
+}
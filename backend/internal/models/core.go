@@ -29,6 +29,18 @@ type Project struct {
 	ProxyPort int
 	DBPort    int
 	Status    string `gorm:"size:32"`
+	// GitBranch is the branch git redeploys track; AutoDeploy lets GitHub
+	// push webhooks for that branch queue a redeploy.
+	GitBranch      string `gorm:"size:255"`
+	AutoDeploy     bool
+	DeployedCommit string `gorm:"size:64"`
+	DeployedAt     *time.Time
+	// BackupRetention is how many volume backups the project keeps; zero
+	// uses the VOLUME_BACKUP_KEEP default.
+	BackupRetention int
+	// Tunnel names the CloudflareTunnel serving the project's hostnames; empty
+	// uses the tunnel configured in settings.
+	Tunnel string `gorm:"size:64"`
 }
 
 type Deployment struct {
@@ -36,6 +48,7 @@ type Deployment struct {
 	ProjectID uint   `gorm:"index;not null"`
 	Subdomain string `gorm:"size:120;not null"`
 	Hostname  string `gorm:"size:255"`
+	Path      string `gorm:"size:255"`
 	Port      int
 	State     string `gorm:"size:32"`
 	LastRunAt *time.Time
@@ -78,3 +91,55 @@ type Settings struct {
 	CloudflaredConfigPath   string `gorm:"size:512"`
 	NetBirdConfigEncrypted  string `gorm:"type:text"`
 }
+
+// CloudflareTunnel is a cloudflared tunnel projects can be assigned to besides
+// the one in settings. An empty AccountID or ZoneID falls back to settings;
+// Token does too, and is kept in the vault when it is configured. Tunnels run
+// side by side on the host, so each needs its own config file and metrics
+// address.
+type CloudflareTunnel struct {
+	gorm.Model
+	Name           string `gorm:"size:64;not null;uniqueIndex"`
+	AccountID      string `gorm:"size:255"`
+	ZoneID         string `gorm:"size:255"`
+	Tunnel         string `gorm:"size:255;not null"`
+	ConfigPath     string `gorm:"size:512"`
+	MetricsAddress string `gorm:"size:64"`
+	// Token is sealed with the settings payload key, and empty when the
+	// token lives in the secrets vault.
+	Token string `gorm:"type:text"`
+}
+
+type WorkbenchSnapshot struct {
+	gorm.Model
+	ProjectName string `gorm:"size:120;not null;uniqueIndex"`
+	Revision    int    `gorm:"not null"`
+	Payload     string `gorm:"type:text;not null"`
+}
+
+type WorkbenchSnapshotRevision struct {
+	gorm.Model
+	ProjectName  string `gorm:"size:120;not null;index:idx_workbench_revision_project_revision"`
+	Revision     int    `gorm:"not null;index:idx_workbench_revision_project_revision"`
+	UserID       uint   `gorm:"index"`
+	UserLogin    string `gorm:"size:64"`
+	MutationType string `gorm:"size:64;not null"`
+	Note         string `gorm:"size:255"`
+	Snapshot     string `gorm:"type:text"`
+	Diff         string `gorm:"type:text"`
+}
+
+// SecretVersion is one encrypted value of a vault secret. Setting a secret
+// appends a version; the highest version is the live value. KeyID names the
+// key the ciphertext was sealed with so rotation can find stale rows.
+type SecretVersion struct {
+	gorm.Model
+	Scope      string `gorm:"size:16;not null;index:idx_secret_version_name"`
+	Project    string `gorm:"size:120;not null;index:idx_secret_version_name"`
+	Name       string `gorm:"size:128;not null;index:idx_secret_version_name"`
+	Version    int    `gorm:"not null;index:idx_secret_version_name"`
+	Ciphertext string `gorm:"type:text;not null"`
+	KeyID      string `gorm:"size:32;not null;index"`
+	UserID     uint
+	UserLogin  string `gorm:"size:64"`
+}
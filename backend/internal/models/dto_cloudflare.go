@@ -5,3 +5,9 @@ type CloudflareZoneResponse struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
+
+// CloudflareReconcileCleanupRequest selects report findings by ID for the
+// cleanup job.
+type CloudflareReconcileCleanupRequest struct {
+	FindingIDs []string `json:"findingIds"`
+}
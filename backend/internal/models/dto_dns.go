@@ -0,0 +1,15 @@
+package models
+
+// DNSRecordRequest is the request body for creating or replacing a DNS
+// record. Zone is a zone name or ID; on create it defaults to the zone that
+// contains Name. Name may be relative to the zone, with "@" for the apex.
+type DNSRecordRequest struct {
+	Zone     string `json:"zone,omitempty"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl,omitempty"`
+	Proxied  bool   `json:"proxied"`
+	Priority *int   `json:"priority,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
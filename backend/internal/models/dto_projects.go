@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // --- Requests ---
 
@@ -21,12 +24,25 @@ type ProjectEnvWriteRequest struct {
 	CreateBackup *bool  `json:"createBackup,omitempty"`
 }
 
+// ProjectEnvValidateRequest is the request body for checking .env content without saving it.
+type ProjectEnvValidateRequest struct {
+	Content string `json:"content"`
+}
+
 // ProjectArchiveRequest is the request body for archiving a project.
 type ProjectArchiveRequest struct {
 	RemoveContainers *bool `json:"removeContainers,omitempty"`
 	RemoveVolumes    *bool `json:"removeVolumes,omitempty"`
 	RemoveIngress    *bool `json:"removeIngress,omitempty"`
 	RemoveDNS        *bool `json:"removeDns,omitempty"`
+	RemoveAccess     *bool `json:"removeAccess,omitempty"`
+	BackupVolumes    *bool `json:"backupVolumes,omitempty"`
+}
+
+// ProjectTunnelRequest is the request body for moving a project to another
+// tunnel. "default" is the tunnel in settings.
+type ProjectTunnelRequest struct {
+	Tunnel string `json:"tunnel"`
 }
 
 // ProjectWorkbenchImportRequest is the request body for importing a workbench snapshot.
@@ -73,7 +89,27 @@ type ProjectWorkbenchResourceMutationRequest struct {
 
 // ProjectWorkbenchOptionalServiceAddRequest is the request body for adding an optional service.
 type ProjectWorkbenchOptionalServiceAddRequest struct {
-	EntryKey string `json:"entryKey"`
+	EntryKey   string `json:"entryKey"`
+	AppService string `json:"appService"`
+}
+
+// ProjectWorkbenchNetworkCreateRequest is the request body for defining a workbench network.
+type ProjectWorkbenchNetworkCreateRequest struct {
+	Name     string `json:"name"`
+	Driver   string `json:"driver,omitempty"`
+	Internal bool   `json:"internal,omitempty"`
+}
+
+// ProjectWorkbenchNetworkUpdateRequest is the request body for changing a workbench network.
+type ProjectWorkbenchNetworkUpdateRequest struct {
+	Driver   *string `json:"driver,omitempty"`
+	Internal *bool   `json:"internal,omitempty"`
+}
+
+// ProjectWorkbenchServiceNetworksRequest is the request body for attaching or detaching service networks.
+type ProjectWorkbenchServiceNetworksRequest struct {
+	Attach []string `json:"attach,omitempty"`
+	Detach []string `json:"detach,omitempty"`
 }
 
 // ProjectWorkbenchModuleMutationRequest is the request body for mutating workbench modules.
@@ -87,6 +123,17 @@ type ProjectWorkbenchComposePreviewRequest struct {
 	ExpectedRevision *int `json:"expectedRevision,omitempty"`
 }
 
+// ProjectWorkbenchPolicyUpdateRequest is the request body for replacing workbench policy severity overrides.
+type ProjectWorkbenchPolicyUpdateRequest struct {
+	ExpectedRevision *int              `json:"expectedRevision,omitempty"`
+	Overrides        map[string]string `json:"overrides"`
+}
+
+// ProjectWorkbenchRevertRequest is the request body for reverting to a stored workbench revision.
+type ProjectWorkbenchRevertRequest struct {
+	ExpectedRevision *int `json:"expectedRevision,omitempty"`
+}
+
 // ProjectWorkbenchComposeApplyRequest is the request body for applying compose changes.
 type ProjectWorkbenchComposeApplyRequest struct {
 	ExpectedRevision          *int   `json:"expectedRevision,omitempty"`
@@ -111,6 +158,16 @@ type ProjectResponse struct {
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	GitBranch      string     `json:"gitBranch,omitempty"`
+	AutoDeploy     bool       `json:"autoDeploy"`
+	DeployedCommit string     `json:"deployedCommit,omitempty"`
+	DeployedAt     *time.Time `json:"deployedAt,omitempty"`
+
+	BackupRetention int `json:"backupRetention"`
+	// Tunnel is the stored tunnel serving the project; empty means the
+	// tunnel in settings.
+	Tunnel string `json:"tunnel,omitempty"`
 }
 
 // NewProjectResponse builds a ProjectResponse from a Project model.
@@ -125,6 +182,14 @@ func NewProjectResponse(project Project) ProjectResponse {
 		Status:    project.Status,
 		CreatedAt: project.CreatedAt,
 		UpdatedAt: project.UpdatedAt,
+
+		GitBranch:      project.GitBranch,
+		AutoDeploy:     project.AutoDeploy,
+		DeployedCommit: project.DeployedCommit,
+		DeployedAt:     project.DeployedAt,
+
+		BackupRetention: project.BackupRetention,
+		Tunnel:          project.Tunnel,
 	}
 }
 
@@ -136,3 +201,97 @@ func NewProjectResponses(projects []Project) []ProjectResponse {
 	}
 	return response
 }
+
+// ProjectWorkbenchBundleImportRequest is the request body for creating a project from a workbench bundle.
+type ProjectWorkbenchBundleImportRequest struct {
+	Bundle          json.RawMessage `json:"bundle"`
+	KeepManualPorts bool            `json:"keepManualPorts,omitempty"`
+}
+
+// ProjectWorkbenchServiceRestartRequest is the request body for a dependency-ordered service restart.
+type ProjectWorkbenchServiceRestartRequest struct {
+	Mode                 string `json:"mode,omitempty"`
+	HealthTimeoutSeconds int    `json:"healthTimeoutSeconds,omitempty"`
+}
+
+// ProjectImageUpdateRequest is the request body for pulling and recreating services with newer images.
+type ProjectImageUpdateRequest struct {
+	Images               []string `json:"images,omitempty"`
+	HealthTimeoutSeconds int      `json:"healthTimeoutSeconds,omitempty"`
+}
+
+// ProjectBlueGreenDeployRequest is the request body for a blue/green redeploy.
+type ProjectBlueGreenDeployRequest struct {
+	HealthTimeoutSeconds int `json:"healthTimeoutSeconds,omitempty"`
+}
+
+// ProjectGitRedeployRequest is the request body for pulling and rebuilding a template project.
+type ProjectGitRedeployRequest struct {
+	Ref string `json:"ref,omitempty"`
+}
+
+// ProjectGitDeploySettingsRequest is the request body for the tracked branch and push-to-deploy flag.
+type ProjectGitDeploySettingsRequest struct {
+	Branch     string `json:"branch"`
+	AutoDeploy bool   `json:"autoDeploy"`
+}
+
+// ProjectBackupRetentionRequest is the request body for how many volume backups a project keeps.
+type ProjectBackupRetentionRequest struct {
+	Retention int `json:"retention"`
+}
+
+// ProjectBackupRestoreRequest is the optional request body for restoring a volume backup.
+type ProjectBackupRestoreRequest struct {
+	Volumes []string `json:"volumes,omitempty"`
+}
+
+// ProjectDatabaseDumpRequest optionally limits a dump to some database services.
+type ProjectDatabaseDumpRequest struct {
+	Services []string `json:"services,omitempty"`
+}
+
+// ProjectDatabaseRestoreRequest is the optional request body for restoring a
+// database dump, into the same project or a clone of it.
+type ProjectDatabaseRestoreRequest struct {
+	TargetProject string   `json:"targetProject,omitempty"`
+	Services      []string `json:"services,omitempty"`
+}
+
+// ProjectCloneRequest is the request body for copying a project under a new
+// name. The subdomain defaults to the clone name.
+type ProjectCloneRequest struct {
+	Name        string `json:"name"`
+	Subdomain   string `json:"subdomain,omitempty"`
+	Domain      string `json:"domain,omitempty"`
+	CopyVolumes bool   `json:"copyVolumes,omitempty"`
+}
+
+// ProjectHostnamesRequest is the request body for moving a project to a new subdomain or domain.
+type ProjectHostnamesRequest struct {
+	Subdomain string `json:"subdomain"`
+	Domain    string `json:"domain,omitempty"`
+}
+
+// ProjectRouteRequest is one hostname and path prefix routed to a project port.
+type ProjectRouteRequest struct {
+	Subdomain string `json:"subdomain"`
+	Domain    string `json:"domain,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Port      int    `json:"port,omitempty"`
+}
+
+// ProjectRoutesRequest is the request body for replacing a project's routes.
+type ProjectRoutesRequest struct {
+	Routes []ProjectRouteRequest `json:"routes" binding:"required"`
+}
+
+// ProjectAccessRequest is the request body for putting a project hostname
+// behind Cloudflare Access. An empty hostname applies to every project
+// hostname.
+type ProjectAccessRequest struct {
+	Hostname     string   `json:"hostname,omitempty"`
+	Emails       []string `json:"emails"`
+	EmailDomains []string `json:"emailDomains"`
+	GitHubOrgs   []string `json:"githubOrgs"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// SecretSetRequest is the request body for storing a new secret version.
+type SecretSetRequest struct {
+	Value string `json:"value"`
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"go-notes/internal/models"
+	"gorm.io/gorm"
+)
+
+type GormDeploymentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormDeploymentRepository(db *gorm.DB) *GormDeploymentRepository {
+	return &GormDeploymentRepository{db: db}
+}
+
+func (r *GormDeploymentRepository) ListByProject(ctx context.Context, projectID uint) ([]models.Deployment, error) {
+	var deployments []models.Deployment
+	if err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Order("id asc").Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+func (r *GormDeploymentRepository) Save(ctx context.Context, deployment *models.Deployment) error {
+	return r.db.WithContext(ctx).Save(deployment).Error
+}
+
+func (r *GormDeploymentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Deployment{}, id).Error
+}
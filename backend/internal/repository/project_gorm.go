@@ -43,3 +43,7 @@ func (r *GormProjectRepository) GetByName(ctx context.Context, name string) (*mo
 func (r *GormProjectRepository) Update(ctx context.Context, project *models.Project) error {
 	return r.db.WithContext(ctx).Save(project).Error
 }
+
+func (r *GormProjectRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.Project{}, id).Error
+}
@@ -8,7 +8,10 @@ import (
 	"go-notes/internal/models"
 )
 
-var ErrNotFound = errors.New("record not found")
+var (
+	ErrNotFound = errors.New("record not found")
+	ErrConflict = errors.New("record was modified concurrently")
+)
 
 type UserRepository interface {
 	UpsertFromGitHub(githubID int64, login, avatarURL string) (*models.User, error)
@@ -28,6 +31,14 @@ type ProjectRepository interface {
 	Create(ctx context.Context, project *models.Project) error
 	GetByName(ctx context.Context, name string) (*models.Project, error)
 	Update(ctx context.Context, project *models.Project) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// DeploymentRepository stores the hostname and port a project is published on.
+type DeploymentRepository interface {
+	ListByProject(ctx context.Context, projectID uint) ([]models.Deployment, error)
+	Save(ctx context.Context, deployment *models.Deployment) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type JobRepository interface {
@@ -47,7 +58,47 @@ type SettingsRepository interface {
 	Save(ctx context.Context, settings *models.Settings) error
 }
 
+// SecretRepository stores vault secret versions. Versions are append-only
+// until their name is deleted; UpdateCiphertexts rewrites rows in place during
+// key rotation and must apply all of them or none.
+type SecretRepository interface {
+	List(ctx context.Context, scope, project string) ([]models.SecretVersion, error)
+	ListVersions(ctx context.Context, scope, project, name string) ([]models.SecretVersion, error)
+	ListAll(ctx context.Context) ([]models.SecretVersion, error)
+	Create(ctx context.Context, version *models.SecretVersion) error
+	DeleteName(ctx context.Context, scope, project, name string) (int64, error)
+	UpdateCiphertexts(ctx context.Context, versions []models.SecretVersion) error
+}
+
+// TunnelRepository stores the Cloudflare tunnels projects can be assigned to.
+// Delete removes the row for good so its name can be reused.
+type TunnelRepository interface {
+	List(ctx context.Context) ([]models.CloudflareTunnel, error)
+	GetByName(ctx context.Context, name string) (*models.CloudflareTunnel, error)
+	Create(ctx context.Context, tunnel *models.CloudflareTunnel) error
+	Update(ctx context.Context, tunnel *models.CloudflareTunnel) error
+	Delete(ctx context.Context, id uint) error
+}
+
 type AuditLogRepository interface {
 	List(ctx context.Context, limit int) ([]models.AuditLog, error)
 	Create(ctx context.Context, entry *models.AuditLog) error
 }
+
+// WorkbenchSnapshotRepository stores one snapshot row per project. Save only
+// succeeds when the stored revision still equals expectedRevision (0 inserts a
+// new row); a negative expectedRevision writes unconditionally.
+type WorkbenchSnapshotRepository interface {
+	Get(ctx context.Context, projectName string) (*models.WorkbenchSnapshot, error)
+	List(ctx context.Context) ([]models.WorkbenchSnapshot, error)
+	Save(ctx context.Context, snapshot *models.WorkbenchSnapshot, expectedRevision int) error
+	// SaveWithRevision is Save plus the revision log insert, committed together.
+	SaveWithRevision(ctx context.Context, snapshot *models.WorkbenchSnapshot, expectedRevision int, revision *models.WorkbenchSnapshotRevision) error
+	Delete(ctx context.Context, projectName string) error
+}
+
+type WorkbenchRevisionRepository interface {
+	Create(ctx context.Context, revision *models.WorkbenchSnapshotRevision) error
+	ListByProject(ctx context.Context, projectName string, limit int) ([]models.WorkbenchSnapshotRevision, error)
+	GetByProjectRevision(ctx context.Context, projectName string, revision int) (*models.WorkbenchSnapshotRevision, error)
+}
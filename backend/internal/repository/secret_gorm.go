@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"go-notes/internal/models"
+	"gorm.io/gorm"
+)
+
+type GormSecretRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSecretRepository(db *gorm.DB) *GormSecretRepository {
+	return &GormSecretRepository{db: db}
+}
+
+func (r *GormSecretRepository) List(ctx context.Context, scope, project string) ([]models.SecretVersion, error) {
+	var versions []models.SecretVersion
+	if err := r.db.WithContext(ctx).
+		Where("scope = ? AND project = ?", scope, project).
+		Order("name asc, version desc").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *GormSecretRepository) ListVersions(ctx context.Context, scope, project, name string) ([]models.SecretVersion, error) {
+	var versions []models.SecretVersion
+	if err := r.db.WithContext(ctx).
+		Where("scope = ? AND project = ? AND name = ?", scope, project, name).
+		Order("version desc").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *GormSecretRepository) ListAll(ctx context.Context) ([]models.SecretVersion, error) {
+	var versions []models.SecretVersion
+	if err := r.db.WithContext(ctx).Order("id asc").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *GormSecretRepository) Create(ctx context.Context, version *models.SecretVersion) error {
+	return r.db.WithContext(ctx).Create(version).Error
+}
+
+// DeleteName hard-deletes every version so no ciphertext outlives the secret.
+func (r *GormSecretRepository) DeleteName(ctx context.Context, scope, project, name string) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("scope = ? AND project = ? AND name = ?", scope, project, name).
+		Delete(&models.SecretVersion{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormSecretRepository) UpdateCiphertexts(ctx context.Context, versions []models.SecretVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, version := range versions {
+			if err := tx.Model(&models.SecretVersion{}).
+				Where("id = ?", version.ID).
+				Updates(map[string]any{"ciphertext": version.Ciphertext, "key_id": version.KeyID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go-notes/internal/models"
+	"gorm.io/gorm"
+)
+
+type GormTunnelRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTunnelRepository(db *gorm.DB) *GormTunnelRepository {
+	return &GormTunnelRepository{db: db}
+}
+
+func (r *GormTunnelRepository) List(ctx context.Context) ([]models.CloudflareTunnel, error) {
+	var tunnels []models.CloudflareTunnel
+	if err := r.db.WithContext(ctx).Order("name asc").Find(&tunnels).Error; err != nil {
+		return nil, err
+	}
+	return tunnels, nil
+}
+
+func (r *GormTunnelRepository) GetByName(ctx context.Context, name string) (*models.CloudflareTunnel, error) {
+	var tunnel models.CloudflareTunnel
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&tunnel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &tunnel, nil
+}
+
+func (r *GormTunnelRepository) Create(ctx context.Context, tunnel *models.CloudflareTunnel) error {
+	return r.db.WithContext(ctx).Create(tunnel).Error
+}
+
+func (r *GormTunnelRepository) Update(ctx context.Context, tunnel *models.CloudflareTunnel) error {
+	return r.db.WithContext(ctx).Save(tunnel).Error
+}
+
+func (r *GormTunnelRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.CloudflareTunnel{}, id).Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go-notes/internal/models"
+	"gorm.io/gorm"
+)
+
+type GormWorkbenchRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWorkbenchRevisionRepository(db *gorm.DB) *GormWorkbenchRevisionRepository {
+	return &GormWorkbenchRevisionRepository{db: db}
+}
+
+func (r *GormWorkbenchRevisionRepository) Create(ctx context.Context, revision *models.WorkbenchSnapshotRevision) error {
+	return r.db.WithContext(ctx).Create(revision).Error
+}
+
+func (r *GormWorkbenchRevisionRepository) ListByProject(ctx context.Context, projectName string, limit int) ([]models.WorkbenchSnapshotRevision, error) {
+	var revisions []models.WorkbenchSnapshotRevision
+	query := r.db.WithContext(ctx).
+		Omit("snapshot").
+		Where("project_name = ?", projectName).
+		Order("revision desc, id desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *GormWorkbenchRevisionRepository) GetByProjectRevision(ctx context.Context, projectName string, revision int) (*models.WorkbenchSnapshotRevision, error) {
+	var entry models.WorkbenchSnapshotRevision
+	if err := r.db.WithContext(ctx).
+		Where("project_name = ? AND revision = ?", projectName, revision).
+		Order("id desc").
+		First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-notes/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormWorkbenchSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWorkbenchSnapshotRepository(db *gorm.DB) *GormWorkbenchSnapshotRepository {
+	return &GormWorkbenchSnapshotRepository{db: db}
+}
+
+func (r *GormWorkbenchSnapshotRepository) Get(ctx context.Context, projectName string) (*models.WorkbenchSnapshot, error) {
+	var snapshot models.WorkbenchSnapshot
+	if err := r.db.WithContext(ctx).Where("project_name = ?", projectName).First(&snapshot).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (r *GormWorkbenchSnapshotRepository) List(ctx context.Context) ([]models.WorkbenchSnapshot, error) {
+	var snapshots []models.WorkbenchSnapshot
+	if err := r.db.WithContext(ctx).Order("project_name asc").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (r *GormWorkbenchSnapshotRepository) Save(ctx context.Context, snapshot *models.WorkbenchSnapshot, expectedRevision int) error {
+	return saveWorkbenchSnapshot(r.db.WithContext(ctx), snapshot, expectedRevision)
+}
+
+func (r *GormWorkbenchSnapshotRepository) SaveWithRevision(
+	ctx context.Context,
+	snapshot *models.WorkbenchSnapshot,
+	expectedRevision int,
+	revision *models.WorkbenchSnapshotRevision,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveWorkbenchSnapshot(tx, snapshot, expectedRevision); err != nil {
+			return err
+		}
+		return tx.Create(revision).Error
+	})
+}
+
+func (r *GormWorkbenchSnapshotRepository) Delete(ctx context.Context, projectName string) error {
+	return r.db.WithContext(ctx).Unscoped().Where("project_name = ?", projectName).Delete(&models.WorkbenchSnapshot{}).Error
+}
+
+func saveWorkbenchSnapshot(db *gorm.DB, snapshot *models.WorkbenchSnapshot, expectedRevision int) error {
+	switch {
+	case expectedRevision < 0:
+		return db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revision", "payload", "updated_at", "deleted_at"}),
+		}).Create(snapshot).Error
+	case expectedRevision == 0:
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(snapshot)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConflict
+		}
+		return nil
+	default:
+		result := db.Model(&models.WorkbenchSnapshot{}).
+			Where("project_name = ? AND revision = ?", snapshot.ProjectName, expectedRevision).
+			Updates(map[string]any{
+				"revision":   snapshot.Revision,
+				"payload":    snapshot.Payload,
+				"updated_at": time.Now().UTC(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConflict
+		}
+		return nil
+	}
+}
@@ -20,6 +20,9 @@ type Dependencies struct {
 	Users           *controller.UsersController
 	GitHub          *controller.GitHubController
 	Cloudflare      *controller.CloudflareController
+	Secrets         *controller.SecretsController
+	DNS             *controller.DNSController
+	Tunnels         *controller.TunnelsController
 	AllowedOrigins  []string
 	AuthMiddleware  gin.HandlerFunc
 	UsersMiddleware gin.HandlerFunc
@@ -44,7 +47,7 @@ func NewRouter(deps Dependencies) *gin.Engine {
 		adminGroup.Use(deps.UsersMiddleware)
 	}
 
-	routes.Register(r, authed, adminGroup, routes.Dependencies{
+	routes.Register(r, api, authed, adminGroup, routes.Dependencies{
 		Health:     deps.Health,
 		Auth:       deps.Auth,
 		Projects:   deps.Projects,
@@ -56,6 +59,9 @@ func NewRouter(deps Dependencies) *gin.Engine {
 		Users:      deps.Users,
 		GitHub:     deps.GitHub,
 		Cloudflare: deps.Cloudflare,
+		Secrets:    deps.Secrets,
+		DNS:        deps.DNS,
+		Tunnels:    deps.Tunnels,
 	})
 
 	return r
@@ -12,4 +12,6 @@ func RegisterCloudflare(r gin.IRoutes, c *controller.CloudflareController) {
 	}
 	r.GET("/cloudflare/preflight", c.Preflight)
 	r.GET("/cloudflare/zones", c.Zones)
+	r.GET("/cloudflare/reconcile", c.Reconcile)
+	r.POST("/cloudflare/reconcile/cleanup", c.ReconcileCleanup)
 }
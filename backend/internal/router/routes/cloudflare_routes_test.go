@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func TestRegisterCloudflareIncludesReconcileRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterCloudflare(router, &controller.CloudflareController{})
+
+	expected := map[string]bool{
+		"GET /cloudflare/preflight":          false,
+		"GET /cloudflare/zones":              false,
+		"GET /cloudflare/reconcile":          false,
+		"POST /cloudflare/reconcile/cleanup": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected route %s to be registered", route)
+		}
+	}
+}
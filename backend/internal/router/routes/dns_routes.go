@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func RegisterDNS(r gin.IRoutes, c *controller.DNSController) {
+	if c == nil {
+		return
+	}
+	r.GET("/dns", c.List)
+	r.POST("/dns", c.Create)
+	r.PUT("/dns/:zone/:record", c.Update)
+	r.DELETE("/dns/:zone/:record", c.Delete)
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func TestRegisterDNSIncludesRecordRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterDNS(router, &controller.DNSController{})
+
+	expected := map[string]bool{
+		"GET /dns":                  false,
+		"POST /dns":                 false,
+		"PUT /dns/:zone/:record":    false,
+		"DELETE /dns/:zone/:record": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected route %s to be registered", route)
+		}
+	}
+}
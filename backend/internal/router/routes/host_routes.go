@@ -12,6 +12,7 @@ func RegisterHost(r gin.IRoutes, c *controller.HostController) {
 	}
 	r.GET("/host/docker", c.ListDocker)
 	r.GET("/host/docker/usage", c.DockerUsage)
+	r.GET("/host/images/updates", c.QuickServiceImageUpdates)
 	r.GET("/host/stats", c.RuntimeStats)
 	r.GET("/host/stats/stream", c.StreamRuntimeStats)
 	r.GET("/host/docker/logs", c.StreamDockerLogs)
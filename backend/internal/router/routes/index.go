@@ -19,12 +19,17 @@ type Dependencies struct {
 	Users      *controller.UsersController
 	GitHub     *controller.GitHubController
 	Cloudflare *controller.CloudflareController
+	Secrets    *controller.SecretsController
+	DNS        *controller.DNSController
+	Tunnels    *controller.TunnelsController
 }
 
-// Register wires all public and authenticated route modules.
-func Register(root gin.IRoutes, authed gin.IRoutes, admin gin.IRoutes, deps Dependencies) {
+// Register wires all public and authenticated route modules. Public routes
+// live under the API prefix but skip the session middleware.
+func Register(root gin.IRoutes, public gin.IRoutes, authed gin.IRoutes, admin gin.IRoutes, deps Dependencies) {
 	RegisterHealth(root, deps.Health)
 	RegisterAuth(root, deps.Auth)
+	RegisterWebhooks(public, deps.Projects)
 
 	RegisterProjects(authed, deps.Projects)
 	RegisterJobs(authed, deps.Jobs)
@@ -36,4 +41,7 @@ func Register(root gin.IRoutes, authed gin.IRoutes, admin gin.IRoutes, deps Depe
 	RegisterUsersAdmin(admin, deps.Users)
 	RegisterGitHub(authed, deps.GitHub)
 	RegisterCloudflare(authed, deps.Cloudflare)
+	RegisterSecrets(authed, deps.Secrets)
+	RegisterDNS(authed, deps.DNS)
+	RegisterTunnels(authed, deps.Tunnels)
 }
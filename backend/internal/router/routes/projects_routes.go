@@ -12,25 +12,64 @@ func RegisterProjects(r gin.IRoutes, c *controller.ProjectsController) {
 	}
 	r.GET("/projects", c.List)
 	r.GET("/projects/local", c.ListLocal)
+	r.POST("/workbench/catalog/reload", c.WorkbenchReloadCatalog)
+	r.GET("/workbench/drift", c.WorkbenchDriftScan)
 	r.GET("/projects/:name", c.Detail)
 	r.GET("/projects/:name/jobs", c.ListJobs)
 	r.GET("/projects/:name/workbench", c.WorkbenchSnapshot)
 	r.GET("/projects/:name/workbench/graph", c.WorkbenchGraph)
+	r.GET("/projects/:name/workbench/graph/services/:serviceName/restart-plan", c.WorkbenchServiceRestartPlan)
+	r.POST("/projects/:name/workbench/graph/services/:serviceName/restart", c.WorkbenchRestartService)
 	r.GET("/projects/:name/workbench/catalog", c.WorkbenchCatalog)
 	r.POST("/projects/:name/workbench/import", c.WorkbenchImport)
+	r.GET("/projects/:name/workbench/export", c.WorkbenchExport)
+	r.POST("/projects/:name/workbench/import-bundle", c.WorkbenchImportBundle)
+	r.GET("/projects/:name/workbench/drift", c.WorkbenchDrift)
+	r.GET("/projects/:name/images/updates", c.ImageUpdates)
+	r.POST("/projects/:name/images/update", c.UpdateImages)
+	r.POST("/projects/:name/deploy/blue-green", c.BlueGreenDeploy)
+	r.POST("/projects/:name/deploy/git", c.GitRedeploy)
+	r.PUT("/projects/:name/deploy/git/settings", c.UpdateGitDeploySettings)
 	r.POST("/projects/:name/workbench/ports/resolve", c.WorkbenchResolvePorts)
 	r.POST("/projects/:name/workbench/ports/mutate", c.WorkbenchMutatePort)
 	r.POST("/projects/:name/workbench/ports/suggest", c.WorkbenchSuggestPorts)
 	r.POST("/projects/:name/workbench/services", c.WorkbenchAddService)
 	r.DELETE("/projects/:name/workbench/services/:serviceName", c.WorkbenchRemoveService)
 	r.PATCH("/projects/:name/workbench/services/:serviceName/resources", c.WorkbenchMutateResource)
+	r.POST("/projects/:name/workbench/services/:serviceName/networks", c.WorkbenchMutateServiceNetworks)
+	r.POST("/projects/:name/workbench/networks", c.WorkbenchCreateNetwork)
+	r.PATCH("/projects/:name/workbench/networks/:network", c.WorkbenchUpdateNetwork)
+	r.DELETE("/projects/:name/workbench/networks/:network", c.WorkbenchDeleteNetwork)
 	r.POST("/projects/:name/workbench/modules", c.WorkbenchMutateModule)
+	r.GET("/projects/:name/workbench/policy", c.WorkbenchPolicy)
+	r.PUT("/projects/:name/workbench/policy", c.WorkbenchUpdatePolicy)
+	r.GET("/projects/:name/workbench/revisions", c.WorkbenchRevisions)
+	r.GET("/projects/:name/workbench/revisions/:revision", c.WorkbenchRevision)
+	r.POST("/projects/:name/workbench/revisions/:revision/revert", c.WorkbenchRevert)
 	r.POST("/projects/:name/workbench/compose/preview", c.WorkbenchComposePreview)
 	r.POST("/projects/:name/workbench/compose/apply", c.WorkbenchComposeApply)
 	r.GET("/projects/:name/workbench/compose/backups", c.WorkbenchComposeBackups)
 	r.POST("/projects/:name/workbench/compose/restore", c.WorkbenchComposeRestore)
 	r.GET("/projects/:name/archive/plan", c.ArchivePlan)
 	r.POST("/projects/:name/archive", c.Archive)
+	r.POST("/projects/:name/restore", c.Restore)
+	r.GET("/projects/:name/backups", c.ListBackups)
+	r.POST("/projects/:name/backups", c.CreateBackup)
+	r.PUT("/projects/:name/backups/policy", c.UpdateBackupPolicy)
+	r.POST("/projects/:name/backups/:backupId/restore", c.RestoreBackup)
+	r.GET("/projects/:name/databases", c.Databases)
+	r.POST("/projects/:name/databases/dumps", c.CreateDatabaseDump)
+	r.POST("/projects/:name/databases/dumps/:dumpId/restore", c.RestoreDatabaseDump)
+	r.PATCH("/projects/:name/hostnames", c.ChangeHostnames)
+	r.POST("/projects/:name/clone", c.Clone)
+	r.GET("/projects/:name/routes", c.Routes)
+	r.PUT("/projects/:name/routes", c.UpdateRoutes)
+	r.GET("/projects/:name/access", c.Access)
+	r.PUT("/projects/:name/access", c.UpdateAccess)
+	r.DELETE("/projects/:name/access", c.RemoveAccess)
+	r.GET("/projects/:name/ingress", c.Ingress)
+	r.PUT("/projects/:name/ingress", c.UpdateIngress)
+	r.PUT("/projects/:name/tunnel", c.UpdateTunnel)
 	r.POST("/projects/:name/stack/restart", c.RestartStack)
 	r.POST("/projects/:name/containers/stop", c.StopContainer)
 	r.POST("/projects/:name/containers/restart", c.RestartContainer)
@@ -38,6 +77,10 @@ func RegisterProjects(r gin.IRoutes, c *controller.ProjectsController) {
 	r.GET("/projects/:name/logs", c.StreamLogs)
 	r.GET("/projects/:name/env", c.ReadEnv)
 	r.PUT("/projects/:name/env", c.WriteEnv)
+	r.POST("/projects/:name/env/validate", c.ValidateEnv)
+	r.GET("/projects/:name/env/history", c.EnvHistory)
+	r.GET("/projects/:name/env/diff", c.EnvDiff)
+	r.POST("/projects/:name/env/history/:versionId/restore", c.RestoreEnv)
 	r.POST("/projects/template", c.CreateFromTemplate)
 	r.POST("/projects/existing", c.DeployExisting)
 	r.POST("/projects/forward", c.ForwardLocal)
@@ -42,6 +42,23 @@ func TestRegisterProjectsIncludesWorkbenchCatalogRoute(t *testing.T) {
 	t.Fatal("expected GET /projects/:name/workbench/catalog route to be registered")
 }
 
+func TestRegisterProjectsIncludesWorkbenchCatalogReloadRoute(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	for _, route := range router.Routes() {
+		if route.Method == "POST" && route.Path == "/workbench/catalog/reload" {
+			return
+		}
+	}
+
+	t.Fatal("expected POST /workbench/catalog/reload route to be registered")
+}
+
 func TestRegisterProjectsIncludesWorkbenchServiceMutationRoutes(t *testing.T) {
 	t.Parallel()
 
@@ -85,3 +102,430 @@ func TestRegisterProjectsIncludesWorkbenchModuleMutationRoute(t *testing.T) {
 
 	t.Fatal("expected POST /projects/:name/workbench/modules route to be registered")
 }
+
+func TestRegisterProjectsIncludesWorkbenchPolicyRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	found := map[string]bool{}
+	for _, route := range router.Routes() {
+		if route.Path == "/projects/:name/workbench/policy" {
+			found[route.Method] = true
+		}
+	}
+
+	if !found["GET"] || !found["PUT"] {
+		t.Fatalf("expected GET and PUT /projects/:name/workbench/policy routes to be registered, got %v", found)
+	}
+}
+
+func TestRegisterProjectsIncludesWorkbenchRevisionRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/workbench/revisions":                   false,
+		"GET /projects/:name/workbench/revisions/:revision":         false,
+		"POST /projects/:name/workbench/revisions/:revision/revert": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesWorkbenchDriftRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/workbench/drift": false,
+		"GET /workbench/drift":                false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesWorkbenchNetworkRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"POST /projects/:name/workbench/networks":                       false,
+		"PATCH /projects/:name/workbench/networks/:network":             false,
+		"DELETE /projects/:name/workbench/networks/:network":            false,
+		"POST /projects/:name/workbench/services/:serviceName/networks": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesWorkbenchBundleRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/workbench/export":         false,
+		"POST /projects/:name/workbench/import-bundle": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesWorkbenchServiceRestartRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/workbench/graph/services/:serviceName/restart-plan": false,
+		"POST /projects/:name/workbench/graph/services/:serviceName/restart":     false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesImageUpdateRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/images/updates": false,
+		"POST /projects/:name/images/update": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesBlueGreenDeployRoute(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	for _, route := range router.Routes() {
+		if route.Method == "POST" && route.Path == "/projects/:name/deploy/blue-green" {
+			return
+		}
+	}
+	t.Fatalf("expected POST /projects/:name/deploy/blue-green route to be registered")
+}
+
+func TestRegisterProjectsIncludesGitDeployRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"POST /projects/:name/deploy/git":         false,
+		"PUT /projects/:name/deploy/git/settings": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesHostnamesRoute(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	for _, route := range router.Routes() {
+		if route.Method == "PATCH" && route.Path == "/projects/:name/hostnames" {
+			return
+		}
+	}
+	t.Fatalf("expected PATCH /projects/:name/hostnames route to be registered")
+}
+
+func TestRegisterProjectsIncludesEnvHistoryRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"POST /projects/:name/env/validate":                   false,
+		"GET /projects/:name/env/history":                     false,
+		"GET /projects/:name/env/diff":                        false,
+		"POST /projects/:name/env/history/:versionId/restore": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesCloneRoute(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	for _, route := range router.Routes() {
+		if route.Method == "POST" && route.Path == "/projects/:name/clone" {
+			return
+		}
+	}
+	t.Fatalf("expected POST /projects/:name/clone route to be registered")
+}
+
+func TestRegisterProjectsIncludesRoutesRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/routes": false,
+		"PUT /projects/:name/routes": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesRestoreRoute(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	for _, route := range router.Routes() {
+		if route.Method == "POST" && route.Path == "/projects/:name/restore" {
+			return
+		}
+	}
+
+	t.Fatal("expected POST /projects/:name/restore route to be registered")
+}
+
+func TestRegisterProjectsIncludesBackupRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/backups":                    false,
+		"POST /projects/:name/backups":                   false,
+		"PUT /projects/:name/backups/policy":             false,
+		"POST /projects/:name/backups/:backupId/restore": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesDatabaseRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/databases":                        false,
+		"POST /projects/:name/databases/dumps":                 false,
+		"POST /projects/:name/databases/dumps/:dumpId/restore": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesAccessRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/access":    false,
+		"PUT /projects/:name/access":    false,
+		"DELETE /projects/:name/access": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
+
+func TestRegisterProjectsIncludesIngressRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	RegisterProjects(router, &controller.ProjectsController{})
+
+	expected := map[string]bool{
+		"GET /projects/:name/ingress": false,
+		"PUT /projects/:name/ingress": false,
+		"PUT /projects/:name/tunnel":  false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected %s route to be registered", route)
+		}
+	}
+}
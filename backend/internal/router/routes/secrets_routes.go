@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func RegisterSecrets(r gin.IRoutes, c *controller.SecretsController) {
+	if c == nil {
+		return
+	}
+	r.GET("/secrets", c.Status)
+	r.GET("/projects/:name/secrets", c.ListProject)
+	r.PUT("/projects/:name/secrets/:secret", c.SetProject)
+	r.DELETE("/projects/:name/secrets/:secret", c.DeleteProject)
+	r.GET("/projects/:name/secrets/:secret/versions", c.ProjectVersions)
+	r.POST("/projects/:name/secrets/:secret/versions/:version/restore", c.RestoreProjectVersion)
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func TestRegisterSecretsIncludesVaultRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterSecrets(router, &controller.SecretsController{})
+
+	expected := map[string]bool{
+		"GET /secrets":                                                   false,
+		"GET /projects/:name/secrets":                                    false,
+		"PUT /projects/:name/secrets/:secret":                            false,
+		"DELETE /projects/:name/secrets/:secret":                         false,
+		"GET /projects/:name/secrets/:secret/versions":                   false,
+		"POST /projects/:name/secrets/:secret/versions/:version/restore": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected route %s to be registered", route)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func RegisterTunnels(r gin.IRoutes, c *controller.TunnelsController) {
+	if c == nil {
+		return
+	}
+	r.GET("/tunnels", c.List)
+	r.POST("/tunnels", c.Create)
+	r.PUT("/tunnels/:name", c.Update)
+	r.DELETE("/tunnels/:name", c.Delete)
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func TestRegisterTunnelsIncludesTunnelRoutes(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterTunnels(router, &controller.TunnelsController{})
+
+	expected := map[string]bool{
+		"GET /tunnels":          false,
+		"POST /tunnels":         false,
+		"PUT /tunnels/:name":    false,
+		"DELETE /tunnels/:name": false,
+	}
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := expected[key]; ok {
+			expected[key] = true
+		}
+	}
+	for route, found := range expected {
+		if !found {
+			t.Fatalf("expected route %s to be registered", route)
+		}
+	}
+}
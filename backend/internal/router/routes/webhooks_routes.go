@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+// RegisterWebhooks wires unauthenticated webhook receivers. Each handler
+// verifies its own request signature.
+func RegisterWebhooks(r gin.IRoutes, projects *controller.ProjectsController) {
+	if projects == nil {
+		return
+	}
+	r.POST("/webhooks/github", projects.GitHubWebhook)
+}
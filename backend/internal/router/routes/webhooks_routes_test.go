@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-notes/internal/controller"
+)
+
+func TestRegisterMountsGitHubWebhookOutsideAuthedGroup(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1")
+	authed := api.Group("")
+	authed.Use(func(ctx *gin.Context) { ctx.AbortWithStatus(http.StatusUnauthorized) })
+
+	Register(router, api, authed, authed, Dependencies{Projects: &controller.ProjectsController{}})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", strings.NewReader("{}"))
+	router.ServeHTTP(recorder, request)
+
+	// The controller has no project service, so reaching it yields 503 rather
+	// than the session middleware's 401.
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected webhook to bypass session auth, got status %d", recorder.Code)
+	}
+}
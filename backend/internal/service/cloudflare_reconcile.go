@@ -0,0 +1,625 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+const (
+	// CloudflareFindingDNSWithoutIngress is a CNAME to the tunnel that no
+	// ingress rule answers for; visitors get the tunnel's catch-all.
+	CloudflareFindingDNSWithoutIngress = "dns_without_ingress"
+	// CloudflareFindingIngressWithoutProject is an ingress rule no live project
+	// or service exposure owns.
+	CloudflareFindingIngressWithoutProject = "ingress_without_project"
+	// CloudflareFindingDNSPointsElsewhere is an ingress hostname whose DNS is
+	// missing or does not point at the tunnel. It is reported only.
+	CloudflareFindingDNSPointsElsewhere = "dns_points_elsewhere"
+)
+
+type CloudflareReconcileZone struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+}
+
+type CloudflareReconcileFinding struct {
+	ID         string `json:"id"`
+	Kind       string `json:"kind"`
+	Hostname   string `json:"hostname"`
+	ZoneID     string `json:"zoneId,omitempty"`
+	RecordID   string `json:"recordId,omitempty"`
+	RecordType string `json:"recordType,omitempty"`
+	Content    string `json:"content,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Service    string `json:"service,omitempty"`
+	Source     string `json:"source,omitempty"`
+	// Tunnel is the stored tunnel the record or rule belongs to; empty for
+	// the default tunnel.
+	Tunnel string `json:"tunnel,omitempty"`
+	// Project is the archived project that last owned the hostname.
+	Project    string `json:"project,omitempty"`
+	Detail     string `json:"detail"`
+	Cleanable  bool   `json:"cleanable"`
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// CloudflareReconcileTunnel is one scanned tunnel. Name is DefaultTunnelName
+// for the settings tunnel.
+type CloudflareReconcileTunnel struct {
+	Name         string `json:"name"`
+	Target       string `json:"target"`
+	IngressRules int    `json:"ingressRules"`
+}
+
+type CloudflareReconcileReport struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	// TunnelTarget is the default tunnel's CNAME target.
+	TunnelTarget   string                       `json:"tunnelTarget"`
+	Tunnels        []CloudflareReconcileTunnel  `json:"tunnels"`
+	Zones          []CloudflareReconcileZone    `json:"zones"`
+	IngressRules   int                          `json:"ingressRules"`
+	KnownHostnames int                          `json:"knownHostnames"`
+	Findings       []CloudflareReconcileFinding `json:"findings"`
+	Warnings       []string                     `json:"warnings"`
+}
+
+type CloudflareReconcileCleanupJobRequest struct {
+	Findings    []CloudflareReconcileFinding `json:"findings"`
+	PlannedAt   time.Time                    `json:"plannedAt"`
+	RequestedBy ProjectArchiveActor          `json:"requestedBy"`
+}
+
+// CloudflareReconcileService compares tunnel ingress rules, DNS records in the
+// configured zones, and the hostnames gungnr knows about to find orphans.
+type CloudflareReconcileService struct {
+	settings *SettingsService
+	projects repository.ProjectRepository
+	archive  *ProjectArchiveService
+	jobs     *JobService
+	tunnels  *TunnelService
+}
+
+func NewCloudflareReconcileService(
+	settings *SettingsService,
+	projects repository.ProjectRepository,
+	archive *ProjectArchiveService,
+	jobs *JobService,
+) *CloudflareReconcileService {
+	return &CloudflareReconcileService{
+		settings: settings,
+		projects: projects,
+		archive:  archive,
+		jobs:     jobs,
+	}
+}
+
+// SetTunnels adds the stored tunnels to the scan. Without it only the
+// settings tunnel is compared.
+func (s *CloudflareReconcileService) SetTunnels(tunnels *TunnelService) {
+	s.tunnels = tunnels
+}
+
+// cloudflareReconcileState is everything the comparison needs. The complete
+// flags record whether a source was read in full; findings that would
+// otherwise rest on a partial view are reported but not cleanable.
+type cloudflareReconcileState struct {
+	tunnels           []cloudflareReconcileTunnel
+	zones             []cloudflareReconcileZoneRecords
+	known             map[string]struct{}
+	archived          map[string]string
+	ownershipComplete bool
+}
+
+// cloudflareReconcileTunnel is the ingress of one tunnel and the CNAME target
+// its DNS records point at. name is empty for the default tunnel.
+type cloudflareReconcileTunnel struct {
+	name            string
+	target          string
+	ingress         []ProjectArchivePlanIngress
+	ingressComplete bool
+}
+
+type cloudflareReconcileZoneRecords struct {
+	zone    cloudflare.ZoneInfo
+	records []cloudflare.DNSRecord
+}
+
+// Report scans Cloudflare and the project store and returns every finding.
+// Nothing is changed.
+func (s *CloudflareReconcileService) Report(ctx context.Context) (CloudflareReconcileReport, error) {
+	if s.settings == nil {
+		return CloudflareReconcileReport{}, fmt.Errorf("settings service unavailable")
+	}
+	cfg, err := s.settings.ResolveConfig(ctx)
+	if err != nil {
+		return CloudflareReconcileReport{}, err
+	}
+	warnings := make(map[string]struct{})
+
+	if strings.TrimSpace(cfg.CloudflareAPIToken) == "" {
+		return CloudflareReconcileReport{}, errs.New(errs.CodeCloudflareMissingToken, cloudflare.ErrMissingToken.Error())
+	}
+	state := cloudflareReconcileState{}
+	names := []string{""}
+	if stored, err := s.tunnels.Names(ctx); err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("failed to list tunnels: %v", err))
+	} else {
+		names = append(names, stored...)
+	}
+	scannedZones := make(map[string]struct{})
+	for _, name := range names {
+		tunnelCfg, err := s.tunnels.Config(ctx, cfg, name)
+		if err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("tunnel %s: %v", name, err))
+			continue
+		}
+		tunnelWarnings := make(map[string]struct{})
+		client := cloudflare.NewClient(tunnelCfg)
+		tunnel := cloudflareReconcileTunnel{name: name}
+		if target, err := client.ExpectedTunnelCNAME(ctx); err != nil {
+			addArchiveWarning(tunnelWarnings, fmt.Sprintf("failed to resolve tunnel DNS target: %v", err))
+		} else {
+			tunnel.target = strings.ToLower(strings.TrimSpace(target))
+		}
+		tunnel.ingress, tunnel.ingressComplete = listReconcileIngress(ctx, tunnelCfg, client, tunnelWarnings)
+		state.tunnels = append(state.tunnels, tunnel)
+
+		// Tunnels in another account or zone bring their own DNS records.
+		zoneKey := tunnelCfg.CloudflareAccountID + "|" + tunnelCfg.CloudflareZoneID + "|" + tunnelCfg.CloudflareAPIToken
+		if _, scanned := scannedZones[zoneKey]; !scanned {
+			scannedZones[zoneKey] = struct{}{}
+			state.zones = appendReconcileZones(state.zones, s.listReconcileZones(ctx, tunnelCfg, client, tunnelWarnings))
+		}
+		for warning := range tunnelWarnings {
+			if name != "" {
+				warning = fmt.Sprintf("tunnel %s: %s", name, warning)
+			}
+			addArchiveWarning(warnings, warning)
+		}
+	}
+	state.known, state.archived, state.ownershipComplete, err = s.knownHostnames(ctx, cfg, warnings)
+	if err != nil {
+		return CloudflareReconcileReport{}, err
+	}
+
+	report := CloudflareReconcileReport{
+		GeneratedAt:    time.Now().UTC(),
+		Tunnels:        make([]CloudflareReconcileTunnel, 0, len(state.tunnels)),
+		Zones:          make([]CloudflareReconcileZone, 0, len(state.zones)),
+		KnownHostnames: len(state.known),
+		Findings:       reconcileCloudflare(state),
+	}
+	for _, tunnel := range state.tunnels {
+		name := tunnel.name
+		if name == "" {
+			name = DefaultTunnelName
+			report.TunnelTarget = tunnel.target
+		}
+		report.Tunnels = append(report.Tunnels, CloudflareReconcileTunnel{Name: name, Target: tunnel.target, IngressRules: len(tunnel.ingress)})
+		report.IngressRules += len(tunnel.ingress)
+	}
+	for _, zone := range state.zones {
+		report.Zones = append(report.Zones, CloudflareReconcileZone{ID: zone.zone.ID, Name: zone.zone.Name, Records: len(zone.records)})
+	}
+	report.Warnings = sortedArchiveWarnings(warnings)
+	return report, nil
+}
+
+// QueueCleanup queues a job removing the selected findings. Each one must be
+// cleanable in a fresh report; the job checks again before deleting.
+func (s *CloudflareReconcileService) QueueCleanup(ctx context.Context, findingIDs []string, actor ProjectArchiveActor) (*models.Job, []CloudflareReconcileFinding, error) {
+	if s.jobs == nil {
+		return nil, nil, fmt.Errorf("job service unavailable")
+	}
+	ids := dedupeStrings(findingIDs)
+	if len(ids) == 0 {
+		return nil, nil, errs.New(errs.CodeCloudflareReconcileInvalid, "select at least one finding to clean up")
+	}
+	report, err := s.Report(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	selected, err := selectReconcileFindings(report.Findings, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := s.jobs.Create(ctx, JobTypeCloudflareReconcileCleanup, CloudflareReconcileCleanupJobRequest{
+		Findings:    selected,
+		PlannedAt:   time.Now().UTC(),
+		RequestedBy: actor,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, selected, nil
+}
+
+func selectReconcileFindings(findings []CloudflareReconcileFinding, ids []string) ([]CloudflareReconcileFinding, error) {
+	byID := make(map[string]CloudflareReconcileFinding, len(findings))
+	for _, finding := range findings {
+		byID[finding.ID] = finding
+	}
+	selected := make([]CloudflareReconcileFinding, 0, len(ids))
+	for _, id := range ids {
+		finding, ok := byID[id]
+		if !ok {
+			return nil, errs.New(errs.CodeCloudflareReconcileInvalid, fmt.Sprintf("finding %s is no longer reported", id))
+		}
+		if !finding.Cleanable {
+			return nil, errs.New(errs.CodeCloudflareReconcileInvalid, fmt.Sprintf("finding %s cannot be cleaned up: %s", id, finding.SkipReason))
+		}
+		selected = append(selected, finding)
+	}
+	return selected, nil
+}
+
+func listReconcileIngress(ctx context.Context, cfg config.Config, client *cloudflare.Client, warnings map[string]struct{}) ([]ProjectArchivePlanIngress, bool) {
+	result := make([]ProjectArchivePlanIngress, 0)
+	complete := true
+
+	localRules, err := cloudflare.ListLocalIngressRules(cfg.CloudflaredConfig)
+	switch {
+	case errors.Is(err, cloudflare.ErrMissingConfigPath):
+	case err != nil:
+		addArchiveWarning(warnings, fmt.Sprintf("failed to inspect local ingress rules: %v", err))
+		complete = false
+	default:
+		for _, rule := range localRules {
+			result = append(result, reconcileIngressRule(rule, "local"))
+		}
+	}
+
+	remoteRules, err := client.ListIngressRules(ctx)
+	switch {
+	case errors.Is(err, cloudflare.ErrTunnelNotRemote):
+	case err != nil:
+		addArchiveWarning(warnings, fmt.Sprintf("failed to inspect remote ingress rules: %v", err))
+		complete = false
+	default:
+		for _, rule := range remoteRules {
+			result = append(result, reconcileIngressRule(rule, "remote"))
+		}
+	}
+	return result, complete
+}
+
+func reconcileIngressRule(rule cloudflare.IngressRule, source string) ProjectArchivePlanIngress {
+	return ProjectArchivePlanIngress{
+		Hostname: strings.ToLower(strings.TrimSpace(rule.Hostname)),
+		Path:     strings.TrimSpace(rule.Path),
+		Service:  strings.TrimSpace(rule.Service),
+		Source:   source,
+	}
+}
+
+func (s *CloudflareReconcileService) listReconcileZones(ctx context.Context, cfg config.Config, client *cloudflare.Client, warnings map[string]struct{}) []cloudflareReconcileZoneRecords {
+	zones, err := configuredCloudflareZones(ctx, cfg, client)
+	if err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("failed to list Cloudflare zones: %v", err))
+		return nil
+	}
+	result := make([]cloudflareReconcileZoneRecords, 0, len(zones))
+	for _, zone := range zones {
+		records, err := client.ListZoneDNSRecords(ctx, zone.ID)
+		if err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("failed to list DNS records in %s: %v", zone.Name, err))
+			continue
+		}
+		result = append(result, cloudflareReconcileZoneRecords{zone: zone, records: records})
+	}
+	return result
+}
+
+// appendReconcileZones adds the zones not scanned yet.
+func appendReconcileZones(zones, more []cloudflareReconcileZoneRecords) []cloudflareReconcileZoneRecords {
+	for _, candidate := range more {
+		seen := false
+		for _, zone := range zones {
+			if zone.zone.ID == candidate.zone.ID {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			zones = append(zones, candidate)
+		}
+	}
+	return zones
+}
+
+// configuredCloudflareZones returns the zones projects can be published in:
+// every zone of the account when one is set, otherwise CLOUDFLARE_ZONE_ID.
+func configuredCloudflareZones(ctx context.Context, cfg config.Config, client *cloudflare.Client) ([]cloudflare.ZoneInfo, error) {
+	accountID := strings.TrimSpace(cfg.CloudflareAccountID)
+	if accountID != "" {
+		zones, err := client.ListZones(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]cloudflare.ZoneInfo, 0, len(zones))
+		for _, zone := range zones {
+			if zone.Account.ID != "" && !strings.EqualFold(zone.Account.ID, accountID) {
+				continue
+			}
+			result = append(result, zone)
+		}
+		return result, nil
+	}
+	zoneID := strings.TrimSpace(cfg.CloudflareZoneID)
+	if zoneID == "" {
+		return nil, cloudflare.ErrMissingZoneID
+	}
+	zone, err := client.Zone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	return []cloudflare.ZoneInfo{zone}, nil
+}
+
+// knownHostnames collects the hostnames of live projects and service
+// exposures, plus the hostnames archived projects left behind. An error is
+// returned only when the project list itself is unreadable.
+func (s *CloudflareReconcileService) knownHostnames(ctx context.Context, cfg config.Config, warnings map[string]struct{}) (map[string]struct{}, map[string]string, bool, error) {
+	if s.projects == nil || s.archive == nil {
+		return nil, nil, false, fmt.Errorf("project store unavailable")
+	}
+	projects, err := s.projects.List(ctx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	baseDomain := normalizeDomain(cfg.Domain)
+	known := make(map[string]struct{})
+	archived := make(map[string]string)
+	complete := true
+
+	for _, project := range projects {
+		name := strings.ToLower(strings.TrimSpace(project.Name))
+		if name == "" {
+			continue
+		}
+		projectWarnings := make(map[string]struct{})
+		hostnames := s.archive.discoverHostnames(ctx, name, baseDomain, projectWarnings)
+		if len(projectWarnings) > 0 {
+			complete = false
+			for warning := range projectWarnings {
+				addArchiveWarning(warnings, fmt.Sprintf("%s: %s", name, warning))
+			}
+		}
+		isArchived := strings.EqualFold(strings.TrimSpace(project.Status), "archived")
+		for _, hostname := range hostnames {
+			if isArchived {
+				archived[hostname] = name
+				continue
+			}
+			known[hostname] = struct{}{}
+		}
+	}
+
+	if s.jobs == nil {
+		addArchiveWarning(warnings, "job service unavailable while collecting service exposures")
+		complete = false
+	} else if jobs, err := s.jobs.List(ctx); err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("failed to inspect service exposure jobs: %v", err))
+		complete = false
+	} else {
+		for _, job := range jobs {
+			if job.Type != JobTypeForwardLocal && job.Type != JobTypeQuickService {
+				continue
+			}
+			addHostnamesFromJobInput(known, job.Input, baseDomain)
+		}
+	}
+
+	for hostname := range known {
+		delete(archived, hostname)
+	}
+	return known, archived, complete, nil
+}
+
+// reconcileCloudflare compares the collected state and returns findings sorted
+// by hostname. Each tunnel is matched against its own CNAME target, so a
+// hostname served by one tunnel is not reported against another.
+func reconcileCloudflare(state cloudflareReconcileState) []CloudflareReconcileFinding {
+	findings := make([]CloudflareReconcileFinding, 0)
+
+	recordsByName := make(map[string][]cloudflareReconcileRecord)
+	for _, zone := range state.zones {
+		for _, record := range zone.records {
+			name := strings.ToLower(strings.TrimSpace(record.Name))
+			recordsByName[name] = append(recordsByName[name], cloudflareReconcileRecord{zone: zone.zone, record: record})
+		}
+	}
+
+	for _, tunnel := range state.tunnels {
+		findings = append(findings, reconcileCloudflareTunnel(state, tunnel, recordsByName)...)
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Hostname == findings[j].Hostname {
+			if findings[i].Kind == findings[j].Kind {
+				return findings[i].ID < findings[j].ID
+			}
+			return findings[i].Kind < findings[j].Kind
+		}
+		return findings[i].Hostname < findings[j].Hostname
+	})
+	return findings
+}
+
+func reconcileCloudflareTunnel(
+	state cloudflareReconcileState,
+	tunnel cloudflareReconcileTunnel,
+	recordsByName map[string][]cloudflareReconcileRecord,
+) []CloudflareReconcileFinding {
+	findings := make([]CloudflareReconcileFinding, 0)
+
+	ingressHosts := make(map[string]struct{})
+	for _, rule := range tunnel.ingress {
+		if rule.Hostname != "" {
+			ingressHosts[rule.Hostname] = struct{}{}
+		}
+	}
+
+	if tunnel.target != "" {
+		for _, zone := range state.zones {
+			for _, record := range zone.records {
+				if !isTunnelCNAME(record, tunnel.target) {
+					continue
+				}
+				name := strings.ToLower(strings.TrimSpace(record.Name))
+				if ingressCoversHostname(ingressHosts, name) {
+					continue
+				}
+				finding := CloudflareReconcileFinding{
+					ID:         fmt.Sprintf("dns:%s:%s", zone.zone.ID, record.ID),
+					Kind:       CloudflareFindingDNSWithoutIngress,
+					Hostname:   name,
+					ZoneID:     zone.zone.ID,
+					RecordID:   record.ID,
+					RecordType: "CNAME",
+					Content:    strings.TrimSpace(record.Content),
+					Tunnel:     tunnel.name,
+					Project:    state.archived[name],
+					Detail:     "CNAME points at the tunnel but no ingress rule serves it",
+					Cleanable:  tunnel.ingressComplete,
+				}
+				if _, live := state.known[name]; live {
+					finding.Cleanable = false
+					finding.SkipReason = "hostname belongs to a live project; redeploy it to restore the ingress rule"
+				} else if !tunnel.ingressComplete {
+					finding.Cleanable = false
+					finding.SkipReason = "ingress rules could not be read in full"
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	idPrefix := ""
+	if tunnel.name != "" {
+		idPrefix = tunnel.name + "/"
+	}
+	checkedDNS := make(map[string]struct{})
+	for _, rule := range tunnel.ingress {
+		if rule.Hostname == "" {
+			continue
+		}
+		if _, ok := state.known[rule.Hostname]; !ok {
+			finding := CloudflareReconcileFinding{
+				ID:        fmt.Sprintf("ingress:%s%s:%s|%s|%s", idPrefix, rule.Source, rule.Hostname, rule.Path, rule.Service),
+				Kind:      CloudflareFindingIngressWithoutProject,
+				Hostname:  rule.Hostname,
+				Path:      rule.Path,
+				Service:   rule.Service,
+				Source:    rule.Source,
+				Tunnel:    tunnel.name,
+				Project:   state.archived[rule.Hostname],
+				Detail:    "no live project or service exposure uses this hostname",
+				Cleanable: true,
+			}
+			if finding.Project != "" {
+				finding.Detail = fmt.Sprintf("hostname belonged to archived project %s", finding.Project)
+			}
+			switch {
+			case strings.HasPrefix(rule.Hostname, "*."):
+				finding.Cleanable = false
+				finding.SkipReason = "wildcard rules are never removed automatically"
+			case !state.ownershipComplete:
+				finding.Cleanable = false
+				finding.SkipReason = "project hostnames could not be read in full"
+			}
+			findings = append(findings, finding)
+		}
+
+		if _, ok := checkedDNS[rule.Hostname]; ok || tunnel.target == "" || strings.HasPrefix(rule.Hostname, "*.") {
+			continue
+		}
+		checkedDNS[rule.Hostname] = struct{}{}
+		zone, ok := reconcileZoneForHostname(state.zones, rule.Hostname)
+		if !ok {
+			continue
+		}
+		records := recordsByName[rule.Hostname]
+		pointsAtTunnel := false
+		for _, candidate := range records {
+			if isTunnelCNAME(candidate.record, tunnel.target) {
+				pointsAtTunnel = true
+				break
+			}
+		}
+		if pointsAtTunnel {
+			continue
+		}
+		finding := CloudflareReconcileFinding{
+			ID:         fmt.Sprintf("elsewhere:%s%s", idPrefix, rule.Hostname),
+			Kind:       CloudflareFindingDNSPointsElsewhere,
+			Hostname:   rule.Hostname,
+			ZoneID:     zone.ID,
+			Source:     rule.Source,
+			Tunnel:     tunnel.name,
+			Detail:     fmt.Sprintf("no DNS record in %s", zone.Name),
+			SkipReason: "DNS that does not point at the tunnel is never changed automatically",
+		}
+		if len(records) > 0 {
+			finding.RecordID = records[0].record.ID
+			finding.RecordType = strings.ToUpper(strings.TrimSpace(records[0].record.Type))
+			finding.Content = strings.TrimSpace(records[0].record.Content)
+			finding.Detail = fmt.Sprintf("%s record points to %s instead of the tunnel", finding.RecordType, finding.Content)
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+type cloudflareReconcileRecord struct {
+	zone   cloudflare.ZoneInfo
+	record cloudflare.DNSRecord
+}
+
+func isTunnelCNAME(record cloudflare.DNSRecord, tunnelTarget string) bool {
+	return strings.EqualFold(strings.TrimSpace(record.Type), "CNAME") &&
+		strings.EqualFold(strings.TrimSpace(record.Content), tunnelTarget)
+}
+
+// ingressCoversHostname reports whether an ingress rule, including a
+// single-label wildcard, matches hostname.
+func ingressCoversHostname(ingressHosts map[string]struct{}, hostname string) bool {
+	if _, ok := ingressHosts[hostname]; ok {
+		return true
+	}
+	if _, parent, ok := strings.Cut(hostname, "."); ok {
+		if _, ok := ingressHosts["*."+parent]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileZoneForHostname returns the most specific scanned zone containing
+// hostname.
+func reconcileZoneForHostname(zones []cloudflareReconcileZoneRecords, hostname string) (cloudflare.ZoneInfo, bool) {
+	var best cloudflare.ZoneInfo
+	found := false
+	for _, zone := range zones {
+		name := normalizeDomain(zone.zone.Name)
+		if name == "" || (hostname != name && !strings.HasSuffix(hostname, "."+name)) {
+			continue
+		}
+		if !found || len(name) > len(best.Name) {
+			best = zone.zone
+			found = true
+		}
+	}
+	return best, found
+}
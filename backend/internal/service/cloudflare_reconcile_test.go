@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+)
+
+const reconcileTestTunnel = "tunnel-1.cfargotunnel.com"
+
+func reconcileTestState() cloudflareReconcileState {
+	return cloudflareReconcileState{
+		tunnels: []cloudflareReconcileTunnel{{
+			target: reconcileTestTunnel,
+			ingress: []ProjectArchivePlanIngress{
+				{Hostname: "app.example.com", Service: "http://localhost:8080", Source: "remote"},
+				{Hostname: "moved.example.com", Service: "http://localhost:8081", Source: "remote"},
+				{Hostname: "gone.example.com", Service: "http://localhost:8082", Source: "local"},
+				{Hostname: "*.wiki.example.com", Service: "http://localhost:8083", Source: "remote"},
+				{Hostname: "app.other.org", Service: "http://localhost:8084", Source: "remote"},
+			},
+			ingressComplete: true,
+		}},
+		zones: []cloudflareReconcileZoneRecords{{
+			zone: cloudflare.ZoneInfo{ID: "zone-1", Name: "example.com"},
+			records: []cloudflare.DNSRecord{
+				{ID: "rec-app", Type: "CNAME", Name: "app.example.com", Content: reconcileTestTunnel},
+				{ID: "rec-stale", Type: "CNAME", Name: "stale.example.com", Content: reconcileTestTunnel},
+				{ID: "rec-old", Type: "CNAME", Name: "old.example.com", Content: reconcileTestTunnel},
+				{ID: "rec-wild", Type: "CNAME", Name: "team.wiki.example.com", Content: reconcileTestTunnel},
+				{ID: "rec-moved", Type: "A", Name: "moved.example.com", Content: "192.0.2.10"},
+				{ID: "rec-www", Type: "CNAME", Name: "www.example.com", Content: "example.com"},
+			},
+		}},
+		known: map[string]struct{}{
+			"app.example.com":   {},
+			"moved.example.com": {},
+			"app.other.org":     {},
+		},
+		archived:          map[string]string{"old.example.com": "legacy", "gone.example.com": "legacy"},
+		ownershipComplete: true,
+	}
+}
+
+func findReconcileFinding(t *testing.T, findings []CloudflareReconcileFinding, kind, hostname string) CloudflareReconcileFinding {
+	t.Helper()
+	for _, finding := range findings {
+		if finding.Kind == kind && finding.Hostname == hostname {
+			return finding
+		}
+	}
+	t.Fatalf("expected %s finding for %s in %+v", kind, hostname, findings)
+	return CloudflareReconcileFinding{}
+}
+
+func TestReconcileCloudflareFindsOrphans(t *testing.T) {
+	t.Parallel()
+
+	findings := reconcileCloudflare(reconcileTestState())
+	require.Len(t, findings, 6)
+
+	stale := findReconcileFinding(t, findings, CloudflareFindingDNSWithoutIngress, "stale.example.com")
+	require.Equal(t, "dns:zone-1:rec-stale", stale.ID)
+	require.True(t, stale.Cleanable)
+
+	old := findReconcileFinding(t, findings, CloudflareFindingDNSWithoutIngress, "old.example.com")
+	require.Equal(t, "legacy", old.Project)
+	require.True(t, old.Cleanable)
+
+	gone := findReconcileFinding(t, findings, CloudflareFindingIngressWithoutProject, "gone.example.com")
+	require.Equal(t, "ingress:local:gone.example.com||http://localhost:8082", gone.ID)
+	require.Equal(t, "hostname belonged to archived project legacy", gone.Detail)
+	require.True(t, gone.Cleanable)
+
+	wildcard := findReconcileFinding(t, findings, CloudflareFindingIngressWithoutProject, "*.wiki.example.com")
+	require.False(t, wildcard.Cleanable)
+
+	moved := findReconcileFinding(t, findings, CloudflareFindingDNSPointsElsewhere, "moved.example.com")
+	require.Equal(t, "A record points to 192.0.2.10 instead of the tunnel", moved.Detail)
+	require.False(t, moved.Cleanable)
+
+	missing := findReconcileFinding(t, findings, CloudflareFindingDNSPointsElsewhere, "gone.example.com")
+	require.Equal(t, "no DNS record in example.com", missing.Detail)
+
+	for _, finding := range findings {
+		require.NotEqual(t, "app.example.com", finding.Hostname)
+		require.NotEqual(t, "team.wiki.example.com", finding.Hostname, "wildcard ingress serves this CNAME")
+		require.NotEqual(t, "app.other.org", finding.Hostname, "hostnames outside scanned zones are not DNS-checked")
+	}
+}
+
+func TestReconcileCloudflareGuardsPartialViews(t *testing.T) {
+	t.Parallel()
+
+	state := reconcileTestState()
+	state.tunnels[0].ingressComplete = false
+	state.ownershipComplete = false
+	state.known["stale.example.com"] = struct{}{}
+	state.tunnels[0].ingress = append(state.tunnels[0].ingress, ProjectArchivePlanIngress{Hostname: "stray.example.com", Service: "http://localhost:9000", Source: "remote"})
+
+	findings := reconcileCloudflare(state)
+	stale := findReconcileFinding(t, findings, CloudflareFindingDNSWithoutIngress, "stale.example.com")
+	require.False(t, stale.Cleanable)
+	require.Contains(t, stale.SkipReason, "live project")
+
+	old := findReconcileFinding(t, findings, CloudflareFindingDNSWithoutIngress, "old.example.com")
+	require.False(t, old.Cleanable)
+	require.Equal(t, "ingress rules could not be read in full", old.SkipReason)
+
+	stray := findReconcileFinding(t, findings, CloudflareFindingIngressWithoutProject, "stray.example.com")
+	require.False(t, stray.Cleanable)
+	require.Equal(t, "project hostnames could not be read in full", stray.SkipReason)
+
+	_, err := selectReconcileFindings(findings, []string{stray.ID})
+	appErr, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeCloudflareReconcileInvalid, appErr.Code)
+
+	_, err = selectReconcileFindings(findings, []string{"dns:zone-1:missing"})
+	require.ErrorContains(t, err, "no longer reported")
+}
+
+func TestReconcileCloudflareComparesEachTunnel(t *testing.T) {
+	t.Parallel()
+
+	const edgeTunnel = "tunnel-2.cfargotunnel.com"
+	state := reconcileTestState()
+	state.tunnels = append(state.tunnels, cloudflareReconcileTunnel{
+		name:   "edge",
+		target: edgeTunnel,
+		ingress: []ProjectArchivePlanIngress{
+			{Hostname: "api.example.com", Service: "http://localhost:9080", Source: "remote"},
+			{Hostname: "retired.example.com", Service: "http://localhost:9081", Source: "local"},
+		},
+		ingressComplete: true,
+	})
+	state.zones[0].records = append(state.zones[0].records,
+		cloudflare.DNSRecord{ID: "rec-api", Type: "CNAME", Name: "api.example.com", Content: edgeTunnel},
+		cloudflare.DNSRecord{ID: "rec-retired", Type: "CNAME", Name: "retired.example.com", Content: edgeTunnel},
+		cloudflare.DNSRecord{ID: "rec-lost", Type: "CNAME", Name: "lost.example.com", Content: edgeTunnel},
+	)
+	state.known["api.example.com"] = struct{}{}
+
+	findings := reconcileCloudflare(state)
+	for _, finding := range findings {
+		require.NotEqual(t, "api.example.com", finding.Hostname, "hostname served by the edge tunnel was reported: %+v", finding)
+	}
+
+	lost := findReconcileFinding(t, findings, CloudflareFindingDNSWithoutIngress, "lost.example.com")
+	require.Equal(t, "edge", lost.Tunnel)
+	require.True(t, lost.Cleanable)
+
+	retired := findReconcileFinding(t, findings, CloudflareFindingIngressWithoutProject, "retired.example.com")
+	require.Equal(t, "edge", retired.Tunnel)
+	require.Equal(t, "ingress:edge/local:retired.example.com||http://localhost:9081", retired.ID)
+	require.True(t, retired.Cleanable)
+
+	gone := findReconcileFinding(t, findings, CloudflareFindingIngressWithoutProject, "gone.example.com")
+	require.Empty(t, gone.Tunnel)
+
+	report := CloudflareReconcileReport{
+		TunnelTarget: reconcileTestTunnel,
+		Tunnels:      []CloudflareReconcileTunnel{{Name: DefaultTunnelName, Target: reconcileTestTunnel}, {Name: "edge", Target: edgeTunnel}},
+	}
+	require.Equal(t, reconcileTestTunnel, reconcileTunnelTarget(report, ""))
+	require.Equal(t, edgeTunnel, reconcileTunnelTarget(report, "edge"))
+}
+
+type stubReconcileCleanupClient struct {
+	deleted      []string
+	removedRules []cloudflare.IngressRule
+}
+
+func (s *stubReconcileCleanupClient) DeleteTunnelCNAMERecord(_ context.Context, zoneID, recordID, hostname, expectedTarget string) (cloudflare.DNSDeleteResult, error) {
+	if expectedTarget != reconcileTestTunnel {
+		return cloudflare.DNSDeleteResult{SkipReason: "expected tunnel target is unavailable"}, nil
+	}
+	s.deleted = append(s.deleted, zoneID+"/"+recordID+"/"+hostname)
+	return cloudflare.DNSDeleteResult{Deleted: true}, nil
+}
+
+func (s *stubReconcileCleanupClient) RemoveIngressRules(_ context.Context, targets []cloudflare.IngressRule) ([]cloudflare.IngressRule, error) {
+	s.removedRules = append(s.removedRules, targets...)
+	return targets, nil
+}
+
+func TestApplyReconcileCleanupOnlyRemovesFindingsStillOrphaned(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte(strings.TrimSpace(`
+ingress:
+  - hostname: gone.example.com
+    service: http://localhost:8082
+  - hostname: app.example.com
+    service: http://localhost:8080
+  - service: http_status:404
+`)+"\n"), 0o644))
+
+	queued := reconcileCleanupTestFindings(t)
+	state := reconcileTestState()
+	// stale.example.com was redeployed after the cleanup was queued.
+	state.known["stale.example.com"] = struct{}{}
+	state.tunnels[0].ingress = append(state.tunnels[0].ingress, ProjectArchivePlanIngress{Hostname: "stale.example.com", Service: "http://localhost:8090", Source: "remote"})
+	fresh := CloudflareReconcileReport{TunnelTarget: reconcileTestTunnel, Findings: reconcileCloudflare(state)}
+
+	client := &stubReconcileCleanupClient{}
+	logger := &archiveTestLogger{}
+	summary := applyReconcileCleanup(context.Background(), logger, client, configPath, "", queued, fresh)
+
+	require.Equal(t, reconcileCleanupSummary{Requested: 4, RemovedDNS: 1, RemovedLocal: 1, Skipped: 2}, summary)
+	require.Equal(t, []string{"zone-1/rec-old/old.example.com"}, client.deleted)
+	require.Empty(t, client.removedRules)
+	requireArchiveLogContains(t, logger.lines, "skip dns:zone-1:rec-stale: no longer orphaned")
+	requireArchiveLogContains(t, logger.lines, "skip elsewhere:moved.example.com: DNS that does not point at the tunnel is never changed automatically")
+
+	rules, err := cloudflare.ListLocalIngressRules(configPath)
+	require.NoError(t, err)
+	require.Equal(t, []cloudflare.IngressRule{{Hostname: "app.example.com", Service: "http://localhost:8080"}}, rules)
+}
+
+func reconcileCleanupTestFindings(t *testing.T) []CloudflareReconcileFinding {
+	t.Helper()
+	findings := reconcileCloudflare(reconcileTestState())
+	return []CloudflareReconcileFinding{
+		findReconcileFinding(t, findings, CloudflareFindingDNSWithoutIngress, "stale.example.com"),
+		findReconcileFinding(t, findings, CloudflareFindingDNSWithoutIngress, "old.example.com"),
+		findReconcileFinding(t, findings, CloudflareFindingIngressWithoutProject, "gone.example.com"),
+		findReconcileFinding(t, findings, CloudflareFindingDNSPointsElsewhere, "moved.example.com"),
+	}
+}
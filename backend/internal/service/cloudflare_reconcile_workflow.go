@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+)
+
+type reconcileCleanupClient interface {
+	DeleteTunnelCNAMERecord(ctx context.Context, zoneID, recordID, hostname, expectedTarget string) (cloudflare.DNSDeleteResult, error)
+	RemoveIngressRules(ctx context.Context, targets []cloudflare.IngressRule) ([]cloudflare.IngressRule, error)
+}
+
+type reconcileCleanupSummary struct {
+	Requested     int
+	RemovedDNS    int
+	RemovedRemote int
+	RemovedLocal  int
+	Skipped       int
+	Failed        int
+}
+
+func (s *reconcileCleanupSummary) add(other reconcileCleanupSummary) {
+	s.Requested += other.Requested
+	s.RemovedDNS += other.RemovedDNS
+	s.RemovedRemote += other.RemovedRemote
+	s.RemovedLocal += other.RemovedLocal
+	s.Skipped += other.Skipped
+	s.Failed += other.Failed
+}
+
+func (w *ProjectWorkflows) SetCloudflareReconcile(reconcile *CloudflareReconcileService) {
+	w.reconcile = reconcile
+}
+
+func (w *ProjectWorkflows) handleCloudflareReconcileCleanup(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req CloudflareReconcileCleanupJobRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse reconcile cleanup request: %w", err)
+	}
+	if w.reconcile == nil {
+		return fmt.Errorf("reconcile service unavailable")
+	}
+	runtimeCfg, err := w.resolveArchiveRuntimeConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("resolve runtime config: %w", err)
+	}
+
+	logger.Logf("re-checking %d finding(s) before cleanup", len(req.Findings))
+	fresh, err := w.reconcile.Report(ctx)
+	if err != nil {
+		return fmt.Errorf("refresh reconcile report: %w", err)
+	}
+	for _, warning := range fresh.Warnings {
+		logger.Logf("warning: %s", warning)
+	}
+
+	// Findings are cleaned through the tunnel that reported them, so each
+	// group uses that tunnel's credentials, config file, and CNAME target.
+	byTunnel := make(map[string][]CloudflareReconcileFinding)
+	order := make([]string, 0)
+	for _, finding := range req.Findings {
+		if _, ok := byTunnel[finding.Tunnel]; !ok {
+			order = append(order, finding.Tunnel)
+		}
+		byTunnel[finding.Tunnel] = append(byTunnel[finding.Tunnel], finding)
+	}
+	summary := reconcileCleanupSummary{}
+	restartFailed := false
+	for _, name := range order {
+		tunnelCfg, err := w.tunnels.Config(ctx, runtimeCfg, name)
+		if err != nil {
+			logger.Logf("skip %d finding(s) on tunnel %s: %v", len(byTunnel[name]), name, err)
+			summary.Requested += len(byTunnel[name])
+			summary.Failed += len(byTunnel[name])
+			continue
+		}
+		result := applyReconcileCleanup(ctx, logger, cloudflare.NewClient(tunnelCfg), tunnelCfg.CloudflaredConfig, name, byTunnel[name], fresh)
+		summary.add(result)
+		if result.RemovedLocal > 0 {
+			if err := w.restartTunnelForArchive(ctx, logger, fmt.Sprintf("job-%d", job.ID), tunnelCfg); err != nil {
+				logger.Logf("cloudflared restart after ingress cleanup failed: %v", err)
+				restartFailed = true
+			}
+		}
+	}
+	logger.Logf(
+		"reconcile cleanup: requested=%d removed_dns=%d removed_remote=%d removed_local=%d skipped=%d failed=%d tunnel_restart_failed=%t",
+		summary.Requested,
+		summary.RemovedDNS,
+		summary.RemovedRemote,
+		summary.RemovedLocal,
+		summary.Skipped,
+		summary.Failed,
+		restartFailed,
+	)
+	if summary.Failed > 0 || restartFailed {
+		return fmt.Errorf("reconcile cleanup finished with failures")
+	}
+	return nil
+}
+
+// applyReconcileCleanup removes each requested finding of tunnel that fresh
+// still reports as cleanable. Anything that changed since the report was
+// queued is skipped, and DNS records are re-checked against the tunnel target
+// again at delete time.
+func applyReconcileCleanup(
+	ctx context.Context,
+	logger jobs.Logger,
+	client reconcileCleanupClient,
+	configPath string,
+	tunnel string,
+	requested []CloudflareReconcileFinding,
+	fresh CloudflareReconcileReport,
+) reconcileCleanupSummary {
+	current := make(map[string]CloudflareReconcileFinding, len(fresh.Findings))
+	for _, finding := range fresh.Findings {
+		current[finding.ID] = finding
+	}
+
+	target := reconcileTunnelTarget(fresh, tunnel)
+	summary := reconcileCleanupSummary{Requested: len(requested)}
+	remote := make([]cloudflare.IngressRule, 0)
+	local := make([]cloudflare.IngressRule, 0)
+	for _, finding := range requested {
+		still, ok := current[finding.ID]
+		if !ok {
+			logger.Logf("skip %s: no longer orphaned", finding.ID)
+			summary.Skipped++
+			continue
+		}
+		if still.Tunnel != tunnel {
+			logger.Logf("skip %s: now reported on another tunnel", finding.ID)
+			summary.Skipped++
+			continue
+		}
+		if !still.Cleanable {
+			logger.Logf("skip %s: %s", finding.ID, still.SkipReason)
+			summary.Skipped++
+			continue
+		}
+		switch still.Kind {
+		case CloudflareFindingDNSWithoutIngress:
+			result, err := client.DeleteTunnelCNAMERecord(ctx, still.ZoneID, still.RecordID, still.Hostname, target)
+			switch {
+			case err != nil:
+				logger.Logf("delete DNS record %s for %s failed: %v", still.RecordID, still.Hostname, err)
+				summary.Failed++
+			case !result.Deleted:
+				logger.Logf("skip DNS record %s for %s because %s", still.RecordID, still.Hostname, result.SkipReason)
+				summary.Skipped++
+			default:
+				logger.Logf("deleted DNS record %s for %s", still.RecordID, still.Hostname)
+				summary.RemovedDNS++
+			}
+		case CloudflareFindingIngressWithoutProject:
+			rule := cloudflare.IngressRule{Hostname: still.Hostname, Path: still.Path, Service: still.Service}
+			if still.Source == "local" {
+				local = append(local, rule)
+			} else {
+				remote = append(remote, rule)
+			}
+		default:
+			logger.Logf("skip %s: %s findings are report-only", finding.ID, still.Kind)
+			summary.Skipped++
+		}
+	}
+
+	if len(remote) > 0 {
+		removed, err := client.RemoveIngressRules(ctx, remote)
+		if err != nil {
+			logger.Logf("remove remote ingress rules failed: %v", err)
+			summary.Failed += len(remote)
+		} else {
+			logger.Logf("removed %d remote ingress rules", len(removed))
+			summary.RemovedRemote = len(removed)
+			summary.Skipped += len(remote) - len(removed)
+		}
+	}
+	if len(local) > 0 {
+		removed, err := cloudflare.RemoveLocalIngressRules(configPath, local)
+		if err != nil {
+			logger.Logf("remove local ingress rules failed: %v", err)
+			summary.Failed += len(local)
+		} else {
+			logger.Logf("removed %d local ingress rules", len(removed))
+			summary.RemovedLocal = len(removed)
+			summary.Skipped += len(local) - len(removed)
+		}
+	}
+	return summary
+}
+
+// reconcileTunnelTarget returns the CNAME target fresh recorded for tunnel.
+func reconcileTunnelTarget(fresh CloudflareReconcileReport, tunnel string) string {
+	name := normalizeTunnelName(tunnel)
+	if name == "" {
+		return fresh.TunnelTarget
+	}
+	for _, candidate := range fresh.Tunnels {
+		if candidate.Name == name {
+			return candidate.Target
+		}
+	}
+	return ""
+}
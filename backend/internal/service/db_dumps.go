@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/jobs"
+)
+
+// ProjectDatabase is a compose service whose image is a database engine the
+// bridge can dump. Container is empty when the service is not running.
+type ProjectDatabase struct {
+	Service   string `json:"service"`
+	Engine    string `json:"engine"`
+	Image     string `json:"image"`
+	Container string `json:"container,omitempty"`
+}
+
+// ProjectDatabaseTarget is a database service picked when the dump was queued.
+type ProjectDatabaseTarget struct {
+	Service string `json:"service"`
+	Engine  string `json:"engine"`
+}
+
+// ProjectDatabaseDump is one set of logical dumps, read from the manifest the
+// bridge worker writes next to the dump files.
+type ProjectDatabaseDump struct {
+	Project   string                   `json:"project"`
+	DumpID    string                   `json:"dumpId"`
+	CreatedAt time.Time                `json:"createdAt"`
+	SizeBytes int64                    `json:"sizeBytes"`
+	Dumps     []contract.DBDumpArchive `json:"dumps"`
+}
+
+type ProjectDatabaseDumpRequest struct {
+	Project   string                  `json:"project"`
+	Databases []ProjectDatabaseTarget `json:"databases"`
+}
+
+// ProjectDatabaseRestoreRequest restores a dump of Project into
+// TargetProject, which is Project itself or a clone with the same services.
+type ProjectDatabaseRestoreRequest struct {
+	Project       string   `json:"project"`
+	DumpID        string   `json:"dumpId"`
+	TargetProject string   `json:"targetProject"`
+	Services      []string `json:"services,omitempty"`
+}
+
+// DetectProjectDatabases lists the snapshot services that run a Postgres or
+// MySQL-compatible image.
+func DetectProjectDatabases(snapshot WorkbenchStackSnapshot) []ProjectDatabase {
+	databases := make([]ProjectDatabase, 0)
+	for _, svc := range snapshot.Services {
+		engine := databaseEngineForImage(svc.Image)
+		if engine == "" {
+			continue
+		}
+		databases = append(databases, ProjectDatabase{
+			Service: strings.TrimSpace(svc.ServiceName),
+			Engine:  engine,
+			Image:   strings.TrimSpace(svc.Image),
+		})
+	}
+	sort.SliceStable(databases, func(i, j int) bool {
+		return databases[i].Service < databases[j].Service
+	})
+	return databases
+}
+
+// databaseEngineForImage matches the repository name of an image reference,
+// ignoring registry, namespace, tag, and digest.
+func databaseEngineForImage(image string) string {
+	image = strings.ToLower(strings.TrimSpace(image))
+	if image == "" || strings.Contains(image, "${") {
+		return ""
+	}
+	if index := strings.Index(image, "@"); index >= 0 {
+		image = image[:index]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if index := strings.Index(name, ":"); index >= 0 {
+		name = name[:index]
+	}
+	switch {
+	case name == "postgres" || name == "postgis" || name == "pgvector" || strings.HasPrefix(name, "timescaledb"):
+		return contract.DBEnginePostgres
+	case name == "mysql" || name == "mariadb" || name == "percona-server" || name == "percona":
+		return contract.DBEngineMySQL
+	default:
+		return ""
+	}
+}
+
+// ProjectDatabases detects the snapshot's database services and attaches the
+// running container of each.
+func (s *HostService) ProjectDatabases(ctx context.Context, snapshot WorkbenchStackSnapshot) ([]ProjectDatabase, error) {
+	databases := DetectProjectDatabases(snapshot)
+	if len(databases) == 0 {
+		return databases, nil
+	}
+	containers, err := s.projectServiceContainers(ctx, snapshot.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+	for index := range databases {
+		databases[index].Container = containers[databases[index].Service]
+	}
+	return databases, nil
+}
+
+// projectServiceContainers maps compose service names to the running
+// container of the project.
+func (s *HostService) projectServiceContainers(ctx context.Context, project string) (map[string]string, error) {
+	containers, err := s.ListContainers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	byService := map[string]string{}
+	for _, container := range containers {
+		if !strings.EqualFold(strings.TrimSpace(container.Project), project) || container.Service == "" {
+			continue
+		}
+		if _, exists := byService[container.Service]; !exists {
+			byService[container.Service] = strings.TrimSpace(container.Name)
+		}
+	}
+	return byService, nil
+}
+
+// DumpProjectDatabasesWithLogger runs a logical dump of each database service
+// inside its running container and stores the results under the project's
+// backup directory.
+func (s *HostService) DumpProjectDatabasesWithLogger(
+	ctx context.Context,
+	requestID string,
+	project string,
+	databases []ProjectDatabaseTarget,
+	logger jobs.Logger,
+) (*ProjectDatabaseDump, error) {
+	project = strings.TrimSpace(project)
+	if project == "" || project == "." || project == ".." {
+		return nil, fmt.Errorf("invalid project name")
+	}
+	if s.infraClient == nil {
+		return nil, fmt.Errorf("infra bridge client unavailable")
+	}
+	if len(databases) == 0 {
+		return nil, errs.New(errs.CodeProjectNoDatabases, "no database services to dump")
+	}
+	containers, err := s.projectServiceContainers(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]contract.DBDumpTarget, 0, len(databases))
+	for _, database := range databases {
+		container := containers[database.Service]
+		if container == "" {
+			return nil, errs.New(errs.CodeProjectDBNotRunning, fmt.Sprintf("database service %s of %q is not running", database.Service, project))
+		}
+		targets = append(targets, contract.DBDumpTarget{Service: database.Service, Container: container, Engine: database.Engine})
+	}
+
+	dumpID := time.Now().UTC().Format(volumeBackupIDLayout)
+	hostLogf(logger, "dumping %d database(s) of project %q as %s", len(targets), project, dumpID)
+	waitCtx, cancel := withVolumeBackupWaitTimeout(ctx)
+	defer cancel()
+	result, err := s.infraClient.DockerDBDump(waitCtx, requestID, contract.DockerDBDumpPayload{
+		Project: project,
+		DumpID:  dumpID,
+		Targets: targets,
+	})
+	if err != nil {
+		return nil, bridgeTaskErrorWithCode(errs.CodeProjectDBDumpFailed, "database dump failed", contract.TaskTypeDockerDBDump, project, err)
+	}
+	if err := bridgeResultErrorWithCode(errs.CodeProjectDBDumpFailed, "database dump failed", contract.TaskTypeDockerDBDump, project, result); err != nil {
+		return nil, err
+	}
+	for _, line := range result.LogTail {
+		hostLogf(logger, "%s", line)
+	}
+
+	var payload struct {
+		Manifest contract.DBDumpManifest `json:"manifest"`
+	}
+	raw, err := json.Marshal(result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("encode database dump payload: %w", err)
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("decode database dump payload: %w", err)
+	}
+	dump := newProjectDatabaseDump(payload.Manifest)
+	return &dump, nil
+}
+
+// RestoreProjectDatabaseDumpWithLogger loads a dump of project into the
+// matching running services of targetProject. The stack keeps running; the
+// restore tools replace the dumped objects in place.
+func (s *HostService) RestoreProjectDatabaseDumpWithLogger(
+	ctx context.Context,
+	requestID string,
+	project string,
+	dumpID string,
+	targetProject string,
+	services []string,
+	logger jobs.Logger,
+) error {
+	project = strings.TrimSpace(project)
+	if project == "" || project == "." || project == ".." {
+		return fmt.Errorf("invalid project name")
+	}
+	targetProject = strings.TrimSpace(targetProject)
+	if targetProject == "" {
+		targetProject = project
+	}
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	dump, err := s.GetProjectDatabaseDump(project, dumpID)
+	if err != nil {
+		return err
+	}
+	selected := map[string]struct{}{}
+	for _, service := range services {
+		if trimmed := strings.TrimSpace(service); trimmed != "" {
+			selected[trimmed] = struct{}{}
+		}
+	}
+	containers, err := s.projectServiceContainers(ctx, targetProject)
+	if err != nil {
+		return err
+	}
+	targets := make([]contract.DBDumpTarget, 0, len(dump.Dumps))
+	for _, archive := range dump.Dumps {
+		if _, wanted := selected[archive.Service]; len(selected) > 0 && !wanted {
+			continue
+		}
+		delete(selected, archive.Service)
+		container := containers[archive.Service]
+		if container == "" {
+			return errs.New(errs.CodeProjectDBNotRunning, fmt.Sprintf("database service %s of %q is not running", archive.Service, targetProject))
+		}
+		targets = append(targets, contract.DBDumpTarget{Service: archive.Service, Container: container, Engine: archive.Engine})
+	}
+	if len(selected) > 0 {
+		missing := make([]string, 0, len(selected))
+		for service := range selected {
+			missing = append(missing, service)
+		}
+		sort.Strings(missing)
+		return errs.New(errs.CodeProjectInvalidBody, fmt.Sprintf("dump %s does not contain service(s) %s", dump.DumpID, strings.Join(missing, ", ")))
+	}
+	if len(targets) == 0 {
+		return errs.New(errs.CodeProjectNoDatabases, "dump contains no databases to restore")
+	}
+
+	hostLogf(logger, "restoring dump %s of project %q into %q", dump.DumpID, project, targetProject)
+	waitCtx, cancel := withVolumeBackupWaitTimeout(ctx)
+	defer cancel()
+	result, err := s.infraClient.DockerDBRestore(waitCtx, requestID, contract.DockerDBRestorePayload{
+		Project: project,
+		DumpID:  dump.DumpID,
+		Targets: targets,
+	})
+	if err != nil {
+		return bridgeTaskErrorWithCode(errs.CodeProjectDBDumpFailed, "database restore failed", contract.TaskTypeDockerDBRestore, targetProject, err)
+	}
+	if err := bridgeResultErrorWithCode(errs.CodeProjectDBDumpFailed, "database restore failed", contract.TaskTypeDockerDBRestore, targetProject, result); err != nil {
+		return err
+	}
+	for _, line := range result.LogTail {
+		hostLogf(logger, "%s", line)
+	}
+	return nil
+}
+
+// ListProjectDatabaseDumps reads the project's dump manifests, newest first.
+func (s *HostService) ListProjectDatabaseDumps(project string) ([]ProjectDatabaseDump, error) {
+	projectDir, err := s.volumeBackupProjectDir(project)
+	if err != nil {
+		return nil, err
+	}
+	dumpsDir := filepath.Join(projectDir, contract.DBDumpDirName)
+	entries, err := os.ReadDir(dumpsDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []ProjectDatabaseDump{}, nil
+		}
+		return nil, errs.Wrap(errs.CodeProjectDBDumpFailed, "failed to read dump directory", err)
+	}
+	dumps := make([]ProjectDatabaseDump, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := readDBDumpManifest(filepath.Join(dumpsDir, entry.Name()))
+		if err != nil || manifest.DumpID != entry.Name() {
+			continue
+		}
+		dumps = append(dumps, newProjectDatabaseDump(manifest))
+	}
+	sort.SliceStable(dumps, func(i, j int) bool {
+		return dumps[i].CreatedAt.After(dumps[j].CreatedAt)
+	})
+	return dumps, nil
+}
+
+// GetProjectDatabaseDump returns one dump of the project.
+func (s *HostService) GetProjectDatabaseDump(project, dumpID string) (ProjectDatabaseDump, error) {
+	projectDir, err := s.volumeBackupProjectDir(project)
+	if err != nil {
+		return ProjectDatabaseDump{}, err
+	}
+	dumpsDir := filepath.Join(projectDir, contract.DBDumpDirName)
+	dumpID = strings.TrimSpace(dumpID)
+	dumpDir := filepath.Join(dumpsDir, dumpID)
+	if dumpID == "" || filepath.Base(dumpDir) != dumpID || !isPathWithinBase(dumpsDir, dumpDir) {
+		return ProjectDatabaseDump{}, errs.New(errs.CodeProjectDBDumpNotFound, "database dump not found")
+	}
+	manifest, err := readDBDumpManifest(dumpDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ProjectDatabaseDump{}, errs.New(errs.CodeProjectDBDumpNotFound, "database dump not found")
+		}
+		return ProjectDatabaseDump{}, errs.Wrap(errs.CodeProjectDBDumpFailed, "failed to read dump manifest", err)
+	}
+	return newProjectDatabaseDump(manifest), nil
+}
+
+func readDBDumpManifest(dumpDir string) (contract.DBDumpManifest, error) {
+	raw, err := os.ReadFile(filepath.Join(dumpDir, contract.VolumeBackupManifestName))
+	if err != nil {
+		return contract.DBDumpManifest{}, err
+	}
+	var manifest contract.DBDumpManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return contract.DBDumpManifest{}, fmt.Errorf("decode dump manifest: %w", err)
+	}
+	if manifest.Version != contract.DBDumpManifestVersion {
+		return contract.DBDumpManifest{}, fmt.Errorf("unsupported dump manifest version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+func newProjectDatabaseDump(manifest contract.DBDumpManifest) ProjectDatabaseDump {
+	dump := ProjectDatabaseDump{
+		Project:   manifest.Project,
+		DumpID:    manifest.DumpID,
+		CreatedAt: manifest.CreatedAt,
+		Dumps:     append([]contract.DBDumpArchive{}, manifest.Dumps...),
+	}
+	for _, archive := range manifest.Dumps {
+		dump.SizeBytes += archive.SizeBytes
+	}
+	return dump
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+)
+
+func writeDBDumpManifestFixture(t *testing.T, dir string, manifest contract.DBDumpManifest) {
+	t.Helper()
+	dumpDir := filepath.Join(dir, manifest.Project, contract.DBDumpDirName, manifest.DumpID)
+	require.NoError(t, os.MkdirAll(dumpDir, 0o700))
+	content, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dumpDir, contract.VolumeBackupManifestName), content, 0o600))
+}
+
+func TestDetectProjectDatabasesMatchesEngineImages(t *testing.T) {
+	t.Parallel()
+
+	databases := DetectProjectDatabases(WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Services: []WorkbenchComposeService{
+			{ServiceName: "web", Image: "nginx:1.27"},
+			{ServiceName: "db", Image: "postgres:16-alpine"},
+			{ServiceName: "geo", Image: "ghcr.io/example/postgis:3.4@sha256:abc"},
+			{ServiceName: "legacy", Image: "docker.io/library/mariadb:11"},
+			{ServiceName: "metrics", Image: "timescale/timescaledb-ha:pg16"},
+			{ServiceName: "custom", Image: "${DB_IMAGE}"},
+			{ServiceName: "api", BuildSource: "./api"},
+		},
+	})
+	require.Equal(t, []ProjectDatabase{
+		{Service: "db", Engine: contract.DBEnginePostgres, Image: "postgres:16-alpine"},
+		{Service: "geo", Engine: contract.DBEnginePostgres, Image: "ghcr.io/example/postgis:3.4@sha256:abc"},
+		{Service: "legacy", Engine: contract.DBEngineMySQL, Image: "docker.io/library/mariadb:11"},
+		{Service: "metrics", Engine: contract.DBEnginePostgres, Image: "timescale/timescaledb-ha:pg16"},
+	}, databases)
+}
+
+func TestDatabaseDumpRestoresIntoClonedProjectContainers(t *testing.T) {
+	t.Parallel()
+
+	backupDir := t.TempDir()
+	bridge := &stubHostInfraBridgeClient{
+		listContainersResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"ID":"a1","Image":"postgres:16","Names":"demo-db-1","Status":"Up 2 minutes","Labels":"com.docker.compose.project=demo,com.docker.compose.service=db"}`,
+					`{"ID":"b2","Image":"postgres:16","Names":"demo-copy-db-1","Status":"Up 1 minute","Labels":"com.docker.compose.project=demo-copy,com.docker.compose.service=db"}`,
+				},
+			},
+		},
+		dbDumpResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"manifest": map[string]any{
+					"version": 1,
+					"project": "demo",
+					"dumpId":  "20260102T030405Z",
+					"dumps": []map[string]any{
+						{"service": "db", "engine": "postgres", "container": "demo-db-1", "file": "db.pgdump", "sizeBytes": 64, "sha256": "aa"},
+					},
+				},
+			},
+		},
+		dbRestoreResult: contract.Result{Status: contract.StatusSucceeded},
+	}
+	svc := NewHostService(t.TempDir(), &archiveTestProjectRepo{}, bridge)
+	svc.SetVolumeBackups(backupDir, 5)
+
+	dump, err := svc.DumpProjectDatabasesWithLogger(context.Background(), "job-1", "demo", []ProjectDatabaseTarget{{Service: "db", Engine: contract.DBEnginePostgres}}, &captureHostLogger{})
+	require.NoError(t, err)
+	require.Equal(t, int64(64), dump.SizeBytes)
+	require.Equal(t, []contract.DBDumpTarget{{Service: "db", Container: "demo-db-1", Engine: contract.DBEnginePostgres}}, bridge.dbDumpPayloads[0].Targets)
+
+	_, err = svc.DumpProjectDatabasesWithLogger(context.Background(), "job-2", "demo", []ProjectDatabaseTarget{{Service: "mysql", Engine: contract.DBEngineMySQL}}, &captureHostLogger{})
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectDBNotRunning, typed.Code)
+
+	// The worker writes the manifest; the service reads it back for restores.
+	writeDBDumpManifestFixture(t, backupDir, contract.DBDumpManifest{
+		Version: 1,
+		Project: "demo",
+		DumpID:  "20260102T030405Z",
+		Dumps:   []contract.DBDumpArchive{{Service: "db", Engine: contract.DBEnginePostgres, File: "db.pgdump"}},
+	})
+	err = svc.RestoreProjectDatabaseDumpWithLogger(context.Background(), "job-3", "demo", "20260102T030405Z", "demo-copy", nil, &captureHostLogger{})
+	require.NoError(t, err)
+	require.Equal(t, []contract.DockerDBRestorePayload{{
+		Project: "demo",
+		DumpID:  "20260102T030405Z",
+		Targets: []contract.DBDumpTarget{{Service: "db", Container: "demo-copy-db-1", Engine: contract.DBEnginePostgres}},
+	}}, bridge.dbRestorePayloads)
+
+	err = svc.RestoreProjectDatabaseDumpWithLogger(context.Background(), "job-4", "demo", "20260102T030405Z", "demo", []string{"cache"}, &captureHostLogger{})
+	require.ErrorContains(t, err, "does not contain service(s) cache")
+
+	err = svc.RestoreProjectDatabaseDumpWithLogger(context.Background(), "job-5", "demo", "missing", "demo", nil, &captureHostLogger{})
+	typed, ok = errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectDBDumpNotFound, typed.Code)
+	require.Len(t, bridge.dbRestorePayloads, 1)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+)
+
+// DNSZoneRecords is a configured zone with its records of the types the DNS
+// API manages.
+type DNSZoneRecords struct {
+	ID      string                 `json:"id"`
+	Name    string                 `json:"name"`
+	Records []cloudflare.DNSRecord `json:"records"`
+}
+
+// DNSZoneRecord is a single record and the zone it lives in.
+type DNSZoneRecord struct {
+	ZoneID   string               `json:"zoneId"`
+	ZoneName string               `json:"zoneName"`
+	Record   cloudflare.DNSRecord `json:"record"`
+}
+
+// DNSService manages A, AAAA, CNAME, MX, and TXT records in the configured
+// Cloudflare zones. Every record is listed, but only records carrying the
+// gungnr-managed comment can be changed or deleted.
+type DNSService struct {
+	settings *SettingsService
+}
+
+func NewDNSService(settings *SettingsService) *DNSService {
+	return &DNSService{settings: settings}
+}
+
+// List returns the records of every configured zone, or of zone (a name or
+// ID) when it is set.
+func (s *DNSService) List(ctx context.Context, zone string) ([]DNSZoneRecords, error) {
+	client, zones, err := s.resolveZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(zone) != "" {
+		selected, err := findDNSZone(zones, zone)
+		if err != nil {
+			return nil, err
+		}
+		zones = []cloudflare.ZoneInfo{selected}
+	}
+
+	result := make([]DNSZoneRecords, 0, len(zones))
+	for _, zone := range zones {
+		records, err := client.ListZoneDNSRecords(ctx, zone.ID)
+		if err != nil {
+			return nil, dnsError(err, fmt.Sprintf("failed to list DNS records in %s", zone.Name))
+		}
+		filtered := make([]cloudflare.DNSRecord, 0, len(records))
+		for _, record := range records {
+			if cloudflare.IsManagedDNSType(record.Type) {
+				filtered = append(filtered, record)
+			}
+		}
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if filtered[i].Name == filtered[j].Name {
+				return filtered[i].Type < filtered[j].Type
+			}
+			return filtered[i].Name < filtered[j].Name
+		})
+		result = append(result, DNSZoneRecords{ID: zone.ID, Name: zone.Name, Records: filtered})
+	}
+	return result, nil
+}
+
+// Create adds a managed record. zone may be empty, in which case the zone is
+// taken from the record name.
+func (s *DNSService) Create(ctx context.Context, zone string, input cloudflare.DNSRecordInput) (DNSZoneRecord, error) {
+	client, zones, err := s.resolveZones(ctx)
+	if err != nil {
+		return DNSZoneRecord{}, err
+	}
+	var selected cloudflare.ZoneInfo
+	if strings.TrimSpace(zone) == "" {
+		selected, err = dnsZoneForName(zones, input.Name)
+	} else {
+		selected, err = findDNSZone(zones, zone)
+	}
+	if err != nil {
+		return DNSZoneRecord{}, err
+	}
+	input.Name = qualifyDNSRecordName(input.Name, selected.Name)
+
+	record, err := client.CreateManagedDNSRecord(ctx, selected.ID, input)
+	if err != nil {
+		return DNSZoneRecord{}, dnsError(err, fmt.Sprintf("failed to create %s record %s", strings.ToUpper(input.Type), input.Name))
+	}
+	return DNSZoneRecord{ZoneID: selected.ID, ZoneName: selected.Name, Record: record}, nil
+}
+
+// Update replaces a managed record in zoneID.
+func (s *DNSService) Update(ctx context.Context, zoneID, recordID string, input cloudflare.DNSRecordInput) (DNSZoneRecord, error) {
+	client, zones, err := s.resolveZones(ctx)
+	if err != nil {
+		return DNSZoneRecord{}, err
+	}
+	selected, err := findDNSZone(zones, zoneID)
+	if err != nil {
+		return DNSZoneRecord{}, err
+	}
+	input.Name = qualifyDNSRecordName(input.Name, selected.Name)
+
+	record, err := client.UpdateManagedDNSRecord(ctx, selected.ID, recordID, input)
+	if err != nil {
+		return DNSZoneRecord{}, dnsError(err, fmt.Sprintf("failed to update DNS record %s", recordID))
+	}
+	return DNSZoneRecord{ZoneID: selected.ID, ZoneName: selected.Name, Record: record}, nil
+}
+
+// Delete removes a managed record from zoneID and returns it.
+func (s *DNSService) Delete(ctx context.Context, zoneID, recordID string) (DNSZoneRecord, error) {
+	client, zones, err := s.resolveZones(ctx)
+	if err != nil {
+		return DNSZoneRecord{}, err
+	}
+	selected, err := findDNSZone(zones, zoneID)
+	if err != nil {
+		return DNSZoneRecord{}, err
+	}
+	record, err := client.DeleteManagedDNSRecord(ctx, selected.ID, recordID)
+	if err != nil {
+		return DNSZoneRecord{}, dnsError(err, fmt.Sprintf("failed to delete DNS record %s", recordID))
+	}
+	return DNSZoneRecord{ZoneID: selected.ID, ZoneName: selected.Name, Record: record}, nil
+}
+
+func (s *DNSService) resolveZones(ctx context.Context) (*cloudflare.Client, []cloudflare.ZoneInfo, error) {
+	if s.settings == nil {
+		return nil, nil, errs.New(errs.CodeDNSUnavailable, "settings service unavailable")
+	}
+	cfg, err := s.settings.ResolveConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := cloudflare.NewClient(cfg)
+	zones, err := configuredCloudflareZones(ctx, cfg, client)
+	if err != nil {
+		return nil, nil, dnsError(err, "failed to list Cloudflare zones")
+	}
+	return client, zones, nil
+}
+
+// findDNSZone matches ref against the ID or name of a configured zone.
+func findDNSZone(zones []cloudflare.ZoneInfo, ref string) (cloudflare.ZoneInfo, error) {
+	ref = strings.TrimSpace(ref)
+	for _, zone := range zones {
+		if zone.ID == ref || normalizeDomain(zone.Name) == normalizeDomain(ref) {
+			return zone, nil
+		}
+	}
+	return cloudflare.ZoneInfo{}, errs.New(errs.CodeDNSZoneNotFound, fmt.Sprintf("%s is not a configured zone", ref))
+}
+
+// dnsZoneForName returns the most specific configured zone containing name.
+func dnsZoneForName(zones []cloudflare.ZoneInfo, name string) (cloudflare.ZoneInfo, error) {
+	name = strings.TrimSuffix(normalizeDomain(name), ".")
+	var best cloudflare.ZoneInfo
+	for _, zone := range zones {
+		zoneName := normalizeDomain(zone.Name)
+		if zoneName == "" || (name != zoneName && !strings.HasSuffix(name, "."+zoneName)) {
+			continue
+		}
+		if len(zoneName) > len(best.Name) {
+			best = zone
+		}
+	}
+	if best.ID == "" {
+		return cloudflare.ZoneInfo{}, errs.New(errs.CodeDNSZoneNotFound, fmt.Sprintf("%s is not in a configured zone; pass zone to name one", name))
+	}
+	return best, nil
+}
+
+// qualifyDNSRecordName turns "@", an empty name, or a name relative to
+// zoneName into a fully qualified one. Names already inside the zone are
+// kept.
+func qualifyDNSRecordName(name, zoneName string) string {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	zoneName = normalizeDomain(zoneName)
+	switch {
+	case name == "" || name == "@":
+		return zoneName
+	case name == zoneName || strings.HasSuffix(name, "."+zoneName):
+		return name
+	}
+	return name + "." + zoneName
+}
+
+func dnsError(err error, message string) error {
+	if _, ok := errs.From(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, cloudflare.ErrMissingToken):
+		return errs.Wrap(errs.CodeCloudflareMissingToken, err.Error(), err)
+	case errors.Is(err, cloudflare.ErrMissingZoneID):
+		return errs.Wrap(errs.CodeCloudflareMissingZone, err.Error(), err)
+	case errors.Is(err, cloudflare.ErrInvalidDNSRecord):
+		return errs.Wrap(errs.CodeDNSInvalidRecord, err.Error(), err)
+	case errors.Is(err, cloudflare.ErrDNSRecordNotManaged):
+		return errs.Wrap(errs.CodeDNSUnmanaged, err.Error(), err)
+	}
+	return errs.Wrap(errs.CodeDNSFailed, fmt.Sprintf("%s: %v", message, err), err)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+)
+
+func TestDNSZoneResolution(t *testing.T) {
+	t.Parallel()
+
+	zones := []cloudflare.ZoneInfo{
+		{ID: "zone-1", Name: "example.com"},
+		{ID: "zone-2", Name: "dev.example.com"},
+		{ID: "zone-3", Name: "other.org"},
+	}
+
+	zone, err := dnsZoneForName(zones, "api.dev.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "zone-2", zone.ID, "the most specific zone wins")
+
+	zone, err = dnsZoneForName(zones, "Example.com.")
+	require.NoError(t, err)
+	require.Equal(t, "zone-1", zone.ID)
+
+	_, err = dnsZoneForName(zones, "notexample.com")
+	appErr, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeDNSZoneNotFound, appErr.Code)
+
+	zone, err = findDNSZone(zones, "OTHER.org")
+	require.NoError(t, err)
+	require.Equal(t, "zone-3", zone.ID)
+	zone, err = findDNSZone(zones, "zone-2")
+	require.NoError(t, err)
+	require.Equal(t, "dev.example.com", zone.Name)
+	_, err = findDNSZone(zones, "zone-9")
+	require.Error(t, err)
+
+	require.Equal(t, "example.com", qualifyDNSRecordName("@", "example.com"))
+	require.Equal(t, "example.com", qualifyDNSRecordName("", "example.com"))
+	require.Equal(t, "_dmarc.example.com", qualifyDNSRecordName("_dmarc", "example.com"))
+	require.Equal(t, "mail.example.com", qualifyDNSRecordName("Mail.Example.com.", "example.com"))
+	require.Equal(t, "*.apps.example.com", qualifyDNSRecordName("*.apps", "example.com"))
+}
+
+func TestDNSErrorMapsClientErrors(t *testing.T) {
+	t.Parallel()
+
+	for err, code := range map[error]errs.Code{
+		cloudflare.ErrMissingToken:                                      errs.CodeCloudflareMissingToken,
+		fmt.Errorf("%w: bad ttl", cloudflare.ErrInvalidDNSRecord):       errs.CodeDNSInvalidRecord,
+		fmt.Errorf("%w: A app", cloudflare.ErrDNSRecordNotManaged):      errs.CodeDNSUnmanaged,
+		fmt.Errorf("cloudflare api error: rate limited"):                errs.CodeDNSFailed,
+		errs.New(errs.CodeDNSZoneNotFound, "example.net is not a zone"): errs.CodeDNSZoneNotFound,
+	} {
+		appErr, ok := errs.From(dnsError(err, "failed"))
+		require.True(t, ok)
+		require.Equal(t, code, appErr.Code, err.Error())
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-notes/internal/errs"
+)
+
+// GitHubPushEvent is the part of a GitHub push webhook payload used to match
+// projects and queue redeploys.
+type GitHubPushEvent struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+		CloneURL string `json:"clone_url"`
+	} `json:"repository"`
+}
+
+type GitHubPushDeployment struct {
+	Project string `json:"project"`
+	JobID   uint   `json:"jobId"`
+}
+
+// GitHubPushResult lists the redeploys a push queued.
+type GitHubPushResult struct {
+	Repository string                 `json:"repository"`
+	Branch     string                 `json:"branch"`
+	Commit     string                 `json:"commit"`
+	Queued     []GitHubPushDeployment `json:"queued"`
+}
+
+// VerifyGitHubWebhookSignature checks an X-Hub-Signature-256 header against
+// the HMAC-SHA256 of body.
+func VerifyGitHubWebhookSignature(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(signature), "sha256=")
+	if !ok {
+		return false
+	}
+	received, err := hex.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+// VerifyGitHubWebhook rejects deliveries when no secret is configured or the
+// signature does not match.
+func (s *ProjectService) VerifyGitHubWebhook(body []byte, signature string) error {
+	secret := strings.TrimSpace(s.cfg.GitHubWebhookSecret)
+	if secret == "" {
+		return errs.New(errs.CodeGitHubWebhookOff, "github webhook secret not configured")
+	}
+	if !VerifyGitHubWebhookSignature(secret, body, signature) {
+		return errs.New(errs.CodeGitHubWebhookSig, "invalid webhook signature")
+	}
+	return nil
+}
+
+// HandleGitHubPush queues a git redeploy for every auto-deploy project whose
+// repository and tracked branch match the push. Tag pushes and branch
+// deletions queue nothing.
+func (s *ProjectService) HandleGitHubPush(ctx context.Context, event GitHubPushEvent) (GitHubPushResult, error) {
+	result := GitHubPushResult{
+		Repository: event.Repository.FullName,
+		Commit:     strings.TrimSpace(event.After),
+		Queued:     []GitHubPushDeployment{},
+	}
+	branch, ok := strings.CutPrefix(strings.TrimSpace(event.Ref), "refs/heads/")
+	if !ok || branch == "" || event.Deleted {
+		return result, nil
+	}
+	result.Branch = branch
+
+	repoKeys := map[string]struct{}{}
+	for _, raw := range []string{event.Repository.HTMLURL, event.Repository.CloneURL} {
+		if key := githubRepoKey(raw); key != "" {
+			repoKeys[key] = struct{}{}
+		}
+	}
+	if fullName := strings.ToLower(strings.TrimSpace(event.Repository.FullName)); fullName != "" {
+		repoKeys["github.com/"+fullName] = struct{}{}
+	}
+	if len(repoKeys) == 0 {
+		return result, errs.New(errs.CodeGitHubWebhookBody, "push payload has no repository")
+	}
+
+	projects, err := s.repo.List(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, project := range projects {
+		if !project.AutoDeploy || project.GitBranch != branch {
+			continue
+		}
+		if _, ok := repoKeys[githubRepoKey(project.RepoURL)]; !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(project.Path, ".git")); err != nil {
+			continue
+		}
+		job, err := s.jobs.Create(ctx, JobTypeGitRedeploy, GitRedeployRequest{
+			Project: project.Name,
+			Ref:     branch,
+			Commit:  result.Commit,
+			Trigger: GitRedeployTriggerWebhook,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Queued = append(result.Queued, GitHubPushDeployment{Project: project.Name, JobID: job.ID})
+	}
+	return result, nil
+}
+
+// githubRepoKey reduces https, ssh, and scp-style repository URLs to
+// "host/owner/repo" so the html_url stored on a project matches a clone URL.
+func githubRepoKey(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		// git@github.com:owner/repo.git
+		if at := strings.Index(raw, "@"); at >= 0 {
+			raw = raw[at+1:]
+		}
+		raw = "ssh://" + strings.Replace(raw, ":", "/", 1)
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	path := strings.Trim(strings.TrimSuffix(strings.Trim(parsed.Path, "/"), ".git"), "/")
+	if path == "" {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname() + "/" + path)
+}
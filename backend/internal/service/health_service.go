@@ -60,13 +60,17 @@ type DockerDaemonIsolationHealthRef struct {
 	RollbackSteps   []string `json:"rollbackSteps,omitempty"`
 }
 
+// TunnelHealth reports the settings tunnel. When tunnels are stored besides
+// it, Name is set and Tunnels holds the health of each stored tunnel.
 type TunnelHealth struct {
+	Name        string             `json:"name,omitempty"`
 	Status      string             `json:"status"`
 	Detail      string             `json:"detail,omitempty"`
 	Tunnel      string             `json:"tunnel,omitempty"`
 	Connections int                `json:"connections"`
 	ConfigPath  string             `json:"configPath,omitempty"`
 	Diagnostics *TunnelDiagnostics `json:"diagnostics,omitempty"`
+	Tunnels     []TunnelHealth     `json:"tunnels,omitempty"`
 }
 
 type TunnelDiagnostics struct {
@@ -88,6 +92,7 @@ type HealthService struct {
 	dbPublishPort     int
 	dockerNetworkMode string
 	daemonMode        string
+	tunnels           *TunnelService
 }
 
 func NewHealthService(host *HostService, settings *SettingsService, cfg config.Config) *HealthService {
@@ -102,6 +107,11 @@ func NewHealthService(host *HostService, settings *SettingsService, cfg config.C
 	}
 }
 
+// SetTunnels adds the stored tunnels to the tunnel health report.
+func (s *HealthService) SetTunnels(tunnels *TunnelService) {
+	s.tunnels = tunnels
+}
+
 func (s *HealthService) Docker(ctx context.Context) DockerHealth {
 	dbPublish := DBHostPublishHealthRef{
 		Mode:    "disabled",
@@ -292,6 +302,54 @@ func (s *HealthService) Tunnel(ctx context.Context) TunnelHealth {
 		Sources:       sources,
 	}
 
+	health := s.tunnelHealth(ctx, cfg, diagnostics)
+	if s.tunnels == nil {
+		return health
+	}
+	names, err := s.tunnels.Names(ctx)
+	if err != nil {
+		logTunnelHealthError("stored tunnels", err, diagnostics)
+		return health
+	}
+	if len(names) == 0 {
+		return health
+	}
+	health.Name = DefaultTunnelName
+	unhealthy := make([]string, 0)
+	for _, name := range names {
+		named := TunnelHealth{Name: name, Status: "error"}
+		tunnelCfg, err := s.tunnels.Config(ctx, cfg, name)
+		if err != nil {
+			named.Detail = err.Error()
+		} else {
+			named = s.tunnelHealth(ctx, tunnelCfg, &TunnelDiagnostics{
+				AccountID:     strings.TrimSpace(tunnelCfg.CloudflareAccountID),
+				ZoneID:        strings.TrimSpace(tunnelCfg.CloudflareZoneID),
+				Tunnel:        strings.TrimSpace(tunnelCfg.CloudflaredTunnel),
+				Domain:        strings.TrimSpace(tunnelCfg.Domain),
+				ConfigPath:    strings.TrimSpace(tunnelCfg.CloudflaredConfig),
+				TokenSet:      strings.TrimSpace(tunnelCfg.CloudflareAPIToken) != "",
+				TunnelRefType: tunnelRefType(tunnelCfg.CloudflaredTunnel),
+			})
+			named.Name = name
+		}
+		if named.Status != "ok" {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s %s", name, named.Status))
+		}
+		health.Tunnels = append(health.Tunnels, named)
+	}
+	// The top-level status stays that of the settings tunnel, but a healthy
+	// default should not hide a failing tunnel other projects depend on.
+	if health.Status == "ok" && len(unhealthy) > 0 {
+		health.Status = "warning"
+		health.Detail = fmt.Sprintf("%s; tunnels not ok: %s", health.Detail, strings.Join(unhealthy, ", "))
+	}
+	return health
+}
+
+// tunnelHealth checks one tunnel through the Cloudflare API when cfg has the
+// credentials for it, or with cloudflared and its config file otherwise.
+func (s *HealthService) tunnelHealth(ctx context.Context, cfg config.Config, diagnostics *TunnelDiagnostics) TunnelHealth {
 	if strings.TrimSpace(cfg.CloudflareAPIToken) != "" &&
 		strings.TrimSpace(cfg.CloudflareAccountID) != "" &&
 		strings.TrimSpace(cfg.CloudflaredTunnel) != "" {
@@ -37,6 +37,7 @@ type DockerContainer struct {
 	RunningFor   string              `json:"runningFor"`
 	Service      string              `json:"service"`
 	Project      string              `json:"project"`
+	QuickService bool                `json:"quickService,omitempty"`
 	PortBindings []DockerPortBinding `json:"portBindings"`
 }
 
@@ -53,6 +54,9 @@ type HostService struct {
 	templatesDir string
 	projects     repository.ProjectRepository
 	infraClient  hostInfraBridgeClient
+
+	volumeBackupDir  string
+	volumeBackupKeep int
 }
 
 type hostInfraBridgeClient interface {
@@ -67,6 +71,16 @@ type hostInfraBridgeClient interface {
 	HostRuntimeStats(ctx context.Context, requestID string) (contract.Result, error)
 	HostRuntimeStream(ctx context.Context, requestID string) (contract.Result, error)
 	ComposeUpStack(ctx context.Context, requestID string, payload contract.ComposeUpStackPayload) (contract.Result, error)
+	ComposeDownStack(ctx context.Context, requestID string, payload contract.ComposeDownStackPayload) (contract.Result, error)
+	DockerImageDigests(ctx context.Context, requestID string, images []string) (contract.Result, error)
+	DockerImagePull(ctx context.Context, requestID, image string) (contract.Result, error)
+	DockerImageTag(ctx context.Context, requestID, source, target string) (contract.Result, error)
+	DockerVolumeBackup(ctx context.Context, requestID string, payload contract.DockerVolumeBackupPayload) (contract.Result, error)
+	DockerVolumeRestore(ctx context.Context, requestID string, payload contract.DockerVolumeRestorePayload) (contract.Result, error)
+	VolumeBackupPrune(ctx context.Context, requestID, project string, keep int) (contract.Result, error)
+	DockerDBDump(ctx context.Context, requestID string, payload contract.DockerDBDumpPayload) (contract.Result, error)
+	DockerDBRestore(ctx context.Context, requestID string, payload contract.DockerDBRestorePayload) (contract.Result, error)
+	DockerVolumeCopy(ctx context.Context, requestID string, payload contract.DockerVolumeCopyPayload) (contract.Result, error)
 }
 
 func NewHostService(templatesDir string, projects repository.ProjectRepository, infraClient hostInfraBridgeClient) *HostService {
@@ -347,6 +361,7 @@ func parseDockerPSLinesToContainers(lines []string) ([]DockerContainer, error) {
 			RunningFor:   entry.RunningFor,
 			Service:      labels["com.docker.compose.service"],
 			Project:      labels["com.docker.compose.project"],
+			QuickService: labels[contract.QuickServiceManagedLabelKey] == contract.QuickServiceManagedLabelValue,
 			PortBindings: parseDockerPorts(entry.Ports),
 		})
 	}
@@ -630,6 +645,14 @@ func (s *HostService) restartProjectStack(ctx context.Context, requestID, projec
 	if s.infraClient == nil {
 		return fmt.Errorf("infra bridge client unavailable")
 	}
+	slot, projectDir, err := s.projectLiveSlot(ctx, project)
+	if err != nil {
+		return err
+	}
+	if slot != "" {
+		hostLogf(logger, "project %q is served by blue/green slot %q; restarting the slot with the pinned services", project, slot)
+		return s.StartBlueGreenProject(ctx, requestID, project, projectDir, slot, true, logger)
+	}
 
 	hostLogf(logger, "submitting compose_up_stack intent via infra bridge for project %q", project)
 	result, err := s.infraClient.ComposeUpStack(ctx, requestID, contract.ComposeUpStackPayload{
@@ -64,8 +64,39 @@ type stubHostInfraBridgeClient struct {
 	composeCalled            bool
 	composeRequestID         string
 	composePayload           contract.ComposeUpStackPayload
+	composePayloads          []contract.ComposeUpStackPayload
 	composeResult            contract.Result
+	composeDownPayloads      []contract.ComposeDownStackPayload
+	composeDownResult        contract.Result
+	composeDownErr           error
 	composeErr               error
+	imageDigestsImages       []string
+	imageDigestsResult       contract.Result
+	imageDigestsErr          error
+	pulledImages             []string
+	pullResult               contract.Result
+	pullErr                  error
+	taggedImages             [][2]string
+	tagResult                contract.Result
+	tagErr                   error
+	volumeBackupPayloads     []contract.DockerVolumeBackupPayload
+	volumeBackupResult       contract.Result
+	volumeBackupErr          error
+	volumeRestorePayloads    []contract.DockerVolumeRestorePayload
+	volumeRestoreResult      contract.Result
+	volumeRestoreErr         error
+	pruneKeeps               []int
+	pruneResult              contract.Result
+	pruneErr                 error
+	dbDumpPayloads           []contract.DockerDBDumpPayload
+	dbDumpResult             contract.Result
+	dbDumpErr                error
+	dbRestorePayloads        []contract.DockerDBRestorePayload
+	dbRestoreResult          contract.Result
+	dbRestoreErr             error
+	volumeCopyPayloads       []contract.DockerVolumeCopyPayload
+	volumeCopyResult         contract.Result
+	volumeCopyErr            error
 }
 
 func (s *stubHostInfraBridgeClient) StopContainer(_ context.Context, requestID, container string) (contract.Result, error) {
@@ -139,9 +170,60 @@ func (s *stubHostInfraBridgeClient) ComposeUpStack(_ context.Context, requestID
 	s.composeCalled = true
 	s.composeRequestID = requestID
 	s.composePayload = payload
+	s.composePayloads = append(s.composePayloads, payload)
 	return s.composeResult, s.composeErr
 }
 
+func (s *stubHostInfraBridgeClient) ComposeDownStack(_ context.Context, _ string, payload contract.ComposeDownStackPayload) (contract.Result, error) {
+	s.composeDownPayloads = append(s.composeDownPayloads, payload)
+	return s.composeDownResult, s.composeDownErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerImageDigests(_ context.Context, _ string, images []string) (contract.Result, error) {
+	s.imageDigestsImages = images
+	return s.imageDigestsResult, s.imageDigestsErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerImagePull(_ context.Context, _ string, image string) (contract.Result, error) {
+	s.pulledImages = append(s.pulledImages, image)
+	return s.pullResult, s.pullErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerImageTag(_ context.Context, _ string, source, target string) (contract.Result, error) {
+	s.taggedImages = append(s.taggedImages, [2]string{source, target})
+	return s.tagResult, s.tagErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerVolumeBackup(_ context.Context, _ string, payload contract.DockerVolumeBackupPayload) (contract.Result, error) {
+	s.volumeBackupPayloads = append(s.volumeBackupPayloads, payload)
+	return s.volumeBackupResult, s.volumeBackupErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerVolumeRestore(_ context.Context, _ string, payload contract.DockerVolumeRestorePayload) (contract.Result, error) {
+	s.volumeRestorePayloads = append(s.volumeRestorePayloads, payload)
+	return s.volumeRestoreResult, s.volumeRestoreErr
+}
+
+func (s *stubHostInfraBridgeClient) VolumeBackupPrune(_ context.Context, _ string, _ string, keep int) (contract.Result, error) {
+	s.pruneKeeps = append(s.pruneKeeps, keep)
+	return s.pruneResult, s.pruneErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerDBDump(_ context.Context, _ string, payload contract.DockerDBDumpPayload) (contract.Result, error) {
+	s.dbDumpPayloads = append(s.dbDumpPayloads, payload)
+	return s.dbDumpResult, s.dbDumpErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerDBRestore(_ context.Context, _ string, payload contract.DockerDBRestorePayload) (contract.Result, error) {
+	s.dbRestorePayloads = append(s.dbRestorePayloads, payload)
+	return s.dbRestoreResult, s.dbRestoreErr
+}
+
+func (s *stubHostInfraBridgeClient) DockerVolumeCopy(_ context.Context, _ string, payload contract.DockerVolumeCopyPayload) (contract.Result, error) {
+	s.volumeCopyPayloads = append(s.volumeCopyPayloads, payload)
+	return s.volumeCopyResult, s.volumeCopyErr
+}
+
 type captureHostLogger struct {
 	lines []string
 }
@@ -644,6 +726,118 @@ func TestHostServiceRestartProjectStackBridgeSuccess(t *testing.T) {
 	require.NotEmpty(t, logger.lines)
 }
 
+func TestHostServiceRestartProjectServicesRunsTiersInOrder(t *testing.T) {
+	t.Parallel()
+
+	bridge := &stubHostInfraBridgeClient{
+		composeResult: contract.Result{Status: contract.StatusSucceeded, IntentID: "intent-compose-tier"},
+		listContainersResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"ID":"a1","Names":"demo-db-1","Status":"Up 5 seconds (healthy)","Labels":"com.docker.compose.project=demo,com.docker.compose.service=db"}`,
+					`{"ID":"a2","Names":"demo-api-1","Status":"Up 3 seconds","Labels":"com.docker.compose.project=demo,com.docker.compose.service=api"}`,
+					`{"ID":"a3","Names":"other-api-1","Status":"Exited (1) 2 minutes ago","Labels":"com.docker.compose.project=other,com.docker.compose.service=api"}`,
+				},
+			},
+		},
+	}
+	logger := &captureHostLogger{}
+	svc := &HostService{infraClient: bridge}
+
+	err := svc.RestartProjectServicesWithLogger(context.Background(), "job-7", "demo", [][]string{{"db"}, {"api"}}, ProjectServicesRestartOptions{
+		Build:        true,
+		PollInterval: time.Millisecond,
+	}, logger)
+	require.NoError(t, err)
+	require.Len(t, bridge.composePayloads, 2)
+	require.Equal(t, []string{"db"}, bridge.composePayloads[0].Services)
+	require.True(t, bridge.composePayloads[0].Build)
+	require.True(t, bridge.composePayloads[0].NoDeps)
+	require.Equal(t, []string{"api"}, bridge.composePayloads[1].Services)
+	require.False(t, bridge.composePayloads[1].Build)
+	require.True(t, bridge.composePayloads[1].ForceRecreate)
+}
+
+func TestHostServiceRestartProjectServicesStopsOnFailedTier(t *testing.T) {
+	t.Parallel()
+
+	bridge := &stubHostInfraBridgeClient{
+		composeResult: contract.Result{Status: contract.StatusSucceeded, IntentID: "intent-compose-tier"},
+		listContainersResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"ID":"a1","Names":"demo-db-1","Status":"Up 40 seconds (unhealthy)","Labels":"com.docker.compose.project=demo,com.docker.compose.service=db"}`,
+				},
+			},
+		},
+	}
+	svc := &HostService{infraClient: bridge}
+
+	err := svc.RestartProjectServicesWithLogger(context.Background(), "job-8", "demo", [][]string{{"db"}, {"api"}}, ProjectServicesRestartOptions{
+		PollInterval: time.Millisecond,
+	}, &captureHostLogger{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "db")
+	require.Len(t, bridge.composePayloads, 1)
+
+	bridge.composePayloads = nil
+	bridge.listContainersResult.Data = map[string]any{"lines": []string{}}
+	err = svc.RestartProjectServicesWithLogger(context.Background(), "job-9", "demo", [][]string{{"db"}}, ProjectServicesRestartOptions{
+		HealthTimeout: 5 * time.Millisecond,
+		PollInterval:  time.Millisecond,
+	}, &captureHostLogger{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timed out")
+}
+
+func TestHostServiceRestartProjectServicesAcceptsCleanExitOfOneShotService(t *testing.T) {
+	t.Parallel()
+
+	bridge := &stubHostInfraBridgeClient{
+		composeResult: contract.Result{Status: contract.StatusSucceeded, IntentID: "intent-compose-tier"},
+		listContainersResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"ID":"a1","Names":"demo-migrate-1","Status":"Exited (0) 2 seconds ago","Labels":"com.docker.compose.project=demo,com.docker.compose.service=migrate"}`,
+					`{"ID":"a2","Names":"demo-api-1","Status":"Up 3 seconds","Labels":"com.docker.compose.project=demo,com.docker.compose.service=api"}`,
+				},
+			},
+		},
+	}
+	svc := &HostService{infraClient: bridge}
+
+	err := svc.RestartProjectServicesWithLogger(context.Background(), "job-10", "demo", [][]string{{"migrate"}, {"api"}}, ProjectServicesRestartOptions{
+		OneShot:      []string{"migrate"},
+		PollInterval: time.Millisecond,
+	}, &captureHostLogger{})
+	require.NoError(t, err)
+	require.Len(t, bridge.composePayloads, 2)
+
+	// Without the one-shot hint a clean exit still fails a long-running service.
+	bridge.composePayloads = nil
+	err = svc.RestartProjectServicesWithLogger(context.Background(), "job-11", "demo", [][]string{{"migrate"}, {"api"}}, ProjectServicesRestartOptions{
+		PollInterval: time.Millisecond,
+	}, &captureHostLogger{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "migrate")
+	require.Len(t, bridge.composePayloads, 1)
+
+	// A one-shot service that exits non-zero is still a failure.
+	bridge.composePayloads = nil
+	bridge.listContainersResult.Data = map[string]any{"lines": []string{
+		`{"ID":"a1","Names":"demo-migrate-1","Status":"Exited (1) 2 seconds ago","Labels":"com.docker.compose.project=demo,com.docker.compose.service=migrate"}`,
+	}}
+	err = svc.RestartProjectServicesWithLogger(context.Background(), "job-12", "demo", [][]string{{"migrate"}}, ProjectServicesRestartOptions{
+		OneShot:      []string{"migrate"},
+		PollInterval: time.Millisecond,
+	}, &captureHostLogger{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Exited (1)")
+}
+
 func TestHostServiceRestartProjectStackBridgeFailureMapping(t *testing.T) {
 	t.Parallel()
 
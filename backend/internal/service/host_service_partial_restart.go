@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/jobs"
+)
+
+const (
+	defaultServiceHealthTimeout      = 2 * time.Minute
+	defaultServiceHealthPollInterval = 2 * time.Second
+)
+
+type ProjectServicesRestartOptions struct {
+	// Build rebuilds images for the first tier only; dependents are recreated
+	// against their existing images.
+	Build bool
+	// OneShot names services without a restart policy; a clean exit is their
+	// expected end state rather than a failure.
+	OneShot       []string
+	HealthTimeout time.Duration
+	PollInterval  time.Duration
+}
+
+// RestartProjectServicesWithLogger recreates the given service tiers one at a
+// time and waits for every container in a tier to be running and healthy
+// before moving on.
+func (s *HostService) RestartProjectServicesWithLogger(
+	ctx context.Context,
+	requestID string,
+	project string,
+	tiers [][]string,
+	opts ProjectServicesRestartOptions,
+	logger jobs.Logger,
+) error {
+	project = strings.TrimSpace(project)
+	if project == "" || project == "." || project == ".." {
+		return fmt.Errorf("invalid project name")
+	}
+	if len(tiers) == 0 {
+		return fmt.Errorf("no services to restart")
+	}
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = defaultServiceHealthTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultServiceHealthPollInterval
+	}
+
+	// Services that moved into a live blue/green slot are recreated there;
+	// recreating them in the project's own compose project would bring back
+	// the copies the switch removed.
+	slot, projectDir, err := s.projectLiveSlot(ctx, project)
+	if err != nil {
+		return err
+	}
+	slotServices := map[string]struct{}{}
+	if slot != "" {
+		parsed, ok := workbenchBlueGreenSlotFile(projectDir, slot)
+		if !ok {
+			return fmt.Errorf("slot %q has no readable compose file in %s", slot, projectDir)
+		}
+		for _, service := range parsed.Services {
+			slotServices[service.ServiceName] = struct{}{}
+		}
+	}
+
+	for index, tier := range tiers {
+		if len(tier) == 0 {
+			continue
+		}
+		hostLogf(logger, "tier %d/%d: recreating %s", index+1, len(tiers), strings.Join(tier, ", "))
+		groups := []contract.ComposeUpStackPayload{{Project: project, Services: []string{}}}
+		if slot != "" {
+			groups = append(groups, contract.ComposeUpStackPayload{
+				Project:     project,
+				ProjectDir:  projectDir,
+				ConfigFiles: []string{WorkbenchBlueGreenSlotComposeFile(slot)},
+				ProjectName: WorkbenchBlueGreenSlotProject(projectComposeProjectName(projectDir), slot),
+				Services:    []string{},
+			})
+		}
+		for _, service := range tier {
+			if _, ok := slotServices[service]; ok {
+				groups[1].Services = append(groups[1].Services, service)
+			} else {
+				groups[0].Services = append(groups[0].Services, service)
+			}
+		}
+
+		for _, payload := range groups {
+			if len(payload.Services) == 0 {
+				continue
+			}
+			healthProject := project
+			if payload.ProjectName != "" {
+				healthProject = payload.ProjectName
+				hostLogf(logger, "tier %d/%d: %s run in blue/green slot %q", index+1, len(tiers), strings.Join(payload.Services, ", "), slot)
+			}
+			payload.Build = opts.Build && index == 0
+			payload.ForceRecreate = true
+			payload.NoDeps = true
+			result, err := s.infraClient.ComposeUpStack(ctx, requestID, payload)
+			if err != nil {
+				hostLogf(logger, "infra bridge compose_up_stack error: %v", err)
+				return bridgeTaskError("restart compose services failed", contract.TaskTypeComposeUpStack, healthProject, err)
+			}
+			if err := bridgeResultError("restart compose services failed", contract.TaskTypeComposeUpStack, healthProject, result); err != nil {
+				hostLogf(logger, "infra bridge compose_up_stack failed result: %v", err)
+				return err
+			}
+
+			hostLogf(logger, "tier %d/%d: waiting up to %s for %s to become healthy", index+1, len(tiers), opts.HealthTimeout, strings.Join(payload.Services, ", "))
+			if err := s.waitForServicesHealthy(ctx, healthProject, payload.Services, opts, logger); err != nil {
+				return err
+			}
+		}
+		hostLogf(logger, "tier %d/%d: healthy", index+1, len(tiers))
+	}
+	return nil
+}
+
+func (s *HostService) waitForServicesHealthy(
+	ctx context.Context,
+	project string,
+	services []string,
+	opts ProjectServicesRestartOptions,
+	logger jobs.Logger,
+) error {
+	wanted := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		wanted[strings.ToLower(strings.TrimSpace(service))] = struct{}{}
+	}
+	oneShot := make(map[string]struct{}, len(opts.OneShot))
+	for _, service := range opts.OneShot {
+		oneShot[strings.ToLower(strings.TrimSpace(service))] = struct{}{}
+	}
+
+	deadline := time.Now().Add(opts.HealthTimeout)
+	lastPending := ""
+	for {
+		containers, err := s.ListContainers(ctx, true)
+		if err != nil {
+			return fmt.Errorf("list containers while waiting for health: %w", err)
+		}
+
+		statuses := make(map[string][]string, len(wanted))
+		for _, container := range containers {
+			if !strings.EqualFold(strings.TrimSpace(container.Project), project) {
+				continue
+			}
+			service := strings.ToLower(strings.TrimSpace(container.Service))
+			if _, ok := wanted[service]; !ok {
+				continue
+			}
+			statuses[service] = append(statuses[service], container.Status)
+		}
+
+		pending := []string{}
+		for service := range wanted {
+			serviceStatuses := statuses[service]
+			if len(serviceStatuses) == 0 {
+				pending = append(pending, service+" (no containers)")
+				continue
+			}
+			_, isOneShot := oneShot[service]
+			for _, status := range serviceStatuses {
+				if isOneShot && serviceExitedCleanly(status) {
+					continue
+				}
+				if serviceHealthCheckFailed(status) {
+					return fmt.Errorf("service %q failed after restart: %s", service, status)
+				}
+				if !isHealthyContainerStatus(status) {
+					pending = append(pending, fmt.Sprintf("%s (%s)", service, status))
+					break
+				}
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		sort.Strings(pending)
+		summary := strings.Join(pending, ", ")
+		if summary != lastPending {
+			hostLogf(logger, "waiting for %s", summary)
+			lastPending = summary
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for %s", opts.HealthTimeout, summary)
+		}
+
+		timer := time.NewTimer(opts.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// serviceHealthCheckFailed reports statuses that will not recover on their
+// own, so the wait can stop early instead of running into the timeout.
+func serviceHealthCheckFailed(status string) bool {
+	normalized := strings.ToLower(strings.TrimSpace(status))
+	return strings.Contains(normalized, "(unhealthy)") ||
+		strings.HasPrefix(normalized, "exited") ||
+		strings.HasPrefix(normalized, "dead")
+}
+
+// serviceExitedCleanly reports a container that ran to completion with exit
+// code 0, e.g. `Exited (0) 3 seconds ago`.
+func serviceExitedCleanly(status string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(status)), "exited (0)")
+}
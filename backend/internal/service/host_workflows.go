@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"go-notes/internal/jobs"
 	"go-notes/internal/models"
@@ -15,6 +16,18 @@ type RestartProjectStackRequest struct {
 	Project string `json:"project"`
 }
 
+// RestartProjectServicesRequest carries a restart plan computed when the job
+// was queued, so the job restarts exactly what the caller was shown.
+type RestartProjectServicesRequest struct {
+	Project              string     `json:"project"`
+	Service              string     `json:"service"`
+	Mode                 string     `json:"mode"`
+	Revision             int        `json:"revision"`
+	Tiers                [][]string `json:"tiers"`
+	OneShot              []string   `json:"oneShot,omitempty"`
+	HealthTimeoutSeconds int        `json:"healthTimeoutSeconds,omitempty"`
+}
+
 type HostWorkflows struct {
 	host *HostService
 }
@@ -28,6 +41,12 @@ func (w *HostWorkflows) Register(runner *jobs.Runner) {
 		return
 	}
 	runner.Register(JobTypeHostRestart, w.handleRestartProjectStack)
+	runner.Register(JobTypeServiceRestart, w.handleRestartProjectServices)
+	runner.Register(JobTypeImageUpdate, w.handleProjectImageUpdate)
+	runner.Register(JobTypeVolumeBackup, w.handleProjectVolumeBackup)
+	runner.Register(JobTypeVolumeRestore, w.handleProjectVolumeRestore)
+	runner.Register(JobTypeDBDump, w.handleProjectDatabaseDump)
+	runner.Register(JobTypeDBRestore, w.handleProjectDatabaseRestore)
 }
 
 func (w *HostWorkflows) handleRestartProjectStack(ctx context.Context, job models.Job, logger jobs.Logger) error {
@@ -53,3 +72,171 @@ func (w *HostWorkflows) handleRestartProjectStack(ctx context.Context, job model
 	logger.Logf("compose restart completed for project %q", req.Project)
 	return nil
 }
+
+func (w *HostWorkflows) handleRestartProjectServices(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	if w.host == nil {
+		return fmt.Errorf("host service unavailable")
+	}
+	var req RestartProjectServicesRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse restart project services request: %w", err)
+	}
+	req.Project = strings.TrimSpace(req.Project)
+	if req.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if req.Project == "." || req.Project == ".." || !httpx.IsSafeRef(req.Project) {
+		return fmt.Errorf("invalid project name")
+	}
+
+	logger.Logf("%s of service %q in project %q (revision %d): %d tier(s)", req.Mode, req.Service, req.Project, req.Revision, len(req.Tiers))
+	if err := w.host.RestartProjectServicesWithLogger(ctx, fmt.Sprintf("job-%d", job.ID), req.Project, req.Tiers, ProjectServicesRestartOptions{
+		Build:         req.Mode == WorkbenchServiceRestartModeRedeploy,
+		OneShot:       req.OneShot,
+		HealthTimeout: time.Duration(req.HealthTimeoutSeconds) * time.Second,
+	}, logger); err != nil {
+		return err
+	}
+	logger.Logf("service %s completed for project %q", req.Mode, req.Project)
+	return nil
+}
+
+func (w *HostWorkflows) handleProjectImageUpdate(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	if w.host == nil {
+		return fmt.Errorf("host service unavailable")
+	}
+	var req ProjectImageUpdateRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse project image update request: %w", err)
+	}
+	req.Project = strings.TrimSpace(req.Project)
+	if req.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if req.Project == "." || req.Project == ".." || !httpx.IsSafeRef(req.Project) {
+		return fmt.Errorf("invalid project name")
+	}
+	if len(req.Images) == 0 {
+		return fmt.Errorf("no images to update")
+	}
+
+	logger.Logf("updating %d image(s) for project %q (revision %d)", len(req.Images), req.Project, req.Revision)
+	if err := w.host.UpdateProjectImagesWithLogger(ctx, fmt.Sprintf("job-%d", job.ID), req.Project, req.Images, ProjectServicesRestartOptions{
+		HealthTimeout: time.Duration(req.HealthTimeoutSeconds) * time.Second,
+	}, logger); err != nil {
+		return err
+	}
+	logger.Logf("image update completed for project %q", req.Project)
+	return nil
+}
+
+func (w *HostWorkflows) handleProjectVolumeBackup(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	if w.host == nil {
+		return fmt.Errorf("host service unavailable")
+	}
+	var req ProjectVolumeBackupRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse project volume backup request: %w", err)
+	}
+	req.Project = strings.TrimSpace(req.Project)
+	if req.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if req.Project == "." || req.Project == ".." || !httpx.IsSafeRef(req.Project) {
+		return fmt.Errorf("invalid project name")
+	}
+	if req.Reason == "" {
+		req.Reason = VolumeBackupReasonManual
+	}
+
+	backup, err := w.host.BackupProjectVolumesWithLogger(ctx, fmt.Sprintf("job-%d", job.ID), req.Project, req.Reason, logger)
+	if err != nil {
+		return err
+	}
+	if backup != nil {
+		logger.Logf("volume backup %s completed for project %q: %d volume(s), %d bytes", backup.BackupID, req.Project, len(backup.Volumes), backup.SizeBytes)
+	}
+	return nil
+}
+
+func (w *HostWorkflows) handleProjectVolumeRestore(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	if w.host == nil {
+		return fmt.Errorf("host service unavailable")
+	}
+	var req ProjectVolumeRestoreRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse project volume restore request: %w", err)
+	}
+	req.Project = strings.TrimSpace(req.Project)
+	if req.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if req.Project == "." || req.Project == ".." || !httpx.IsSafeRef(req.Project) {
+		return fmt.Errorf("invalid project name")
+	}
+	if !httpx.IsSafeRef(req.BackupID) {
+		return fmt.Errorf("invalid backup id")
+	}
+
+	if err := w.host.RestoreProjectVolumesWithLogger(ctx, fmt.Sprintf("job-%d", job.ID), req.Project, req.BackupID, req.Volumes, logger); err != nil {
+		return err
+	}
+	logger.Logf("volume restore of backup %s completed for project %q", req.BackupID, req.Project)
+	return nil
+}
+
+func (w *HostWorkflows) handleProjectDatabaseDump(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	if w.host == nil {
+		return fmt.Errorf("host service unavailable")
+	}
+	var req ProjectDatabaseDumpRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse project database dump request: %w", err)
+	}
+	req.Project = strings.TrimSpace(req.Project)
+	if req.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if req.Project == "." || req.Project == ".." || !httpx.IsSafeRef(req.Project) {
+		return fmt.Errorf("invalid project name")
+	}
+
+	dump, err := w.host.DumpProjectDatabasesWithLogger(ctx, fmt.Sprintf("job-%d", job.ID), req.Project, req.Databases, logger)
+	if err != nil {
+		return err
+	}
+	logger.Logf("database dump %s completed for project %q: %d database(s), %d bytes", dump.DumpID, req.Project, len(dump.Dumps), dump.SizeBytes)
+	return nil
+}
+
+func (w *HostWorkflows) handleProjectDatabaseRestore(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	if w.host == nil {
+		return fmt.Errorf("host service unavailable")
+	}
+	var req ProjectDatabaseRestoreRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse project database restore request: %w", err)
+	}
+	req.Project = strings.TrimSpace(req.Project)
+	req.TargetProject = strings.TrimSpace(req.TargetProject)
+	if req.TargetProject == "" {
+		req.TargetProject = req.Project
+	}
+	for _, project := range []string{req.Project, req.TargetProject} {
+		if project == "" {
+			return fmt.Errorf("project is required")
+		}
+		if project == "." || project == ".." || !httpx.IsSafeRef(project) {
+			return fmt.Errorf("invalid project name")
+		}
+	}
+	if !httpx.IsSafeRef(req.DumpID) {
+		return fmt.Errorf("invalid dump id")
+	}
+
+	if err := w.host.RestoreProjectDatabaseDumpWithLogger(ctx, fmt.Sprintf("job-%d", job.ID), req.Project, req.DumpID, req.TargetProject, req.Services, logger); err != nil {
+		return err
+	}
+	logger.Logf("database restore of dump %s into project %q completed", req.DumpID, req.TargetProject)
+	return nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/jobs"
+)
+
+// ImageDigestStatus compares the digest of a locally pulled image with the
+// digest its registry currently serves for the same tag.
+type ImageDigestStatus struct {
+	Image        string `json:"image"`
+	Status       string `json:"status"`
+	LocalDigest  string `json:"localDigest,omitempty"`
+	LocalImageID string `json:"localImageId,omitempty"`
+	RemoteDigest string `json:"remoteDigest,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type ProjectImageUpdate struct {
+	ImageDigestStatus
+	Services []string `json:"services"`
+}
+
+type ProjectImageUpdateReport struct {
+	Project          string               `json:"project"`
+	Revision         int                  `json:"revision"`
+	CheckedAt        time.Time            `json:"checkedAt"`
+	UpdatesAvailable int                  `json:"updatesAvailable"`
+	Images           []ProjectImageUpdate `json:"images"`
+	// ServicesWithoutImage lists build-only services and services whose image
+	// is interpolated from .env; neither can be compared against a registry.
+	ServicesWithoutImage []string `json:"servicesWithoutImage"`
+}
+
+type QuickServiceImageUpdate struct {
+	ImageDigestStatus
+	Containers []string `json:"containers"`
+}
+
+type QuickServiceImageUpdateReport struct {
+	CheckedAt        time.Time                 `json:"checkedAt"`
+	UpdatesAvailable int                       `json:"updatesAvailable"`
+	Images           []QuickServiceImageUpdate `json:"images"`
+}
+
+// ProjectImageUpdateTarget is one image to pull, with the services using it
+// and the image ID to restore if the updated services fail to come up.
+type ProjectImageUpdateTarget struct {
+	Image           string   `json:"image"`
+	Services        []string `json:"services"`
+	PreviousImageID string   `json:"previousImageId"`
+	RemoteDigest    string   `json:"remoteDigest,omitempty"`
+}
+
+type ProjectImageUpdateRequest struct {
+	Project              string                     `json:"project"`
+	Revision             int                        `json:"revision"`
+	Images               []ProjectImageUpdateTarget `json:"images"`
+	HealthTimeoutSeconds int                        `json:"healthTimeoutSeconds,omitempty"`
+}
+
+func (s *HostService) CheckImageDigests(ctx context.Context, images []string) ([]ImageDigestStatus, error) {
+	if s.infraClient == nil {
+		return nil, errs.WithDetails(
+			errs.New(errs.CodeHostDockerFailed, "infra bridge client unavailable"),
+			map[string]any{"task_type": contract.TaskTypeDockerImageDigests},
+		)
+	}
+	unique := uniqueImageNames(images)
+	if len(unique) == 0 {
+		return []ImageDigestStatus{}, nil
+	}
+
+	result, err := s.infraClient.DockerImageDigests(ctx, "", unique)
+	if err != nil {
+		return nil, bridgeTaskError("failed to check image digests", contract.TaskTypeDockerImageDigests, "docker", err)
+	}
+	if err := bridgeResultError("failed to check image digests", contract.TaskTypeDockerImageDigests, "docker", result); err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Images []struct {
+			Image        string `json:"image"`
+			Status       string `json:"status"`
+			LocalDigest  string `json:"local_digest"`
+			LocalImageID string `json:"local_image_id"`
+			RemoteDigest string `json:"remote_digest"`
+			Error        string `json:"error"`
+		} `json:"images"`
+	}
+	raw, err := json.Marshal(result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("encode image digest payload: %w", err)
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("decode image digest payload: %w", err)
+	}
+
+	statuses := make([]ImageDigestStatus, 0, len(payload.Images))
+	for _, item := range payload.Images {
+		statuses = append(statuses, ImageDigestStatus{
+			Image:        strings.TrimSpace(item.Image),
+			Status:       strings.TrimSpace(item.Status),
+			LocalDigest:  strings.TrimSpace(item.LocalDigest),
+			LocalImageID: strings.TrimSpace(item.LocalImageID),
+			RemoteDigest: strings.TrimSpace(item.RemoteDigest),
+			Error:        strings.TrimSpace(item.Error),
+		})
+	}
+	return statuses, nil
+}
+
+// ProjectImageUpdates checks every image referenced by the snapshot's
+// services and groups the services by image.
+func (s *HostService) ProjectImageUpdates(ctx context.Context, snapshot WorkbenchStackSnapshot) (ProjectImageUpdateReport, error) {
+	servicesByImage, withoutImage := workbenchServicesByImage(snapshot)
+	images := make([]string, 0, len(servicesByImage))
+	for image := range servicesByImage {
+		images = append(images, image)
+	}
+	statuses, err := s.CheckImageDigests(ctx, images)
+	if err != nil {
+		return ProjectImageUpdateReport{}, err
+	}
+	return buildProjectImageUpdateReport(snapshot, servicesByImage, withoutImage, statuses, time.Now().UTC()), nil
+}
+
+func buildProjectImageUpdateReport(
+	snapshot WorkbenchStackSnapshot,
+	servicesByImage map[string][]string,
+	withoutImage []string,
+	statuses []ImageDigestStatus,
+	checkedAt time.Time,
+) ProjectImageUpdateReport {
+	report := ProjectImageUpdateReport{
+		Project:              snapshot.ProjectName,
+		Revision:             snapshot.Revision,
+		CheckedAt:            checkedAt,
+		Images:               make([]ProjectImageUpdate, 0, len(statuses)),
+		ServicesWithoutImage: withoutImage,
+	}
+	for _, status := range statuses {
+		if status.Status == contract.ImageDigestStatusUpdateAvailable {
+			report.UpdatesAvailable++
+		}
+		report.Images = append(report.Images, ProjectImageUpdate{
+			ImageDigestStatus: status,
+			Services:          append([]string{}, servicesByImage[status.Image]...),
+		})
+	}
+	sort.SliceStable(report.Images, func(i, j int) bool {
+		return report.Images[i].Image < report.Images[j].Image
+	})
+	return report
+}
+
+// QuickServiceImageUpdates checks the images of containers started through
+// quick services. Quick services are not part of a compose stack, so updates
+// are applied by re-running the quick service.
+func (s *HostService) QuickServiceImageUpdates(ctx context.Context) (QuickServiceImageUpdateReport, error) {
+	containers, err := s.ListContainers(ctx, true)
+	if err != nil {
+		return QuickServiceImageUpdateReport{}, err
+	}
+	containersByImage := map[string][]string{}
+	for _, container := range containers {
+		if !container.QuickService {
+			continue
+		}
+		image := strings.TrimSpace(container.Image)
+		if image == "" {
+			continue
+		}
+		containersByImage[image] = append(containersByImage[image], container.Name)
+	}
+	images := make([]string, 0, len(containersByImage))
+	for image := range containersByImage {
+		images = append(images, image)
+	}
+
+	statuses, err := s.CheckImageDigests(ctx, images)
+	if err != nil {
+		return QuickServiceImageUpdateReport{}, err
+	}
+	report := QuickServiceImageUpdateReport{
+		CheckedAt: time.Now().UTC(),
+		Images:    make([]QuickServiceImageUpdate, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		if status.Status == contract.ImageDigestStatusUpdateAvailable {
+			report.UpdatesAvailable++
+		}
+		names := append([]string{}, containersByImage[status.Image]...)
+		sort.Strings(names)
+		report.Images = append(report.Images, QuickServiceImageUpdate{ImageDigestStatus: status, Containers: names})
+	}
+	sort.SliceStable(report.Images, func(i, j int) bool {
+		return report.Images[i].Image < report.Images[j].Image
+	})
+	return report, nil
+}
+
+// UpdateProjectImagesWithLogger pulls each target image and recreates only
+// the services using it. If the recreated services do not become healthy, the
+// previous image ID is tagged back onto the reference and the services are
+// recreated again, then the job fails. Images updated before the failing one
+// are kept.
+func (s *HostService) UpdateProjectImagesWithLogger(
+	ctx context.Context,
+	requestID string,
+	project string,
+	targets []ProjectImageUpdateTarget,
+	opts ProjectServicesRestartOptions,
+	logger jobs.Logger,
+) error {
+	project = strings.TrimSpace(project)
+	if project == "" || project == "." || project == ".." {
+		return fmt.Errorf("invalid project name")
+	}
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = defaultServiceHealthTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultServiceHealthPollInterval
+	}
+
+	for _, target := range targets {
+		image := strings.TrimSpace(target.Image)
+		if image == "" || len(target.Services) == 0 {
+			continue
+		}
+		hostLogf(logger, "pulling %s for %s", image, strings.Join(target.Services, ", "))
+		result, err := s.infraClient.DockerImagePull(ctx, requestID, image)
+		if err == nil {
+			err = bridgeResultError("pull image failed", contract.TaskTypeDockerImagePull, image, result)
+		}
+		if err != nil {
+			hostLogf(logger, "pull %s failed: %v", image, err)
+			return bridgeTaskError("pull image failed", contract.TaskTypeDockerImagePull, image, err)
+		}
+
+		recreateErr := s.recreateServicesAndWait(ctx, requestID, project, target.Services, opts, logger)
+		if recreateErr == nil {
+			hostLogf(logger, "%s updated", image)
+			continue
+		}
+
+		hostLogf(logger, "updated services failed: %v", recreateErr)
+		previous := strings.TrimSpace(target.PreviousImageID)
+		if previous == "" {
+			return fmt.Errorf("update of %s failed and no previous image is known for rollback: %w", image, recreateErr)
+		}
+		hostLogf(logger, "rolling %s back to %s", image, previous)
+		tagResult, tagErr := s.infraClient.DockerImageTag(ctx, requestID, previous, image)
+		if tagErr == nil {
+			tagErr = bridgeResultError("tag previous image failed", contract.TaskTypeDockerImageTag, image, tagResult)
+		}
+		if tagErr != nil {
+			return fmt.Errorf("update of %s failed (%v) and rollback tag failed: %w", image, recreateErr, tagErr)
+		}
+		if rollbackErr := s.recreateServicesAndWait(ctx, requestID, project, target.Services, opts, logger); rollbackErr != nil {
+			return fmt.Errorf("update of %s failed (%v) and rollback did not become healthy: %w", image, recreateErr, rollbackErr)
+		}
+		return fmt.Errorf("update of %s failed and was rolled back: %w", image, recreateErr)
+	}
+	return nil
+}
+
+func (s *HostService) recreateServicesAndWait(
+	ctx context.Context,
+	requestID string,
+	project string,
+	services []string,
+	opts ProjectServicesRestartOptions,
+	logger jobs.Logger,
+) error {
+	result, err := s.infraClient.ComposeUpStack(ctx, requestID, contract.ComposeUpStackPayload{
+		Project:       project,
+		ForceRecreate: true,
+		Services:      services,
+		NoDeps:        true,
+	})
+	if err != nil {
+		return bridgeTaskError("recreate compose services failed", contract.TaskTypeComposeUpStack, project, err)
+	}
+	if err := bridgeResultError("recreate compose services failed", contract.TaskTypeComposeUpStack, project, result); err != nil {
+		return err
+	}
+	return s.waitForServicesHealthy(ctx, project, services, opts, logger)
+}
+
+func workbenchServicesByImage(snapshot WorkbenchStackSnapshot) (map[string][]string, []string) {
+	byImage := map[string][]string{}
+	withoutImage := []string{}
+	for _, service := range snapshot.Services {
+		image := strings.TrimSpace(service.Image)
+		name := strings.TrimSpace(service.ServiceName)
+		// Interpolated images cannot be resolved without the project's env.
+		if image == "" || strings.Contains(image, "${") {
+			withoutImage = append(withoutImage, name)
+			continue
+		}
+		byImage[image] = append(byImage[image], name)
+	}
+	for image := range byImage {
+		sort.Strings(byImage[image])
+	}
+	sort.Strings(withoutImage)
+	return byImage, withoutImage
+}
+
+func uniqueImageNames(images []string) []string {
+	seen := make(map[string]struct{}, len(images))
+	unique := make([]string, 0, len(images))
+	for _, image := range images {
+		trimmed := strings.TrimSpace(image)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := seen[trimmed]; ok {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		unique = append(unique, trimmed)
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/infra/contract"
+)
+
+func TestHostServiceProjectImageUpdatesGroupsServicesByImage(t *testing.T) {
+	t.Parallel()
+
+	bridge := &stubHostInfraBridgeClient{
+		imageDigestsResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"images": []any{
+					map[string]any{"image": "nginx:1.25", "status": "update_available", "local_digest": "sha256:old", "remote_digest": "sha256:new", "local_image_id": "sha256:img"},
+					map[string]any{"image": "postgres:16", "status": "up_to_date", "local_digest": "sha256:pg", "remote_digest": "sha256:pg"},
+				},
+			},
+		},
+	}
+	svc := &HostService{infraClient: bridge}
+
+	report, err := svc.ProjectImageUpdates(context.Background(), WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Revision:    3,
+		Services: []WorkbenchComposeService{
+			{ServiceName: "web", Image: "nginx:1.25"},
+			{ServiceName: "admin", Image: "nginx:1.25"},
+			{ServiceName: "db", Image: "postgres:16"},
+			{ServiceName: "api", BuildSource: "./api"},
+			{ServiceName: "worker", Image: "${WORKER_IMAGE}"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"nginx:1.25", "postgres:16"}, bridge.imageDigestsImages)
+	require.Equal(t, 1, report.UpdatesAvailable)
+	require.Equal(t, 3, report.Revision)
+	require.Len(t, report.Images, 2)
+	require.Equal(t, []string{"admin", "web"}, report.Images[0].Services)
+	require.Equal(t, "sha256:img", report.Images[0].LocalImageID)
+	require.Equal(t, []string{"api", "worker"}, report.ServicesWithoutImage)
+}
+
+func TestHostServiceUpdateProjectImagesRollsBackUnhealthyServices(t *testing.T) {
+	t.Parallel()
+
+	bridge := &stubHostInfraBridgeClient{
+		pullResult:    contract.Result{Status: contract.StatusSucceeded},
+		tagResult:     contract.Result{Status: contract.StatusSucceeded},
+		composeResult: contract.Result{Status: contract.StatusSucceeded},
+		listContainersResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"ID":"a1","Names":"demo-web-1","Status":"Exited (1) 1 second ago","Labels":"com.docker.compose.project=demo,com.docker.compose.service=web"}`,
+				},
+			},
+		},
+	}
+	svc := &HostService{infraClient: bridge}
+
+	err := svc.UpdateProjectImagesWithLogger(context.Background(), "job-3", "demo", []ProjectImageUpdateTarget{{
+		Image:           "nginx:1.25",
+		Services:        []string{"web"},
+		PreviousImageID: "sha256:img",
+	}}, ProjectServicesRestartOptions{
+		HealthTimeout: 10 * time.Millisecond,
+		PollInterval:  time.Millisecond,
+	}, &captureHostLogger{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "rollback")
+	require.Equal(t, []string{"nginx:1.25"}, bridge.pulledImages)
+	require.Equal(t, [][2]string{{"sha256:img", "nginx:1.25"}}, bridge.taggedImages)
+	require.Len(t, bridge.composePayloads, 2)
+	for _, payload := range bridge.composePayloads {
+		require.Equal(t, []string{"web"}, payload.Services)
+		require.True(t, payload.NoDeps)
+		require.False(t, payload.Build)
+	}
+}
+
+func TestHostServiceUpdateProjectImagesKeepsHealthyUpdate(t *testing.T) {
+	t.Parallel()
+
+	bridge := &stubHostInfraBridgeClient{
+		pullResult:    contract.Result{Status: contract.StatusSucceeded},
+		composeResult: contract.Result{Status: contract.StatusSucceeded},
+		listContainersResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"ID":"a1","Names":"demo-web-1","Status":"Up 2 seconds (healthy)","Labels":"com.docker.compose.project=demo,com.docker.compose.service=web"}`,
+				},
+			},
+		},
+	}
+	svc := &HostService{infraClient: bridge}
+
+	err := svc.UpdateProjectImagesWithLogger(context.Background(), "job-4", "demo", []ProjectImageUpdateTarget{{
+		Image:           "nginx:1.25",
+		Services:        []string{"web"},
+		PreviousImageID: "sha256:img",
+	}}, ProjectServicesRestartOptions{PollInterval: time.Millisecond}, &captureHostLogger{})
+	require.NoError(t, err)
+	require.Empty(t, bridge.taggedImages)
+	require.Len(t, bridge.composePayloads, 1)
+}
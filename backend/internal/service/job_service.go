@@ -237,7 +237,7 @@ func jobMatchesProject(job models.Job, project string) bool {
 
 func jobTypeSupportsProjectFilter(jobType string) bool {
 	switch jobType {
-	case JobTypeCreateTemplate, JobTypeDeployExisting, JobTypeHostRestart, JobTypeProjectArchive:
+	case JobTypeCreateTemplate, JobTypeDeployExisting, JobTypeHostRestart, JobTypeServiceRestart, JobTypeImageUpdate, JobTypeVolumeBackup, JobTypeVolumeRestore, JobTypeDBDump, JobTypeDBRestore, JobTypeBlueGreenDeploy, JobTypeGitRedeploy, JobTypeProjectArchive, JobTypeProjectHostnames, JobTypeProjectRoutes, JobTypeProjectRestore, JobTypeProjectClone:
 		return true
 	default:
 		return false
@@ -1,13 +1,28 @@
 package service
 
 const (
-	JobTypeCreateTemplate   = "create_template"
-	JobTypeDeployExisting   = "deploy_existing"
-	JobTypeQuickService     = "quick_service"
-	JobTypeForwardLocal     = "forward_local"
-	JobTypeProjectArchive   = "project_archive"
-	JobTypeDockerRun        = "docker_run"
-	JobTypeDockerCompose    = "docker_compose_up"
-	JobTypeHostRestart      = "host_restart_project_stack"
-	JobTypeNetBirdModeApply = "netbird_mode_apply"
+	JobTypeCreateTemplate             = "create_template"
+	JobTypeDeployExisting             = "deploy_existing"
+	JobTypeQuickService               = "quick_service"
+	JobTypeForwardLocal               = "forward_local"
+	JobTypeProjectArchive             = "project_archive"
+	JobTypeBlueGreenDeploy            = "project_blue_green_deploy"
+	JobTypeGitRedeploy                = "project_git_redeploy"
+	JobTypeProjectHostnames           = "project_hostname_change"
+	JobTypeProjectRoutes              = "project_routes_apply"
+	JobTypeProjectIngress             = "project_ingress_update"
+	JobTypeProjectTunnel              = "project_tunnel_move"
+	JobTypeProjectRestore             = "project_restore"
+	JobTypeProjectClone               = "project_clone"
+	JobTypeDockerRun                  = "docker_run"
+	JobTypeDockerCompose              = "docker_compose_up"
+	JobTypeHostRestart                = "host_restart_project_stack"
+	JobTypeServiceRestart             = "host_restart_project_services"
+	JobTypeImageUpdate                = "project_image_update"
+	JobTypeVolumeBackup               = "project_volume_backup"
+	JobTypeVolumeRestore              = "project_volume_restore"
+	JobTypeDBDump                     = "project_db_dump"
+	JobTypeDBRestore                  = "project_db_restore"
+	JobTypeNetBirdModeApply           = "netbird_mode_apply"
+	JobTypeCloudflareReconcileCleanup = "cloudflare_reconcile_cleanup"
 )
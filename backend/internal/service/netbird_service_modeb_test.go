@@ -84,3 +84,5 @@ func (fakeNetBirdServiceProjectRepo) GetByName(context.Context, string) (*models
 }
 
 func (fakeNetBirdServiceProjectRepo) Update(context.Context, *models.Project) error { return nil }
+
+func (fakeNetBirdServiceProjectRepo) Delete(context.Context, uint) error { return nil }
@@ -292,6 +292,8 @@ func (fakeNetBirdProjectRepo) GetByName(context.Context, string) (*models.Projec
 }
 func (fakeNetBirdProjectRepo) Update(context.Context, *models.Project) error { return nil }
 
+func (fakeNetBirdProjectRepo) Delete(context.Context, uint) error { return nil }
+
 type fakeNetBirdJobRepo struct {
 	jobs                     []models.Job
 	listErr                  error
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/validate"
+)
+
+var githubOrgRe = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+
+// ProjectAccessRequest puts a new project's hostname behind Cloudflare Access
+// when Enabled. Visitors matching any of the rules are let in.
+type ProjectAccessRequest struct {
+	Enabled      bool     `json:"enabled"`
+	Emails       []string `json:"emails,omitempty"`
+	EmailDomains []string `json:"emailDomains,omitempty"`
+	GitHubOrgs   []string `json:"githubOrgs,omitempty"`
+}
+
+func (r ProjectAccessRequest) rules() cloudflare.AccessRules {
+	return cloudflare.AccessRules{Emails: r.Emails, EmailDomains: r.EmailDomains, GitHubOrgs: r.GitHubOrgs}
+}
+
+// normalizeProjectAccessRequest validates an enabled Access toggle in place so
+// a bad rule fails the request instead of the job.
+func normalizeProjectAccessRequest(access *ProjectAccessRequest) error {
+	if access == nil || !access.Enabled {
+		return nil
+	}
+	rules, err := normalizeProjectAccessRules(access.rules())
+	if err != nil {
+		return err
+	}
+	access.Emails = rules.Emails
+	access.EmailDomains = rules.EmailDomains
+	access.GitHubOrgs = rules.GitHubOrgs
+	return nil
+}
+
+// ProjectAccessHostname is the Access state of one project hostname.
+// Application is nil when the hostname is public.
+type ProjectAccessHostname struct {
+	Hostname    string                        `json:"hostname"`
+	Protected   bool                          `json:"protected"`
+	Application *cloudflare.AccessApplication `json:"application,omitempty"`
+}
+
+type projectAccessClient interface {
+	AccessApplicationForHostname(ctx context.Context, hostname string) (*cloudflare.AccessApplication, error)
+	EnsureAccessApplication(ctx context.Context, hostname string, rules cloudflare.AccessRules) (cloudflare.AccessApplication, error)
+	DeleteAccessApplication(ctx context.Context, appID, hostname string) (cloudflare.AccessDeleteResult, error)
+}
+
+// Access lists the project's hostnames with the Access application guarding
+// each one.
+func (s *ProjectArchiveService) Access(ctx context.Context, projectName string) ([]ProjectAccessHostname, error) {
+	runtimeCfg, hostnames, err := s.projectAccessHostnames(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return listProjectAccess(ctx, cloudflare.NewClient(runtimeCfg), hostnames)
+}
+
+// UpdateAccess puts hostname, or every project hostname when it is empty,
+// behind a managed Access application allowing rules.
+func (s *ProjectArchiveService) UpdateAccess(ctx context.Context, projectName, hostname string, rules cloudflare.AccessRules) ([]ProjectAccessHostname, error) {
+	rules, err := normalizeProjectAccessRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	runtimeCfg, hostnames, err := s.projectAccessHostnames(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := selectProjectAccessHostnames(hostnames, hostname)
+	if err != nil {
+		return nil, err
+	}
+	client := cloudflare.NewClient(runtimeCfg)
+	for _, target := range targets {
+		if _, err := client.EnsureAccessApplication(ctx, target, rules); err != nil {
+			return nil, projectAccessError(err, fmt.Sprintf("failed to protect %s", target))
+		}
+	}
+	return listProjectAccess(ctx, client, hostnames)
+}
+
+// RemoveAccess deletes the managed Access application of hostname, or of
+// every project hostname when it is empty, making it public again.
+// Applications gungnr did not create are reported and left in place.
+func (s *ProjectArchiveService) RemoveAccess(ctx context.Context, projectName, hostname string) ([]ProjectAccessHostname, error) {
+	runtimeCfg, hostnames, err := s.projectAccessHostnames(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := selectProjectAccessHostnames(hostnames, hostname)
+	if err != nil {
+		return nil, err
+	}
+	client := cloudflare.NewClient(runtimeCfg)
+	for _, target := range targets {
+		app, err := client.AccessApplicationForHostname(ctx, target)
+		if err != nil {
+			return nil, projectAccessError(err, fmt.Sprintf("failed to inspect Access for %s", target))
+		}
+		if app == nil {
+			continue
+		}
+		if !app.Managed {
+			return nil, errs.New(errs.CodeProjectAccessUnmanaged, fmt.Sprintf("%s is guarded by Access application %q, which gungnr does not manage", target, app.Name))
+		}
+		if _, err := client.DeleteAccessApplication(ctx, app.ID, target); err != nil {
+			return nil, projectAccessError(err, fmt.Sprintf("failed to remove Access for %s", target))
+		}
+	}
+	return listProjectAccess(ctx, client, hostnames)
+}
+
+func (s *ProjectArchiveService) projectAccessHostnames(ctx context.Context, projectName string) (config.Config, []string, error) {
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+	hostnames := s.discoverHostnames(ctx, resolved.NormalizedName, normalizeDomain(runtimeCfg.Domain), map[string]struct{}{})
+	return runtimeCfg, hostnames, nil
+}
+
+func listProjectAccess(ctx context.Context, client projectAccessClient, hostnames []string) ([]ProjectAccessHostname, error) {
+	result := make([]ProjectAccessHostname, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		app, err := client.AccessApplicationForHostname(ctx, hostname)
+		if err != nil {
+			return nil, projectAccessError(err, fmt.Sprintf("failed to inspect Access for %s", hostname))
+		}
+		result = append(result, ProjectAccessHostname{Hostname: hostname, Protected: app != nil, Application: app})
+	}
+	return result, nil
+}
+
+func selectProjectAccessHostnames(hostnames []string, hostname string) ([]string, error) {
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+	if hostname == "" {
+		if len(hostnames) == 0 {
+			return nil, errs.New(errs.CodeProjectAccessHostname, "project has no hostnames")
+		}
+		return hostnames, nil
+	}
+	for _, candidate := range hostnames {
+		if candidate == hostname {
+			return []string{hostname}, nil
+		}
+	}
+	return nil, errs.New(errs.CodeProjectAccessHostname, fmt.Sprintf("%s is not a hostname of this project", hostname))
+}
+
+// normalizeProjectAccessRules validates each rule and returns the rules in
+// the normalized form the Cloudflare client stores them in.
+func normalizeProjectAccessRules(rules cloudflare.AccessRules) (cloudflare.AccessRules, error) {
+	normalized := cloudflare.AccessRules{
+		Emails:       []string{},
+		EmailDomains: []string{},
+		GitHubOrgs:   []string{},
+	}
+	seen := map[string]struct{}{}
+	for _, email := range rules.Emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			continue
+		}
+		local, domain, ok := strings.Cut(email, "@")
+		if !ok || local == "" || strings.Contains(domain, "@") || validate.Domain(domain) != nil {
+			return cloudflare.AccessRules{}, errs.New(errs.CodeProjectAccessInvalid, fmt.Sprintf("%q is not a valid email address", email))
+		}
+		if _, dup := seen["email:"+email]; !dup {
+			seen["email:"+email] = struct{}{}
+			normalized.Emails = append(normalized.Emails, email)
+		}
+	}
+	for _, domain := range rules.EmailDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain == "" {
+			continue
+		}
+		if validate.Domain(domain) != nil {
+			return cloudflare.AccessRules{}, errs.New(errs.CodeProjectAccessInvalid, fmt.Sprintf("%q is not a valid email domain", domain))
+		}
+		if _, dup := seen["domain:"+domain]; !dup {
+			seen["domain:"+domain] = struct{}{}
+			normalized.EmailDomains = append(normalized.EmailDomains, domain)
+		}
+	}
+	for _, org := range rules.GitHubOrgs {
+		org = strings.TrimSpace(org)
+		if org == "" {
+			continue
+		}
+		if !githubOrgRe.MatchString(org) {
+			return cloudflare.AccessRules{}, errs.New(errs.CodeProjectAccessInvalid, fmt.Sprintf("%q is not a valid GitHub organization", org))
+		}
+		if _, dup := seen["org:"+org]; !dup {
+			seen["org:"+org] = struct{}{}
+			normalized.GitHubOrgs = append(normalized.GitHubOrgs, org)
+		}
+	}
+	if normalized.Empty() {
+		return cloudflare.AccessRules{}, errs.New(errs.CodeProjectAccessInvalid, "at least one email, email domain, or GitHub organization is required")
+	}
+	return normalized, nil
+}
+
+func projectAccessError(err error, message string) error {
+	switch {
+	case errors.Is(err, cloudflare.ErrMissingToken):
+		return errs.Wrap(errs.CodeCloudflareMissingToken, err.Error(), err)
+	case errors.Is(err, cloudflare.ErrMissingAccountID):
+		return errs.Wrap(errs.CodeCloudflareMissingAccount, err.Error(), err)
+	case errors.Is(err, cloudflare.ErrAccessAppNotManaged):
+		return errs.Wrap(errs.CodeProjectAccessUnmanaged, err.Error(), err)
+	case errors.Is(err, cloudflare.ErrMissingGitHubAccessLogin):
+		return errs.Wrap(errs.CodeProjectAccessInvalid, err.Error(), err)
+	}
+	return errs.Wrap(errs.CodeProjectAccessFailed, fmt.Sprintf("%s: %v", message, err), err)
+}
+
+// protectProjectHostname applies the Access toggle of a create request. It
+// runs before the tunnel route exists so the hostname is never public.
+func protectProjectHostname(ctx context.Context, logger jobs.Logger, client projectAccessClient, hostname string, access *ProjectAccessRequest) error {
+	if access == nil || !access.Enabled {
+		return nil
+	}
+	rules, err := normalizeProjectAccessRules(access.rules())
+	if err != nil {
+		return err
+	}
+	logger.Logf("putting %s behind Cloudflare Access", hostname)
+	app, err := client.EnsureAccessApplication(ctx, hostname, rules)
+	if err != nil {
+		return fmt.Errorf("configure Cloudflare Access for %s: %w", hostname, err)
+	}
+	logger.Logf("Cloudflare Access application %s allows %d email(s), %d email domain(s), %d GitHub org(s)",
+		app.ID, len(rules.Emails), len(rules.EmailDomains), len(rules.GitHubOrgs))
+	return nil
+}
+
+// carryProjectAccess puts targets behind the rules of the managed Access
+// applications guarding previous, so a hostname replacing a protected one is
+// protected before it is routed. Targets that already have an application are
+// left alone. It returns the previous applications, which the caller deletes
+// once the previous hostnames are gone.
+func carryProjectAccess(
+	ctx context.Context,
+	logger jobs.Logger,
+	client projectAccessClient,
+	previous []string,
+	targets []string,
+	warnings map[string]struct{},
+) ([]cloudflare.AccessApplication, error) {
+	apps := []cloudflare.AccessApplication{}
+	rules := cloudflare.AccessRules{}
+	for _, hostname := range previous {
+		app, err := client.AccessApplicationForHostname(ctx, hostname)
+		if err != nil {
+			return nil, fmt.Errorf("inspect Cloudflare Access for %s: %w", hostname, err)
+		}
+		if app == nil {
+			continue
+		}
+		if !app.Managed {
+			addArchiveWarning(warnings, fmt.Sprintf("Access application %q for %s is not managed by gungnr; its rules were not carried over", app.Name, hostname))
+			continue
+		}
+		if app.Rules.Empty() {
+			addArchiveWarning(warnings, fmt.Sprintf("Access application %s for %s has no managed allow rules to carry over", app.ID, hostname))
+			continue
+		}
+		carried := *app
+		carried.Domain = hostname
+		apps = append(apps, carried)
+		rules.Emails = append(rules.Emails, app.Rules.Emails...)
+		rules.EmailDomains = append(rules.EmailDomains, app.Rules.EmailDomains...)
+		rules.GitHubOrgs = append(rules.GitHubOrgs, app.Rules.GitHubOrgs...)
+	}
+	if len(apps) == 0 {
+		return apps, nil
+	}
+	for _, hostname := range targets {
+		existing, err := client.AccessApplicationForHostname(ctx, hostname)
+		if err != nil {
+			return nil, fmt.Errorf("inspect Cloudflare Access for %s: %w", hostname, err)
+		}
+		if existing != nil {
+			logger.Logf("%s is already behind Cloudflare Access application %s", hostname, existing.ID)
+			continue
+		}
+		logger.Logf("putting %s behind Cloudflare Access with the rules of %s", hostname, strings.Join(accessAppDomains(apps), ", "))
+		app, err := client.EnsureAccessApplication(ctx, hostname, rules)
+		if err != nil {
+			return nil, fmt.Errorf("configure Cloudflare Access for %s: %w", hostname, err)
+		}
+		logger.Logf("Cloudflare Access application %s allows %d email(s), %d email domain(s), %d GitHub org(s)",
+			app.ID, len(app.Rules.Emails), len(app.Rules.EmailDomains), len(app.Rules.GitHubOrgs))
+	}
+	return apps, nil
+}
+
+func accessAppDomains(apps []cloudflare.AccessApplication) []string {
+	domains := make([]string, 0, len(apps))
+	for _, app := range apps {
+		domains = append(domains, app.Domain)
+	}
+	return domains
+}
+
+// removeCarriedProjectAccess deletes the Access applications returned by
+// carryProjectAccess. Failures are recorded as warnings; a leftover
+// application only guards a hostname that no longer routes anywhere.
+func removeCarriedProjectAccess(
+	ctx context.Context,
+	logger jobs.Logger,
+	client projectAccessClient,
+	apps []cloudflare.AccessApplication,
+	warnings map[string]struct{},
+) int {
+	removed := 0
+	for _, app := range apps {
+		result, err := client.DeleteAccessApplication(ctx, app.ID, app.Domain)
+		if err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("delete Access application %s for %s failed: %v", app.ID, app.Domain, err))
+			continue
+		}
+		if !result.Deleted {
+			addArchiveWarning(warnings, fmt.Sprintf("skip Access application %s because %s", app.ID, result.SkipReason))
+			continue
+		}
+		logger.Logf("deleted Cloudflare Access application %s for %s", app.ID, app.Domain)
+		removed++
+	}
+	return removed
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+)
+
+type stubAccessClient struct {
+	apps    map[string]*cloudflare.AccessApplication
+	ensured map[string]cloudflare.AccessRules
+	deleted []string
+	err     error
+}
+
+func (s *stubAccessClient) AccessApplicationForHostname(_ context.Context, hostname string) (*cloudflare.AccessApplication, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.apps[hostname], nil
+}
+
+func (s *stubAccessClient) EnsureAccessApplication(_ context.Context, hostname string, rules cloudflare.AccessRules) (cloudflare.AccessApplication, error) {
+	if s.err != nil {
+		return cloudflare.AccessApplication{}, s.err
+	}
+	if s.ensured == nil {
+		s.ensured = map[string]cloudflare.AccessRules{}
+	}
+	s.ensured[hostname] = rules
+	return cloudflare.AccessApplication{ID: "app-" + hostname, Domain: hostname, Managed: true, Rules: rules}, nil
+}
+
+func (s *stubAccessClient) DeleteAccessApplication(_ context.Context, appID, hostname string) (cloudflare.AccessDeleteResult, error) {
+	if s.err != nil {
+		return cloudflare.AccessDeleteResult{}, s.err
+	}
+	app := s.apps[hostname]
+	if app == nil || app.ID != appID {
+		return cloudflare.AccessDeleteResult{SkipReason: "it no longer exists"}, nil
+	}
+	s.deleted = append(s.deleted, appID)
+	return cloudflare.AccessDeleteResult{Deleted: true}, nil
+}
+
+func TestNormalizeProjectAccessRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := normalizeProjectAccessRules(cloudflare.AccessRules{
+		Emails:       []string{" Ops@Example.com", "ops@example.com", ""},
+		EmailDomains: []string{"@Example.org"},
+		GitHubOrgs:   []string{"Acme-Labs"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops@example.com"}, rules.Emails)
+	require.Equal(t, []string{"example.org"}, rules.EmailDomains)
+	require.Equal(t, []string{"Acme-Labs"}, rules.GitHubOrgs)
+
+	for _, invalid := range []cloudflare.AccessRules{
+		{},
+		{Emails: []string{"not-an-email"}},
+		{Emails: []string{"a@b@example.com"}},
+		{EmailDomains: []string{"localhost"}},
+		{GitHubOrgs: []string{"acme/labs"}},
+	} {
+		_, err := normalizeProjectAccessRules(invalid)
+		appErr, ok := errs.From(err)
+		require.True(t, ok, "expected app error for %+v", invalid)
+		require.Equal(t, errs.CodeProjectAccessInvalid, appErr.Code)
+	}
+}
+
+func TestProtectProjectHostnameAppliesEnabledToggle(t *testing.T) {
+	t.Parallel()
+
+	client := &stubAccessClient{}
+	logger := &archiveTestLogger{}
+
+	require.NoError(t, protectProjectHostname(context.Background(), logger, client, "demo.example.com", nil))
+	require.NoError(t, protectProjectHostname(context.Background(), logger, client, "demo.example.com", &ProjectAccessRequest{Emails: []string{"a@example.com"}}))
+	require.Empty(t, client.ensured)
+
+	err := protectProjectHostname(context.Background(), logger, client, "demo.example.com", &ProjectAccessRequest{
+		Enabled:    true,
+		GitHubOrgs: []string{"acme"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"acme"}, client.ensured["demo.example.com"].GitHubOrgs)
+
+	client.err = errors.New("cloudflare down")
+	err = protectProjectHostname(context.Background(), logger, client, "other.example.com", &ProjectAccessRequest{
+		Enabled: true,
+		Emails:  []string{"a@example.com"},
+	})
+	require.ErrorContains(t, err, "configure Cloudflare Access for other.example.com")
+}
+
+func TestPlanAccessAppsOnlyMarksManagedAppsEligible(t *testing.T) {
+	t.Parallel()
+
+	client := &stubAccessClient{apps: map[string]*cloudflare.AccessApplication{
+		"demo.example.com": {ID: "app-1", Name: "gungnr: demo.example.com", Managed: true},
+		"wiki.example.com": {ID: "app-2", Name: "Team wiki"},
+	}}
+	cfg := config.Config{CloudflareAPIToken: "token", CloudflareAccountID: "acct"}
+	warnings := map[string]struct{}{}
+
+	apps := planAccessApps(context.Background(), cfg, client, []string{"demo.example.com", "public.example.com", "wiki.example.com"}, warnings)
+	require.Equal(t, []ProjectArchivePlanAccessApp{
+		{ID: "app-1", Hostname: "demo.example.com", Name: "gungnr: demo.example.com", DeleteEligible: true},
+		{ID: "app-2", Hostname: "wiki.example.com", Name: "Team wiki", SkipReason: "application is not managed by gungnr"},
+	}, apps)
+	require.Empty(t, warnings)
+
+	require.Empty(t, planAccessApps(context.Background(), config.Config{}, client, []string{"demo.example.com"}, warnings))
+}
+
+func TestRemoveArchiveAccessApps(t *testing.T) {
+	t.Parallel()
+
+	client := &stubAccessClient{apps: map[string]*cloudflare.AccessApplication{
+		"demo.example.com": {ID: "app-1", Managed: true},
+	}}
+	targets := dedupeAccessDeleteTargets([]ProjectArchiveAccessDeleteTarget{
+		{AppID: "app-1", Hostname: "Demo.Example.com"},
+		{AppID: "app-1", Hostname: "demo.example.com"},
+		{AppID: "app-9", Hostname: "gone.example.com"},
+	})
+	require.Len(t, targets, 2)
+
+	logger := &archiveTestLogger{}
+	warnings := map[string]struct{}{}
+	status, summary := removeArchiveAccessApps(context.Background(), logger, client, false, targets, warnings)
+	require.Equal(t, projectArchiveStepStatusSkipped, status)
+	require.Equal(t, 2, summary.Skipped)
+	require.Empty(t, client.deleted)
+
+	status, summary = removeArchiveAccessApps(context.Background(), logger, client, true, targets, warnings)
+	require.Equal(t, projectArchiveStepStatusCompleted, status)
+	require.Equal(t, projectArchiveAccessSummary{Targets: 2, Removed: 1, Skipped: 1}, summary)
+	require.Equal(t, []string{"app-1"}, client.deleted)
+	require.Contains(t, sortedArchiveWarnings(warnings), "skip Access application app-9 because it no longer exists")
+	requireArchiveLogContains(t, logger.lines, "archive step access: result=completed")
+}
@@ -22,6 +22,12 @@ type ProjectArchiveOptions struct {
 	RemoveVolumes    bool `json:"removeVolumes"`
 	RemoveIngress    bool `json:"removeIngress"`
 	RemoveDNS        bool `json:"removeDns"`
+	// RemoveAccess deletes the Cloudflare Access applications gungnr created
+	// for the project's hostnames.
+	RemoveAccess bool `json:"removeAccess"`
+	// BackupVolumes snapshots the project's named volumes before anything is
+	// removed; a failed backup aborts the archive.
+	BackupVolumes bool `json:"backupVolumes"`
 }
 
 type ProjectArchivePlan struct {
@@ -32,6 +38,7 @@ type ProjectArchivePlan struct {
 	ServiceExposures []ProjectArchivePlanServiceCleanup `json:"serviceExposures"`
 	Ingress          []ProjectArchivePlanIngress        `json:"ingressRules"`
 	DNSRecords       []ProjectArchivePlanDNSRecord      `json:"dnsRecords"`
+	AccessApps       []ProjectArchivePlanAccessApp      `json:"accessApps"`
 	Warnings         []string                           `json:"warnings"`
 }
 
@@ -59,9 +66,11 @@ type ProjectArchivePlanServiceCleanup struct {
 }
 
 type ProjectArchivePlanIngress struct {
-	Hostname string `json:"hostname"`
-	Service  string `json:"service"`
-	Source   string `json:"source"`
+	Hostname string                    `json:"hostname"`
+	Path     string                    `json:"path,omitempty"`
+	Service  string                    `json:"service"`
+	Source   string                    `json:"source"`
+	Origin   *cloudflare.IngressOrigin `json:"origin,omitempty"`
 }
 
 type ProjectArchivePlanDNSRecord struct {
@@ -75,6 +84,15 @@ type ProjectArchivePlanDNSRecord struct {
 	SkipReason     string `json:"skipReason,omitempty"`
 }
 
+type ProjectArchivePlanAccessApp struct {
+	ID             string                 `json:"id"`
+	Hostname       string                 `json:"hostname"`
+	Name           string                 `json:"name"`
+	Rules          cloudflare.AccessRules `json:"rules"`
+	DeleteEligible bool                   `json:"deleteEligible"`
+	SkipReason     string                 `json:"skipReason,omitempty"`
+}
+
 type ProjectArchiveActor struct {
 	UserID uint   `json:"userId"`
 	Login  string `json:"login"`
@@ -87,10 +105,21 @@ type ProjectArchiveDNSDeleteTarget struct {
 	Content  string `json:"content"`
 }
 
+// ProjectArchiveAccessDeleteTarget is a managed Access application the
+// archive deletes. Rules are recorded in the manifest so restore can put the
+// hostname back behind Access.
+type ProjectArchiveAccessDeleteTarget struct {
+	AppID    string                 `json:"appId"`
+	Hostname string                 `json:"hostname"`
+	Rules    cloudflare.AccessRules `json:"rules"`
+}
+
 type ProjectArchiveIngressDeleteTarget struct {
-	Hostname string `json:"hostname"`
-	Service  string `json:"service"`
-	Source   string `json:"source"`
+	Hostname string                    `json:"hostname"`
+	Path     string                    `json:"path,omitempty"`
+	Service  string                    `json:"service"`
+	Source   string                    `json:"source"`
+	Origin   *cloudflare.IngressOrigin `json:"origin,omitempty"`
 }
 
 type ProjectArchiveTargets struct {
@@ -100,6 +129,7 @@ type ProjectArchiveTargets struct {
 	ExposureHostnames  []string                            `json:"exposureHostnames,omitempty"`
 	IngressRules       []ProjectArchiveIngressDeleteTarget `json:"ingressRules,omitempty"`
 	DNSRecords         []ProjectArchiveDNSDeleteTarget     `json:"dnsRecords"`
+	AccessApps         []ProjectArchiveAccessDeleteTarget  `json:"accessApps,omitempty"`
 }
 
 type ProjectArchiveJobRequest struct {
@@ -111,11 +141,13 @@ type ProjectArchiveJobRequest struct {
 }
 
 type ProjectArchiveService struct {
-	cfg      config.Config
-	projects repository.ProjectRepository
-	settings *SettingsService
-	jobs     *JobService
-	host     *HostService
+	cfg         config.Config
+	projects    repository.ProjectRepository
+	settings    *SettingsService
+	jobs        *JobService
+	host        *HostService
+	deployments repository.DeploymentRepository
+	tunnels     *TunnelService
 }
 
 func NewProjectArchiveService(
@@ -140,11 +172,12 @@ func DefaultProjectArchiveOptions() ProjectArchiveOptions {
 		RemoveVolumes:    false,
 		RemoveIngress:    true,
 		RemoveDNS:        true,
+		RemoveAccess:     true,
 	}
 }
 
 func (s *ProjectArchiveService) Plan(ctx context.Context, projectName string) (ProjectArchivePlan, error) {
-	runtimeCfg, err := s.resolveRuntimeConfig(ctx)
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
 	if err != nil {
 		return ProjectArchivePlan{}, err
 	}
@@ -167,6 +200,7 @@ func (s *ProjectArchiveService) Plan(ctx context.Context, projectName string) (P
 		ServiceExposures: []ProjectArchivePlanServiceCleanup{},
 		Ingress:          []ProjectArchivePlanIngress{},
 		DNSRecords:       []ProjectArchivePlanDNSRecord{},
+		AccessApps:       []ProjectArchivePlanAccessApp{},
 		Warnings:         []string{},
 	}
 	if resolved.ProjectRecord != nil && strings.TrimSpace(resolved.ProjectRecord.Status) != "" {
@@ -183,11 +217,12 @@ func (s *ProjectArchiveService) Plan(ctx context.Context, projectName string) (P
 	if len(plan.Hostnames) == 0 {
 		addArchiveWarning(warnings, "no managed hostnames were discovered for this project")
 	}
-	plan.Containers = s.planContainers(ctx, resolved.NormalizedName, exposureContainers, warnings)
+	plan.Containers = s.planContainers(ctx, resolved.NormalizedName, projectComposeProjectName(resolved.ProjectDir), exposureContainers, warnings)
 
 	cfClient := cloudflare.NewClient(runtimeCfg)
 	plan.Ingress = s.planIngress(ctx, runtimeCfg, cfClient, plan.Hostnames, warnings)
 	plan.DNSRecords = s.planDNSRecords(ctx, runtimeCfg, cfClient, plan.Hostnames, warnings)
+	plan.AccessApps = planAccessApps(ctx, runtimeCfg, cfClient, plan.Hostnames, warnings)
 	plan.Warnings = sortedArchiveWarnings(warnings)
 	return plan, nil
 }
@@ -222,6 +257,7 @@ func (s *ProjectArchiveService) Queue(
 		ExposureHostnames:  []string{},
 		IngressRules:       []ProjectArchiveIngressDeleteTarget{},
 		DNSRecords:         []ProjectArchiveDNSDeleteTarget{},
+		AccessApps:         []ProjectArchiveAccessDeleteTarget{},
 	}
 
 	if options.RemoveContainers {
@@ -256,8 +292,10 @@ func (s *ProjectArchiveService) Queue(
 			}
 			targets.IngressRules = append(targets.IngressRules, ProjectArchiveIngressDeleteTarget{
 				Hostname: hostname,
+				Path:     strings.TrimSpace(ingress.Path),
 				Service:  strings.TrimSpace(ingress.Service),
 				Source:   strings.ToLower(strings.TrimSpace(ingress.Source)),
+				Origin:   ingress.Origin,
 			})
 		}
 	}
@@ -275,6 +313,19 @@ func (s *ProjectArchiveService) Queue(
 		}
 	}
 
+	if options.RemoveAccess {
+		for _, app := range plan.AccessApps {
+			if !app.DeleteEligible {
+				continue
+			}
+			targets.AccessApps = append(targets.AccessApps, ProjectArchiveAccessDeleteTarget{
+				AppID:    app.ID,
+				Hostname: app.Hostname,
+				Rules:    app.Rules,
+			})
+		}
+	}
+
 	payload := ProjectArchiveJobRequest{
 		Project:     plan.Project.NormalizedName,
 		Options:     options,
@@ -305,9 +356,34 @@ func (s *ProjectArchiveService) resolveRuntimeConfig(ctx context.Context) (confi
 	return s.settings.ResolveConfig(ctx)
 }
 
+// resolveProjectRuntimeConfig is resolveRuntimeConfig with the Cloudflare
+// settings of the tunnel serving projectName.
+func (s *ProjectArchiveService) resolveProjectRuntimeConfig(ctx context.Context, projectName string) (config.Config, error) {
+	runtimeCfg, err := s.resolveRuntimeConfig(ctx)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return s.tunnels.ProjectConfig(ctx, runtimeCfg, projectName)
+}
+
+// selectDomain resolves requested for a hostname on the tunnel cfg describes.
+func (s *ProjectArchiveService) selectDomain(ctx context.Context, cfg config.Config, requested string) (DomainSelection, error) {
+	if s.tunnels == nil {
+		return selectProjectDomain(ctx, s.settings, cfg, requested)
+	}
+	base, err := s.resolveRuntimeConfig(ctx)
+	if err != nil {
+		return DomainSelection{}, err
+	}
+	return selectTunnelDomain(ctx, s.settings, base, cfg, requested)
+}
+
+// planContainers lists the project's containers, including the blue/green
+// slot compose projects of composeProject that serve it after a switch.
 func (s *ProjectArchiveService) planContainers(
 	ctx context.Context,
 	project string,
+	composeProject string,
 	exposureContainers []string,
 	warnings map[string]struct{},
 ) []ProjectArchivePlanContainer {
@@ -340,7 +416,9 @@ func (s *ProjectArchiveService) planContainers(
 		}
 
 		_, exposureMatch := exposureSet[containerName]
-		projectMatch := strings.EqualFold(strings.TrimSpace(container.Project), project)
+		projectMatch := strings.EqualFold(strings.TrimSpace(container.Project), project) ||
+			blueGreenSlotOf(container.Project, project) != "" ||
+			blueGreenSlotOf(container.Project, composeProject) != ""
 		if !projectMatch && !exposureMatch {
 			continue
 		}
@@ -396,6 +474,7 @@ func (s *ProjectArchiveService) discoverHostnames(
 			}
 		}
 	}
+	s.addHostnamesFromDeployments(ctx, candidates, project, warnings)
 
 	if baseDomain != "" {
 		fallback := fmt.Sprintf("%s.%s", project, baseDomain)
@@ -889,8 +968,10 @@ func (s *ProjectArchiveService) planIngress(
 			}
 			result = append(result, ProjectArchivePlanIngress{
 				Hostname: hostname,
+				Path:     rule.Path,
 				Service:  rule.Service,
 				Source:   "local",
+				Origin:   rule.Origin,
 			})
 		}
 	}
@@ -906,15 +987,20 @@ func (s *ProjectArchiveService) planIngress(
 			}
 			result = append(result, ProjectArchivePlanIngress{
 				Hostname: hostname,
+				Path:     rule.Path,
 				Service:  rule.Service,
 				Source:   "remote",
+				Origin:   rule.Origin,
 			})
 		}
 	}
 
 	sort.Slice(result, func(i, j int) bool {
 		if result[i].Hostname == result[j].Hostname {
-			return result[i].Source < result[j].Source
+			if result[i].Path == result[j].Path {
+				return result[i].Source < result[j].Source
+			}
+			return result[i].Path < result[j].Path
 		}
 		return result[i].Hostname < result[j].Hostname
 	})
@@ -1009,6 +1095,44 @@ func (s *ProjectArchiveService) planDNSRecords(
 	return result
 }
 
+// planAccessApps lists the Access applications guarding hostnames. Only the
+// ones gungnr created are eligible for deletion. Without Cloudflare account
+// credentials there is nothing to preview and no warning is raised.
+func planAccessApps(
+	ctx context.Context,
+	runtimeCfg config.Config,
+	cfClient projectAccessClient,
+	hostnames []string,
+	warnings map[string]struct{},
+) []ProjectArchivePlanAccessApp {
+	result := make([]ProjectArchivePlanAccessApp, 0)
+	if len(hostnames) == 0 || strings.TrimSpace(runtimeCfg.CloudflareAPIToken) == "" || strings.TrimSpace(runtimeCfg.CloudflareAccountID) == "" {
+		return result
+	}
+	for _, hostname := range hostnames {
+		app, err := cfClient.AccessApplicationForHostname(ctx, hostname)
+		if err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("failed to inspect Access application for %s: %v", hostname, err))
+			continue
+		}
+		if app == nil {
+			continue
+		}
+		planApp := ProjectArchivePlanAccessApp{
+			ID:             app.ID,
+			Hostname:       hostname,
+			Name:           app.Name,
+			Rules:          app.Rules,
+			DeleteEligible: app.Managed,
+		}
+		if !app.Managed {
+			planApp.SkipReason = "application is not managed by gungnr"
+		}
+		result = append(result, planApp)
+	}
+	return result
+}
+
 func addArchiveWarning(target map[string]struct{}, warning string) {
 	normalized := strings.TrimSpace(warning)
 	if normalized == "" {
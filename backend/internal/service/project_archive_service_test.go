@@ -10,6 +10,7 @@ import (
 
 	"go-notes/internal/config"
 	"go-notes/internal/infra/contract"
+	"go-notes/internal/integrations/cloudflare"
 	"go-notes/internal/models"
 	"go-notes/internal/repository"
 
@@ -301,6 +302,66 @@ func TestProjectArchiveQueueCapturesExactPlannedIngressRules(t *testing.T) {
 	}, payload.Targets.IngressRules)
 }
 
+func TestProjectArchiveQueueCapturesPathRulesOnDeploymentHostnames(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte("services: {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(configPath, []byte(
+		"ingress:\n"+
+			"  - hostname: app.example.com\n"+
+			"    path: ^/api(/|$)\n"+
+			"    service: http://localhost:8080\n"+
+			"  - hostname: app.example.com\n"+
+			"    service: http://localhost:3000\n"+
+			"  - hostname: other.example.com\n"+
+			"    service: http://localhost:7070\n"+
+			"  - service: http_status:404\n",
+	), 0o644))
+
+	jobRepo := &archiveTestJobRepo{}
+	service := NewProjectArchiveService(
+		config.Config{
+			TemplatesDir:      templatesDir,
+			Domain:            "example.com",
+			CloudflaredConfig: configPath,
+		},
+		&archiveTestProjectRepo{
+			projects: []models.Project{{
+				Model:  gorm.Model{ID: 4},
+				Name:   "demo",
+				Path:   projectDir,
+				Status: "running",
+			}},
+		},
+		nil,
+		NewJobService(jobRepo, nil),
+		nil,
+	)
+	service.SetDeploymentRepository(&stubDeploymentRepository{deployments: []models.Deployment{
+		{Model: gorm.Model{ID: 1}, ProjectID: 4, Subdomain: "app", Hostname: "app.example.com", Path: "/api", Port: 8080},
+		{Model: gorm.Model{ID: 2}, ProjectID: 4, Subdomain: "app", Hostname: "app.example.com", Port: 3000},
+	}})
+
+	job, _, err := service.Queue(context.Background(), "demo", DefaultProjectArchiveOptions(), ProjectArchiveActor{UserID: 7, Login: "tester"})
+	require.NoError(t, err)
+
+	var payload ProjectArchiveJobRequest
+	require.NoError(t, json.Unmarshal([]byte(job.Input), &payload))
+	require.ElementsMatch(t, []ProjectArchiveIngressDeleteTarget{
+		{Hostname: "app.example.com", Service: "http://localhost:3000", Source: "local"},
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080", Source: "local"},
+	}, payload.Targets.IngressRules)
+	require.Equal(t, []cloudflare.IngressRule{
+		{Hostname: "app.example.com", Service: "http://localhost:3000"},
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080"},
+	}, filterIngressDeleteTargetsBySource(normalizeIngressDeleteTargets(payload.Targets.IngressRules), "local"))
+}
+
 type archiveTestProjectRepo struct {
 	projects []models.Project
 }
@@ -334,6 +395,16 @@ func (r *archiveTestProjectRepo) Update(ctx context.Context, project *models.Pro
 	return repository.ErrNotFound
 }
 
+func (r *archiveTestProjectRepo) Delete(ctx context.Context, id uint) error {
+	for i := range r.projects {
+		if r.projects[i].ID == id {
+			r.projects = append(r.projects[:i], r.projects[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 type archiveTestJobRepo struct {
 	jobs   []models.Job
 	nextID uint
@@ -424,3 +495,29 @@ func (r *archiveTestJobRepo) AppendLog(ctx context.Context, id uint, line string
 	}
 	return repository.ErrNotFound
 }
+
+func TestProjectArchivePlanContainersIncludesBlueGreenSlots(t *testing.T) {
+	t.Parallel()
+
+	bridge := &stubHostInfraBridgeClient{listContainersResult: contract.Result{
+		Status: contract.StatusSucceeded,
+		Data: map[string]any{
+			"lines": []string{
+				`{"ID":"d1","Names":"demo-db-1","Status":"Up 3 hours","Labels":"com.docker.compose.project=demo,com.docker.compose.service=db"}`,
+				`{"ID":"g1","Names":"demo-green-web-1","Status":"Up 3 hours","Labels":"com.docker.compose.project=demo-green,com.docker.compose.service=web"}`,
+				`{"ID":"s1","Names":"shop-blue-web-1","Status":"Up 3 hours","Labels":"com.docker.compose.project=shop-blue,com.docker.compose.service=web"}`,
+				`{"ID":"o1","Names":"demo-api-web-1","Status":"Up 3 hours","Labels":"com.docker.compose.project=demo-api,com.docker.compose.service=web"}`,
+			},
+		},
+	}}
+	svc := &ProjectArchiveService{host: &HostService{infraClient: bridge}}
+
+	warnings := map[string]struct{}{}
+	containers := svc.planContainers(context.Background(), "demo", "shop", nil, warnings)
+	names := []string{}
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	require.Equal(t, []string{"demo-db-1", "demo-green-web-1", "shop-blue-web-1"}, names)
+	require.Empty(t, warnings)
+}
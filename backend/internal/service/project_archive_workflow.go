@@ -40,6 +40,7 @@ const (
 	projectArchiveStepStatusCompleted      projectArchiveStepStatus = "completed"
 	projectArchiveStepStatusPartialFailure projectArchiveStepStatus = "partial_failure"
 	projectArchiveStepStatusSkipped        projectArchiveStepStatus = "skipped"
+	projectArchiveStepStatusFailed         projectArchiveStepStatus = "failed"
 )
 
 func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.Job, logger jobs.Logger) error {
@@ -70,6 +71,11 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 		TargetHostnames:  len(exposureHostnames),
 	}
 
+	volumeBackupID, err := w.backupVolumesBeforeArchive(ctx, logger, job, req.Project, options)
+	if err != nil {
+		return err
+	}
+
 	projectContainerTargets := countStringsExcludingSet(targetContainers, exposureContainerSet)
 	removedContainers := 0
 	projectContainersRemoved := 0
@@ -147,7 +153,7 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 		removedContainers,
 	)
 
-	runtimeCfg, err := w.resolveArchiveRuntimeConfig(ctx)
+	runtimeCfg, err := w.resolveProjectRuntimeConfig(ctx, req.Project)
 	if err != nil {
 		return fmt.Errorf("resolve runtime config: %w", err)
 	}
@@ -217,7 +223,7 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 					exposureSummary.RemovedIngressLocal += countIngressRulesForHostnames(removedLocal, exposureHostnameSet)
 					logger.Logf("removed %d local ingress rules", localIngressRemoved)
 					if localIngressRemoved > 0 {
-						if restartErr := w.restartTunnelForArchive(ctx, logger, fmt.Sprintf("job-%d", job.ID), runtimeCfg.CloudflaredConfig); restartErr != nil {
+						if restartErr := w.restartTunnelForArchive(ctx, logger, fmt.Sprintf("job-%d", job.ID), runtimeCfg); restartErr != nil {
 							addArchiveWarning(warnings, fmt.Sprintf("cloudflared restart after ingress cleanup failed: %v", restartErr))
 							tunnelRestartFailed = true
 							ingressStepFailed = true
@@ -371,6 +377,11 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 		expectedTargetResolved,
 	)
 
+	accessTargets := dedupeAccessDeleteTargets(req.Targets.AccessApps)
+	accessStepStatus, accessSummary := removeArchiveAccessApps(ctx, logger, cloudflare.NewClient(runtimeCfg), options.RemoveAccess, accessTargets, warnings)
+
+	manifestStepStatus := w.writeArchiveManifest(ctx, logger, runtimeCfg, req, options, volumeBackupID, ingressTargets, dnsTargets, exposureHostnameSet, warnings)
+
 	statusPersisted := false
 	auditLogged := false
 	statusAuditStepFailed := false
@@ -401,7 +412,7 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 
 	if w.audit != nil {
 		completionSummary := map[string]any{
-			"outcome":      projectArchiveExecutionOutcome(containersStepStatus, ingressStepStatus, dnsStepStatus, statusAuditStepStatusForAudit, len(sortedWarnings)),
+			"outcome":      projectArchiveExecutionOutcome(containersStepStatus, ingressStepStatus, dnsStepStatus, accessStepStatus, statusAuditStepStatusForAudit, len(sortedWarnings)),
 			"warningCount": len(sortedWarnings),
 			"steps": map[string]any{
 				"containers": map[string]any{
@@ -443,6 +454,17 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 					"expectedTargetResolved": expectedTargetResolved,
 					"removeDns":              options.RemoveDNS,
 				},
+				"access": map[string]any{
+					"status":          string(accessStepStatus),
+					"targetAppCount":  accessSummary.Targets,
+					"removedAppCount": accessSummary.Removed,
+					"skippedAppCount": accessSummary.Skipped,
+					"failedAppCount":  accessSummary.Failed,
+					"removeAccess":    options.RemoveAccess,
+				},
+				"manifest": map[string]any{
+					"status": string(manifestStepStatus),
+				},
 				"statusAudit": map[string]any{
 					"status":          string(statusAuditStepStatusForAudit),
 					"statusPersisted": statusPersisted,
@@ -457,10 +479,14 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 			"removeVolumes":        options.RemoveVolumes,
 			"removeIngress":        options.RemoveIngress,
 			"removeDns":            options.RemoveDNS,
+			"removeAccess":         options.RemoveAccess,
+			"backupVolumes":        options.BackupVolumes,
+			"volumeBackupId":       volumeBackupID,
 			"removedContainers":    removedContainers,
 			"removedIngressRemote": remoteIngressRemoved,
 			"removedIngressLocal":  localIngressRemoved,
 			"removedDnsRecords":    dnsRemoved,
+			"removedAccessApps":    accessSummary.Removed,
 			"statusPersisted":      statusPersisted,
 			"serviceExposureCleanup": map[string]any{
 				"targetContainers":     exposureSummary.TargetContainers,
@@ -530,14 +556,15 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 	}
 
 	totalWarningCount := len(sortedWarnings) + auditWarningCount
-	overallOutcome := projectArchiveExecutionOutcome(containersStepStatus, ingressStepStatus, dnsStepStatus, statusAuditStepStatus, totalWarningCount)
+	overallOutcome := projectArchiveExecutionOutcome(containersStepStatus, ingressStepStatus, dnsStepStatus, accessStepStatus, statusAuditStepStatus, totalWarningCount)
 	logger.Logf(
-		"archive completion summary: outcome=%s warnings=%d steps=containers:%s ingress:%s dns:%s status_audit:%s",
+		"archive completion summary: outcome=%s warnings=%d steps=containers:%s ingress:%s dns:%s access:%s status_audit:%s",
 		overallOutcome,
 		totalWarningCount,
 		containersStepStatus,
 		ingressStepStatus,
 		dnsStepStatus,
+		accessStepStatus,
 		statusAuditStepStatus,
 	)
 	switch overallOutcome {
@@ -555,6 +582,39 @@ func (w *ProjectWorkflows) handleProjectArchive(ctx context.Context, job models.
 	return nil
 }
 
+// backupVolumesBeforeArchive runs the optional pre-archive volume backup. It
+// runs before any container is removed, and a failure aborts the archive so
+// removeVolumes never destroys data that was not saved.
+func (w *ProjectWorkflows) backupVolumesBeforeArchive(
+	ctx context.Context,
+	logger jobs.Logger,
+	job models.Job,
+	project string,
+	options ProjectArchiveOptions,
+) (string, error) {
+	logProjectArchiveStepStart(logger, "volume_backup", "backup_volumes=%t", options.BackupVolumes)
+	if !options.BackupVolumes {
+		logProjectArchiveStepResult(logger, "volume_backup", projectArchiveStepStatusSkipped, "reason=%q", "backupVolumes=false")
+		return "", nil
+	}
+	if w.host == nil {
+		err := fmt.Errorf("host service unavailable")
+		logProjectArchiveStepResult(logger, "volume_backup", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return "", fmt.Errorf("pre-archive volume backup failed; nothing was archived: %w", err)
+	}
+	backup, err := w.host.BackupProjectVolumesWithLogger(ctx, fmt.Sprintf("job-%d", job.ID), project, VolumeBackupReasonPreArchive, logger)
+	if err != nil {
+		logProjectArchiveStepResult(logger, "volume_backup", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return "", fmt.Errorf("pre-archive volume backup failed; nothing was archived: %w", err)
+	}
+	if backup == nil {
+		logProjectArchiveStepResult(logger, "volume_backup", projectArchiveStepStatusCompleted, "volumes=0")
+		return "", nil
+	}
+	logProjectArchiveStepResult(logger, "volume_backup", projectArchiveStepStatusCompleted, "backup_id=%s volumes=%d bytes=%d", backup.BackupID, len(backup.Volumes), backup.SizeBytes)
+	return backup.BackupID, nil
+}
+
 func (w *ProjectWorkflows) resolveArchiveRuntimeConfig(ctx context.Context) (config.Config, error) {
 	if w.settings == nil {
 		return w.cfg, nil
@@ -562,20 +622,23 @@ func (w *ProjectWorkflows) resolveArchiveRuntimeConfig(ctx context.Context) (con
 	return w.settings.ResolveConfig(ctx)
 }
 
+// restartTunnelForArchive restarts the locally managed tunnel cfg points at
+// so it picks up a rewritten config file.
 func (w *ProjectWorkflows) restartTunnelForArchive(
 	ctx context.Context,
 	logger jobs.Logger,
 	requestID string,
-	configPath string,
+	cfg config.Config,
 ) error {
-	if strings.TrimSpace(configPath) == "" {
+	configPath := strings.TrimSpace(cfg.CloudflaredConfig)
+	if configPath == "" {
 		return fmt.Errorf("cloudflared config path is empty")
 	}
 	if w.infraClient == nil {
 		return fmt.Errorf("infra bridge client unavailable")
 	}
 
-	result, err := w.infraClient.RestartTunnel(ctx, strings.TrimSpace(requestID), configPath)
+	result, err := w.infraClient.RestartTunnel(ctx, strings.TrimSpace(requestID), configPath, cfg.CloudflaredMetrics)
 	if err != nil {
 		return err
 	}
@@ -651,12 +714,16 @@ func normalizeIngressDeleteTargets(values []ProjectArchiveIngressDeleteTarget) [
 			continue
 		}
 		entry.Hostname = hostname
+		entry.Path = strings.TrimSpace(entry.Path)
 		entry.Service = service
 		entry.Source = source
 		result = append(result, entry)
 	}
 	sort.Slice(result, func(i, j int) bool {
 		if result[i].Hostname == result[j].Hostname {
+			if result[i].Path != result[j].Path {
+				return result[i].Path < result[j].Path
+			}
 			if result[i].Service == result[j].Service {
 				return result[i].Source < result[j].Source
 			}
@@ -683,6 +750,7 @@ func filterIngressDeleteTargetsBySource(values []ProjectArchiveIngressDeleteTarg
 		}
 		result = append(result, cloudflare.IngressRule{
 			Hostname: hostname,
+			Path:     strings.TrimSpace(entry.Path),
 			Service:  strings.TrimSpace(entry.Service),
 		})
 	}
@@ -774,10 +842,11 @@ func projectArchiveExecutionOutcome(
 	containers projectArchiveStepStatus,
 	ingress projectArchiveStepStatus,
 	dns projectArchiveStepStatus,
+	access projectArchiveStepStatus,
 	statusAudit projectArchiveStepStatus,
 	warningCount int,
 ) string {
-	for _, status := range []projectArchiveStepStatus{containers, ingress, dns, statusAudit} {
+	for _, status := range []projectArchiveStepStatus{containers, ingress, dns, access, statusAudit} {
 		if status == projectArchiveStepStatusPartialFailure {
 			return "partial_failure"
 		}
@@ -788,26 +857,110 @@ func projectArchiveExecutionOutcome(
 	return "completed"
 }
 
+type projectArchiveAccessSummary struct {
+	Targets int
+	Removed int
+	Skipped int
+	Failed  int
+}
+
+// removeArchiveAccessApps is the access step of the archive job. Each target
+// is re-checked by the client, so an application that changed hands since
+// the plan was built is skipped rather than deleted.
+func removeArchiveAccessApps(
+	ctx context.Context,
+	logger jobs.Logger,
+	client projectAccessClient,
+	removeAccess bool,
+	targets []ProjectArchiveAccessDeleteTarget,
+	warnings map[string]struct{},
+) (projectArchiveStepStatus, projectArchiveAccessSummary) {
+	summary := projectArchiveAccessSummary{Targets: len(targets)}
+	logProjectArchiveStepStart(logger, "access", "remove_access=%t target_apps=%d", removeAccess, len(targets))
+	status := projectArchiveStepStatusCompleted
+	switch {
+	case !removeAccess:
+		status = projectArchiveStepStatusSkipped
+		summary.Skipped = len(targets)
+	default:
+		for _, target := range targets {
+			result, err := client.DeleteAccessApplication(ctx, target.AppID, target.Hostname)
+			if err != nil {
+				addArchiveWarning(warnings, fmt.Sprintf("delete Access application %s for %s failed: %v", target.AppID, target.Hostname, err))
+				summary.Failed++
+				status = projectArchiveStepStatusPartialFailure
+				continue
+			}
+			if !result.Deleted {
+				addArchiveWarning(warnings, fmt.Sprintf("skip Access application %s because %s", target.AppID, result.SkipReason))
+				summary.Skipped++
+				continue
+			}
+			logger.Logf("deleted Cloudflare Access application %s for %s", target.AppID, target.Hostname)
+			summary.Removed++
+		}
+	}
+	logProjectArchiveStepResult(
+		logger,
+		"access",
+		status,
+		"target_apps=%d removed=%d skipped=%d failed=%d",
+		summary.Targets,
+		summary.Removed,
+		summary.Skipped,
+		summary.Failed,
+	)
+	return status, summary
+}
+
+func dedupeAccessDeleteTargets(targets []ProjectArchiveAccessDeleteTarget) []ProjectArchiveAccessDeleteTarget {
+	seen := make(map[string]struct{}, len(targets))
+	result := make([]ProjectArchiveAccessDeleteTarget, 0, len(targets))
+	for _, target := range targets {
+		target.AppID = strings.TrimSpace(target.AppID)
+		target.Hostname = strings.ToLower(strings.TrimSpace(target.Hostname))
+		if target.AppID == "" {
+			continue
+		}
+		if _, ok := seen[target.AppID]; ok {
+			continue
+		}
+		seen[target.AppID] = struct{}{}
+		result = append(result, target)
+	}
+	return result
+}
+
 func logProjectArchiveStepStart(logger jobs.Logger, step string, format string, args ...any) {
+	logProjectStepStart(logger, "archive", step, format, args...)
+}
+
+func logProjectArchiveStepResult(logger jobs.Logger, step string, status projectArchiveStepStatus, format string, args ...any) {
+	logProjectStepResult(logger, "archive", step, status, format, args...)
+}
+
+// logProjectStepStart and logProjectStepResult write the "<workflow> step
+// <name>: ..." lines shared by the multi-step project workflows.
+func logProjectStepStart(logger jobs.Logger, workflow, step string, format string, args ...any) {
 	if logger == nil {
 		return
 	}
 	message := strings.TrimSpace(fmt.Sprintf(format, args...))
 	if message == "" {
-		logger.Logf("archive step %s: start", step)
+		logger.Logf("%s step %s: start", workflow, step)
 		return
 	}
-	logger.Logf("archive step %s: start %s", step, message)
+	logger.Logf("%s step %s: start %s", workflow, step, message)
 }
 
-func logProjectArchiveStepResult(logger jobs.Logger, step string, status projectArchiveStepStatus, format string, args ...any) {
+func logProjectStepResult(logger jobs.Logger, workflow, step string, status projectArchiveStepStatus, format string, args ...any) {
 	if logger == nil {
 		return
 	}
 	message := strings.TrimSpace(fmt.Sprintf(format, args...))
 	if message == "" {
-		logger.Logf("archive step %s: result=%s", step, status)
+		logger.Logf("%s step %s: result=%s", workflow, step, status)
 		return
 	}
-	logger.Logf("archive step %s: result=%s %s", step, status, message)
+	logger.Logf("%s step %s: result=%s %s", workflow, step, status, message)
 }
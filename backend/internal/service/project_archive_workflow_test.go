@@ -143,7 +143,7 @@ func TestHandleProjectArchiveLogsPartialFailureCompletionSummary(t *testing.T) {
 	requireArchiveLogContains(t, logger.lines, "archive step ingress: result=skipped")
 	requireArchiveLogContains(t, logger.lines, "archive step dns: result=skipped")
 	requireArchiveLogContains(t, logger.lines, "archive step status_audit: result=completed")
-	requireArchiveLogContains(t, logger.lines, "archive completion summary: outcome=partial_failure warnings=1 steps=containers:partial_failure ingress:skipped dns:skipped status_audit:completed")
+	requireArchiveLogContains(t, logger.lines, "archive completion summary: outcome=partial_failure warnings=1 steps=containers:partial_failure ingress:skipped dns:skipped access:skipped status_audit:completed")
 	requireArchiveLogContains(t, logger.lines, "archive completed with partial failures for project demo (warning_count=1)")
 	requireArchiveLogContains(t, logger.lines, "warning: host service unavailable while removing project containers")
 }
@@ -262,7 +262,7 @@ func TestHandleProjectArchiveCountsAuditWarningInCompletionSummary(t *testing.T)
 
 	requireArchiveLogContains(t, logger.lines, "audit warning: failed to write archive completion event: audit unavailable")
 	requireArchiveLogContains(t, logger.lines, "archive step status_audit: result=partial_failure")
-	requireArchiveLogContains(t, logger.lines, "archive completion summary: outcome=partial_failure warnings=1 steps=containers:skipped ingress:skipped dns:skipped status_audit:partial_failure")
+	requireArchiveLogContains(t, logger.lines, "archive completion summary: outcome=partial_failure warnings=1 steps=containers:skipped ingress:skipped dns:skipped access:skipped status_audit:partial_failure")
 	requireArchiveLogContains(t, logger.lines, "archive completed with partial failures for project demo (warning_count=1)")
 }
 
@@ -317,7 +317,7 @@ func (c *archiveTestProjectInfraClient) DockerPublishedPorts(ctx context.Context
 	return contract.Result{}, fmt.Errorf("not implemented")
 }
 
-func (c *archiveTestProjectInfraClient) RestartTunnel(ctx context.Context, requestID, configPath string) (contract.Result, error) {
+func (c *archiveTestProjectInfraClient) RestartTunnel(ctx context.Context, requestID, configPath, metricsAddress string) (contract.Result, error) {
 	return contract.Result{
 		IntentID: "intent-restart-tunnel",
 		Status:   contract.StatusSucceeded,
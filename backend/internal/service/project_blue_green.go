@@ -0,0 +1,533 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-notes/internal/config"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+	"go-notes/internal/validate"
+)
+
+// BlueGreenDeployRequest is the job input for a blue/green redeploy.
+type BlueGreenDeployRequest struct {
+	Project              string `json:"project"`
+	HealthTimeoutSeconds int    `json:"healthTimeoutSeconds,omitempty"`
+}
+
+type blueGreenIngressClient interface {
+	cloudflareWorkflowClient
+	ListIngressRules(ctx context.Context) ([]cloudflare.IngressRule, error)
+}
+
+func (w *ProjectWorkflows) handleBlueGreenDeploy(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req BlueGreenDeployRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse blue/green deploy request: %w", err)
+	}
+	req.Project = strings.ToLower(strings.TrimSpace(req.Project))
+	if err := validate.ProjectName(req.Project); err != nil {
+		return err
+	}
+	if w.settings == nil {
+		return fmt.Errorf("settings not configured")
+	}
+	runtimeCfg, err := w.resolveProjectRuntimeConfig(ctx, req.Project)
+	if err != nil {
+		return fmt.Errorf("load settings: %w", err)
+	}
+	return w.runBlueGreenDeploy(ctx, logger, runtimeCfg, cloudflare.NewClient(runtimeCfg), fmt.Sprintf("job-%d", job.ID), req)
+}
+
+// runBlueGreenDeploy starts the project's non-data services in the idle slot on
+// a fresh port, waits for them to become healthy, moves every ingress rule
+// that pointed at the old proxy port over to the new one, and only then
+// removes the previous slot. Any failure before the switch completes removes
+// the new slot and leaves ingress on the old port.
+func (w *ProjectWorkflows) runBlueGreenDeploy(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl blueGreenIngressClient,
+	requestID string,
+	req BlueGreenDeployRequest,
+) error {
+	if w.workbench == nil {
+		return fmt.Errorf("workbench service unavailable")
+	}
+	if w.host == nil {
+		return fmt.Errorf("host service unavailable")
+	}
+
+	plan, err := w.workbench.PlanBlueGreenDeploy(ctx, req.Project)
+	if err != nil {
+		return err
+	}
+	logger.Logf("proxy service %q on port %d; slot services: %s", plan.ProxyService, plan.ProxyPort, strings.Join(plan.Services, ", "))
+	if len(plan.Pinned) > 0 {
+		logger.Logf("data services stay in compose project %q: %s", plan.ComposeProject, strings.Join(plan.Pinned, ", "))
+	}
+
+	containers, err := w.host.ListContainers(ctx, true)
+	if err != nil {
+		return fmt.Errorf("list containers: %w", err)
+	}
+	active := blueGreenActiveSlot(containers, plan.ComposeProject, plan.ProxyPort, plan.SlotPorts)
+	candidate := blueGreenCandidateSlot(active)
+	if active == "" {
+		logger.Logf("live stack is compose project %q; deploying slot %q", plan.ComposeProject, candidate)
+	} else {
+		logger.Logf("live slot is %q; deploying slot %q", active, candidate)
+	}
+
+	reserved := map[int]bool{plan.ProxyPort: true}
+	addDockerReservedPorts(ctx, w.infraClient, reserved)
+	addHostReservedPorts(ctx, w.infraClient, reserved)
+	candidatePort, err := blueGreenCandidatePort(plan, candidate, reserved)
+	if err != nil {
+		return err
+	}
+
+	slot, err := w.workbench.WriteBlueGreenSlotCompose(ctx, req.Project, candidate, candidatePort)
+	if err != nil {
+		return err
+	}
+	logger.Logf("starting compose project %q from %s on port %d", slot.SlotProject, slot.ComposeFile, slot.Port)
+
+	opts := ProjectServicesRestartOptions{HealthTimeout: time.Duration(req.HealthTimeoutSeconds) * time.Second}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = defaultServiceHealthTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultServiceHealthPollInterval
+	}
+	abandon := func(cause error) error {
+		logger.Logf("blue/green deploy failed: %v; removing slot %q", cause, slot.Slot)
+		if err := w.host.RemoveBlueGreenSlot(ctx, requestID, req.Project, slot.ProjectDir, slot.SlotProject, slot.ComposeFile, logger); err != nil {
+			logger.Logf("failed to remove slot %q: %v", slot.Slot, err)
+		}
+		return fmt.Errorf("blue/green deploy rolled back: %w", cause)
+	}
+
+	if err := w.host.StartBlueGreenSlot(ctx, requestID, slot, logger); err != nil {
+		return abandon(err)
+	}
+	logger.Logf("waiting up to %s for %s to become healthy", opts.HealthTimeout, strings.Join(slot.Services, ", "))
+	if err := w.host.waitForServicesHealthy(ctx, slot.SlotProject, slot.Services, opts, logger); err != nil {
+		return abandon(err)
+	}
+
+	routes := blueGreenIngressRoutes(ctx, logger, cfg, cloudfl, plan.ProxyPort)
+	if len(routes) == 0 {
+		return abandon(fmt.Errorf("no ingress rule points at port %d", plan.ProxyPort))
+	}
+	switched := make([]cloudflare.IngressRule, 0, len(routes))
+	for _, route := range routes {
+		logger.Logf("switching ingress for %s to port %d", describeIngressRoute(route), slot.Port)
+		if err := w.updateTunnelIngressRoute(ctx, logger, cfg, cloudfl, requestID, route.Hostname, route.Path, slot.Port); err != nil {
+			w.revertBlueGreenIngress(ctx, logger, cfg, cloudfl, requestID, switched, plan.ProxyPort)
+			return abandon(err)
+		}
+		switched = append(switched, route)
+	}
+
+	if err := w.updateProjectProxyPort(ctx, req.Project, slot.Port); err != nil {
+		w.revertBlueGreenIngress(ctx, logger, cfg, cloudfl, requestID, switched, plan.ProxyPort)
+		return abandon(err)
+	}
+	logger.Logf("ingress switched to slot %q on port %d", slot.Slot, slot.Port)
+
+	// Traffic has moved; a failed teardown leaves stale containers but does
+	// not undo the deploy.
+	if active == "" {
+		logger.Logf("removing %s from compose project %q", strings.Join(plan.Services, ", "), plan.ComposeProject)
+		if err := w.host.RemoveProjectServices(ctx, requestID, req.Project, slot.ProjectDir, plan.Services, logger); err != nil {
+			logger.Logf("warning: failed to remove previous services: %v", err)
+		}
+	} else {
+		previous := WorkbenchBlueGreenSlotProject(plan.ComposeProject, active)
+		logger.Logf("removing previous slot %q", previous)
+		if err := w.host.RemoveBlueGreenSlot(ctx, requestID, req.Project, slot.ProjectDir, previous, WorkbenchBlueGreenSlotComposeFile(active), logger); err != nil {
+			logger.Logf("warning: failed to remove previous slot: %v", err)
+		}
+	}
+	return nil
+}
+
+func (w *ProjectWorkflows) revertBlueGreenIngress(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl blueGreenIngressClient,
+	requestID string,
+	routes []cloudflare.IngressRule,
+	port int,
+) {
+	for _, route := range routes {
+		logger.Logf("restoring ingress for %s to port %d", describeIngressRoute(route), port)
+		if err := w.updateTunnelIngressRoute(ctx, logger, cfg, cloudfl, requestID, route.Hostname, route.Path, port); err != nil {
+			logger.Logf("failed to restore ingress for %s: %v", describeIngressRoute(route), err)
+		}
+	}
+}
+
+func (w *ProjectWorkflows) updateProjectProxyPort(ctx context.Context, project string, port int) error {
+	if w.projects == nil {
+		return fmt.Errorf("project repository unavailable")
+	}
+	record, err := w.projects.GetByName(ctx, project)
+	if err != nil {
+		return fmt.Errorf("load project record: %w", err)
+	}
+	record.ProxyPort = port
+	if err := w.projects.Update(ctx, record); err != nil {
+		return fmt.Errorf("update project proxy port: %w", err)
+	}
+	return nil
+}
+
+// StartBlueGreenSlot builds and starts a slot compose project.
+func (s *HostService) StartBlueGreenSlot(ctx context.Context, requestID string, slot WorkbenchBlueGreenSlot, logger jobs.Logger) error {
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	result, err := s.infraClient.ComposeUpStack(ctx, requestID, contract.ComposeUpStackPayload{
+		Project:       slot.ProjectName,
+		ProjectDir:    slot.ProjectDir,
+		ConfigFiles:   []string{slot.ComposeFile},
+		ProjectName:   slot.SlotProject,
+		Build:         true,
+		ForceRecreate: true,
+	})
+	if err != nil {
+		hostLogf(logger, "infra bridge compose_up_stack error: %v", err)
+		return bridgeTaskError("start blue/green slot failed", contract.TaskTypeComposeUpStack, slot.SlotProject, err)
+	}
+	if err := bridgeResultError("start blue/green slot failed", contract.TaskTypeComposeUpStack, slot.SlotProject, result); err != nil {
+		hostLogf(logger, "infra bridge compose_up_stack failed result: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveBlueGreenSlot takes a slot compose project down. Volumes are kept:
+// they belong to the project's own compose project.
+func (s *HostService) RemoveBlueGreenSlot(ctx context.Context, requestID, project, projectDir, slotProject, composeFile string, logger jobs.Logger) error {
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	result, err := s.infraClient.ComposeDownStack(ctx, requestID, contract.ComposeDownStackPayload{
+		Project:     project,
+		ProjectDir:  projectDir,
+		ConfigFiles: []string{composeFile},
+		ProjectName: slotProject,
+	})
+	if err != nil {
+		hostLogf(logger, "infra bridge compose_down_stack error: %v", err)
+		return bridgeTaskError("remove blue/green slot failed", contract.TaskTypeComposeDownStack, slotProject, err)
+	}
+	return bridgeResultError("remove blue/green slot failed", contract.TaskTypeComposeDownStack, slotProject, result)
+}
+
+// RemoveProjectServices stops and removes services from the project's own
+// compose project, leaving the rest of it running.
+func (s *HostService) RemoveProjectServices(ctx context.Context, requestID, project, projectDir string, services []string, logger jobs.Logger) error {
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	if len(services) == 0 {
+		return nil
+	}
+	result, err := s.infraClient.ComposeDownStack(ctx, requestID, contract.ComposeDownStackPayload{
+		Project:    project,
+		ProjectDir: projectDir,
+		Services:   services,
+	})
+	if err != nil {
+		hostLogf(logger, "infra bridge compose_down_stack error: %v", err)
+		return bridgeTaskError("remove project services failed", contract.TaskTypeComposeDownStack, project, err)
+	}
+	return bridgeResultError("remove project services failed", contract.TaskTypeComposeDownStack, project, result)
+}
+
+// blueGreenActiveSlot reports which slot currently publishes the proxy port:
+// "blue", "green", or "" when the project's own compose project still does.
+// When no container of the project publishes it (the stack is stopped or
+// archived), the slot whose compose file publishes it in slotPorts is live.
+func blueGreenActiveSlot(containers []DockerContainer, composeProject string, proxyPort int, slotPorts map[string]int) string {
+	owned := false
+	for _, container := range containers {
+		publishesProxy := false
+		for _, binding := range container.PortBindings {
+			if binding.Published && binding.HostPort == proxyPort {
+				publishesProxy = true
+				break
+			}
+		}
+		if !publishesProxy {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(container.Project), composeProject) {
+			owned = true
+			continue
+		}
+		if slot := blueGreenSlotOf(container.Project, composeProject); slot != "" {
+			return slot
+		}
+	}
+	if owned || proxyPort <= 0 {
+		return ""
+	}
+	for _, slot := range []string{WorkbenchBlueGreenSlotBlue, WorkbenchBlueGreenSlotGreen} {
+		if slotPorts[slot] == proxyPort {
+			return slot
+		}
+	}
+	return ""
+}
+
+// blueGreenSlotOf returns the slot containerProject runs for composeProject,
+// or "" when it is not one of its slot compose projects.
+func blueGreenSlotOf(containerProject, composeProject string) string {
+	containerProject = strings.TrimSpace(containerProject)
+	for _, slot := range []string{WorkbenchBlueGreenSlotBlue, WorkbenchBlueGreenSlotGreen} {
+		if strings.EqualFold(containerProject, WorkbenchBlueGreenSlotProject(composeProject, slot)) {
+			return slot
+		}
+	}
+	return ""
+}
+
+func blueGreenCandidateSlot(active string) string {
+	if active == WorkbenchBlueGreenSlotBlue {
+		return WorkbenchBlueGreenSlotGreen
+	}
+	return WorkbenchBlueGreenSlotBlue
+}
+
+// blueGreenCandidatePort picks the candidate slot's port. The stack that
+// stopped serving at the previous switch freed a port: the candidate slot's
+// own, or the project compose file's when the candidate has not run yet.
+// Reusing it keeps a project alternating between two ports; the search only
+// moves on when that port is taken.
+func blueGreenCandidatePort(plan WorkbenchBlueGreenPlan, candidate string, reserved map[int]bool) (int, error) {
+	freed := plan.SlotPorts[candidate]
+	if freed <= 0 {
+		freed = plan.ComposePort
+	}
+	if freed > 0 && freed != plan.ProxyPort && !reserved[freed] {
+		if port, err := findFreePortNearby(freed, reserved); err == nil && port == freed {
+			return port, nil
+		}
+	}
+	return findFreePortNearby(plan.ProxyPort+1, reserved)
+}
+
+// ProjectBlueGreenSlot reports the slot serving proxyPort for the project in
+// projectDir, or "" when the project's own compose project serves it. Projects
+// that never ran a blue/green deploy have no slot files and are answered
+// without listing containers.
+func (s *HostService) ProjectBlueGreenSlot(ctx context.Context, projectDir string, proxyPort int) (string, error) {
+	slotPorts := workbenchBlueGreenSlotHostPorts(projectDir)
+	if len(slotPorts) == 0 || proxyPort <= 0 {
+		return "", nil
+	}
+	containers, err := s.ListContainers(ctx, true)
+	if err != nil {
+		return "", fmt.Errorf("list containers: %w", err)
+	}
+	return blueGreenActiveSlot(containers, projectComposeProjectName(projectDir), proxyPort, slotPorts), nil
+}
+
+// projectLiveSlot resolves the live blue/green slot of a project from its
+// record. Projects without a record or directory have none.
+func (s *HostService) projectLiveSlot(ctx context.Context, project string) (string, string, error) {
+	if s.projects == nil {
+		return "", "", nil
+	}
+	record, err := s.projects.GetByName(ctx, project)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("load project record: %w", err)
+	}
+	projectDir, err := normalizeProjectPath(s.templatesDir, record.Path)
+	if err != nil {
+		return "", "", nil
+	}
+	slot, err := s.ProjectBlueGreenSlot(ctx, projectDir, record.ProxyPort)
+	if err != nil {
+		return "", "", err
+	}
+	return slot, projectDir, nil
+}
+
+// StartBlueGreenProject brings up a project whose proxy port is served by
+// slot: the services the slot compose file does not run start in the
+// project's own compose project, the rest in the slot compose project. The
+// project compose file still declares the slot's services, so a plain
+// compose up would start them a second time on the old port.
+func (s *HostService) StartBlueGreenProject(ctx context.Context, requestID, project, projectDir, slot string, forceRecreate bool, logger jobs.Logger) error {
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	pinned, err := workbenchBlueGreenPinnedServices(projectDir, slot)
+	if err != nil {
+		return err
+	}
+	if len(pinned) > 0 {
+		hostLogf(logger, "starting %s in compose project %q", strings.Join(pinned, ", "), project)
+		result, err := s.infraClient.ComposeUpStack(ctx, requestID, contract.ComposeUpStackPayload{
+			Project:       project,
+			ProjectDir:    projectDir,
+			Build:         true,
+			ForceRecreate: forceRecreate,
+			Services:      pinned,
+		})
+		if err != nil {
+			hostLogf(logger, "infra bridge compose_up_stack error: %v", err)
+			return bridgeTaskError("start pinned services failed", contract.TaskTypeComposeUpStack, project, err)
+		}
+		if err := bridgeResultError("start pinned services failed", contract.TaskTypeComposeUpStack, project, result); err != nil {
+			hostLogf(logger, "infra bridge compose_up_stack failed result: %v", err)
+			return err
+		}
+	}
+
+	slotProject := WorkbenchBlueGreenSlotProject(projectComposeProjectName(projectDir), slot)
+	composeFile := WorkbenchBlueGreenSlotComposeFile(slot)
+	hostLogf(logger, "starting slot %q from %s", slotProject, composeFile)
+	result, err := s.infraClient.ComposeUpStack(ctx, requestID, contract.ComposeUpStackPayload{
+		Project:       project,
+		ProjectDir:    projectDir,
+		ConfigFiles:   []string{composeFile},
+		ProjectName:   slotProject,
+		Build:         true,
+		ForceRecreate: forceRecreate,
+	})
+	if err != nil {
+		hostLogf(logger, "infra bridge compose_up_stack error: %v", err)
+		return bridgeTaskError("start blue/green slot failed", contract.TaskTypeComposeUpStack, slotProject, err)
+	}
+	if err := bridgeResultError("start blue/green slot failed", contract.TaskTypeComposeUpStack, slotProject, result); err != nil {
+		hostLogf(logger, "infra bridge compose_up_stack failed result: %v", err)
+		return err
+	}
+	return nil
+}
+
+// composeUpProject starts the project in projectDir as it last ran: a plain
+// compose up, or its pinned services and live slot when a blue/green slot
+// serves proxyPort. Callers that just changed the project compose file pass
+// renderSlot to rewrite the slot compose file from it first.
+func (w *ProjectWorkflows) composeUpProject(ctx context.Context, logger jobs.Logger, requestID, project, projectDir string, proxyPort int, renderSlot func(slot string) error) error {
+	if w.host == nil {
+		return w.runCompose(ctx, logger, projectDir)
+	}
+	slot, err := w.host.ProjectBlueGreenSlot(ctx, projectDir, proxyPort)
+	if err != nil {
+		return err
+	}
+	if slot == "" {
+		return w.runCompose(ctx, logger, projectDir)
+	}
+	logger.Logf("port %d is served by blue/green slot %q; starting the slot instead of the project's own copies of its services", proxyPort, slot)
+	if renderSlot != nil {
+		if err := renderSlot(slot); err != nil {
+			return w.workbenchJobError(logger, "blue/green slot render", err)
+		}
+	}
+	if err := w.injectProjectSecrets(ctx, logger, projectDir); err != nil {
+		return err
+	}
+	return w.host.StartBlueGreenProject(ctx, requestID, project, projectDir, slot, false, logger)
+}
+
+// blueGreenIngressRoutes lists the hostname and path pairs whose local or
+// remote ingress rule targets localhost on port. Service is left empty.
+func blueGreenIngressRoutes(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl blueGreenIngressClient,
+	port int,
+) []cloudflare.IngressRule {
+	rules := []cloudflare.IngressRule{}
+	if localRules, err := cloudflare.ListLocalIngressRules(cfg.CloudflaredConfig); err != nil {
+		logger.Logf("local ingress rules unavailable: %v", err)
+	} else {
+		rules = append(rules, localRules...)
+	}
+	if cloudfl != nil {
+		remoteRules, err := cloudfl.ListIngressRules(ctx)
+		switch {
+		case err == nil:
+			rules = append(rules, remoteRules...)
+		case errors.Is(err, cloudflare.ErrTunnelNotRemote):
+		default:
+			logger.Logf("remote ingress rules unavailable: %v", err)
+		}
+	}
+
+	seen := map[string]struct{}{}
+	routes := []cloudflare.IngressRule{}
+	for _, rule := range rules {
+		hostname := strings.ToLower(strings.TrimSpace(rule.Hostname))
+		if hostname == "" {
+			continue
+		}
+		if rulePort, ok := ingressServiceLocalPort(rule.Service); !ok || rulePort != port {
+			continue
+		}
+		route := cloudflare.IngressRule{Hostname: hostname, Path: strings.TrimSpace(rule.Path)}
+		key := route.Hostname + "\x00" + route.Path
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Hostname == routes[j].Hostname {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Hostname < routes[j].Hostname
+	})
+	return routes
+}
+
+func describeIngressRoute(rule cloudflare.IngressRule) string {
+	if rule.Path == "" {
+		return rule.Hostname
+	}
+	return fmt.Sprintf("%s (path %s)", rule.Hostname, rule.Path)
+}
+
+func ingressServiceLocalPort(service string) (int, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(service))
+	if err != nil || parsed.Host == "" {
+		return 0, false
+	}
+	switch strings.ToLower(parsed.Hostname()) {
+	case "localhost", "127.0.0.1", "::1", "0.0.0.0":
+	default:
+		return 0, false
+	}
+	port, err := strconv.Atoi(parsed.Port())
+	if err != nil {
+		return 0, false
+	}
+	return port, true
+}
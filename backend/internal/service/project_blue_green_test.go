@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/integrations/cloudflare"
+)
+
+type stubBlueGreenIngressClient struct {
+	rules   []cloudflare.IngressRule
+	updates []cloudflare.IngressRule
+}
+
+func (s *stubBlueGreenIngressClient) EnsureDNSForZone(_ context.Context, _ string, _ string) error {
+	return nil
+}
+
+func (s *stubBlueGreenIngressClient) UpdateIngressRouteOptions(_ context.Context, hostname, path string, port int, _ cloudflare.IngressRouteOptions) error {
+	service := "http://localhost:" + strconv.Itoa(port)
+	s.updates = append(s.updates, cloudflare.IngressRule{Hostname: hostname, Path: path, Service: service})
+	for idx := range s.rules {
+		if s.rules[idx].Hostname == hostname && s.rules[idx].Path == path {
+			s.rules[idx].Service = service
+		}
+	}
+	return nil
+}
+
+func (s *stubBlueGreenIngressClient) ListIngressRules(_ context.Context) ([]cloudflare.IngressRule, error) {
+	return s.rules, nil
+}
+
+func blueGreenContainerLines(slotStatus string) contract.Result {
+	return contract.Result{
+		Status: contract.StatusSucceeded,
+		Data: map[string]any{
+			"lines": []string{
+				`{"ID":"a1","Names":"demo-web-1","Status":"Up 3 hours","Ports":"127.0.0.1:18080->80/tcp","Labels":"com.docker.compose.project=demo,com.docker.compose.service=web"}`,
+				`{"ID":"b1","Names":"demo-blue-web-1","Status":"` + slotStatus + `","Labels":"com.docker.compose.project=demo-blue,com.docker.compose.service=web"}`,
+				`{"ID":"b2","Names":"demo-blue-worker-1","Status":"` + slotStatus + `","Labels":"com.docker.compose.project=demo-blue,com.docker.compose.service=worker"}`,
+			},
+		},
+	}
+}
+
+func TestBlueGreenDeploySwitchesIngressAndRemovesPreviousServices(t *testing.T) {
+	t.Parallel()
+
+	workbench, repo, projectDir := newBlueGreenTestWorkbench(t, 18080)
+	bridge := &stubHostInfraBridgeClient{
+		composeResult:        contract.Result{Status: contract.StatusSucceeded},
+		composeDownResult:    contract.Result{Status: contract.StatusSucceeded},
+		listContainersResult: blueGreenContainerLines("Up 5 seconds (healthy)"),
+	}
+	cloudfl := &stubBlueGreenIngressClient{rules: []cloudflare.IngressRule{
+		{Hostname: "demo.example.com", Service: "http://localhost:18080"},
+		{Hostname: "other.example.com", Service: "http://localhost:3000"},
+	}}
+	workflows := &ProjectWorkflows{
+		projects:    repo,
+		host:        &HostService{infraClient: bridge},
+		workbench:   workbench,
+		infraClient: &stubInfraBridgeClient{},
+	}
+
+	err := workflows.runBlueGreenDeploy(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)},
+		cloudfl,
+		"job-7",
+		BlueGreenDeployRequest{Project: "demo"},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, bridge.composePayloads, 1)
+	up := bridge.composePayloads[0]
+	require.Equal(t, "demo-blue", up.ProjectName)
+	require.Equal(t, []string{"docker-compose.blue.yml"}, up.ConfigFiles)
+	require.True(t, up.Build)
+
+	record, err := repo.GetByName(context.Background(), "demo")
+	require.NoError(t, err)
+	require.NotEqual(t, 18080, record.ProxyPort)
+	require.Equal(t, []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:" + strconv.Itoa(record.ProxyPort)}}, cloudfl.updates)
+
+	slotCompose, err := os.ReadFile(filepath.Join(projectDir, "docker-compose.blue.yml"))
+	require.NoError(t, err)
+	require.Contains(t, string(slotCompose), "127.0.0.1:"+strconv.Itoa(record.ProxyPort)+":80")
+
+	require.Len(t, bridge.composeDownPayloads, 1)
+	require.Equal(t, []string{"web", "worker"}, bridge.composeDownPayloads[0].Services)
+	require.Empty(t, bridge.composeDownPayloads[0].ProjectName)
+}
+
+// blueGreenSlotContainerLines lists the live slot publishing port next to a
+// healthy candidate slot.
+func blueGreenSlotContainerLines(live string, port int, candidate string) contract.Result {
+	return contract.Result{
+		Status: contract.StatusSucceeded,
+		Data: map[string]any{
+			"lines": []string{
+				`{"ID":"a1","Names":"demo-` + live + `-web-1","Status":"Up 3 hours","Ports":"127.0.0.1:` + strconv.Itoa(port) + `->80/tcp","Labels":"com.docker.compose.project=demo-` + live + `,com.docker.compose.service=web"}`,
+				`{"ID":"b1","Names":"demo-` + candidate + `-web-1","Status":"Up 5 seconds (healthy)","Labels":"com.docker.compose.project=demo-` + candidate + `,com.docker.compose.service=web"}`,
+				`{"ID":"b2","Names":"demo-` + candidate + `-worker-1","Status":"Up 5 seconds","Labels":"com.docker.compose.project=demo-` + candidate + `,com.docker.compose.service=worker"}`,
+			},
+		},
+	}
+}
+
+func TestBlueGreenDeployAlternatesSlotsAcrossDeploys(t *testing.T) {
+	t.Parallel()
+
+	workbench, repo, _ := newBlueGreenTestWorkbench(t, 18080)
+	bridge := &stubHostInfraBridgeClient{
+		composeResult:        contract.Result{Status: contract.StatusSucceeded},
+		composeDownResult:    contract.Result{Status: contract.StatusSucceeded},
+		listContainersResult: blueGreenContainerLines("Up 5 seconds (healthy)"),
+	}
+	cloudfl := &stubBlueGreenIngressClient{rules: []cloudflare.IngressRule{
+		{Hostname: "demo.example.com", Service: "http://localhost:18080"},
+	}}
+	workflows := &ProjectWorkflows{
+		projects:    repo,
+		host:        &HostService{infraClient: bridge},
+		workbench:   workbench,
+		infraClient: &stubInfraBridgeClient{},
+	}
+	cfg := config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)}
+	deploy := func(requestID string) int {
+		t.Helper()
+		bridge.composePayloads = nil
+		bridge.composeDownPayloads = nil
+		err := workflows.runBlueGreenDeploy(context.Background(), &testWorkflowLogger{}, cfg, cloudfl, requestID, BlueGreenDeployRequest{Project: "demo"})
+		require.NoError(t, err)
+		record, err := repo.GetByName(context.Background(), "demo")
+		require.NoError(t, err)
+		return record.ProxyPort
+	}
+
+	bluePort := deploy("job-1")
+	require.Equal(t, "demo-blue", bridge.composePayloads[0].ProjectName)
+
+	// The proxy port now belongs to the blue slot; the project compose file
+	// still publishes 18080.
+	bridge.listContainersResult = blueGreenSlotContainerLines(WorkbenchBlueGreenSlotBlue, bluePort, WorkbenchBlueGreenSlotGreen)
+	greenPort := deploy("job-2")
+	require.NotEqual(t, bluePort, greenPort)
+	require.Len(t, bridge.composePayloads, 1)
+	require.Equal(t, "demo-green", bridge.composePayloads[0].ProjectName)
+	require.Len(t, bridge.composeDownPayloads, 1)
+	require.Equal(t, "demo-blue", bridge.composeDownPayloads[0].ProjectName)
+	require.Equal(t, []string{"docker-compose.blue.yml"}, bridge.composeDownPayloads[0].ConfigFiles)
+
+	bridge.listContainersResult = blueGreenSlotContainerLines(WorkbenchBlueGreenSlotGreen, greenPort, WorkbenchBlueGreenSlotBlue)
+	// Blue's next run reuses the port it freed when green took over.
+	finalPort := deploy("job-3")
+	require.Equal(t, bluePort, finalPort)
+	require.Equal(t, "demo-blue", bridge.composePayloads[0].ProjectName)
+	require.Len(t, bridge.composeDownPayloads, 1)
+	require.Equal(t, "demo-green", bridge.composeDownPayloads[0].ProjectName)
+	require.Equal(t, []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:" + strconv.Itoa(finalPort)}}, cloudfl.rules)
+}
+
+func TestBlueGreenDeployRemovesUnhealthySlotWithoutTouchingIngress(t *testing.T) {
+	t.Parallel()
+
+	workbench, repo, _ := newBlueGreenTestWorkbench(t, 18080)
+	bridge := &stubHostInfraBridgeClient{
+		composeResult:        contract.Result{Status: contract.StatusSucceeded},
+		composeDownResult:    contract.Result{Status: contract.StatusSucceeded},
+		listContainersResult: blueGreenContainerLines("Up 5 seconds (unhealthy)"),
+	}
+	cloudfl := &stubBlueGreenIngressClient{rules: []cloudflare.IngressRule{
+		{Hostname: "demo.example.com", Service: "http://localhost:18080"},
+	}}
+	workflows := &ProjectWorkflows{
+		projects:    repo,
+		host:        &HostService{infraClient: bridge},
+		workbench:   workbench,
+		infraClient: &stubInfraBridgeClient{},
+	}
+
+	err := workflows.runBlueGreenDeploy(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)},
+		cloudfl,
+		"job-8",
+		BlueGreenDeployRequest{Project: "demo"},
+	)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "rolled back")
+	require.Empty(t, cloudfl.updates)
+
+	record, err := repo.GetByName(context.Background(), "demo")
+	require.NoError(t, err)
+	require.Equal(t, 18080, record.ProxyPort)
+
+	require.Len(t, bridge.composeDownPayloads, 1)
+	require.Equal(t, "demo-blue", bridge.composeDownPayloads[0].ProjectName)
+	require.Equal(t, []string{"docker-compose.blue.yml"}, bridge.composeDownPayloads[0].ConfigFiles)
+}
+
+func TestBlueGreenActiveSlotFollowsProxyPort(t *testing.T) {
+	t.Parallel()
+
+	containers := []DockerContainer{
+		{Project: "demo", PortBindings: []DockerPortBinding{{HostPort: 5432, Published: true}}},
+		{Project: "demo-green", PortBindings: []DockerPortBinding{{HostPort: 18081, Published: true}}},
+	}
+	require.Equal(t, WorkbenchBlueGreenSlotGreen, blueGreenActiveSlot(containers, "demo", 18081, nil))
+	require.Equal(t, WorkbenchBlueGreenSlotBlue, blueGreenCandidateSlot(WorkbenchBlueGreenSlotGreen))
+	require.Equal(t, "", blueGreenActiveSlot(containers, "demo", 18080, nil))
+	require.Equal(t, WorkbenchBlueGreenSlotBlue, blueGreenCandidateSlot(""))
+
+	// A stopped or archived stack is answered by the slot compose files; a
+	// running project container publishing the port wins over them.
+	slotPorts := map[string]int{WorkbenchBlueGreenSlotBlue: 18080, WorkbenchBlueGreenSlotGreen: 18081}
+	require.Equal(t, WorkbenchBlueGreenSlotGreen, blueGreenActiveSlot(nil, "demo", 18081, slotPorts))
+	require.Equal(t, "", blueGreenActiveSlot(nil, "demo", 18082, slotPorts))
+	running := []DockerContainer{{Project: "demo", PortBindings: []DockerPortBinding{{HostPort: 18080, Published: true}}}}
+	require.Equal(t, "", blueGreenActiveSlot(running, "demo", 18080, slotPorts))
+
+	port, ok := ingressServiceLocalPort("http://127.0.0.1:18081")
+	require.True(t, ok)
+	require.Equal(t, 18081, port)
+	_, ok = ingressServiceLocalPort("http://api.internal:18081")
+	require.False(t, ok)
+}
+
+func TestBlueGreenCandidatePortReusesFreedPort(t *testing.T) {
+	t.Parallel()
+
+	plan := WorkbenchBlueGreenPlan{ProxyPort: 18081, ComposePort: 18080, SlotPorts: map[string]int{WorkbenchBlueGreenSlotBlue: 18081}}
+	port, err := blueGreenCandidatePort(plan, WorkbenchBlueGreenSlotGreen, map[int]bool{18081: true})
+	require.NoError(t, err)
+	require.Equal(t, 18080, port)
+
+	plan = WorkbenchBlueGreenPlan{ProxyPort: 18080, ComposePort: 18080, SlotPorts: map[string]int{WorkbenchBlueGreenSlotBlue: 18081, WorkbenchBlueGreenSlotGreen: 18080}}
+	port, err = blueGreenCandidatePort(plan, WorkbenchBlueGreenSlotBlue, map[int]bool{18080: true})
+	require.NoError(t, err)
+	require.Equal(t, 18081, port)
+
+	// A taken port is not reused; the search starts past the proxy port.
+	port, err = blueGreenCandidatePort(plan, WorkbenchBlueGreenSlotBlue, map[int]bool{18080: true, 18081: true})
+	require.NoError(t, err)
+	require.Greater(t, port, 18081)
+}
+
+// newLiveGreenSlotProject leaves demo served by a green slot on 18081 whose
+// containers are gone, as after an archive.
+func newLiveGreenSlotProject(t *testing.T) (*WorkbenchService, *stubProjectRepository, string) {
+	t.Helper()
+
+	workbench, repo, projectDir := newBlueGreenTestWorkbench(t, 18080)
+	_, err := workbench.WriteBlueGreenSlotCompose(context.Background(), "demo", WorkbenchBlueGreenSlotGreen, 18081)
+	require.NoError(t, err)
+	repo.projects[0].ProxyPort = 18081
+	return workbench, repo, projectDir
+}
+
+func TestComposeUpProjectStartsLiveSlotInsteadOfItsServices(t *testing.T) {
+	t.Parallel()
+
+	workbench, repo, projectDir := newLiveGreenSlotProject(t)
+	bridge := &stubHostInfraBridgeClient{
+		composeResult:        contract.Result{Status: contract.StatusSucceeded},
+		listContainersResult: contract.Result{Status: contract.StatusSucceeded, Data: map[string]any{"lines": []string{}}},
+	}
+	workflows := &ProjectWorkflows{
+		projects:  repo,
+		host:      &HostService{infraClient: bridge},
+		workbench: workbench,
+	}
+
+	err := workflows.composeUpProject(context.Background(), &testWorkflowLogger{}, "job-3", "demo", projectDir, 18081, nil)
+	require.NoError(t, err)
+	require.Len(t, bridge.composePayloads, 2)
+	require.Equal(t, []string{"db"}, bridge.composePayloads[0].Services)
+	require.Empty(t, bridge.composePayloads[0].ProjectName)
+	require.Equal(t, "demo-green", bridge.composePayloads[1].ProjectName)
+	require.Equal(t, []string{"docker-compose.green.yml"}, bridge.composePayloads[1].ConfigFiles)
+	require.Empty(t, bridge.composePayloads[1].Services)
+
+	slot, err := workflows.host.ProjectBlueGreenSlot(context.Background(), projectDir, 18080)
+	require.NoError(t, err)
+	require.Empty(t, slot)
+}
+
+func TestRestartProjectTargetsLiveSlot(t *testing.T) {
+	t.Parallel()
+
+	_, repo, projectDir := newLiveGreenSlotProject(t)
+	bridge := &stubHostInfraBridgeClient{
+		composeResult: contract.Result{Status: contract.StatusSucceeded},
+		listContainersResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"ID":"d1","Names":"demo-db-1","Status":"Up 3 hours (healthy)","Labels":"com.docker.compose.project=demo,com.docker.compose.service=db"}`,
+					`{"ID":"g1","Names":"demo-green-web-1","Status":"Up 3 hours (healthy)","Ports":"127.0.0.1:18081->80/tcp","Labels":"com.docker.compose.project=demo-green,com.docker.compose.service=web"}`,
+				},
+			},
+		},
+	}
+	svc := &HostService{templatesDir: filepath.Dir(projectDir), projects: repo, infraClient: bridge}
+
+	require.NoError(t, svc.RestartProjectStackWithLogger(context.Background(), "job-4", "demo", &testWorkflowLogger{}))
+	require.Len(t, bridge.composePayloads, 2)
+	require.Equal(t, []string{"db"}, bridge.composePayloads[0].Services)
+	require.True(t, bridge.composePayloads[0].ForceRecreate)
+	require.Equal(t, "demo-green", bridge.composePayloads[1].ProjectName)
+	require.True(t, bridge.composePayloads[1].ForceRecreate)
+
+	bridge.composePayloads = nil
+	err := svc.RestartProjectServicesWithLogger(context.Background(), "job-5", "demo", [][]string{{"db"}, {"web"}}, ProjectServicesRestartOptions{
+		HealthTimeout: time.Second,
+		PollInterval:  time.Millisecond,
+	}, &testWorkflowLogger{})
+	require.NoError(t, err)
+	require.Len(t, bridge.composePayloads, 2)
+	require.Equal(t, []string{"db"}, bridge.composePayloads[0].Services)
+	require.Empty(t, bridge.composePayloads[0].ProjectName)
+	require.Equal(t, []string{"web"}, bridge.composePayloads[1].Services)
+	require.Equal(t, "demo-green", bridge.composePayloads[1].ProjectName)
+	require.Equal(t, []string{"docker-compose.green.yml"}, bridge.composePayloads[1].ConfigFiles)
+}
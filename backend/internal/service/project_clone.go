@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+	"go-notes/internal/validate"
+)
+
+const projectCloneStatus = "cloning"
+
+// projectCloneSkippedDirs are not copied into a clone: the git checkout
+// belongs to the source and .gungnr holds its archive and compose backups.
+var projectCloneSkippedDirs = map[string]struct{}{
+	".git":    {},
+	".gungnr": {},
+}
+
+// projectCloneSkippedFiles are not copied either: blue/green slot compose
+// files bind the source's networks and volumes by name. The clone runs every
+// service from the project compose file.
+var projectCloneSkippedFiles = map[string]struct{}{
+	WorkbenchBlueGreenSlotComposeFile(WorkbenchBlueGreenSlotBlue):  {},
+	WorkbenchBlueGreenSlotComposeFile(WorkbenchBlueGreenSlotGreen): {},
+}
+
+// ProjectCloneRequest names the clone and the subdomain it is served on. An
+// empty subdomain uses the clone name; an empty domain uses the base domain.
+type ProjectCloneRequest struct {
+	Name        string `json:"name"`
+	Subdomain   string `json:"subdomain"`
+	Domain      string `json:"domain"`
+	CopyVolumes bool   `json:"copyVolumes"`
+}
+
+// ProjectClonePlan describes the clone a queued job creates.
+type ProjectClonePlan struct {
+	Project     string   `json:"project"`
+	Name        string   `json:"name"`
+	Hostname    string   `json:"hostname"`
+	Subdomain   string   `json:"subdomain"`
+	Domain      string   `json:"domain"`
+	CopyVolumes bool     `json:"copyVolumes"`
+	Volumes     []string `json:"volumes"`
+	Warnings    []string `json:"warnings"`
+}
+
+// ProjectCloneJobRequest is the job input for a clone. The clone is keyed as
+// "name" and the source as "source", so job filters and hostname discovery
+// attribute the job, and the new hostname, to the clone.
+type ProjectCloneJobRequest struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Subdomain   string `json:"subdomain"`
+	Domain      string `json:"domain"`
+	Hostname    string `json:"hostname"`
+	CopyVolumes bool   `json:"copyVolumes"`
+	// SourceHostnames are the source's hostnames when the clone was queued;
+	// their managed Access rules are applied to the clone's hostname.
+	SourceHostnames []string            `json:"sourceHostnames,omitempty"`
+	PlannedAt       time.Time           `json:"plannedAt"`
+	RequestedBy     ProjectArchiveActor `json:"requestedBy"`
+}
+
+// QueueClone checks that projectName can be copied under req.Name and that
+// the clone's hostname is free, then queues the clone job.
+func (s *ProjectArchiveService) QueueClone(
+	ctx context.Context,
+	projectName string,
+	req ProjectCloneRequest,
+	actor ProjectArchiveActor,
+) (*models.Job, ProjectClonePlan, error) {
+	if s.jobs == nil {
+		return nil, ProjectClonePlan{}, fmt.Errorf("job service unavailable")
+	}
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
+	if err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+	project := resolved.NormalizedName
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if err := validate.ProjectName(name); err != nil {
+		return nil, ProjectClonePlan{}, errs.New(errs.CodeProjectInvalidName, "clone name must be lowercase alphanumerics or dashes")
+	}
+	if name == project {
+		return nil, ProjectClonePlan{}, errs.New(errs.CodeProjectInvalidName, "clone name must differ from the source project")
+	}
+	if err := checkProjectCloneSource(runtimeCfg.TemplatesDir, resolved); err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+	if err := checkProjectCloneTarget(ctx, s.projects, runtimeCfg.TemplatesDir, name); err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+
+	subdomain := strings.ToLower(strings.TrimSpace(req.Subdomain))
+	if subdomain == "" {
+		subdomain = name
+	}
+	if err := validate.Subdomain(subdomain); err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+	selection, err := s.selectDomain(ctx, runtimeCfg, req.Domain)
+	if err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+	hostname := fmt.Sprintf("%s.%s", subdomain, selection.Domain)
+	if err := validate.Domain(hostname); err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+
+	warnings := make(map[string]struct{})
+	existing := s.planIngress(ctx, runtimeCfg, cloudflare.NewClient(runtimeCfg), []string{hostname}, warnings)
+	if len(existing) > 0 {
+		return nil, ProjectClonePlan{}, errs.New(errs.CodeProjectHostnameTaken, fmt.Sprintf("%s already routes to %s", hostname, existing[0].Service))
+	}
+
+	plan := ProjectClonePlan{
+		Project:     project,
+		Name:        name,
+		Hostname:    hostname,
+		Subdomain:   subdomain,
+		Domain:      selection.Domain,
+		CopyVolumes: req.CopyVolumes,
+		Volumes:     []string{},
+	}
+	if req.CopyVolumes {
+		if s.host == nil {
+			addArchiveWarning(warnings, "volume list unavailable: host service unavailable")
+		} else if volumes, err := s.host.ProjectVolumes(ctx, projectComposeProjectName(resolved.ProjectDir)); err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("volume list unavailable: %v", err))
+		} else {
+			plan.Volumes = volumes
+		}
+	}
+	plan.Warnings = sortedArchiveWarnings(warnings)
+
+	job, err := s.jobs.Create(ctx, JobTypeProjectClone, ProjectCloneJobRequest{
+		Name:            name,
+		Source:          project,
+		Subdomain:       subdomain,
+		Domain:          selection.Domain,
+		Hostname:        hostname,
+		CopyVolumes:     req.CopyVolumes,
+		SourceHostnames: s.discoverHostnames(ctx, project, normalizeDomain(runtimeCfg.Domain), warnings),
+		PlannedAt:       time.Now().UTC(),
+		RequestedBy:     actor,
+	})
+	if err != nil {
+		return nil, ProjectClonePlan{}, err
+	}
+	return job, plan, nil
+}
+
+// checkProjectCloneSource requires a compose project inside the templates
+// directory, since files are copied through the bridge relative to it.
+func checkProjectCloneSource(templatesDir string, resolved projectPathResolution) error {
+	if strings.TrimSpace(templatesDir) == "" {
+		return errs.New(errs.CodeProjectCloneBlocked, "TEMPLATES_DIR not configured")
+	}
+	if !isPathWithinBase(templatesDir, resolved.ProjectDir) || filepath.Clean(resolved.ProjectDir) == filepath.Clean(templatesDir) {
+		return errs.New(errs.CodeProjectCloneBlocked, fmt.Sprintf("project %s lives outside the templates directory and cannot be cloned", resolved.NormalizedName))
+	}
+	if len(resolved.ComposeFiles) == 0 {
+		return errs.New(errs.CodeProjectCloneBlocked, fmt.Sprintf("project %s has no compose file to clone", resolved.NormalizedName))
+	}
+	if resolved.ProjectRecord != nil && strings.EqualFold(strings.TrimSpace(resolved.ProjectRecord.Status), "archived") {
+		return errs.New(errs.CodeProjectCloneBlocked, fmt.Sprintf("project %s is archived; restore it before cloning", resolved.NormalizedName))
+	}
+	return nil
+}
+
+// checkProjectCloneTarget refuses a clone name that is already registered or
+// whose directory already exists.
+func checkProjectCloneTarget(ctx context.Context, projects repository.ProjectRepository, templatesDir, name string) error {
+	existsErr := errs.New(errs.CodeProjectCloneExists, fmt.Sprintf("project %s already exists", name))
+	record, err := lookupProjectRecord(ctx, projects, name)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		return existsErr
+	}
+	if _, err := os.Stat(filepath.Join(templatesDir, name)); err == nil {
+		return existsErr
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return errs.Wrap(errs.CodeProjectCloneFailed, "failed to inspect clone directory", err)
+	}
+	return nil
+}
+
+// listProjectCloneFiles returns the regular files of projectDir relative to
+// it, in walk order. Symlinks and other special files are reported as skipped
+// because the bridge copies regular files only.
+func listProjectCloneFiles(projectDir string) ([]string, []string, error) {
+	files := []string{}
+	skipped := []string{}
+	err := filepath.WalkDir(projectDir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path == projectDir {
+			return nil
+		}
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if _, skip := projectCloneSkippedDirs[entry.Name()]; skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			skipped = append(skipped, rel)
+			return nil
+		}
+		if _, skip := projectCloneSkippedFiles[rel]; skip {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, skipped, nil
+}
+
+// rewriteClonedCompose points a copied compose file at the clone: a top-level
+// name is replaced, and container_name entries are dropped so compose derives
+// per-project names instead of colliding with the source's containers. It
+// reports whether the file changed and what it changed.
+func rewriteClonedCompose(source, cloneName string) (string, []string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(source), &document); err != nil {
+		return "", nil, err
+	}
+	root := workbenchDocumentRoot(&document)
+	if root == nil || root.Kind != yaml.MappingNode {
+		return "", nil, fmt.Errorf("compose source is not a mapping")
+	}
+
+	changes := []string{}
+	if nameNode, ok := workbenchYAMLFindMapValue(root, "name"); ok && nameNode.Value != cloneName {
+		changes = append(changes, fmt.Sprintf("name: %s -> %s", nameNode.Value, cloneName))
+		workbenchYAMLSetMapEntry(root, "name", workbenchYAMLScalarNode(cloneName))
+	}
+	if servicesNode, ok := workbenchYAMLFindMapValue(root, "services"); ok && servicesNode.Kind == yaml.MappingNode {
+		for idx := 0; idx+1 < len(servicesNode.Content); idx += 2 {
+			serviceNode := servicesNode.Content[idx+1]
+			if containerName, ok := workbenchYAMLFindMapValue(serviceNode, "container_name"); ok {
+				changes = append(changes, fmt.Sprintf("services.%s.container_name %s removed", servicesNode.Content[idx].Value, containerName.Value))
+				workbenchYAMLDeleteMapEntry(serviceNode, "container_name")
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return source, changes, nil
+	}
+	rendered, err := encodeWorkbenchComposeYAML(root)
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Strings(changes)
+	return rendered, changes, nil
+}
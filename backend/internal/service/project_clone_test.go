@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+)
+
+func TestRewriteClonedComposeRenamesProjectAndDropsContainerNames(t *testing.T) {
+	t.Parallel()
+
+	rewritten, changes, err := rewriteClonedCompose("name: demo\nservices:\n  web:\n    image: nginx\n    container_name: demo-web\n  db:\n    image: postgres:16\n", "demo-qa")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"name: demo -> demo-qa",
+		"services.web.container_name demo-web removed",
+	}, changes)
+	require.Contains(t, rewritten, "name: demo-qa\n")
+	require.NotContains(t, rewritten, "container_name")
+
+	unchanged := "services:\n  web:\n    image: nginx\n"
+	rewritten, changes, err = rewriteClonedCompose(unchanged, "demo-qa")
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Equal(t, unchanged, rewritten)
+}
+
+func TestProjectCloneQueueChecksNamesAndKeysJobToClone(t *testing.T) {
+	t.Parallel()
+
+	templatesDir, projectDir := writeRestoreTestProject(t)
+	projects := &archiveTestProjectRepo{projects: []models.Project{
+		{Name: "demo", Path: projectDir, Status: "running"},
+		{Name: "taken", Path: filepath.Join(templatesDir, "taken"), Status: "running"},
+	}}
+	svc := NewProjectArchiveService(config.Config{TemplatesDir: templatesDir, Domain: "example.com"}, projects, nil, NewJobService(&archiveTestJobRepo{}, nil), nil)
+
+	job, plan, err := svc.QueueClone(context.Background(), "demo", ProjectCloneRequest{Name: "Demo-QA"}, ProjectArchiveActor{UserID: 7, Login: "tester"})
+	require.NoError(t, err)
+	require.Equal(t, JobTypeProjectClone, job.Type)
+	require.Equal(t, "demo-qa.example.com", plan.Hostname)
+
+	var payload ProjectCloneJobRequest
+	require.NoError(t, json.Unmarshal([]byte(job.Input), &payload))
+	require.Equal(t, "demo-qa", payload.Name)
+	require.Equal(t, "demo", payload.Source)
+	require.Equal(t, "demo-qa", projectNameFromJobInput(job.Input))
+
+	for name, code := range map[string]errs.Code{
+		"demo":  errs.CodeProjectInvalidName,
+		"taken": errs.CodeProjectCloneExists,
+	} {
+		_, _, err := svc.QueueClone(context.Background(), "demo", ProjectCloneRequest{Name: name}, ProjectArchiveActor{})
+		typed, ok := errs.From(err)
+		require.True(t, ok, name)
+		require.Equal(t, code, typed.Code, name)
+	}
+
+	projects.projects[0].Status = "archived"
+	_, _, err = svc.QueueClone(context.Background(), "demo", ProjectCloneRequest{Name: "demo-qa"}, ProjectArchiveActor{})
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectCloneBlocked, typed.Code)
+}
+
+func TestProjectCloneCopiesFilesMovesPortsAndRoutesNewHostname(t *testing.T) {
+	t.Parallel()
+
+	workbench, repo, projectDir := newBlueGreenTestWorkbench(t, 18080)
+	templatesDir := filepath.Dir(projectDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "web"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "web", "Dockerfile"), []byte("FROM nginx\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".env"), []byte("TOKEN=abc\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0o644))
+
+	infra := &stubDockerRunnerInfra{}
+	cloudfl := &stubHostnameCloudflareClient{dns: map[string]cloudflare.DNSRecord{}, dnsTarget: hostnameTestTunnelTarget}
+	cloudfl.apps = map[string]*cloudflare.AccessApplication{
+		"demo.example.com": {ID: "app-demo", Domain: "demo.example.com", Managed: true, Rules: cloudflare.AccessRules{Emails: []string{"ops@example.com"}}},
+	}
+	cfg := config.Config{TemplatesDir: templatesDir, Domain: "example.com", CloudflareZoneID: "zone-1"}
+	workflows := &ProjectWorkflows{
+		cfg:          cfg,
+		projects:     repo,
+		workbench:    workbench,
+		dockerRunner: NewDockerRunner(infra),
+		fileClient:   &stubProjectFileMutationClient{},
+	}
+	logger := &captureWorkflowLogger{}
+
+	err := workflows.runProjectClone(context.Background(), logger, cfg, cloudfl, "job-5", ProjectCloneJobRequest{
+		Name:            "demo-qa",
+		Source:          "demo",
+		Subdomain:       "demo-qa",
+		Domain:          "example.com",
+		Hostname:        "demo-qa.example.com",
+		SourceHostnames: []string{"demo.example.com"},
+	})
+	require.NoError(t, err, strings.Join(logger.lines, "\n"))
+
+	cloneDir := filepath.Join(templatesDir, "demo-qa")
+	env, err := os.ReadFile(filepath.Join(cloneDir, ".env"))
+	require.NoError(t, err)
+	require.Equal(t, "TOKEN=abc\n", string(env))
+	require.FileExists(t, filepath.Join(cloneDir, "web", "Dockerfile"))
+	require.NoDirExists(t, filepath.Join(cloneDir, ".git"))
+	compose, err := os.ReadFile(filepath.Join(cloneDir, "docker-compose.yml"))
+	require.NoError(t, err)
+	require.NotContains(t, string(compose), "container_name")
+	require.NotContains(t, string(compose), "18080:80")
+
+	require.True(t, infra.composeCalled)
+	require.Len(t, cloudfl.rules, 1)
+	require.Equal(t, "demo-qa.example.com", cloudfl.rules[0].Hostname)
+	require.NotEqual(t, "http://localhost:18080", cloudfl.rules[0].Service)
+	require.Contains(t, cloudfl.dns, "demo-qa.example.com")
+	require.Equal(t, []string{"ops@example.com"}, cloudfl.ensured["demo-qa.example.com"].Emails)
+	require.Empty(t, cloudfl.deleted, "the source keeps its Access application")
+
+	clone, err := repo.GetByName(context.Background(), "demo-qa")
+	require.NoError(t, err)
+	require.Equal(t, "running", clone.Status)
+	require.Equal(t, cloneDir, clone.Path)
+	require.Equal(t, "http://localhost:"+strconv.Itoa(clone.ProxyPort), cloudfl.rules[0].Service)
+
+	err = workflows.runProjectClone(context.Background(), logger, cfg, cloudfl, "job-6", ProjectCloneJobRequest{Name: "demo-qa", Source: "demo", Hostname: "demo-qa.example.com"})
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectCloneExists, typed.Code)
+}
+
+func TestProjectCloneIsNotRoutedWhenAccessCannotBeCarried(t *testing.T) {
+	t.Parallel()
+
+	workbench, repo, projectDir := newBlueGreenTestWorkbench(t, 18080)
+	templatesDir := filepath.Dir(projectDir)
+	cloudfl := &stubHostnameCloudflareClient{dns: map[string]cloudflare.DNSRecord{}, dnsTarget: hostnameTestTunnelTarget}
+	cloudfl.stubAccessClient.err = cloudflare.ErrMissingAccountID
+	cfg := config.Config{TemplatesDir: templatesDir, Domain: "example.com", CloudflareZoneID: "zone-1"}
+	workflows := &ProjectWorkflows{
+		cfg:          cfg,
+		projects:     repo,
+		workbench:    workbench,
+		dockerRunner: NewDockerRunner(&stubDockerRunnerInfra{}),
+		fileClient:   &stubProjectFileMutationClient{},
+	}
+
+	err := workflows.runProjectClone(context.Background(), &captureWorkflowLogger{}, cfg, cloudfl, "job-7", ProjectCloneJobRequest{
+		Name:            "demo-qa",
+		Source:          "demo",
+		Domain:          "example.com",
+		Hostname:        "demo-qa.example.com",
+		SourceHostnames: []string{"demo.example.com"},
+	})
+	require.ErrorContains(t, err, "the clone was not routed")
+	require.Empty(t, cloudfl.rules)
+	require.Empty(t, cloudfl.dns)
+}
+
+func TestCloneProxyPortFollowsSourceSlotToComposeMapping(t *testing.T) {
+	t.Parallel()
+
+	imported := WorkbenchStackSnapshot{Ports: []WorkbenchComposePort{
+		{ServiceName: "db", ContainerPort: 5432, HostPort: intPtr(15432)},
+		{ServiceName: "web", ContainerPort: 80, HostPort: intPtr(18080)},
+	}}
+	resolved := WorkbenchStackSnapshot{Ports: []WorkbenchComposePort{
+		{ServiceName: "db", ContainerPort: 5432, HostPort: intPtr(15433)},
+		{ServiceName: "web", ContainerPort: 80, HostPort: intPtr(18082)},
+	}}
+	slotPorts := []WorkbenchComposePort{{ServiceName: "web", ContainerPort: 80, HostPort: intPtr(18081)}}
+
+	// The source's proxy port belongs to its green slot, which is not cloned.
+	port, ok := cloneProxyPort(imported, resolved, 18081, slotPorts)
+	require.True(t, ok)
+	require.Equal(t, 18082, port)
+
+	port, ok = cloneProxyPort(imported, resolved, 18081, nil)
+	require.True(t, ok)
+	require.Equal(t, 15433, port)
+}
+
+func TestListProjectCloneFilesSkipsBlueGreenSlotFiles(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	for _, name := range []string{"docker-compose.yml", "docker-compose.blue.yml", "docker-compose.green.yml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, name), []byte("services: {}\n"), 0o644))
+	}
+	files, _, err := listProjectCloneFiles(projectDir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docker-compose.yml"}, files)
+}
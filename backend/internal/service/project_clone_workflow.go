@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"go-notes/internal/config"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/validate"
+)
+
+func (w *ProjectWorkflows) handleProjectClone(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req ProjectCloneJobRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse clone request: %w", err)
+	}
+	runtimeCfg, err := w.resolveProjectRuntimeConfig(ctx, req.Source)
+	if err != nil {
+		return fmt.Errorf("resolve runtime config: %w", err)
+	}
+	return w.runProjectClone(ctx, logger, runtimeCfg, cloudflare.NewClient(runtimeCfg), fmt.Sprintf("job-%d", job.ID), req)
+}
+
+// runProjectClone copies the source project directory file by file through
+// the bridge, points the copied compose files at the clone, registers the
+// clone, re-resolves its host ports around the running source, optionally
+// copies the source's volumes, starts the stack, puts the clone's hostname
+// behind the source's Access rules, and routes it to the port that replaced
+// the source's proxy port.
+func (w *ProjectWorkflows) runProjectClone(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl cloudflareAccessWorkflowClient,
+	requestID string,
+	req ProjectCloneJobRequest,
+) error {
+	req.Name = strings.ToLower(strings.TrimSpace(req.Name))
+	req.Source = strings.ToLower(strings.TrimSpace(req.Source))
+	if err := validate.ProjectName(req.Name); err != nil {
+		return err
+	}
+	if w.projects == nil {
+		return fmt.Errorf("project repository unavailable")
+	}
+	if w.fileClient == nil {
+		return fmt.Errorf("infra bridge file client unavailable")
+	}
+	if w.workbench == nil {
+		return fmt.Errorf("workbench service unavailable")
+	}
+	resolved, err := resolveProjectPath(ctx, w.projects, cfg.TemplatesDir, req.Source, w.runtimeMetaClient())
+	if err != nil {
+		return err
+	}
+	if err := checkProjectCloneSource(cfg.TemplatesDir, resolved); err != nil {
+		return err
+	}
+	if err := checkProjectCloneTarget(ctx, w.projects, cfg.TemplatesDir, req.Name); err != nil {
+		return err
+	}
+	selection, err := w.resolveDomainSelection(ctx, cfg, req.Domain)
+	if err != nil {
+		return err
+	}
+	sourceDir := resolved.ProjectDir
+	cloneDir, err := projectPath(cfg.TemplatesDir, req.Name)
+	if err != nil {
+		return err
+	}
+
+	logProjectStepStart(logger, "clone", "files", "source=%s target=%s", sourceDir, cloneDir)
+	files, skipped, err := listProjectCloneFiles(sourceDir)
+	if err != nil {
+		logProjectStepResult(logger, "clone", "files", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("clone %s: list files: %w", req.Source, err)
+	}
+	for _, rel := range skipped {
+		logger.Logf("skipping %s: not a regular file", rel)
+	}
+	for _, rel := range files {
+		info, err := os.Stat(filepath.Join(sourceDir, rel))
+		if err != nil {
+			return fmt.Errorf("clone %s: stat %s: %w", req.Source, rel, err)
+		}
+		if _, err := w.fileClient.ProjectFileCopy(ctx, requestID, contract.ProjectFileCopyPayload{
+			BasePath:        cfg.TemplatesDir,
+			SourcePath:      filepath.Join(sourceDir, rel),
+			DestinationPath: filepath.Join(cloneDir, rel),
+			Mode:            uint32(info.Mode().Perm()),
+			CreateParents:   true,
+		}); err != nil {
+			logProjectStepResult(logger, "clone", "files", projectArchiveStepStatusFailed, "file=%s error=%q", rel, err.Error())
+			return fmt.Errorf("clone %s: copy %s: %w; remove %s before retrying", req.Source, rel, err, cloneDir)
+		}
+	}
+	logProjectStepResult(logger, "clone", "files", projectArchiveStepStatusCompleted, "copied=%d skipped=%d", len(files), len(skipped))
+
+	sourceComposeProject := projectComposeProjectName(sourceDir)
+	logProjectStepStart(logger, "clone", "compose", "source_compose_project=%s", sourceComposeProject)
+	for _, composePath := range existingComposeFiles(cloneDir) {
+		raw, err := os.ReadFile(composePath)
+		if err != nil {
+			return fmt.Errorf("clone %s: read %s: %w", req.Source, composePath, err)
+		}
+		rewritten, changes, err := rewriteClonedCompose(string(raw), req.Name)
+		if err != nil {
+			logProjectStepResult(logger, "clone", "compose", projectArchiveStepStatusFailed, "file=%s error=%q", composePath, err.Error())
+			return fmt.Errorf("clone %s: rewrite %s: %w", req.Source, filepath.Base(composePath), err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if _, err := w.fileClient.ProjectFileWriteAtomic(ctx, requestID, contract.ProjectFileWriteAtomicPayload{
+			BasePath: cfg.TemplatesDir,
+			Path:     composePath,
+			Content:  rewritten,
+			Mode:     0o644,
+		}); err != nil {
+			return fmt.Errorf("clone %s: write %s: %w", req.Source, filepath.Base(composePath), err)
+		}
+		for _, change := range changes {
+			logger.Logf("%s: %s", filepath.Base(composePath), change)
+		}
+	}
+	logProjectStepResult(logger, "clone", "compose", projectArchiveStepStatusCompleted, "compose_project=%s", req.Name)
+
+	// The clone's hostname goes on the source's tunnel, so it keeps that assignment.
+	tunnel, err := w.tunnels.ProjectTunnel(ctx, req.Source)
+	if err != nil {
+		return fmt.Errorf("clone %s: resolve tunnel: %w", req.Source, err)
+	}
+	record := &models.Project{Name: req.Name, Path: cloneDir, Tunnel: tunnel, Status: projectCloneStatus}
+	if _, err := w.upsertProject(ctx, record); err != nil {
+		return fmt.Errorf("clone %s: register %s: %w", req.Source, req.Name, err)
+	}
+
+	logProjectStepStart(logger, "clone", "ports", "project=%s", req.Name)
+	imported, cloned, summary, err := w.workbench.ImportClonedSnapshot(ctx, req.Name)
+	if err != nil {
+		logProjectStepResult(logger, "clone", "ports", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return w.workbenchJobError(logger, "clone port resolution", err)
+	}
+	sourcePort := 0
+	if resolved.ProjectRecord != nil {
+		sourcePort = resolved.ProjectRecord.ProxyPort
+	}
+	proxyPort, ok := cloneProxyPort(imported, cloned, sourcePort, workbenchBlueGreenSlotPorts(sourceDir))
+	if !ok {
+		logProjectStepResult(logger, "clone", "ports", projectArchiveStepStatusFailed, "reason=%q", "no published host port")
+		return fmt.Errorf("clone %s: the clone publishes no host port to route %s to", req.Source, req.Hostname)
+	}
+	logProjectStepResult(logger, "clone", "ports", projectArchiveStepStatusCompleted, "changed=%t proxy_port=%d source_proxy_port=%d", summary.Changed, proxyPort, sourcePort)
+
+	volumesStatus := projectArchiveStepStatusSkipped
+	logProjectStepStart(logger, "clone", "volumes", "copy_volumes=%t", req.CopyVolumes)
+	if !req.CopyVolumes {
+		logProjectStepResult(logger, "clone", "volumes", volumesStatus, "reason=%q", "volume copy not requested")
+	} else {
+		if w.host == nil {
+			err = fmt.Errorf("host service unavailable")
+		} else {
+			_, err = w.host.CopyProjectVolumesWithLogger(ctx, requestID, sourceComposeProject, req.Name, logger)
+		}
+		if err != nil {
+			logProjectStepResult(logger, "clone", "volumes", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("clone %s: copy volumes: %w; the clone was not started", req.Source, err)
+		}
+		volumesStatus = projectArchiveStepStatusCompleted
+		logProjectStepResult(logger, "clone", "volumes", volumesStatus, "source=%s", sourceComposeProject)
+	}
+
+	logProjectStepStart(logger, "clone", "stack", "project_dir=%s", cloneDir)
+	if err := w.runCompose(ctx, logger, cloneDir); err != nil {
+		logProjectStepResult(logger, "clone", "stack", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("clone %s: compose up: %w", req.Source, err)
+	}
+	logProjectStepResult(logger, "clone", "stack", projectArchiveStepStatusCompleted, "project=%s", req.Name)
+
+	// A clone of a protected project is protected before it is routed; the
+	// source's applications stay where they are.
+	warnings := make(map[string]struct{})
+	logProjectStepStart(logger, "clone", "access", "hostname=%s source_hostnames=%d", req.Hostname, len(req.SourceHostnames))
+	accessApps, err := carryProjectAccess(ctx, logger, cloudfl, req.SourceHostnames, []string{req.Hostname}, warnings)
+	if err != nil {
+		logProjectStepResult(logger, "clone", "access", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("clone %s: protect %s: %w; the clone was not routed", req.Source, req.Hostname, err)
+	}
+	logProjectStepResult(logger, "clone", "access", projectArchiveStepStatusCompleted, "carried_apps=%d", len(accessApps))
+	for _, warning := range sortedArchiveWarnings(warnings) {
+		logger.Logf("warning: %s", warning)
+	}
+
+	logger.Logf("configuring tunnel ingress for %s", req.Hostname)
+	if err := w.cloudflareSetup(ctx, logger, cfg, cloudfl, requestID, req.Hostname, selection.Domain, selection.ZoneID, proxyPort); err != nil {
+		return err
+	}
+
+	record.ProxyPort = proxyPort
+	record.Status = "running"
+	if _, err := w.upsertProject(ctx, record); err != nil {
+		return err
+	}
+
+	if w.audit != nil {
+		if err := w.audit.Log(ctx, AuditEntry{
+			UserID:    req.RequestedBy.UserID,
+			UserLogin: req.RequestedBy.Login,
+			Action:    "project.clone.completed",
+			Target:    req.Name,
+			Metadata: map[string]any{
+				"project":   req.Name,
+				"source":    req.Source,
+				"hostname":  req.Hostname,
+				"proxyPort": proxyPort,
+				"volumes":   volumesStatus,
+				"access":    len(accessApps) > 0,
+			},
+		}); err != nil {
+			logger.Logf("audit warning: failed to write clone completion event: %v", err)
+		}
+	}
+	logger.Logf("clone completed: %s is serving %s on port %d", req.Name, req.Hostname, proxyPort)
+	return nil
+}
+
+// cloneProxyPort finds the clone's replacement for the source's proxy port:
+// the mapping that published sourcePort before port resolution, read after
+// it. A source served by a blue/green slot publishes sourcePort from a slot
+// compose file (slotPorts), so the clone uses the project compose file's
+// mapping for the same service. Without a match it falls back to the clone's
+// first published port.
+func cloneProxyPort(imported, resolved WorkbenchStackSnapshot, sourcePort int, slotPorts []WorkbenchComposePort) (int, bool) {
+	source := workbenchBlueGreenProxyPort(imported.Ports, sourcePort)
+	if source == nil {
+		source = workbenchBlueGreenProxyPort(slotPorts, sourcePort)
+	}
+	if sourcePort > 0 && source != nil {
+		for _, port := range imported.Ports {
+			if port.ServiceName != source.ServiceName || port.ContainerPort != source.ContainerPort || port.HostPort == nil {
+				continue
+			}
+			for _, candidate := range resolved.Ports {
+				if candidate.ServiceName == port.ServiceName && candidate.ContainerPort == port.ContainerPort &&
+					candidate.Protocol == port.Protocol && candidate.HostPort != nil {
+					return *candidate.HostPort, true
+				}
+			}
+		}
+	}
+	for _, candidate := range resolved.Ports {
+		if candidate.HostPort != nil && *candidate.HostPort > 0 {
+			return *candidate.HostPort, true
+		}
+	}
+	return 0, false
+}
+
+// projectComposeProjectName is the compose project docker compose derives
+// for the project's compose file when run from projectDir.
+func projectComposeProjectName(projectDir string) string {
+	var document yaml.Node
+	if composePath, err := resolveComposeFile(projectDir); err == nil {
+		if raw, err := os.ReadFile(composePath); err == nil && yaml.Unmarshal(raw, &document) == nil {
+			return workbenchComposeProjectName(workbenchDocumentRoot(&document), projectDir)
+		}
+	}
+	return workbenchComposeProjectName(nil, projectDir)
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+)
+
+const (
+	// projectEnvHistoryDir holds one file per replaced .env, named by version.
+	projectEnvHistoryDir     = ".gungnr/env/history"
+	projectEnvHistoryKeep    = 20
+	projectEnvVersionLayout  = "20060102T150405.000Z"
+	projectEnvCurrentVersion = "current"
+)
+
+var projectEnvVersionPattern = regexp.MustCompile(`^\d{8}T\d{6}\.\d{3}Z$`)
+
+// ProjectEnvVersion is a saved copy of the .env content that a later save or
+// restore replaced.
+type ProjectEnvVersion struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	SizeBytes int64     `json:"sizeBytes"`
+	KeyCount  int       `json:"keyCount"`
+}
+
+// ProjectEnvDiff compares the keys of two versions. "current" names the live
+// .env file.
+type ProjectEnvDiff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	ProjectEnvKeyChanges
+	Unchanged int `json:"unchanged"`
+}
+
+// ProjectEnvRestore is the result of putting a history version back.
+type ProjectEnvRestore struct {
+	ProjectEnvWrite
+	RestoredVersion string `json:"restoredVersion"`
+}
+
+// Validate checks content as Save would, without writing it.
+func (s *ProjectEnvService) Validate(ctx context.Context, projectName, content string) (ProjectEnvValidation, error) {
+	if int64(len(content)) > s.maxBytes {
+		return ProjectEnvValidation{}, errs.New(
+			errs.CodeProjectEnvTooLarge,
+			fmt.Sprintf(".env exceeds max size (%d bytes)", s.maxBytes),
+		)
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
+	if err != nil {
+		return ProjectEnvValidation{}, err
+	}
+	return validateProjectEnv(content, resolved.ComposeFiles), nil
+}
+
+// History lists the saved versions of the project's .env, newest first.
+func (s *ProjectEnvService) History(ctx context.Context, projectName string) ([]ProjectEnvVersion, error) {
+	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := listProjectEnvVersions(resolved.ProjectDir)
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeProjectEnvReadFailed, "failed to list .env history", err)
+	}
+	versions := make([]ProjectEnvVersion, 0, len(ids))
+	for idx := len(ids) - 1; idx >= 0; idx-- {
+		id := ids[idx]
+		content, err := s.readVersion(resolved.ProjectDir, id)
+		if err != nil {
+			return nil, err
+		}
+		entries, _ := parseProjectEnv(content)
+		createdAt, _ := time.Parse(projectEnvVersionLayout, id)
+		versions = append(versions, ProjectEnvVersion{
+			ID:        id,
+			CreatedAt: createdAt,
+			SizeBytes: int64(len(content)),
+			KeyCount:  len(projectEnvValues(entries)),
+		})
+	}
+	return versions, nil
+}
+
+// Diff compares the keys of two versions; to defaults to the live file.
+func (s *ProjectEnvService) Diff(ctx context.Context, projectName, from, to string) (ProjectEnvDiff, error) {
+	from = strings.TrimSpace(from)
+	to = strings.TrimSpace(to)
+	if to == "" {
+		to = projectEnvCurrentVersion
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
+	if err != nil {
+		return ProjectEnvDiff{}, err
+	}
+	before, err := s.readVersionOrCurrent(resolved, from)
+	if err != nil {
+		return ProjectEnvDiff{}, err
+	}
+	after, err := s.readVersionOrCurrent(resolved, to)
+	if err != nil {
+		return ProjectEnvDiff{}, err
+	}
+	beforeEntries, _ := parseProjectEnv(before)
+	afterEntries, _ := parseProjectEnv(after)
+	changes := diffProjectEnv(beforeEntries, afterEntries)
+	afterValues := projectEnvValues(afterEntries)
+	return ProjectEnvDiff{
+		From:                 from,
+		To:                   to,
+		ProjectEnvKeyChanges: changes,
+		Unchanged:            len(afterValues) - len(changes.Added) - len(changes.Changed),
+	}, nil
+}
+
+// Restore saves a history version as the live .env. The replaced content is
+// recorded as a new version first, so a restore can itself be undone.
+func (s *ProjectEnvService) Restore(ctx context.Context, projectName, versionID string) (ProjectEnvRestore, error) {
+	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
+	if err != nil {
+		return ProjectEnvRestore{}, err
+	}
+	content, err := s.readVersion(resolved.ProjectDir, strings.TrimSpace(versionID))
+	if err != nil {
+		return ProjectEnvRestore{}, err
+	}
+	written, err := s.Save(ctx, projectName, content, true)
+	if err != nil {
+		return ProjectEnvRestore{}, err
+	}
+	return ProjectEnvRestore{ProjectEnvWrite: written, RestoredVersion: strings.TrimSpace(versionID)}, nil
+}
+
+func (s *ProjectEnvService) readVersionOrCurrent(resolved projectPathResolution, id string) (string, error) {
+	if id != projectEnvCurrentVersion {
+		return s.readVersion(resolved.ProjectDir, id)
+	}
+	if exists, sizeBytes, _ := envFileInfo(resolved.EnvPath); !exists {
+		return "", nil
+	} else if sizeBytes > s.maxBytes {
+		return "", errs.New(errs.CodeProjectEnvTooLarge, fmt.Sprintf(".env exceeds max size (%d bytes)", s.maxBytes))
+	}
+	raw, err := os.ReadFile(resolved.EnvPath)
+	if err != nil {
+		return "", errs.Wrap(errs.CodeProjectEnvReadFailed, "failed to read .env", err)
+	}
+	rest, _ := splitProjectSecretsBlock(string(raw))
+	return rest, nil
+}
+
+func (s *ProjectEnvService) readVersion(projectDir, id string) (string, error) {
+	if !projectEnvVersionPattern.MatchString(id) {
+		return "", errs.New(errs.CodeProjectEnvVersionNotFound, fmt.Sprintf(".env version %q not found", id))
+	}
+	raw, err := os.ReadFile(projectEnvVersionPath(projectDir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", errs.New(errs.CodeProjectEnvVersionNotFound, fmt.Sprintf(".env version %q not found", id))
+	}
+	if err != nil {
+		return "", errs.Wrap(errs.CodeProjectEnvReadFailed, "failed to read .env version", err)
+	}
+	// Versions recorded before the managed block was stripped may still
+	// carry it; keep those keys out of history listings and diffs too.
+	rest, _ := splitProjectSecretsBlock(string(raw))
+	return rest, nil
+}
+
+// recordHistoryVersion writes the replaced .env content into the history
+// directory under a new millisecond timestamp, bumping it past any version
+// saved in the same millisecond. content must already have the managed secrets
+// block removed: history files are plain copies outside the vault.
+func (s *ProjectEnvService) recordHistoryVersion(ctx context.Context, projectDir, content string) (string, string, error) {
+	stamp := time.Now().UTC()
+	id := stamp.Format(projectEnvVersionLayout)
+	for {
+		if _, err := os.Stat(projectEnvVersionPath(projectDir, id)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		stamp = stamp.Add(time.Millisecond)
+		id = stamp.Format(projectEnvVersionLayout)
+	}
+	versionPath := projectEnvVersionPath(projectDir, id)
+	if !isPathWithinBase(projectDir, versionPath) {
+		return "", "", errs.New(errs.CodeProjectEnvWriteFailed, "unsafe .env history path")
+	}
+	if _, err := s.fileClient.ProjectFileWriteAtomic(ctx, "", contract.ProjectFileWriteAtomicPayload{
+		BasePath:      projectDir,
+		Path:          versionPath,
+		Content:       content,
+		Mode:          0o600,
+		CreateParents: true,
+	}); err != nil {
+		return "", "", errs.Wrap(errs.CodeProjectEnvWriteFailed, "failed to record .env history", err)
+	}
+	return id, versionPath, nil
+}
+
+// pruneHistory removes versions beyond projectEnvHistoryKeep. A failed
+// removal only leaves an extra version behind, which the next save retries.
+func (s *ProjectEnvService) pruneHistory(ctx context.Context, projectDir string) {
+	ids, err := listProjectEnvVersions(projectDir)
+	if err != nil || len(ids) <= projectEnvHistoryKeep {
+		return
+	}
+	for _, id := range ids[:len(ids)-projectEnvHistoryKeep] {
+		_, _ = s.fileClient.ProjectFileRemove(ctx, "", contract.ProjectFileRemovePayload{
+			BasePath:       projectDir,
+			Path:           projectEnvVersionPath(projectDir, id),
+			IgnoreNotExist: true,
+		})
+	}
+}
+
+// listProjectEnvVersions returns the version IDs in the history directory,
+// oldest first.
+func listProjectEnvVersions(projectDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(projectDir, filepath.FromSlash(projectEnvHistoryDir)))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".env")
+		if !ok || !entry.Type().IsRegular() || !projectEnvVersionPattern.MatchString(id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func projectEnvVersionPath(projectDir, id string) string {
+	return filepath.Join(projectDir, filepath.FromSlash(projectEnvHistoryDir), id+".env")
+}
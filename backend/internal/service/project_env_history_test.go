@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/errs"
+)
+
+func TestParseProjectEnvReportsMalformedLinesAndDuplicates(t *testing.T) {
+	t.Parallel()
+
+	entries, issues := parseProjectEnv("# comment\nexport A=1\nB=\"two\nlines\" # note\nC='x' y\nnot a pair\n1BAD=2\nA=3\nD=plain # inline\nE=\"open\n")
+	require.Equal(t, []ProjectEnvIssue{
+		{Line: 5, Key: "C", Message: "unexpected text after closing ' quote"},
+		{Line: 6, Message: "expected KEY=VALUE"},
+		{Line: 7, Key: "1BAD", Message: "key must start with a letter or underscore and contain only letters, digits, _, . or -"},
+		{Line: 8, Key: "A", Message: "duplicate key; first set on line 2"},
+		{Line: 10, Key: "E", Message: "unterminated \" quote"},
+	}, issues)
+	values := projectEnvValues(entries)
+	require.Equal(t, "two\nlines", values["B"])
+	require.Equal(t, "plain", values["D"])
+	require.Equal(t, "3", values["A"])
+}
+
+func TestValidateProjectEnvReportsComposeKeysWithoutDefaults(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	composePath := filepath.Join(dir, "docker-compose.yml")
+	require.NoError(t, os.WriteFile(composePath, []byte(`services:
+  web:
+    image: nginx:${TAG:-latest}
+    environment:
+      TOKEN: ${TOKEN}
+      SECRET: ${SECRET:?set SECRET}
+      DEFINED: $DEFINED
+      OPTIONAL: ${OPTIONAL-}
+`), 0o644))
+
+	result := validateProjectEnv("DEFINED=1\n", []string{composePath})
+	require.True(t, result.Valid)
+	require.Equal(t, []string{"DEFINED"}, result.Keys)
+	require.Len(t, result.MissingKeys, 2)
+	require.Equal(t, "SECRET", result.MissingKeys[0].Key)
+	require.Equal(t, "TOKEN", result.MissingKeys[1].Key)
+	require.NotEmpty(t, result.MissingKeys[1].References)
+}
+
+func TestProjectEnvServiceHistoryDiffAndRestore(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	envPath := filepath.Join(projectDir, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("A=1\nB=2\n"), 0o600))
+
+	svc := NewProjectEnvService(templatesDir, nil)
+	svc.SetFileMutationClient(&stubProjectFileMutationClient{})
+	ctx := context.Background()
+
+	first, err := svc.Save(ctx, "demo", "A=1\nB=3\nC=4\n", true)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.VersionID)
+	require.Equal(t, ProjectEnvKeyChanges{Added: []string{"C"}, Removed: []string{}, Changed: []string{"B"}}, first.Changes)
+
+	second, err := svc.Save(ctx, "demo", "A=9\n", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"B", "C"}, second.Changes.Removed)
+
+	unchanged, err := svc.Save(ctx, "demo", "A=9\n", true)
+	require.NoError(t, err)
+	require.Empty(t, unchanged.VersionID)
+
+	versions, err := svc.History(ctx, "demo")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, second.VersionID, versions[0].ID)
+	require.Equal(t, 3, versions[0].KeyCount)
+	require.Equal(t, first.VersionID, versions[1].ID)
+
+	diff, err := svc.Diff(ctx, "demo", first.VersionID, "")
+	require.NoError(t, err)
+	require.Equal(t, "current", diff.To)
+	require.Equal(t, []string{"A"}, diff.Changed)
+	require.Equal(t, []string{"B"}, diff.Removed)
+	require.Equal(t, 0, diff.Unchanged)
+
+	restored, err := svc.Restore(ctx, "demo", first.VersionID)
+	require.NoError(t, err)
+	require.Equal(t, first.VersionID, restored.RestoredVersion)
+	require.Equal(t, []string{"B"}, restored.Changes.Added)
+	raw, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	require.Equal(t, "A=1\nB=2\n", string(raw))
+
+	_, err = svc.Restore(ctx, "demo", "../../.env")
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectEnvVersionNotFound, typed.Code)
+
+	_, err = svc.Save(ctx, "demo", "A=1\nA=2\n", true)
+	typed, ok = errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectEnvInvalid, typed.Code)
+	require.Equal(t, ".env line 2 (A): duplicate key; first set on line 1", typed.Message)
+}
+
+func TestProjectEnvServiceHistoryExcludesManagedSecretsBlock(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	envPath := filepath.Join(projectDir, ".env")
+	live := mergeProjectSecretsBlock("A=1\n", map[string]string{"API_TOKEN": "vaulted-value"})
+	require.NoError(t, os.WriteFile(envPath, []byte(live), 0o600))
+
+	svc := NewProjectEnvService(templatesDir, nil)
+	svc.SetFileMutationClient(&stubProjectFileMutationClient{})
+	ctx := context.Background()
+
+	saved, err := svc.Save(ctx, "demo", "A=2\n", true)
+	require.NoError(t, err)
+	require.NotEmpty(t, saved.VersionID)
+
+	version, err := os.ReadFile(projectEnvVersionPath(projectDir, saved.VersionID))
+	require.NoError(t, err)
+	require.Equal(t, "A=1\n", string(version))
+
+	raw, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "vaulted-value")
+
+	versions, err := svc.History(ctx, "demo")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	require.Equal(t, 1, versions[0].KeyCount)
+
+	diff, err := svc.Diff(ctx, "demo", saved.VersionID, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"A"}, diff.Changed)
+	require.Empty(t, diff.Added)
+	require.Empty(t, diff.Removed)
+	require.Equal(t, 0, diff.Unchanged)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var projectEnvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// ProjectEnvIssue is a line of a .env file that compose would misread.
+type ProjectEnvIssue struct {
+	Line    int    `json:"line"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProjectEnvMissingKey is a variable the compose files interpolate without a
+// default that the .env file does not define.
+type ProjectEnvMissingKey struct {
+	Key        string   `json:"key"`
+	References []string `json:"references"`
+}
+
+// ProjectEnvValidation reports the parse issues of a .env file and the
+// compose variables it leaves undefined. Only key names are reported.
+type ProjectEnvValidation struct {
+	Valid       bool                   `json:"valid"`
+	Keys        []string               `json:"keys"`
+	Issues      []ProjectEnvIssue      `json:"issues"`
+	MissingKeys []ProjectEnvMissingKey `json:"missingKeys"`
+	Warnings    []string               `json:"warnings"`
+}
+
+// ProjectEnvKeyChanges lists the keys added, removed, or given a new value
+// between two .env contents. Values are never included.
+type ProjectEnvKeyChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+func (c ProjectEnvKeyChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+type projectEnvEntry struct {
+	Key     string
+	Value   string
+	Line    int
+	EndLine int
+}
+
+// parseProjectEnv reads .env content the way compose does: blank lines and
+// # comments are ignored, an export prefix is allowed, quoted values may span
+// lines, and unquoted values end at an inline " #" comment. Entries are
+// returned even when issues are found so callers can still diff the file.
+func parseProjectEnv(content string) ([]projectEnvEntry, []ProjectEnvIssue) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	entries := []projectEnvEntry{}
+	issues := []ProjectEnvIssue{}
+	firstSeen := map[string]int{}
+	for idx := 0; idx < len(lines); idx++ {
+		lineNo := idx + 1
+		line := strings.TrimSpace(lines[idx])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export "); ok {
+			line = strings.TrimSpace(rest)
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			issues = append(issues, ProjectEnvIssue{Line: lineNo, Message: "expected KEY=VALUE"})
+			continue
+		}
+		key := strings.TrimSpace(line[:eq])
+		if !projectEnvKeyPattern.MatchString(key) {
+			issues = append(issues, ProjectEnvIssue{Line: lineNo, Key: key, Message: "key must start with a letter or underscore and contain only letters, digits, _, . or -"})
+			continue
+		}
+		value, consumed, err := parseProjectEnvValue(strings.TrimLeft(line[eq+1:], " \t"), lines[idx+1:])
+		idx += consumed
+		if err != nil {
+			issues = append(issues, ProjectEnvIssue{Line: lineNo, Key: key, Message: err.Error()})
+			continue
+		}
+		if first, dup := firstSeen[key]; dup {
+			issues = append(issues, ProjectEnvIssue{Line: lineNo, Key: key, Message: fmt.Sprintf("duplicate key; first set on line %d", first)})
+		} else {
+			firstSeen[key] = lineNo
+		}
+		entries = append(entries, projectEnvEntry{Key: key, Value: value, Line: lineNo, EndLine: lineNo + consumed})
+	}
+	return entries, issues
+}
+
+// parseProjectEnvValue returns the value starting at raw and how many of the
+// following lines a multi-line quoted value consumed.
+func parseProjectEnvValue(raw string, rest []string) (string, int, error) {
+	if raw == "" || (raw[0] != '"' && raw[0] != '\'') {
+		if idx := strings.Index(raw, " #"); idx >= 0 {
+			raw = raw[:idx]
+		}
+		return strings.TrimSpace(raw), 0, nil
+	}
+	quote := raw[0]
+	text := raw[1:]
+	consumed := 0
+	for {
+		if end := projectEnvClosingQuote(text, quote); end >= 0 {
+			trailing := strings.TrimSpace(text[end+1:])
+			if trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return "", consumed, fmt.Errorf("unexpected text after closing %c quote", quote)
+			}
+			return text[:end], consumed, nil
+		}
+		if consumed >= len(rest) {
+			return "", consumed, fmt.Errorf("unterminated %c quote", quote)
+		}
+		text += "\n" + rest[consumed]
+		consumed++
+	}
+}
+
+func projectEnvClosingQuote(text string, quote byte) int {
+	for idx := 0; idx < len(text); idx++ {
+		switch text[idx] {
+		case '\\':
+			if quote == '"' {
+				idx++
+			}
+		case quote:
+			return idx
+		}
+	}
+	return -1
+}
+
+func projectEnvValues(entries []projectEnvEntry) map[string]string {
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.Key] = entry.Value
+	}
+	return values
+}
+
+func diffProjectEnv(before, after []projectEnvEntry) ProjectEnvKeyChanges {
+	changes := ProjectEnvKeyChanges{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	beforeValues := projectEnvValues(before)
+	afterValues := projectEnvValues(after)
+	for key, value := range afterValues {
+		previous, ok := beforeValues[key]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, key)
+		case previous != value:
+			changes.Changed = append(changes.Changed, key)
+		}
+	}
+	for key := range beforeValues {
+		if _, ok := afterValues[key]; !ok {
+			changes.Removed = append(changes.Removed, key)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return changes
+}
+
+// validateProjectEnv parses content and checks it against the variables the
+// compose files interpolate. A compose file that cannot be parsed is reported
+// as a warning rather than failing validation.
+func validateProjectEnv(content string, composeFiles []string) ProjectEnvValidation {
+	entries, issues := parseProjectEnv(content)
+	values := projectEnvValues(entries)
+	result := ProjectEnvValidation{
+		Valid:       len(issues) == 0,
+		Keys:        make([]string, 0, len(values)),
+		Issues:      issues,
+		MissingKeys: []ProjectEnvMissingKey{},
+		Warnings:    []string{},
+	}
+	for key := range values {
+		result.Keys = append(result.Keys, key)
+	}
+	sort.Strings(result.Keys)
+
+	references := map[string]map[string]struct{}{}
+	for _, composePath := range composeFiles {
+		raw, err := os.ReadFile(composePath)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", filepath.Base(composePath), err))
+			continue
+		}
+		parsed, err := ParseWorkbenchComposeCore(strings.ReplaceAll(string(raw), "\r\n", "\n"))
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", filepath.Base(composePath), err))
+			continue
+		}
+		for _, ref := range parsed.EnvRefs {
+			if ref.Variable == "" || !projectEnvRefRequired(ref.Expression) {
+				continue
+			}
+			if _, ok := values[ref.Variable]; ok {
+				continue
+			}
+			if references[ref.Variable] == nil {
+				references[ref.Variable] = map[string]struct{}{}
+			}
+			references[ref.Variable][ref.Path] = struct{}{}
+		}
+	}
+	for key, paths := range references {
+		missing := ProjectEnvMissingKey{Key: key, References: make([]string, 0, len(paths))}
+		for path := range paths {
+			missing.References = append(missing.References, path)
+		}
+		sort.Strings(missing.References)
+		result.MissingKeys = append(result.MissingKeys, missing)
+	}
+	sort.Slice(result.MissingKeys, func(i, j int) bool {
+		return result.MissingKeys[i].Key < result.MissingKeys[j].Key
+	})
+	return result
+}
+
+// projectEnvRefRequired reports whether an interpolation has no default:
+// ${VAR}, $VAR and ${VAR:?err} need the variable, ${VAR:-x} and ${VAR:+x} do not.
+func projectEnvRefRequired(expression string) bool {
+	trimmed := strings.TrimSpace(expression)
+	if !strings.HasPrefix(trimmed, "${") || !strings.HasSuffix(trimmed, "}") {
+		return true
+	}
+	inner := strings.TrimSuffix(strings.TrimPrefix(trimmed, "${"), "}")
+	idx := strings.IndexAny(inner, ":-+?")
+	if idx < 0 {
+		return true
+	}
+	operator := inner[idx]
+	if operator == ':' && idx+1 < len(inner) {
+		operator = inner[idx+1]
+	}
+	return operator != '-' && operator != '+'
+}
+
+func projectEnvIssuesMessage(issues []ProjectEnvIssue) string {
+	first := issues[0]
+	message := fmt.Sprintf(".env line %d: %s", first.Line, first.Message)
+	if first.Key != "" {
+		message = fmt.Sprintf(".env line %d (%s): %s", first.Line, first.Key, first.Message)
+	}
+	if len(issues) > 1 {
+		message += fmt.Sprintf(" (and %d more)", len(issues)-1)
+	}
+	return message
+}
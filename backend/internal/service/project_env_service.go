@@ -21,6 +21,12 @@ type ProjectEnvRead struct {
 	SizeBytes int64      `json:"sizeBytes"`
 	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
 	Content   string     `json:"content"`
+	// Validation reports parse issues and compose variables the file leaves
+	// undefined.
+	Validation ProjectEnvValidation `json:"validation"`
+	// ManagedKeys are injected from the secrets vault at deploy time. Their
+	// block is left out of Content and kept as-is on save.
+	ManagedKeys []string `json:"managedKeys"`
 }
 
 type ProjectEnvWrite struct {
@@ -28,6 +34,10 @@ type ProjectEnvWrite struct {
 	SizeBytes  int64     `json:"sizeBytes"`
 	UpdatedAt  time.Time `json:"updatedAt"`
 	BackupPath string    `json:"backupPath,omitempty"`
+	// VersionID names the history entry holding the replaced content.
+	VersionID   string                 `json:"versionId,omitempty"`
+	Changes     ProjectEnvKeyChanges   `json:"changes"`
+	MissingKeys []ProjectEnvMissingKey `json:"missingKeys"`
 }
 
 type ProjectEnvService struct {
@@ -63,9 +73,10 @@ func (s *ProjectEnvService) Load(ctx context.Context, projectName string) (Proje
 	}
 
 	response := ProjectEnvRead{
-		Path:    resolved.EnvPath,
-		Exists:  false,
-		Content: "",
+		Path:        resolved.EnvPath,
+		Exists:      false,
+		Content:     "",
+		ManagedKeys: []string{},
 	}
 
 	exists, sizeBytes, updatedAt := envFileInfo(resolved.EnvPath)
@@ -74,6 +85,7 @@ func (s *ProjectEnvService) Load(ctx context.Context, projectName string) (Proje
 	response.UpdatedAt = updatedAt
 
 	if !exists {
+		response.Validation = validateProjectEnv("", resolved.ComposeFiles)
 		return response, nil
 	}
 	if sizeBytes > s.maxBytes {
@@ -88,7 +100,10 @@ func (s *ProjectEnvService) Load(ctx context.Context, projectName string) (Proje
 		return ProjectEnvRead{}, errs.Wrap(errs.CodeProjectEnvReadFailed, "failed to read .env", err)
 	}
 
-	response.Content = string(content)
+	rest, block := splitProjectSecretsBlock(string(content))
+	response.Content = rest
+	response.ManagedKeys = projectSecretsBlockKeys(block)
+	response.Validation = validateProjectEnv(string(content), resolved.ComposeFiles)
 	return response, nil
 }
 
@@ -105,6 +120,15 @@ func (s *ProjectEnvService) Save(
 		)
 	}
 
+	content, _ = splitProjectSecretsBlock(content)
+	entries, issues := parseProjectEnv(content)
+	if len(issues) > 0 {
+		return ProjectEnvWrite{}, errs.WithDetails(
+			errs.New(errs.CodeProjectEnvInvalid, projectEnvIssuesMessage(issues)),
+			map[string]any{"issues": issues},
+		)
+	}
+
 	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
 	if err != nil {
 		return ProjectEnvWrite{}, err
@@ -124,22 +148,31 @@ func (s *ProjectEnvService) Save(
 		return ProjectEnvWrite{}, errs.New(errs.CodeProjectEnvWriteFailed, "infra bridge file client unavailable")
 	}
 
+	previous := ""
+	exists, _, _ := envFileInfo(envPath)
+	if exists {
+		raw, err := os.ReadFile(envPath)
+		if err != nil {
+			return ProjectEnvWrite{}, errs.Wrap(errs.CodeProjectEnvWriteFailed, "failed to read current .env", err)
+		}
+		previous = string(raw)
+	}
+	previousRest, block := splitProjectSecretsBlock(previous)
+	previousEntries, _ := parseProjectEnv(previousRest)
+	if block != "" {
+		if trimmed := strings.TrimRight(content, "\n"); trimmed != "" {
+			content = trimmed + "\n\n" + block
+		} else {
+			content = block
+		}
+	}
+
 	backupPath := ""
-	if createBackup {
-		if exists, _, _ := envFileInfo(envPath); exists {
-			backupPath = filepath.Join(projectDir, fmt.Sprintf(".env.backup.%s", time.Now().UTC().Format("20060102-150405")))
-			if !isPathWithinBase(projectDir, backupPath) {
-				return ProjectEnvWrite{}, errs.New(errs.CodeProjectEnvWriteFailed, "unsafe .env backup path")
-			}
-			if _, err := s.fileClient.ProjectFileCopy(ctx, "", contract.ProjectFileCopyPayload{
-				BasePath:        projectDir,
-				SourcePath:      envPath,
-				DestinationPath: backupPath,
-				Mode:            0o600,
-				CreateParents:   true,
-			}); err != nil {
-				return ProjectEnvWrite{}, errs.Wrap(errs.CodeProjectEnvWriteFailed, "failed to create .env backup", err)
-			}
+	versionID := ""
+	if createBackup && exists && previous != content {
+		versionID, backupPath, err = s.recordHistoryVersion(ctx, projectDir, previousRest)
+		if err != nil {
+			return ProjectEnvWrite{}, err
 		}
 	}
 
@@ -158,12 +191,18 @@ func (s *ProjectEnvService) Save(
 	if err != nil {
 		return ProjectEnvWrite{}, errs.Wrap(errs.CodeProjectEnvWriteFailed, "failed to stat saved .env file", err)
 	}
+	if versionID != "" {
+		s.pruneHistory(ctx, projectDir)
+	}
 	updatedAt := info.ModTime().UTC()
 	return ProjectEnvWrite{
-		Path:       envPath,
-		SizeBytes:  info.Size(),
-		UpdatedAt:  updatedAt,
-		BackupPath: backupPath,
+		Path:        envPath,
+		SizeBytes:   info.Size(),
+		UpdatedAt:   updatedAt,
+		BackupPath:  backupPath,
+		VersionID:   versionID,
+		Changes:     diffProjectEnv(previousEntries, entries),
+		MissingKeys: validateProjectEnv(content, resolved.ComposeFiles).MissingKeys,
 	}, nil
 }
 
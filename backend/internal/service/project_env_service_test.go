@@ -113,11 +113,12 @@ func TestProjectEnvServiceSaveUsesBridgeFileMutations(t *testing.T) {
 	if result.BackupPath == "" {
 		t.Fatal("expected backup path")
 	}
-	if len(stub.copyCalls) != 1 {
-		t.Fatalf("expected one copy call, got %d", len(stub.copyCalls))
+	if len(stub.copyCalls) != 0 {
+		t.Fatalf("expected no copy calls, got %d", len(stub.copyCalls))
 	}
-	if len(stub.writeCalls) != 1 {
-		t.Fatalf("expected one write call, got %d", len(stub.writeCalls))
+	// One write records the history version, the second replaces .env.
+	if len(stub.writeCalls) != 2 {
+		t.Fatalf("expected two write calls, got %d", len(stub.writeCalls))
 	}
 
 	raw, err := os.ReadFile(envPath)
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+	"go-notes/internal/validate"
+)
+
+const (
+	GitRedeployTriggerManual  = "manual"
+	GitRedeployTriggerWebhook = "webhook"
+)
+
+// gitRedeployLockWait bounds how long a redeploy queues behind another one
+// of the same project; a rebuild can take several minutes.
+const gitRedeployLockWait = 30 * time.Minute
+
+// GitRedeployRequest is the job input for pulling a template project's
+// repository and rebuilding its stack.
+type GitRedeployRequest struct {
+	Project string `json:"project"`
+	// Ref is a branch, tag, or commit. Empty means the project's tracked
+	// branch, or the branch currently checked out.
+	Ref string `json:"ref,omitempty"`
+	// Commit is the head commit reported by a push webhook, for the job log.
+	Commit  string `json:"commit,omitempty"`
+	Trigger string `json:"trigger,omitempty"`
+}
+
+// ProjectGitDeploySettings controls which branch git redeploys track and
+// whether GitHub pushes to it queue a redeploy.
+type ProjectGitDeploySettings struct {
+	Branch     string `json:"branch"`
+	AutoDeploy bool   `json:"autoDeploy"`
+}
+
+// GitRedeploy queues a pull-and-rebuild job for a template project.
+func (s *ProjectService) GitRedeploy(ctx context.Context, name, ref string) (*models.Job, error) {
+	record, err := s.gitProjectRecord(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ref = strings.TrimSpace(ref)
+	if ref != "" {
+		if err := validate.GitRef(ref); err != nil {
+			return nil, err
+		}
+	}
+	return s.jobs.Create(ctx, JobTypeGitRedeploy, GitRedeployRequest{
+		Project: record.Name,
+		Ref:     ref,
+		Trigger: GitRedeployTriggerManual,
+	})
+}
+
+// UpdateGitDeploySettings stores the tracked branch and auto-deploy flag.
+func (s *ProjectService) UpdateGitDeploySettings(ctx context.Context, name string, settings ProjectGitDeploySettings) (*models.Project, error) {
+	record, err := s.gitProjectRecord(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	settings.Branch = strings.TrimSpace(settings.Branch)
+	if settings.Branch != "" {
+		if err := validate.GitRef(settings.Branch); err != nil {
+			return nil, err
+		}
+	}
+	if settings.AutoDeploy && settings.Branch == "" {
+		return nil, errs.New(errs.CodeValidationGitRef, "branch is required when autoDeploy is enabled")
+	}
+	record.GitBranch = settings.Branch
+	record.AutoDeploy = settings.AutoDeploy
+	if err := s.repo.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("update project git settings: %w", err)
+	}
+	return record, nil
+}
+
+func (s *ProjectService) gitProjectRecord(ctx context.Context, name string) (*models.Project, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if err := validate.ProjectName(name); err != nil {
+		return nil, err
+	}
+	record, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeProjectNotFound, "project not found")
+		}
+		return nil, err
+	}
+	path := strings.TrimSpace(record.Path)
+	if path == "" {
+		return nil, errs.New(errs.CodeProjectGitUnavailable, "project has no directory on this host")
+	}
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return nil, errs.New(errs.CodeProjectGitUnavailable, "project directory is not a git checkout")
+	}
+	return record, nil
+}
+
+func (w *ProjectWorkflows) handleGitRedeploy(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req GitRedeployRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse git redeploy request: %w", err)
+	}
+	req.Project = strings.ToLower(strings.TrimSpace(req.Project))
+	if err := validate.ProjectName(req.Project); err != nil {
+		return err
+	}
+	if w.settings == nil {
+		return fmt.Errorf("settings not configured")
+	}
+	runtimeCfg, err := w.settings.ResolveConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("load settings: %w", err)
+	}
+	githubToken, configured, err := w.githubInstallationToken(ctx)
+	if err != nil {
+		return err
+	}
+	if !configured {
+		logger.Log("github app not configured; fetching without credentials")
+	}
+	return w.runGitRedeploy(ctx, logger, runtimeCfg, githubToken, req)
+}
+
+// runGitRedeploy fetches origin, moves the checkout to the requested ref, and
+// rebuilds the stack. Branches are only fast-forwarded, so local commits or
+// uncommitted edits that conflict with upstream stop the job before anything
+// is rebuilt. A project served by a blue/green slot is rebuilt in that slot.
+func (w *ProjectWorkflows) runGitRedeploy(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	githubToken string,
+	req GitRedeployRequest,
+) error {
+	if w.projects == nil {
+		return fmt.Errorf("project repository unavailable")
+	}
+	record, err := w.projects.GetByName(ctx, req.Project)
+	if err != nil {
+		return fmt.Errorf("load project record: %w", err)
+	}
+	projectDir := strings.TrimSpace(record.Path)
+	if projectDir == "" {
+		return fmt.Errorf("project %q has no directory", req.Project)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".git")); err != nil {
+		return fmt.Errorf("project %q is not a git checkout: %w", req.Project, err)
+	}
+
+	// Redeploys of one project share a checkout. Holding the workbench lock
+	// from the first git command until the commit is recorded queues them
+	// behind each other and keeps workbench edits off the compose file while
+	// it moves.
+	if w.workbench != nil {
+		release, err := w.workbench.AcquireProjectLockWait(ctx, req.Project, gitRedeployLockWait)
+		if err != nil {
+			return w.workbenchJobError(logger, "git redeploy lock", err)
+		}
+		defer release()
+	}
+
+	ref := strings.TrimSpace(req.Ref)
+	if ref == "" {
+		ref = strings.TrimSpace(record.GitBranch)
+	}
+	if ref == "" {
+		current, err := gitOutput(ctx, projectDir, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return err
+		}
+		if current == "HEAD" {
+			return fmt.Errorf("checkout is detached and no ref was requested")
+		}
+		ref = current
+	}
+	if err := validate.GitRef(ref); err != nil {
+		return err
+	}
+
+	previous, err := gitOutput(ctx, projectDir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	remote := "origin"
+	if githubToken != "" {
+		originURL, err := gitOutput(ctx, projectDir, "remote", "get-url", "origin")
+		if err == nil && strings.HasPrefix(originURL, "https://") {
+			if authURL, err := buildAuthenticatedCloneURL(originURL, githubToken); err == nil {
+				remote = authURL
+			}
+		}
+	}
+	trigger := strings.TrimSpace(req.Trigger)
+	if trigger == "" {
+		trigger = GitRedeployTriggerManual
+	}
+	logger.Logf("fetching origin for %s (trigger=%s)", req.Project, trigger)
+	if err := runQuietCommand(ctx, logger, projectDir, []string{"GIT_TERMINAL_PROMPT=0"},
+		"git", "fetch", "--prune", "--tags", remote, "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return fmt.Errorf("fetch origin: %w", err)
+	}
+
+	if _, err := gitOutput(ctx, projectDir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+ref); err == nil {
+		current, _ := gitOutput(ctx, projectDir, "rev-parse", "--abbrev-ref", "HEAD")
+		if current != ref {
+			logger.Logf("checking out branch %s", ref)
+			args := []string{"checkout", ref, "--"}
+			if _, err := gitOutput(ctx, projectDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+ref); err != nil {
+				args = []string{"checkout", "-b", ref, "--track", "origin/" + ref}
+			}
+			if err := runQuietCommand(ctx, logger, projectDir, nil, "git", args...); err != nil {
+				return fmt.Errorf("checkout branch %s: %w", ref, err)
+			}
+		}
+		logger.Logf("fast-forwarding %s to origin/%s", ref, ref)
+		if err := runQuietCommand(ctx, logger, projectDir, nil, "git", "merge", "--ff-only", "origin/"+ref); err != nil {
+			return fmt.Errorf("fast-forward %s: %w", ref, err)
+		}
+	} else {
+		commit, err := gitOutput(ctx, projectDir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+		if err != nil {
+			return fmt.Errorf("ref %q not found on origin", ref)
+		}
+		logger.Logf("checking out %s at %s", ref, shortCommit(commit))
+		if err := runQuietCommand(ctx, logger, projectDir, nil, "git", "checkout", "--detach", commit); err != nil {
+			return fmt.Errorf("checkout %s: %w", ref, err)
+		}
+	}
+
+	head, err := gitOutput(ctx, projectDir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if head == previous {
+		logger.Logf("already at %s; rebuilding anyway", shortCommit(head))
+	} else {
+		logger.Logf("updated %s -> %s", shortCommit(previous), shortCommit(head))
+	}
+	if expected := strings.TrimSpace(req.Commit); expected != "" && !strings.HasPrefix(head, expected) {
+		logger.Logf("warning: push reported %s but origin/%s is now at %s", shortCommit(expected), ref, shortCommit(head))
+	}
+
+	if err := repairProjectOwnership(logger, cfg.TemplatesDir, cfg.CloudflaredConfig, projectDir); err != nil {
+		return err
+	}
+	if w.workbench != nil {
+		snapshot, changed, err := w.workbench.importComposeSnapshotLocked(ctx, req.Project, workbenchImportReasonAutoRedeploy)
+		if err != nil {
+			return w.workbenchJobError(logger, "workbench snapshot import", err)
+		}
+		if changed {
+			logger.Logf("compose changed upstream; workbench snapshot now at revision %d", snapshot.Revision)
+		}
+	}
+
+	logger.Log("rebuilding docker compose stack")
+	renderSlot := func(slot string) error {
+		if w.workbench == nil {
+			return nil
+		}
+		_, err := w.workbench.writeBlueGreenSlotComposeLocked(ctx, req.Project, slot, record.ProxyPort)
+		return err
+	}
+	if err := w.composeUpProject(ctx, logger, "", req.Project, projectDir, record.ProxyPort, renderSlot); err != nil {
+		return err
+	}
+
+	// Reload the record: a blue/green switch may have moved the proxy port
+	// while the stack was rebuilding.
+	record, err = w.projects.GetByName(ctx, req.Project)
+	if err != nil {
+		return fmt.Errorf("load project record: %w", err)
+	}
+	deployedAt := time.Now().UTC()
+	record.DeployedCommit = head
+	record.DeployedAt = &deployedAt
+	record.Status = "running"
+	if err := w.projects.Update(ctx, record); err != nil {
+		return fmt.Errorf("record deployed commit: %w", err)
+	}
+	return nil
+}
+
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/models"
+)
+
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func commitTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	runTestGit(t, dir, "add", name)
+	runTestGit(t, dir, "commit", "-q", "-m", "update "+name)
+	return runTestGit(t, dir, "rev-parse", "HEAD")
+}
+
+// newGitDeployFixture returns an upstream repo and a clone of it under a
+// templates directory, registered as project "demo".
+func newGitDeployFixture(t *testing.T) (string, string, *stubProjectRepository) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	require.NoError(t, os.MkdirAll(upstream, 0o755))
+	runTestGit(t, upstream, "init", "-q", "-b", "main")
+	commitTestFile(t, upstream, "docker-compose.yml", "services:\n  web:\n    image: nginx:1.25\n")
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	runTestGit(t, templatesDir, "clone", "-q", upstream, projectDir)
+
+	repo := &stubProjectRepository{projects: []models.Project{{
+		Name:      "demo",
+		Path:      projectDir,
+		GitBranch: "main",
+	}}}
+	return upstream, projectDir, repo
+}
+
+func TestGitRedeployFastForwardsAndRecordsCommit(t *testing.T) {
+	t.Parallel()
+
+	upstream, projectDir, repo := newGitDeployFixture(t)
+	head := commitTestFile(t, upstream, "VERSION", "2\n")
+	infra := &stubDockerRunnerInfra{}
+	workflows := &ProjectWorkflows{projects: repo, dockerRunner: NewDockerRunner(infra)}
+
+	err := workflows.runGitRedeploy(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{TemplatesDir: filepath.Dir(projectDir)},
+		"",
+		GitRedeployRequest{Project: "demo", Commit: head, Trigger: GitRedeployTriggerWebhook},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, head, runTestGit(t, projectDir, "rev-parse", "HEAD"))
+	require.Equal(t, "main", runTestGit(t, projectDir, "rev-parse", "--abbrev-ref", "HEAD"))
+	require.True(t, infra.composeCalled)
+	require.True(t, infra.composePayload.Build)
+	require.Equal(t, projectDir, infra.composePayload.ProjectDir)
+
+	record, err := repo.GetByName(context.Background(), "demo")
+	require.NoError(t, err)
+	require.Equal(t, head, record.DeployedCommit)
+	require.NotNil(t, record.DeployedAt)
+	require.Equal(t, "running", record.Status)
+}
+
+func TestGitRedeployChecksOutTagDetached(t *testing.T) {
+	t.Parallel()
+
+	upstream, projectDir, repo := newGitDeployFixture(t)
+	tagged := commitTestFile(t, upstream, "VERSION", "1\n")
+	runTestGit(t, upstream, "tag", "v1.0.0")
+	commitTestFile(t, upstream, "VERSION", "2\n")
+	workflows := &ProjectWorkflows{projects: repo, dockerRunner: NewDockerRunner(&stubDockerRunnerInfra{})}
+
+	err := workflows.runGitRedeploy(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{TemplatesDir: filepath.Dir(projectDir)},
+		"",
+		GitRedeployRequest{Project: "demo", Ref: "v1.0.0"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, tagged, runTestGit(t, projectDir, "rev-parse", "HEAD"))
+	require.Equal(t, "HEAD", runTestGit(t, projectDir, "rev-parse", "--abbrev-ref", "HEAD"))
+}
+
+func TestGitRedeployStopsWhenBranchCannotFastForward(t *testing.T) {
+	t.Parallel()
+
+	upstream, projectDir, repo := newGitDeployFixture(t)
+	commitTestFile(t, upstream, "VERSION", "upstream\n")
+	local := commitTestFile(t, projectDir, "VERSION", "local\n")
+	infra := &stubDockerRunnerInfra{}
+	workflows := &ProjectWorkflows{projects: repo, dockerRunner: NewDockerRunner(infra)}
+
+	err := workflows.runGitRedeploy(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{TemplatesDir: filepath.Dir(projectDir)},
+		"",
+		GitRedeployRequest{Project: "demo"},
+	)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "fast-forward")
+	require.False(t, infra.composeCalled)
+	require.Equal(t, local, runTestGit(t, projectDir, "rev-parse", "HEAD"))
+
+	record, err := repo.GetByName(context.Background(), "demo")
+	require.NoError(t, err)
+	require.Empty(t, record.DeployedCommit)
+}
+
+func TestGitRedeployWaitsForProjectLock(t *testing.T) {
+	t.Parallel()
+
+	upstream, projectDir, repo := newGitDeployFixture(t)
+	previous := runTestGit(t, projectDir, "rev-parse", "HEAD")
+	head := commitTestFile(t, upstream, "VERSION", "2\n")
+	workbench := NewWorkbenchServiceWithStorage(filepath.Dir(projectDir), repo, &fakeSettingsRepo{}, "test-session-secret")
+	workflows := &ProjectWorkflows{projects: repo, workbench: workbench, dockerRunner: NewDockerRunner(&stubDockerRunnerInfra{})}
+
+	// A redeploy already holds the checkout.
+	release, err := workbench.AcquireProjectLock(context.Background(), "demo")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- workflows.runGitRedeploy(
+			context.Background(),
+			&testWorkflowLogger{},
+			config.Config{TemplatesDir: filepath.Dir(projectDir)},
+			"",
+			GitRedeployRequest{Project: "demo", Trigger: GitRedeployTriggerWebhook},
+		)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, previous, runTestGit(t, projectDir, "rev-parse", "HEAD"))
+	select {
+	case err := <-done:
+		t.Fatalf("redeploy finished while the lock was held: %v", err)
+	default:
+	}
+
+	release()
+	require.NoError(t, <-done)
+	require.Equal(t, head, runTestGit(t, projectDir, "rev-parse", "HEAD"))
+	record, err := repo.GetByName(context.Background(), "demo")
+	require.NoError(t, err)
+	require.Equal(t, head, record.DeployedCommit)
+}
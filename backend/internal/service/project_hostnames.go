@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+	"go-notes/internal/validate"
+)
+
+// ProjectHostnameChangeRequest moves a project to a new subdomain and/or
+// domain. An empty subdomain keeps the project name; an empty domain uses the
+// base domain.
+type ProjectHostnameChangeRequest struct {
+	Subdomain string `json:"subdomain"`
+	Domain    string `json:"domain"`
+}
+
+// ProjectHostnamePlan describes the new hostname and the previous ingress
+// rules and DNS records that are removed once it verifies.
+type ProjectHostnamePlan struct {
+	Project           string                        `json:"project"`
+	Hostname          string                        `json:"hostname"`
+	Subdomain         string                        `json:"subdomain"`
+	Domain            string                        `json:"domain"`
+	Port              int                           `json:"port"`
+	PreviousHostnames []string                      `json:"previousHostnames"`
+	Ingress           []ProjectArchivePlanIngress   `json:"ingressRules"`
+	DNSRecords        []ProjectArchivePlanDNSRecord `json:"dnsRecords"`
+	Warnings          []string                      `json:"warnings"`
+}
+
+type ProjectHostnamePreviousTargets struct {
+	Hostnames    []string                            `json:"hostnames"`
+	IngressRules []ProjectArchiveIngressDeleteTarget `json:"ingressRules"`
+	DNSRecords   []ProjectArchiveDNSDeleteTarget     `json:"dnsRecords"`
+}
+
+// ProjectHostnameRoute is a path rule served on a hostname. Path is the
+// cloudflared path regex; the hostname's catch-all rule has no entry.
+type ProjectHostnameRoute struct {
+	Path   string                    `json:"path"`
+	Port   int                       `json:"port"`
+	Scheme string                    `json:"scheme,omitempty"`
+	Origin *cloudflare.IngressOrigin `json:"origin,omitempty"`
+}
+
+// ProjectHostnameJobRequest is the job input for a hostname change. The
+// hostname, subdomain, and domain keys are what later archive plans read to
+// discover the project's current hostname.
+type ProjectHostnameJobRequest struct {
+	Project   string `json:"project"`
+	Subdomain string `json:"subdomain"`
+	Domain    string `json:"domain"`
+	Hostname  string `json:"hostname"`
+	Port      int    `json:"port"`
+	// Ingress carries the scheme and origin options of the previous root
+	// rule to the new hostname's.
+	Ingress     *ProjectIngressRequest         `json:"ingress,omitempty"`
+	Routes      []ProjectHostnameRoute         `json:"routes,omitempty"`
+	Previous    ProjectHostnamePreviousTargets `json:"previous"`
+	PlannedAt   time.Time                      `json:"plannedAt"`
+	RequestedBy ProjectArchiveActor            `json:"requestedBy"`
+}
+
+// SetDeploymentRepository lets hostname discovery include the hostnames
+// recorded on deployment rows.
+func (s *ProjectArchiveService) SetDeploymentRepository(deployments repository.DeploymentRepository) {
+	s.deployments = deployments
+}
+
+// QueueHostnameChange plans a hostname change and queues the job that adds the
+// new DNS record and ingress rule, verifies them, and only then removes the
+// previous entries.
+func (s *ProjectArchiveService) QueueHostnameChange(
+	ctx context.Context,
+	projectName string,
+	req ProjectHostnameChangeRequest,
+	actor ProjectArchiveActor,
+) (*models.Job, ProjectHostnamePlan, error) {
+	if s.jobs == nil {
+		return nil, ProjectHostnamePlan{}, fmt.Errorf("job service unavailable")
+	}
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
+	if err != nil {
+		return nil, ProjectHostnamePlan{}, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, ProjectHostnamePlan{}, err
+	}
+	project := resolved.NormalizedName
+
+	subdomain := strings.ToLower(strings.TrimSpace(req.Subdomain))
+	if subdomain == "" {
+		subdomain = project
+	}
+	if err := validate.Subdomain(subdomain); err != nil {
+		return nil, ProjectHostnamePlan{}, err
+	}
+	selection, err := s.selectDomain(ctx, runtimeCfg, req.Domain)
+	if err != nil {
+		return nil, ProjectHostnamePlan{}, err
+	}
+	hostname := fmt.Sprintf("%s.%s", subdomain, selection.Domain)
+	if err := validate.Domain(hostname); err != nil {
+		return nil, ProjectHostnamePlan{}, err
+	}
+
+	warnings := make(map[string]struct{})
+	cfClient := cloudflare.NewClient(runtimeCfg)
+	discovered := s.discoverHostnames(ctx, project, normalizeDomain(runtimeCfg.Domain), warnings)
+	previous := make([]string, 0, len(discovered))
+	for _, candidate := range discovered {
+		if candidate != hostname {
+			previous = append(previous, candidate)
+		}
+	}
+	plan := ProjectHostnamePlan{
+		Project:           project,
+		Hostname:          hostname,
+		Subdomain:         subdomain,
+		Domain:            selection.Domain,
+		PreviousHostnames: previous,
+		Ingress:           s.planIngress(ctx, runtimeCfg, cfClient, previous, warnings),
+		DNSRecords:        s.planDNSRecords(ctx, runtimeCfg, cfClient, previous, warnings),
+	}
+
+	if resolved.ProjectRecord != nil {
+		plan.Port = resolved.ProjectRecord.ProxyPort
+	}
+	if plan.Port <= 0 {
+		for _, rule := range plan.Ingress {
+			if port, ok := ingressServiceLocalPort(rule.Service); ok {
+				plan.Port = port
+				break
+			}
+		}
+	}
+	if plan.Port <= 0 {
+		return nil, ProjectHostnamePlan{}, errs.New(errs.CodeProjectHostnameNoPort, "project has no proxy port to route the new hostname to")
+	}
+
+	// Rules already on the new hostname either belong to this project (nothing
+	// to move) or to something else (refuse to take it over).
+	existing := s.planIngress(ctx, runtimeCfg, cfClient, []string{hostname}, map[string]struct{}{})
+	for _, rule := range existing {
+		if port, ok := ingressServiceLocalPort(rule.Service); !ok || port != plan.Port {
+			return nil, ProjectHostnamePlan{}, errs.New(errs.CodeProjectHostnameTaken, fmt.Sprintf("%s already routes to %s", hostname, rule.Service))
+		}
+	}
+	if len(existing) > 0 && len(plan.Ingress) == 0 && !hasDeletableDNSRecord(plan.DNSRecords) {
+		return nil, ProjectHostnamePlan{}, errs.New(errs.CodeProjectHostnameUnchanged, fmt.Sprintf("project already serves %s", hostname))
+	}
+
+	// Path rules on the previous hostnames move along with the hostname, and
+	// every moved rule keeps its service scheme and origin options.
+	var rootIngress *ProjectIngressRequest
+	for _, rule := range plan.Ingress {
+		if rule.Path != "" {
+			continue
+		}
+		if port, ok := ingressServiceLocalPort(rule.Service); ok && port == plan.Port {
+			rootIngress = &ProjectIngressRequest{Scheme: ingressServiceScheme(rule.Service), Origin: rule.Origin}
+			break
+		}
+	}
+	routes := []ProjectHostnameRoute{}
+	seenPaths := make(map[string]struct{})
+	for _, rule := range plan.Ingress {
+		if rule.Path == "" {
+			continue
+		}
+		port, ok := ingressServiceLocalPort(rule.Service)
+		if !ok {
+			continue
+		}
+		if _, seen := seenPaths[rule.Path]; seen {
+			continue
+		}
+		seenPaths[rule.Path] = struct{}{}
+		routes = append(routes, ProjectHostnameRoute{Path: rule.Path, Port: port, Scheme: ingressServiceScheme(rule.Service), Origin: rule.Origin})
+	}
+
+	previousTargets := ProjectHostnamePreviousTargets{
+		Hostnames:    append([]string{}, previous...),
+		IngressRules: []ProjectArchiveIngressDeleteTarget{},
+		DNSRecords:   []ProjectArchiveDNSDeleteTarget{},
+	}
+	for _, rule := range plan.Ingress {
+		previousTargets.IngressRules = append(previousTargets.IngressRules, ProjectArchiveIngressDeleteTarget{
+			Hostname: rule.Hostname,
+			Path:     rule.Path,
+			Service:  strings.TrimSpace(rule.Service),
+			Source:   rule.Source,
+		})
+	}
+	for _, record := range plan.DNSRecords {
+		if !record.DeleteEligible {
+			continue
+		}
+		previousTargets.DNSRecords = append(previousTargets.DNSRecords, ProjectArchiveDNSDeleteTarget{
+			ZoneID:   record.ZoneID,
+			RecordID: record.ID,
+			Hostname: record.Name,
+			Content:  record.Content,
+		})
+	}
+	plan.Warnings = sortedArchiveWarnings(warnings)
+
+	job, err := s.jobs.Create(ctx, JobTypeProjectHostnames, ProjectHostnameJobRequest{
+		Project:     project,
+		Subdomain:   subdomain,
+		Domain:      selection.Domain,
+		Hostname:    hostname,
+		Port:        plan.Port,
+		Ingress:     rootIngress,
+		Routes:      routes,
+		Previous:    previousTargets,
+		PlannedAt:   time.Now().UTC(),
+		RequestedBy: actor,
+	})
+	if err != nil {
+		return nil, ProjectHostnamePlan{}, err
+	}
+	return job, plan, nil
+}
+
+func hasDeletableDNSRecord(records []ProjectArchivePlanDNSRecord) bool {
+	for _, record := range records {
+		if record.DeleteEligible {
+			return true
+		}
+	}
+	return false
+}
+
+// addHostnamesFromDeployments adds the hostnames recorded on the project's
+// deployment rows.
+func (s *ProjectArchiveService) addHostnamesFromDeployments(ctx context.Context, target map[string]struct{}, project string, warnings map[string]struct{}) {
+	if s.deployments == nil {
+		return
+	}
+	record, err := lookupProjectRecord(ctx, s.projects, project)
+	if err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("failed to load project record: %v", err))
+		return
+	}
+	if record == nil {
+		return
+	}
+	deployments, err := s.deployments.ListByProject(ctx, record.ID)
+	if err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("failed to inspect project deployments: %v", err))
+		return
+	}
+	for _, deployment := range deployments {
+		hostname := strings.ToLower(strings.TrimSpace(deployment.Hostname))
+		if hostname == "" || validate.Domain(hostname) != nil {
+			continue
+		}
+		target[hostname] = struct{}{}
+	}
+}
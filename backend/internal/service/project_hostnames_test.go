@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+)
+
+const hostnameTestTunnelTarget = "tunnel-id.cfargotunnel.com"
+
+type stubHostnameCloudflareClient struct {
+	stubAccessClient
+	rules      []cloudflare.IngressRule
+	dns        map[string]cloudflare.DNSRecord
+	dnsTarget  string
+	removed    []cloudflare.IngressRule
+	deletedDNS []string
+}
+
+func (s *stubHostnameCloudflareClient) EnsureDNSForZone(_ context.Context, hostname string, _ string) error {
+	s.dns[hostname] = cloudflare.DNSRecord{ID: "rec-" + hostname, Type: "CNAME", Name: hostname, Content: s.dnsTarget}
+	return nil
+}
+
+func (s *stubHostnameCloudflareClient) UpdateIngressRouteOptions(_ context.Context, hostname, path string, port int, opts cloudflare.IngressRouteOptions) error {
+	scheme := opts.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	s.rules = append(s.rules, cloudflare.IngressRule{Hostname: hostname, Path: path, Service: scheme + "://localhost:" + strconv.Itoa(port), Origin: opts.Origin})
+	return nil
+}
+
+func (s *stubHostnameCloudflareClient) ListIngressRules(_ context.Context) ([]cloudflare.IngressRule, error) {
+	return s.rules, nil
+}
+
+func (s *stubHostnameCloudflareClient) RemoveIngressRules(_ context.Context, targets []cloudflare.IngressRule) ([]cloudflare.IngressRule, error) {
+	s.removed = append(s.removed, targets...)
+	return targets, nil
+}
+
+func (s *stubHostnameCloudflareClient) ExpectedTunnelCNAME(_ context.Context) (string, error) {
+	return hostnameTestTunnelTarget, nil
+}
+
+func (s *stubHostnameCloudflareClient) ListDNSRecordsByName(_ context.Context, hostname, _ string) ([]cloudflare.DNSRecord, error) {
+	if record, ok := s.dns[hostname]; ok {
+		return []cloudflare.DNSRecord{record}, nil
+	}
+	return nil, nil
+}
+
+func (s *stubHostnameCloudflareClient) DeleteTunnelCNAMERecord(_ context.Context, _, recordID, _, _ string) (cloudflare.DNSDeleteResult, error) {
+	s.deletedDNS = append(s.deletedDNS, recordID)
+	return cloudflare.DNSDeleteResult{Deleted: true}, nil
+}
+
+type stubDeploymentRepository struct {
+	deployments []models.Deployment
+}
+
+func (r *stubDeploymentRepository) ListByProject(_ context.Context, projectID uint) ([]models.Deployment, error) {
+	result := []models.Deployment{}
+	for _, deployment := range r.deployments {
+		if deployment.ProjectID == projectID {
+			result = append(result, deployment)
+		}
+	}
+	return result, nil
+}
+
+func (r *stubDeploymentRepository) Save(_ context.Context, deployment *models.Deployment) error {
+	for i := range r.deployments {
+		if deployment.ID != 0 && r.deployments[i].ID == deployment.ID {
+			r.deployments[i] = *deployment
+			return nil
+		}
+	}
+	for _, existing := range r.deployments {
+		if existing.ID > deployment.ID {
+			deployment.ID = existing.ID
+		}
+	}
+	deployment.ID++
+	r.deployments = append(r.deployments, *deployment)
+	return nil
+}
+
+func (r *stubDeploymentRepository) Delete(_ context.Context, id uint) error {
+	for i := range r.deployments {
+		if r.deployments[i].ID == id {
+			r.deployments = append(r.deployments[:i], r.deployments[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func hostnameChangeRequest() ProjectHostnameJobRequest {
+	return ProjectHostnameJobRequest{
+		Project:   "demo",
+		Subdomain: "shop",
+		Domain:    "example.com",
+		Hostname:  "shop.example.com",
+		Port:      18080,
+		Previous: ProjectHostnamePreviousTargets{
+			Hostnames: []string{"demo.example.com"},
+			IngressRules: []ProjectArchiveIngressDeleteTarget{
+				{Hostname: "demo.example.com", Service: "http://localhost:18080", Source: "remote"},
+			},
+			DNSRecords: []ProjectArchiveDNSDeleteTarget{
+				{ZoneID: "zone-1", RecordID: "rec-demo", Hostname: "demo.example.com", Content: hostnameTestTunnelTarget},
+			},
+		},
+	}
+}
+
+func TestProjectHostnameChangeAddsVerifiesThenRemovesPrevious(t *testing.T) {
+	t.Parallel()
+
+	projects := &stubProjectRepository{projects: []models.Project{{Model: gorm.Model{ID: 3}, Name: "demo", ProxyPort: 18080, Status: "running"}}}
+	deployments := &stubDeploymentRepository{deployments: []models.Deployment{
+		{Model: gorm.Model{ID: 1}, ProjectID: 3, Subdomain: "demo", Hostname: "demo.example.com", Port: 18080},
+		{Model: gorm.Model{ID: 2}, ProjectID: 3, Subdomain: "demo", Hostname: "demo.example.com", Path: "/api", Port: 18081},
+	}}
+	cloudfl := &stubHostnameCloudflareClient{
+		rules:     []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:18080"}},
+		dns:       map[string]cloudflare.DNSRecord{},
+		dnsTarget: hostnameTestTunnelTarget,
+	}
+	workflows := &ProjectWorkflows{
+		cfg:         config.Config{Domain: "example.com", CloudflareZoneID: "zone-1"},
+		projects:    projects,
+		deployments: deployments,
+	}
+	logger := &captureWorkflowLogger{}
+	req := hostnameChangeRequest()
+	req.Routes = []ProjectHostnameRoute{{Path: "^/api(/|$)", Port: 18081, Scheme: "https", Origin: &cloudflare.IngressOrigin{NoTLSVerify: true}}}
+
+	err := workflows.runProjectHostnameChange(
+		context.Background(),
+		logger,
+		config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)},
+		cloudfl,
+		"job-9",
+		req,
+	)
+	require.NoError(t, err)
+
+	require.Contains(t, cloudfl.rules, cloudflare.IngressRule{Hostname: "shop.example.com", Service: "http://localhost:18080"})
+	require.Contains(t, cloudfl.rules, cloudflare.IngressRule{Hostname: "shop.example.com", Path: "^/api(/|$)", Service: "https://localhost:18081", Origin: &cloudflare.IngressOrigin{NoTLSVerify: true}})
+	require.Equal(t, []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:18080"}}, cloudfl.removed)
+	require.Equal(t, []string{"rec-demo"}, cloudfl.deletedDNS)
+
+	require.Len(t, deployments.deployments, 2)
+	require.Equal(t, "shop", deployments.deployments[0].Subdomain)
+	require.Equal(t, "shop.example.com", deployments.deployments[0].Hostname)
+	require.Equal(t, "shop.example.com", deployments.deployments[1].Hostname)
+	require.Equal(t, 18081, deployments.deployments[1].Port)
+
+	logs := strings.Join(logger.lines, "\n")
+	require.Contains(t, logs, "hostname step verify: result=completed")
+	require.Contains(t, logs, "hostname step remove_old: result=completed removed_remote=1 removed_local=0 removed_dns=1")
+	require.Contains(t, logs, "hostname change completion summary: outcome=completed")
+}
+
+func TestProjectHostnameChangeKeepsPreviousWhenVerifyFails(t *testing.T) {
+	t.Parallel()
+
+	cloudfl := &stubHostnameCloudflareClient{
+		rules:     []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:18080"}},
+		dns:       map[string]cloudflare.DNSRecord{},
+		dnsTarget: "203.0.113.10",
+	}
+	workflows := &ProjectWorkflows{
+		cfg:      config.Config{Domain: "example.com", CloudflareZoneID: "zone-1"},
+		projects: &stubProjectRepository{},
+	}
+
+	err := workflows.runProjectHostnameChange(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)},
+		cloudfl,
+		"job-10",
+		hostnameChangeRequest(),
+	)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "previous hostnames were left in place")
+	require.Empty(t, cloudfl.removed)
+	require.Empty(t, cloudfl.deletedDNS)
+}
+
+func TestProjectHostnameQueueCapturesPreviousIngress(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte("services: {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(configPath, []byte(
+		"ingress:\n"+
+			"  - hostname: demo.example.com\n"+
+			"    path: ^/ssh(/|$)\n"+
+			"    service: ssh://localhost:2222\n"+
+			"  - hostname: demo.example.com\n"+
+			"    service: tcp://localhost:18080\n"+
+			"    originRequest:\n"+
+			"      connectTimeout: 5s\n"+
+			"  - hostname: taken.example.com\n"+
+			"    service: http://localhost:7070\n"+
+			"  - service: http_status:404\n",
+	), 0o644))
+
+	jobRepo := &archiveTestJobRepo{}
+	svc := NewProjectArchiveService(
+		config.Config{TemplatesDir: templatesDir, Domain: "example.com", CloudflaredConfig: configPath},
+		&archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, ProxyPort: 18080}}},
+		nil,
+		NewJobService(jobRepo, nil),
+		nil,
+	)
+
+	job, plan, err := svc.QueueHostnameChange(context.Background(), "demo", ProjectHostnameChangeRequest{Subdomain: "Shop"}, ProjectArchiveActor{UserID: 7, Login: "tester"})
+	require.NoError(t, err)
+	require.Equal(t, "shop.example.com", plan.Hostname)
+	require.Equal(t, 18080, plan.Port)
+	require.Equal(t, []string{"demo.example.com"}, plan.PreviousHostnames)
+
+	var payload ProjectHostnameJobRequest
+	require.NoError(t, json.Unmarshal([]byte(job.Input), &payload))
+	require.Equal(t, JobTypeProjectHostnames, job.Type)
+	require.Equal(t, "shop.example.com", payload.Hostname)
+	require.Equal(t, &ProjectIngressRequest{Scheme: "tcp", Origin: &cloudflare.IngressOrigin{ConnectTimeout: 5}}, payload.Ingress)
+	require.Equal(t, []ProjectHostnameRoute{{Path: "^/ssh(/|$)", Port: 2222, Scheme: "ssh"}}, payload.Routes)
+	require.Equal(t, []ProjectArchiveIngressDeleteTarget{
+		{Hostname: "demo.example.com", Service: "tcp://localhost:18080", Source: "local"},
+		{Hostname: "demo.example.com", Path: "^/ssh(/|$)", Service: "ssh://localhost:2222", Source: "local"},
+	}, payload.Previous.IngressRules)
+
+	_, _, err = svc.QueueHostnameChange(context.Background(), "demo", ProjectHostnameChangeRequest{Subdomain: "taken"}, ProjectArchiveActor{})
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectHostnameTaken, typed.Code)
+}
+
+func TestProjectHostnameChangeCarriesAccessToNewHostname(t *testing.T) {
+	t.Parallel()
+
+	rules := cloudflare.AccessRules{Emails: []string{"ops@example.com"}, GitHubOrgs: []string{"acme"}}
+	cloudfl := &stubHostnameCloudflareClient{
+		stubAccessClient: stubAccessClient{apps: map[string]*cloudflare.AccessApplication{
+			"demo.example.com": {ID: "app-demo", Name: cloudflare.AccessApplicationName("demo.example.com"), Domain: "demo.example.com", Managed: true, Rules: rules},
+		}},
+		rules:     []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:18080"}},
+		dns:       map[string]cloudflare.DNSRecord{},
+		dnsTarget: hostnameTestTunnelTarget,
+	}
+	workflows := &ProjectWorkflows{
+		cfg:      config.Config{Domain: "example.com", CloudflareZoneID: "zone-1"},
+		projects: &stubProjectRepository{},
+	}
+	logger := &captureWorkflowLogger{}
+
+	err := workflows.runProjectHostnameChange(
+		context.Background(),
+		logger,
+		config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)},
+		cloudfl,
+		"job-11",
+		hostnameChangeRequest(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]cloudflare.AccessRules{"shop.example.com": rules}, cloudfl.ensured)
+	require.Equal(t, []string{"app-demo"}, cloudfl.deleted)
+	require.Contains(t, strings.Join(logger.lines, "\n"), "hostname step access: result=completed carried=1")
+}
+
+func TestProjectHostnameChangeKeepsPreviousAccessWhenRemovalFails(t *testing.T) {
+	t.Parallel()
+
+	cloudfl := &stubHostnameCloudflareClient{
+		stubAccessClient: stubAccessClient{apps: map[string]*cloudflare.AccessApplication{
+			"demo.example.com": {ID: "app-demo", Domain: "demo.example.com", Managed: true, Rules: cloudflare.AccessRules{Emails: []string{"ops@example.com"}}},
+		}},
+		rules:     []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:18080"}},
+		dns:       map[string]cloudflare.DNSRecord{},
+		dnsTarget: hostnameTestTunnelTarget,
+	}
+	workflows := &ProjectWorkflows{
+		cfg:      config.Config{Domain: "example.com", CloudflareZoneID: "zone-1"},
+		projects: &stubProjectRepository{},
+	}
+	req := hostnameChangeRequest()
+	// A local rule in a missing cloudflared config cannot be removed.
+	req.Previous.IngressRules = append(req.Previous.IngressRules, ProjectArchiveIngressDeleteTarget{Hostname: "demo.example.com", Service: "http://localhost:18080", Source: "local"})
+
+	err := workflows.runProjectHostnameChange(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{CloudflaredConfig: filepath.Join(t.TempDir(), "missing.yml")},
+		cloudfl,
+		"job-12",
+		req,
+	)
+	require.NoError(t, err)
+	require.Contains(t, cloudfl.ensured, "shop.example.com")
+	require.Empty(t, cloudfl.deleted)
+}
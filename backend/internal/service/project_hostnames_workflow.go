@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-notes/internal/config"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+	"go-notes/internal/validate"
+)
+
+type projectHostnameCloudflareClient interface {
+	blueGreenIngressClient
+	projectAccessClient
+	RemoveIngressRules(ctx context.Context, targets []cloudflare.IngressRule) ([]cloudflare.IngressRule, error)
+	ExpectedTunnelCNAME(ctx context.Context) (string, error)
+	ListDNSRecordsByName(ctx context.Context, hostname, zoneID string) ([]cloudflare.DNSRecord, error)
+	DeleteTunnelCNAMERecord(ctx context.Context, zoneID, recordID, hostname, expectedTarget string) (cloudflare.DNSDeleteResult, error)
+}
+
+// SetDeploymentRepository records hostname changes on the project's
+// deployment rows.
+func (w *ProjectWorkflows) SetDeploymentRepository(deployments repository.DeploymentRepository) {
+	w.deployments = deployments
+}
+
+func (w *ProjectWorkflows) handleProjectHostnameChange(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req ProjectHostnameJobRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse hostname change request: %w", err)
+	}
+	runtimeCfg, err := w.resolveProjectRuntimeConfig(ctx, req.Project)
+	if err != nil {
+		return fmt.Errorf("resolve runtime config: %w", err)
+	}
+	return w.runProjectHostnameChange(ctx, logger, runtimeCfg, cloudflare.NewClient(runtimeCfg), fmt.Sprintf("job-%d", job.ID), req)
+}
+
+// runProjectHostnameChange adds the new hostname, verifies that its DNS record
+// and ingress rule are live, and only then removes the previous entries and
+// updates the deployment records. A failed add or verify leaves the previous
+// hostnames serving traffic.
+func (w *ProjectWorkflows) runProjectHostnameChange(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl projectHostnameCloudflareClient,
+	requestID string,
+	req ProjectHostnameJobRequest,
+) error {
+	req.Project = strings.ToLower(strings.TrimSpace(req.Project))
+	req.Hostname = strings.ToLower(strings.TrimSpace(req.Hostname))
+	if err := validate.ProjectName(req.Project); err != nil {
+		return err
+	}
+	if err := validate.Domain(req.Hostname); err != nil {
+		return err
+	}
+	if req.Port <= 0 {
+		return fmt.Errorf("hostname change for %s has no target port", req.Project)
+	}
+	warnings := make(map[string]struct{})
+
+	selection, err := w.resolveDomainSelection(ctx, cfg, req.Domain)
+	if err != nil {
+		return err
+	}
+	zoneID := strings.TrimSpace(selection.ZoneID)
+	if zoneID == "" {
+		zoneID = strings.TrimSpace(cfg.CloudflareZoneID)
+	}
+
+	previousHostnames := make([]string, 0, len(req.Previous.Hostnames))
+	for _, hostname := range dedupeHostnames(req.Previous.Hostnames) {
+		if hostname != "" && hostname != req.Hostname {
+			previousHostnames = append(previousHostnames, hostname)
+		}
+	}
+	// Access applications are bound to one hostname; protect the new one
+	// before it is routed so it is never public.
+	logProjectStepStart(logger, "hostname", "access", "hostname=%s previous=%d", req.Hostname, len(previousHostnames))
+	accessApps, err := carryProjectAccess(ctx, logger, cloudfl, previousHostnames, []string{req.Hostname}, warnings)
+	if err != nil {
+		logProjectStepResult(logger, "hostname", "access", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("protect %s: %w; previous hostnames were left in place", req.Hostname, err)
+	}
+	logProjectStepResult(logger, "hostname", "access", projectArchiveStepStatusCompleted, "carried=%d", len(accessApps))
+
+	logProjectStepStart(logger, "hostname", "add", "hostname=%s port=%d", req.Hostname, req.Port)
+	logger.Logf("configuring tunnel ingress for %s", req.Hostname)
+	if err := w.cloudflareSetupOptions(ctx, logger, cfg, cloudfl, requestID, req.Hostname, selection.Domain, zoneID, req.Port, req.Ingress.options()); err != nil {
+		logProjectStepResult(logger, "hostname", "add", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("add %s: %w", req.Hostname, err)
+	}
+	routes := sortProjectHostnameRoutes(req.Routes)
+	for _, route := range routes {
+		logger.Logf("configuring tunnel ingress for %s path %s", req.Hostname, route.Path)
+		opts := cloudflare.IngressRouteOptions{Scheme: route.Scheme, Origin: route.Origin}
+		if err := w.updateTunnelIngressRouteOptions(ctx, logger, cfg, cloudfl, requestID, req.Hostname, route.Path, route.Port, opts); err != nil {
+			logProjectStepResult(logger, "hostname", "add", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("add %s path %s: %w", req.Hostname, route.Path, err)
+		}
+	}
+	logProjectStepResult(logger, "hostname", "add", projectArchiveStepStatusCompleted, "hostname=%s path_routes=%d", req.Hostname, len(routes))
+
+	logProjectStepStart(logger, "hostname", "verify", "hostname=%s zone_id=%s", req.Hostname, describeSetting(zoneID))
+	verifyRoutes := append([]ProjectHostnameRoute{{Port: req.Port}}, routes...)
+	if err := verifyProjectHostname(ctx, cfg, cloudfl, req.Hostname, zoneID, verifyRoutes); err != nil {
+		logProjectStepResult(logger, "hostname", "verify", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("verify %s: %w; previous hostnames were left in place", req.Hostname, err)
+	}
+	logProjectStepResult(logger, "hostname", "verify", projectArchiveStepStatusCompleted, "dns=ok ingress=ok")
+
+	ingressTargets := make([]ProjectArchiveIngressDeleteTarget, 0, len(req.Previous.IngressRules))
+	for _, target := range normalizeIngressDeleteTargets(req.Previous.IngressRules) {
+		if target.Hostname != req.Hostname {
+			ingressTargets = append(ingressTargets, target)
+		}
+	}
+	dnsTargets := make([]ProjectArchiveDNSDeleteTarget, 0, len(req.Previous.DNSRecords))
+	for _, target := range dedupeDNSDeleteTargets(req.Previous.DNSRecords) {
+		if target.Hostname != req.Hostname {
+			dnsTargets = append(dnsTargets, target)
+		}
+	}
+	removal := w.removeProjectRouteTargets(ctx, logger, cfg, cloudfl, requestID, "hostname", len(req.Previous.Hostnames), ingressTargets, dnsTargets, warnings)
+	removedAccess := removePreviousProjectAccess(ctx, logger, cloudfl, removal, accessApps, warnings)
+
+	logProjectStepStart(logger, "hostname", "records", "project=%s deployments_enabled=%t", req.Project, w.deployments != nil)
+	recordsStatus, updatedRecords := w.recordProjectHostname(ctx, req, warnings)
+	logProjectStepResult(logger, "hostname", "records", recordsStatus, "updated=%d", updatedRecords)
+
+	sortedWarnings := sortedArchiveWarnings(warnings)
+	outcome := "completed"
+	if removal.status == projectArchiveStepStatusPartialFailure || recordsStatus == projectArchiveStepStatusPartialFailure {
+		outcome = "partial_failure"
+	} else if len(sortedWarnings) > 0 {
+		outcome = "completed_with_warnings"
+	}
+	if w.audit != nil {
+		if err := w.audit.Log(ctx, AuditEntry{
+			UserID:    req.RequestedBy.UserID,
+			UserLogin: req.RequestedBy.Login,
+			Action:    "project.hostnames.completed",
+			Target:    req.Project,
+			Metadata: map[string]any{
+				"project":           req.Project,
+				"hostname":          req.Hostname,
+				"port":              req.Port,
+				"previousHostnames": req.Previous.Hostnames,
+				"removedRemote":     removal.remote,
+				"removedLocal":      removal.local,
+				"removedDnsRecords": removal.dns,
+				"removedAccessApps": removedAccess,
+				"updatedRecords":    updatedRecords,
+				"outcome":           outcome,
+				"warnings":          sortedWarnings,
+			},
+		}); err != nil {
+			logger.Logf("audit warning: failed to write hostname change completion event: %v", err)
+		}
+	}
+
+	logger.Logf(
+		"hostname change completion summary: outcome=%s warnings=%d steps=add:%s verify:%s remove_old:%s records:%s",
+		outcome,
+		len(sortedWarnings),
+		projectArchiveStepStatusCompleted,
+		projectArchiveStepStatusCompleted,
+		removal.status,
+		recordsStatus,
+	)
+	for _, warning := range sortedWarnings {
+		logger.Logf("warning: %s", warning)
+	}
+	return nil
+}
+
+type projectRouteRemoval struct {
+	status projectArchiveStepStatus
+	remote int
+	local  int
+	dns    int
+}
+
+// removeProjectRouteTargets runs the remove_old step shared by hostname
+// changes and route updates: it deletes the given ingress rules and tunnel
+// CNAME records, recording failures as warnings so the new routes stay up.
+func (w *ProjectWorkflows) removeProjectRouteTargets(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl projectHostnameCloudflareClient,
+	requestID string,
+	workflow string,
+	hostnameCount int,
+	ingressTargets []ProjectArchiveIngressDeleteTarget,
+	dnsTargets []ProjectArchiveDNSDeleteTarget,
+	warnings map[string]struct{},
+) projectRouteRemoval {
+	remoteTargets := filterIngressDeleteTargetsBySource(ingressTargets, "remote")
+	localTargets := filterIngressDeleteTargetsBySource(ingressTargets, "local")
+	removal := projectRouteRemoval{}
+	removeFailed := false
+	logProjectStepStart(
+		logger,
+		workflow,
+		"remove_old",
+		"hostnames=%d remote_rules=%d local_rules=%d dns_records=%d",
+		hostnameCount,
+		len(remoteTargets),
+		len(localTargets),
+		len(dnsTargets),
+	)
+	if len(remoteTargets) > 0 {
+		removed, err := cloudfl.RemoveIngressRules(ctx, remoteTargets)
+		if err != nil && !errors.Is(err, cloudflare.ErrTunnelNotRemote) {
+			addArchiveWarning(warnings, fmt.Sprintf("remove remote ingress rules failed: %v", err))
+			removeFailed = true
+		}
+		removal.remote = len(removed)
+	}
+	if len(localTargets) > 0 {
+		removed, err := cloudflare.RemoveLocalIngressRules(cfg.CloudflaredConfig, localTargets)
+		if err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("remove local ingress rules failed: %v", err))
+			removeFailed = true
+		}
+		removal.local = len(removed)
+		if removal.local > 0 {
+			if err := w.restartTunnelForArchive(ctx, logger, requestID, cfg); err != nil {
+				addArchiveWarning(warnings, fmt.Sprintf("cloudflared restart after ingress cleanup failed: %v", err))
+				removeFailed = true
+			}
+		}
+	}
+	if len(dnsTargets) > 0 {
+		expectedTarget, err := cloudfl.ExpectedTunnelCNAME(ctx)
+		if err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("resolve tunnel dns target failed: %v", err))
+			removeFailed = true
+		}
+		expectedTarget = strings.ToLower(strings.TrimSpace(expectedTarget))
+		for _, target := range dnsTargets {
+			result, err := cloudfl.DeleteTunnelCNAMERecord(ctx, target.ZoneID, target.RecordID, target.Hostname, expectedTarget)
+			if err != nil {
+				addArchiveWarning(warnings, fmt.Sprintf("delete DNS record %s failed: %v", target.RecordID, err))
+				removeFailed = true
+				continue
+			}
+			if !result.Deleted {
+				addArchiveWarning(warnings, fmt.Sprintf("skip DNS record %s because %s", target.RecordID, result.SkipReason))
+				continue
+			}
+			logger.Logf("deleted Cloudflare DNS record %s for %s", target.RecordID, target.Hostname)
+			removal.dns++
+		}
+	}
+	removal.status = projectArchiveStepStatusCompleted
+	switch {
+	case removeFailed:
+		removal.status = projectArchiveStepStatusPartialFailure
+	case len(ingressTargets) == 0 && len(dnsTargets) == 0:
+		removal.status = projectArchiveStepStatusSkipped
+	}
+	logProjectStepResult(
+		logger,
+		workflow,
+		"remove_old",
+		removal.status,
+		"removed_remote=%d removed_local=%d removed_dns=%d",
+		removal.remote,
+		removal.local,
+		removal.dns,
+	)
+	return removal
+
+}
+
+// removePreviousProjectAccess deletes the Access applications carried over
+// from previous hostnames, but only once their routes were removed cleanly;
+// otherwise a previous hostname could still serve traffic without Access.
+func removePreviousProjectAccess(
+	ctx context.Context,
+	logger jobs.Logger,
+	client projectAccessClient,
+	removal projectRouteRemoval,
+	apps []cloudflare.AccessApplication,
+	warnings map[string]struct{},
+) int {
+	if len(apps) == 0 {
+		return 0
+	}
+	if removal.status != projectArchiveStepStatusCompleted {
+		addArchiveWarning(warnings, fmt.Sprintf("kept %d Access application(s) of previous hostnames because their routes were not removed", len(apps)))
+		return 0
+	}
+	return removeCarriedProjectAccess(ctx, logger, client, apps, warnings)
+}
+
+// verifyProjectHostname checks that the tunnel routes each hostname path to
+// its local port and that the hostname's DNS record is a CNAME to the tunnel.
+func verifyProjectHostname(
+	ctx context.Context,
+	cfg config.Config,
+	cloudfl projectHostnameCloudflareClient,
+	hostname string,
+	zoneID string,
+	routes []ProjectHostnameRoute,
+) error {
+	rules, err := cloudfl.ListIngressRules(ctx)
+	if errors.Is(err, cloudflare.ErrTunnelNotRemote) {
+		rules, err = cloudflare.ListLocalIngressRules(cfg.CloudflaredConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("list ingress rules: %w", err)
+	}
+	for _, route := range routes {
+		routed := false
+		for _, rule := range rules {
+			if !strings.EqualFold(strings.TrimSpace(rule.Hostname), hostname) || strings.TrimSpace(rule.Path) != route.Path {
+				continue
+			}
+			if rulePort, ok := ingressServiceLocalPort(rule.Service); ok && rulePort == route.Port {
+				routed = true
+				break
+			}
+		}
+		if !routed {
+			return fmt.Errorf("no ingress rule routes %s to localhost:%d", describeIngressRoute(cloudflare.IngressRule{Hostname: hostname, Path: route.Path}), route.Port)
+		}
+	}
+
+	expectedTarget, err := cloudfl.ExpectedTunnelCNAME(ctx)
+	if err != nil {
+		return fmt.Errorf("resolve tunnel dns target: %w", err)
+	}
+	records, err := cloudfl.ListDNSRecordsByName(ctx, hostname, zoneID)
+	if err != nil {
+		return fmt.Errorf("list DNS records: %w", err)
+	}
+	for _, record := range records {
+		if strings.EqualFold(strings.TrimSpace(record.Type), "CNAME") &&
+			strings.EqualFold(strings.TrimSpace(record.Content), strings.TrimSpace(expectedTarget)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no CNAME record points %s at %s", hostname, expectedTarget)
+}
+
+// recordProjectHostname moves the deployment rows that carried a previous
+// hostname (or none) to the new one, creating a row when the project has
+// none yet.
+func (w *ProjectWorkflows) recordProjectHostname(
+	ctx context.Context,
+	req ProjectHostnameJobRequest,
+	warnings map[string]struct{},
+) (projectArchiveStepStatus, int) {
+	if w.deployments == nil {
+		return projectArchiveStepStatusSkipped, 0
+	}
+	record, err := lookupProjectRecord(ctx, w.projects, req.Project)
+	if err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("deployment update skipped: resolve project record failed: %v", err))
+		return projectArchiveStepStatusPartialFailure, 0
+	}
+	if record == nil {
+		addArchiveWarning(warnings, fmt.Sprintf("deployment update skipped: no project database row found for %s", req.Project))
+		return projectArchiveStepStatusPartialFailure, 0
+	}
+	deployments, err := w.deployments.ListByProject(ctx, record.ID)
+	if err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("deployment update skipped: list deployments failed: %v", err))
+		return projectArchiveStepStatusPartialFailure, 0
+	}
+
+	previous := stringSliceSet(dedupeHostnames(req.Previous.Hostnames))
+	updated := 0
+	for i := range deployments {
+		deployment := deployments[i]
+		hostname := strings.ToLower(strings.TrimSpace(deployment.Hostname))
+		if _, ok := previous[hostname]; !ok && hostname != "" && hostname != req.Hostname {
+			continue
+		}
+		deployment.Subdomain = req.Subdomain
+		deployment.Hostname = req.Hostname
+		if strings.TrimSpace(deployment.Path) == "" {
+			deployment.Port = req.Port
+		}
+		if err := w.deployments.Save(ctx, &deployment); err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("deployment %d update failed: %v", deployment.ID, err))
+			return projectArchiveStepStatusPartialFailure, updated
+		}
+		updated++
+	}
+	if updated == 0 {
+		deployment := models.Deployment{
+			ProjectID: record.ID,
+			Subdomain: req.Subdomain,
+			Hostname:  req.Hostname,
+			Port:      req.Port,
+			State:     strings.TrimSpace(record.Status),
+		}
+		if err := w.deployments.Save(ctx, &deployment); err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("deployment create failed: %v", err))
+			return projectArchiveStepStatusPartialFailure, 0
+		}
+		updated++
+	}
+	return projectArchiveStepStatusCompleted, updated
+}
+
+// sortProjectHostnameRoutes orders path rules longest first so a nested
+// prefix such as /api/v2 is matched before /api.
+func sortProjectHostnameRoutes(routes []ProjectHostnameRoute) []ProjectHostnameRoute {
+	result := make([]ProjectHostnameRoute, 0, len(routes))
+	for _, route := range routes {
+		route.Path = strings.TrimSpace(route.Path)
+		if route.Path == "" || route.Port <= 0 {
+			continue
+		}
+		result = append(result, route)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if len(result[i].Path) != len(result[j].Path) {
+			return len(result[i].Path) > len(result[j].Path)
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
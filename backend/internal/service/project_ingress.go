@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+	"go-notes/internal/validate"
+)
+
+// ProjectIngressRequest sets the service scheme and origin options of a new
+// project's tunnel rule. An empty scheme means http.
+type ProjectIngressRequest struct {
+	Scheme string                    `json:"scheme,omitempty"`
+	Origin *cloudflare.IngressOrigin `json:"origin,omitempty"`
+}
+
+func (r *ProjectIngressRequest) options() cloudflare.IngressRouteOptions {
+	if r == nil {
+		return cloudflare.IngressRouteOptions{}
+	}
+	return cloudflare.IngressRouteOptions{Scheme: r.Scheme, Origin: r.Origin}
+}
+
+// normalizeProjectIngressRequest validates ingress options in place so a bad
+// option fails the request instead of the job.
+func normalizeProjectIngressRequest(req *ProjectIngressRequest) error {
+	if req == nil {
+		return nil
+	}
+	scheme, origin, err := normalizeProjectIngressOptions(req.Scheme, req.Origin)
+	if err != nil {
+		return err
+	}
+	req.Scheme = scheme
+	req.Origin = origin
+	return nil
+}
+
+func normalizeProjectIngressOptions(scheme string, origin *cloudflare.IngressOrigin) (string, *cloudflare.IngressOrigin, error) {
+	scheme, err := cloudflare.NormalizeIngressScheme(scheme)
+	if err != nil {
+		return "", nil, errs.Wrap(errs.CodeProjectIngressInvalid, err.Error(), err)
+	}
+	if origin == nil {
+		return scheme, nil, nil
+	}
+	normalized, err := cloudflare.NormalizeIngressOrigin(*origin)
+	if err != nil {
+		return "", nil, errs.Wrap(errs.CodeProjectIngressInvalid, err.Error(), err)
+	}
+	return scheme, &normalized, nil
+}
+
+// ProjectIngressUpdate changes the scheme and origin options of one project
+// ingress rule. Path is the URL prefix of the route; an empty Scheme or a nil
+// Origin keeps the rule's current value.
+type ProjectIngressUpdate struct {
+	Hostname string                    `json:"hostname"`
+	Path     string                    `json:"path,omitempty"`
+	Scheme   string                    `json:"scheme,omitempty"`
+	Origin   *cloudflare.IngressOrigin `json:"origin,omitempty"`
+}
+
+// ProjectIngressJobRequest is the job input for updating the options of a
+// project ingress rule. Path is the cloudflared path regex of the rule.
+type ProjectIngressJobRequest struct {
+	Project     string                    `json:"project"`
+	Hostname    string                    `json:"hostname"`
+	Path        string                    `json:"path,omitempty"`
+	Port        int                       `json:"port"`
+	Scheme      string                    `json:"scheme,omitempty"`
+	Origin      *cloudflare.IngressOrigin `json:"origin,omitempty"`
+	Targets     ProjectRoutesTargets      `json:"targets"`
+	RequestedBy ProjectArchiveActor       `json:"requestedBy"`
+}
+
+// Ingress lists the tunnel ingress rules serving the project's hostnames,
+// with their service and origin options.
+func (s *ProjectArchiveService) Ingress(ctx context.Context, projectName string) ([]ProjectArchivePlanIngress, []string, error) {
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings := make(map[string]struct{})
+	hostnames := s.discoverHostnames(ctx, resolved.NormalizedName, normalizeDomain(runtimeCfg.Domain), warnings)
+	rules := s.planIngress(ctx, runtimeCfg, cloudflare.NewClient(runtimeCfg), hostnames, warnings)
+	return rules, sortedArchiveWarnings(warnings), nil
+}
+
+// QueueIngress queues a job that rewrites the scheme and origin options of
+// one of the project's ingress rules. The rule keeps its local port.
+func (s *ProjectArchiveService) QueueIngress(
+	ctx context.Context,
+	projectName string,
+	update ProjectIngressUpdate,
+	actor ProjectArchiveActor,
+) (*models.Job, ProjectIngressJobRequest, error) {
+	if s.jobs == nil {
+		return nil, ProjectIngressJobRequest{}, fmt.Errorf("job service unavailable")
+	}
+	scheme, origin, err := normalizeProjectIngressOptions(update.Scheme, update.Origin)
+	if err != nil {
+		return nil, ProjectIngressJobRequest{}, err
+	}
+	if scheme == "" && origin == nil {
+		return nil, ProjectIngressJobRequest{}, errs.New(errs.CodeProjectIngressInvalid, "scheme or origin is required")
+	}
+	hostname := strings.ToLower(strings.TrimSpace(update.Hostname))
+	if err := validate.Domain(hostname); err != nil {
+		return nil, ProjectIngressJobRequest{}, err
+	}
+	routePath := normalizeRoutePath(update.Path)
+	if err := validate.RoutePath(routePath); err != nil {
+		return nil, ProjectIngressJobRequest{}, err
+	}
+
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
+	if err != nil {
+		return nil, ProjectIngressJobRequest{}, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, ProjectIngressJobRequest{}, err
+	}
+	project := resolved.NormalizedName
+	owned := stringSliceSet(s.discoverHostnames(ctx, project, normalizeDomain(runtimeCfg.Domain), map[string]struct{}{}))
+	if _, ok := owned[hostname]; !ok {
+		return nil, ProjectIngressJobRequest{}, errs.New(errs.CodeProjectIngressNotFound, fmt.Sprintf("%s is not a hostname of this project", hostname))
+	}
+
+	pattern := cloudflare.IngressPathPattern(routePath)
+	rule, err := selectProjectIngressRule(s.planIngress(ctx, runtimeCfg, cloudflare.NewClient(runtimeCfg), []string{hostname}, map[string]struct{}{}), hostname, pattern)
+	if err != nil {
+		return nil, ProjectIngressJobRequest{}, err
+	}
+	port, ok := ingressServiceLocalPort(rule.Service)
+	if !ok {
+		return nil, ProjectIngressJobRequest{}, errs.New(errs.CodeProjectIngressInvalid, fmt.Sprintf("%s routes to %s, which is not a local port", describeIngressRoute(cloudflare.IngressRule{Hostname: hostname, Path: pattern}), rule.Service))
+	}
+
+	req := ProjectIngressJobRequest{
+		Project:     project,
+		Hostname:    hostname,
+		Path:        pattern,
+		Port:        port,
+		Scheme:      scheme,
+		Origin:      origin,
+		Targets:     ProjectRoutesTargets{Hostnames: []string{hostname}},
+		RequestedBy: actor,
+	}
+	job, err := s.jobs.Create(ctx, JobTypeProjectIngress, req)
+	if err != nil {
+		return nil, ProjectIngressJobRequest{}, err
+	}
+	return job, req, nil
+}
+
+// selectProjectIngressRule picks the rule for hostname and pattern, preferring
+// the remote rule because the job updates the remote config first.
+func selectProjectIngressRule(rules []ProjectArchivePlanIngress, hostname, pattern string) (ProjectArchivePlanIngress, error) {
+	matches := make([]ProjectArchivePlanIngress, 0, 2)
+	for _, rule := range rules {
+		if rule.Hostname == hostname && strings.TrimSpace(rule.Path) == pattern {
+			matches = append(matches, rule)
+		}
+	}
+	if len(matches) == 0 {
+		return ProjectArchivePlanIngress{}, errs.New(errs.CodeProjectIngressNotFound, fmt.Sprintf("no ingress rule serves %s", describeIngressRoute(cloudflare.IngressRule{Hostname: hostname, Path: pattern})))
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Source == "remote" && matches[j].Source != "remote"
+	})
+	return matches[0], nil
+}
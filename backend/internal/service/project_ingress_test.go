@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+)
+
+type stubIngressOptionsClient struct {
+	hostname string
+	path     string
+	port     int
+	opts     cloudflare.IngressRouteOptions
+	err      error
+}
+
+func (s *stubIngressOptionsClient) EnsureDNSForZone(_ context.Context, _ string, _ string) error {
+	return nil
+}
+
+func (s *stubIngressOptionsClient) UpdateIngressRouteOptions(_ context.Context, hostname, path string, port int, opts cloudflare.IngressRouteOptions) error {
+	s.hostname, s.path, s.port, s.opts = hostname, path, port, opts
+	return s.err
+}
+
+func TestNormalizeProjectIngressRequest(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, normalizeProjectIngressRequest(nil))
+
+	req := &ProjectIngressRequest{Scheme: "SSH", Origin: &cloudflare.IngressOrigin{HTTPHostHeader: " App.Internal "}}
+	require.NoError(t, normalizeProjectIngressRequest(req))
+	require.Equal(t, "ssh", req.Scheme)
+	require.Equal(t, "app.internal", req.Origin.HTTPHostHeader)
+
+	for _, bad := range []*ProjectIngressRequest{
+		{Scheme: "udp"},
+		{Origin: &cloudflare.IngressOrigin{ConnectTimeout: 900}},
+	} {
+		err := normalizeProjectIngressRequest(bad)
+		appErr, ok := errs.From(err)
+		require.True(t, ok)
+		require.Equal(t, errs.CodeProjectIngressInvalid, appErr.Code)
+	}
+}
+
+func TestSelectProjectIngressRulePrefersRemote(t *testing.T) {
+	t.Parallel()
+
+	rules := []ProjectArchivePlanIngress{
+		{Hostname: "app.example.com", Service: "http://localhost:8080", Source: "local"},
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:9090", Source: "remote"},
+		{Hostname: "app.example.com", Service: "http://localhost:8080", Source: "remote"},
+	}
+	rule, err := selectProjectIngressRule(rules, "app.example.com", "")
+	require.NoError(t, err)
+	require.Equal(t, "remote", rule.Source)
+	require.Equal(t, "http://localhost:8080", rule.Service)
+
+	_, err = selectProjectIngressRule(rules, "app.example.com", "^/admin(/|$)")
+	appErr, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectIngressNotFound, appErr.Code)
+}
+
+func TestRunProjectIngressUpdatesRuleInPlace(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte(
+		"ingress:\n"+
+			"  - hostname: db.example.com\n"+
+			"    service: http://localhost:5432\n"+
+			"    originRequest:\n"+
+			"      proxyType: socks\n"+
+			"  - service: http_status:404\n",
+	), 0o644))
+
+	client := &stubIngressOptionsClient{}
+	logger := &archiveTestLogger{}
+	workflows := &ProjectWorkflows{}
+	err := workflows.runProjectIngress(context.Background(), logger, config.Config{CloudflaredConfig: configPath}, client, "job-7", ProjectIngressJobRequest{
+		Project:  "db",
+		Hostname: "DB.example.com",
+		Port:     5432,
+		Scheme:   "tcp",
+		Origin:   &cloudflare.IngressOrigin{ConnectTimeout: 10},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "db.example.com", client.hostname)
+	require.Equal(t, 5432, client.port)
+	require.Equal(t, "tcp", client.opts.Scheme)
+	require.Equal(t, 10, client.opts.Origin.ConnectTimeout)
+
+	rules, err := cloudflare.ListLocalIngressRules(configPath)
+	require.NoError(t, err)
+	require.Equal(t, []cloudflare.IngressRule{{
+		Hostname: "db.example.com",
+		Service:  "tcp://localhost:5432",
+		Origin:   &cloudflare.IngressOrigin{ConnectTimeout: 10},
+	}}, rules)
+	requireArchiveLogContains(t, logger.lines, "scheme=tcp origin=true")
+}
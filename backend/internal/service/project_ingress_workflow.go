@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-notes/internal/config"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/validate"
+)
+
+func (w *ProjectWorkflows) handleProjectIngress(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req ProjectIngressJobRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse ingress update request: %w", err)
+	}
+	runtimeCfg, err := w.resolveProjectRuntimeConfig(ctx, req.Project)
+	if err != nil {
+		return fmt.Errorf("resolve runtime config: %w", err)
+	}
+	return w.runProjectIngress(ctx, logger, runtimeCfg, cloudflare.NewClient(runtimeCfg), fmt.Sprintf("job-%d", job.ID), req)
+}
+
+// runProjectIngress rewrites the scheme and origin options of one ingress
+// rule in place. The rule keeps its port, its position, and any originRequest
+// keys gungnr does not manage.
+func (w *ProjectWorkflows) runProjectIngress(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl cloudflareWorkflowClient,
+	requestID string,
+	req ProjectIngressJobRequest,
+) error {
+	req.Hostname = strings.ToLower(strings.TrimSpace(req.Hostname))
+	if err := validate.Domain(req.Hostname); err != nil {
+		return err
+	}
+	if err := validate.Port(req.Port); err != nil {
+		return err
+	}
+	scheme, origin, err := normalizeProjectIngressOptions(req.Scheme, req.Origin)
+	if err != nil {
+		return err
+	}
+
+	rule := cloudflare.IngressRule{Hostname: req.Hostname, Path: req.Path}
+	describedScheme := scheme
+	if describedScheme == "" {
+		describedScheme = "unchanged"
+	}
+	logProjectStepStart(logger, "ingress", "update", "route=%s port=%d scheme=%s origin=%t", describeIngressRoute(rule), req.Port, describedScheme, origin != nil)
+	opts := cloudflare.IngressRouteOptions{Scheme: scheme, Origin: origin}
+	if err := w.updateTunnelIngressRouteOptions(ctx, logger, cfg, cloudfl, requestID, req.Hostname, req.Path, req.Port, opts); err != nil {
+		logProjectStepResult(logger, "ingress", "update", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("update %s: %w", describeIngressRoute(rule), err)
+	}
+	logProjectStepResult(logger, "ingress", "update", projectArchiveStepStatusCompleted, "route=%s", describeIngressRoute(rule))
+
+	if w.audit != nil {
+		if err := w.audit.Log(ctx, AuditEntry{
+			UserID:    req.RequestedBy.UserID,
+			UserLogin: req.RequestedBy.Login,
+			Action:    "project.ingress.completed",
+			Target:    req.Project,
+			Metadata: map[string]any{
+				"project":  req.Project,
+				"hostname": req.Hostname,
+				"path":     req.Path,
+				"scheme":   scheme,
+				"origin":   origin,
+			},
+		}); err != nil {
+			logger.Logf("audit warning: failed to write ingress update completion event: %v", err)
+		}
+	}
+	return nil
+}
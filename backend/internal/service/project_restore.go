@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+)
+
+const (
+	projectArchiveManifestVersion     = 1
+	projectArchiveManifestRelative    = ".gungnr/archive/manifest.json"
+	projectArchiveEnvBackupRelative   = ".gungnr/archive/env.backup"
+	projectRestoreUnrestorableVolumes = "volumes were removed during archive; services start with empty volumes"
+	projectRestoreUnrestorableEnv     = ".env is missing and its archive backup no longer exists"
+	projectRestoreUnrestorableBackup  = "volumes were removed during archive and the pre-archive volume backup no longer exists; services start with empty volumes"
+)
+
+// ProjectArchiveManifest is written to the project directory when a project
+// is archived and holds what a restore needs to bring it back.
+type ProjectArchiveManifest struct {
+	Version           int                            `json:"version"`
+	Project           string                         `json:"project"`
+	ArchivedAt        time.Time                      `json:"archivedAt"`
+	ProjectDir        string                         `json:"projectDir"`
+	ComposeFiles      []string                       `json:"composeFiles"`
+	ProxyPort         int                            `json:"proxyPort"`
+	Routes            []ProjectArchiveManifestRoute  `json:"routes"`
+	DNSRecords        []ProjectArchiveManifestDNS    `json:"dnsRecords"`
+	Access            []ProjectArchiveManifestAccess `json:"access,omitempty"`
+	Containers        []string                       `json:"containers"`
+	ExposureHostnames []string                       `json:"exposureHostnames,omitempty"`
+	VolumesRemoved    bool                           `json:"volumesRemoved"`
+	VolumeBackupID    string                         `json:"volumeBackupId,omitempty"`
+	WorkbenchRevision int                            `json:"workbenchRevision,omitempty"`
+	EnvBackupPath     string                         `json:"envBackupPath,omitempty"`
+}
+
+// ProjectArchiveManifestRoute is an ingress rule removed by the archive. Path
+// is the cloudflared path regex; Scheme and Origin are the rule's service
+// scheme and originRequest options.
+type ProjectArchiveManifestRoute struct {
+	Hostname string                    `json:"hostname"`
+	Path     string                    `json:"path,omitempty"`
+	Port     int                       `json:"port"`
+	Scheme   string                    `json:"scheme,omitempty"`
+	Origin   *cloudflare.IngressOrigin `json:"origin,omitempty"`
+}
+
+// ProjectArchiveManifestAccess is a managed Access application removed by the
+// archive, with the rules it allowed.
+type ProjectArchiveManifestAccess struct {
+	Hostname string                 `json:"hostname"`
+	Rules    cloudflare.AccessRules `json:"rules"`
+}
+
+// ProjectArchiveManifestDNS is a tunnel CNAME removed by the archive.
+type ProjectArchiveManifestDNS struct {
+	Hostname string `json:"hostname"`
+	ZoneID   string `json:"zoneId"`
+}
+
+// ProjectRestorePlan describes what a restore re-creates and what it no
+// longer can.
+type ProjectRestorePlan struct {
+	Project      string                        `json:"project"`
+	ArchivedAt   time.Time                     `json:"archivedAt"`
+	ComposeFiles []string                      `json:"composeFiles"`
+	Routes       []ProjectArchiveManifestRoute `json:"routes"`
+	DNSRecords   []ProjectArchiveManifestDNS   `json:"dnsRecords"`
+	// Access lists the hostnames put back behind Cloudflare Access before
+	// their routes are re-created.
+	Access     []ProjectArchiveManifestAccess `json:"access"`
+	RestoreEnv bool                           `json:"restoreEnv"`
+	// VolumeBackupID is the pre-archive volume backup the restore puts back.
+	VolumeBackupID string   `json:"volumeBackupId,omitempty"`
+	Unrestorable   []string `json:"unrestorable"`
+}
+
+type ProjectRestoreJobRequest struct {
+	Project        string                 `json:"project"`
+	Manifest       ProjectArchiveManifest `json:"manifest"`
+	RestoreEnv     bool                   `json:"restoreEnv"`
+	VolumeBackupID string                 `json:"volumeBackupId,omitempty"`
+	Unrestorable   []string               `json:"unrestorable"`
+	PlannedAt      time.Time              `json:"plannedAt"`
+	RequestedBy    ProjectArchiveActor    `json:"requestedBy"`
+}
+
+// QueueRestore reads the archive manifest of an archived project and queues
+// the job that brings its stack, DNS records, and ingress rules back.
+func (s *ProjectArchiveService) QueueRestore(
+	ctx context.Context,
+	projectName string,
+	actor ProjectArchiveActor,
+) (*models.Job, ProjectRestorePlan, error) {
+	if s.jobs == nil {
+		return nil, ProjectRestorePlan{}, fmt.Errorf("job service unavailable")
+	}
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
+	if err != nil {
+		return nil, ProjectRestorePlan{}, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, ProjectRestorePlan{}, err
+	}
+	project := resolved.NormalizedName
+	if resolved.ProjectRecord == nil || !strings.EqualFold(strings.TrimSpace(resolved.ProjectRecord.Status), "archived") {
+		return nil, ProjectRestorePlan{}, errs.New(errs.CodeProjectNotArchived, fmt.Sprintf("project %s is not archived", project))
+	}
+	manifest, err := loadProjectArchiveManifest(resolved.ProjectDir)
+	if err != nil {
+		return nil, ProjectRestorePlan{}, err
+	}
+	if len(resolved.ComposeFiles) == 0 {
+		return nil, ProjectRestorePlan{}, errs.New(errs.CodeProjectRestoreBlocked, "compose file no longer exists; the stack cannot be started")
+	}
+
+	plan := ProjectRestorePlan{
+		Project:      project,
+		ArchivedAt:   manifest.ArchivedAt,
+		ComposeFiles: append([]string{}, resolved.ComposeFiles...),
+		Routes:       append([]ProjectArchiveManifestRoute{}, manifest.Routes...),
+		DNSRecords:   append([]ProjectArchiveManifestDNS{}, manifest.DNSRecords...),
+		Access:       append([]ProjectArchiveManifestAccess{}, manifest.Access...),
+		Unrestorable: []string{},
+	}
+	if manifest.VolumesRemoved {
+		switch {
+		case manifest.VolumeBackupID == "":
+			plan.Unrestorable = append(plan.Unrestorable, projectRestoreUnrestorableVolumes)
+		case s.host == nil:
+			plan.Unrestorable = append(plan.Unrestorable, projectRestoreUnrestorableBackup)
+		default:
+			if _, err := s.host.GetProjectVolumeBackup(project, manifest.VolumeBackupID); err != nil {
+				plan.Unrestorable = append(plan.Unrestorable, projectRestoreUnrestorableBackup)
+			} else {
+				plan.VolumeBackupID = manifest.VolumeBackupID
+			}
+		}
+	}
+	if backup := strings.TrimSpace(manifest.EnvBackupPath); backup != "" && !resolved.EnvExists {
+		if exists, _, _ := envFileInfo(backup); exists && isPathWithinBase(resolved.ProjectDir, backup) {
+			plan.RestoreEnv = true
+		} else {
+			plan.Unrestorable = append(plan.Unrestorable, projectRestoreUnrestorableEnv)
+		}
+	}
+	for _, hostname := range manifest.ExposureHostnames {
+		plan.Unrestorable = append(plan.Unrestorable, fmt.Sprintf("service exposure %s is not restored; re-create the forward_local or quick_service exposure", hostname))
+	}
+
+	job, err := s.jobs.Create(ctx, JobTypeProjectRestore, ProjectRestoreJobRequest{
+		Project:        project,
+		Manifest:       manifest,
+		RestoreEnv:     plan.RestoreEnv,
+		VolumeBackupID: plan.VolumeBackupID,
+		Unrestorable:   plan.Unrestorable,
+		PlannedAt:      time.Now().UTC(),
+		RequestedBy:    actor,
+	})
+	if err != nil {
+		return nil, ProjectRestorePlan{}, err
+	}
+	return job, plan, nil
+}
+
+func loadProjectArchiveManifest(projectDir string) (ProjectArchiveManifest, error) {
+	manifestPath, err := resolveWorkbenchComposeBackupArtifactPath(projectDir, projectArchiveManifestRelative)
+	if err != nil {
+		return ProjectArchiveManifest{}, err
+	}
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ProjectArchiveManifest{}, errs.New(errs.CodeProjectRestoreNoManifest, "project has no archive manifest to restore from")
+		}
+		return ProjectArchiveManifest{}, errs.Wrap(errs.CodeProjectRestoreFailed, "failed to read archive manifest", err)
+	}
+	var manifest ProjectArchiveManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return ProjectArchiveManifest{}, errs.Wrap(errs.CodeProjectRestoreFailed, "archive manifest is not valid JSON", err)
+	}
+	if manifest.Version != projectArchiveManifestVersion {
+		return ProjectArchiveManifest{}, errs.New(errs.CodeProjectRestoreFailed, fmt.Sprintf("unsupported archive manifest version %d", manifest.Version))
+	}
+	return manifest, nil
+}
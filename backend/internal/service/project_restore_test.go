@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+)
+
+func writeRestoreTestProject(t *testing.T) (string, string) {
+	t.Helper()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte("services: {}\n"), 0o644))
+	return templatesDir, projectDir
+}
+
+func writeRestoreTestManifest(t *testing.T, projectDir string, manifest ProjectArchiveManifest) {
+	t.Helper()
+	content, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	path := filepath.Join(projectDir, filepath.FromSlash(projectArchiveManifestRelative))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+}
+
+func TestHandleProjectArchiveWritesRestoreManifest(t *testing.T) {
+	t.Parallel()
+
+	templatesDir, projectDir := writeRestoreTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".env"), []byte("TOKEN=abc\n"), 0o600))
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte(
+		"ingress:\n"+
+			"  - hostname: app.example.com\n"+
+			"    path: ^/api(/|$)\n"+
+			"    service: http://localhost:8080\n"+
+			"  - hostname: app.example.com\n"+
+			"    service: http://localhost:3000\n"+
+			"  - hostname: share.example.com\n"+
+			"    service: http://localhost:9000\n"+
+			"  - service: http_status:404\n",
+	), 0o644))
+
+	req := ProjectArchiveJobRequest{
+		Project: "demo",
+		Options: ProjectArchiveOptions{RemoveContainers: true, RemoveVolumes: true, RemoveIngress: true, RemoveAccess: true},
+		Targets: ProjectArchiveTargets{
+			Hostnames:         []string{"app.example.com", "share.example.com"},
+			ExposureHostnames: []string{"share.example.com"},
+			IngressRules: []ProjectArchiveIngressDeleteTarget{
+				{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080", Source: "local", Origin: &cloudflare.IngressOrigin{ConnectTimeout: 5}},
+				{Hostname: "app.example.com", Service: "tcp://localhost:3000", Source: "local"},
+				{Hostname: "share.example.com", Service: "http://localhost:9000", Source: "local"},
+			},
+			AccessApps: []ProjectArchiveAccessDeleteTarget{
+				{AppID: "app-1", Hostname: "app.example.com", Rules: cloudflare.AccessRules{EmailDomains: []string{"example.com"}}},
+			},
+		},
+	}
+	payload, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	workflows := &ProjectWorkflows{
+		cfg:         config.Config{TemplatesDir: templatesDir, CloudflaredConfig: configPath},
+		projects:    &archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, ProxyPort: 3000, Status: "running"}}},
+		infraClient: &archiveTestProjectInfraClient{},
+		fileClient:  &stubProjectFileMutationClient{},
+	}
+	logger := &archiveTestLogger{}
+	require.NoError(t, workflows.handleProjectArchive(context.Background(), models.Job{Input: string(payload)}, logger))
+
+	manifest, err := loadProjectArchiveManifest(projectDir)
+	require.NoError(t, err)
+	require.Equal(t, "demo", manifest.Project)
+	require.Equal(t, 3000, manifest.ProxyPort)
+	require.True(t, manifest.VolumesRemoved)
+	require.ElementsMatch(t, []ProjectArchiveManifestRoute{
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Port: 8080, Scheme: "http", Origin: &cloudflare.IngressOrigin{ConnectTimeout: 5}},
+		{Hostname: "app.example.com", Port: 3000, Scheme: "tcp"},
+	}, manifest.Routes)
+	require.Equal(t, []string{"share.example.com"}, manifest.ExposureHostnames)
+	require.Equal(t, []ProjectArchiveManifestAccess{
+		{Hostname: "app.example.com", Rules: cloudflare.AccessRules{EmailDomains: []string{"example.com"}}},
+	}, manifest.Access)
+
+	backup, err := os.ReadFile(manifest.EnvBackupPath)
+	require.NoError(t, err)
+	require.Equal(t, "TOKEN=abc\n", string(backup))
+	requireArchiveLogContains(t, logger.lines, "archive step manifest: result=completed")
+	// The warnings are the missing host service and the Access delete without
+	// credentials; the manifest adds none.
+	requireArchiveLogContains(t, logger.lines, "archive completion summary: outcome=partial_failure warnings=2")
+}
+
+func TestProjectRestoreQueueReportsUnrestorableItems(t *testing.T) {
+	t.Parallel()
+
+	templatesDir, projectDir := writeRestoreTestProject(t)
+	backupPath := filepath.Join(projectDir, filepath.FromSlash(projectArchiveEnvBackupRelative))
+	writeRestoreTestManifest(t, projectDir, ProjectArchiveManifest{
+		Version:           projectArchiveManifestVersion,
+		Project:           "demo",
+		ArchivedAt:        time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Routes:            []ProjectArchiveManifestRoute{{Hostname: "demo.example.com", Port: 3000}},
+		VolumesRemoved:    true,
+		ExposureHostnames: []string{"share.example.com"},
+		EnvBackupPath:     backupPath,
+	})
+	require.NoError(t, os.WriteFile(backupPath, []byte("TOKEN=abc\n"), 0o600))
+
+	projects := &archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, Status: "archived"}}}
+	jobRepo := &archiveTestJobRepo{}
+	svc := NewProjectArchiveService(config.Config{TemplatesDir: templatesDir}, projects, nil, NewJobService(jobRepo, nil), nil)
+
+	job, plan, err := svc.QueueRestore(context.Background(), "demo", ProjectArchiveActor{UserID: 7, Login: "tester"})
+	require.NoError(t, err)
+	require.Equal(t, JobTypeProjectRestore, job.Type)
+	require.True(t, plan.RestoreEnv)
+	require.Equal(t, []string{
+		projectRestoreUnrestorableVolumes,
+		"service exposure share.example.com is not restored; re-create the forward_local or quick_service exposure",
+	}, plan.Unrestorable)
+
+	var payload ProjectRestoreJobRequest
+	require.NoError(t, json.Unmarshal([]byte(job.Input), &payload))
+	require.Equal(t, "demo", payload.Project)
+	require.Equal(t, backupPath, payload.Manifest.EnvBackupPath)
+
+	require.NoError(t, os.Remove(backupPath))
+	_, plan, err = svc.QueueRestore(context.Background(), "demo", ProjectArchiveActor{})
+	require.NoError(t, err)
+	require.False(t, plan.RestoreEnv)
+	require.Contains(t, plan.Unrestorable, projectRestoreUnrestorableEnv)
+
+	projects.projects[0].Status = "running"
+	_, _, err = svc.QueueRestore(context.Background(), "demo", ProjectArchiveActor{})
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectNotArchived, typed.Code)
+
+	projects.projects[0].Status = "archived"
+	require.NoError(t, os.Remove(filepath.Join(projectDir, filepath.FromSlash(projectArchiveManifestRelative))))
+	_, _, err = svc.QueueRestore(context.Background(), "demo", ProjectArchiveActor{})
+	typed, ok = errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectRestoreNoManifest, typed.Code)
+}
+
+func TestProjectRestoreBringsStackAndRoutesBack(t *testing.T) {
+	t.Parallel()
+
+	templatesDir, projectDir := writeRestoreTestProject(t)
+	backupPath := filepath.Join(projectDir, filepath.FromSlash(projectArchiveEnvBackupRelative))
+	require.NoError(t, os.MkdirAll(filepath.Dir(backupPath), 0o755))
+	require.NoError(t, os.WriteFile(backupPath, []byte("TOKEN=abc\n"), 0o600))
+
+	projects := &archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, Status: "archived"}}}
+	infra := &stubDockerRunnerInfra{}
+	cloudfl := &stubHostnameCloudflareClient{dns: map[string]cloudflare.DNSRecord{}, dnsTarget: hostnameTestTunnelTarget}
+	workflows := &ProjectWorkflows{
+		cfg:          config.Config{TemplatesDir: templatesDir},
+		projects:     projects,
+		dockerRunner: NewDockerRunner(infra),
+		fileClient:   &stubProjectFileMutationClient{},
+	}
+	logger := &captureWorkflowLogger{}
+
+	err := workflows.runProjectRestore(
+		context.Background(),
+		logger,
+		config.Config{TemplatesDir: templatesDir, CloudflareZoneID: "zone-1"},
+		cloudfl,
+		"job-12",
+		ProjectRestoreJobRequest{
+			Project: "demo",
+			Manifest: ProjectArchiveManifest{
+				Version: projectArchiveManifestVersion,
+				Project: "demo",
+				Routes: []ProjectArchiveManifestRoute{
+					{Hostname: "demo.example.com", Port: 3000, Scheme: "tcp"},
+					{Hostname: "demo.example.com", Path: "^/api(/|$)", Port: 8080, Scheme: "http", Origin: &cloudflare.IngressOrigin{ConnectTimeout: 5}},
+				},
+				DNSRecords:    []ProjectArchiveManifestDNS{{Hostname: "demo.example.com", ZoneID: "zone-1"}},
+				Access:        []ProjectArchiveManifestAccess{{Hostname: "demo.example.com", Rules: cloudflare.AccessRules{Emails: []string{"ops@example.com"}}}},
+				EnvBackupPath: backupPath,
+			},
+			RestoreEnv:   true,
+			Unrestorable: []string{projectRestoreUnrestorableVolumes},
+		},
+	)
+	require.NoError(t, err)
+
+	require.True(t, infra.composeCalled)
+	env, err := os.ReadFile(filepath.Join(projectDir, ".env"))
+	require.NoError(t, err)
+	require.Equal(t, "TOKEN=abc\n", string(env))
+	require.Contains(t, cloudfl.dns, "demo.example.com")
+	require.Equal(t, []cloudflare.IngressRule{
+		{Hostname: "demo.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080", Origin: &cloudflare.IngressOrigin{ConnectTimeout: 5}},
+		{Hostname: "demo.example.com", Service: "tcp://localhost:3000"},
+	}, cloudfl.rules)
+	require.Equal(t, []string{"ops@example.com"}, cloudfl.ensured["demo.example.com"].Emails)
+	require.Equal(t, "running", projects.projects[0].Status)
+
+	logs := strings.Join(logger.lines, "\n")
+	require.Contains(t, logs, "restore completion summary: outcome=completed_with_warnings warnings=0 unrestorable=1 steps=env:completed volumes:skipped stack:completed access:completed routes:completed records:completed")
+	require.Contains(t, logs, "cannot restore: "+projectRestoreUnrestorableVolumes)
+}
+
+func TestProjectRestoreFailsClosedWhenAccessCannotBeRestored(t *testing.T) {
+	t.Parallel()
+
+	templatesDir, projectDir := writeRestoreTestProject(t)
+	projects := &archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, Status: "archived"}}}
+	cloudfl := &stubHostnameCloudflareClient{dns: map[string]cloudflare.DNSRecord{}, dnsTarget: hostnameTestTunnelTarget}
+	cloudfl.stubAccessClient.err = cloudflare.ErrMissingAccountID
+	workflows := &ProjectWorkflows{
+		cfg:          config.Config{TemplatesDir: templatesDir},
+		projects:     projects,
+		dockerRunner: NewDockerRunner(&stubDockerRunnerInfra{}),
+		fileClient:   &stubProjectFileMutationClient{},
+	}
+
+	err := workflows.runProjectRestore(
+		context.Background(),
+		&captureWorkflowLogger{},
+		config.Config{TemplatesDir: templatesDir, CloudflareZoneID: "zone-1"},
+		cloudfl,
+		"job-13",
+		ProjectRestoreJobRequest{
+			Project: "demo",
+			Manifest: ProjectArchiveManifest{
+				Version:    projectArchiveManifestVersion,
+				Project:    "demo",
+				Routes:     []ProjectArchiveManifestRoute{{Hostname: "demo.example.com", Port: 3000}},
+				DNSRecords: []ProjectArchiveManifestDNS{{Hostname: "demo.example.com", ZoneID: "zone-1"}},
+				Access:     []ProjectArchiveManifestAccess{{Hostname: "demo.example.com", Rules: cloudflare.AccessRules{Emails: []string{"ops@example.com"}}}},
+			},
+		},
+	)
+	require.ErrorContains(t, err, "no routes were restored")
+	require.Empty(t, cloudfl.rules)
+	require.Empty(t, cloudfl.dns)
+	require.Equal(t, "archived", projects.projects[0].Status)
+}
@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/config"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/validate"
+)
+
+// SetFileMutationClient lets archive jobs write the restore manifest and .env
+// backup, and restore jobs put the .env back, through the infra bridge.
+func (w *ProjectWorkflows) SetFileMutationClient(fileClient infraProjectFileMutationClient) {
+	w.fileClient = fileClient
+}
+
+func (w *ProjectWorkflows) runtimeMetaClient() infraDockerMetadataClient {
+	if w.host == nil {
+		return nil
+	}
+	return w.host.infraClient
+}
+
+// writeArchiveManifest records what the archive removed so a later restore
+// can bring it back. It runs after the removal steps so the manifest matches
+// the targets the archive actually acted on.
+func (w *ProjectWorkflows) writeArchiveManifest(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	req ProjectArchiveJobRequest,
+	options ProjectArchiveOptions,
+	volumeBackupID string,
+	ingressTargets []ProjectArchiveIngressDeleteTarget,
+	dnsTargets []ProjectArchiveDNSDeleteTarget,
+	exposureHostnames map[string]struct{},
+	warnings map[string]struct{},
+) projectArchiveStepStatus {
+	logProjectArchiveStepStart(logger, "manifest", "file_client_enabled=%t", w.fileClient != nil)
+	if w.fileClient == nil {
+		logProjectArchiveStepResult(logger, "manifest", projectArchiveStepStatusSkipped, "reason=%q", "infra bridge file client unavailable")
+		return projectArchiveStepStatusSkipped
+	}
+	fail := func(message string, err error) projectArchiveStepStatus {
+		addArchiveWarning(warnings, fmt.Sprintf("%s: %v", message, err))
+		logProjectArchiveStepResult(logger, "manifest", projectArchiveStepStatusPartialFailure, "error=%q", err.Error())
+		return projectArchiveStepStatusPartialFailure
+	}
+
+	resolved, err := resolveProjectPath(ctx, w.projects, cfg.TemplatesDir, req.Project, w.runtimeMetaClient())
+	if err != nil {
+		return fail("archive manifest skipped: resolve project path failed", err)
+	}
+	manifest := ProjectArchiveManifest{
+		Version:        projectArchiveManifestVersion,
+		Project:        req.Project,
+		ArchivedAt:     time.Now().UTC(),
+		ProjectDir:     resolved.ProjectDir,
+		ComposeFiles:   append([]string{}, resolved.ComposeFiles...),
+		Routes:         []ProjectArchiveManifestRoute{},
+		DNSRecords:     []ProjectArchiveManifestDNS{},
+		Containers:     append([]string{}, req.Targets.Containers...),
+		VolumesRemoved: options.RemoveContainers && options.RemoveVolumes,
+		VolumeBackupID: volumeBackupID,
+	}
+	if resolved.ProjectRecord != nil {
+		manifest.ProxyPort = resolved.ProjectRecord.ProxyPort
+	}
+
+	seenRoutes := make(map[string]struct{})
+	for _, target := range ingressTargets {
+		if _, ok := exposureHostnames[target.Hostname]; ok {
+			manifest.ExposureHostnames = append(manifest.ExposureHostnames, target.Hostname)
+			continue
+		}
+		port, ok := ingressServiceLocalPort(target.Service)
+		if !ok {
+			addArchiveWarning(warnings, fmt.Sprintf("archive manifest: %s routes to %s, which restore cannot re-create", describeIngressRoute(cloudflare.IngressRule{Hostname: target.Hostname, Path: target.Path}), target.Service))
+			continue
+		}
+		key := projectRouteKey(target.Hostname, target.Path)
+		if _, seen := seenRoutes[key]; seen {
+			continue
+		}
+		seenRoutes[key] = struct{}{}
+		manifest.Routes = append(manifest.Routes, ProjectArchiveManifestRoute{
+			Hostname: target.Hostname,
+			Path:     target.Path,
+			Port:     port,
+			Scheme:   ingressServiceScheme(target.Service),
+			Origin:   target.Origin,
+		})
+	}
+	manifest.ExposureHostnames = dedupeHostnames(manifest.ExposureHostnames)
+	for _, target := range dnsTargets {
+		if _, ok := exposureHostnames[target.Hostname]; ok {
+			continue
+		}
+		manifest.DNSRecords = append(manifest.DNSRecords, ProjectArchiveManifestDNS{Hostname: target.Hostname, ZoneID: target.ZoneID})
+	}
+	if options.RemoveAccess {
+		for _, target := range dedupeAccessDeleteTargets(req.Targets.AccessApps) {
+			if _, ok := exposureHostnames[target.Hostname]; ok {
+				continue
+			}
+			if target.Rules.Empty() {
+				addArchiveWarning(warnings, fmt.Sprintf("archive manifest: Access application %s for %s has no managed allow rules; restore leaves %s public", target.AppID, target.Hostname, target.Hostname))
+				continue
+			}
+			manifest.Access = append(manifest.Access, ProjectArchiveManifestAccess{Hostname: target.Hostname, Rules: target.Rules})
+		}
+	}
+
+	if w.workbench != nil {
+		if snapshot, exists, err := w.workbench.loadStoredWorkbenchSnapshot(ctx, req.Project); err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("archive manifest: workbench snapshot lookup failed: %v", err))
+		} else if exists {
+			manifest.WorkbenchRevision = snapshot.Revision
+		}
+	}
+
+	if resolved.EnvExists {
+		backupPath, err := resolveWorkbenchComposeBackupArtifactPath(resolved.ProjectDir, projectArchiveEnvBackupRelative)
+		if err != nil {
+			return fail("archive manifest skipped: resolve .env backup path failed", err)
+		}
+		if _, err := w.fileClient.ProjectFileCopy(ctx, "", contract.ProjectFileCopyPayload{
+			BasePath:        resolved.ProjectDir,
+			SourcePath:      resolved.EnvPath,
+			DestinationPath: backupPath,
+			Mode:            0o600,
+			CreateParents:   true,
+		}); err != nil {
+			return fail("archive manifest skipped: .env backup failed", err)
+		}
+		manifest.EnvBackupPath = backupPath
+	}
+
+	manifestPath, err := resolveWorkbenchComposeBackupArtifactPath(resolved.ProjectDir, projectArchiveManifestRelative)
+	if err != nil {
+		return fail("archive manifest skipped: resolve manifest path failed", err)
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fail("archive manifest skipped: encode failed", err)
+	}
+	if _, err := w.fileClient.ProjectFileWriteAtomic(ctx, "", contract.ProjectFileWriteAtomicPayload{
+		BasePath:      resolved.ProjectDir,
+		Path:          manifestPath,
+		Content:       string(append(content, '\n')),
+		Mode:          0o600,
+		CreateParents: true,
+	}); err != nil {
+		return fail("archive manifest write failed", err)
+	}
+	logProjectArchiveStepResult(
+		logger,
+		"manifest",
+		projectArchiveStepStatusCompleted,
+		"path=%s routes=%d dns_records=%d access_apps=%d env_backup=%t volumes_removed=%t volume_backup=%q",
+		manifestPath,
+		len(manifest.Routes),
+		len(manifest.DNSRecords),
+		len(manifest.Access),
+		manifest.EnvBackupPath != "",
+		manifest.VolumesRemoved,
+		manifest.VolumeBackupID,
+	)
+	return projectArchiveStepStatusCompleted
+}
+
+func (w *ProjectWorkflows) handleProjectRestore(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req ProjectRestoreJobRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse restore request: %w", err)
+	}
+	runtimeCfg, err := w.resolveProjectRuntimeConfig(ctx, req.Project)
+	if err != nil {
+		return fmt.Errorf("resolve runtime config: %w", err)
+	}
+	return w.runProjectRestore(ctx, logger, runtimeCfg, cloudflare.NewClient(runtimeCfg), fmt.Sprintf("job-%d", job.ID), req)
+}
+
+// runProjectRestore reverses an archive from its manifest: it puts the .env
+// back, restores the pre-archive volume backup, brings the compose stack up,
+// puts protected hostnames back behind Access, re-creates DNS records and
+// ingress rules, and marks the project running. A failed volume restore,
+// compose up, or Access step fails the job; route failures are reported as
+// warnings so the stack stays up.
+func (w *ProjectWorkflows) runProjectRestore(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl cloudflareAccessWorkflowClient,
+	requestID string,
+	req ProjectRestoreJobRequest,
+) error {
+	req.Project = strings.ToLower(strings.TrimSpace(req.Project))
+	if err := validate.ProjectName(req.Project); err != nil {
+		return err
+	}
+	if w.projects == nil {
+		return fmt.Errorf("project repository unavailable")
+	}
+	manifest := req.Manifest
+	resolved, err := resolveProjectPath(ctx, w.projects, cfg.TemplatesDir, req.Project, w.runtimeMetaClient())
+	if err != nil {
+		return err
+	}
+	warnings := make(map[string]struct{})
+
+	envStatus := projectArchiveStepStatusSkipped
+	logProjectStepStart(logger, "restore", "env", "restore_env=%t env_exists=%t", req.RestoreEnv, resolved.EnvExists)
+	switch {
+	case !req.RestoreEnv || resolved.EnvExists:
+		logProjectStepResult(logger, "restore", "env", envStatus, "reason=%q", "no .env to restore")
+	case w.fileClient == nil:
+		envStatus = projectArchiveStepStatusPartialFailure
+		addArchiveWarning(warnings, ".env restore skipped: infra bridge file client unavailable")
+		logProjectStepResult(logger, "restore", "env", envStatus, "reason=%q", "infra bridge file client unavailable")
+	case !isPathWithinBase(resolved.ProjectDir, manifest.EnvBackupPath):
+		envStatus = projectArchiveStepStatusPartialFailure
+		addArchiveWarning(warnings, ".env restore skipped: backup path resolves outside the project directory")
+		logProjectStepResult(logger, "restore", "env", envStatus, "reason=%q", "unsafe backup path")
+	default:
+		envPath := resolved.EnvPath
+		if strings.TrimSpace(envPath) == "" {
+			envPath = filepath.Join(resolved.ProjectDir, ".env")
+		}
+		if _, err := w.fileClient.ProjectFileCopy(ctx, "", contract.ProjectFileCopyPayload{
+			BasePath:        resolved.ProjectDir,
+			SourcePath:      manifest.EnvBackupPath,
+			DestinationPath: envPath,
+			Mode:            0o600,
+			CreateParents:   true,
+		}); err != nil {
+			envStatus = projectArchiveStepStatusPartialFailure
+			addArchiveWarning(warnings, fmt.Sprintf(".env restore failed: %v", err))
+			logProjectStepResult(logger, "restore", "env", envStatus, "error=%q", err.Error())
+		} else {
+			envStatus = projectArchiveStepStatusCompleted
+			logProjectStepResult(logger, "restore", "env", envStatus, "path=%s", envPath)
+		}
+	}
+
+	volumesStatus := projectArchiveStepStatusSkipped
+	logProjectStepStart(logger, "restore", "volumes", "volume_backup=%q", req.VolumeBackupID)
+	if req.VolumeBackupID == "" {
+		logProjectStepResult(logger, "restore", "volumes", volumesStatus, "reason=%q", "no volume backup to restore")
+	} else {
+		if w.host == nil {
+			err = fmt.Errorf("host service unavailable")
+		} else {
+			err = w.host.RestoreProjectVolumesWithLogger(ctx, requestID, req.Project, req.VolumeBackupID, nil, logger)
+		}
+		if err != nil {
+			logProjectStepResult(logger, "restore", "volumes", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("restore %s: volume backup %s: %w; the stack was not started", req.Project, req.VolumeBackupID, err)
+		}
+		volumesStatus = projectArchiveStepStatusCompleted
+		logProjectStepResult(logger, "restore", "volumes", volumesStatus, "backup_id=%s", req.VolumeBackupID)
+	}
+
+	logProjectStepStart(logger, "restore", "stack", "project_dir=%s compose_files=%d", resolved.ProjectDir, len(resolved.ComposeFiles))
+	if manifest.WorkbenchRevision > 0 && w.workbench != nil {
+		snapshot, exists, err := w.workbench.loadStoredWorkbenchSnapshot(ctx, req.Project)
+		switch {
+		case err != nil:
+			addArchiveWarning(warnings, fmt.Sprintf("workbench snapshot lookup failed: %v", err))
+		case !exists:
+			addArchiveWarning(warnings, fmt.Sprintf("workbench snapshot revision %d recorded at archive time no longer exists", manifest.WorkbenchRevision))
+		case snapshot.Revision != manifest.WorkbenchRevision:
+			addArchiveWarning(warnings, fmt.Sprintf("workbench snapshot changed since archive (revision %d, archived at %d)", snapshot.Revision, manifest.WorkbenchRevision))
+		}
+	}
+	if err := w.composeUpProject(ctx, logger, requestID, req.Project, resolved.ProjectDir, manifest.ProxyPort, nil); err != nil {
+		logProjectStepResult(logger, "restore", "stack", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("restore %s: compose up: %w", req.Project, err)
+	}
+	logProjectStepResult(logger, "restore", "stack", projectArchiveStepStatusCompleted, "containers=%d", len(manifest.Containers))
+
+	// Access goes back first and fails closed, like create: a hostname that
+	// was protected is never routed without its application.
+	accessStatus := projectArchiveStepStatusSkipped
+	logProjectStepStart(logger, "restore", "access", "access_apps=%d", len(manifest.Access))
+	for _, app := range manifest.Access {
+		access := &ProjectAccessRequest{
+			Enabled:      true,
+			Emails:       app.Rules.Emails,
+			EmailDomains: app.Rules.EmailDomains,
+			GitHubOrgs:   app.Rules.GitHubOrgs,
+		}
+		if err := protectProjectHostname(ctx, logger, cloudfl, app.Hostname, access); err != nil {
+			logProjectStepResult(logger, "restore", "access", projectArchiveStepStatusFailed, "hostname=%s error=%q", app.Hostname, err.Error())
+			return fmt.Errorf("restore %s: %w; no routes were restored", req.Project, err)
+		}
+		accessStatus = projectArchiveStepStatusCompleted
+	}
+	logProjectStepResult(logger, "restore", "access", accessStatus, "access_apps=%d", len(manifest.Access))
+
+	routes := append([]ProjectArchiveManifestRoute{}, manifest.Routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Hostname != routes[j].Hostname {
+			return routes[i].Hostname < routes[j].Hostname
+		}
+		return len(routes[i].Path) > len(routes[j].Path)
+	})
+	routesStatus := projectArchiveStepStatusCompleted
+	restoredDNS := 0
+	restoredRoutes := 0
+	logProjectStepStart(logger, "restore", "routes", "dns_records=%d routes=%d", len(manifest.DNSRecords), len(routes))
+	for _, record := range manifest.DNSRecords {
+		zoneID := strings.TrimSpace(record.ZoneID)
+		if zoneID == "" {
+			zoneID = strings.TrimSpace(cfg.CloudflareZoneID)
+		}
+		logger.Logf("updating Cloudflare DNS record for %s", record.Hostname)
+		if err := cloudfl.EnsureDNSForZone(ctx, record.Hostname, zoneID); err != nil {
+			routesStatus = projectArchiveStepStatusPartialFailure
+			addArchiveWarning(warnings, fmt.Sprintf("restore DNS record for %s failed: %v", record.Hostname, err))
+			continue
+		}
+		restoredDNS++
+	}
+	for _, route := range routes {
+		rule := cloudflare.IngressRule{Hostname: route.Hostname, Path: route.Path}
+		logger.Logf("configuring tunnel ingress for %s", describeIngressRoute(rule))
+		opts := cloudflare.IngressRouteOptions{Scheme: route.Scheme, Origin: route.Origin}
+		if err := w.updateTunnelIngressRouteOptions(ctx, logger, cfg, cloudfl, requestID, route.Hostname, route.Path, route.Port, opts); err != nil {
+			routesStatus = projectArchiveStepStatusPartialFailure
+			addArchiveWarning(warnings, fmt.Sprintf("restore ingress for %s failed: %v", describeIngressRoute(rule), err))
+			continue
+		}
+		restoredRoutes++
+	}
+	if len(manifest.DNSRecords)+len(routes) == 0 {
+		routesStatus = projectArchiveStepStatusSkipped
+	}
+	logProjectStepResult(logger, "restore", "routes", routesStatus, "dns_restored=%d routes_restored=%d", restoredDNS, restoredRoutes)
+
+	recordsStatus := projectArchiveStepStatusCompleted
+	logProjectStepStart(logger, "restore", "records", "project=%s", req.Project)
+	if record, err := lookupProjectRecord(ctx, w.projects, req.Project); err != nil {
+		recordsStatus = projectArchiveStepStatusPartialFailure
+		addArchiveWarning(warnings, fmt.Sprintf("project status update skipped: resolve project record failed: %v", err))
+	} else if record == nil {
+		recordsStatus = projectArchiveStepStatusPartialFailure
+		addArchiveWarning(warnings, fmt.Sprintf("project status update skipped: no project database row found for %s", req.Project))
+	} else {
+		record.Status = "running"
+		if err := w.projects.Update(ctx, record); err != nil {
+			recordsStatus = projectArchiveStepStatusPartialFailure
+			addArchiveWarning(warnings, fmt.Sprintf("project status update failed: %v", err))
+		}
+	}
+	logProjectStepResult(logger, "restore", "records", recordsStatus, "status=running")
+
+	sortedWarnings := sortedArchiveWarnings(warnings)
+	outcome := "completed"
+	if envStatus == projectArchiveStepStatusPartialFailure || routesStatus == projectArchiveStepStatusPartialFailure || recordsStatus == projectArchiveStepStatusPartialFailure {
+		outcome = "partial_failure"
+	} else if len(sortedWarnings) > 0 || len(req.Unrestorable) > 0 {
+		outcome = "completed_with_warnings"
+	}
+	if w.audit != nil {
+		if err := w.audit.Log(ctx, AuditEntry{
+			UserID:    req.RequestedBy.UserID,
+			UserLogin: req.RequestedBy.Login,
+			Action:    "project.restore.completed",
+			Target:    req.Project,
+			Metadata: map[string]any{
+				"project":        req.Project,
+				"archivedAt":     manifest.ArchivedAt,
+				"envRestored":    envStatus == projectArchiveStepStatusCompleted,
+				"volumeBackupId": req.VolumeBackupID,
+				"accessRestored": len(manifest.Access),
+				"dnsRestored":    restoredDNS,
+				"routesRestored": restoredRoutes,
+				"unrestorable":   req.Unrestorable,
+				"outcome":        outcome,
+				"warnings":       sortedWarnings,
+			},
+		}); err != nil {
+			logger.Logf("audit warning: failed to write restore completion event: %v", err)
+		}
+	}
+
+	logger.Logf(
+		"restore completion summary: outcome=%s warnings=%d unrestorable=%d steps=env:%s volumes:%s stack:%s access:%s routes:%s records:%s",
+		outcome,
+		len(sortedWarnings),
+		len(req.Unrestorable),
+		envStatus,
+		volumesStatus,
+		projectArchiveStepStatusCompleted,
+		accessStatus,
+		routesStatus,
+		recordsStatus,
+	)
+	for _, item := range req.Unrestorable {
+		logger.Logf("cannot restore: %s", item)
+	}
+	for _, warning := range sortedWarnings {
+		logger.Logf("warning: %s", warning)
+	}
+	return nil
+}
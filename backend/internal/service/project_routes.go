@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+	"go-notes/internal/validate"
+)
+
+// ProjectRoute maps a hostname and path prefix to a local project port. An
+// empty path is the hostname's catch-all route.
+type ProjectRoute struct {
+	Subdomain string `json:"subdomain"`
+	Domain    string `json:"domain"`
+	Hostname  string `json:"hostname"`
+	Path      string `json:"path"`
+	Port      int    `json:"port"`
+}
+
+// ProjectRoutesPlan describes the routes a project will serve and the ingress
+// rules and DNS records that are removed once they verify.
+type ProjectRoutesPlan struct {
+	Project    string                        `json:"project"`
+	Routes     []ProjectRoute                `json:"routes"`
+	Ingress    []ProjectArchivePlanIngress   `json:"ingressRules"`
+	DNSRecords []ProjectArchivePlanDNSRecord `json:"dnsRecords"`
+	Warnings   []string                      `json:"warnings"`
+}
+
+// ProjectRoutesTargets lists the hostnames a routes job serves. It uses the
+// same "targets.hostnames" key archive plans read from job inputs.
+type ProjectRoutesTargets struct {
+	Hostnames []string `json:"hostnames"`
+}
+
+// ProjectRoutesJobRequest is the job input for replacing a project's routes.
+type ProjectRoutesJobRequest struct {
+	Project     string                         `json:"project"`
+	Routes      []ProjectRoute                 `json:"routes"`
+	Targets     ProjectRoutesTargets           `json:"targets"`
+	Previous    ProjectHostnamePreviousTargets `json:"previous"`
+	PlannedAt   time.Time                      `json:"plannedAt"`
+	RequestedBy ProjectArchiveActor            `json:"requestedBy"`
+}
+
+// Routes returns the hostname and path routes recorded for a project.
+func (s *ProjectArchiveService) Routes(ctx context.Context, projectName string) ([]ProjectRoute, error) {
+	runtimeCfg, err := s.resolveRuntimeConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, err
+	}
+	routes := []ProjectRoute{}
+	if s.deployments == nil || resolved.ProjectRecord == nil {
+		return routes, nil
+	}
+	deployments, err := s.deployments.ListByProject(ctx, resolved.ProjectRecord.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		hostname := strings.ToLower(strings.TrimSpace(deployment.Hostname))
+		if hostname == "" {
+			continue
+		}
+		subdomain := strings.ToLower(strings.TrimSpace(deployment.Subdomain))
+		routes = append(routes, ProjectRoute{
+			Subdomain: subdomain,
+			Domain:    strings.TrimPrefix(hostname, subdomain+"."),
+			Hostname:  hostname,
+			Path:      normalizeRoutePath(deployment.Path),
+			Port:      deployment.Port,
+		})
+	}
+	sortProjectRoutes(routes)
+	return routes, nil
+}
+
+// QueueRoutes replaces the project's routes with the requested set. The job
+// adds and verifies every route before removing the ingress rules and DNS
+// records the project no longer uses.
+func (s *ProjectArchiveService) QueueRoutes(
+	ctx context.Context,
+	projectName string,
+	requested []ProjectRoute,
+	actor ProjectArchiveActor,
+) (*models.Job, ProjectRoutesPlan, error) {
+	if s.jobs == nil {
+		return nil, ProjectRoutesPlan{}, fmt.Errorf("job service unavailable")
+	}
+	if len(requested) == 0 {
+		return nil, ProjectRoutesPlan{}, errs.New(errs.CodeProjectRoutesInvalid, "at least one route is required")
+	}
+	runtimeCfg, err := s.resolveProjectRuntimeConfig(ctx, projectName)
+	if err != nil {
+		return nil, ProjectRoutesPlan{}, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, runtimeCfg.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, ProjectRoutesPlan{}, err
+	}
+	project := resolved.NormalizedName
+	defaultPort := 0
+	if resolved.ProjectRecord != nil {
+		defaultPort = resolved.ProjectRecord.ProxyPort
+	}
+
+	routes := make([]ProjectRoute, 0, len(requested))
+	seen := make(map[string]struct{}, len(requested))
+	hostnames := make(map[string]struct{})
+	ports := make(map[int]struct{})
+	for _, route := range requested {
+		route.Subdomain = strings.ToLower(strings.TrimSpace(route.Subdomain))
+		if route.Subdomain == "" {
+			route.Subdomain = project
+		}
+		if err := validate.Subdomain(route.Subdomain); err != nil {
+			return nil, ProjectRoutesPlan{}, err
+		}
+		selection, err := s.selectDomain(ctx, runtimeCfg, route.Domain)
+		if err != nil {
+			return nil, ProjectRoutesPlan{}, err
+		}
+		route.Domain = selection.Domain
+		route.Hostname = fmt.Sprintf("%s.%s", route.Subdomain, selection.Domain)
+		if err := validate.Domain(route.Hostname); err != nil {
+			return nil, ProjectRoutesPlan{}, err
+		}
+		route.Path = normalizeRoutePath(route.Path)
+		if err := validate.RoutePath(route.Path); err != nil {
+			return nil, ProjectRoutesPlan{}, err
+		}
+		if route.Port == 0 {
+			route.Port = defaultPort
+		}
+		if err := validate.Port(route.Port); err != nil {
+			return nil, ProjectRoutesPlan{}, err
+		}
+		key := projectRouteKey(route.Hostname, cloudflare.IngressPathPattern(route.Path))
+		if _, ok := seen[key]; ok {
+			return nil, ProjectRoutesPlan{}, errs.New(errs.CodeProjectRoutesInvalid, fmt.Sprintf("duplicate route %s%s", route.Hostname, route.Path))
+		}
+		seen[key] = struct{}{}
+		hostnames[route.Hostname] = struct{}{}
+		ports[route.Port] = struct{}{}
+		routes = append(routes, route)
+	}
+	sortProjectRoutes(routes)
+
+	warnings := make(map[string]struct{})
+	cfClient := cloudflare.NewClient(runtimeCfg)
+	discovered := s.discoverHostnames(ctx, project, normalizeDomain(runtimeCfg.Domain), warnings)
+	owned := stringSliceSet(discovered)
+
+	// Hostnames the project does not own yet must not already route elsewhere.
+	added := make([]string, 0)
+	for hostname := range hostnames {
+		if _, ok := owned[hostname]; !ok {
+			added = append(added, hostname)
+		}
+	}
+	sort.Strings(added)
+	for _, rule := range s.planIngress(ctx, runtimeCfg, cfClient, added, map[string]struct{}{}) {
+		port, ok := ingressServiceLocalPort(rule.Service)
+		if _, ours := ports[port]; !ok || !ours {
+			return nil, ProjectRoutesPlan{}, errs.New(errs.CodeProjectHostnameTaken, fmt.Sprintf("%s already routes to %s", describeIngressRoute(cloudflare.IngressRule{Hostname: rule.Hostname, Path: rule.Path}), rule.Service))
+		}
+	}
+
+	plan := ProjectRoutesPlan{
+		Project:    project,
+		Routes:     routes,
+		Ingress:    []ProjectArchivePlanIngress{},
+		DNSRecords: []ProjectArchivePlanDNSRecord{},
+	}
+	for _, rule := range s.planIngress(ctx, runtimeCfg, cfClient, discovered, warnings) {
+		if _, keep := seen[projectRouteKey(rule.Hostname, rule.Path)]; !keep {
+			plan.Ingress = append(plan.Ingress, rule)
+		}
+	}
+	unused := make([]string, 0)
+	for _, hostname := range discovered {
+		if _, ok := hostnames[hostname]; !ok {
+			unused = append(unused, hostname)
+		}
+	}
+	plan.DNSRecords = s.planDNSRecords(ctx, runtimeCfg, cfClient, unused, warnings)
+
+	previous := ProjectHostnamePreviousTargets{
+		Hostnames:    unused,
+		IngressRules: []ProjectArchiveIngressDeleteTarget{},
+		DNSRecords:   []ProjectArchiveDNSDeleteTarget{},
+	}
+	for _, rule := range plan.Ingress {
+		previous.IngressRules = append(previous.IngressRules, ProjectArchiveIngressDeleteTarget{
+			Hostname: rule.Hostname,
+			Path:     rule.Path,
+			Service:  strings.TrimSpace(rule.Service),
+			Source:   rule.Source,
+		})
+	}
+	for _, record := range plan.DNSRecords {
+		if !record.DeleteEligible {
+			continue
+		}
+		previous.DNSRecords = append(previous.DNSRecords, ProjectArchiveDNSDeleteTarget{
+			ZoneID:   record.ZoneID,
+			RecordID: record.ID,
+			Hostname: record.Name,
+			Content:  record.Content,
+		})
+	}
+	plan.Warnings = sortedArchiveWarnings(warnings)
+
+	served := make([]string, 0, len(hostnames))
+	for hostname := range hostnames {
+		served = append(served, hostname)
+	}
+	sort.Strings(served)
+
+	job, err := s.jobs.Create(ctx, JobTypeProjectRoutes, ProjectRoutesJobRequest{
+		Project:     project,
+		Routes:      routes,
+		Targets:     ProjectRoutesTargets{Hostnames: served},
+		Previous:    previous,
+		PlannedAt:   time.Now().UTC(),
+		RequestedBy: actor,
+	})
+	if err != nil {
+		return nil, ProjectRoutesPlan{}, err
+	}
+	return job, plan, nil
+}
+
+// normalizeRoutePath trims surrounding space and trailing slashes so the root
+// path is "" and "/api/" is stored as "/api".
+func normalizeRoutePath(path string) string {
+	return strings.TrimRight(strings.TrimSpace(path), "/")
+}
+
+// projectRouteKey identifies a route by hostname and cloudflared path regex.
+func projectRouteKey(hostname, pattern string) string {
+	return strings.ToLower(strings.TrimSpace(hostname)) + "\x00" + strings.TrimSpace(pattern)
+}
+
+// sortProjectRoutes orders routes by hostname and then longest path first,
+// the order cloudflared needs to match nested prefixes.
+func sortProjectRoutes(routes []ProjectRoute) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Hostname != routes[j].Hostname {
+			return routes[i].Hostname < routes[j].Hostname
+		}
+		if len(routes[i].Path) != len(routes[j].Path) {
+			return len(routes[i].Path) > len(routes[j].Path)
+		}
+		return routes[i].Path < routes[j].Path
+	})
+}
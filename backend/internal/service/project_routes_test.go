@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+)
+
+func TestProjectRoutesAddsPathRulesThenRemovesUnused(t *testing.T) {
+	t.Parallel()
+
+	projects := &stubProjectRepository{projects: []models.Project{{Model: gorm.Model{ID: 3}, Name: "demo", ProxyPort: 3000, Status: "running"}}}
+	deployments := &stubDeploymentRepository{deployments: []models.Deployment{
+		{Model: gorm.Model{ID: 1}, ProjectID: 3, Subdomain: "demo", Hostname: "demo.example.com", Port: 3000},
+		{Model: gorm.Model{ID: 2}, ProjectID: 3, Subdomain: "app", Hostname: "app.example.com", Port: 3000},
+	}}
+	cloudfl := &stubHostnameCloudflareClient{
+		rules: []cloudflare.IngressRule{
+			{Hostname: "demo.example.com", Service: "http://localhost:3000"},
+			{Hostname: "app.example.com", Service: "http://localhost:3000"},
+		},
+		dns:       map[string]cloudflare.DNSRecord{},
+		dnsTarget: hostnameTestTunnelTarget,
+	}
+	workflows := &ProjectWorkflows{
+		cfg:         config.Config{Domain: "example.com", CloudflareZoneID: "zone-1"},
+		projects:    projects,
+		deployments: deployments,
+	}
+	logger := &captureWorkflowLogger{}
+
+	err := workflows.runProjectRoutes(
+		context.Background(),
+		logger,
+		config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)},
+		cloudfl,
+		"job-11",
+		ProjectRoutesJobRequest{
+			Project: "demo",
+			Routes: []ProjectRoute{
+				{Subdomain: "app", Domain: "example.com", Hostname: "app.example.com", Port: 3000},
+				{Subdomain: "app", Domain: "example.com", Hostname: "app.example.com", Path: "/api", Port: 8080},
+			},
+			Previous: ProjectHostnamePreviousTargets{
+				Hostnames: []string{"demo.example.com"},
+				IngressRules: []ProjectArchiveIngressDeleteTarget{
+					{Hostname: "demo.example.com", Service: "http://localhost:3000", Source: "remote"},
+				},
+				DNSRecords: []ProjectArchiveDNSDeleteTarget{
+					{ZoneID: "zone-1", RecordID: "rec-demo", Hostname: "demo.example.com", Content: hostnameTestTunnelTarget},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, []cloudflare.IngressRule{
+		{Hostname: "app.example.com", Path: "^/api(/|$)", Service: "http://localhost:8080"},
+		{Hostname: "app.example.com", Service: "http://localhost:3000"},
+	}, cloudfl.rules[2:])
+	require.Equal(t, []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:3000"}}, cloudfl.removed)
+	require.Equal(t, []string{"rec-demo"}, cloudfl.deletedDNS)
+
+	require.Len(t, deployments.deployments, 2)
+	require.Equal(t, uint(2), deployments.deployments[0].ID)
+	require.Equal(t, "", deployments.deployments[0].Path)
+	require.Equal(t, "/api", deployments.deployments[1].Path)
+	require.Equal(t, 8080, deployments.deployments[1].Port)
+
+	logs := strings.Join(logger.lines, "\n")
+	require.Contains(t, logs, "configuring tunnel ingress for app.example.com (path ^/api(/|$))")
+	require.Contains(t, logs, "routes step records: result=completed updated=2 deleted=1")
+	require.Contains(t, logs, "routes update completion summary: outcome=completed")
+}
+
+func TestProjectRoutesQueueRemovesOnlyUnusedRules(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte("services: {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(configPath, []byte(
+		"ingress:\n"+
+			"  - hostname: demo.example.com\n"+
+			"    path: ^/old(/|$)\n"+
+			"    service: http://localhost:9090\n"+
+			"  - hostname: demo.example.com\n"+
+			"    service: http://localhost:3000\n"+
+			"  - hostname: taken.example.com\n"+
+			"    service: http://localhost:7070\n"+
+			"  - service: http_status:404\n",
+	), 0o644))
+
+	jobRepo := &archiveTestJobRepo{}
+	svc := NewProjectArchiveService(
+		config.Config{TemplatesDir: templatesDir, Domain: "example.com", CloudflaredConfig: configPath},
+		&archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, ProxyPort: 3000}}},
+		nil,
+		NewJobService(jobRepo, nil),
+		nil,
+	)
+
+	job, plan, err := svc.QueueRoutes(context.Background(), "demo", []ProjectRoute{
+		{Path: "/"},
+		{Path: "/api/", Port: 8080},
+	}, ProjectArchiveActor{UserID: 7, Login: "tester"})
+	require.NoError(t, err)
+	require.Equal(t, []ProjectRoute{
+		{Subdomain: "demo", Domain: "example.com", Hostname: "demo.example.com", Path: "/api", Port: 8080},
+		{Subdomain: "demo", Domain: "example.com", Hostname: "demo.example.com", Path: "", Port: 3000},
+	}, plan.Routes)
+
+	var payload ProjectRoutesJobRequest
+	require.NoError(t, json.Unmarshal([]byte(job.Input), &payload))
+	require.Equal(t, JobTypeProjectRoutes, job.Type)
+	require.Equal(t, []string{"demo.example.com"}, payload.Targets.Hostnames)
+	require.Equal(t, []ProjectArchiveIngressDeleteTarget{
+		{Hostname: "demo.example.com", Path: "^/old(/|$)", Service: "http://localhost:9090", Source: "local"},
+	}, payload.Previous.IngressRules)
+
+	_, _, err = svc.QueueRoutes(context.Background(), "demo", []ProjectRoute{{Subdomain: "taken"}}, ProjectArchiveActor{})
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectHostnameTaken, typed.Code)
+
+	_, _, err = svc.QueueRoutes(context.Background(), "demo", []ProjectRoute{{Path: "/api"}, {Path: "/api/"}}, ProjectArchiveActor{})
+	typed, ok = errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectRoutesInvalid, typed.Code)
+
+	_, _, err = svc.QueueRoutes(context.Background(), "demo", []ProjectRoute{{Path: "api?x=1"}}, ProjectArchiveActor{})
+	typed, ok = errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeValidationRoutePath, typed.Code)
+}
+
+func TestProjectRoutesCarriesAccessFromDroppedHostnames(t *testing.T) {
+	t.Parallel()
+
+	rules := cloudflare.AccessRules{EmailDomains: []string{"example.com"}}
+	cloudfl := &stubHostnameCloudflareClient{
+		stubAccessClient: stubAccessClient{apps: map[string]*cloudflare.AccessApplication{
+			"demo.example.com": {ID: "app-demo", Domain: "demo.example.com", Managed: true, Rules: rules},
+		}},
+		rules:     []cloudflare.IngressRule{{Hostname: "demo.example.com", Service: "http://localhost:3000"}},
+		dns:       map[string]cloudflare.DNSRecord{},
+		dnsTarget: hostnameTestTunnelTarget,
+	}
+	workflows := &ProjectWorkflows{
+		cfg:      config.Config{Domain: "example.com", CloudflareZoneID: "zone-1"},
+		projects: &stubProjectRepository{},
+	}
+
+	err := workflows.runProjectRoutes(
+		context.Background(),
+		&testWorkflowLogger{},
+		config.Config{CloudflaredConfig: writeCloudflaredConfigFixture(t)},
+		cloudfl,
+		"job-13",
+		ProjectRoutesJobRequest{
+			Project: "demo",
+			Routes: []ProjectRoute{
+				{Subdomain: "app", Domain: "example.com", Hostname: "app.example.com", Port: 3000},
+			},
+			Previous: ProjectHostnamePreviousTargets{
+				Hostnames: []string{"demo.example.com"},
+				IngressRules: []ProjectArchiveIngressDeleteTarget{
+					{Hostname: "demo.example.com", Service: "http://localhost:3000", Source: "remote"},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]cloudflare.AccessRules{"app.example.com": rules}, cloudfl.ensured)
+	require.Equal(t, []string{"app-demo"}, cloudfl.deleted)
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-notes/internal/config"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/validate"
+)
+
+func (w *ProjectWorkflows) handleProjectRoutes(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req ProjectRoutesJobRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse routes request: %w", err)
+	}
+	runtimeCfg, err := w.resolveProjectRuntimeConfig(ctx, req.Project)
+	if err != nil {
+		return fmt.Errorf("resolve runtime config: %w", err)
+	}
+	return w.runProjectRoutes(ctx, logger, runtimeCfg, cloudflare.NewClient(runtimeCfg), fmt.Sprintf("job-%d", job.ID), req)
+}
+
+// runProjectRoutes adds every requested hostname and path route, verifies
+// them, and only then removes the rules and records the project no longer
+// uses and rewrites its deployment rows. A failed add or verify leaves the
+// previous routes serving traffic.
+func (w *ProjectWorkflows) runProjectRoutes(
+	ctx context.Context,
+	logger jobs.Logger,
+	cfg config.Config,
+	cloudfl projectHostnameCloudflareClient,
+	requestID string,
+	req ProjectRoutesJobRequest,
+) error {
+	req.Project = strings.ToLower(strings.TrimSpace(req.Project))
+	if err := validate.ProjectName(req.Project); err != nil {
+		return err
+	}
+	if len(req.Routes) == 0 {
+		return fmt.Errorf("routes update for %s has no routes", req.Project)
+	}
+	routes := make([]ProjectRoute, 0, len(req.Routes))
+	for _, route := range req.Routes {
+		route.Hostname = strings.ToLower(strings.TrimSpace(route.Hostname))
+		route.Path = normalizeRoutePath(route.Path)
+		if err := validate.Domain(route.Hostname); err != nil {
+			return err
+		}
+		if err := validate.RoutePath(route.Path); err != nil {
+			return err
+		}
+		if err := validate.Port(route.Port); err != nil {
+			return err
+		}
+		routes = append(routes, route)
+	}
+	sortProjectRoutes(routes)
+	warnings := make(map[string]struct{})
+
+	// Routes are sorted by hostname, so each hostname's routes are contiguous.
+	type hostnameRoutes struct {
+		hostname string
+		zoneID   string
+		routes   []ProjectHostnameRoute
+	}
+	groups := make([]hostnameRoutes, 0)
+	for _, route := range routes {
+		if len(groups) == 0 || groups[len(groups)-1].hostname != route.Hostname {
+			selection, err := w.resolveDomainSelection(ctx, cfg, route.Domain)
+			if err != nil {
+				return err
+			}
+			zoneID := strings.TrimSpace(selection.ZoneID)
+			if zoneID == "" {
+				zoneID = strings.TrimSpace(cfg.CloudflareZoneID)
+			}
+			groups = append(groups, hostnameRoutes{hostname: route.Hostname, zoneID: zoneID})
+		}
+		group := &groups[len(groups)-1]
+		group.routes = append(group.routes, ProjectHostnameRoute{Path: cloudflare.IngressPathPattern(route.Path), Port: route.Port})
+	}
+
+	previousSet := stringSliceSet(dedupeHostnames(req.Previous.Hostnames))
+	served := make(map[string]struct{}, len(groups))
+	addedHostnames := make([]string, 0, len(groups))
+	for _, group := range groups {
+		served[group.hostname] = struct{}{}
+		if _, ok := previousSet[group.hostname]; !ok {
+			addedHostnames = append(addedHostnames, group.hostname)
+		}
+	}
+	droppedHostnames := make([]string, 0, len(previousSet))
+	for _, hostname := range dedupeHostnames(req.Previous.Hostnames) {
+		if _, ok := served[hostname]; !ok && hostname != "" {
+			droppedHostnames = append(droppedHostnames, hostname)
+		}
+	}
+	accessApps := []cloudflare.AccessApplication{}
+	if len(addedHostnames) > 0 && len(droppedHostnames) > 0 {
+		logProjectStepStart(logger, "routes", "access", "added=%d dropped=%d", len(addedHostnames), len(droppedHostnames))
+		carried, err := carryProjectAccess(ctx, logger, cloudfl, droppedHostnames, addedHostnames, warnings)
+		if err != nil {
+			logProjectStepResult(logger, "routes", "access", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("%w; previous routes were left in place", err)
+		}
+		accessApps = carried
+		logProjectStepResult(logger, "routes", "access", projectArchiveStepStatusCompleted, "carried=%d", len(accessApps))
+	}
+
+	logProjectStepStart(logger, "routes", "add", "hostnames=%d routes=%d", len(groups), len(routes))
+	for _, group := range groups {
+		logger.Logf("updating Cloudflare DNS record for %s", group.hostname)
+		if err := cloudfl.EnsureDNSForZone(ctx, group.hostname, group.zoneID); err != nil {
+			logProjectStepResult(logger, "routes", "add", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("add %s: cloudflare dns: %w", group.hostname, err)
+		}
+		for _, route := range group.routes {
+			rule := cloudflare.IngressRule{Hostname: group.hostname, Path: route.Path}
+			logger.Logf("configuring tunnel ingress for %s", describeIngressRoute(rule))
+			if err := w.updateTunnelIngressRoute(ctx, logger, cfg, cloudfl, requestID, group.hostname, route.Path, route.Port); err != nil {
+				logProjectStepResult(logger, "routes", "add", projectArchiveStepStatusFailed, "error=%q", err.Error())
+				return fmt.Errorf("add %s: %w", describeIngressRoute(rule), err)
+			}
+		}
+	}
+	logProjectStepResult(logger, "routes", "add", projectArchiveStepStatusCompleted, "routes=%d", len(routes))
+
+	logProjectStepStart(logger, "routes", "verify", "hostnames=%d", len(groups))
+	for _, group := range groups {
+		if err := verifyProjectHostname(ctx, cfg, cloudfl, group.hostname, group.zoneID, group.routes); err != nil {
+			logProjectStepResult(logger, "routes", "verify", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("verify %s: %w; previous routes were left in place", group.hostname, err)
+		}
+	}
+	logProjectStepResult(logger, "routes", "verify", projectArchiveStepStatusCompleted, "dns=ok ingress=ok")
+
+	desired := make(map[string]struct{}, len(routes))
+	for _, group := range groups {
+		for _, route := range group.routes {
+			desired[projectRouteKey(group.hostname, route.Path)] = struct{}{}
+		}
+	}
+	ingressTargets := make([]ProjectArchiveIngressDeleteTarget, 0, len(req.Previous.IngressRules))
+	for _, target := range normalizeIngressDeleteTargets(req.Previous.IngressRules) {
+		if _, keep := desired[projectRouteKey(target.Hostname, target.Path)]; !keep {
+			ingressTargets = append(ingressTargets, target)
+		}
+	}
+	dnsTargets := make([]ProjectArchiveDNSDeleteTarget, 0, len(req.Previous.DNSRecords))
+	for _, target := range dedupeDNSDeleteTargets(req.Previous.DNSRecords) {
+		if _, keep := served[target.Hostname]; !keep {
+			dnsTargets = append(dnsTargets, target)
+		}
+	}
+	removal := w.removeProjectRouteTargets(ctx, logger, cfg, cloudfl, requestID, "routes", len(req.Previous.Hostnames), ingressTargets, dnsTargets, warnings)
+	removedAccess := removePreviousProjectAccess(ctx, logger, cloudfl, removal, accessApps, warnings)
+
+	logProjectStepStart(logger, "routes", "records", "project=%s deployments_enabled=%t", req.Project, w.deployments != nil)
+	recordsStatus, updatedRecords, deletedRecords := w.recordProjectRoutes(ctx, req.Project, routes, warnings)
+	logProjectStepResult(logger, "routes", "records", recordsStatus, "updated=%d deleted=%d", updatedRecords, deletedRecords)
+
+	sortedWarnings := sortedArchiveWarnings(warnings)
+	outcome := "completed"
+	if removal.status == projectArchiveStepStatusPartialFailure || recordsStatus == projectArchiveStepStatusPartialFailure {
+		outcome = "partial_failure"
+	} else if len(sortedWarnings) > 0 {
+		outcome = "completed_with_warnings"
+	}
+	if w.audit != nil {
+		if err := w.audit.Log(ctx, AuditEntry{
+			UserID:    req.RequestedBy.UserID,
+			UserLogin: req.RequestedBy.Login,
+			Action:    "project.routes.completed",
+			Target:    req.Project,
+			Metadata: map[string]any{
+				"project":           req.Project,
+				"routes":            routes,
+				"previousHostnames": req.Previous.Hostnames,
+				"removedRemote":     removal.remote,
+				"removedLocal":      removal.local,
+				"removedDnsRecords": removal.dns,
+				"removedAccessApps": removedAccess,
+				"updatedRecords":    updatedRecords,
+				"deletedRecords":    deletedRecords,
+				"outcome":           outcome,
+				"warnings":          sortedWarnings,
+			},
+		}); err != nil {
+			logger.Logf("audit warning: failed to write routes completion event: %v", err)
+		}
+	}
+
+	logger.Logf(
+		"routes update completion summary: outcome=%s warnings=%d steps=add:%s verify:%s remove_old:%s records:%s",
+		outcome,
+		len(sortedWarnings),
+		projectArchiveStepStatusCompleted,
+		projectArchiveStepStatusCompleted,
+		removal.status,
+		recordsStatus,
+	)
+	for _, warning := range sortedWarnings {
+		logger.Logf("warning: %s", warning)
+	}
+	return nil
+}
+
+// recordProjectRoutes rewrites the project's deployment rows to one row per
+// route, reusing rows that already carry the route's hostname and path.
+func (w *ProjectWorkflows) recordProjectRoutes(
+	ctx context.Context,
+	project string,
+	routes []ProjectRoute,
+	warnings map[string]struct{},
+) (projectArchiveStepStatus, int, int) {
+	if w.deployments == nil {
+		return projectArchiveStepStatusSkipped, 0, 0
+	}
+	record, err := lookupProjectRecord(ctx, w.projects, project)
+	if err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("deployment update skipped: resolve project record failed: %v", err))
+		return projectArchiveStepStatusPartialFailure, 0, 0
+	}
+	if record == nil {
+		addArchiveWarning(warnings, fmt.Sprintf("deployment update skipped: no project database row found for %s", project))
+		return projectArchiveStepStatusPartialFailure, 0, 0
+	}
+	deployments, err := w.deployments.ListByProject(ctx, record.ID)
+	if err != nil {
+		addArchiveWarning(warnings, fmt.Sprintf("deployment update skipped: list deployments failed: %v", err))
+		return projectArchiveStepStatusPartialFailure, 0, 0
+	}
+
+	existing := make(map[string]models.Deployment, len(deployments))
+	stale := make([]models.Deployment, 0)
+	for _, deployment := range deployments {
+		key := projectRouteKey(deployment.Hostname, normalizeRoutePath(deployment.Path))
+		if _, dup := existing[key]; dup {
+			stale = append(stale, deployment)
+			continue
+		}
+		existing[key] = deployment
+	}
+
+	updated := 0
+	for _, route := range routes {
+		key := projectRouteKey(route.Hostname, route.Path)
+		deployment, ok := existing[key]
+		if ok {
+			delete(existing, key)
+		} else {
+			deployment = models.Deployment{ProjectID: record.ID, State: strings.TrimSpace(record.Status)}
+		}
+		deployment.Subdomain = route.Subdomain
+		deployment.Hostname = route.Hostname
+		deployment.Path = route.Path
+		deployment.Port = route.Port
+		if err := w.deployments.Save(ctx, &deployment); err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("deployment save for %s%s failed: %v", route.Hostname, route.Path, err))
+			return projectArchiveStepStatusPartialFailure, updated, 0
+		}
+		updated++
+	}
+	for _, deployment := range existing {
+		stale = append(stale, deployment)
+	}
+
+	deleted := 0
+	for _, deployment := range stale {
+		if err := w.deployments.Delete(ctx, deployment.ID); err != nil {
+			addArchiveWarning(warnings, fmt.Sprintf("deployment %d delete failed: %v", deployment.ID, err))
+			return projectArchiveStepStatusPartialFailure, updated, deleted
+		}
+		deleted++
+	}
+	return projectArchiveStepStatusCompleted, updated, deleted
+}
@@ -32,6 +32,14 @@ type ProjectSummary struct {
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	GitBranch      string     `json:"gitBranch,omitempty"`
+	AutoDeploy     bool       `json:"autoDeploy"`
+	DeployedCommit string     `json:"deployedCommit,omitempty"`
+	DeployedAt     *time.Time `json:"deployedAt,omitempty"`
+
+	BackupRetention int    `json:"backupRetention"`
+	Tunnel          string `json:"tunnel,omitempty"`
 }
 
 type ProjectDetail struct {
@@ -136,6 +144,14 @@ func (s *ProjectRuntimeService) ListSummaries(ctx context.Context) ([]ProjectSum
 			Status:    status,
 			CreatedAt: project.CreatedAt,
 			UpdatedAt: project.UpdatedAt,
+
+			GitBranch:      project.GitBranch,
+			AutoDeploy:     project.AutoDeploy,
+			DeployedCommit: project.DeployedCommit,
+			DeployedAt:     project.DeployedAt,
+
+			BackupRetention: project.BackupRetention,
+			Tunnel:          project.Tunnel,
 		}
 
 		if runtimeAvailable && strings.TrimSpace(summary.Path) == "" {
@@ -193,6 +209,14 @@ func (s *ProjectRuntimeService) Detail(ctx context.Context, projectName string)
 			Status:    record.Status,
 			CreatedAt: record.CreatedAt,
 			UpdatedAt: record.UpdatedAt,
+
+			GitBranch:      record.GitBranch,
+			AutoDeploy:     record.AutoDeploy,
+			DeployedCommit: record.DeployedCommit,
+			DeployedAt:     record.DeployedAt,
+
+			BackupRetention: record.BackupRetention,
+			Tunnel:          record.Tunnel,
 		}
 		detail.Network.ProxyPort = record.ProxyPort
 		detail.Network.DBPort = record.DBPort
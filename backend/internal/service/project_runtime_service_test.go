@@ -49,6 +49,16 @@ func (s *stubProjectRepository) Update(_ context.Context, project *models.Projec
 	return repository.ErrNotFound
 }
 
+func (s *stubProjectRepository) Delete(_ context.Context, id uint) error {
+	for index, existing := range s.projects {
+		if existing.ID == id {
+			s.projects = append(s.projects[:index], s.projects[index+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func TestProjectRuntimeServiceDetailReturnsDegradedRuntimeDiagnosticsWhenDockerInventoryFails(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/jobs"
+)
+
+// The deploy step owns everything between these markers in a project .env.
+// The .env editor hides the block and keeps it intact on save.
+const (
+	projectSecretsBlockStart = "# >>> gungnr secrets (managed from the secrets vault; do not edit)"
+	projectSecretsBlockEnd   = "# <<< gungnr secrets"
+)
+
+func (w *ProjectWorkflows) SetSecretsVault(secrets *SecretsService) {
+	w.secrets = secrets
+}
+
+// injectProjectSecrets writes the project's vault secrets into the managed
+// block of its .env before compose reads it. A user-defined line for a
+// vaulted key is dropped so the vault value wins. Only key names are logged.
+func (w *ProjectWorkflows) injectProjectSecrets(ctx context.Context, logger jobs.Logger, projectDir string) error {
+	if !w.secrets.Enabled() {
+		return nil
+	}
+	values, err := w.secrets.Values(ctx, SecretScopeProject, filepath.Base(projectDir))
+	if err != nil {
+		return fmt.Errorf("load project secrets: %w", err)
+	}
+	envPath := filepath.Join(projectDir, ".env")
+	raw, err := os.ReadFile(envPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read .env: %w", err)
+	}
+	next := mergeProjectSecretsBlock(string(raw), values)
+	if next == string(raw) {
+		return nil
+	}
+	if w.fileClient == nil {
+		return fmt.Errorf("infra bridge file client unavailable")
+	}
+	if _, err := w.fileClient.ProjectFileWriteAtomic(ctx, "", contract.ProjectFileWriteAtomicPayload{
+		BasePath: projectDir,
+		Path:     envPath,
+		Content:  next,
+		Mode:     0o600,
+	}); err != nil {
+		return fmt.Errorf("write .env secrets: %w", err)
+	}
+	if len(values) == 0 {
+		logger.Logf("removed vault secrets block from .env")
+		return nil
+	}
+	logger.Logf("injected %d vault secrets into .env: %s", len(values), strings.Join(sortedSecretNames(values), ", "))
+	return nil
+}
+
+// splitProjectSecretsBlock separates .env content from its managed block.
+// An unterminated block runs to the end of the file.
+func splitProjectSecretsBlock(content string) (string, string) {
+	lines := strings.SplitAfter(content, "\n")
+	var rest, block strings.Builder
+	inBlock := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inBlock && trimmed == projectSecretsBlockStart:
+			inBlock = true
+			block.WriteString(line)
+		case inBlock:
+			block.WriteString(line)
+			if trimmed == projectSecretsBlockEnd {
+				inBlock = false
+			}
+		default:
+			rest.WriteString(line)
+		}
+	}
+	if block.Len() == 0 {
+		return rest.String(), ""
+	}
+	// Drop the blank separator line written before the block.
+	trimmed := strings.TrimRight(rest.String(), "\n")
+	if trimmed != "" {
+		trimmed += "\n"
+	}
+	return trimmed, block.String()
+}
+
+// mergeProjectSecretsBlock replaces the managed block of content with values,
+// dropping other definitions of those keys. No values removes the block.
+func mergeProjectSecretsBlock(content string, values map[string]string) string {
+	rest, _ := splitProjectSecretsBlock(content)
+	if len(values) > 0 {
+		entries, _ := parseProjectEnv(rest)
+		drop := map[int]bool{}
+		for _, entry := range entries {
+			if _, ok := values[entry.Key]; ok {
+				for line := entry.Line; line <= entry.EndLine; line++ {
+					drop[line] = true
+				}
+			}
+		}
+		if len(drop) > 0 {
+			kept := []string{}
+			for idx, line := range strings.Split(rest, "\n") {
+				if !drop[idx+1] {
+					kept = append(kept, line)
+				}
+			}
+			rest = strings.Join(kept, "\n")
+		}
+	}
+	rest = strings.TrimRight(rest, "\n")
+	if len(values) == 0 {
+		if rest == "" {
+			return ""
+		}
+		return rest + "\n"
+	}
+	var out strings.Builder
+	if rest != "" {
+		out.WriteString(rest)
+		out.WriteString("\n\n")
+	}
+	out.WriteString(projectSecretsBlockStart + "\n")
+	for _, name := range sortedSecretNames(values) {
+		out.WriteString(name + "=" + quoteProjectEnvValue(values[name]) + "\n")
+	}
+	out.WriteString(projectSecretsBlockEnd + "\n")
+	return out.String()
+}
+
+// quoteProjectEnvValue single-quotes a value so compose reads it literally,
+// falling back to an escaped double-quoted value when it contains a quote.
+func quoteProjectEnvValue(value string) string {
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// projectSecretsBlockKeys lists the keys defined in a managed block.
+func projectSecretsBlockKeys(block string) []string {
+	entries, _ := parseProjectEnv(block)
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSecretNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,6 +22,7 @@ type ProjectService struct {
 	jobs     *JobService
 	settings *SettingsService
 	infra    infraPortProbeClient
+	tunnels  *TunnelService
 }
 
 func NewProjectService(
@@ -33,6 +35,11 @@ func NewProjectService(
 	return &ProjectService{cfg: cfg, repo: repo, jobs: jobs, settings: settings, infra: infra}
 }
 
+// SetTunnels lets deploys name the tunnel that serves their hostname.
+func (s *ProjectService) SetTunnels(tunnels *TunnelService) {
+	s.tunnels = tunnels
+}
+
 func (s *ProjectService) List(ctx context.Context) ([]models.Project, error) {
 	return s.repo.List(ctx)
 }
@@ -49,20 +56,32 @@ type CreateTemplateRequest struct {
 	Domain    string `json:"domain,omitempty"`
 	ProxyPort int    `json:"proxyPort"`
 	DBPort    int    `json:"dbPort"`
+	// Access puts the new hostname behind Cloudflare Access.
+	Access *ProjectAccessRequest `json:"access,omitempty"`
+	// Ingress sets the service scheme and origin options of the tunnel rule.
+	Ingress *ProjectIngressRequest `json:"ingress,omitempty"`
+	// Tunnel names the tunnel serving the hostname; empty keeps the project's
+	// current tunnel, or the settings tunnel for a new project.
+	Tunnel string `json:"tunnel,omitempty"`
 }
 
 type DeployExistingRequest struct {
-	Name      string `json:"name"`
-	Subdomain string `json:"subdomain"`
-	Domain    string `json:"domain,omitempty"`
-	Port      int    `json:"port"`
+	Name      string                 `json:"name"`
+	Subdomain string                 `json:"subdomain"`
+	Domain    string                 `json:"domain,omitempty"`
+	Port      int                    `json:"port"`
+	Access    *ProjectAccessRequest  `json:"access,omitempty"`
+	Ingress   *ProjectIngressRequest `json:"ingress,omitempty"`
+	Tunnel    string                 `json:"tunnel,omitempty"`
 }
 
 type ForwardLocalRequest struct {
-	Name      string `json:"name"`
-	Subdomain string `json:"subdomain"`
-	Domain    string `json:"domain,omitempty"`
-	Port      int    `json:"port"`
+	Name      string                 `json:"name"`
+	Subdomain string                 `json:"subdomain"`
+	Domain    string                 `json:"domain,omitempty"`
+	Port      int                    `json:"port"`
+	Ingress   *ProjectIngressRequest `json:"ingress,omitempty"`
+	Tunnel    string                 `json:"tunnel,omitempty"`
 }
 
 type QuickServiceRequest struct {
@@ -116,7 +135,12 @@ func (s *ProjectService) CreateFromTemplate(ctx context.Context, req CreateTempl
 	if err := validate.Subdomain(req.Subdomain); err != nil {
 		return nil, err
 	}
-	domain, err := s.resolveDomain(ctx, req.Domain)
+	tunnel, err := s.resolveTunnel(ctx, req.Name, req.Tunnel)
+	if err != nil {
+		return nil, err
+	}
+	req.Tunnel = tunnel
+	domain, err := s.resolveDomain(ctx, req.Tunnel, req.Domain)
 	if err != nil {
 		return nil, err
 	}
@@ -136,6 +160,12 @@ func (s *ProjectService) CreateFromTemplate(ctx context.Context, req CreateTempl
 		return nil, err
 	}
 	req.Template = normalizedTemplate
+	if err := normalizeProjectAccessRequest(req.Access); err != nil {
+		return nil, err
+	}
+	if err := normalizeProjectIngressRequest(req.Ingress); err != nil {
+		return nil, err
+	}
 	return s.jobs.Create(ctx, JobTypeCreateTemplate, req)
 }
 
@@ -148,7 +178,12 @@ func (s *ProjectService) DeployExisting(ctx context.Context, req DeployExistingR
 	if err := validate.Subdomain(req.Subdomain); err != nil {
 		return nil, err
 	}
-	domain, err := s.resolveDomain(ctx, req.Domain)
+	tunnel, err := s.resolveTunnel(ctx, req.Name, req.Tunnel)
+	if err != nil {
+		return nil, err
+	}
+	req.Tunnel = tunnel
+	domain, err := s.resolveDomain(ctx, req.Tunnel, req.Domain)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +194,12 @@ func (s *ProjectService) DeployExisting(ctx context.Context, req DeployExistingR
 	if err := validate.Port(req.Port); err != nil {
 		return nil, err
 	}
+	if err := normalizeProjectAccessRequest(req.Access); err != nil {
+		return nil, err
+	}
+	if err := normalizeProjectIngressRequest(req.Ingress); err != nil {
+		return nil, err
+	}
 	return s.jobs.Create(ctx, JobTypeDeployExisting, req)
 }
 
@@ -171,7 +212,11 @@ func (s *ProjectService) ForwardLocal(ctx context.Context, req ForwardLocalReque
 	if err := validate.Subdomain(req.Subdomain); err != nil {
 		return nil, err
 	}
-	domain, err := s.resolveDomain(ctx, req.Domain)
+	req.Tunnel = normalizeTunnelName(req.Tunnel)
+	if err := s.tunnels.Exists(ctx, req.Tunnel); err != nil {
+		return nil, err
+	}
+	domain, err := s.resolveDomain(ctx, req.Tunnel, req.Domain)
 	if err != nil {
 		return nil, err
 	}
@@ -182,6 +227,9 @@ func (s *ProjectService) ForwardLocal(ctx context.Context, req ForwardLocalReque
 	if err := validate.Port(req.Port); err != nil {
 		return nil, err
 	}
+	if err := normalizeProjectIngressRequest(req.Ingress); err != nil {
+		return nil, err
+	}
 	return s.jobs.Create(ctx, JobTypeForwardLocal, req)
 }
 
@@ -222,14 +270,14 @@ func (s *ProjectService) QuickService(ctx context.Context, req QuickServiceReque
 	req.RequestedPort = requestedPort
 	req.Port = chosenPort
 	if quickServiceRequiresPublishedPort(exposureMode) {
-		domain, err := s.resolveDomain(ctx, req.Domain)
+		domain, err := s.resolveDomain(ctx, "", req.Domain)
 		if err != nil {
 			return nil, 0, err
 		}
 		req.Domain = domain
 	} else {
 		if strings.TrimSpace(req.Domain) != "" {
-			domain, err := s.resolveDomain(ctx, req.Domain)
+			domain, err := s.resolveDomain(ctx, "", req.Domain)
 			if err != nil {
 				return nil, 0, err
 			}
@@ -245,7 +293,47 @@ func (s *ProjectService) QuickService(ctx context.Context, req QuickServiceReque
 	return job, req.Port, nil
 }
 
-func (s *ProjectService) resolveDomain(ctx context.Context, requested string) (string, error) {
+// resolveTunnel checks the tunnel a deploy names. A registered project stays
+// on its tunnel; moving it is a separate job that also moves its other
+// hostnames.
+func (s *ProjectService) resolveTunnel(ctx context.Context, project, requested string) (string, error) {
+	tunnel := normalizeTunnelName(requested)
+	if err := s.tunnels.Exists(ctx, tunnel); err != nil {
+		return "", err
+	}
+	existing, err := s.repo.GetByName(ctx, project)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return tunnel, nil
+		}
+		return "", err
+	}
+	current := normalizeTunnelName(existing.Tunnel)
+	if strings.TrimSpace(requested) == "" {
+		return current, nil
+	}
+	if current != tunnel {
+		return "", errs.New(errs.CodeTunnelConflict, fmt.Sprintf("%s is served by tunnel %s; move the project before deploying to %s", project, describeTunnelName(current), describeTunnelName(tunnel)))
+	}
+	return tunnel, nil
+}
+
+func (s *ProjectService) resolveDomain(ctx context.Context, tunnel, requested string) (string, error) {
+	if s.settings != nil && s.tunnels != nil && tunnel != "" {
+		base, err := s.settings.ResolveConfig(ctx)
+		if err != nil {
+			return "", err
+		}
+		cfg, err := s.tunnels.Config(ctx, base, tunnel)
+		if err != nil {
+			return "", err
+		}
+		selection, err := selectTunnelDomain(ctx, s.settings, base, cfg, requested)
+		if err != nil {
+			return "", err
+		}
+		return selection.Domain, nil
+	}
 	if s.settings != nil {
 		selection, err := s.settings.ResolveDomainSelection(ctx, requested)
 		if err != nil {
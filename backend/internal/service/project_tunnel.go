@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+)
+
+// ProjectTunnelRule is an ingress rule a tunnel move recreates on the new
+// tunnel. ZoneID is the zone of the hostname's CNAME record.
+type ProjectTunnelRule struct {
+	Hostname string                    `json:"hostname"`
+	Path     string                    `json:"path,omitempty"`
+	Port     int                       `json:"port"`
+	Scheme   string                    `json:"scheme,omitempty"`
+	Origin   *cloudflare.IngressOrigin `json:"origin,omitempty"`
+	ZoneID   string                    `json:"zoneId,omitempty"`
+}
+
+// ProjectTunnelPlan describes a move of a project's hostnames from one tunnel
+// to another. Tunnel names are DefaultTunnelName for the settings tunnel.
+type ProjectTunnelPlan struct {
+	Project  string              `json:"project"`
+	From     string              `json:"from"`
+	To       string              `json:"to"`
+	Rules    []ProjectTunnelRule `json:"rules"`
+	Warnings []string            `json:"warnings"`
+}
+
+// ProjectTunnelJobRequest is the job input for moving a project to another
+// tunnel. Previous lists the rules removed from the old tunnel once the new
+// one serves every hostname.
+type ProjectTunnelJobRequest struct {
+	Project     string                              `json:"project"`
+	From        string                              `json:"from"`
+	To          string                              `json:"to"`
+	Rules       []ProjectTunnelRule                 `json:"rules"`
+	Targets     ProjectRoutesTargets                `json:"targets"`
+	Previous    []ProjectArchiveIngressDeleteTarget `json:"previous"`
+	PlannedAt   time.Time                           `json:"plannedAt"`
+	RequestedBy ProjectArchiveActor                 `json:"requestedBy"`
+}
+
+// SetTunnels resolves each project's Cloudflare settings from the tunnel
+// assigned to it.
+func (s *ProjectArchiveService) SetTunnels(tunnels *TunnelService) {
+	s.tunnels = tunnels
+}
+
+// QueueTunnel queues a job that moves the project's ingress rules and DNS
+// records to tunnel and records the new assignment. The rules keep their
+// ports, schemes, and origin options.
+func (s *ProjectArchiveService) QueueTunnel(
+	ctx context.Context,
+	projectName string,
+	tunnel string,
+	actor ProjectArchiveActor,
+) (*models.Job, ProjectTunnelPlan, error) {
+	if s.jobs == nil {
+		return nil, ProjectTunnelPlan{}, fmt.Errorf("job service unavailable")
+	}
+	if s.tunnels == nil {
+		return nil, ProjectTunnelPlan{}, errs.New(errs.CodeTunnelUnavailable, "tunnel service unavailable")
+	}
+	base, err := s.resolveRuntimeConfig(ctx)
+	if err != nil {
+		return nil, ProjectTunnelPlan{}, err
+	}
+	resolved, err := resolveProjectPath(ctx, s.projects, base.TemplatesDir, projectName, s.runtimeMetaClient())
+	if err != nil {
+		return nil, ProjectTunnelPlan{}, err
+	}
+	project := resolved.NormalizedName
+	if resolved.ProjectRecord == nil {
+		return nil, ProjectTunnelPlan{}, errs.New(errs.CodeProjectNotFound, fmt.Sprintf("project %s is not registered", project))
+	}
+
+	from := normalizeTunnelName(resolved.ProjectRecord.Tunnel)
+	to := normalizeTunnelName(tunnel)
+	if from == to {
+		return nil, ProjectTunnelPlan{}, errs.New(errs.CodeProjectTunnelUnchanged, fmt.Sprintf("%s already uses tunnel %s", project, describeTunnelName(to)))
+	}
+	fromCfg, err := s.tunnels.Config(ctx, base, from)
+	if err != nil {
+		return nil, ProjectTunnelPlan{}, err
+	}
+	toCfg, err := s.tunnels.Config(ctx, base, to)
+	if err != nil {
+		return nil, ProjectTunnelPlan{}, err
+	}
+	// Removing the old rules would delete the new ones when both names point
+	// at the same Cloudflare tunnel.
+	if fromCfg.CloudflareAccountID == toCfg.CloudflareAccountID && strings.EqualFold(fromCfg.CloudflaredTunnel, toCfg.CloudflaredTunnel) {
+		return nil, ProjectTunnelPlan{}, errs.New(errs.CodeProjectTunnelUnchanged, fmt.Sprintf("tunnels %s and %s are the same Cloudflare tunnel", describeTunnelName(from), describeTunnelName(to)))
+	}
+
+	warnings := make(map[string]struct{})
+	cfClient := cloudflare.NewClient(fromCfg)
+	hostnames := s.discoverHostnames(ctx, project, normalizeDomain(base.Domain), warnings)
+	zones := make(map[string]string)
+	for _, record := range s.planDNSRecords(ctx, fromCfg, cfClient, hostnames, warnings) {
+		if _, ok := zones[record.Name]; !ok && record.ZoneID != "" {
+			zones[record.Name] = record.ZoneID
+		}
+	}
+
+	plan := ProjectTunnelPlan{Project: project, From: describeTunnelName(from), To: describeTunnelName(to), Rules: []ProjectTunnelRule{}}
+	previous := make([]ProjectArchiveIngressDeleteTarget, 0)
+	seen := make(map[string]struct{})
+	rules := s.planIngress(ctx, fromCfg, cfClient, hostnames, warnings)
+	// Remote rules come first so they win over a local copy of the same route.
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Source == "remote" && rules[j].Source != "remote"
+	})
+	for _, rule := range rules {
+		previous = append(previous, ProjectArchiveIngressDeleteTarget{
+			Hostname: rule.Hostname,
+			Path:     rule.Path,
+			Service:  strings.TrimSpace(rule.Service),
+			Source:   rule.Source,
+		})
+		key := projectRouteKey(rule.Hostname, rule.Path)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		port, ok := ingressServiceLocalPort(rule.Service)
+		if !ok {
+			return nil, ProjectTunnelPlan{}, errs.New(errs.CodeProjectIngressInvalid, fmt.Sprintf("%s routes to %s, which is not a local port", describeIngressRoute(cloudflare.IngressRule{Hostname: rule.Hostname, Path: rule.Path}), rule.Service))
+		}
+		zoneID := zones[rule.Hostname]
+		if zoneID == "" {
+			zoneID = strings.TrimSpace(toCfg.CloudflareZoneID)
+		}
+		plan.Rules = append(plan.Rules, ProjectTunnelRule{
+			Hostname: rule.Hostname,
+			Path:     rule.Path,
+			Port:     port,
+			Scheme:   ingressServiceScheme(rule.Service),
+			Origin:   rule.Origin,
+			ZoneID:   zoneID,
+		})
+	}
+	plan.Warnings = sortedArchiveWarnings(warnings)
+
+	job, err := s.jobs.Create(ctx, JobTypeProjectTunnel, ProjectTunnelJobRequest{
+		Project:     project,
+		From:        from,
+		To:          to,
+		Rules:       plan.Rules,
+		Targets:     ProjectRoutesTargets{Hostnames: hostnames},
+		Previous:    previous,
+		PlannedAt:   time.Now().UTC(),
+		RequestedBy: actor,
+	})
+	if err != nil {
+		return nil, ProjectTunnelPlan{}, err
+	}
+	return job, plan, nil
+}
+
+// ingressServiceScheme returns the scheme of a localhost service URL, or ""
+// when it has none gungnr manages.
+func ingressServiceScheme(service string) string {
+	prefix, _, ok := strings.Cut(strings.TrimSpace(service), "://")
+	if !ok {
+		return ""
+	}
+	scheme, err := cloudflare.NormalizeIngressScheme(prefix)
+	if err != nil {
+		return ""
+	}
+	return scheme
+}
+
+func describeTunnelName(name string) string {
+	if name == "" {
+		return DefaultTunnelName
+	}
+	return name
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-notes/internal/config"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/validate"
+)
+
+// SetTunnels runs each project's tunnel work against the tunnel assigned to
+// it instead of the one in settings.
+func (w *ProjectWorkflows) SetTunnels(tunnels *TunnelService) {
+	w.tunnels = tunnels
+}
+
+// resolveProjectRuntimeConfig is resolveArchiveRuntimeConfig with the
+// Cloudflare settings of the tunnel serving project.
+func (w *ProjectWorkflows) resolveProjectRuntimeConfig(ctx context.Context, project string) (config.Config, error) {
+	runtimeCfg, err := w.resolveArchiveRuntimeConfig(ctx)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return w.tunnels.ProjectConfig(ctx, runtimeCfg, project)
+}
+
+// deployTunnelConfig picks the tunnel a deploy job puts its hostname on: the
+// requested one, else the project's current one. It returns the stored
+// tunnel name ("" for the settings tunnel) and base overlaid with its config.
+func (w *ProjectWorkflows) deployTunnelConfig(ctx context.Context, base config.Config, project, requested string) (string, config.Config, error) {
+	tunnel := normalizeTunnelName(requested)
+	if tunnel == "" && strings.TrimSpace(requested) == "" && project != "" {
+		current, err := w.tunnels.ProjectTunnel(ctx, project)
+		if err != nil {
+			return "", config.Config{}, err
+		}
+		tunnel = current
+	}
+	cfg, err := w.tunnels.Config(ctx, base, tunnel)
+	if err != nil {
+		return "", config.Config{}, err
+	}
+	return tunnel, cfg, nil
+}
+
+func (w *ProjectWorkflows) handleProjectTunnel(ctx context.Context, job models.Job, logger jobs.Logger) error {
+	var req ProjectTunnelJobRequest
+	if err := json.Unmarshal([]byte(job.Input), &req); err != nil {
+		return fmt.Errorf("parse tunnel move request: %w", err)
+	}
+	runtimeCfg, err := w.resolveArchiveRuntimeConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("resolve runtime config: %w", err)
+	}
+	fromCfg, err := w.tunnels.Config(ctx, runtimeCfg, req.From)
+	if err != nil {
+		return fmt.Errorf("resolve tunnel %s: %w", describeTunnelName(req.From), err)
+	}
+	toCfg, err := w.tunnels.Config(ctx, runtimeCfg, req.To)
+	if err != nil {
+		return fmt.Errorf("resolve tunnel %s: %w", describeTunnelName(req.To), err)
+	}
+	return w.runProjectTunnel(ctx, logger, fromCfg, toCfg, cloudflare.NewClient(fromCfg), cloudflare.NewClient(toCfg), fmt.Sprintf("job-%d", job.ID), req)
+}
+
+// runProjectTunnel recreates the project's ingress rules on the new tunnel,
+// points its CNAME records there, and verifies both before recording the
+// assignment and removing the rules from the old tunnel. A failure before the
+// records switch leaves the old tunnel serving traffic.
+func (w *ProjectWorkflows) runProjectTunnel(
+	ctx context.Context,
+	logger jobs.Logger,
+	fromCfg config.Config,
+	toCfg config.Config,
+	fromClient projectHostnameCloudflareClient,
+	toClient projectHostnameCloudflareClient,
+	requestID string,
+	req ProjectTunnelJobRequest,
+) error {
+	req.Project = strings.ToLower(strings.TrimSpace(req.Project))
+	if err := validate.ProjectName(req.Project); err != nil {
+		return err
+	}
+	from, to := describeTunnelName(req.From), describeTunnelName(req.To)
+	warnings := make(map[string]struct{})
+
+	type hostnameRoutes struct {
+		hostname string
+		zoneID   string
+		routes   []ProjectHostnameRoute
+	}
+	groups := make([]hostnameRoutes, 0)
+	index := make(map[string]int)
+	for _, rule := range req.Rules {
+		rule.Hostname = strings.ToLower(strings.TrimSpace(rule.Hostname))
+		if err := validate.Domain(rule.Hostname); err != nil {
+			return err
+		}
+		if err := validate.Port(rule.Port); err != nil {
+			return err
+		}
+		i, ok := index[rule.Hostname]
+		if !ok {
+			i = len(groups)
+			index[rule.Hostname] = i
+			groups = append(groups, hostnameRoutes{hostname: rule.Hostname, zoneID: strings.TrimSpace(rule.ZoneID)})
+		}
+		groups[i].routes = append(groups[i].routes, ProjectHostnameRoute{Path: rule.Path, Port: rule.Port})
+	}
+
+	logProjectStepStart(logger, "tunnel", "add", "from=%s to=%s rules=%d", from, to, len(req.Rules))
+	for _, rule := range req.Rules {
+		route := cloudflare.IngressRule{Hostname: strings.ToLower(strings.TrimSpace(rule.Hostname)), Path: rule.Path}
+		opts := cloudflare.IngressRouteOptions{Scheme: rule.Scheme, Origin: rule.Origin}
+		logger.Logf("configuring tunnel ingress for %s on %s", describeIngressRoute(route), to)
+		if err := w.updateTunnelIngressRouteOptions(ctx, logger, toCfg, toClient, requestID, route.Hostname, route.Path, rule.Port, opts); err != nil {
+			logProjectStepResult(logger, "tunnel", "add", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("add %s to tunnel %s: %w; tunnel %s still serves the project", describeIngressRoute(route), to, err, from)
+		}
+	}
+	logProjectStepResult(logger, "tunnel", "add", projectArchiveStepStatusCompleted, "rules=%d", len(req.Rules))
+
+	logProjectStepStart(logger, "tunnel", "dns", "hostnames=%d", len(groups))
+	for _, group := range groups {
+		logger.Logf("pointing Cloudflare DNS record for %s at tunnel %s", group.hostname, to)
+		if err := toClient.EnsureDNSForZone(ctx, group.hostname, group.zoneID); err != nil {
+			logProjectStepResult(logger, "tunnel", "dns", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("point %s at tunnel %s: cloudflare dns: %w", group.hostname, to, err)
+		}
+	}
+	logProjectStepResult(logger, "tunnel", "dns", projectArchiveStepStatusCompleted, "hostnames=%d", len(groups))
+
+	logProjectStepStart(logger, "tunnel", "verify", "hostnames=%d", len(groups))
+	for _, group := range groups {
+		if err := verifyProjectHostname(ctx, toCfg, toClient, group.hostname, group.zoneID, group.routes); err != nil {
+			logProjectStepResult(logger, "tunnel", "verify", projectArchiveStepStatusFailed, "error=%q", err.Error())
+			return fmt.Errorf("verify %s on tunnel %s: %w; rules on tunnel %s were left in place", group.hostname, to, err, from)
+		}
+	}
+	logProjectStepResult(logger, "tunnel", "verify", projectArchiveStepStatusCompleted, "dns=ok ingress=ok")
+
+	logProjectStepStart(logger, "tunnel", "assign", "project=%s tunnel=%s", req.Project, to)
+	record, err := w.projects.GetByName(ctx, req.Project)
+	if err == nil {
+		record.Tunnel = req.To
+		err = w.projects.Update(ctx, record)
+	}
+	if err != nil {
+		logProjectStepResult(logger, "tunnel", "assign", projectArchiveStepStatusFailed, "error=%q", err.Error())
+		return fmt.Errorf("record tunnel %s for %s: %w", to, req.Project, err)
+	}
+	logProjectStepResult(logger, "tunnel", "assign", projectArchiveStepStatusCompleted, "tunnel=%s", to)
+
+	removal := w.removeProjectRouteTargets(ctx, logger, fromCfg, fromClient, requestID, "tunnel", len(groups), normalizeIngressDeleteTargets(req.Previous), nil, warnings)
+
+	sortedWarnings := sortedArchiveWarnings(warnings)
+	outcome := "completed"
+	if removal.status == projectArchiveStepStatusPartialFailure {
+		outcome = "partial_failure"
+	} else if len(sortedWarnings) > 0 {
+		outcome = "completed_with_warnings"
+	}
+	if w.audit != nil {
+		if err := w.audit.Log(ctx, AuditEntry{
+			UserID:    req.RequestedBy.UserID,
+			UserLogin: req.RequestedBy.Login,
+			Action:    "project.tunnel.completed",
+			Target:    req.Project,
+			Metadata: map[string]any{
+				"project":       req.Project,
+				"from":          from,
+				"to":            to,
+				"rules":         len(req.Rules),
+				"removedRemote": removal.remote,
+				"removedLocal":  removal.local,
+				"outcome":       outcome,
+				"warnings":      sortedWarnings,
+			},
+		}); err != nil {
+			logger.Logf("audit warning: failed to write tunnel move completion event: %v", err)
+		}
+	}
+
+	logger.Logf(
+		"tunnel move completion summary: outcome=%s warnings=%d steps=add:%s dns:%s verify:%s assign:%s remove_old:%s",
+		outcome,
+		len(sortedWarnings),
+		projectArchiveStepStatusCompleted,
+		projectArchiveStepStatusCompleted,
+		projectArchiveStepStatusCompleted,
+		projectArchiveStepStatusCompleted,
+		removal.status,
+	)
+	for _, warning := range sortedWarnings {
+		logger.Logf("warning: %s", warning)
+	}
+	return nil
+}
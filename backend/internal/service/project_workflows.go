@@ -39,11 +39,23 @@ type ProjectWorkflows struct {
 	workbench    *WorkbenchService
 	dockerRunner *DockerRunner
 	infraClient  infraBridgeClient
+	deployments  repository.DeploymentRepository
+	fileClient   infraProjectFileMutationClient
+	secrets      *SecretsService
+	reconcile    *CloudflareReconcileService
+	tunnels      *TunnelService
 }
 
 type cloudflareWorkflowClient interface {
 	EnsureDNSForZone(ctx context.Context, hostname string, zoneID string) error
-	UpdateIngress(ctx context.Context, hostname string, port int) error
+	UpdateIngressRouteOptions(ctx context.Context, hostname, path string, port int, opts cloudflare.IngressRouteOptions) error
+}
+
+// cloudflareAccessWorkflowClient is cloudflareWorkflowClient for jobs that put
+// a hostname behind Access before routing it.
+type cloudflareAccessWorkflowClient interface {
+	cloudflareWorkflowClient
+	projectAccessClient
 }
 
 type infraPortProbeClient interface {
@@ -53,7 +65,7 @@ type infraPortProbeClient interface {
 
 type infraBridgeClient interface {
 	infraPortProbeClient
-	RestartTunnel(ctx context.Context, requestID, configPath string) (contract.Result, error)
+	RestartTunnel(ctx context.Context, requestID, configPath, metricsAddress string) (contract.Result, error)
 }
 
 const bridgeProbeWaitTimeout = 2 * time.Second
@@ -86,6 +98,15 @@ func (w *ProjectWorkflows) Register(runner *jobs.Runner) {
 	runner.Register(JobTypeForwardLocal, w.handleForwardLocal)
 	runner.Register(JobTypeQuickService, w.handleQuickService)
 	runner.Register(JobTypeProjectArchive, w.handleProjectArchive)
+	runner.Register(JobTypeBlueGreenDeploy, w.handleBlueGreenDeploy)
+	runner.Register(JobTypeGitRedeploy, w.handleGitRedeploy)
+	runner.Register(JobTypeProjectHostnames, w.handleProjectHostnameChange)
+	runner.Register(JobTypeProjectRoutes, w.handleProjectRoutes)
+	runner.Register(JobTypeProjectIngress, w.handleProjectIngress)
+	runner.Register(JobTypeProjectTunnel, w.handleProjectTunnel)
+	runner.Register(JobTypeProjectRestore, w.handleProjectRestore)
+	runner.Register(JobTypeProjectClone, w.handleProjectClone)
+	runner.Register(JobTypeCloudflareReconcileCleanup, w.handleCloudflareReconcileCleanup)
 }
 
 func (w *ProjectWorkflows) handleCreateTemplate(ctx context.Context, job models.Job, logger jobs.Logger) error {
@@ -112,7 +133,11 @@ func (w *ProjectWorkflows) handleCreateTemplate(ctx context.Context, job models.
 	if err != nil {
 		return fmt.Errorf("load settings: %w", err)
 	}
-	selection, err := w.resolveDomainSelection(ctx, req.Domain)
+	tunnel, tunnelCfg, err := w.deployTunnelConfig(ctx, runtimeCfg, req.Name, req.Tunnel)
+	if err != nil {
+		return err
+	}
+	selection, err := w.resolveDomainSelection(ctx, tunnelCfg, req.Domain)
 	if err != nil {
 		return err
 	}
@@ -143,25 +168,13 @@ func (w *ProjectWorkflows) handleCreateTemplate(ctx context.Context, job models.
 	if err := os.MkdirAll(runtimeCfg.TemplatesDir, 0o755); err != nil {
 		return fmt.Errorf("create templates dir: %w", err)
 	}
-	appSettings, configured, err := w.settings.GitHubAppSettings(ctx)
+	githubToken, configured, err := w.githubInstallationToken(ctx)
 	if err != nil {
-		return fmt.Errorf("load github app settings: %w", err)
+		return err
 	}
 	if !configured {
 		return fmt.Errorf("github app settings are incomplete")
 	}
-	creds, err := gh.ParseAppInstallationCredentials(
-		appSettings.AppID,
-		appSettings.InstallationID,
-		appSettings.PrivateKey,
-	)
-	if err != nil {
-		return fmt.Errorf("github app credentials: %w", err)
-	}
-	githubToken, err := gh.MintInstallationToken(ctx, creds)
-	if err != nil {
-		return err
-	}
 
 	logger.Logf("creating GitHub repo from template for %s (%s/%s)", req.Name, templateOwner, templateRepo)
 	logger.Log("using GitHub App installation token for template creation")
@@ -241,6 +254,7 @@ func (w *ProjectWorkflows) handleCreateTemplate(ctx context.Context, job models.
 		Path:      projectDir,
 		ProxyPort: proxyPort,
 		DBPort:    dbPort,
+		Tunnel:    tunnel,
 		Status:    "provisioning",
 	}
 	projectRecord, err := w.upsertProject(ctx, &project)
@@ -255,9 +269,12 @@ func (w *ProjectWorkflows) handleCreateTemplate(ctx context.Context, job models.
 
 	hostname := fmt.Sprintf("%s.%s", req.Subdomain, selection.Domain)
 	logger.Logf("configuring tunnel ingress for %s", hostname)
-	cloudflareClient := cloudflare.NewClient(runtimeCfg)
+	cloudflareClient := cloudflare.NewClient(tunnelCfg)
+	if err := protectProjectHostname(ctx, logger, cloudflareClient, hostname, req.Access); err != nil {
+		return err
+	}
 	requestID := fmt.Sprintf("job-%d", job.ID)
-	if err := w.cloudflareSetup(ctx, logger, runtimeCfg, cloudflareClient, requestID, hostname, selection.Domain, selection.ZoneID, proxyPort); err != nil {
+	if err := w.cloudflareSetupOptions(ctx, logger, tunnelCfg, cloudflareClient, requestID, hostname, selection.Domain, selection.ZoneID, proxyPort, req.Ingress.options()); err != nil {
 		return err
 	}
 
@@ -294,7 +311,11 @@ func (w *ProjectWorkflows) handleDeployExisting(ctx context.Context, job models.
 	if err != nil {
 		return fmt.Errorf("load settings: %w", err)
 	}
-	selection, err := w.resolveDomainSelection(ctx, req.Domain)
+	tunnel, tunnelCfg, err := w.deployTunnelConfig(ctx, runtimeCfg, req.Name, req.Tunnel)
+	if err != nil {
+		return err
+	}
+	selection, err := w.resolveDomainSelection(ctx, tunnelCfg, req.Domain)
 	if err != nil {
 		return err
 	}
@@ -320,9 +341,12 @@ func (w *ProjectWorkflows) handleDeployExisting(ctx context.Context, job models.
 
 	hostname := fmt.Sprintf("%s.%s", req.Subdomain, selection.Domain)
 	logger.Logf("configuring tunnel ingress for %s", hostname)
-	cloudflareClient := cloudflare.NewClient(runtimeCfg)
+	cloudflareClient := cloudflare.NewClient(tunnelCfg)
+	if err := protectProjectHostname(ctx, logger, cloudflareClient, hostname, req.Access); err != nil {
+		return err
+	}
 	requestID := fmt.Sprintf("job-%d", job.ID)
-	if err := w.cloudflareSetup(ctx, logger, runtimeCfg, cloudflareClient, requestID, hostname, selection.Domain, selection.ZoneID, req.Port); err != nil {
+	if err := w.cloudflareSetupOptions(ctx, logger, tunnelCfg, cloudflareClient, requestID, hostname, selection.Domain, selection.ZoneID, req.Port, req.Ingress.options()); err != nil {
 		return err
 	}
 
@@ -330,6 +354,7 @@ func (w *ProjectWorkflows) handleDeployExisting(ctx context.Context, job models.
 		Name:      req.Name,
 		Path:      projectDir,
 		ProxyPort: req.Port,
+		Tunnel:    tunnel,
 		Status:    "running",
 	}
 	if _, err := w.upsertProject(ctx, &project); err != nil {
@@ -566,16 +591,20 @@ func (w *ProjectWorkflows) handleForwardLocal(ctx context.Context, job models.Jo
 	if err != nil {
 		return fmt.Errorf("load settings: %w", err)
 	}
-	selection, err := w.resolveDomainSelection(ctx, req.Domain)
+	_, tunnelCfg, err := w.deployTunnelConfig(ctx, runtimeCfg, "", req.Tunnel)
+	if err != nil {
+		return err
+	}
+	selection, err := w.resolveDomainSelection(ctx, tunnelCfg, req.Domain)
 	if err != nil {
 		return err
 	}
 
 	hostname := fmt.Sprintf("%s.%s", req.Subdomain, selection.Domain)
 	logger.Logf("configuring tunnel ingress for %s", hostname)
-	cloudflareClient := cloudflare.NewClient(runtimeCfg)
+	cloudflareClient := cloudflare.NewClient(tunnelCfg)
 	requestID := fmt.Sprintf("job-%d", job.ID)
-	if err := w.cloudflareSetup(ctx, logger, runtimeCfg, cloudflareClient, requestID, hostname, selection.Domain, selection.ZoneID, req.Port); err != nil {
+	if err := w.cloudflareSetupOptions(ctx, logger, tunnelCfg, cloudflareClient, requestID, hostname, selection.Domain, selection.ZoneID, req.Port, req.Ingress.options()); err != nil {
 		return err
 	}
 
@@ -653,7 +682,7 @@ func (w *ProjectWorkflows) handleQuickService(ctx context.Context, job models.Jo
 	if err != nil {
 		return fmt.Errorf("load settings: %w", err)
 	}
-	selection, err := w.resolveDomainSelection(ctx, req.Domain)
+	selection, err := w.resolveDomainSelection(ctx, runtimeCfg, req.Domain)
 	if err != nil {
 		return err
 	}
@@ -672,6 +701,9 @@ func (w *ProjectWorkflows) runCompose(ctx context.Context, logger jobs.Logger, p
 	if w.dockerRunner == nil {
 		return fmt.Errorf("docker runner unavailable")
 	}
+	if err := w.injectProjectSecrets(ctx, logger, projectDir); err != nil {
+		return err
+	}
 	return w.dockerRunner.ComposeUp(ctx, logger, DockerComposeRequest{ProjectDir: projectDir})
 }
 
@@ -690,6 +722,12 @@ func (w *ProjectWorkflows) runContainer(ctx context.Context, logger jobs.Logger,
 }
 
 func (w *ProjectWorkflows) cloudflareSetup(ctx context.Context, logger jobs.Logger, cfg config.Config, cloudfl cloudflareWorkflowClient, requestID, hostname, domain, zoneID string, port int) error {
+	return w.cloudflareSetupOptions(ctx, logger, cfg, cloudfl, requestID, hostname, domain, zoneID, port, cloudflare.IngressRouteOptions{})
+}
+
+// cloudflareSetupOptions is cloudflareSetup with a service scheme and origin
+// options for the hostname's ingress rule.
+func (w *ProjectWorkflows) cloudflareSetupOptions(ctx context.Context, logger jobs.Logger, cfg config.Config, cloudfl cloudflareWorkflowClient, requestID, hostname, domain, zoneID string, port int, opts cloudflare.IngressRouteOptions) error {
 	if strings.TrimSpace(domain) == "" {
 		return fmt.Errorf("domain not configured")
 	}
@@ -708,11 +746,25 @@ func (w *ProjectWorkflows) cloudflareSetup(ctx context.Context, logger jobs.Logg
 		logger.Logf("cloudflare dns error: %v", err)
 		return fmt.Errorf("cloudflare dns: %w", err)
 	}
+	return w.updateTunnelIngressRouteOptions(ctx, logger, cfg, cloudfl, requestID, hostname, "", port, opts)
+}
+
+// updateTunnelIngressRoute points the hostname/path rule at localhost:port,
+// through the Cloudflare API for remotely managed tunnels or the local
+// cloudflared config otherwise. path is a cloudflared path regex; empty
+// matches every path.
+func (w *ProjectWorkflows) updateTunnelIngressRoute(ctx context.Context, logger jobs.Logger, cfg config.Config, cloudfl cloudflareWorkflowClient, requestID, hostname, path string, port int) error {
+	return w.updateTunnelIngressRouteOptions(ctx, logger, cfg, cloudfl, requestID, hostname, path, port, cloudflare.IngressRouteOptions{})
+}
+
+// updateTunnelIngressRouteOptions is updateTunnelIngressRoute with a service
+// scheme and origin options. Zero options keep those of an existing rule.
+func (w *ProjectWorkflows) updateTunnelIngressRouteOptions(ctx context.Context, logger jobs.Logger, cfg config.Config, cloudfl cloudflareWorkflowClient, requestID, hostname, path string, port int, opts cloudflare.IngressRouteOptions) error {
 	logger.Log("updating Cloudflare tunnel ingress")
-	if err := cloudfl.UpdateIngress(ctx, hostname, port); err != nil {
+	if err := cloudfl.UpdateIngressRouteOptions(ctx, hostname, path, port, opts); err != nil {
 		if errors.Is(err, cloudflare.ErrTunnelNotRemote) {
 			logger.Log("tunnel is locally managed; updating local cloudflared config instead")
-			if updateErr := cloudflare.UpdateLocalIngress(cfg.CloudflaredConfig, hostname, port); updateErr != nil {
+			if updateErr := cloudflare.UpdateLocalIngressRouteOptions(cfg.CloudflaredConfig, hostname, path, port, opts); updateErr != nil {
 				logger.Logf("cloudflared config update error: %v", updateErr)
 				return fmt.Errorf("cloudflared ingress: %w", updateErr)
 			}
@@ -721,7 +773,7 @@ func (w *ProjectWorkflows) cloudflareSetup(ctx context.Context, logger jobs.Logg
 				return nil
 			}
 			logger.Logf("submitting restart_tunnel intent via infra bridge (request_id=%s)", strings.TrimSpace(requestID))
-			result, restartErr := w.infraClient.RestartTunnel(ctx, requestID, cfg.CloudflaredConfig)
+			result, restartErr := w.infraClient.RestartTunnel(ctx, requestID, cfg.CloudflaredConfig, cfg.CloudflaredMetrics)
 			if restartErr != nil {
 				if ctx.Err() != nil {
 					return ctx.Err()
@@ -753,27 +805,42 @@ func (w *ProjectWorkflows) cloudflareSetup(ctx context.Context, logger jobs.Logg
 		logger.Logf("cloudflare ingress error: %v", err)
 		return fmt.Errorf("cloudflare ingress: %w", err)
 	}
-	if updateErr := cloudflare.UpdateLocalIngress(cfg.CloudflaredConfig, hostname, port); updateErr != nil {
+	if updateErr := cloudflare.UpdateLocalIngressRouteOptions(cfg.CloudflaredConfig, hostname, path, port, opts); updateErr != nil {
 		logger.Logf("cloudflared config update skipped: %v", updateErr)
 	}
 	logger.Log("tunnel ingress updated via Cloudflare API")
 	return nil
 }
 
-func (w *ProjectWorkflows) resolveDomainSelection(ctx context.Context, requested string) (DomainSelection, error) {
-	if w.settings != nil {
-		selection, err := w.settings.ResolveDomainSelection(ctx, requested)
+// resolveDomainSelection resolves requested for a hostname on the tunnel cfg
+// describes, which may belong to another Cloudflare account than settings.
+func (w *ProjectWorkflows) resolveDomainSelection(ctx context.Context, cfg config.Config, requested string) (DomainSelection, error) {
+	if w.tunnels == nil {
+		return selectProjectDomain(ctx, w.settings, w.cfg, requested)
+	}
+	base, err := w.resolveArchiveRuntimeConfig(ctx)
+	if err != nil {
+		return DomainSelection{}, err
+	}
+	return selectTunnelDomain(ctx, w.settings, base, cfg, requested)
+}
+
+// selectProjectDomain resolves a requested domain against the managed domain
+// settings, falling back to the static config when settings are unavailable.
+func selectProjectDomain(ctx context.Context, settings *SettingsService, cfg config.Config, requested string) (DomainSelection, error) {
+	if settings != nil {
+		selection, err := settings.ResolveDomainSelection(ctx, requested)
 		if err != nil {
 			return DomainSelection{}, err
 		}
 		return selection, nil
 	}
-	base := normalizeDomain(w.cfg.Domain)
+	base := normalizeDomain(cfg.Domain)
 	selected, err := selectDomain(requested, base, nil)
 	if err != nil {
 		return DomainSelection{}, err
 	}
-	return DomainSelection{Domain: selected, ZoneID: strings.TrimSpace(w.cfg.CloudflareZoneID)}, nil
+	return DomainSelection{Domain: selected, ZoneID: strings.TrimSpace(cfg.CloudflareZoneID)}, nil
 }
 
 func (w *ProjectWorkflows) upsertProject(ctx context.Context, project *models.Project) (*models.Project, error) {
@@ -784,6 +851,7 @@ func (w *ProjectWorkflows) upsertProject(ctx context.Context, project *models.Pr
 		existing.ProxyPort = project.ProxyPort
 		existing.DBPort = project.DBPort
 		existing.Status = project.Status
+		existing.Tunnel = project.Tunnel
 		if err := w.projects.Update(ctx, existing); err != nil {
 			return nil, err
 		}
@@ -998,6 +1066,35 @@ func parsePublishedPorts(raw string) []int {
 	return ports
 }
 
+// githubInstallationToken mints a GitHub App installation token. The boolean
+// reports whether the app is configured at all; it is false with a nil error
+// when settings are incomplete.
+func (w *ProjectWorkflows) githubInstallationToken(ctx context.Context) (string, bool, error) {
+	if w.settings == nil {
+		return "", false, nil
+	}
+	appSettings, configured, err := w.settings.GitHubAppSettings(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("load github app settings: %w", err)
+	}
+	if !configured {
+		return "", false, nil
+	}
+	creds, err := gh.ParseAppInstallationCredentials(
+		appSettings.AppID,
+		appSettings.InstallationID,
+		appSettings.PrivateKey,
+	)
+	if err != nil {
+		return "", true, fmt.Errorf("github app credentials: %w", err)
+	}
+	token, err := gh.MintInstallationToken(ctx, creds)
+	if err != nil {
+		return "", true, err
+	}
+	return token, true, nil
+}
+
 func buildAuthenticatedCloneURL(rawURL, token string) (string, error) {
 	if token == "" {
 		return "", gh.ErrMissingToken
@@ -32,7 +32,7 @@ func (s *stubCloudflareWorkflowClient) EnsureDNSForZone(_ context.Context, _ str
 	return s.dnsErr
 }
 
-func (s *stubCloudflareWorkflowClient) UpdateIngress(_ context.Context, _ string, _ int) error {
+func (s *stubCloudflareWorkflowClient) UpdateIngressRouteOptions(_ context.Context, _, _ string, _ int, _ cloudflare.IngressRouteOptions) error {
 	return s.ingressErr
 }
 
@@ -42,6 +42,7 @@ type stubInfraBridgeClient struct {
 	called               bool
 	requestID            string
 	configPath           string
+	metricsAddress       string
 	fn                   func(ctx context.Context, requestID, configPath string) (contract.Result, error)
 	hostListenCalled     bool
 	hostListenRequestID  string
@@ -79,10 +80,11 @@ func (s *stubInfraBridgeClient) DockerPublishedPorts(ctx context.Context, reques
 	return s.dockerPortsResult, s.dockerPortsErr
 }
 
-func (s *stubInfraBridgeClient) RestartTunnel(ctx context.Context, requestID, configPath string) (contract.Result, error) {
+func (s *stubInfraBridgeClient) RestartTunnel(ctx context.Context, requestID, configPath, metricsAddress string) (contract.Result, error) {
 	s.called = true
 	s.requestID = requestID
 	s.configPath = configPath
+	s.metricsAddress = metricsAddress
 	if s.fn != nil {
 		return s.fn(ctx, requestID, configPath)
 	}
@@ -113,7 +115,7 @@ func TestCloudflareSetupLocalTunnelBridgeSuccess(t *testing.T) {
 	err := workflows.cloudflareSetup(
 		context.Background(),
 		logger,
-		config.Config{CloudflaredConfig: configPath},
+		config.Config{CloudflaredConfig: configPath, CloudflaredMetrics: "127.0.0.1:20242"},
 		cloudfl,
 		"job-99",
 		"app.example.com",
@@ -125,6 +127,7 @@ func TestCloudflareSetupLocalTunnelBridgeSuccess(t *testing.T) {
 	require.True(t, bridge.called)
 	require.Equal(t, "job-99", bridge.requestID)
 	require.Equal(t, configPath, bridge.configPath)
+	require.Equal(t, "127.0.0.1:20242", bridge.metricsAddress)
 
 	updated, readErr := os.ReadFile(configPath)
 	require.NoError(t, readErr)
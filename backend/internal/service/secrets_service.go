@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+	"go-notes/internal/utils/cryptox"
+	"go-notes/internal/validate"
+)
+
+const (
+	SecretScopePanel   = "panel"
+	SecretScopeProject = "project"
+
+	secretValueMaxBytes = 64 * 1024
+)
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// secretsWriteLock serializes version numbering and rotation.
+var secretsWriteLock sync.Mutex
+
+// SecretMetadata describes a secret without its value.
+type SecretMetadata struct {
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	KeyID     string    `json:"keyId"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+}
+
+// SecretsStatus reports whether the vault is usable and which key seals new
+// versions.
+type SecretsStatus struct {
+	Enabled        bool             `json:"enabled"`
+	KeyID          string           `json:"keyId,omitempty"`
+	PreviousKeyID  string           `json:"previousKeyId,omitempty"`
+	StaleVersions  int              `json:"staleVersions"`
+	PanelSecrets   []SecretMetadata `json:"panelSecrets"`
+	ProjectSecrets map[string]int   `json:"projectSecrets"`
+}
+
+// SecretsRotation counts the versions a rotation re-encrypted.
+type SecretsRotation struct {
+	KeyID       string `json:"keyId"`
+	ReEncrypted int    `json:"reEncrypted"`
+	Current     int    `json:"current"`
+}
+
+// SecretsService keeps panel and project secrets encrypted with SECRETS_KEY,
+// a key-encryption key that is independent of SESSION_SECRET. Every change
+// appends a version; SECRETS_KEY_PREVIOUS still opens versions sealed before
+// a rotation until Rotate rewrites them.
+type SecretsService struct {
+	repo        repository.SecretRepository
+	key         string
+	previousKey string
+}
+
+func NewSecretsService(cfg config.Config, repo repository.SecretRepository) *SecretsService {
+	return &SecretsService{
+		repo:        repo,
+		key:         strings.TrimSpace(cfg.SecretsKey),
+		previousKey: strings.TrimSpace(cfg.SecretsKeyPrevious),
+	}
+}
+
+func (s *SecretsService) Enabled() bool {
+	return s != nil && s.repo != nil && s.key != ""
+}
+
+func (s *SecretsService) Status(ctx context.Context) (SecretsStatus, error) {
+	status := SecretsStatus{PanelSecrets: []SecretMetadata{}, ProjectSecrets: map[string]int{}}
+	if !s.Enabled() {
+		return status, nil
+	}
+	status.Enabled = true
+	status.KeyID = cryptox.KeyID(s.key)
+	status.PreviousKeyID = cryptox.KeyID(s.previousKey)
+	rows, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return SecretsStatus{}, errs.Wrap(errs.CodeSecretsFailed, "failed to list secrets", err)
+	}
+	latest := map[string]models.SecretVersion{}
+	for _, row := range rows {
+		if row.KeyID != status.KeyID {
+			status.StaleVersions++
+		}
+		id := row.Scope + "/" + row.Project + "/" + row.Name
+		if current, ok := latest[id]; !ok || row.Version > current.Version {
+			latest[id] = row
+		}
+	}
+	for _, row := range latest {
+		switch row.Scope {
+		case SecretScopePanel:
+			status.PanelSecrets = append(status.PanelSecrets, secretMetadata(row))
+		case SecretScopeProject:
+			status.ProjectSecrets[row.Project]++
+		}
+	}
+	sort.Slice(status.PanelSecrets, func(i, j int) bool {
+		return status.PanelSecrets[i].Name < status.PanelSecrets[j].Name
+	})
+	return status, nil
+}
+
+// List returns the live version of every secret in scope, sorted by name.
+func (s *SecretsService) List(ctx context.Context, scope, project string) ([]SecretMetadata, error) {
+	latest, err := s.latest(ctx, scope, project)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]SecretMetadata, 0, len(latest))
+	for _, row := range latest {
+		items = append(items, secretMetadata(row))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
+
+// Versions returns every version of one secret, newest first.
+func (s *SecretsService) Versions(ctx context.Context, scope, project, name string) ([]SecretMetadata, error) {
+	rows, err := s.versions(ctx, scope, project, name)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]SecretMetadata, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, secretMetadata(row))
+	}
+	return items, nil
+}
+
+// Set stores value as a new version. Setting the live value again is a
+// no-op and returns the existing version. A live version that no longer
+// decrypts, e.g. after a key rotation, counts as changed, so it can be
+// overwritten.
+func (s *SecretsService) Set(ctx context.Context, scope, project, name, value string, actor ProjectArchiveActor) (SecretMetadata, error) {
+	if err := s.checkScope(scope, project); err != nil {
+		return SecretMetadata{}, err
+	}
+	if err := checkSecretName(name); err != nil {
+		return SecretMetadata{}, err
+	}
+	if value == "" {
+		return SecretMetadata{}, errs.New(errs.CodeSecretsInvalidBody, "secret value is required; delete the secret to clear it")
+	}
+	if len(value) > secretValueMaxBytes {
+		return SecretMetadata{}, errs.New(errs.CodeSecretsInvalidBody, fmt.Sprintf("secret value exceeds max size (%d bytes)", secretValueMaxBytes))
+	}
+
+	secretsWriteLock.Lock()
+	defer secretsWriteLock.Unlock()
+
+	rows, err := s.repo.ListVersions(ctx, scope, project, name)
+	if err != nil {
+		return SecretMetadata{}, errs.Wrap(errs.CodeSecretsFailed, "failed to load secret versions", err)
+	}
+	next := 1
+	if len(rows) > 0 {
+		if current, err := s.open(rows[0]); err == nil && current == value {
+			return secretMetadata(rows[0]), nil
+		}
+		next = rows[0].Version + 1
+	}
+	ciphertext, err := cryptox.EncryptWithSecret(s.key, value)
+	if err != nil {
+		return SecretMetadata{}, errs.Wrap(errs.CodeSecretsFailed, "failed to encrypt secret", err)
+	}
+	row := models.SecretVersion{
+		Scope:      scope,
+		Project:    project,
+		Name:       name,
+		Version:    next,
+		Ciphertext: ciphertext,
+		KeyID:      cryptox.KeyID(s.key),
+		UserID:     actor.UserID,
+		UserLogin:  actor.Login,
+	}
+	if err := s.repo.Create(ctx, &row); err != nil {
+		return SecretMetadata{}, errs.Wrap(errs.CodeSecretsFailed, "failed to store secret", err)
+	}
+	return secretMetadata(row), nil
+}
+
+// Restore makes an earlier version live again by storing its value as a new
+// version.
+func (s *SecretsService) Restore(ctx context.Context, scope, project, name string, version int, actor ProjectArchiveActor) (SecretMetadata, error) {
+	rows, err := s.versions(ctx, scope, project, name)
+	if err != nil {
+		return SecretMetadata{}, err
+	}
+	for _, row := range rows {
+		if row.Version != version {
+			continue
+		}
+		value, err := s.open(row)
+		if err != nil {
+			return SecretMetadata{}, err
+		}
+		return s.Set(ctx, scope, project, name, value, actor)
+	}
+	return SecretMetadata{}, errs.New(errs.CodeSecretsNotFound, fmt.Sprintf("secret %s has no version %d", name, version))
+}
+
+// Delete removes a secret and all of its versions.
+func (s *SecretsService) Delete(ctx context.Context, scope, project, name string) error {
+	if err := s.checkScope(scope, project); err != nil {
+		return err
+	}
+	if err := checkSecretName(name); err != nil {
+		return err
+	}
+	secretsWriteLock.Lock()
+	defer secretsWriteLock.Unlock()
+
+	removed, err := s.repo.DeleteName(ctx, scope, project, name)
+	if err != nil {
+		return errs.Wrap(errs.CodeSecretsFailed, "failed to delete secret", err)
+	}
+	if removed == 0 {
+		return errs.New(errs.CodeSecretsNotFound, fmt.Sprintf("secret %s not found", name))
+	}
+	return nil
+}
+
+// Values decrypts the live value of every secret in scope.
+func (s *SecretsService) Values(ctx context.Context, scope, project string) (map[string]string, error) {
+	latest, err := s.latest(ctx, scope, project)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(latest))
+	for name, row := range latest {
+		value, err := s.open(row)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// Rotate re-encrypts every version not sealed with the current key. Rows are
+// rewritten in one transaction, so a version the keyring cannot open aborts
+// the rotation without changing anything.
+func (s *SecretsService) Rotate(ctx context.Context) (SecretsRotation, error) {
+	if !s.Enabled() {
+		return SecretsRotation{}, errSecretsDisabled()
+	}
+	secretsWriteLock.Lock()
+	defer secretsWriteLock.Unlock()
+
+	keyID := cryptox.KeyID(s.key)
+	report := SecretsRotation{KeyID: keyID}
+	rows, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return SecretsRotation{}, errs.Wrap(errs.CodeSecretsFailed, "failed to list secrets", err)
+	}
+	stale := make([]models.SecretVersion, 0, len(rows))
+	for _, row := range rows {
+		if row.KeyID == keyID {
+			report.Current++
+			continue
+		}
+		value, err := s.open(row)
+		if err != nil {
+			return SecretsRotation{}, err
+		}
+		row.Ciphertext, err = cryptox.EncryptWithSecret(s.key, value)
+		if err != nil {
+			return SecretsRotation{}, errs.Wrap(errs.CodeSecretsFailed, "failed to encrypt secret", err)
+		}
+		row.KeyID = keyID
+		stale = append(stale, row)
+	}
+	if len(stale) == 0 {
+		return report, nil
+	}
+	if err := s.repo.UpdateCiphertexts(ctx, stale); err != nil {
+		return SecretsRotation{}, errs.Wrap(errs.CodeSecretsFailed, "failed to store re-encrypted secrets", err)
+	}
+	report.ReEncrypted = len(stale)
+	return report, nil
+}
+
+func (s *SecretsService) latest(ctx context.Context, scope, project string) (map[string]models.SecretVersion, error) {
+	if err := s.checkScope(scope, project); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.List(ctx, scope, project)
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeSecretsFailed, "failed to list secrets", err)
+	}
+	latest := make(map[string]models.SecretVersion, len(rows))
+	for _, row := range rows {
+		if current, ok := latest[row.Name]; !ok || row.Version > current.Version {
+			latest[row.Name] = row
+		}
+	}
+	return latest, nil
+}
+
+func (s *SecretsService) versions(ctx context.Context, scope, project, name string) ([]models.SecretVersion, error) {
+	if err := s.checkScope(scope, project); err != nil {
+		return nil, err
+	}
+	if err := checkSecretName(name); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListVersions(ctx, scope, project, name)
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeSecretsFailed, "failed to load secret versions", err)
+	}
+	if len(rows) == 0 {
+		return nil, errs.New(errs.CodeSecretsNotFound, fmt.Sprintf("secret %s not found", name))
+	}
+	return rows, nil
+}
+
+// open decrypts a version with whichever configured key sealed it.
+func (s *SecretsService) open(row models.SecretVersion) (string, error) {
+	key := ""
+	switch row.KeyID {
+	case cryptox.KeyID(s.key):
+		key = s.key
+	case cryptox.KeyID(s.previousKey):
+		key = s.previousKey
+	}
+	if key == "" {
+		return "", errs.WithDetails(
+			errs.New(errs.CodeSecretsUnknownKey, fmt.Sprintf("secret %s v%d was sealed with key %s, which is neither SECRETS_KEY nor SECRETS_KEY_PREVIOUS", row.Name, row.Version, row.KeyID)),
+			map[string]any{"name": row.Name, "version": row.Version, "keyId": row.KeyID},
+		)
+	}
+	value, err := cryptox.DecryptWithSecret(key, row.Ciphertext)
+	if err != nil {
+		return "", errs.Wrap(errs.CodeSecretsFailed, fmt.Sprintf("failed to decrypt secret %s v%d", row.Name, row.Version), err)
+	}
+	return value, nil
+}
+
+func (s *SecretsService) checkScope(scope, project string) error {
+	if !s.Enabled() {
+		return errSecretsDisabled()
+	}
+	switch scope {
+	case SecretScopePanel:
+		if project != "" {
+			return errs.New(errs.CodeSecretsInvalidBody, "panel secrets have no project")
+		}
+	case SecretScopeProject:
+		if err := validate.ProjectName(project); err != nil {
+			return errs.New(errs.CodeProjectInvalidName, "project name must be lowercase alphanumerics or dashes")
+		}
+	default:
+		return errs.New(errs.CodeSecretsInvalidBody, fmt.Sprintf("unknown secret scope %q", scope))
+	}
+	return nil
+}
+
+func checkSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return errs.New(errs.CodeSecretsInvalidName, "secret name must start with a letter or underscore and contain only letters, digits, or _ (max 128)")
+	}
+	return nil
+}
+
+func errSecretsDisabled() error {
+	return errs.New(errs.CodeSecretsDisabled, "secrets vault is disabled; set SECRETS_KEY to enable it")
+}
+
+func secretMetadata(row models.SecretVersion) SecretMetadata {
+	return SecretMetadata{
+		Name:      row.Name,
+		Version:   row.Version,
+		KeyID:     row.KeyID,
+		UpdatedAt: row.CreatedAt,
+		UpdatedBy: row.UserLogin,
+	}
+}
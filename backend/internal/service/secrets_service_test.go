@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+)
+
+type fakeSecretRepo struct {
+	rows   []models.SecretVersion
+	nextID uint
+}
+
+func (f *fakeSecretRepo) List(_ context.Context, scope, project string) ([]models.SecretVersion, error) {
+	out := []models.SecretVersion{}
+	for _, row := range f.rows {
+		if row.Scope == scope && row.Project == project {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSecretRepo) ListVersions(_ context.Context, scope, project, name string) ([]models.SecretVersion, error) {
+	out := []models.SecretVersion{}
+	for idx := len(f.rows) - 1; idx >= 0; idx-- {
+		row := f.rows[idx]
+		if row.Scope == scope && row.Project == project && row.Name == name {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSecretRepo) ListAll(context.Context) ([]models.SecretVersion, error) {
+	return append([]models.SecretVersion(nil), f.rows...), nil
+}
+
+func (f *fakeSecretRepo) Create(_ context.Context, version *models.SecretVersion) error {
+	f.nextID++
+	version.ID = f.nextID
+	version.CreatedAt = time.Now().UTC()
+	f.rows = append(f.rows, *version)
+	return nil
+}
+
+func (f *fakeSecretRepo) DeleteName(_ context.Context, scope, project, name string) (int64, error) {
+	kept := f.rows[:0]
+	removed := int64(0)
+	for _, row := range f.rows {
+		if row.Scope == scope && row.Project == project && row.Name == name {
+			removed++
+			continue
+		}
+		kept = append(kept, row)
+	}
+	f.rows = kept
+	return removed, nil
+}
+
+func (f *fakeSecretRepo) UpdateCiphertexts(_ context.Context, versions []models.SecretVersion) error {
+	for _, version := range versions {
+		for idx := range f.rows {
+			if f.rows[idx].ID == version.ID {
+				f.rows[idx].Ciphertext = version.Ciphertext
+				f.rows[idx].KeyID = version.KeyID
+			}
+		}
+	}
+	return nil
+}
+
+func TestSecretsServiceVersionsValuesAndRestore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := &fakeSecretRepo{}
+	svc := NewSecretsService(config.Config{SecretsKey: "kek-one"}, repo)
+	actor := ProjectArchiveActor{UserID: 3, Login: "ops"}
+
+	first, err := svc.Set(ctx, SecretScopeProject, "demo", "DB_PASSWORD", "hunter2", actor)
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Version)
+	require.NotContains(t, repo.rows[0].Ciphertext, "hunter2")
+
+	same, err := svc.Set(ctx, SecretScopeProject, "demo", "DB_PASSWORD", "hunter2", actor)
+	require.NoError(t, err)
+	require.Equal(t, 1, same.Version)
+	require.Len(t, repo.rows, 1)
+
+	second, err := svc.Set(ctx, SecretScopeProject, "demo", "DB_PASSWORD", "correct-horse", actor)
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Version)
+	require.Equal(t, "ops", second.UpdatedBy)
+
+	values, err := svc.Values(ctx, SecretScopeProject, "demo")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"DB_PASSWORD": "correct-horse"}, values)
+
+	restored, err := svc.Restore(ctx, SecretScopeProject, "demo", "DB_PASSWORD", 1, actor)
+	require.NoError(t, err)
+	require.Equal(t, 3, restored.Version)
+	values, err = svc.Values(ctx, SecretScopeProject, "demo")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", values["DB_PASSWORD"])
+
+	versions, err := svc.Versions(ctx, SecretScopeProject, "demo", "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	require.Equal(t, 3, versions[0].Version)
+
+	require.NoError(t, svc.Delete(ctx, SecretScopeProject, "demo", "DB_PASSWORD"))
+	require.Empty(t, repo.rows)
+
+	for name, code := range map[string]errs.Code{
+		"1BAD":   errs.CodeSecretsInvalidName,
+		"HAS-DA": errs.CodeSecretsInvalidName,
+	} {
+		_, err := svc.Set(ctx, SecretScopeProject, "demo", name, "x", actor)
+		typed, ok := errs.From(err)
+		require.True(t, ok, name)
+		require.Equal(t, code, typed.Code, name)
+	}
+
+	_, err = NewSecretsService(config.Config{}, repo).List(ctx, SecretScopePanel, "")
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeSecretsDisabled, typed.Code)
+}
+
+func TestSecretsServiceRotateUsesPreviousKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := &fakeSecretRepo{}
+	old := NewSecretsService(config.Config{SecretsKey: "kek-old"}, repo)
+	_, err := old.Set(ctx, SecretScopePanel, "", "CLOUDFLARE_API_TOKEN", "cf-token", ProjectArchiveActor{})
+	require.NoError(t, err)
+
+	withoutPrevious := NewSecretsService(config.Config{SecretsKey: "kek-new"}, repo)
+	_, err = withoutPrevious.Values(ctx, SecretScopePanel, "")
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeSecretsUnknownKey, typed.Code)
+	_, err = withoutPrevious.Rotate(ctx)
+	require.Error(t, err)
+
+	rotating := NewSecretsService(config.Config{SecretsKey: "kek-new", SecretsKeyPrevious: "kek-old"}, repo)
+	values, err := rotating.Values(ctx, SecretScopePanel, "")
+	require.NoError(t, err)
+	require.Equal(t, "cf-token", values["CLOUDFLARE_API_TOKEN"])
+
+	report, err := rotating.Rotate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.ReEncrypted)
+
+	values, err = withoutPrevious.Values(ctx, SecretScopePanel, "")
+	require.NoError(t, err)
+	require.Equal(t, "cf-token", values["CLOUDFLARE_API_TOKEN"])
+
+	report, err = rotating.Rotate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, report.ReEncrypted)
+	require.Equal(t, 1, report.Current)
+}
+
+func TestSecretsServiceSetOverwritesVersionSealedWithLostKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := &fakeSecretRepo{}
+	_, err := NewSecretsService(config.Config{SecretsKey: "kek-lost"}, repo).Set(ctx, SecretScopePanel, "", "CLOUDFLARE_API_TOKEN", "cf-token", ProjectArchiveActor{})
+	require.NoError(t, err)
+
+	svc := NewSecretsService(config.Config{SecretsKey: "kek-new"}, repo)
+	updated, err := svc.Set(ctx, SecretScopePanel, "", "CLOUDFLARE_API_TOKEN", "cf-token", ProjectArchiveActor{})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.Version)
+
+	values, err := svc.Values(ctx, SecretScopePanel, "")
+	require.NoError(t, err)
+	require.Equal(t, "cf-token", values["CLOUDFLARE_API_TOKEN"])
+}
+
+func TestSettingsServiceKeepsPanelCredentialsInVault(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := config.Config{SessionSecret: "session-one", SecretsKey: "kek"}
+	repo := &fakeSettingsRepo{settings: &models.Settings{CloudflareToken: "cf-plain", GitHubAppPrivateKey: "pem"}}
+
+	// A blob written before the vault existed is sealed with the session secret.
+	legacy := NewSettingsService(config.Config{SessionSecret: "session-one"}, repo)
+	apiToken := "nb-token"
+	_, err := legacy.UpsertNetBirdModeConfig(ctx, NetBirdModeConfigUpdate{APIToken: &apiToken})
+	require.NoError(t, err)
+
+	vault := NewSecretsService(cfg, &fakeSecretRepo{})
+	svc := NewSettingsService(cfg, repo)
+	svc.SetSecretsVault(vault)
+
+	moved, err := svc.MigratePanelSecrets(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, moved)
+	require.Empty(t, repo.settings.CloudflareToken)
+	require.Empty(t, repo.settings.GitHubAppPrivateKey)
+
+	resealed, err := svc.ReencryptSettingsPayload(ctx)
+	require.NoError(t, err)
+	require.True(t, resealed)
+
+	// Rotating SESSION_SECRET no longer loses the blob.
+	rotatedSession := NewSettingsService(config.Config{SessionSecret: "session-two", SecretsKey: "kek"}, repo)
+	rotatedSession.SetSecretsVault(vault)
+	stored, _, err := rotatedSession.loadNetBirdStoredConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "nb-token", stored.APIToken)
+
+	payload, err := rotatedSession.Get(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "cf-plain", payload.CloudflareToken)
+	require.Equal(t, "pem", payload.GitHubAppPrivateKey)
+	_, sources, err := rotatedSession.ResolveConfigWithSources(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "vault", sources.CloudflareToken)
+
+	payload.CloudflareToken = "cf-next"
+	payload.GitHubAppPrivateKey = ""
+	updated, err := rotatedSession.Update(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, "cf-next", updated.CloudflareToken)
+	require.Empty(t, repo.settings.CloudflareToken)
+
+	values, err := vault.Values(ctx, SecretScopePanel, "")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{panelSecretCloudflareToken: "cf-next"}, values)
+}
+
+func TestMergeProjectSecretsBlockReplacesBlockAndOverriddenKeys(t *testing.T) {
+	t.Parallel()
+
+	content := "A=1\nDB_PASSWORD=\"old\nvalue\"\nB=2\n"
+	merged := mergeProjectSecretsBlock(content, map[string]string{"DB_PASSWORD": "s3cr$t", "QUOTED": "it's"})
+	require.Equal(t, "A=1\nB=2\n\n"+projectSecretsBlockStart+"\nDB_PASSWORD='s3cr$t'\nQUOTED=\"it's\"\n"+projectSecretsBlockEnd+"\n", merged)
+
+	entries, issues := parseProjectEnv(merged)
+	require.Empty(t, issues)
+	require.Equal(t, map[string]string{"A": "1", "B": "2", "DB_PASSWORD": "s3cr$t", "QUOTED": "it's"}, projectEnvValues(entries))
+
+	require.Equal(t, merged, mergeProjectSecretsBlock(merged, map[string]string{"DB_PASSWORD": "s3cr$t", "QUOTED": "it's"}))
+	require.Equal(t, "A=1\nB=2\n", mergeProjectSecretsBlock(merged, nil))
+}
+
+func TestProjectSecretsInjectedBeforeComposeAndHiddenFromEnvEditor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	envPath := filepath.Join(projectDir, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("APP_ENV=prod\n"), 0o600))
+
+	vault := NewSecretsService(config.Config{SecretsKey: "kek"}, &fakeSecretRepo{})
+	_, err := vault.Set(ctx, SecretScopeProject, "demo", "API_KEY", "abc123", ProjectArchiveActor{})
+	require.NoError(t, err)
+
+	files := &stubProjectFileMutationClient{}
+	workflows := &ProjectWorkflows{fileClient: files}
+	workflows.SetSecretsVault(vault)
+	logger := &captureWorkflowLogger{}
+	require.NoError(t, workflows.injectProjectSecrets(ctx, logger, projectDir))
+	require.Len(t, files.writeCalls, 1)
+	require.Equal(t, uint32(0o600), files.writeCalls[0].Mode)
+	require.NotContains(t, strings.Join(logger.lines, "\n"), "abc123")
+
+	raw, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "API_KEY='abc123'")
+
+	// A second deploy with unchanged secrets leaves the file alone.
+	require.NoError(t, workflows.injectProjectSecrets(ctx, logger, projectDir))
+	require.Len(t, files.writeCalls, 1)
+
+	env := NewProjectEnvService(templatesDir, nil)
+	env.SetFileMutationClient(files)
+	read, err := env.Load(ctx, "demo")
+	require.NoError(t, err)
+	require.Equal(t, "APP_ENV=prod\n", read.Content)
+	require.Equal(t, []string{"API_KEY"}, read.ManagedKeys)
+
+	written, err := env.Save(ctx, "demo", "APP_ENV=staging\n", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"APP_ENV"}, written.Changes.Changed)
+	require.Empty(t, written.Changes.Removed)
+	raw, err = os.ReadFile(envPath)
+	require.NoError(t, err)
+	require.Equal(t, "APP_ENV=staging\n\n"+projectSecretsBlockStart+"\nAPI_KEY='abc123'\n"+projectSecretsBlockEnd+"\n", string(raw))
+}
@@ -58,7 +58,7 @@ func (s *SettingsService) UpsertNetBirdModeConfig(ctx context.Context, input Net
 		stored = &models.Settings{}
 	}
 
-	payload, err := loadSettingsEncryptedPayload(s.cfg.SessionSecret, stored.NetBirdConfigEncrypted)
+	payload, err := loadSettingsEncryptedPayload(SettingsPayloadKey(s.cfg), stored.NetBirdConfigEncrypted)
 	if err != nil {
 		return NetBirdModeConfig{}, fmt.Errorf("decode stored netbird config: %w", err)
 	}
@@ -85,7 +85,7 @@ func (s *SettingsService) UpsertNetBirdModeConfig(ctx context.Context, input Net
 	}
 
 	payload.NetBird = &next
-	encoded, err := encodeSettingsEncryptedPayload(s.cfg.SessionSecret, payload)
+	encoded, err := encodeSettingsEncryptedPayload(SettingsPayloadKey(s.cfg), payload)
 	if err != nil {
 		return NetBirdModeConfig{}, err
 	}
@@ -151,7 +151,7 @@ func (s *SettingsService) loadNetBirdStoredConfig(ctx context.Context) (netBirdS
 	if stored == nil {
 		return netBirdStoredConfig{}, false, nil
 	}
-	payload, err := loadSettingsEncryptedPayload(s.cfg.SessionSecret, stored.NetBirdConfigEncrypted)
+	payload, err := loadSettingsEncryptedPayload(SettingsPayloadKey(s.cfg), stored.NetBirdConfigEncrypted)
 	if err != nil {
 		return netBirdStoredConfig{}, false, fmt.Errorf("decode stored netbird config: %w", err)
 	}
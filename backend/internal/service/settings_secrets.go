@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-notes/internal/config"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+// Vault names of the panel credentials that used to live in settings columns.
+const (
+	panelSecretCloudflareToken       = "CLOUDFLARE_API_TOKEN"
+	panelSecretGitHubAppClientSecret = "GITHUB_APP_CLIENT_SECRET"
+	panelSecretGitHubAppPrivateKey   = "GITHUB_APP_PRIVATE_KEY"
+)
+
+// SettingsPayloadKey is the key that seals the encrypted settings blob:
+// SECRETS_KEY when the vault is configured, SESSION_SECRET otherwise.
+func SettingsPayloadKey(cfg config.Config) string {
+	if key := strings.TrimSpace(cfg.SecretsKey); key != "" {
+		return key
+	}
+	return cfg.SessionSecret
+}
+
+// settingsPayloadFallbackKeys are the keys an older blob may still be sealed
+// with, tried in order when SettingsPayloadKey cannot open it.
+func settingsPayloadFallbackKeys(cfg config.Config) []string {
+	current := SettingsPayloadKey(cfg)
+	keys := []string{}
+	for _, key := range []string{strings.TrimSpace(cfg.SecretsKeyPrevious), cfg.SessionSecret} {
+		if key != "" && key != current {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SetSecretsVault moves the panel credentials out of the settings row and
+// into the vault. Without it they stay in their plaintext columns.
+func (s *SettingsService) SetSecretsVault(secrets *SecretsService) {
+	s.secrets = secrets
+}
+
+func panelSecretFields(stored *models.Settings) map[string]*string {
+	return map[string]*string{
+		panelSecretCloudflareToken:       &stored.CloudflareToken,
+		panelSecretGitHubAppClientSecret: &stored.GitHubAppClientSecret,
+		panelSecretGitHubAppPrivateKey:   &stored.GitHubAppPrivateKey,
+	}
+}
+
+// hydratePanelSecrets fills the credential fields of stored from the vault and
+// returns the names it found there.
+func (s *SettingsService) hydratePanelSecrets(ctx context.Context, stored *models.Settings) (map[string]bool, error) {
+	found := map[string]bool{}
+	if stored == nil || !s.secrets.Enabled() {
+		return found, nil
+	}
+	values, err := s.secrets.Values(ctx, SecretScopePanel, "")
+	if err != nil {
+		return nil, err
+	}
+	for name, field := range panelSecretFields(stored) {
+		if value, ok := values[name]; ok {
+			*field = value
+			found[name] = true
+		}
+	}
+	return found, nil
+}
+
+// vaultPanelSecrets writes the credential fields of stored to the vault, drops
+// vault entries whose field was cleared, and blanks the fields so the row
+// that gets saved holds no credentials.
+func (s *SettingsService) vaultPanelSecrets(ctx context.Context, stored *models.Settings) (int, error) {
+	if !s.secrets.Enabled() {
+		return 0, nil
+	}
+	current, err := s.secrets.Values(ctx, SecretScopePanel, "")
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for name, field := range panelSecretFields(stored) {
+		value := strings.TrimSpace(*field)
+		*field = ""
+		previous, ok := current[name]
+		switch {
+		case value == "" && ok:
+			if err := s.secrets.Delete(ctx, SecretScopePanel, "", name); err != nil {
+				return changed, err
+			}
+			changed++
+		case value != "" && value != previous:
+			if _, err := s.secrets.Set(ctx, SecretScopePanel, "", name, value, ProjectArchiveActor{Login: "settings"}); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// MigratePanelSecrets moves credentials still held in plaintext settings
+// columns into the vault. Safe to run on every start.
+func (s *SettingsService) MigratePanelSecrets(ctx context.Context) (int, error) {
+	if !s.secrets.Enabled() {
+		return 0, nil
+	}
+	settingsWriteLock.Lock()
+	defer settingsWriteLock.Unlock()
+
+	stored, err := s.repo.Get(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	pending := 0
+	for _, field := range panelSecretFields(stored) {
+		if strings.TrimSpace(*field) != "" {
+			pending++
+		}
+	}
+	if pending == 0 {
+		return 0, nil
+	}
+	// Keep vaulted values the columns do not mention.
+	values, err := s.secrets.Values(ctx, SecretScopePanel, "")
+	if err != nil {
+		return 0, err
+	}
+	for name, field := range panelSecretFields(stored) {
+		if strings.TrimSpace(*field) == "" {
+			*field = values[name]
+		}
+	}
+	if _, err := s.vaultPanelSecrets(ctx, stored); err != nil {
+		return 0, err
+	}
+	if err := s.repo.Save(ctx, stored); err != nil {
+		return 0, err
+	}
+	return pending, nil
+}
+
+// ReencryptSettingsPayload reseals the encrypted settings blob with
+// SettingsPayloadKey when it is still sealed with SECRETS_KEY_PREVIOUS or
+// SESSION_SECRET. It reports whether the blob was rewritten.
+func (s *SettingsService) ReencryptSettingsPayload(ctx context.Context) (bool, error) {
+	settingsWriteLock.Lock()
+	defer settingsWriteLock.Unlock()
+
+	stored, err := s.repo.Get(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if stored == nil || strings.TrimSpace(stored.NetBirdConfigEncrypted) == "" {
+		return false, nil
+	}
+	key := SettingsPayloadKey(s.cfg)
+	_, currentErr := loadSettingsEncryptedPayload(key, stored.NetBirdConfigEncrypted)
+	if currentErr == nil {
+		return false, nil
+	}
+	for _, fallback := range settingsPayloadFallbackKeys(s.cfg) {
+		payload, err := loadSettingsEncryptedPayload(fallback, stored.NetBirdConfigEncrypted)
+		if err != nil {
+			continue
+		}
+		encoded, err := encodeSettingsEncryptedPayload(key, payload)
+		if err != nil {
+			return false, err
+		}
+		stored.NetBirdConfigEncrypted = encoded
+		if err := s.repo.Save(ctx, stored); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("settings payload is not sealed with any configured key: %w", currentErr)
+}
@@ -73,8 +73,9 @@ type GitHubAppSettings struct {
 }
 
 type SettingsService struct {
-	cfg  config.Config
-	repo repository.SettingsRepository
+	cfg     config.Config
+	repo    repository.SettingsRepository
+	secrets *SecretsService
 }
 
 type DomainSelection struct {
@@ -91,6 +92,9 @@ func (s *SettingsService) Get(ctx context.Context) (SettingsPayload, error) {
 	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		return SettingsPayload{}, err
 	}
+	if _, err := s.hydratePanelSecrets(ctx, stored); err != nil {
+		return SettingsPayload{}, err
+	}
 	return s.resolve(stored), nil
 }
 
@@ -167,7 +171,7 @@ func (s *SettingsService) Update(ctx context.Context, input SettingsPayload) (Se
 	stored.CloudflaredTunnel = strings.TrimSpace(input.CloudflaredTunnel)
 	stored.CloudflaredConfigPath = strings.TrimSpace(input.CloudflaredConfigPath)
 
-	if err := s.repo.Save(ctx, stored); err != nil {
+	if err := s.save(ctx, stored); err != nil {
 		return SettingsPayload{}, err
 	}
 	return s.resolve(stored), nil
@@ -186,18 +190,36 @@ func (s *SettingsService) SyncCloudflareFromEnv(ctx context.Context) (SettingsPa
 		}
 	}
 
+	if _, err := s.hydratePanelSecrets(ctx, stored); err != nil {
+		return SettingsPayload{}, err
+	}
+
 	stored.CloudflareToken = strings.TrimSpace(s.cfg.CloudflareAPIToken)
 	stored.CloudflareAccountID = strings.TrimSpace(s.cfg.CloudflareAccountID)
 	stored.CloudflareZoneID = strings.TrimSpace(s.cfg.CloudflareZoneID)
 	stored.CloudflaredTunnel = strings.TrimSpace(s.cfg.CloudflaredTunnel)
 	stored.CloudflaredConfigPath = strings.TrimSpace(s.cfg.CloudflaredConfig)
 
-	if err := s.repo.Save(ctx, stored); err != nil {
+	if err := s.save(ctx, stored); err != nil {
 		return SettingsPayload{}, err
 	}
 	return s.resolve(stored), nil
 }
 
+// save stores the settings row with its credentials moved into the vault;
+// stored itself keeps them so callers can resolve the saved payload.
+func (s *SettingsService) save(ctx context.Context, stored *models.Settings) error {
+	persisted := *stored
+	if _, err := s.vaultPanelSecrets(ctx, &persisted); err != nil {
+		return err
+	}
+	if err := s.repo.Save(ctx, &persisted); err != nil {
+		return err
+	}
+	stored.Model = persisted.Model
+	return nil
+}
+
 func (s *SettingsService) validateAdditionalDomains(ctx context.Context, input SettingsPayload, domains []string) error {
 	if len(domains) == 0 {
 		return nil
@@ -312,6 +334,10 @@ func (s *SettingsService) ResolveConfigWithSources(ctx context.Context) (config.
 	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		return config.Config{}, SettingsSources{}, err
 	}
+	vaulted, err := s.hydratePanelSecrets(ctx, stored)
+	if err != nil {
+		return config.Config{}, SettingsSources{}, err
+	}
 
 	cfg := s.cfg
 	sources := SettingsSources{
@@ -392,6 +418,15 @@ func (s *SettingsService) ResolveConfigWithSources(ctx context.Context) (config.
 			sources.CloudflaredConfigPath = "unset"
 		}
 	}
+	if vaulted[panelSecretCloudflareToken] {
+		sources.CloudflareToken = "vault"
+	}
+	if vaulted[panelSecretGitHubAppClientSecret] {
+		sources.GitHubAppClientSecret = "vault"
+	}
+	if vaulted[panelSecretGitHubAppPrivateKey] {
+		sources.GitHubAppPrivateKey = "vault"
+	}
 	if strings.TrimSpace(cfg.TemplatesDir) == "" {
 		if sources.TemplatesDir == "" {
 			sources.TemplatesDir = "unset"
@@ -0,0 +1,614 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/integrations/cloudflare"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+	"go-notes/internal/utils/cryptox"
+	"go-notes/internal/validate"
+)
+
+// DefaultTunnelName refers to the tunnel configured in settings. Projects
+// without an assignment use it, and no stored tunnel can take the name.
+const DefaultTunnelName = "default"
+
+var tunnelNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
+// TunnelInput creates or replaces a stored tunnel. On update an empty Token
+// keeps the stored one and ClearToken drops it so the settings token is used.
+type TunnelInput struct {
+	Name           string `json:"name"`
+	AccountID      string `json:"accountId"`
+	ZoneID         string `json:"zoneId"`
+	Tunnel         string `json:"tunnel"`
+	ConfigPath     string `json:"configPath"`
+	MetricsAddress string `json:"metricsAddress"`
+	Token          string `json:"token,omitempty"`
+	ClearToken     bool   `json:"clearToken,omitempty"`
+}
+
+// TunnelSummary describes a tunnel without its token. Projects lists the
+// projects whose hostnames the tunnel serves.
+type TunnelSummary struct {
+	Name           string   `json:"name"`
+	Default        bool     `json:"default"`
+	AccountID      string   `json:"accountId,omitempty"`
+	ZoneID         string   `json:"zoneId,omitempty"`
+	Tunnel         string   `json:"tunnel,omitempty"`
+	ConfigPath     string   `json:"configPath,omitempty"`
+	MetricsAddress string   `json:"metricsAddress"`
+	TokenSet       bool     `json:"tokenSet"`
+	Projects       []string `json:"projects"`
+}
+
+// TunnelService keeps the cloudflared tunnels a host runs besides the one in
+// settings and resolves the Cloudflare config a project's tunnel work uses.
+type TunnelService struct {
+	repo     repository.TunnelRepository
+	projects repository.ProjectRepository
+	settings *SettingsService
+	secrets  *SecretsService
+}
+
+func NewTunnelService(repo repository.TunnelRepository, projects repository.ProjectRepository, settings *SettingsService) *TunnelService {
+	return &TunnelService{repo: repo, projects: projects, settings: settings}
+}
+
+// SetSecretsVault keeps tunnel tokens in the vault instead of the tunnel row.
+func (s *TunnelService) SetSecretsVault(secrets *SecretsService) {
+	s.secrets = secrets
+}
+
+// List returns the settings tunnel followed by the stored tunnels.
+func (s *TunnelService) List(ctx context.Context) ([]TunnelSummary, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+	base, err := s.settings.ResolveConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := s.assignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]TunnelSummary, 0, len(stored)+1)
+	summaries = append(summaries, TunnelSummary{
+		Name:           DefaultTunnelName,
+		Default:        true,
+		AccountID:      strings.TrimSpace(base.CloudflareAccountID),
+		ZoneID:         strings.TrimSpace(base.CloudflareZoneID),
+		Tunnel:         strings.TrimSpace(base.CloudflaredTunnel),
+		ConfigPath:     expandUserPath(base.CloudflaredConfig),
+		MetricsAddress: tunnelMetricsAddress(base.CloudflaredMetrics),
+		TokenSet:       strings.TrimSpace(base.CloudflareAPIToken) != "",
+		Projects:       assignedProjects(assigned, ""),
+	})
+	for _, tunnel := range stored {
+		token, err := s.token(ctx, tunnel)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, tunnelSummary(tunnel, token != "", assignedProjects(assigned, tunnel.Name)))
+	}
+	return summaries, nil
+}
+
+// Create stores a new tunnel.
+func (s *TunnelService) Create(ctx context.Context, input TunnelInput) (TunnelSummary, error) {
+	if err := s.ready(); err != nil {
+		return TunnelSummary{}, err
+	}
+	tunnel, err := normalizeTunnelInput(input)
+	if err != nil {
+		return TunnelSummary{}, err
+	}
+	if _, err := s.repo.GetByName(ctx, tunnel.Name); err == nil {
+		return TunnelSummary{}, errs.New(errs.CodeTunnelConflict, fmt.Sprintf("tunnel %s already exists", tunnel.Name))
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return TunnelSummary{}, err
+	}
+	if err := s.checkHostConflicts(ctx, tunnel); err != nil {
+		return TunnelSummary{}, err
+	}
+	token := strings.TrimSpace(input.Token)
+	if err := s.storeToken(ctx, &tunnel, token); err != nil {
+		return TunnelSummary{}, err
+	}
+	if err := s.repo.Create(ctx, &tunnel); err != nil {
+		return TunnelSummary{}, err
+	}
+	return tunnelSummary(tunnel, token != "", []string{}), nil
+}
+
+// Update replaces the settings of a stored tunnel. The name cannot change.
+func (s *TunnelService) Update(ctx context.Context, name string, input TunnelInput) (TunnelSummary, error) {
+	if err := s.ready(); err != nil {
+		return TunnelSummary{}, err
+	}
+	existing, err := s.get(ctx, name)
+	if err != nil {
+		return TunnelSummary{}, err
+	}
+	input.Name = existing.Name
+	tunnel, err := normalizeTunnelInput(input)
+	if err != nil {
+		return TunnelSummary{}, err
+	}
+	tunnel.Model = existing.Model
+	if err := s.checkHostConflicts(ctx, tunnel); err != nil {
+		return TunnelSummary{}, err
+	}
+
+	token := strings.TrimSpace(input.Token)
+	if token == "" && !input.ClearToken {
+		if token, err = s.token(ctx, *existing); err != nil {
+			return TunnelSummary{}, err
+		}
+	}
+	if err := s.storeToken(ctx, &tunnel, token); err != nil {
+		return TunnelSummary{}, err
+	}
+	if err := s.repo.Update(ctx, &tunnel); err != nil {
+		return TunnelSummary{}, err
+	}
+	assigned, err := s.assignments(ctx)
+	if err != nil {
+		return TunnelSummary{}, err
+	}
+	return tunnelSummary(tunnel, token != "", assignedProjects(assigned, tunnel.Name)), nil
+}
+
+// Delete removes a stored tunnel that no project is assigned to. The tunnel
+// itself is left running; only gungnr forgets it.
+func (s *TunnelService) Delete(ctx context.Context, name string) (TunnelSummary, error) {
+	if err := s.ready(); err != nil {
+		return TunnelSummary{}, err
+	}
+	existing, err := s.get(ctx, name)
+	if err != nil {
+		return TunnelSummary{}, err
+	}
+	assigned, err := s.assignments(ctx)
+	if err != nil {
+		return TunnelSummary{}, err
+	}
+	if projects := assignedProjects(assigned, existing.Name); len(projects) > 0 {
+		return TunnelSummary{}, errs.New(errs.CodeTunnelInUse, fmt.Sprintf("tunnel %s still serves %s; move those projects first", existing.Name, strings.Join(projects, ", ")))
+	}
+	if err := s.storeToken(ctx, existing, ""); err != nil {
+		return TunnelSummary{}, err
+	}
+	if err := s.repo.Delete(ctx, existing.ID); err != nil {
+		return TunnelSummary{}, err
+	}
+	return tunnelSummary(*existing, false, []string{}), nil
+}
+
+// Names returns the names of the stored tunnels.
+func (s *TunnelService) Names(ctx context.Context) ([]string, error) {
+	if s == nil || s.repo == nil {
+		return nil, nil
+	}
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(stored))
+	for _, tunnel := range stored {
+		names = append(names, tunnel.Name)
+	}
+	return names, nil
+}
+
+// Exists reports whether name is the settings tunnel or a stored tunnel.
+func (s *TunnelService) Exists(ctx context.Context, name string) error {
+	name = normalizeTunnelName(name)
+	if name == "" {
+		return nil
+	}
+	if s == nil || s.repo == nil {
+		return errs.New(errs.CodeTunnelNotFound, fmt.Sprintf("tunnel %s not found", name))
+	}
+	_, err := s.get(ctx, name)
+	return err
+}
+
+// ProjectTunnel returns the stored tunnel project is assigned to, or "" for
+// the settings tunnel.
+func (s *TunnelService) ProjectTunnel(ctx context.Context, project string) (string, error) {
+	if s == nil || s.projects == nil {
+		return "", nil
+	}
+	record, err := s.projects.GetByName(ctx, strings.ToLower(strings.TrimSpace(project)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return normalizeTunnelName(record.Tunnel), nil
+}
+
+// Config returns base with the account, zone, token, tunnel, config file, and
+// metrics address of the named tunnel. An empty name or DefaultTunnelName
+// returns base unchanged.
+func (s *TunnelService) Config(ctx context.Context, base config.Config, name string) (config.Config, error) {
+	name = normalizeTunnelName(name)
+	if name == "" {
+		return base, nil
+	}
+	if s == nil || s.repo == nil {
+		return config.Config{}, errs.New(errs.CodeTunnelNotFound, fmt.Sprintf("tunnel %s not found", name))
+	}
+	tunnel, err := s.get(ctx, name)
+	if err != nil {
+		return config.Config{}, err
+	}
+	token, err := s.token(ctx, *tunnel)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	cfg := base
+	if tunnel.AccountID != "" {
+		cfg.CloudflareAccountID = tunnel.AccountID
+	}
+	if tunnel.ZoneID != "" {
+		cfg.CloudflareZoneID = tunnel.ZoneID
+	}
+	if token != "" {
+		cfg.CloudflareAPIToken = token
+	}
+	cfg.CloudflaredTunnel = tunnel.Tunnel
+	cfg.CloudflareTunnelID = ""
+	cfg.CloudflaredConfig = expandUserPath(tunnel.ConfigPath)
+	cfg.CloudflaredMetrics = tunnel.MetricsAddress
+	return cfg, nil
+}
+
+// ProjectConfig is Config for the tunnel project is assigned to.
+func (s *TunnelService) ProjectConfig(ctx context.Context, base config.Config, project string) (config.Config, error) {
+	name, err := s.ProjectTunnel(ctx, project)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return s.Config(ctx, base, name)
+}
+
+// Assign records name as the tunnel serving project. It does not touch the
+// project's ingress rules or DNS records.
+func (s *TunnelService) Assign(ctx context.Context, project, name string) error {
+	if s == nil || s.projects == nil {
+		return errs.New(errs.CodeTunnelUnavailable, "tunnel service unavailable")
+	}
+	name = normalizeTunnelName(name)
+	if err := s.Exists(ctx, name); err != nil {
+		return err
+	}
+	record, err := s.projects.GetByName(ctx, strings.ToLower(strings.TrimSpace(project)))
+	if err != nil {
+		return err
+	}
+	record.Tunnel = name
+	return s.projects.Update(ctx, record)
+}
+
+// selectTunnelDomain resolves requested for a hostname served by the tunnel
+// cfg describes. Tunnels on the settings account use the managed domains;
+// a tunnel in another account must hold the zone itself.
+func selectTunnelDomain(ctx context.Context, settings *SettingsService, base, cfg config.Config, requested string) (DomainSelection, error) {
+	if cfg.CloudflareAccountID == base.CloudflareAccountID && cfg.CloudflareAPIToken == base.CloudflareAPIToken {
+		return selectProjectDomain(ctx, settings, base, requested)
+	}
+	domain := normalizeDomain(requested)
+	if domain == "" {
+		domain = normalizeDomain(base.Domain)
+	}
+	if domain == "" {
+		return DomainSelection{}, errBaseDomainUnset()
+	}
+	if err := validate.Domain(domain); err != nil {
+		return DomainSelection{}, err
+	}
+	zones, err := cloudflare.NewClient(cfg).ListZones(ctx)
+	if err != nil {
+		return DomainSelection{}, errs.Wrap(errs.CodeCloudflareZones, "list cloudflare zones failed", err)
+	}
+	for _, zone := range zones {
+		if normalizeDomain(zone.Name) == domain {
+			return DomainSelection{Domain: domain, ZoneID: zone.ID}, nil
+		}
+	}
+	return DomainSelection{}, errDomainNotConfigured(domain)
+}
+
+func (s *TunnelService) ready() error {
+	if s == nil || s.repo == nil || s.settings == nil {
+		return errs.New(errs.CodeTunnelUnavailable, "tunnel service unavailable")
+	}
+	return nil
+}
+
+func (s *TunnelService) get(ctx context.Context, name string) (*models.CloudflareTunnel, error) {
+	name = normalizeTunnelName(name)
+	tunnel, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeTunnelNotFound, fmt.Sprintf("tunnel %s not found", name))
+		}
+		return nil, err
+	}
+	return tunnel, nil
+}
+
+// assignments maps project names to the stored tunnel serving them; projects
+// on the settings tunnel map to "".
+func (s *TunnelService) assignments(ctx context.Context) (map[string]string, error) {
+	assigned := map[string]string{}
+	if s.projects == nil {
+		return assigned, nil
+	}
+	projects, err := s.projects.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		assigned[project.Name] = normalizeTunnelName(project.Tunnel)
+	}
+	return assigned, nil
+}
+
+// checkHostConflicts refuses a tunnel whose config file or metrics address is
+// already used by another tunnel on the host; two cloudflared processes
+// cannot share either.
+func (s *TunnelService) checkHostConflicts(ctx context.Context, tunnel models.CloudflareTunnel) error {
+	base, err := s.settings.ResolveConfig(ctx)
+	if err != nil {
+		return err
+	}
+	type hostTunnel struct {
+		name           string
+		configPath     string
+		metricsAddress string
+	}
+	others := []hostTunnel{{
+		name:           DefaultTunnelName,
+		configPath:     expandUserPath(base.CloudflaredConfig),
+		metricsAddress: tunnelMetricsAddress(base.CloudflaredMetrics),
+	}}
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range stored {
+		if other.Name == tunnel.Name {
+			continue
+		}
+		others = append(others, hostTunnel{name: other.Name, configPath: expandUserPath(other.ConfigPath), metricsAddress: other.MetricsAddress})
+	}
+	configPath := expandUserPath(tunnel.ConfigPath)
+	for _, other := range others {
+		if other.configPath != "" && other.configPath == configPath {
+			return errs.New(errs.CodeTunnelConflict, fmt.Sprintf("tunnel %s already uses %s", other.name, tunnel.ConfigPath))
+		}
+		if other.metricsAddress == tunnel.MetricsAddress {
+			return errs.New(errs.CodeTunnelConflict, fmt.Sprintf("tunnel %s already serves metrics on %s", other.name, tunnel.MetricsAddress))
+		}
+	}
+	return nil
+}
+
+func tunnelTokenSecretName(name string) string {
+	return "CLOUDFLARE_TUNNEL_TOKEN_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// token returns the API token stored for tunnel, or "" when it uses the
+// settings token.
+func (s *TunnelService) token(ctx context.Context, tunnel models.CloudflareTunnel) (string, error) {
+	if !s.secrets.Enabled() {
+		return s.openRowToken(tunnel)
+	}
+	values, err := s.secrets.Values(ctx, SecretScopePanel, "")
+	if err != nil {
+		return "", err
+	}
+	if value, ok := values[tunnelTokenSecretName(tunnel.Name)]; ok {
+		return value, nil
+	}
+	return s.openRowToken(tunnel)
+}
+
+// openRowToken decrypts the token column with the settings payload key, or a
+// key it was sealed with before a rotation.
+func (s *TunnelService) openRowToken(tunnel models.CloudflareTunnel) (string, error) {
+	sealed := strings.TrimSpace(tunnel.Token)
+	if sealed == "" {
+		return "", nil
+	}
+	keys := append([]string{SettingsPayloadKey(s.settings.cfg)}, settingsPayloadFallbackKeys(s.settings.cfg)...)
+	var lastErr error
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			continue
+		}
+		value, err := cryptox.DecryptWithSecret(key, sealed)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no settings payload key is configured")
+	}
+	return "", errs.Wrap(errs.CodeTunnelFailed, fmt.Sprintf("open token of tunnel %s: %v", tunnel.Name, lastErr), lastErr)
+}
+
+// SealStoredTokens moves tunnel tokens still held in plaintext columns into
+// the vault, or seals them with the settings payload key when the vault is
+// not configured. Safe to run on every start.
+func (s *TunnelService) SealStoredTokens(ctx context.Context) (int, error) {
+	if err := s.ready(); err != nil {
+		return 0, err
+	}
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sealed := 0
+	for _, tunnel := range stored {
+		column := strings.TrimSpace(tunnel.Token)
+		if column == "" {
+			continue
+		}
+		if !s.secrets.Enabled() {
+			opened, err := s.openRowToken(tunnel)
+			if err != nil {
+				return sealed, err
+			}
+			// A plaintext column opens to itself; anything else is sealed.
+			if opened != column {
+				continue
+			}
+		}
+		// With the vault configured a vaulted token wins over the column.
+		value, err := s.token(ctx, tunnel)
+		if err != nil {
+			return sealed, err
+		}
+		if err := s.storeToken(ctx, &tunnel, value); err != nil {
+			return sealed, err
+		}
+		if err := s.repo.Update(ctx, &tunnel); err != nil {
+			return sealed, err
+		}
+		sealed++
+	}
+	return sealed, nil
+}
+
+// storeToken writes token to the vault when it is configured, leaving the
+// row's column empty, or seals it into the column with the settings payload
+// key otherwise. An empty token removes it.
+func (s *TunnelService) storeToken(ctx context.Context, tunnel *models.CloudflareTunnel, token string) error {
+	if !s.secrets.Enabled() {
+		if token == "" {
+			tunnel.Token = ""
+			return nil
+		}
+		key := SettingsPayloadKey(s.settings.cfg)
+		if strings.TrimSpace(key) == "" {
+			return errs.New(errs.CodeTunnelInvalid, "a tunnel token needs SECRETS_KEY or SESSION_SECRET to be stored encrypted")
+		}
+		sealed, err := cryptox.EncryptWithSecret(key, token)
+		if err != nil {
+			return errs.Wrap(errs.CodeTunnelFailed, fmt.Sprintf("encrypt token of tunnel %s: %v", tunnel.Name, err), err)
+		}
+		tunnel.Token = sealed
+		return nil
+	}
+	tunnel.Token = ""
+	current, err := s.secrets.Values(ctx, SecretScopePanel, "")
+	if err != nil {
+		return err
+	}
+	name := tunnelTokenSecretName(tunnel.Name)
+	previous, ok := current[name]
+	switch {
+	case token == "" && ok:
+		return s.secrets.Delete(ctx, SecretScopePanel, "", name)
+	case token != "" && token != previous:
+		_, err := s.secrets.Set(ctx, SecretScopePanel, "", name, token, ProjectArchiveActor{Login: "tunnels"})
+		return err
+	}
+	return nil
+}
+
+func normalizeTunnelName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == DefaultTunnelName {
+		return ""
+	}
+	return name
+}
+
+func normalizeTunnelInput(input TunnelInput) (models.CloudflareTunnel, error) {
+	invalid := func(format string, args ...any) (models.CloudflareTunnel, error) {
+		return models.CloudflareTunnel{}, errs.New(errs.CodeTunnelInvalid, fmt.Sprintf(format, args...))
+	}
+	tunnel := models.CloudflareTunnel{
+		Name:           strings.ToLower(strings.TrimSpace(input.Name)),
+		AccountID:      strings.TrimSpace(input.AccountID),
+		ZoneID:         strings.TrimSpace(input.ZoneID),
+		Tunnel:         strings.TrimSpace(input.Tunnel),
+		ConfigPath:     strings.TrimSpace(input.ConfigPath),
+		MetricsAddress: strings.TrimSpace(input.MetricsAddress),
+	}
+	switch {
+	case tunnel.Name == DefaultTunnelName:
+		return invalid("%s is reserved for the tunnel in settings", DefaultTunnelName)
+	case !tunnelNamePattern.MatchString(tunnel.Name):
+		return invalid("tunnel name must be 1-32 lowercase alphanumerics or dashes")
+	case tunnel.Tunnel == "":
+		return invalid("tunnel name or ID is required")
+	case tunnel.ConfigPath == "":
+		return invalid("cloudflared config path is required")
+	case strings.TrimSpace(input.Token) != "" && tunnel.AccountID == "":
+		return invalid("a tunnel with its own token needs its account ID")
+	}
+	host, port, err := net.SplitHostPort(tunnel.MetricsAddress)
+	if err != nil || port == "" {
+		return invalid("metrics address must be host:port, for example 127.0.0.1:20242")
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return invalid("metrics address must be on a loopback address")
+	}
+	return tunnel, nil
+}
+
+func tunnelMetricsAddress(address string) string {
+	if address = strings.TrimSpace(address); address != "" {
+		return address
+	}
+	return contract.DefaultTunnelMetricsAddress
+}
+
+func tunnelSummary(tunnel models.CloudflareTunnel, tokenSet bool, projects []string) TunnelSummary {
+	return TunnelSummary{
+		Name:           tunnel.Name,
+		AccountID:      tunnel.AccountID,
+		ZoneID:         tunnel.ZoneID,
+		Tunnel:         tunnel.Tunnel,
+		ConfigPath:     expandUserPath(tunnel.ConfigPath),
+		MetricsAddress: tunnel.MetricsAddress,
+		TokenSet:       tokenSet,
+		Projects:       projects,
+	}
+}
+
+func assignedProjects(assigned map[string]string, tunnel string) []string {
+	projects := []string{}
+	for project, name := range assigned {
+		if name == tunnel {
+			projects = append(projects, project)
+		}
+	}
+	sort.Strings(projects)
+	return projects
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+type fakeTunnelRepo struct {
+	tunnels []models.CloudflareTunnel
+	nextID  uint
+}
+
+func (f *fakeTunnelRepo) List(context.Context) ([]models.CloudflareTunnel, error) {
+	items := make([]models.CloudflareTunnel, len(f.tunnels))
+	copy(items, f.tunnels)
+	return items, nil
+}
+
+func (f *fakeTunnelRepo) GetByName(_ context.Context, name string) (*models.CloudflareTunnel, error) {
+	for _, tunnel := range f.tunnels {
+		if tunnel.Name == name {
+			item := tunnel
+			return &item, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeTunnelRepo) Create(_ context.Context, tunnel *models.CloudflareTunnel) error {
+	f.nextID++
+	tunnel.ID = f.nextID
+	f.tunnels = append(f.tunnels, *tunnel)
+	return nil
+}
+
+func (f *fakeTunnelRepo) Update(_ context.Context, tunnel *models.CloudflareTunnel) error {
+	for index, existing := range f.tunnels {
+		if existing.ID == tunnel.ID {
+			f.tunnels[index] = *tunnel
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (f *fakeTunnelRepo) Delete(_ context.Context, id uint) error {
+	for index, existing := range f.tunnels {
+		if existing.ID == id {
+			f.tunnels = append(f.tunnels[:index], f.tunnels[index+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func tunnelErrorCode(t *testing.T, err error) errs.Code {
+	t.Helper()
+	appErr, ok := errs.From(err)
+	require.True(t, ok, "expected app error, got %v", err)
+	return appErr.Code
+}
+
+func newTestTunnelService(cfg config.Config, projects ...models.Project) (*TunnelService, *fakeTunnelRepo) {
+	repo := &fakeTunnelRepo{}
+	settings := NewSettingsService(cfg, &fakeSettingsRepo{})
+	return NewTunnelService(repo, &stubProjectRepository{projects: projects}, settings), repo
+}
+
+func TestTunnelServiceCreateValidatesAndRejectsHostConflicts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	svc, _ := newTestTunnelService(config.Config{
+		SessionSecret:     "test-session-secret",
+		CloudflaredTunnel: "main",
+		CloudflaredConfig: "/etc/cloudflared/config.yml",
+	})
+
+	for _, input := range []TunnelInput{
+		{Name: "default", Tunnel: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "127.0.0.1:20242"},
+		{Name: "Edge_1", Tunnel: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "127.0.0.1:20242"},
+		{Name: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "127.0.0.1:20242"},
+		{Name: "edge", Tunnel: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "0.0.0.0:20242"},
+		{Name: "edge", Tunnel: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "127.0.0.1:20242", Token: "token"},
+	} {
+		_, err := svc.Create(ctx, input)
+		require.Error(t, err, "input %+v", input)
+		require.Equal(t, errs.CodeTunnelInvalid, tunnelErrorCode(t, err), "input %+v", input)
+	}
+
+	_, err := svc.Create(ctx, TunnelInput{Name: "edge", Tunnel: "edge", ConfigPath: "/etc/cloudflared/config.yml", MetricsAddress: "127.0.0.1:20242"})
+	require.Equal(t, errs.CodeTunnelConflict, tunnelErrorCode(t, err))
+
+	_, err = svc.Create(ctx, TunnelInput{Name: "edge", Tunnel: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "127.0.0.1:20241"})
+	require.Equal(t, errs.CodeTunnelConflict, tunnelErrorCode(t, err))
+
+	created, err := svc.Create(ctx, TunnelInput{Name: "edge", Tunnel: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "127.0.0.1:20242"})
+	require.NoError(t, err)
+	require.Equal(t, "edge", created.Name)
+	require.False(t, created.TokenSet)
+
+	_, err = svc.Create(ctx, TunnelInput{Name: "edge", Tunnel: "other", ConfigPath: "/etc/cloudflared/other.yml", MetricsAddress: "127.0.0.1:20243"})
+	require.Equal(t, errs.CodeTunnelConflict, tunnelErrorCode(t, err))
+}
+
+func TestTunnelServiceConfigOverlaysStoredTunnel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	base := config.Config{
+		SessionSecret:       "test-session-secret",
+		CloudflareAccountID: "acct-main",
+		CloudflareZoneID:    "zone-main",
+		CloudflareAPIToken:  "token-main",
+		CloudflareTunnelID:  "tunnel-id-main",
+		CloudflaredTunnel:   "main",
+		CloudflaredConfig:   "/etc/cloudflared/config.yml",
+	}
+	svc, _ := newTestTunnelService(base, models.Project{Name: "demo", Tunnel: "edge"}, models.Project{Name: "docs"})
+
+	_, err := svc.Create(ctx, TunnelInput{
+		Name:           "edge",
+		AccountID:      "acct-edge",
+		Tunnel:         "edge",
+		ConfigPath:     "/etc/cloudflared/edge.yml",
+		MetricsAddress: "127.0.0.1:20242",
+		Token:          "token-edge",
+	})
+	require.NoError(t, err)
+
+	cfg, err := svc.ProjectConfig(ctx, base, "demo")
+	require.NoError(t, err)
+	require.Equal(t, "acct-edge", cfg.CloudflareAccountID)
+	require.Equal(t, "zone-main", cfg.CloudflareZoneID)
+	require.Equal(t, "token-edge", cfg.CloudflareAPIToken)
+	require.Equal(t, "edge", cfg.CloudflaredTunnel)
+	require.Empty(t, cfg.CloudflareTunnelID)
+	require.Equal(t, "/etc/cloudflared/edge.yml", cfg.CloudflaredConfig)
+	require.Equal(t, "127.0.0.1:20242", cfg.CloudflaredMetrics)
+
+	cfg, err = svc.ProjectConfig(ctx, base, "docs")
+	require.NoError(t, err)
+	require.Equal(t, base, cfg)
+
+	_, err = svc.Config(ctx, base, "missing")
+	require.Equal(t, errs.CodeTunnelNotFound, tunnelErrorCode(t, err))
+
+	tunnels, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, tunnels, 2)
+	require.True(t, tunnels[0].Default)
+	require.Equal(t, []string{"docs"}, tunnels[0].Projects)
+	require.Equal(t, "edge", tunnels[1].Name)
+	require.True(t, tunnels[1].TokenSet)
+	require.Equal(t, []string{"demo"}, tunnels[1].Projects)
+}
+
+func TestTunnelServiceKeepsTokenInVault(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	svc, repo := newTestTunnelService(config.Config{SessionSecret: "test-session-secret", CloudflareAccountID: "acct-main"})
+	svc.SetSecretsVault(NewSecretsService(config.Config{SecretsKey: "kek"}, &fakeSecretRepo{}))
+
+	_, err := svc.Create(ctx, TunnelInput{
+		Name:           "edge",
+		AccountID:      "acct-edge",
+		Tunnel:         "edge",
+		ConfigPath:     "/etc/cloudflared/edge.yml",
+		MetricsAddress: "127.0.0.1:20242",
+		Token:          "token-edge",
+	})
+	require.NoError(t, err)
+	require.Empty(t, repo.tunnels[0].Token)
+
+	cfg, err := svc.Config(ctx, config.Config{}, "edge")
+	require.NoError(t, err)
+	require.Equal(t, "token-edge", cfg.CloudflareAPIToken)
+
+	updated, err := svc.Update(ctx, "edge", TunnelInput{
+		AccountID:      "acct-edge",
+		Tunnel:         "edge",
+		ConfigPath:     "/etc/cloudflared/edge.yml",
+		MetricsAddress: "127.0.0.1:20242",
+	})
+	require.NoError(t, err)
+	require.True(t, updated.TokenSet)
+
+	updated, err = svc.Update(ctx, "edge", TunnelInput{
+		AccountID:      "acct-edge",
+		Tunnel:         "edge",
+		ConfigPath:     "/etc/cloudflared/edge.yml",
+		MetricsAddress: "127.0.0.1:20242",
+		ClearToken:     true,
+	})
+	require.NoError(t, err)
+	require.False(t, updated.TokenSet)
+
+	cfg, err = svc.Config(ctx, config.Config{CloudflareAPIToken: "token-main"}, "edge")
+	require.NoError(t, err)
+	require.Equal(t, "token-main", cfg.CloudflareAPIToken)
+}
+
+func TestTunnelServiceSealsTokenWithoutVault(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	svc, repo := newTestTunnelService(config.Config{SessionSecret: "test-session-secret", CloudflareAccountID: "acct-main"})
+
+	_, err := svc.Create(ctx, TunnelInput{
+		Name:           "edge",
+		AccountID:      "acct-edge",
+		Tunnel:         "edge",
+		ConfigPath:     "/etc/cloudflared/edge.yml",
+		MetricsAddress: "127.0.0.1:20242",
+		Token:          "token-edge",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, repo.tunnels[0].Token)
+	require.NotContains(t, repo.tunnels[0].Token, "token-edge")
+
+	cfg, err := svc.Config(ctx, config.Config{}, "edge")
+	require.NoError(t, err)
+	require.Equal(t, "token-edge", cfg.CloudflareAPIToken)
+
+	// A row written before tokens were sealed is sealed on start.
+	repo.tunnels = append(repo.tunnels, models.CloudflareTunnel{
+		Name:           "legacy",
+		Tunnel:         "legacy",
+		ConfigPath:     "/etc/cloudflared/legacy.yml",
+		MetricsAddress: "127.0.0.1:20243",
+		Token:          "token-legacy",
+	})
+	repo.tunnels[1].ID = 99
+	sealed, err := svc.SealStoredTokens(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, sealed)
+	require.NotContains(t, repo.tunnels[1].Token, "token-legacy")
+	cfg, err = svc.Config(ctx, config.Config{}, "legacy")
+	require.NoError(t, err)
+	require.Equal(t, "token-legacy", cfg.CloudflareAPIToken)
+
+	sealed, err = svc.SealStoredTokens(ctx)
+	require.NoError(t, err)
+	require.Zero(t, sealed)
+
+	keyless, _ := newTestTunnelService(config.Config{})
+	_, err = keyless.Create(ctx, TunnelInput{
+		Name:           "edge",
+		AccountID:      "acct-edge",
+		Tunnel:         "edge",
+		ConfigPath:     "/etc/cloudflared/edge.yml",
+		MetricsAddress: "127.0.0.1:20242",
+		Token:          "token-edge",
+	})
+	require.Equal(t, errs.CodeTunnelInvalid, tunnelErrorCode(t, err))
+}
+
+func TestTunnelServiceDeleteRefusesAssignedTunnel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	svc, repo := newTestTunnelService(config.Config{SessionSecret: "test-session-secret"}, models.Project{Name: "demo"})
+
+	_, err := svc.Create(ctx, TunnelInput{Name: "edge", Tunnel: "edge", ConfigPath: "/etc/cloudflared/edge.yml", MetricsAddress: "127.0.0.1:20242"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Assign(ctx, "demo", "edge"))
+
+	_, err = svc.Delete(ctx, "edge")
+	require.Equal(t, errs.CodeTunnelInUse, tunnelErrorCode(t, err))
+	require.Len(t, repo.tunnels, 1)
+
+	require.NoError(t, svc.Assign(ctx, "demo", DefaultTunnelName))
+	tunnel, err := svc.ProjectTunnel(ctx, "demo")
+	require.NoError(t, err)
+	require.Empty(t, tunnel)
+
+	_, err = svc.Delete(ctx, "edge")
+	require.NoError(t, err)
+	require.Empty(t, repo.tunnels)
+}
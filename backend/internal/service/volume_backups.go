@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/jobs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+const (
+	VolumeBackupReasonManual     = "manual"
+	VolumeBackupReasonPreArchive = "pre_archive"
+
+	defaultVolumeBackupKeep        = 5
+	maxVolumeBackupRetention       = 100
+	defaultVolumeBackupWaitTimeout = 2 * time.Hour
+	volumeBackupIDLayout           = "20060102T150405Z"
+)
+
+// ProjectVolumeBackup is one snapshot of a project's named volumes, read from
+// the manifest the bridge worker writes next to the archives.
+type ProjectVolumeBackup struct {
+	Project   string                         `json:"project"`
+	BackupID  string                         `json:"backupId"`
+	CreatedAt time.Time                      `json:"createdAt"`
+	Reason    string                         `json:"reason,omitempty"`
+	SizeBytes int64                          `json:"sizeBytes"`
+	Volumes   []contract.VolumeBackupArchive `json:"volumes"`
+}
+
+type ProjectVolumeBackupRequest struct {
+	Project string `json:"project"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type ProjectVolumeRestoreRequest struct {
+	Project  string   `json:"project"`
+	BackupID string   `json:"backupId"`
+	Volumes  []string `json:"volumes,omitempty"`
+}
+
+// SetVolumeBackups configures where volume backups live and how many each
+// project keeps when it has no retention of its own.
+func (s *HostService) SetVolumeBackups(dir string, keep int) {
+	s.volumeBackupDir = strings.TrimSpace(dir)
+	if keep < 1 {
+		keep = defaultVolumeBackupKeep
+	}
+	s.volumeBackupKeep = keep
+}
+
+// ProjectVolumes returns the named volumes compose created for the project.
+func (s *HostService) ProjectVolumes(ctx context.Context, project string) ([]string, error) {
+	volumes, err := s.listVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	normalized := strings.ToLower(strings.TrimSpace(project))
+	names := make([]string, 0)
+	for _, volume := range volumes {
+		labels := parseDockerLabels(volume.Labels)
+		if strings.ToLower(labels["com.docker.compose.project"]) == normalized {
+			names = append(names, strings.TrimSpace(volume.Name))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// BackupProjectVolumesWithLogger snapshots every named volume of the project
+// and then prunes old backups down to the project's retention. It returns a
+// nil backup when the project has no volumes. A failed prune is logged and
+// does not fail the backup.
+func (s *HostService) BackupProjectVolumesWithLogger(
+	ctx context.Context,
+	requestID string,
+	project string,
+	reason string,
+	logger jobs.Logger,
+) (*ProjectVolumeBackup, error) {
+	project = strings.TrimSpace(project)
+	if project == "" || project == "." || project == ".." {
+		return nil, fmt.Errorf("invalid project name")
+	}
+	if s.infraClient == nil {
+		return nil, fmt.Errorf("infra bridge client unavailable")
+	}
+	volumes, err := s.ProjectVolumes(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	if len(volumes) == 0 {
+		hostLogf(logger, "project %q has no named volumes; nothing to back up", project)
+		return nil, nil
+	}
+
+	backupID := time.Now().UTC().Format(volumeBackupIDLayout)
+	hostLogf(logger, "backing up %d volume(s) of project %q as %s: %s", len(volumes), project, backupID, strings.Join(volumes, ", "))
+	waitCtx, cancel := withVolumeBackupWaitTimeout(ctx)
+	defer cancel()
+	result, err := s.infraClient.DockerVolumeBackup(waitCtx, requestID, contract.DockerVolumeBackupPayload{
+		Project:  project,
+		BackupID: backupID,
+		Volumes:  volumes,
+		Reason:   reason,
+	})
+	if err != nil {
+		return nil, bridgeTaskErrorWithCode(errs.CodeProjectBackupFailed, "volume backup failed", contract.TaskTypeDockerVolumeBackup, project, err)
+	}
+	if err := bridgeResultErrorWithCode(errs.CodeProjectBackupFailed, "volume backup failed", contract.TaskTypeDockerVolumeBackup, project, result); err != nil {
+		return nil, err
+	}
+	for _, line := range result.LogTail {
+		hostLogf(logger, "%s", line)
+	}
+
+	var payload struct {
+		Manifest contract.VolumeBackupManifest `json:"manifest"`
+	}
+	raw, err := json.Marshal(result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("encode volume backup payload: %w", err)
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("decode volume backup payload: %w", err)
+	}
+	backup := newProjectVolumeBackup(payload.Manifest)
+
+	keep := s.volumeBackupRetention(ctx, project)
+	pruneResult, pruneErr := s.infraClient.VolumeBackupPrune(ctx, requestID, project, keep)
+	if pruneErr == nil {
+		pruneErr = bridgeResultError("prune volume backups failed", contract.TaskTypeVolumeBackupPrune, project, pruneResult)
+	}
+	if pruneErr != nil {
+		hostLogf(logger, "warning: pruning backups of %q to %d failed: %v", project, keep, pruneErr)
+	} else {
+		for _, line := range pruneResult.LogTail {
+			hostLogf(logger, "%s", line)
+		}
+	}
+	return &backup, nil
+}
+
+// RestoreProjectVolumesWithLogger replaces volume contents with a backup. A
+// running stack is taken down first so no container writes during the
+// restore, and brought back up afterwards.
+func (s *HostService) RestoreProjectVolumesWithLogger(
+	ctx context.Context,
+	requestID string,
+	project string,
+	backupID string,
+	volumes []string,
+	logger jobs.Logger,
+) error {
+	project = strings.TrimSpace(project)
+	if project == "" || project == "." || project == ".." {
+		return fmt.Errorf("invalid project name")
+	}
+	if s.infraClient == nil {
+		return fmt.Errorf("infra bridge client unavailable")
+	}
+	containers, err := s.ListContainers(ctx, false)
+	if err != nil {
+		return err
+	}
+	running := false
+	for _, container := range containers {
+		if strings.EqualFold(strings.TrimSpace(container.Project), project) {
+			running = true
+			break
+		}
+	}
+
+	if running {
+		hostLogf(logger, "stopping compose stack of %q before restore", project)
+		result, err := s.infraClient.ComposeDownStack(ctx, requestID, contract.ComposeDownStackPayload{Project: project})
+		if err == nil {
+			err = bridgeResultError("stop compose stack failed", contract.TaskTypeComposeDownStack, project, result)
+		}
+		if err != nil {
+			return bridgeTaskErrorWithCode(errs.CodeProjectBackupFailed, "stop compose stack failed", contract.TaskTypeComposeDownStack, project, err)
+		}
+	}
+
+	hostLogf(logger, "restoring backup %s of project %q", backupID, project)
+	waitCtx, cancel := withVolumeBackupWaitTimeout(ctx)
+	defer cancel()
+	result, restoreErr := s.infraClient.DockerVolumeRestore(waitCtx, requestID, contract.DockerVolumeRestorePayload{
+		Project:  project,
+		BackupID: backupID,
+		Volumes:  volumes,
+	})
+	if restoreErr == nil {
+		restoreErr = bridgeResultErrorWithCode(errs.CodeProjectBackupFailed, "volume restore failed", contract.TaskTypeDockerVolumeRestore, project, result)
+	} else {
+		restoreErr = bridgeTaskErrorWithCode(errs.CodeProjectBackupFailed, "volume restore failed", contract.TaskTypeDockerVolumeRestore, project, restoreErr)
+	}
+	if restoreErr == nil {
+		for _, line := range result.LogTail {
+			hostLogf(logger, "%s", line)
+		}
+	}
+
+	if running {
+		// Bring the stack back even after a failed restore; the worker verifies
+		// checksums before touching volumes, so most failures leave data intact.
+		hostLogf(logger, "starting compose stack of %q", project)
+		upCtx, upCancel := withComposeUpWaitTimeout(ctx)
+		defer upCancel()
+		upResult, upErr := s.infraClient.ComposeUpStack(upCtx, requestID, contract.ComposeUpStackPayload{Project: project})
+		if upErr == nil {
+			upErr = bridgeResultError("start compose stack failed", contract.TaskTypeComposeUpStack, project, upResult)
+		}
+		if upErr != nil {
+			if restoreErr != nil {
+				return fmt.Errorf("%w; starting the stack again also failed: %v", restoreErr, upErr)
+			}
+			return bridgeTaskError("start compose stack failed", contract.TaskTypeComposeUpStack, project, upErr)
+		}
+	}
+	return restoreErr
+}
+
+// CopyProjectVolumesWithLogger copies the named volumes of compose project
+// source into new volumes of compose project target, named the way compose
+// names them for target. Volumes with a fixed name are not copied: the
+// target's compose file refers to the same volume, so the two share it.
+func (s *HostService) CopyProjectVolumesWithLogger(
+	ctx context.Context,
+	requestID string,
+	source string,
+	target string,
+	logger jobs.Logger,
+) ([]contract.VolumeCopy, error) {
+	source = strings.TrimSpace(source)
+	target = strings.TrimSpace(target)
+	if source == "" || target == "" || source == target {
+		return nil, fmt.Errorf("invalid volume copy projects %q and %q", source, target)
+	}
+	if s.infraClient == nil {
+		return nil, fmt.Errorf("infra bridge client unavailable")
+	}
+	volumes, err := s.listVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	copies := make([]contract.VolumeCopy, 0)
+	for _, volume := range volumes {
+		labels := parseDockerLabels(volume.Labels)
+		if !strings.EqualFold(labels["com.docker.compose.project"], source) {
+			continue
+		}
+		name := strings.TrimSpace(volume.Name)
+		key := strings.TrimSpace(labels["com.docker.compose.volume"])
+		if key == "" || name != source+"_"+key {
+			hostLogf(logger, "volume %s has a fixed name; the clone shares it instead of getting a copy", name)
+			continue
+		}
+		copies = append(copies, contract.VolumeCopy{Source: name, Destination: target + "_" + key})
+	}
+	sort.Slice(copies, func(i, j int) bool { return copies[i].Source < copies[j].Source })
+	if len(copies) == 0 {
+		hostLogf(logger, "project %q has no named volumes to copy", source)
+		return copies, nil
+	}
+
+	hostLogf(logger, "copying %d volume(s) of project %q into %q", len(copies), source, target)
+	waitCtx, cancel := withVolumeBackupWaitTimeout(ctx)
+	defer cancel()
+	result, err := s.infraClient.DockerVolumeCopy(waitCtx, requestID, contract.DockerVolumeCopyPayload{
+		Project: target,
+		Copies:  copies,
+	})
+	if err != nil {
+		return nil, bridgeTaskErrorWithCode(errs.CodeProjectCloneFailed, "volume copy failed", contract.TaskTypeDockerVolumeCopy, target, err)
+	}
+	if err := bridgeResultErrorWithCode(errs.CodeProjectCloneFailed, "volume copy failed", contract.TaskTypeDockerVolumeCopy, target, result); err != nil {
+		return nil, err
+	}
+	for _, line := range result.LogTail {
+		hostLogf(logger, "%s", line)
+	}
+	return copies, nil
+}
+
+// ListProjectVolumeBackups reads the project's backup manifests, newest
+// first. Backups without a readable manifest are skipped.
+func (s *HostService) ListProjectVolumeBackups(project string) ([]ProjectVolumeBackup, error) {
+	projectDir, err := s.volumeBackupProjectDir(project)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []ProjectVolumeBackup{}, nil
+		}
+		return nil, errs.Wrap(errs.CodeProjectBackupFailed, "failed to read backup directory", err)
+	}
+	backups := make([]ProjectVolumeBackup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := readVolumeBackupManifest(filepath.Join(projectDir, entry.Name()))
+		if err != nil || manifest.BackupID != entry.Name() {
+			continue
+		}
+		backups = append(backups, newProjectVolumeBackup(manifest))
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// GetProjectVolumeBackup returns one backup of the project.
+func (s *HostService) GetProjectVolumeBackup(project, backupID string) (ProjectVolumeBackup, error) {
+	projectDir, err := s.volumeBackupProjectDir(project)
+	if err != nil {
+		return ProjectVolumeBackup{}, err
+	}
+	backupID = strings.TrimSpace(backupID)
+	backupDir := filepath.Join(projectDir, backupID)
+	if backupID == "" || filepath.Base(backupDir) != backupID || !isPathWithinBase(projectDir, backupDir) {
+		return ProjectVolumeBackup{}, errs.New(errs.CodeProjectBackupNotFound, "backup not found")
+	}
+	manifest, err := readVolumeBackupManifest(backupDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ProjectVolumeBackup{}, errs.New(errs.CodeProjectBackupNotFound, "backup not found")
+		}
+		return ProjectVolumeBackup{}, errs.Wrap(errs.CodeProjectBackupFailed, "failed to read backup manifest", err)
+	}
+	return newProjectVolumeBackup(manifest), nil
+}
+
+// UpdateVolumeBackupRetention stores how many backups the project keeps. Zero
+// falls back to the VOLUME_BACKUP_KEEP default.
+func (s *HostService) UpdateVolumeBackupRetention(ctx context.Context, project string, retention int) (*models.Project, error) {
+	if retention < 0 || retention > maxVolumeBackupRetention {
+		return nil, errs.New(errs.CodeProjectBackupRetention, fmt.Sprintf("retention must be between 0 and %d", maxVolumeBackupRetention))
+	}
+	if s.projects == nil {
+		return nil, fmt.Errorf("project repository unavailable")
+	}
+	record, err := s.projects.GetByName(ctx, strings.ToLower(strings.TrimSpace(project)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeProjectNotFound, "project not found")
+		}
+		return nil, err
+	}
+	record.BackupRetention = retention
+	if err := s.projects.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("update project backup retention: %w", err)
+	}
+	return record, nil
+}
+
+// ProjectVolumeBackupRetention returns the project's own retention, or zero
+// when it uses the default.
+func (s *HostService) ProjectVolumeBackupRetention(ctx context.Context, project string) int {
+	if s.projects == nil {
+		return 0
+	}
+	record, err := s.projects.GetByName(ctx, strings.ToLower(strings.TrimSpace(project)))
+	if err != nil || record == nil {
+		return 0
+	}
+	return record.BackupRetention
+}
+
+// DefaultVolumeBackupRetention is the retention of projects without their own.
+func (s *HostService) DefaultVolumeBackupRetention() int {
+	if s.volumeBackupKeep < 1 {
+		return defaultVolumeBackupKeep
+	}
+	return s.volumeBackupKeep
+}
+
+func (s *HostService) volumeBackupRetention(ctx context.Context, project string) int {
+	if retention := s.ProjectVolumeBackupRetention(ctx, project); retention > 0 {
+		return retention
+	}
+	return s.DefaultVolumeBackupRetention()
+}
+
+func (s *HostService) volumeBackupProjectDir(project string) (string, error) {
+	if s.volumeBackupDir == "" {
+		return "", errs.New(errs.CodeProjectBackupFailed, "volume backup directory is not configured")
+	}
+	project = strings.ToLower(strings.TrimSpace(project))
+	base := filepath.Clean(s.volumeBackupDir)
+	projectDir := filepath.Join(base, project)
+	if project == "" || filepath.Base(projectDir) != project || !isPathWithinBase(base, projectDir) {
+		return "", errs.New(errs.CodeProjectInvalidName, "invalid project name")
+	}
+	return projectDir, nil
+}
+
+func readVolumeBackupManifest(backupDir string) (contract.VolumeBackupManifest, error) {
+	raw, err := os.ReadFile(filepath.Join(backupDir, contract.VolumeBackupManifestName))
+	if err != nil {
+		return contract.VolumeBackupManifest{}, err
+	}
+	var manifest contract.VolumeBackupManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return contract.VolumeBackupManifest{}, fmt.Errorf("decode backup manifest: %w", err)
+	}
+	if manifest.Version != contract.VolumeBackupManifestVersion {
+		return contract.VolumeBackupManifest{}, fmt.Errorf("unsupported backup manifest version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+func newProjectVolumeBackup(manifest contract.VolumeBackupManifest) ProjectVolumeBackup {
+	backup := ProjectVolumeBackup{
+		Project:   manifest.Project,
+		BackupID:  manifest.BackupID,
+		CreatedAt: manifest.CreatedAt,
+		Reason:    manifest.Reason,
+		Volumes:   append([]contract.VolumeBackupArchive{}, manifest.Volumes...),
+	}
+	for _, volume := range manifest.Volumes {
+		backup.SizeBytes += volume.SizeBytes
+	}
+	return backup
+}
+
+func withVolumeBackupWaitTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, defaultVolumeBackupWaitTimeout)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-notes/internal/config"
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/models"
+)
+
+func volumeBackupTestBridge() *stubHostInfraBridgeClient {
+	return &stubHostInfraBridgeClient{
+		listVolumesResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"lines": []string{
+					`{"Name":"demo_uploads","Driver":"local","Labels":"com.docker.compose.project=demo"}`,
+					`{"Name":"demo_db","Driver":"local","Labels":"com.docker.compose.project=demo"}`,
+					`{"Name":"other_data","Driver":"local","Labels":"com.docker.compose.project=other"}`,
+				},
+			},
+		},
+		volumeBackupResult: contract.Result{
+			Status: contract.StatusSucceeded,
+			Data: map[string]any{
+				"manifest": map[string]any{
+					"version":  1,
+					"project":  "demo",
+					"backupId": "20260102T030405Z",
+					"volumes": []map[string]any{
+						{"volume": "demo_db", "file": "demo_db.tar.gz", "sizeBytes": 100, "sha256": "aa"},
+						{"volume": "demo_uploads", "file": "demo_uploads.tar.gz", "sizeBytes": 20, "sha256": "bb"},
+					},
+				},
+			},
+		},
+		pruneResult: contract.Result{Status: contract.StatusSucceeded},
+	}
+}
+
+func writeVolumeBackupManifestFixture(t *testing.T, dir string, manifest contract.VolumeBackupManifest) {
+	t.Helper()
+	backupDir := filepath.Join(dir, manifest.Project, manifest.BackupID)
+	require.NoError(t, os.MkdirAll(backupDir, 0o700))
+	content, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, contract.VolumeBackupManifestName), content, 0o600))
+}
+
+func TestBackupProjectVolumesUsesProjectRetention(t *testing.T) {
+	t.Parallel()
+
+	bridge := volumeBackupTestBridge()
+	projects := &archiveTestProjectRepo{projects: []models.Project{{Name: "demo", BackupRetention: 2}}}
+	svc := NewHostService(t.TempDir(), projects, bridge)
+	svc.SetVolumeBackups(t.TempDir(), 7)
+
+	backup, err := svc.BackupProjectVolumesWithLogger(context.Background(), "job-1", "demo", VolumeBackupReasonManual, &captureHostLogger{})
+	require.NoError(t, err)
+	require.NotNil(t, backup)
+	require.Equal(t, int64(120), backup.SizeBytes)
+
+	require.Len(t, bridge.volumeBackupPayloads, 1)
+	require.Equal(t, []string{"demo_db", "demo_uploads"}, bridge.volumeBackupPayloads[0].Volumes)
+	require.Equal(t, VolumeBackupReasonManual, bridge.volumeBackupPayloads[0].Reason)
+	require.Equal(t, []int{2}, bridge.pruneKeeps)
+
+	// Without a project override the configured default applies, and a failed
+	// prune does not fail the backup.
+	projects.projects[0].BackupRetention = 0
+	bridge.pruneErr = errors.New("disk busy")
+	logger := &captureHostLogger{}
+	_, err = svc.BackupProjectVolumesWithLogger(context.Background(), "job-2", "demo", VolumeBackupReasonManual, logger)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 7}, bridge.pruneKeeps)
+	require.Contains(t, strings.Join(logger.lines, "\n"), "warning: pruning backups of \"demo\" to 7 failed")
+
+	backup, err = svc.BackupProjectVolumesWithLogger(context.Background(), "job-3", "empty", VolumeBackupReasonManual, &captureHostLogger{})
+	require.NoError(t, err)
+	require.Nil(t, backup)
+	require.Len(t, bridge.volumeBackupPayloads, 2)
+}
+
+func TestRestoreProjectVolumesStopsAndRestartsRunningStack(t *testing.T) {
+	t.Parallel()
+
+	bridge := volumeBackupTestBridge()
+	bridge.listContainersResult = contract.Result{
+		Status: contract.StatusSucceeded,
+		Data: map[string]any{
+			"lines": []string{
+				`{"ID":"abc123","Image":"postgres:16","Names":"demo-db-1","Status":"Up 2 minutes","Labels":"com.docker.compose.project=demo,com.docker.compose.service=db"}`,
+			},
+		},
+	}
+	bridge.volumeRestoreResult = contract.Result{Status: contract.StatusFailed, Error: &contract.Error{Message: "checksum mismatch for demo_db.tar.gz"}}
+	svc := NewHostService(t.TempDir(), &archiveTestProjectRepo{}, bridge)
+
+	err := svc.RestoreProjectVolumesWithLogger(context.Background(), "job-4", "demo", "20260102T030405Z", []string{"demo_db"}, &captureHostLogger{})
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectBackupFailed, typed.Code)
+	require.Equal(t, []contract.ComposeDownStackPayload{{Project: "demo"}}, bridge.composeDownPayloads)
+	require.Equal(t, []contract.DockerVolumeRestorePayload{{Project: "demo", BackupID: "20260102T030405Z", Volumes: []string{"demo_db"}}}, bridge.volumeRestorePayloads)
+	// The stack comes back up even though the restore failed.
+	require.Equal(t, []contract.ComposeUpStackPayload{{Project: "demo"}}, bridge.composePayloads)
+}
+
+func TestListProjectVolumeBackupsReadsManifestsNewestFirst(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeVolumeBackupManifestFixture(t, dir, contract.VolumeBackupManifest{Version: 1, Project: "demo", BackupID: "older", CreatedAt: base})
+	writeVolumeBackupManifestFixture(t, dir, contract.VolumeBackupManifest{
+		Version:   1,
+		Project:   "demo",
+		BackupID:  "newer",
+		CreatedAt: base.Add(time.Hour),
+		Reason:    VolumeBackupReasonPreArchive,
+		Volumes:   []contract.VolumeBackupArchive{{Volume: "demo_db", File: "demo_db.tar.gz", SizeBytes: 42}},
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "demo", "partial"), 0o700))
+
+	svc := NewHostService(t.TempDir(), nil, nil)
+	svc.SetVolumeBackups(dir, 0)
+	backups, err := svc.ListProjectVolumeBackups("demo")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	require.Equal(t, "newer", backups[0].BackupID)
+	require.Equal(t, int64(42), backups[0].SizeBytes)
+	require.Equal(t, "older", backups[1].BackupID)
+
+	_, err = svc.GetProjectVolumeBackup("demo", "partial")
+	typed, ok := errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectBackupNotFound, typed.Code)
+	_, err = svc.GetProjectVolumeBackup("demo", "../other")
+	typed, ok = errs.From(err)
+	require.True(t, ok)
+	require.Equal(t, errs.CodeProjectBackupNotFound, typed.Code)
+
+	backups, err = svc.ListProjectVolumeBackups("missing")
+	require.NoError(t, err)
+	require.Empty(t, backups)
+}
+
+func TestHandleProjectArchiveAbortsWhenPreArchiveBackupFails(t *testing.T) {
+	t.Parallel()
+
+	bridge := volumeBackupTestBridge()
+	bridge.volumeBackupErr = errors.New("helper image pull failed")
+	templatesDir, projectDir := writeRestoreTestProject(t)
+	projects := &archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, Status: "running"}}}
+	host := NewHostService(templatesDir, projects, bridge)
+	host.SetVolumeBackups(t.TempDir(), 5)
+
+	payload, err := json.Marshal(ProjectArchiveJobRequest{
+		Project: "demo",
+		Options: ProjectArchiveOptions{RemoveContainers: true, RemoveVolumes: true, BackupVolumes: true},
+		Targets: ProjectArchiveTargets{Containers: []string{"demo-db-1"}},
+	})
+	require.NoError(t, err)
+	workflows := &ProjectWorkflows{
+		cfg:      config.Config{TemplatesDir: templatesDir},
+		projects: projects,
+		host:     host,
+	}
+	logger := &archiveTestLogger{}
+
+	err = workflows.handleProjectArchive(context.Background(), models.Job{Input: string(payload)}, logger)
+	require.ErrorContains(t, err, "pre-archive volume backup failed; nothing was archived")
+	require.False(t, bridge.removeCalled)
+	require.Equal(t, "running", projects.projects[0].Status)
+	requireArchiveLogContains(t, logger.lines, "archive step volume_backup: result=failed")
+}
+
+func TestProjectRestorePlanRestoresPreArchiveVolumeBackup(t *testing.T) {
+	t.Parallel()
+
+	templatesDir, projectDir := writeRestoreTestProject(t)
+	backupDir := t.TempDir()
+	writeVolumeBackupManifestFixture(t, backupDir, contract.VolumeBackupManifest{Version: 1, Project: "demo", BackupID: "20260102T030405Z"})
+	writeRestoreTestManifest(t, projectDir, ProjectArchiveManifest{
+		Version:        projectArchiveManifestVersion,
+		Project:        "demo",
+		VolumesRemoved: true,
+		VolumeBackupID: "20260102T030405Z",
+	})
+	projects := &archiveTestProjectRepo{projects: []models.Project{{Name: "demo", Path: projectDir, Status: "archived"}}}
+	host := NewHostService(templatesDir, projects, nil)
+	host.SetVolumeBackups(backupDir, 5)
+	svc := NewProjectArchiveService(config.Config{TemplatesDir: templatesDir}, projects, nil, NewJobService(&archiveTestJobRepo{}, nil), host)
+
+	_, plan, err := svc.QueueRestore(context.Background(), "demo", ProjectArchiveActor{})
+	require.NoError(t, err)
+	require.Equal(t, "20260102T030405Z", plan.VolumeBackupID)
+	require.Empty(t, plan.Unrestorable)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(backupDir, "demo")))
+	_, plan, err = svc.QueueRestore(context.Background(), "demo", ProjectArchiveActor{})
+	require.NoError(t, err)
+	require.Empty(t, plan.VolumeBackupID)
+	require.Equal(t, []string{projectRestoreUnrestorableBackup}, plan.Unrestorable)
+}
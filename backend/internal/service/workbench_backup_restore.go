@@ -177,6 +177,11 @@ func (s *WorkbenchService) RestoreComposeFromBackup(
 	if err := s.replaceWorkbenchComposeAtomically(ctx, currentSource.ProjectDir, currentSource.ComposePath, raw); err != nil {
 		return WorkbenchComposeRestoreResult{}, workbenchComposeRestoreError(snapshot, currentSource, target, "failed to restore workbench compose backup", err)
 	}
+	requiresImport := strings.TrimSpace(snapshot.SourceFingerprint) != restoredFingerprint
+	if requiresImport {
+		// A restore is a deliberate rollback; do not let apply merge it away.
+		s.dropWorkbenchComposeBase(ctx, currentSource.ProjectDir)
+	}
 
 	return WorkbenchComposeRestoreResult{
 		Metadata: WorkbenchComposeRestoreMetadata{
@@ -184,7 +189,7 @@ func (s *WorkbenchService) RestoreComposeFromBackup(
 			SourceFingerprint:   strings.TrimSpace(snapshot.SourceFingerprint),
 			RestoredFingerprint: restoredFingerprint,
 			ComposePath:         currentSource.ComposePath,
-			RequiresImport:      strings.TrimSpace(snapshot.SourceFingerprint) != restoredFingerprint,
+			RequiresImport:      requiresImport,
 		},
 		Backup:       workbenchComposeBackupMetadataFromStored(target),
 		ComposeBytes: len(raw),
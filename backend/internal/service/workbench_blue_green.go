@@ -0,0 +1,568 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/repository"
+)
+
+const (
+	WorkbenchBlueGreenSlotBlue  = "blue"
+	WorkbenchBlueGreenSlotGreen = "green"
+)
+
+// WorkbenchBlueGreenPlan splits a stack for a blue/green redeploy. Data stores
+// stay pinned in the project's own compose project; every other service runs
+// in a slot compose project that shares the pinned services' networks and
+// named volumes, so two slots can run side by side against the same data.
+type WorkbenchBlueGreenPlan struct {
+	ProjectName        string   `json:"projectName"`
+	Revision           int      `json:"revision"`
+	ComposeProject     string   `json:"composeProject"`
+	ProxyPort          int      `json:"proxyPort"`
+	ProxyService       string   `json:"proxyService"`
+	ProxyContainerPort int      `json:"proxyContainerPort"`
+	ProxyHostIP        string   `json:"proxyHostIp,omitempty"`
+	Services           []string `json:"services"`
+	Pinned             []string `json:"pinned"`
+	// ComposePort is the host port the project compose file publishes the
+	// proxy service on; SlotPorts holds the port of each slot compose file
+	// already written. A slot's next deploy reuses the port it last freed.
+	ComposePort int            `json:"composePort,omitempty"`
+	SlotPorts   map[string]int `json:"slotPorts,omitempty"`
+}
+
+// WorkbenchBlueGreenSlot is a slot compose file written next to the project's
+// compose file. Only the proxy port is published, on Port.
+type WorkbenchBlueGreenSlot struct {
+	WorkbenchBlueGreenPlan
+	Slot        string `json:"slot"`
+	SlotProject string `json:"slotProject"`
+	ProjectDir  string `json:"projectDir"`
+	ComposeFile string `json:"composeFile"`
+	Port        int    `json:"port"`
+}
+
+// WorkbenchBlueGreenSlotProject returns the compose project name used for a
+// slot of the given compose project.
+func WorkbenchBlueGreenSlotProject(composeProject, slot string) string {
+	return composeProject + "-" + slot
+}
+
+// WorkbenchBlueGreenSlotComposeFile returns the slot compose file name,
+// relative to the project directory.
+func WorkbenchBlueGreenSlotComposeFile(slot string) string {
+	return "docker-compose." + slot + ".yml"
+}
+
+func (s *WorkbenchService) PlanBlueGreenDeploy(ctx context.Context, projectName string) (WorkbenchBlueGreenPlan, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchBlueGreenPlan{}, err
+	}
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBlueGreenPlan{}, err
+	}
+	defer release()
+
+	plan, _, err := s.planBlueGreenDeployLocked(ctx, normalizedProject)
+	return plan, err
+}
+
+// WriteBlueGreenSlotCompose renders the slot compose file for slot, publishing
+// the proxy service on port, and writes it into the project directory.
+func (s *WorkbenchService) WriteBlueGreenSlotCompose(
+	ctx context.Context,
+	projectName string,
+	slot string,
+	port int,
+) (WorkbenchBlueGreenSlot, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchBlueGreenSlot{}, err
+	}
+	slot = strings.ToLower(strings.TrimSpace(slot))
+	if slot != WorkbenchBlueGreenSlotBlue && slot != WorkbenchBlueGreenSlotGreen {
+		return WorkbenchBlueGreenSlot{}, fmt.Errorf("unknown blue/green slot %q", slot)
+	}
+	if port <= 0 || port > 65535 {
+		return WorkbenchBlueGreenSlot{}, fmt.Errorf("invalid slot port %d", port)
+	}
+
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBlueGreenSlot{}, err
+	}
+	defer release()
+	return s.writeBlueGreenSlotComposeLocked(ctx, normalizedProject, slot, port)
+}
+
+// writeBlueGreenSlotComposeLocked is WriteBlueGreenSlotCompose for callers
+// that already hold the project lock.
+func (s *WorkbenchService) writeBlueGreenSlotComposeLocked(
+	ctx context.Context,
+	normalizedProject string,
+	slot string,
+	port int,
+) (WorkbenchBlueGreenSlot, error) {
+	plan, source, err := s.planBlueGreenDeployLocked(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBlueGreenSlot{}, err
+	}
+	content, err := renderWorkbenchBlueGreenSlotCompose(source.Normalized, plan, port)
+	if err != nil {
+		return WorkbenchBlueGreenSlot{}, workbenchSourceInvalidError(
+			projectPathResolution{NormalizedName: normalizedProject, ProjectDir: source.ProjectDir},
+			source.ComposePath,
+			"failed to render blue/green slot compose",
+			err,
+		)
+	}
+
+	composeFile := WorkbenchBlueGreenSlotComposeFile(slot)
+	slotPath := filepath.Join(source.ProjectDir, composeFile)
+	if s.fileClient != nil {
+		if _, err := s.fileClient.ProjectFileWriteAtomic(ctx, "", contract.ProjectFileWriteAtomicPayload{
+			BasePath: source.ProjectDir,
+			Path:     slotPath,
+			Content:  content,
+			Mode:     0o644,
+		}); err != nil {
+			return WorkbenchBlueGreenSlot{}, fmt.Errorf("write slot compose file: %w", err)
+		}
+	} else if err := os.WriteFile(slotPath, []byte(content), 0o644); err != nil {
+		return WorkbenchBlueGreenSlot{}, fmt.Errorf("write slot compose file: %w", err)
+	}
+
+	return WorkbenchBlueGreenSlot{
+		WorkbenchBlueGreenPlan: plan,
+		Slot:                   slot,
+		SlotProject:            WorkbenchBlueGreenSlotProject(plan.ComposeProject, slot),
+		ProjectDir:             source.ProjectDir,
+		ComposeFile:            composeFile,
+		Port:                   port,
+	}, nil
+}
+
+func (s *WorkbenchService) planBlueGreenDeployLocked(
+	ctx context.Context,
+	normalizedProject string,
+) (WorkbenchBlueGreenPlan, WorkbenchComposeSource, error) {
+	if s.projects == nil {
+		return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, fmt.Errorf("project repository unavailable")
+	}
+	record, err := s.projects.GetByName(ctx, normalizedProject)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, errs.New(errs.CodeProjectNotFound, "project not found")
+		}
+		return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, fmt.Errorf("load project record: %w", err)
+	}
+
+	snapshot, err := s.loadStoredSnapshotForComposeLocked(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, err
+	}
+	source, err := s.ResolveComposeSource(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, err
+	}
+	// The slot file is rendered from the compose file on disk, classified by
+	// the stored snapshot; both must describe the same stack.
+	if strings.TrimSpace(source.Fingerprint) != strings.TrimSpace(snapshot.SourceFingerprint) {
+		return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, errs.WithDetails(
+			errs.New(errs.CodeWorkbenchDriftDetected, "compose file changed since the last import; import or apply before a blue/green deploy"),
+			map[string]any{
+				"project":            normalizedProject,
+				"sourceFingerprint":  strings.TrimSpace(snapshot.SourceFingerprint),
+				"currentFingerprint": strings.TrimSpace(source.Fingerprint),
+			},
+		)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(source.Normalized), &document); err != nil {
+		return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, workbenchSourceInvalidError(
+			projectPathResolution{NormalizedName: normalizedProject, ProjectDir: source.ProjectDir},
+			source.ComposePath,
+			"failed to parse compose source",
+			err,
+		)
+	}
+	composeProject := workbenchComposeProjectName(workbenchDocumentRoot(&document), source.ProjectDir)
+
+	plan, err := buildWorkbenchBlueGreenPlan(snapshot, record.ProxyPort, composeProject, workbenchBlueGreenSlotPorts(source.ProjectDir))
+	if err != nil {
+		return WorkbenchBlueGreenPlan{}, WorkbenchComposeSource{}, err
+	}
+	plan.SlotPorts = workbenchBlueGreenSlotHostPorts(source.ProjectDir)
+	return plan, source, nil
+}
+
+// buildWorkbenchBlueGreenPlan finds the service publishing proxyPort. After a
+// switch the proxy port belongs to a slot, so slotPorts (the ports declared by
+// the slot compose files) are consulted when the project compose file does
+// not publish it.
+func buildWorkbenchBlueGreenPlan(
+	snapshot WorkbenchStackSnapshot,
+	proxyPort int,
+	composeProject string,
+	slotPorts []WorkbenchComposePort,
+) (WorkbenchBlueGreenPlan, error) {
+	normalized := normalizeWorkbenchStackSnapshot(snapshot)
+	if proxyPort <= 0 {
+		return WorkbenchBlueGreenPlan{}, workbenchBlueGreenPlanError(normalized, []WorkbenchMutationIssue{{
+			Class:   workbenchMutationIssueClassConflict,
+			Code:    "WB-BLUEGREEN-PROXY-UNKNOWN",
+			Path:    "$.proxyPort",
+			Message: "project has no proxy port on record",
+		}})
+	}
+
+	proxy := workbenchBlueGreenProxyPort(normalized.Ports, proxyPort)
+	if proxy == nil {
+		if slotProxy := workbenchBlueGreenProxyPort(slotPorts, proxyPort); slotProxy != nil && workbenchSnapshotHasService(normalized.Services, slotProxy.ServiceName) {
+			proxy = slotProxy
+		}
+	}
+	if proxy == nil {
+		return WorkbenchBlueGreenPlan{}, workbenchBlueGreenPlanError(normalized, []WorkbenchMutationIssue{{
+			Class:   workbenchMutationIssueClassConflict,
+			Code:    "WB-BLUEGREEN-PROXY-UNKNOWN",
+			Path:    "$.ports",
+			Message: fmt.Sprintf("no service publishes the project proxy port %d", proxyPort),
+		}})
+	}
+
+	plan := WorkbenchBlueGreenPlan{
+		ProjectName:        normalized.ProjectName,
+		Revision:           normalized.Revision,
+		ComposeProject:     composeProject,
+		ProxyPort:          proxyPort,
+		ProxyService:       proxy.ServiceName,
+		ProxyContainerPort: proxy.ContainerPort,
+		ProxyHostIP:        strings.TrimSpace(proxy.HostIP),
+		Services:           []string{},
+		Pinned:             []string{},
+	}
+	for _, port := range normalized.Ports {
+		if port.ServiceName == proxy.ServiceName && port.ContainerPort == proxy.ContainerPort && port.HostPort != nil {
+			plan.ComposePort = *port.HostPort
+			break
+		}
+	}
+	for _, service := range normalized.Services {
+		name := strings.TrimSpace(service.ServiceName)
+		if name == "" {
+			continue
+		}
+		if workbenchServiceIsDataStore(normalized, service) {
+			if strings.EqualFold(name, plan.ProxyService) {
+				return WorkbenchBlueGreenPlan{}, workbenchBlueGreenPlanError(normalized, []WorkbenchMutationIssue{{
+					Class:   workbenchMutationIssueClassConflict,
+					Code:    "WB-BLUEGREEN-PROXY-STATEFUL",
+					Path:    "$.services",
+					Message: fmt.Sprintf("service %q publishes the proxy port but looks like a database; it cannot run in two slots", name),
+					Service: name,
+				}})
+			}
+			plan.Pinned = append(plan.Pinned, name)
+			continue
+		}
+		plan.Services = append(plan.Services, name)
+	}
+	sort.Strings(plan.Services)
+	sort.Strings(plan.Pinned)
+	return plan, nil
+}
+
+func workbenchBlueGreenProxyPort(ports []WorkbenchComposePort, proxyPort int) *WorkbenchComposePort {
+	for idx := range ports {
+		port := ports[idx]
+		if port.HostPort == nil || *port.HostPort != proxyPort {
+			continue
+		}
+		if protocol := strings.ToLower(strings.TrimSpace(port.Protocol)); protocol != "" && protocol != "tcp" {
+			continue
+		}
+		return &ports[idx]
+	}
+	return nil
+}
+
+// workbenchBlueGreenSlotPorts returns the ports published by the slot compose
+// files present in projectDir. Missing or unreadable slot files are skipped.
+func workbenchBlueGreenSlotPorts(projectDir string) []WorkbenchComposePort {
+	ports := []WorkbenchComposePort{}
+	for _, slot := range []string{WorkbenchBlueGreenSlotBlue, WorkbenchBlueGreenSlotGreen} {
+		if parsed, ok := workbenchBlueGreenSlotFile(projectDir, slot); ok {
+			ports = append(ports, parsed.Ports...)
+		}
+	}
+	return ports
+}
+
+// workbenchBlueGreenSlotHostPorts maps each slot with a compose file in
+// projectDir to the host port that file publishes. Slot files publish only
+// the proxy port.
+func workbenchBlueGreenSlotHostPorts(projectDir string) map[string]int {
+	ports := map[string]int{}
+	for _, slot := range []string{WorkbenchBlueGreenSlotBlue, WorkbenchBlueGreenSlotGreen} {
+		parsed, ok := workbenchBlueGreenSlotFile(projectDir, slot)
+		if !ok {
+			continue
+		}
+		for _, port := range parsed.Ports {
+			if port.HostPort != nil && *port.HostPort > 0 {
+				ports[slot] = *port.HostPort
+				break
+			}
+		}
+	}
+	return ports
+}
+
+// workbenchBlueGreenPinnedServices lists the services of the project compose
+// file in projectDir that the slot compose file does not run.
+func workbenchBlueGreenPinnedServices(projectDir, slot string) ([]string, error) {
+	slotFile, ok := workbenchBlueGreenSlotFile(projectDir, slot)
+	if !ok {
+		return nil, fmt.Errorf("slot %q has no readable compose file in %s", slot, projectDir)
+	}
+	composePath, err := resolveComposeFile(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(composePath)
+	if err != nil {
+		return nil, fmt.Errorf("read compose file: %w", err)
+	}
+	normalized, _ := WorkbenchSourceFingerprint(raw)
+	parsed, err := ParseWorkbenchComposeCore(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("parse compose file: %w", err)
+	}
+
+	inSlot := make(map[string]struct{}, len(slotFile.Services))
+	for _, service := range slotFile.Services {
+		inSlot[service.ServiceName] = struct{}{}
+	}
+	pinned := []string{}
+	for _, service := range parsed.Services {
+		if _, ok := inSlot[service.ServiceName]; !ok {
+			pinned = append(pinned, service.ServiceName)
+		}
+	}
+	sort.Strings(pinned)
+	return pinned, nil
+}
+
+func workbenchBlueGreenSlotFile(projectDir, slot string) (WorkbenchComposeParseResult, bool) {
+	raw, err := os.ReadFile(filepath.Join(projectDir, WorkbenchBlueGreenSlotComposeFile(slot)))
+	if err != nil {
+		return WorkbenchComposeParseResult{}, false
+	}
+	normalized, _ := WorkbenchSourceFingerprint(raw)
+	parsed, err := ParseWorkbenchComposeCore(normalized)
+	if err != nil {
+		return WorkbenchComposeParseResult{}, false
+	}
+	return parsed, true
+}
+
+// renderWorkbenchBlueGreenSlotCompose derives a slot compose file from the
+// project compose source. Pinned services are dropped along with any
+// depends_on pointing at them, networks and named volumes are rebound to the
+// ones the project's compose project already owns, and only the proxy port is
+// published.
+func renderWorkbenchBlueGreenSlotCompose(source string, plan WorkbenchBlueGreenPlan, port int) (string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(source), &document); err != nil {
+		return "", err
+	}
+	root := workbenchDocumentRoot(&document)
+	if root == nil || root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("compose source is not a mapping")
+	}
+	servicesNode, ok := workbenchYAMLFindMapValue(root, "services")
+	if !ok || servicesNode.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("compose source has no services")
+	}
+
+	keep := make(map[string]struct{}, len(plan.Services))
+	for _, name := range plan.Services {
+		keep[name] = struct{}{}
+	}
+	pinned := make(map[string]struct{}, len(plan.Pinned))
+	for _, name := range plan.Pinned {
+		pinned[name] = struct{}{}
+	}
+
+	usesDefaultNetwork := false
+	kept := make([]*yaml.Node, 0, len(servicesNode.Content))
+	for idx := 0; idx+1 < len(servicesNode.Content); idx += 2 {
+		keyNode := servicesNode.Content[idx]
+		serviceNode := servicesNode.Content[idx+1]
+		if _, ok := keep[keyNode.Value]; !ok {
+			continue
+		}
+		if serviceNode.Kind != yaml.MappingNode {
+			return "", fmt.Errorf("service %q is not a mapping", keyNode.Value)
+		}
+
+		// Fixed names and host ports would collide with the running slot.
+		workbenchYAMLDeleteMapEntry(serviceNode, "container_name")
+		workbenchYAMLDeleteMapEntry(serviceNode, "ports")
+		if keyNode.Value == plan.ProxyService {
+			hostPort := port
+			workbenchPatchServicePorts(serviceNode, []WorkbenchComposePort{{
+				ServiceName:   plan.ProxyService,
+				ContainerPort: plan.ProxyContainerPort,
+				HostPort:      &hostPort,
+				HostIP:        plan.ProxyHostIP,
+				Protocol:      "tcp",
+			}})
+		}
+		workbenchBlueGreenPruneDependsOn(serviceNode, pinned)
+
+		_, hasNetworks := workbenchYAMLFindMapValue(serviceNode, "networks")
+		_, hasNetworkMode := workbenchYAMLFindMapValue(serviceNode, "network_mode")
+		if !hasNetworks && !hasNetworkMode {
+			usesDefaultNetwork = true
+		}
+		kept = append(kept, keyNode, serviceNode)
+	}
+	servicesNode.Content = kept
+
+	// -p sets the slot project name; a top-level name would be ignored anyway.
+	workbenchYAMLDeleteMapEntry(root, "name")
+
+	if volumesNode, ok := workbenchYAMLFindMapValue(root, "volumes"); ok && volumesNode.Kind == yaml.MappingNode {
+		for idx := 0; idx+1 < len(volumesNode.Content); idx += 2 {
+			name := volumesNode.Content[idx].Value
+			volumeNode := volumesNode.Content[idx+1]
+			if volumeNode.Kind != yaml.MappingNode {
+				volumeNode = workbenchYAMLMappingNode()
+				volumesNode.Content[idx+1] = volumeNode
+			}
+			if workbenchBlueGreenIsExternal(volumeNode) {
+				continue
+			}
+			if _, named := workbenchYAMLFindMapValue(volumeNode, "name"); !named {
+				workbenchYAMLSetMapEntry(volumeNode, "name", workbenchYAMLScalarNode(plan.ComposeProject+"_"+name))
+			}
+		}
+	}
+
+	networksNode, hasNetworks := workbenchYAMLFindMapValue(root, "networks")
+	if !hasNetworks || networksNode.Kind != yaml.MappingNode {
+		networksNode = workbenchYAMLMappingNode()
+	}
+	defaultDeclared := false
+	for idx := 0; idx+1 < len(networksNode.Content); idx += 2 {
+		name := networksNode.Content[idx].Value
+		if name == "default" {
+			defaultDeclared = true
+		}
+		networkNode := networksNode.Content[idx+1]
+		if networkNode.Kind == yaml.MappingNode && workbenchBlueGreenIsExternal(networkNode) {
+			continue
+		}
+		networksNode.Content[idx+1] = workbenchBlueGreenExternalNetworkNode(networkNode, plan.ComposeProject+"_"+name)
+	}
+	if usesDefaultNetwork && !defaultDeclared {
+		workbenchYAMLAddMapEntry(networksNode, "default", workbenchBlueGreenExternalNetworkNode(nil, plan.ComposeProject+"_default"))
+	}
+	if len(networksNode.Content) > 0 {
+		workbenchYAMLSetMapEntry(root, "networks", networksNode)
+	}
+
+	return encodeWorkbenchComposeYAML(root)
+}
+
+func workbenchBlueGreenPruneDependsOn(serviceNode *yaml.Node, pinned map[string]struct{}) {
+	dependsOn, ok := workbenchYAMLFindMapValue(serviceNode, "depends_on")
+	if !ok {
+		return
+	}
+	switch dependsOn.Kind {
+	case yaml.SequenceNode:
+		kept := make([]*yaml.Node, 0, len(dependsOn.Content))
+		for _, item := range dependsOn.Content {
+			if _, drop := pinned[item.Value]; !drop {
+				kept = append(kept, item)
+			}
+		}
+		dependsOn.Content = kept
+	case yaml.MappingNode:
+		kept := make([]*yaml.Node, 0, len(dependsOn.Content))
+		for idx := 0; idx+1 < len(dependsOn.Content); idx += 2 {
+			if _, drop := pinned[dependsOn.Content[idx].Value]; !drop {
+				kept = append(kept, dependsOn.Content[idx], dependsOn.Content[idx+1])
+			}
+		}
+		dependsOn.Content = kept
+	}
+	if len(dependsOn.Content) == 0 {
+		workbenchYAMLDeleteMapEntry(serviceNode, "depends_on")
+	}
+}
+
+func workbenchBlueGreenIsExternal(node *yaml.Node) bool {
+	external, ok := workbenchYAMLFindMapValue(node, "external")
+	return ok && strings.EqualFold(strings.TrimSpace(external.Value), "true")
+}
+
+func workbenchBlueGreenExternalNetworkNode(current *yaml.Node, defaultName string) *yaml.Node {
+	name := defaultName
+	if current != nil && current.Kind == yaml.MappingNode {
+		if named, ok := workbenchYAMLFindMapValue(current, "name"); ok && strings.TrimSpace(named.Value) != "" {
+			name = strings.TrimSpace(named.Value)
+		}
+	}
+	node := workbenchYAMLMappingNode()
+	workbenchYAMLAddMapEntry(node, "name", workbenchYAMLScalarNode(name))
+	workbenchYAMLAddMapEntry(node, "external", workbenchYAMLBoolNode(true))
+	return node
+}
+
+// workbenchComposeProjectName mirrors how docker compose names a project run
+// from projectDir: the top-level name when set, otherwise the directory name
+// lowercased with unsupported characters dropped.
+func workbenchComposeProjectName(root *yaml.Node, projectDir string) string {
+	if nameNode, ok := workbenchYAMLFindMapValue(root, "name"); ok {
+		if name := strings.TrimSpace(nameNode.Value); name != "" && !strings.Contains(name, "${") {
+			return name
+		}
+	}
+	var builder strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(projectDir)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			builder.WriteRune(r)
+		}
+	}
+	return strings.TrimLeft(builder.String(), "-_")
+}
+
+func workbenchBlueGreenPlanError(snapshot WorkbenchStackSnapshot, issues []WorkbenchMutationIssue) error {
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchValidationFailed, "project cannot be deployed blue/green"),
+		map[string]any{
+			"project":    strings.TrimSpace(snapshot.ProjectName),
+			"revision":   snapshot.Revision,
+			"issueCount": len(issues),
+			"issues":     issues,
+		},
+	)
+}
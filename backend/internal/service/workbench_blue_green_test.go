@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+)
+
+const blueGreenTestCompose = `services:
+  web:
+    build: ./web
+    container_name: demo-web
+    ports:
+      - "127.0.0.1:18080:80"
+      - "19090:9090"
+    depends_on:
+      db:
+        condition: service_healthy
+      worker:
+        condition: service_started
+    volumes:
+      - uploads:/srv/uploads
+  worker:
+    image: ghcr.io/acme/worker:1.0
+    networks:
+      - backend
+    depends_on:
+      - db
+  db:
+    image: postgres:16
+    ports:
+      - "15432:5432"
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+    networks:
+      - backend
+      - default
+volumes:
+  uploads:
+  pgdata:
+networks:
+  backend:
+    driver: bridge
+`
+
+func newBlueGreenTestWorkbench(t *testing.T, proxyPort int) (*WorkbenchService, *stubProjectRepository, string) {
+	t.Helper()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(blueGreenTestCompose), 0o644))
+
+	repo := &stubProjectRepository{projects: []models.Project{{Name: "demo", Path: projectDir, ProxyPort: proxyPort}}}
+	svc := NewWorkbenchServiceWithStorage(templatesDir, repo, &fakeSettingsRepo{}, "test-session-secret")
+	svc.hostPortScanner = func(context.Context) (map[int]struct{}, error) {
+		return map[int]struct{}{18080: {}, 19090: {}, 15432: {}}, nil
+	}
+	_, _, err := svc.ImportComposeSnapshot(context.Background(), "demo", "manual")
+	require.NoError(t, err)
+	return svc, repo, projectDir
+}
+
+func TestWorkbenchWriteBlueGreenSlotComposePinsDataServices(t *testing.T) {
+	t.Parallel()
+
+	svc, _, projectDir := newBlueGreenTestWorkbench(t, 18080)
+
+	slot, err := svc.WriteBlueGreenSlotCompose(context.Background(), "demo", WorkbenchBlueGreenSlotGreen, 18081)
+	require.NoError(t, err)
+	require.Equal(t, "demo", slot.ComposeProject)
+	require.Equal(t, "demo-green", slot.SlotProject)
+	require.Equal(t, "docker-compose.green.yml", slot.ComposeFile)
+	require.Equal(t, "web", slot.ProxyService)
+	require.Equal(t, []string{"web", "worker"}, slot.Services)
+	require.Equal(t, []string{"db"}, slot.Pinned)
+
+	raw, err := os.ReadFile(filepath.Join(projectDir, slot.ComposeFile))
+	require.NoError(t, err)
+
+	var rendered struct {
+		Services map[string]struct {
+			ContainerName string         `yaml:"container_name"`
+			Ports         []string       `yaml:"ports"`
+			DependsOn     map[string]any `yaml:"depends_on"`
+		} `yaml:"services"`
+		Volumes  map[string]map[string]any `yaml:"volumes"`
+		Networks map[string]map[string]any `yaml:"networks"`
+	}
+	require.NoError(t, yaml.Unmarshal(raw, &rendered))
+	require.NotContains(t, rendered.Services, "db")
+	require.Empty(t, rendered.Services["web"].ContainerName)
+	require.Equal(t, []string{"127.0.0.1:18081:80"}, rendered.Services["web"].Ports)
+	require.Equal(t, map[string]any{"worker": map[string]any{"condition": "service_started"}}, rendered.Services["web"].DependsOn)
+	require.Empty(t, rendered.Services["worker"].DependsOn)
+	require.Equal(t, "demo_uploads", rendered.Volumes["uploads"]["name"])
+	require.Equal(t, map[string]any{"name": "demo_backend", "external": true}, rendered.Networks["backend"])
+	require.Equal(t, map[string]any{"name": "demo_default", "external": true}, rendered.Networks["default"])
+
+	// The project compose file itself is untouched.
+	original, err := os.ReadFile(filepath.Join(projectDir, "docker-compose.yml"))
+	require.NoError(t, err)
+	require.Equal(t, blueGreenTestCompose, string(original))
+}
+
+func TestWorkbenchPlanBlueGreenDeployRejectsUnknownOrStatefulProxy(t *testing.T) {
+	t.Parallel()
+
+	for name, proxyPort := range map[string]int{
+		"WB-BLUEGREEN-PROXY-UNKNOWN":  18999,
+		"WB-BLUEGREEN-PROXY-STATEFUL": 15432,
+	} {
+		svc, _, _ := newBlueGreenTestWorkbench(t, proxyPort)
+		_, err := svc.PlanBlueGreenDeploy(context.Background(), "demo")
+		require.Error(t, err, name)
+		typed, ok := errs.From(err)
+		require.True(t, ok, name)
+		require.Equal(t, errs.CodeWorkbenchValidationFailed, typed.Code, name)
+		details, ok := typed.Details.(map[string]any)
+		require.True(t, ok, name)
+		issues, ok := details["issues"].([]WorkbenchMutationIssue)
+		require.True(t, ok, name)
+		require.Len(t, issues, 1, name)
+		require.Equal(t, name, issues[0].Code)
+	}
+}
+
+func TestWorkbenchComposeProjectName(t *testing.T) {
+	t.Parallel()
+
+	var document yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("services: {}\n"), &document))
+	require.Equal(t, "myapp-2", workbenchComposeProjectName(workbenchDocumentRoot(&document), "/srv/templates/My.App-2"))
+
+	var named yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("name: shop\nservices: {}\n"), &named))
+	require.Equal(t, "shop", workbenchComposeProjectName(workbenchDocumentRoot(&named), "/srv/templates/demo"))
+}
@@ -0,0 +1,533 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/infra/contract"
+	"go-notes/internal/models"
+)
+
+const (
+	workbenchBundleFormat  = "gungnr.workbench.bundle"
+	workbenchBundleVersion = 1
+
+	workbenchBundleProjectStatus = "imported"
+)
+
+// workbenchEnvSecretKeyPattern matches env keys whose values are blanked in
+// the exported template. Matching is on underscore-separated words so that
+// e.g. MONKEY_COUNT is kept while API_KEY is not.
+var workbenchEnvSecretKeyPattern = regexp.MustCompile(`(^|_)(PASSWORD|PASSWD|PASS|PWD|SECRET|SECRETS|TOKEN|KEY|PRIVATE|CREDENTIAL|CREDENTIALS|AUTH|DSN|SALT|CERT)(_|$)`)
+
+// workbenchEnvURLCredentialPattern matches URL values carrying userinfo, such
+// as the connection strings optional services write into .env.
+var workbenchEnvURLCredentialPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://[^/@\s]+:[^/@\s]*@`)
+
+// WorkbenchBundle is a portable copy of a configured stack. Host-specific
+// snapshot fields (paths, fingerprint, revision) are cleared on export and
+// filled in again on import.
+type WorkbenchBundle struct {
+	Format           string                          `json:"format"`
+	Version          int                             `json:"version"`
+	ExportedAt       time.Time                       `json:"exportedAt"`
+	SourceProject    string                          `json:"sourceProject"`
+	ComposeFile      string                          `json:"composeFile"`
+	Compose          string                          `json:"compose"`
+	Snapshot         WorkbenchStackSnapshot          `json:"snapshot"`
+	EnvTemplate      string                          `json:"envTemplate,omitempty"`
+	RedactedEnvKeys  []string                        `json:"redactedEnvKeys"`
+	OptionalServices []WorkbenchManagedService       `json:"optionalServices"`
+	PortAssignments  []WorkbenchBundlePortAssignment `json:"portAssignments"`
+}
+
+type WorkbenchBundlePortAssignment struct {
+	ServiceName        string `json:"serviceName"`
+	ContainerPort      int    `json:"containerPort"`
+	Protocol           string `json:"protocol"`
+	HostIP             string `json:"hostIp,omitempty"`
+	HostPort           *int   `json:"hostPort,omitempty"`
+	AssignmentStrategy string `json:"assignmentStrategy,omitempty"`
+}
+
+type WorkbenchBundleImportRequest struct {
+	Bundle WorkbenchBundle `json:"bundle"`
+	// KeepManualPorts keeps manually pinned host ports pinned. By default they
+	// become auto ports preferring the same number, so clones on one host do
+	// not collide.
+	KeepManualPorts bool `json:"keepManualPorts,omitempty"`
+}
+
+type WorkbenchBundleImportResult struct {
+	Project        string                         `json:"project"`
+	ProjectDir     string                         `json:"projectDir"`
+	SourceProject  string                         `json:"sourceProject"`
+	Stack          WorkbenchStackSnapshot         `json:"stack"`
+	PortResolution WorkbenchPortResolutionSummary `json:"portResolution"`
+	PortIssues     []WorkbenchPortResolutionIssue `json:"portIssues,omitempty"`
+	Applied        bool                           `json:"applied"`
+	ApplyErrorCode string                         `json:"applyErrorCode,omitempty"`
+	EnvKeysToFill  []string                       `json:"envKeysToFill"`
+	Notes          []string                       `json:"notes,omitempty"`
+}
+
+// ExportBundle packages a project's compose file, stored snapshot, and a
+// .env template with secret values blanked.
+func (s *WorkbenchService) ExportBundle(ctx context.Context, projectName string) (WorkbenchBundle, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchBundle{}, err
+	}
+
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBundle{}, err
+	}
+	defer release()
+
+	snapshot, err := s.loadStoredSnapshotForComposeLocked(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBundle{}, err
+	}
+	source, err := s.ResolveComposeSource(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBundle{}, err
+	}
+	if strings.TrimSpace(source.Fingerprint) != strings.TrimSpace(snapshot.SourceFingerprint) {
+		return WorkbenchBundle{}, errs.WithDetails(
+			errs.New(errs.CodeWorkbenchDriftDetected, "compose file changed since the last import; import or apply before exporting"),
+			map[string]any{
+				"project":            normalizedProject,
+				"sourceFingerprint":  strings.TrimSpace(snapshot.SourceFingerprint),
+				"currentFingerprint": strings.TrimSpace(source.Fingerprint),
+			},
+		)
+	}
+
+	envTemplate, redacted, err := s.readWorkbenchEnvTemplate(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchBundle{}, err
+	}
+
+	portable := normalizeWorkbenchStackSnapshot(snapshot)
+	portable.ProjectName = ""
+	portable.ProjectDir = ""
+	portable.ComposePath = ""
+	portable.SourceFingerprint = ""
+	portable.Revision = 0
+
+	assignments := make([]WorkbenchBundlePortAssignment, 0, len(portable.Ports))
+	for _, port := range portable.Ports {
+		assignments = append(assignments, WorkbenchBundlePortAssignment{
+			ServiceName:        port.ServiceName,
+			ContainerPort:      port.ContainerPort,
+			Protocol:           port.Protocol,
+			HostIP:             port.HostIP,
+			HostPort:           cloneWorkbenchPortInt(port.HostPort),
+			AssignmentStrategy: port.AssignmentStrategy,
+		})
+	}
+
+	return WorkbenchBundle{
+		Format:           workbenchBundleFormat,
+		Version:          workbenchBundleVersion,
+		ExportedAt:       s.now(),
+		SourceProject:    normalizedProject,
+		ComposeFile:      filepath.Base(source.ComposePath),
+		Compose:          source.Normalized,
+		Snapshot:         portable,
+		EnvTemplate:      envTemplate,
+		RedactedEnvKeys:  redacted,
+		OptionalServices: append([]WorkbenchManagedService{}, portable.ManagedServices...),
+		PortAssignments:  assignments,
+	}, nil
+}
+
+// ImportBundle creates a new project from a bundle: it writes the compose
+// file and .env template, registers the project, stores the bundle snapshot
+// on top of a fresh import, re-resolves host ports around ports already in use
+// on this host, and applies the result. If the project cannot be written or
+// its snapshot stored, the files, record and snapshot are removed again so
+// the import can be retried. Port or apply problems are reported on the
+// result; the project is left in place so they can be fixed there.
+func (s *WorkbenchService) ImportBundle(
+	ctx context.Context,
+	projectName string,
+	input WorkbenchBundleImportRequest,
+) (WorkbenchBundleImportResult, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchBundleImportResult{}, err
+	}
+	bundle := input.Bundle
+	if issues := validateWorkbenchBundle(bundle); len(issues) > 0 {
+		return WorkbenchBundleImportResult{}, workbenchBundleValidationError(normalizedProject, issues)
+	}
+
+	projectDir, err := s.createWorkbenchBundleProject(ctx, normalizedProject, bundle)
+	if err != nil {
+		return WorkbenchBundleImportResult{}, err
+	}
+
+	imported, _, err := s.ImportComposeSnapshot(ctx, normalizedProject, workbenchImportReasonBundle)
+	if err != nil {
+		s.discardWorkbenchBundleProject(ctx, normalizedProject, projectDir, bundle)
+		return WorkbenchBundleImportResult{}, err
+	}
+	stack, err := s.overlayWorkbenchBundleSnapshot(ctx, normalizedProject, imported, bundle.Snapshot, input.KeepManualPorts)
+	if err != nil {
+		s.discardWorkbenchBundleProject(ctx, normalizedProject, projectDir, bundle)
+		return WorkbenchBundleImportResult{}, err
+	}
+
+	result := WorkbenchBundleImportResult{
+		Project:       normalizedProject,
+		ProjectDir:    projectDir,
+		SourceProject: strings.TrimSpace(bundle.SourceProject),
+		Stack:         stack,
+		EnvKeysToFill: append([]string{}, bundle.RedactedEnvKeys...),
+	}
+
+	var occupied map[int]struct{}
+	if s.hostPortScanner != nil {
+		if scanned, scanErr := s.hostPortScanner(ctx); scanErr == nil {
+			occupied = scanned
+		} else {
+			result.Notes = append(result.Notes, "Host port scan failed; ports were resolved against the bundle only.")
+		}
+	}
+	resolved, summary, err := s.resolveStoredSnapshotPorts(ctx, normalizedProject, occupied)
+	result.PortResolution = summary
+	if err != nil {
+		typed, ok := errs.From(err)
+		if !ok || typed.Code != errs.CodeWorkbenchValidationFailed {
+			return result, err
+		}
+		details, _ := typed.Details.(map[string]any)
+		result.PortIssues, _ = details["issues"].([]WorkbenchPortResolutionIssue)
+		result.Notes = append(result.Notes, "Host ports could not be resolved; fix the reported ports, resolve again, then apply.")
+		return result, nil
+	}
+	result.Stack = resolved
+
+	expectedRevision := resolved.Revision
+	applied, err := s.ApplyComposeFromStoredSnapshot(ctx, normalizedProject, WorkbenchComposeApplyRequest{
+		ExpectedRevision:          &expectedRevision,
+		ExpectedSourceFingerprint: resolved.SourceFingerprint,
+	})
+	if err != nil {
+		typed, ok := errs.From(err)
+		if !ok {
+			return result, err
+		}
+		result.ApplyErrorCode = string(typed.Code)
+		result.Notes = append(result.Notes, fmt.Sprintf("Compose apply failed (%s): %s. Preview the stack and apply again.", typed.Code, typed.Message))
+		return result, nil
+	}
+	result.Applied = true
+	result.Stack.SourceFingerprint = applied.Metadata.SourceFingerprint
+	if len(result.EnvKeysToFill) > 0 {
+		result.Notes = append(result.Notes, "Secret values were not exported; fill the listed .env keys before deploying.")
+	}
+	return result, nil
+}
+
+func (s *WorkbenchService) createWorkbenchBundleProject(
+	ctx context.Context,
+	projectName string,
+	bundle WorkbenchBundle,
+) (string, error) {
+	release, err := s.AcquireProjectLock(ctx, projectName)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	if strings.TrimSpace(s.templatesDir) == "" {
+		return "", errs.New(errs.CodeWorkbenchStorageFailed, "TEMPLATES_DIR not configured")
+	}
+	existsErr := errs.WithDetails(
+		errs.New(errs.CodeWorkbenchProjectExists, fmt.Sprintf("project %q already exists", projectName)),
+		map[string]any{"project": projectName},
+	)
+	record, err := lookupProjectRecord(ctx, s.projects, projectName)
+	if err != nil {
+		return "", err
+	}
+	if record != nil {
+		return "", existsErr
+	}
+	projectDir := filepath.Join(s.templatesDir, projectName)
+	if _, err := os.Stat(projectDir); err == nil {
+		return "", existsErr
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", workbenchStorageError(projectName, "failed to inspect project directory", err)
+	}
+	if _, exists, err := s.loadStoredWorkbenchSnapshot(ctx, projectName); err != nil {
+		return "", err
+	} else if exists {
+		return "", existsErr
+	}
+
+	if err := s.writeWorkbenchBundleFile(ctx, filepath.Join(projectDir, workbenchBundleComposeFile(bundle)), bundle.Compose, 0o644); err != nil {
+		s.discardWorkbenchBundleProjectLocked(ctx, projectName, projectDir, bundle)
+		return "", workbenchStorageError(projectName, "failed to write bundle compose file", err)
+	}
+	if strings.TrimSpace(bundle.EnvTemplate) != "" {
+		if err := s.writeWorkbenchBundleFile(ctx, filepath.Join(projectDir, ".env"), bundle.EnvTemplate, 0o600); err != nil {
+			s.discardWorkbenchBundleProjectLocked(ctx, projectName, projectDir, bundle)
+			return "", workbenchStorageError(projectName, "failed to write bundle .env template", err)
+		}
+	}
+
+	if s.projects != nil {
+		if err := s.projects.Create(ctx, &models.Project{
+			Name:   projectName,
+			Path:   projectDir,
+			Status: workbenchBundleProjectStatus,
+		}); err != nil {
+			s.discardWorkbenchBundleProjectLocked(ctx, projectName, projectDir, bundle)
+			return "", workbenchStorageError(projectName, "failed to register imported project", err)
+		}
+	}
+	return projectDir, nil
+}
+
+func workbenchBundleComposeFile(bundle WorkbenchBundle) string {
+	if composeFile := strings.TrimSpace(bundle.ComposeFile); composeFile != "" {
+		return composeFile
+	}
+	return projectComposeFileCandidates[0]
+}
+
+// discardWorkbenchBundleProject undoes createWorkbenchBundleProject and the
+// snapshot import that follows it. Cleanup failures are logged; the caller
+// returns the error that triggered the cleanup.
+func (s *WorkbenchService) discardWorkbenchBundleProject(
+	ctx context.Context,
+	projectName string,
+	projectDir string,
+	bundle WorkbenchBundle,
+) {
+	release, err := s.AcquireProjectLock(ctx, projectName)
+	if err != nil {
+		log.Printf("warn: workbench bundle cleanup skipped for %q: %v", projectName, err)
+		return
+	}
+	defer release()
+	s.discardWorkbenchBundleProjectLocked(ctx, projectName, projectDir, bundle)
+}
+
+func (s *WorkbenchService) discardWorkbenchBundleProjectLocked(
+	ctx context.Context,
+	projectName string,
+	projectDir string,
+	bundle WorkbenchBundle,
+) {
+	if err := s.deleteWorkbenchSnapshot(ctx, projectName); err != nil {
+		log.Printf("warn: workbench bundle snapshot cleanup failed for %q: %v", projectName, err)
+	}
+	if s.projects != nil {
+		if record, err := lookupProjectRecord(ctx, s.projects, projectName); err != nil {
+			log.Printf("warn: workbench bundle project lookup failed for %q: %v", projectName, err)
+		} else if record != nil {
+			if err := s.projects.Delete(ctx, record.ID); err != nil {
+				log.Printf("warn: workbench bundle project record cleanup failed for %q: %v", projectName, err)
+			}
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(projectDir, ".env"),
+		filepath.Join(projectDir, workbenchBundleComposeFile(bundle)),
+		projectDir,
+	} {
+		if err := s.removeWorkbenchPath(ctx, s.templatesDir, path, true); err != nil {
+			log.Printf("warn: workbench bundle cleanup failed for %q: %v", path, err)
+		}
+	}
+}
+
+// overlayWorkbenchBundleSnapshot replaces the freshly imported snapshot with
+// the bundle's content while keeping this host's paths and fingerprint.
+func (s *WorkbenchService) overlayWorkbenchBundleSnapshot(
+	ctx context.Context,
+	projectName string,
+	imported WorkbenchStackSnapshot,
+	bundled WorkbenchStackSnapshot,
+	keepManualPorts bool,
+) (WorkbenchStackSnapshot, error) {
+	release, err := s.AcquireProjectLock(ctx, projectName)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	defer release()
+
+	next := normalizeWorkbenchStackSnapshot(bundled)
+	next.ProjectName = imported.ProjectName
+	next.ProjectDir = imported.ProjectDir
+	next.ComposePath = imported.ComposePath
+	next.SourceFingerprint = imported.SourceFingerprint
+	next.Revision = imported.Revision + 1
+	next.Ports = append([]WorkbenchComposePort{}, next.Ports...)
+	for idx := range next.Ports {
+		next.Ports[idx].AllocationStatus = ""
+		if !keepManualPorts {
+			next.Ports[idx].AssignmentStrategy = workbenchPortStrategyAuto
+		}
+	}
+	next = normalizeWorkbenchStackSnapshot(next)
+
+	if err := s.saveWorkbenchSnapshotRevision(ctx, projectName, imported, next, WorkbenchMutationBundleImport, ""); err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	return next, nil
+}
+
+func (s *WorkbenchService) writeWorkbenchBundleFile(ctx context.Context, path, content string, mode os.FileMode) error {
+	if s.fileClient != nil {
+		_, err := s.fileClient.ProjectFileWriteAtomic(ctx, "", contract.ProjectFileWriteAtomicPayload{
+			BasePath:      s.templatesDir,
+			Path:          path,
+			Content:       content,
+			Mode:          uint32(mode),
+			CreateParents: true,
+		})
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), mode)
+}
+
+func (s *WorkbenchService) readWorkbenchEnvTemplate(ctx context.Context, projectName string) (string, []string, error) {
+	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
+	if err != nil {
+		return "", nil, err
+	}
+	redacted := []string{}
+	if !resolved.EnvExists {
+		return "", redacted, nil
+	}
+	content, err := os.ReadFile(resolved.EnvPath)
+	if err != nil {
+		return "", nil, workbenchStorageError(projectName, "failed to read project .env", err)
+	}
+	template, redacted := workbenchEnvTemplate(string(content))
+	return template, redacted, nil
+}
+
+// workbenchEnvTemplate keeps comments, ordering, and non-secret values, and
+// blanks the value of every key that looks like a secret.
+func workbenchEnvTemplate(content string) (string, []string) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	redacted := []string{}
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		separator := strings.Index(line, "=")
+		if separator < 0 {
+			continue
+		}
+		key := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[:separator]), "export "))
+		value := strings.Trim(strings.TrimSpace(line[separator+1:]), `"'`)
+		if !workbenchEnvSecretKeyPattern.MatchString(strings.ToUpper(key)) && !workbenchEnvURLCredentialPattern.MatchString(value) {
+			continue
+		}
+		lines[idx] = line[:separator+1]
+		redacted = append(redacted, key)
+	}
+	sort.Strings(redacted)
+	return strings.Join(lines, "\n"), redacted
+}
+
+func validateWorkbenchBundle(bundle WorkbenchBundle) []WorkbenchMutationIssue {
+	issues := []WorkbenchMutationIssue{}
+	if strings.TrimSpace(bundle.Format) != workbenchBundleFormat {
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:   workbenchMutationIssueClassSchema,
+			Code:    "WB-BUNDLE-FORMAT",
+			Path:    "$.bundle.format",
+			Message: fmt.Sprintf("bundle format must be %q", workbenchBundleFormat),
+		})
+	}
+	if bundle.Version < 1 || bundle.Version > workbenchBundleVersion {
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:   workbenchMutationIssueClassSchema,
+			Code:    "WB-BUNDLE-VERSION",
+			Path:    "$.bundle.version",
+			Message: fmt.Sprintf("bundle version %d is not supported; expected 1 to %d", bundle.Version, workbenchBundleVersion),
+		})
+	}
+	if composeFile := strings.TrimSpace(bundle.ComposeFile); composeFile != "" {
+		known := false
+		for _, candidate := range projectComposeFileCandidates {
+			if composeFile == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			issues = append(issues, WorkbenchMutationIssue{
+				Class:   workbenchMutationIssueClassValue,
+				Code:    "WB-BUNDLE-COMPOSE-FILE",
+				Path:    "$.bundle.composeFile",
+				Message: fmt.Sprintf("compose file name %q is not one of %s", composeFile, strings.Join(projectComposeFileCandidates, ", ")),
+			})
+		}
+	}
+	if strings.TrimSpace(bundle.Compose) == "" {
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:   workbenchMutationIssueClassSchema,
+			Code:    "WB-BUNDLE-COMPOSE-REQUIRED",
+			Path:    "$.bundle.compose",
+			Message: "bundle compose content is required",
+		})
+	} else if _, err := ParseWorkbenchComposeCore(bundle.Compose); err != nil {
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:   workbenchMutationIssueClassValue,
+			Code:    "WB-BUNDLE-COMPOSE-INVALID",
+			Path:    "$.bundle.compose",
+			Message: fmt.Sprintf("bundle compose content does not parse: %v", err),
+		})
+	}
+	for idx, managedService := range bundle.Snapshot.ManagedServices {
+		if _, ok := workbenchOptionalServiceDefinitionByKey(managedService.EntryKey); ok {
+			continue
+		}
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:    workbenchMutationIssueClassConflict,
+			Code:     "WB-BUNDLE-OPTIONAL-SERVICE-UNKNOWN",
+			Path:     fmt.Sprintf("$.bundle.snapshot.managedServices[%d].entryKey", idx),
+			Message:  fmt.Sprintf("optional service %q is not in this host's catalog", managedService.EntryKey),
+			EntryKey: managedService.EntryKey,
+			Service:  managedService.ServiceName,
+		})
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return workbenchMutationIssueLess(issues[i], issues[j])
+	})
+	return issues
+}
+
+func workbenchBundleValidationError(projectName string, issues []WorkbenchMutationIssue) error {
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchBundleInvalid, "invalid workbench bundle"),
+		map[string]any{
+			"project":    projectName,
+			"issueCount": len(issues),
+			"issues":     issues,
+		},
+	)
+}
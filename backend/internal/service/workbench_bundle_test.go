@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-notes/internal/errs"
+)
+
+func TestWorkbenchBundleRoundTripReResolvesPorts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	source := "services:\n  api:\n    image: nginx:1.25\n    ports:\n      - \"18080:80\"\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+	env := "# api settings\nAPP_NAME=demo\nAPI_TOKEN=abc123\nDATABASE_URL=postgres://app:hunter2@db:5432/app\nMONKEY_COUNT=3\n"
+	if err := os.WriteFile(filepath.Join(projectDir, ".env"), []byte(env), 0o600); err != nil {
+		t.Fatalf("write env: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	svc.hostPortScanner = func(context.Context) (map[int]struct{}, error) {
+		return map[int]struct{}{18080: {}}, nil
+	}
+	if _, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual"); err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+
+	bundle, err := svc.ExportBundle(ctx, "demo")
+	if err != nil {
+		t.Fatalf("export bundle: %v", err)
+	}
+	if bundle.Snapshot.ProjectDir != "" || bundle.Snapshot.SourceFingerprint != "" || bundle.Snapshot.Revision != 0 {
+		t.Fatalf("expected host-specific snapshot fields cleared, got %#v", bundle.Snapshot)
+	}
+	if strings.Contains(bundle.EnvTemplate, "abc123") || strings.Contains(bundle.EnvTemplate, "hunter2") {
+		t.Fatalf("expected secret values blanked, got:\n%s", bundle.EnvTemplate)
+	}
+	if !strings.Contains(bundle.EnvTemplate, "# api settings\nAPP_NAME=demo\nAPI_TOKEN=\nDATABASE_URL=\nMONKEY_COUNT=3") {
+		t.Fatalf("expected env template to keep layout and non-secret values, got:\n%s", bundle.EnvTemplate)
+	}
+	if strings.Join(bundle.RedactedEnvKeys, ",") != "API_TOKEN,DATABASE_URL" {
+		t.Fatalf("unexpected redacted keys %v", bundle.RedactedEnvKeys)
+	}
+
+	// The bundle travels as JSON between hosts.
+	encoded, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("marshal bundle: %v", err)
+	}
+	decoded := WorkbenchBundle{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unmarshal bundle: %v", err)
+	}
+
+	result, err := svc.ImportBundle(ctx, "demo-tester", WorkbenchBundleImportRequest{Bundle: decoded})
+	if err != nil {
+		t.Fatalf("import bundle: %v", err)
+	}
+	if !result.Applied || len(result.PortIssues) != 0 {
+		t.Fatalf("expected applied import without port issues, got %#v", result)
+	}
+	if len(result.Stack.Ports) != 1 || result.Stack.Ports[0].HostPort == nil || *result.Stack.Ports[0].HostPort == 18080 {
+		t.Fatalf("expected occupied port 18080 to be re-resolved, got %#v", result.Stack.Ports)
+	}
+	if result.Stack.ProjectName != "demo-tester" {
+		t.Fatalf("expected stack for demo-tester, got %q", result.Stack.ProjectName)
+	}
+
+	written, err := os.ReadFile(filepath.Join(templatesDir, "demo-tester", "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("read imported compose: %v", err)
+	}
+	if strings.Contains(string(written), "18080:80") {
+		t.Fatalf("expected imported compose to use the re-resolved port, got:\n%s", written)
+	}
+	envInfo, err := os.Stat(filepath.Join(templatesDir, "demo-tester", ".env"))
+	if err != nil {
+		t.Fatalf("stat imported env: %v", err)
+	}
+	if envInfo.Mode().Perm() != 0o600 {
+		t.Fatalf("expected imported .env mode 0600, got %v", envInfo.Mode().Perm())
+	}
+}
+
+func TestWorkbenchBundleImportRejectsExistingProject(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	source := "services:\n  api:\n    image: nginx:1.25\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	svc.hostPortScanner = func(context.Context) (map[int]struct{}, error) {
+		return map[int]struct{}{}, nil
+	}
+	if _, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual"); err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+	bundle, err := svc.ExportBundle(ctx, "demo")
+	if err != nil {
+		t.Fatalf("export bundle: %v", err)
+	}
+
+	_, err = svc.ImportBundle(ctx, "demo", WorkbenchBundleImportRequest{Bundle: bundle})
+	typed, ok := errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchProjectExists {
+		t.Fatalf("expected %q, got %v", errs.CodeWorkbenchProjectExists, err)
+	}
+
+	bundle.Format = "something-else"
+	bundle.ComposeFile = "../escape.yml"
+	_, err = svc.ImportBundle(ctx, "fresh", WorkbenchBundleImportRequest{Bundle: bundle})
+	typed, ok = errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchBundleInvalid {
+		t.Fatalf("expected %q, got %v", errs.CodeWorkbenchBundleInvalid, err)
+	}
+	if _, statErr := os.Stat(filepath.Join(templatesDir, "fresh")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no project directory for rejected bundle, got %v", statErr)
+	}
+}
+
+func TestWorkbenchBundleImportRemovesProjectWhenSnapshotFails(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	source := "services:\n  api:\n    image: nginx:1.25\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".env"), []byte("APP_NAME=demo\n"), 0o600); err != nil {
+		t.Fatalf("write env: %v", err)
+	}
+
+	settings := &fakeSettingsRepo{}
+	projects := &stubProjectRepository{}
+	svc := NewWorkbenchServiceWithStorage(templatesDir, projects, settings, "test-session-secret")
+	svc.hostPortScanner = func(context.Context) (map[int]struct{}, error) {
+		return map[int]struct{}{}, nil
+	}
+	if _, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual"); err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+	bundle, err := svc.ExportBundle(ctx, "demo")
+	if err != nil {
+		t.Fatalf("export bundle: %v", err)
+	}
+
+	settings.saveErr = errors.New("database is locked")
+	if _, err := svc.ImportBundle(ctx, "demo-copy", WorkbenchBundleImportRequest{Bundle: bundle}); err == nil {
+		t.Fatal("expected import to fail while snapshots cannot be stored")
+	}
+	if _, statErr := os.Stat(filepath.Join(templatesDir, "demo-copy")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected failed import to remove its project directory, got %v", statErr)
+	}
+	if len(projects.projects) != 0 {
+		t.Fatalf("expected failed import to remove its project record, got %#v", projects.projects)
+	}
+
+	settings.saveErr = nil
+	result, err := svc.ImportBundle(ctx, "demo-copy", WorkbenchBundleImportRequest{Bundle: bundle})
+	if err != nil {
+		t.Fatalf("retry import bundle: %v", err)
+	}
+	if !result.Applied {
+		t.Fatalf("expected retried import to apply, got %#v", result)
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// ImportClonedSnapshot imports the compose file of a freshly copied project,
+// re-resolves its host ports around the ports already in use on this host,
+// and applies the result. The source project is running, so its published
+// ports are occupied and the clone's auto ports move past them. It returns the
+// snapshot as imported, before port resolution, next to the applied one.
+func (s *WorkbenchService) ImportClonedSnapshot(
+	ctx context.Context,
+	projectName string,
+) (WorkbenchStackSnapshot, WorkbenchStackSnapshot, WorkbenchPortResolutionSummary, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchStackSnapshot{}, WorkbenchPortResolutionSummary{}, err
+	}
+	imported, _, err := s.ImportComposeSnapshot(ctx, normalizedProject, workbenchImportReasonClone)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchStackSnapshot{}, WorkbenchPortResolutionSummary{}, err
+	}
+
+	var occupied map[int]struct{}
+	if s.hostPortScanner != nil {
+		scanned, scanErr := s.hostPortScanner(ctx)
+		if scanErr != nil {
+			return imported, WorkbenchStackSnapshot{}, WorkbenchPortResolutionSummary{}, fmt.Errorf("scan host ports: %w", scanErr)
+		}
+		occupied = scanned
+	}
+	resolved, summary, err := s.resolveStoredSnapshotPorts(ctx, normalizedProject, occupied)
+	if err != nil {
+		return imported, resolved, summary, err
+	}
+
+	expectedRevision := resolved.Revision
+	applied, err := s.ApplyComposeFromStoredSnapshot(ctx, normalizedProject, WorkbenchComposeApplyRequest{
+		ExpectedRevision:          &expectedRevision,
+		ExpectedSourceFingerprint: resolved.SourceFingerprint,
+	})
+	if err != nil {
+		return imported, resolved, summary, err
+	}
+	resolved.SourceFingerprint = applied.Metadata.SourceFingerprint
+	return imported, resolved, summary, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/repository"
+)
+
+type WorkbenchDriftStatus struct {
+	Project            string    `json:"project"`
+	Revision           int       `json:"revision"`
+	SourceFingerprint  string    `json:"sourceFingerprint"`
+	CurrentFingerprint string    `json:"currentFingerprint,omitempty"`
+	Drifted            bool      `json:"drifted"`
+	BaseAvailable      bool      `json:"baseAvailable"`
+	Mergeable          bool      `json:"mergeable"`
+	MergedCount        int       `json:"mergedCount"`
+	ConflictCount      int       `json:"conflictCount"`
+	Error              string    `json:"error,omitempty"`
+	CheckedAt          time.Time `json:"checkedAt"`
+}
+
+type WorkbenchDriftScanReport struct {
+	ScannedAt    time.Time              `json:"scannedAt"`
+	DriftedCount int                    `json:"driftedCount"`
+	Projects     []WorkbenchDriftStatus `json:"projects"`
+}
+
+// CheckDrift compares one project's compose file against its stored
+// fingerprint and dry-runs the three-way merge apply would attempt.
+func (s *WorkbenchService) CheckDrift(
+	ctx context.Context,
+	projectName string,
+) (WorkbenchDriftStatus, *WorkbenchMergeReport, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchDriftStatus{}, nil, err
+	}
+
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchDriftStatus{}, nil, err
+	}
+	defer release()
+
+	snapshot, err := s.loadStoredSnapshotForComposeLocked(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchDriftStatus{}, nil, err
+	}
+	currentSource, err := s.ResolveComposeSource(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchDriftStatus{}, nil, err
+	}
+	_, report, err := s.threeWayMergeDrift(snapshot, currentSource)
+	if err != nil {
+		return WorkbenchDriftStatus{}, nil, err
+	}
+
+	status := WorkbenchDriftStatus{
+		Project:            normalizedProject,
+		Revision:           snapshot.Revision,
+		SourceFingerprint:  strings.TrimSpace(snapshot.SourceFingerprint),
+		CurrentFingerprint: strings.TrimSpace(currentSource.Fingerprint),
+		Drifted:            report != nil,
+		BaseAvailable:      true,
+		Mergeable:          true,
+		CheckedAt:          s.now(),
+	}
+	if report != nil {
+		status.BaseAvailable = report.BaseAvailable
+		status.Mergeable = report.Clean()
+		status.MergedCount = len(report.Merged)
+		status.ConflictCount = len(report.Conflicts)
+	}
+	return status, report, nil
+}
+
+// ScanDrift checks every project with a stored snapshot. Per-project failures
+// (missing compose, unreadable project dir) are recorded on the status rather
+// than aborting the scan. The result is kept for LastDriftScan.
+func (s *WorkbenchService) ScanDrift(ctx context.Context) (WorkbenchDriftScanReport, error) {
+	projects, err := s.listWorkbenchSnapshotProjects(ctx)
+	if err != nil {
+		return WorkbenchDriftScanReport{}, err
+	}
+
+	report := WorkbenchDriftScanReport{
+		ScannedAt: s.now(),
+		Projects:  make([]WorkbenchDriftStatus, 0, len(projects)),
+	}
+	for _, project := range projects {
+		if err := ctx.Err(); err != nil {
+			return WorkbenchDriftScanReport{}, err
+		}
+		status, _, err := s.CheckDrift(ctx, project)
+		if err != nil {
+			status = WorkbenchDriftStatus{
+				Project:   project,
+				Error:     err.Error(),
+				CheckedAt: s.now(),
+			}
+		}
+		if status.Drifted {
+			report.DriftedCount++
+		}
+		report.Projects = append(report.Projects, status)
+	}
+
+	s.driftMu.Lock()
+	s.driftScan = &report
+	s.driftMu.Unlock()
+	return report, nil
+}
+
+func (s *WorkbenchService) LastDriftScan() (WorkbenchDriftScanReport, bool) {
+	s.driftMu.Lock()
+	defer s.driftMu.Unlock()
+	if s.driftScan == nil {
+		return WorkbenchDriftScanReport{}, false
+	}
+	return *s.driftScan, true
+}
+
+// RunDriftScanner scans for drift on start and then every interval until ctx
+// is cancelled.
+func (s *WorkbenchService) RunDriftScanner(ctx context.Context, interval time.Duration) {
+	if s == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return
+		}
+		report, err := s.ScanDrift(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("warn: workbench drift scan failed: %v", err)
+		} else if report.DriftedCount > 0 {
+			drifted := make([]string, 0, report.DriftedCount)
+			for _, status := range report.Projects {
+				if status.Drifted {
+					drifted = append(drifted, status.Project)
+				}
+			}
+			log.Printf("workbench drift scan: %d project(s) drifted from stored compose: %s", report.DriftedCount, strings.Join(drifted, ", "))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WorkbenchService) listWorkbenchSnapshotProjects(ctx context.Context) ([]string, error) {
+	projects := []string{}
+	if s.snapshots != nil {
+		rows, err := s.snapshots.List(ctx)
+		if err != nil {
+			return nil, workbenchStorageError("", "failed to list workbench snapshots", err)
+		}
+		for _, row := range rows {
+			projects = append(projects, row.ProjectName)
+		}
+		sort.Strings(projects)
+		return projects, nil
+	}
+	if s.settings == nil {
+		return projects, nil
+	}
+
+	stored, err := s.settings.Get(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return projects, nil
+		}
+		return nil, workbenchStorageError("", "failed to load workbench settings payload", err)
+	}
+	if stored == nil {
+		return projects, nil
+	}
+	payload, err := loadSettingsEncryptedPayload(s.sessionSecret, stored.NetBirdConfigEncrypted)
+	if err != nil {
+		if isSettingsPayloadDecryptMismatch(err) {
+			return projects, nil
+		}
+		return nil, workbenchStorageError("", "failed to decode workbench settings payload", err)
+	}
+	for project := range payload.Workbench {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+func (s *WorkbenchService) now() time.Time {
+	if s.nowFn != nil {
+		return s.nowFn().UTC()
+	}
+	return time.Now().UTC()
+}
@@ -22,8 +22,10 @@ type workbenchComposeGenerationModel struct {
 	ports           map[string][]WorkbenchComposePort
 	resources       map[string]WorkbenchComposeResource
 	networkRefs     map[string][]string
+	networks        map[string]WorkbenchComposeNetwork
 	serviceExtras   map[string]workbenchComposeServiceExtras
 	topLevelNetwork []string
+	topLevelVolumes []string
 }
 
 type workbenchHostBinding struct {
@@ -37,11 +39,13 @@ const (
 	workbenchValidationClassSchema       = "schema"
 	workbenchValidationClassDependency   = "dependency"
 	workbenchValidationClassPortConflict = "port_conflict"
+	workbenchValidationClassNetwork      = "network"
 )
 
 type WorkbenchValidationIssue struct {
 	Class      string `json:"class"`
 	Code       string `json:"code"`
+	Severity   string `json:"severity,omitempty"`
 	Path       string `json:"path"`
 	Message    string `json:"message"`
 	Service    string `json:"service,omitempty"`
@@ -63,6 +67,8 @@ type WorkbenchComposePreviewMetadata struct {
 type WorkbenchComposePreviewResult struct {
 	Compose  string                          `json:"compose"`
 	Metadata WorkbenchComposePreviewMetadata `json:"metadata"`
+	Policy   WorkbenchPolicyReport           `json:"policy"`
+	Merge    *WorkbenchMergeReport           `json:"merge,omitempty"`
 }
 
 type WorkbenchComposeApplyRequest struct {
@@ -81,6 +87,7 @@ type WorkbenchComposeApplyResult struct {
 	ComposeBytes int                                 `json:"composeBytes"`
 	Backup       WorkbenchComposeBackupMetadata      `json:"backup"`
 	Retention    WorkbenchComposeBackupRetentionInfo `json:"retention"`
+	Merge        *WorkbenchMergeReport               `json:"merge,omitempty"`
 }
 
 func (s *WorkbenchService) GenerateComposeFromStoredSnapshot(
@@ -174,10 +181,21 @@ func (s *WorkbenchService) PreviewComposeFromStoredSnapshot(
 	if err != nil {
 		return WorkbenchComposePreviewResult{}, err
 	}
-	compose, err := mergeWorkbenchSnapshotIntoComposeSource(snapshot, currentSource)
+	merged, mergeReport, err := s.threeWayMergeDrift(snapshot, currentSource)
 	if err != nil {
 		return WorkbenchComposePreviewResult{}, err
 	}
+	if !mergeReport.Clean() {
+		merged = snapshot
+	}
+	compose, err := mergeWorkbenchSnapshotIntoComposeSource(merged, currentSource)
+	if err != nil {
+		return WorkbenchComposePreviewResult{}, err
+	}
+	policy, err := lintWorkbenchCompose(compose, merged.PolicyOverrides)
+	if err != nil {
+		return WorkbenchComposePreviewResult{}, workbenchComposeGenerateError(snapshot, "failed to lint compose output", err)
+	}
 
 	return WorkbenchComposePreviewResult{
 		Compose: compose,
@@ -185,6 +203,8 @@ func (s *WorkbenchService) PreviewComposeFromStoredSnapshot(
 			Revision:          snapshot.Revision,
 			SourceFingerprint: strings.TrimSpace(snapshot.SourceFingerprint),
 		},
+		Policy: policy,
+		Merge:  mergeReport,
 	}, nil
 }
 
@@ -227,11 +247,25 @@ func (s *WorkbenchService) ApplyComposeFromStoredSnapshot(
 	if err := workbenchApplyDriftCheck(snapshot, currentSource, normalizedInput.ExpectedSourceFingerprint); err != nil {
 		return WorkbenchComposeApplyResult{}, err
 	}
+	merged, mergeReport, err := s.threeWayMergeDrift(snapshot, currentSource)
+	if err != nil {
+		return WorkbenchComposeApplyResult{}, err
+	}
+	if mergeReport != nil && !mergeReport.Clean() {
+		return WorkbenchComposeApplyResult{}, workbenchDriftMergeError(snapshot, currentSource, mergeReport)
+	}
 
-	compose, err := mergeWorkbenchSnapshotIntoComposeSource(snapshot, currentSource)
+	compose, err := mergeWorkbenchSnapshotIntoComposeSource(merged, currentSource)
 	if err != nil {
 		return WorkbenchComposeApplyResult{}, err
 	}
+	policy, err := lintWorkbenchCompose(compose, merged.PolicyOverrides)
+	if err != nil {
+		return WorkbenchComposeApplyResult{}, workbenchComposeGenerateError(snapshot, "failed to lint compose output", err)
+	}
+	if policy.Blocking {
+		return WorkbenchComposeApplyResult{}, workbenchPolicyBlockedError(snapshot, policy)
+	}
 
 	backup, retention, err := s.createComposeBackup(ctx, normalizedProject, snapshot, currentSource)
 	if err != nil {
@@ -248,12 +282,20 @@ func (s *WorkbenchService) ApplyComposeFromStoredSnapshot(
 		)
 	}
 
-	updatedSnapshot := snapshot
+	updatedSnapshot := merged
 	updatedSnapshot.ProjectName = normalizedProject
 	updatedSnapshot.ProjectDir = currentSource.ProjectDir
 	updatedSnapshot.ComposePath = currentSource.ComposePath
 	updatedSnapshot.SourceFingerprint = appliedFingerprint
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, updatedSnapshot); err != nil {
+	var saveErr error
+	if mergeReport != nil {
+		updatedSnapshot.Revision = snapshot.Revision + 1
+		note := fmt.Sprintf("merged %d on-disk compose changes", len(mergeReport.Merged))
+		saveErr = s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, updatedSnapshot, WorkbenchMutationDriftMerge, note)
+	} else {
+		saveErr = s.saveWorkbenchSnapshotExpected(ctx, normalizedProject, updatedSnapshot, snapshot.Revision)
+	}
+	if err := saveErr; err != nil {
 		restoreErr := s.replaceWorkbenchComposeAtomically(ctx, currentSource.ProjectDir, currentSource.ComposePath, currentSource.Raw)
 		return WorkbenchComposeApplyResult{}, workbenchComposeApplyStorageError(
 			updatedSnapshot,
@@ -264,6 +306,8 @@ func (s *WorkbenchService) ApplyComposeFromStoredSnapshot(
 		)
 	}
 
+	s.storeWorkbenchComposeBase(ctx, currentSource.ProjectDir, []byte(normalizedCompose))
+
 	return WorkbenchComposeApplyResult{
 		Metadata: WorkbenchComposeApplyMetadata{
 			Revision:          updatedSnapshot.Revision,
@@ -273,6 +317,7 @@ func (s *WorkbenchService) ApplyComposeFromStoredSnapshot(
 		ComposeBytes: len(normalizedCompose),
 		Backup:       backup,
 		Retention:    retention,
+		Merge:        mergeReport,
 	}, nil
 }
 
@@ -281,9 +326,12 @@ type workbenchComposePatchModel struct {
 	services      map[string]WorkbenchComposeService
 	dependencies  map[string][]string
 	networkRefs   map[string][]string
+	networks      map[string]WorkbenchComposeNetwork
 	ports         map[string][]WorkbenchComposePort
 	resources     map[string]WorkbenchComposeResource
 	serviceExtras map[string]workbenchComposeServiceExtras
+	// topLevelNetworks lists every network a service references, declared or not.
+	topLevelNetworks []string
 }
 
 func mergeWorkbenchSnapshotIntoComposeSource(
@@ -351,9 +399,15 @@ func mergeWorkbenchSnapshotIntoComposeSource(
 		if extras.Managed {
 			workbenchPatchServiceCommand(serviceNode, extras.Command)
 			workbenchPatchServiceEnvironment(serviceNode, extras.Environment)
+			workbenchPatchServiceVolumes(serviceNode, extras.Volumes)
+			workbenchPatchServiceHealthcheck(serviceNode, extras.Healthcheck)
+		} else {
+			workbenchEnsureServiceEnvironmentEntries(serviceNode, extras.ConnectionEnvironment)
 		}
 	}
 	workbenchPruneRemovedManagedServiceNodes(servicesNode, model.services, model.snapshot)
+	workbenchEnsureTopLevelVolumes(root, workbenchManagedVolumeNames(model.serviceExtras))
+	workbenchPatchTopLevelNetworks(root, model.networks, model.topLevelNetworks, model.snapshot.ModelVersion >= workbenchModelVersion)
 
 	encoded, err := encodeWorkbenchComposeYAML(root)
 	if err != nil {
@@ -385,6 +439,7 @@ func buildWorkbenchComposePatchModel(snapshot WorkbenchStackSnapshot) (workbench
 		services:      make(map[string]WorkbenchComposeService, len(genModel.services)),
 		dependencies:  make(map[string][]string),
 		networkRefs:   make(map[string][]string),
+		networks:      make(map[string]WorkbenchComposeNetwork, len(genModel.networks)),
 		ports:         make(map[string][]WorkbenchComposePort),
 		resources:     make(map[string]WorkbenchComposeResource),
 		serviceExtras: make(map[string]workbenchComposeServiceExtras, len(genModel.serviceExtras)),
@@ -407,6 +462,10 @@ func buildWorkbenchComposePatchModel(snapshot WorkbenchStackSnapshot) (workbench
 	for serviceName, networks := range genModel.networkRefs {
 		model.networkRefs[serviceName] = append([]string(nil), networks...)
 	}
+	for networkName, network := range genModel.networks {
+		model.networks[networkName] = network
+	}
+	model.topLevelNetworks = append([]string(nil), genModel.topLevelNetwork...)
 	for serviceName, ports := range genModel.ports {
 		model.ports[serviceName] = append([]WorkbenchComposePort(nil), ports...)
 	}
@@ -434,9 +493,16 @@ func workbenchBuildComposePatchFallbackGenerationModel(snapshot WorkbenchStackSn
 		ports:         make(map[string][]WorkbenchComposePort),
 		resources:     make(map[string]WorkbenchComposeResource),
 		networkRefs:   make(map[string][]string),
+		networks:      make(map[string]WorkbenchComposeNetwork),
 		serviceExtras: make(map[string]workbenchComposeServiceExtras),
 	}
 
+	for _, network := range normalizedSnapshot.Networks {
+		if network.Name == "" {
+			continue
+		}
+		model.networks[network.Name] = network
+	}
 	for _, service := range normalizedSnapshot.Services {
 		name := strings.TrimSpace(service.ServiceName)
 		if name == "" {
@@ -450,17 +516,17 @@ func workbenchBuildComposePatchFallbackGenerationModel(snapshot WorkbenchStackSn
 		})
 	}
 
-	for _, managedService := range func() []workbenchManagedServiceModel {
-		models, _ := workbenchBuildManagedServiceModels(normalizedSnapshot)
-		return models
-	}() {
+	fallbackManagedModels, _ := workbenchBuildManagedServiceModels(normalizedSnapshot)
+	for _, managedService := range fallbackManagedModels {
 		name := strings.TrimSpace(managedService.ServiceName)
 		if name == "" {
 			continue
 		}
 		model.services = append(model.services, managedService.Service)
 		model.serviceExtras[name] = managedService.Extras
+		model.dependencies[name] = append(model.dependencies[name], managedService.DependsOn...)
 	}
+	workbenchApplyManagedServiceConnections(model.serviceExtras, fallbackManagedModels)
 
 	for _, dependency := range normalizedSnapshot.Dependencies {
 		serviceName := strings.TrimSpace(dependency.ServiceName)
@@ -470,6 +536,7 @@ func workbenchBuildComposePatchFallbackGenerationModel(snapshot WorkbenchStackSn
 		}
 		model.dependencies[serviceName] = append(model.dependencies[serviceName], dependsOn)
 	}
+	fallbackNetworks := make(map[string]struct{})
 	for _, networkRef := range normalizedSnapshot.NetworkRefs {
 		serviceName := strings.TrimSpace(networkRef.ServiceName)
 		networkName := strings.TrimSpace(networkRef.NetworkName)
@@ -477,7 +544,15 @@ func workbenchBuildComposePatchFallbackGenerationModel(snapshot WorkbenchStackSn
 			continue
 		}
 		model.networkRefs[serviceName] = append(model.networkRefs[serviceName], networkName)
+		fallbackNetworks[networkName] = struct{}{}
+	}
+	for networkName := range model.networks {
+		fallbackNetworks[networkName] = struct{}{}
 	}
+	for networkName := range fallbackNetworks {
+		model.topLevelNetwork = append(model.topLevelNetwork, networkName)
+	}
+	sort.Strings(model.topLevelNetwork)
 	for _, port := range normalizedSnapshot.Ports {
 		serviceName := strings.TrimSpace(port.ServiceName)
 		if serviceName == "" {
@@ -769,6 +844,10 @@ func workbenchBuildServiceNode(
 	if extras.Managed {
 		workbenchAddServiceCommand(serviceNode, extras.Command)
 		workbenchAddServiceEnvironment(serviceNode, extras.Environment)
+		workbenchAddServiceVolumes(serviceNode, extras.Volumes)
+		workbenchAddServiceHealthcheck(serviceNode, extras.Healthcheck)
+	} else {
+		workbenchAddServiceEnvironment(serviceNode, extras.ConnectionEnvironment)
 	}
 	if len(dependencies) > 0 {
 		depSequence := workbenchYAMLSequenceNode()
@@ -841,6 +920,195 @@ func workbenchAddServiceEnvironment(serviceNode *yaml.Node, environment []workbe
 	workbenchYAMLAddMapEntry(serviceNode, "environment", workbenchYAMLEnvironmentNode(environment))
 }
 
+// workbenchEnsureServiceEnvironmentEntries adds missing keys to an imported
+// service without touching values the project already declares.
+func workbenchEnsureServiceEnvironmentEntries(serviceNode *yaml.Node, environment []workbenchComposeEnvironmentEntry) {
+	if len(environment) == 0 {
+		return
+	}
+	currentNode, ok := workbenchYAMLFindMapValue(serviceNode, "environment")
+	if !ok || currentNode == nil {
+		workbenchYAMLSetMapEntry(serviceNode, "environment", workbenchYAMLEnvironmentNode(environment))
+		return
+	}
+
+	switch currentNode.Kind {
+	case yaml.MappingNode:
+		for _, entry := range environment {
+			if _, exists := workbenchYAMLFindMapValue(currentNode, entry.Key); exists {
+				continue
+			}
+			workbenchYAMLAddMapEntry(currentNode, entry.Key, workbenchYAMLScalarNode(entry.Value))
+		}
+	case yaml.SequenceNode:
+		existing := make(map[string]struct{}, len(currentNode.Content))
+		for _, item := range currentNode.Content {
+			if item == nil || item.Kind != yaml.ScalarNode {
+				continue
+			}
+			key, _, _ := strings.Cut(item.Value, "=")
+			existing[strings.TrimSpace(key)] = struct{}{}
+		}
+		for _, entry := range environment {
+			if _, exists := existing[entry.Key]; exists {
+				continue
+			}
+			currentNode.Content = append(currentNode.Content, workbenchYAMLScalarNode(entry.Key+"="+entry.Value))
+		}
+	}
+}
+
+func workbenchAddServiceVolumes(serviceNode *yaml.Node, volumes []workbenchComposeVolumeMount) {
+	if len(volumes) == 0 {
+		return
+	}
+	workbenchYAMLAddMapEntry(serviceNode, "volumes", workbenchYAMLVolumesNode(volumes))
+}
+
+func workbenchAddServiceHealthcheck(serviceNode *yaml.Node, healthcheck *workbenchComposeHealthcheck) {
+	if healthcheck == nil || len(healthcheck.Test) == 0 {
+		return
+	}
+	workbenchYAMLAddMapEntry(serviceNode, "healthcheck", workbenchYAMLHealthcheckNode(*healthcheck))
+}
+
+func workbenchPatchServiceVolumes(serviceNode *yaml.Node, volumes []workbenchComposeVolumeMount) {
+	if len(volumes) == 0 {
+		workbenchYAMLDeleteMapEntry(serviceNode, "volumes")
+		return
+	}
+	workbenchYAMLSetMapEntry(serviceNode, "volumes", workbenchYAMLVolumesNode(volumes))
+}
+
+func workbenchPatchServiceHealthcheck(serviceNode *yaml.Node, healthcheck *workbenchComposeHealthcheck) {
+	if healthcheck == nil || len(healthcheck.Test) == 0 {
+		workbenchYAMLDeleteMapEntry(serviceNode, "healthcheck")
+		return
+	}
+	workbenchYAMLSetMapEntry(serviceNode, "healthcheck", workbenchYAMLHealthcheckNode(*healthcheck))
+}
+
+func workbenchManagedVolumeNames(serviceExtras map[string]workbenchComposeServiceExtras) []string {
+	seen := make(map[string]struct{})
+	names := []string{}
+	for _, extras := range serviceExtras {
+		if !extras.Managed {
+			continue
+		}
+		for _, volume := range extras.Volumes {
+			name := strings.TrimSpace(volume.Name)
+			if name == "" {
+				continue
+			}
+			if _, exists := seen[name]; exists {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func workbenchEnsureTopLevelVolumes(root *yaml.Node, volumeNames []string) {
+	if root == nil || root.Kind != yaml.MappingNode || len(volumeNames) == 0 {
+		return
+	}
+	volumesNode, ok := workbenchYAMLFindMapValue(root, "volumes")
+	if !ok || volumesNode == nil || volumesNode.Kind != yaml.MappingNode {
+		volumesNode = workbenchYAMLMappingNode()
+		workbenchYAMLSetMapEntry(root, "volumes", volumesNode)
+	}
+	for _, volumeName := range volumeNames {
+		if _, exists := workbenchYAMLFindMapValue(volumesNode, volumeName); exists {
+			continue
+		}
+		workbenchYAMLAddMapEntry(volumesNode, volumeName, workbenchYAMLMappingNode())
+	}
+}
+
+// workbenchPatchTopLevelNetworks reconciles the top-level networks mapping
+// with the snapshot. Only the fields the workbench models are touched, so
+// pass-through options such as ipam survive. Referenced networks the snapshot
+// does not define are only ensured to exist; prune drops undeclared entries
+// and is reserved for snapshots that track every network definition.
+func workbenchPatchTopLevelNetworks(
+	root *yaml.Node,
+	networks map[string]WorkbenchComposeNetwork,
+	referenced []string,
+	prune bool,
+) {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return
+	}
+	networksNode, ok := workbenchYAMLFindMapValue(root, "networks")
+	if !ok || networksNode == nil || networksNode.Kind != yaml.MappingNode {
+		if len(networks) == 0 && len(referenced) == 0 {
+			if ok && prune {
+				workbenchYAMLDeleteMapEntry(root, "networks")
+			}
+			return
+		}
+		networksNode = workbenchYAMLMappingNode()
+		workbenchYAMLSetMapEntry(root, "networks", networksNode)
+	}
+
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		network := networks[name]
+		networkNode, exists := workbenchYAMLFindMapValue(networksNode, name)
+		if !exists || networkNode == nil || networkNode.Kind != yaml.MappingNode {
+			workbenchYAMLSetMapEntry(networksNode, name, workbenchYAMLNetworkNode(network))
+			continue
+		}
+		workbenchYAMLSetOrDeleteScalarEntry(networkNode, "driver", network.Driver)
+		if network.DockerName != "" {
+			workbenchYAMLSetOrDeleteScalarEntry(networkNode, "name", network.DockerName)
+		}
+		workbenchYAMLSetOrDeleteBoolEntry(networkNode, "internal", network.Internal)
+		if externalNode, exists := workbenchYAMLFindMapValue(networkNode, "external"); !exists || externalNode == nil || externalNode.Kind == yaml.ScalarNode {
+			workbenchYAMLSetOrDeleteBoolEntry(networkNode, "external", network.External)
+		}
+	}
+	for _, name := range referenced {
+		if _, exists := workbenchYAMLFindMapValue(networksNode, name); exists {
+			continue
+		}
+		workbenchYAMLAddMapEntry(networksNode, name, workbenchYAMLMappingNode())
+	}
+
+	if !prune {
+		return
+	}
+	keep := make(map[string]struct{}, len(networks)+len(referenced))
+	for name := range networks {
+		keep[name] = struct{}{}
+	}
+	for _, name := range referenced {
+		keep[name] = struct{}{}
+	}
+	nextContent := make([]*yaml.Node, 0, len(networksNode.Content))
+	for idx := 0; idx+1 < len(networksNode.Content); idx += 2 {
+		keyNode := networksNode.Content[idx]
+		if keyNode == nil {
+			continue
+		}
+		if _, exists := keep[strings.TrimSpace(keyNode.Value)]; !exists {
+			continue
+		}
+		nextContent = append(nextContent, keyNode, networksNode.Content[idx+1])
+	}
+	networksNode.Content = nextContent
+	if len(networksNode.Content) == 0 {
+		workbenchYAMLDeleteMapEntry(root, "networks")
+	}
+}
+
 func workbenchPruneRemovedManagedServiceNodes(
 	servicesNode *yaml.Node,
 	desiredServices map[string]WorkbenchComposeService,
@@ -1065,14 +1333,17 @@ func workbenchApplyDriftCheck(
 	currentFingerprint := strings.TrimSpace(currentSource.Fingerprint)
 	expectedFingerprint := strings.TrimSpace(expectedSourceFingerprint)
 
-	if storedFingerprint != expectedFingerprint {
-		issues = append(issues, WorkbenchValidationIssue{
-			Class:   workbenchValidationClassSchema,
-			Code:    "WB-DRIFT-EXPECTED-SOURCE-FINGERPRINT-MISMATCH",
-			Path:    "$.expectedSourceFingerprint",
-			Message: fmt.Sprintf("expected source fingerprint %q does not match current workbench fingerprint %q", expectedFingerprint, storedFingerprint),
-		})
+	// On-disk drift alone is handled by the three-way merge; only a stale
+	// client fingerprint blocks here.
+	if storedFingerprint == expectedFingerprint {
+		return nil
 	}
+	issues = append(issues, WorkbenchValidationIssue{
+		Class:   workbenchValidationClassSchema,
+		Code:    "WB-DRIFT-EXPECTED-SOURCE-FINGERPRINT-MISMATCH",
+		Path:    "$.expectedSourceFingerprint",
+		Message: fmt.Sprintf("expected source fingerprint %q does not match current workbench fingerprint %q", expectedFingerprint, storedFingerprint),
+	})
 	if storedFingerprint != currentFingerprint {
 		issues = append(issues, WorkbenchValidationIssue{
 			Class:   workbenchValidationClassSchema,
@@ -1081,9 +1352,6 @@ func workbenchApplyDriftCheck(
 			Message: fmt.Sprintf("compose source fingerprint %q does not match stored workbench fingerprint %q", currentFingerprint, storedFingerprint),
 		})
 	}
-	if len(issues) == 0 {
-		return nil
-	}
 
 	sort.SliceStable(issues, func(i, j int) bool {
 		return workbenchValidationIssueLess(issues[i], issues[j])
@@ -1195,10 +1463,17 @@ func generateWorkbenchCompose(snapshot WorkbenchStackSnapshot) (string, error) {
 	if len(model.topLevelNetwork) > 0 {
 		networksNode := workbenchYAMLMappingNode()
 		for _, networkName := range model.topLevelNetwork {
-			workbenchYAMLAddMapEntry(networksNode, networkName, workbenchYAMLMappingNode())
+			workbenchYAMLAddMapEntry(networksNode, networkName, workbenchYAMLNetworkNode(model.networks[networkName]))
 		}
 		workbenchYAMLAddMapEntry(root, "networks", networksNode)
 	}
+	if len(model.topLevelVolumes) > 0 {
+		volumesNode := workbenchYAMLMappingNode()
+		for _, volumeName := range model.topLevelVolumes {
+			workbenchYAMLAddMapEntry(volumesNode, volumeName, workbenchYAMLMappingNode())
+		}
+		workbenchYAMLAddMapEntry(root, "volumes", volumesNode)
+	}
 
 	encoded, err := encodeWorkbenchComposeYAML(root)
 	if err != nil {
@@ -1221,6 +1496,7 @@ func buildWorkbenchComposeGenerationModel(snapshot WorkbenchStackSnapshot) (work
 		ports:         make(map[string][]WorkbenchComposePort),
 		resources:     make(map[string]WorkbenchComposeResource),
 		networkRefs:   make(map[string][]string),
+		networks:      make(map[string]WorkbenchComposeNetwork),
 		serviceExtras: make(map[string]workbenchComposeServiceExtras),
 	}
 
@@ -1296,8 +1572,20 @@ func buildWorkbenchComposeGenerationModel(snapshot WorkbenchStackSnapshot) (work
 		model.services = append(model.services, managedService.Service)
 		model.serviceExtras[serviceName] = managedService.Extras
 	}
+	workbenchApplyManagedServiceConnections(model.serviceExtras, managedServiceModels)
 
 	dependencySet := make(map[string]struct{})
+	for _, managedService := range managedServiceModels {
+		serviceName := strings.TrimSpace(managedService.ServiceName)
+		for _, dependsOn := range managedService.DependsOn {
+			key := serviceName + "|" + dependsOn
+			if _, exists := dependencySet[key]; exists {
+				continue
+			}
+			dependencySet[key] = struct{}{}
+			model.dependencies[serviceName] = append(model.dependencies[serviceName], dependsOn)
+		}
+	}
 	for idx, dependency := range normalizedSnapshot.Dependencies {
 		path := fmt.Sprintf("$.dependencies[%d]", idx)
 		serviceName := strings.TrimSpace(dependency.ServiceName)
@@ -1529,6 +1817,31 @@ func buildWorkbenchComposeGenerationModel(snapshot WorkbenchStackSnapshot) (work
 	}
 
 	networkSet := make(map[string]struct{})
+	for idx, network := range normalizedSnapshot.Networks {
+		path := fmt.Sprintf("$.networks[%d]", idx)
+		if network.Name == "" {
+			addIssue(WorkbenchValidationIssue{
+				Class:   workbenchValidationClassSchema,
+				Code:    "WB-VAL-NETWORK-DEFINITION-NAME-REQUIRED",
+				Path:    path + ".name",
+				Message: "network name is required",
+			})
+			continue
+		}
+		if _, exists := networkSet[network.Name]; exists {
+			addIssue(WorkbenchValidationIssue{
+				Class:   workbenchValidationClassSchema,
+				Code:    "WB-VAL-NETWORK-DEFINITION-DUPLICATE",
+				Path:    path + ".name",
+				Message: fmt.Sprintf("duplicate network definition %q", network.Name),
+			})
+			continue
+		}
+		networkSet[network.Name] = struct{}{}
+		model.networks[network.Name] = network
+		model.topLevelNetwork = append(model.topLevelNetwork, network.Name)
+	}
+
 	perServiceNetworkSet := make(map[string]struct{})
 	for idx, networkRef := range normalizedSnapshot.NetworkRefs {
 		path := fmt.Sprintf("$.networkRefs[%d]", idx)
@@ -1567,6 +1880,10 @@ func buildWorkbenchComposeGenerationModel(snapshot WorkbenchStackSnapshot) (work
 		}
 	}
 	sort.Strings(model.topLevelNetwork)
+	for _, issue := range workbenchDataStoreEdgeNetworkIssues(normalizedSnapshot, model.services, model.networkRefs) {
+		addIssue(issue)
+	}
+	model.topLevelVolumes = workbenchManagedVolumeNames(model.serviceExtras)
 
 	for idx, volumeRef := range normalizedSnapshot.VolumeRefs {
 		path := fmt.Sprintf("$.volumeRefs[%d]", idx)
@@ -1790,6 +2107,14 @@ func workbenchComposeApplyStorageError(
 	return errs.WithDetails(errs.Wrap(errs.CodeWorkbenchStorageFailed, message, cause), details)
 }
 
+func workbenchYAMLSetOrDeleteBoolEntry(node *yaml.Node, key string, value bool) {
+	if !value {
+		workbenchYAMLDeleteMapEntry(node, key)
+		return
+	}
+	workbenchYAMLSetMapEntry(node, key, workbenchYAMLBoolNode(true))
+}
+
 func workbenchYAMLScalarNode(value string) *yaml.Node {
 	return &yaml.Node{
 		Kind:  yaml.ScalarNode,
@@ -1828,6 +2153,61 @@ func workbenchYAMLEnvironmentNode(environment []workbenchComposeEnvironmentEntry
 	return node
 }
 
+func workbenchYAMLVolumesNode(volumes []workbenchComposeVolumeMount) *yaml.Node {
+	node := workbenchYAMLSequenceNode()
+	for _, volume := range volumes {
+		node.Content = append(node.Content, workbenchYAMLScalarNode(volume.Name+":"+volume.Target))
+	}
+	return node
+}
+
+func workbenchYAMLHealthcheckNode(healthcheck workbenchComposeHealthcheck) *yaml.Node {
+	node := workbenchYAMLMappingNode()
+	workbenchYAMLAddMapEntry(node, "test", workbenchYAMLCommandNode(healthcheck.Test))
+	if interval := strings.TrimSpace(healthcheck.Interval); interval != "" {
+		workbenchYAMLAddMapEntry(node, "interval", workbenchYAMLScalarNode(interval))
+	}
+	if timeout := strings.TrimSpace(healthcheck.Timeout); timeout != "" {
+		workbenchYAMLAddMapEntry(node, "timeout", workbenchYAMLScalarNode(timeout))
+	}
+	if healthcheck.Retries > 0 {
+		workbenchYAMLAddMapEntry(node, "retries", &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!int",
+			Value: strconv.Itoa(healthcheck.Retries),
+		})
+	}
+	if startPeriod := strings.TrimSpace(healthcheck.StartPeriod); startPeriod != "" {
+		workbenchYAMLAddMapEntry(node, "start_period", workbenchYAMLScalarNode(startPeriod))
+	}
+	return node
+}
+
+func workbenchYAMLNetworkNode(network WorkbenchComposeNetwork) *yaml.Node {
+	node := workbenchYAMLMappingNode()
+	if network.Driver != "" {
+		workbenchYAMLAddMapEntry(node, "driver", workbenchYAMLScalarNode(network.Driver))
+	}
+	if network.DockerName != "" {
+		workbenchYAMLAddMapEntry(node, "name", workbenchYAMLScalarNode(network.DockerName))
+	}
+	if network.Internal {
+		workbenchYAMLAddMapEntry(node, "internal", workbenchYAMLBoolNode(true))
+	}
+	if network.External {
+		workbenchYAMLAddMapEntry(node, "external", workbenchYAMLBoolNode(true))
+	}
+	return node
+}
+
+func workbenchYAMLBoolNode(value bool) *yaml.Node {
+	return &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   "!!bool",
+		Value: strconv.FormatBool(value),
+	}
+}
+
 func workbenchYAMLAddMapEntry(mapping *yaml.Node, key string, value *yaml.Node) {
 	if mapping == nil || value == nil {
 		return
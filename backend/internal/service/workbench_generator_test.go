@@ -839,7 +839,7 @@ func TestWorkbenchApplyComposeFromStoredSnapshotStaleRevisionBlocked(t *testing.
 	}
 }
 
-func TestWorkbenchApplyComposeFromStoredSnapshotDriftBlocked(t *testing.T) {
+func TestWorkbenchApplyComposeFromStoredSnapshotDriftConflictBlocked(t *testing.T) {
 	t.Parallel()
 
 	templatesDir := t.TempDir()
@@ -859,6 +859,11 @@ func TestWorkbenchApplyComposeFromStoredSnapshotDriftBlocked(t *testing.T) {
 		t.Fatalf("import snapshot: %v", err)
 	}
 
+	imported.Services[0].Image = "nginx:1.26"
+	if err := svc.saveWorkbenchSnapshot(context.Background(), "demo", imported); err != nil {
+		t.Fatalf("save mutated snapshot: %v", err)
+	}
+
 	externalChange := "services:\n  api:\n    image: nginx:1.27\n"
 	if err := os.WriteFile(composePath, []byte(externalChange), 0o644); err != nil {
 		t.Fatalf("write external compose change: %v", err)
@@ -880,6 +885,16 @@ func TestWorkbenchApplyComposeFromStoredSnapshotDriftBlocked(t *testing.T) {
 	if typed.Code != errs.CodeWorkbenchDriftDetected {
 		t.Fatalf("expected code %q, got %q", errs.CodeWorkbenchDriftDetected, typed.Code)
 	}
+	details, _ := typed.Details.(map[string]any)
+	foundConflict := false
+	for _, issue := range extractWorkbenchValidationIssues(t, details) {
+		if issue.Code == "WB-DRIFT-MERGE-CONFLICT" && issue.Service == "api" && issue.Path == `$.services["api"].image` {
+			foundConflict = true
+		}
+	}
+	if !foundConflict {
+		t.Fatalf("expected image merge conflict issue, got %#v", details["issues"])
+	}
 
 	currentSource, readErr := os.ReadFile(composePath)
 	if readErr != nil {
@@ -1070,8 +1085,9 @@ services:
     healthcheck:
       test: ["CMD-SHELL", "pg_isready -U postgres"]
     networks:
-      edge: {}
+      data: {}
 networks:
+  data: {}
   edge: {}
 `
 	if err := os.WriteFile(composePath, []byte(original), 0o644); err != nil {
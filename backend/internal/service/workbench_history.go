@@ -0,0 +1,402 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+const (
+	WorkbenchMutationImport                = "import"
+	WorkbenchMutationPortResolve           = "ports.resolve"
+	WorkbenchMutationPortMutate            = "ports.mutate"
+	WorkbenchMutationResourceMutate        = "resource.mutate"
+	WorkbenchMutationModuleMutate          = "module.mutate"
+	WorkbenchMutationOptionalServiceAdd    = "service.add"
+	WorkbenchMutationOptionalServiceRemove = "service.remove"
+	WorkbenchMutationPolicyUpdate          = "policy.update"
+	WorkbenchMutationRevert                = "revert"
+	WorkbenchMutationDriftMerge            = "drift.merge"
+	WorkbenchMutationNetworkMutate         = "network.mutate"
+	WorkbenchMutationBundleImport          = "bundle.import"
+
+	workbenchRevisionSystemActor  = "system"
+	defaultWorkbenchRevisionLimit = 50
+	maxWorkbenchRevisionLimit     = 200
+
+	workbenchRevisionDiffAdded   = "added"
+	workbenchRevisionDiffRemoved = "removed"
+	workbenchRevisionDiffChanged = "changed"
+)
+
+// WorkbenchActor identifies who changed a workbench snapshot. Requests without
+// an actor (background jobs, auto-import) are recorded as "system".
+type WorkbenchActor struct {
+	UserID uint
+	Login  string
+}
+
+type workbenchActorContextKey struct{}
+
+func WithWorkbenchActor(ctx context.Context, actor WorkbenchActor) context.Context {
+	return context.WithValue(ctx, workbenchActorContextKey{}, actor)
+}
+
+func workbenchActorFromContext(ctx context.Context) WorkbenchActor {
+	actor, _ := ctx.Value(workbenchActorContextKey{}).(WorkbenchActor)
+	actor.Login = strings.TrimSpace(actor.Login)
+	if actor.Login == "" {
+		actor.Login = workbenchRevisionSystemActor
+	}
+	return actor
+}
+
+type WorkbenchRevisionDiffEntry struct {
+	Section string          `json:"section"`
+	Op      string          `json:"op"`
+	Key     string          `json:"key"`
+	Before  json.RawMessage `json:"before,omitempty"`
+	After   json.RawMessage `json:"after,omitempty"`
+}
+
+type WorkbenchRevisionSummary struct {
+	Revision     int                          `json:"revision"`
+	UserID       uint                         `json:"userId,omitempty"`
+	UserLogin    string                       `json:"userLogin"`
+	MutationType string                       `json:"mutationType"`
+	Note         string                       `json:"note,omitempty"`
+	CreatedAt    time.Time                    `json:"createdAt"`
+	Diff         []WorkbenchRevisionDiffEntry `json:"diff"`
+}
+
+type WorkbenchRevisionDetail struct {
+	WorkbenchRevisionSummary
+	Snapshot WorkbenchStackSnapshot `json:"snapshot"`
+}
+
+type WorkbenchRevertRequest struct {
+	Revision         int  `json:"revision"`
+	ExpectedRevision *int `json:"expectedRevision,omitempty"`
+}
+
+func (s *WorkbenchService) SetRevisionRepository(revisions repository.WorkbenchRevisionRepository) {
+	s.revisions = revisions
+}
+
+// saveWorkbenchSnapshotRevision persists next and appends it to the project's
+// revision log. With the snapshot table both rows commit together; with the
+// legacy settings blob a failed log write still fails the mutation.
+func (s *WorkbenchService) saveWorkbenchSnapshotRevision(
+	ctx context.Context,
+	projectName string,
+	previous WorkbenchStackSnapshot,
+	next WorkbenchStackSnapshot,
+	mutationType string,
+	note string,
+) error {
+	entry, err := s.buildWorkbenchRevision(ctx, projectName, previous, next, mutationType, note)
+	if err != nil {
+		return workbenchStorageError(projectName, "failed to encode workbench revision", err)
+	}
+	if entry == nil {
+		return s.saveWorkbenchSnapshotExpected(ctx, projectName, next, previous.Revision)
+	}
+	if s.snapshots != nil {
+		return s.saveTableWorkbenchSnapshot(ctx, projectName, next, previous.Revision, entry)
+	}
+	if err := s.saveWorkbenchSnapshotExpected(ctx, projectName, next, previous.Revision); err != nil {
+		return err
+	}
+	if err := s.revisions.Create(ctx, entry); err != nil {
+		return workbenchStorageError(projectName, "failed to record workbench revision", err)
+	}
+	return nil
+}
+
+// buildWorkbenchRevision returns the revision log row for next, or nil when
+// no revision repository is configured.
+func (s *WorkbenchService) buildWorkbenchRevision(
+	ctx context.Context,
+	projectName string,
+	previous WorkbenchStackSnapshot,
+	next WorkbenchStackSnapshot,
+	mutationType string,
+	note string,
+) (*models.WorkbenchSnapshotRevision, error) {
+	if s.revisions == nil {
+		return nil, nil
+	}
+
+	normalized := normalizeWorkbenchStackSnapshot(next)
+	normalized.ProjectName = projectName
+	encodedSnapshot, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	encodedDiff, err := json.Marshal(diffWorkbenchSnapshots(previous, normalized))
+	if err != nil {
+		return nil, err
+	}
+
+	actor := workbenchActorFromContext(ctx)
+	entry := &models.WorkbenchSnapshotRevision{
+		ProjectName:  projectName,
+		Revision:     normalized.Revision,
+		UserID:       actor.UserID,
+		UserLogin:    actor.Login,
+		MutationType: mutationType,
+		Note:         note,
+		Snapshot:     string(encodedSnapshot),
+		Diff:         string(encodedDiff),
+	}
+	entry.CreatedAt = s.nowFn().UTC()
+	return entry, nil
+}
+
+func (s *WorkbenchService) ListRevisions(
+	ctx context.Context,
+	projectName string,
+	limit int,
+) ([]WorkbenchRevisionSummary, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return nil, err
+	}
+	if s.revisions == nil {
+		return nil, workbenchStorageError(normalizedProject, "workbench revision history is unavailable", nil)
+	}
+	if limit <= 0 {
+		limit = defaultWorkbenchRevisionLimit
+	}
+	if limit > maxWorkbenchRevisionLimit {
+		limit = maxWorkbenchRevisionLimit
+	}
+
+	entries, err := s.revisions.ListByProject(ctx, normalizedProject, limit)
+	if err != nil {
+		return nil, workbenchStorageError(normalizedProject, "failed to load workbench revision history", err)
+	}
+	summaries := make([]WorkbenchRevisionSummary, 0, len(entries))
+	for _, entry := range entries {
+		summary, err := workbenchRevisionSummaryFromModel(entry)
+		if err != nil {
+			return nil, workbenchStorageError(normalizedProject, "failed to decode workbench revision diff", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func (s *WorkbenchService) GetRevision(
+	ctx context.Context,
+	projectName string,
+	revision int,
+) (WorkbenchRevisionDetail, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchRevisionDetail{}, err
+	}
+	return s.loadWorkbenchRevision(ctx, normalizedProject, revision)
+}
+
+// RevertToRevision restores the snapshot content recorded at revision as a new
+// revision. History is append-only; nothing recorded after revision is removed.
+func (s *WorkbenchService) RevertToRevision(
+	ctx context.Context,
+	projectName string,
+	input WorkbenchRevertRequest,
+) (WorkbenchStackSnapshot, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	defer release()
+
+	current, err := s.loadStoredSnapshotForComposeLocked(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	if input.ExpectedRevision != nil && current.Revision != *input.ExpectedRevision {
+		return WorkbenchStackSnapshot{}, workbenchApplyStaleRevisionError(current, *input.ExpectedRevision)
+	}
+
+	target, err := s.loadWorkbenchRevision(ctx, normalizedProject, input.Revision)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+
+	reverted := target.Snapshot
+	reverted.ProjectName = normalizedProject
+	reverted.ProjectDir = current.ProjectDir
+	reverted.ComposePath = current.ComposePath
+	reverted.SourceFingerprint = current.SourceFingerprint
+	reverted.Revision = current.Revision + 1
+
+	note := fmt.Sprintf("reverted to revision %d", target.Revision)
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, current, reverted, WorkbenchMutationRevert, note); err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	return normalizeWorkbenchStackSnapshot(reverted), nil
+}
+
+func (s *WorkbenchService) loadWorkbenchRevision(
+	ctx context.Context,
+	projectName string,
+	revision int,
+) (WorkbenchRevisionDetail, error) {
+	if s.revisions == nil {
+		return WorkbenchRevisionDetail{}, workbenchStorageError(projectName, "workbench revision history is unavailable", nil)
+	}
+	if revision <= 0 {
+		return WorkbenchRevisionDetail{}, errs.New(errs.CodeProjectInvalidBody, "revision must be a positive integer")
+	}
+
+	entry, err := s.revisions.GetByProjectRevision(ctx, projectName, revision)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return WorkbenchRevisionDetail{}, errs.WithDetails(
+				errs.New(errs.CodeWorkbenchRevisionNotFound, fmt.Sprintf("workbench revision %d not found for project %q", revision, projectName)),
+				map[string]any{
+					"project":  projectName,
+					"revision": revision,
+				},
+			)
+		}
+		return WorkbenchRevisionDetail{}, workbenchStorageError(projectName, "failed to load workbench revision", err)
+	}
+
+	summary, err := workbenchRevisionSummaryFromModel(*entry)
+	if err != nil {
+		return WorkbenchRevisionDetail{}, workbenchStorageError(projectName, "failed to decode workbench revision diff", err)
+	}
+	var snapshot WorkbenchStackSnapshot
+	if err := json.Unmarshal([]byte(entry.Snapshot), &snapshot); err != nil {
+		return WorkbenchRevisionDetail{}, workbenchStorageError(projectName, "failed to decode workbench revision snapshot", err)
+	}
+	return WorkbenchRevisionDetail{
+		WorkbenchRevisionSummary: summary,
+		Snapshot:                 normalizeWorkbenchStackSnapshot(snapshot),
+	}, nil
+}
+
+func workbenchRevisionSummaryFromModel(entry models.WorkbenchSnapshotRevision) (WorkbenchRevisionSummary, error) {
+	diff := []WorkbenchRevisionDiffEntry{}
+	if strings.TrimSpace(entry.Diff) != "" {
+		if err := json.Unmarshal([]byte(entry.Diff), &diff); err != nil {
+			return WorkbenchRevisionSummary{}, err
+		}
+	}
+	return WorkbenchRevisionSummary{
+		Revision:     entry.Revision,
+		UserID:       entry.UserID,
+		UserLogin:    entry.UserLogin,
+		MutationType: entry.MutationType,
+		Note:         entry.Note,
+		CreatedAt:    entry.CreatedAt,
+		Diff:         diff,
+	}, nil
+}
+
+// diffWorkbenchSnapshots compares two snapshots section by section, matching
+// entries on their identity (service name, port tuple, rule code, ...).
+func diffWorkbenchSnapshots(before, after WorkbenchStackSnapshot) []WorkbenchRevisionDiffEntry {
+	diff := []WorkbenchRevisionDiffEntry{}
+	diff = append(diff, workbenchDiffSection("services", before.Services, after.Services, func(item WorkbenchComposeService) string {
+		return item.ServiceName
+	})...)
+	diff = append(diff, workbenchDiffSection("dependencies", before.Dependencies, after.Dependencies, func(item WorkbenchComposeDependency) string {
+		return item.ServiceName + "->" + item.DependsOn
+	})...)
+	diff = append(diff, workbenchDiffSection("ports", before.Ports, after.Ports, func(item WorkbenchComposePort) string {
+		return fmt.Sprintf("%s:%d/%s", item.ServiceName, item.ContainerPort, item.Protocol)
+	})...)
+	diff = append(diff, workbenchDiffSection("resources", before.Resources, after.Resources, func(item WorkbenchComposeResource) string {
+		return item.ServiceName
+	})...)
+	diff = append(diff, workbenchDiffSection("networks", before.Networks, after.Networks, func(item WorkbenchComposeNetwork) string {
+		return item.Name
+	})...)
+	diff = append(diff, workbenchDiffSection("networkRefs", before.NetworkRefs, after.NetworkRefs, func(item WorkbenchComposeNetworkRef) string {
+		return item.ServiceName + "/" + item.NetworkName
+	})...)
+	diff = append(diff, workbenchDiffSection("volumeRefs", before.VolumeRefs, after.VolumeRefs, func(item WorkbenchComposeVolumeRef) string {
+		return item.ServiceName + "/" + item.VolumeName
+	})...)
+	diff = append(diff, workbenchDiffSection("envRefs", before.EnvRefs, after.EnvRefs, func(item WorkbenchComposeEnvRef) string {
+		return item.ServiceName + ":" + item.Path + ":" + item.Variable
+	})...)
+	diff = append(diff, workbenchDiffSection("managedServices", before.ManagedServices, after.ManagedServices, func(item WorkbenchManagedService) string {
+		return item.ServiceName
+	})...)
+	diff = append(diff, workbenchDiffSection("modules", before.Modules, after.Modules, func(item WorkbenchStackModule) string {
+		return item.ModuleType + "/" + item.ServiceName
+	})...)
+	diff = append(diff, workbenchDiffSection("policyOverrides", before.PolicyOverrides, after.PolicyOverrides, func(item WorkbenchPolicyOverride) string {
+		return item.Rule
+	})...)
+	return diff
+}
+
+func workbenchDiffSection[T any](section string, before, after []T, key func(T) string) []WorkbenchRevisionDiffEntry {
+	beforeByKey := workbenchDiffIndex(before, key)
+	afterByKey := workbenchDiffIndex(after, key)
+
+	keys := make([]string, 0, len(beforeByKey)+len(afterByKey))
+	for itemKey := range beforeByKey {
+		keys = append(keys, itemKey)
+	}
+	for itemKey := range afterByKey {
+		if _, ok := beforeByKey[itemKey]; !ok {
+			keys = append(keys, itemKey)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := []WorkbenchRevisionDiffEntry{}
+	for _, itemKey := range keys {
+		beforeValue, inBefore := beforeByKey[itemKey]
+		afterValue, inAfter := afterByKey[itemKey]
+		switch {
+		case inBefore && !inAfter:
+			entries = append(entries, WorkbenchRevisionDiffEntry{Section: section, Op: workbenchRevisionDiffRemoved, Key: itemKey, Before: beforeValue})
+		case !inBefore && inAfter:
+			entries = append(entries, WorkbenchRevisionDiffEntry{Section: section, Op: workbenchRevisionDiffAdded, Key: itemKey, After: afterValue})
+		case !bytes.Equal(beforeValue, afterValue):
+			entries = append(entries, WorkbenchRevisionDiffEntry{Section: section, Op: workbenchRevisionDiffChanged, Key: itemKey, Before: beforeValue, After: afterValue})
+		}
+	}
+	return entries
+}
+
+func workbenchDiffIndex[T any](items []T, key func(T) string) map[string]json.RawMessage {
+	indexed := make(map[string]json.RawMessage, len(items))
+	for _, item := range items {
+		itemKey := key(item)
+		for suffix := 2; ; suffix++ {
+			if _, exists := indexed[itemKey]; !exists {
+				break
+			}
+			itemKey = fmt.Sprintf("%s#%d", key(item), suffix)
+		}
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		indexed[itemKey] = encoded
+	}
+	return indexed
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+type fakeWorkbenchRevisionRepo struct {
+	mu      sync.Mutex
+	entries []models.WorkbenchSnapshotRevision
+}
+
+func (r *fakeWorkbenchRevisionRepo) Create(_ context.Context, revision *models.WorkbenchSnapshotRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revision.ID = uint(len(r.entries) + 1)
+	r.entries = append(r.entries, *revision)
+	return nil
+}
+
+func (r *fakeWorkbenchRevisionRepo) ListByProject(_ context.Context, projectName string, limit int) ([]models.WorkbenchSnapshotRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matches := []models.WorkbenchSnapshotRevision{}
+	for _, entry := range r.entries {
+		if entry.ProjectName == projectName {
+			matches = append(matches, entry)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Revision > matches[j].Revision
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (r *fakeWorkbenchRevisionRepo) GetByProjectRevision(_ context.Context, projectName string, revision int) (*models.WorkbenchSnapshotRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for idx := len(r.entries) - 1; idx >= 0; idx-- {
+		if r.entries[idx].ProjectName == projectName && r.entries[idx].Revision == revision {
+			entry := r.entries[idx]
+			return &entry, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestWorkbenchRevisionHistoryRecordsActorAndRevertsAsNewRevision(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	source := "services:\n  api:\n    image: nginx:1.25\n    ports:\n      - \"8080:80\"\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	revisions := &fakeWorkbenchRevisionRepo{}
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	svc.SetRevisionRepository(revisions)
+
+	imported, _, err := svc.ImportComposeSnapshot(context.Background(), "demo", "manual")
+	if err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+
+	actorCtx := WithWorkbenchActor(context.Background(), WorkbenchActor{UserID: 7, Login: "octo"})
+	mutated, _, err := svc.AddOptionalService(actorCtx, "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "redis"})
+	if err != nil {
+		t.Fatalf("add redis: %v", err)
+	}
+
+	history, err := svc.ListRevisions(context.Background(), "demo", 0)
+	if err != nil {
+		t.Fatalf("list revisions: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 revisions, got %#v", history)
+	}
+	latest := history[0]
+	if latest.Revision != mutated.Revision || latest.MutationType != WorkbenchMutationOptionalServiceAdd {
+		t.Fatalf("unexpected latest revision: %#v", latest)
+	}
+	if latest.UserID != 7 || latest.UserLogin != "octo" {
+		t.Fatalf("expected actor octo, got %#v", latest)
+	}
+	if history[1].UserLogin != workbenchRevisionSystemActor || history[1].Note != workbenchImportReasonManual {
+		t.Fatalf("expected system import revision, got %#v", history[1])
+	}
+	if !workbenchRevisionDiffHas(latest.Diff, "managedServices", workbenchRevisionDiffAdded, "redis") {
+		t.Fatalf("expected managed service diff, got %#v", latest.Diff)
+	}
+
+	expected := mutated.Revision
+	reverted, err := svc.RevertToRevision(actorCtx, "demo", WorkbenchRevertRequest{
+		Revision:         imported.Revision,
+		ExpectedRevision: &expected,
+	})
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if reverted.Revision != mutated.Revision+1 {
+		t.Fatalf("expected revert to create revision %d, got %d", mutated.Revision+1, reverted.Revision)
+	}
+	if len(reverted.ManagedServices) != 0 {
+		t.Fatalf("expected reverted snapshot without managed services, got %#v", reverted.ManagedServices)
+	}
+
+	detail, err := svc.GetRevision(context.Background(), "demo", reverted.Revision)
+	if err != nil {
+		t.Fatalf("get revision: %v", err)
+	}
+	if detail.MutationType != WorkbenchMutationRevert || detail.Note != "reverted to revision 1" {
+		t.Fatalf("unexpected revert revision: %#v", detail.WorkbenchRevisionSummary)
+	}
+	if !workbenchRevisionDiffHas(detail.Diff, "managedServices", workbenchRevisionDiffRemoved, "redis") {
+		t.Fatalf("expected revert diff to remove redis, got %#v", detail.Diff)
+	}
+	if _, err := svc.GetRevision(context.Background(), "demo", mutated.Revision); err != nil {
+		t.Fatalf("expected history before revert to remain, got %v", err)
+	}
+
+	_, err = svc.GetRevision(context.Background(), "demo", 99)
+	if typed, ok := errs.From(err); !ok || typed.Code != errs.CodeWorkbenchRevisionNotFound {
+		t.Fatalf("expected %q, got %v", errs.CodeWorkbenchRevisionNotFound, err)
+	}
+}
+
+func TestWorkbenchMutationFailsWithoutRevisionRow(t *testing.T) {
+	t.Parallel()
+
+	revisions := &fakeWorkbenchRevisionRepo{}
+	snapshots := &fakeWorkbenchSnapshotRepo{revisions: revisions}
+	svc := NewWorkbenchServiceWithStorage(t.TempDir(), nil, &fakeSettingsRepo{}, "test-session-secret")
+	svc.SetSnapshotRepository(snapshots)
+	svc.SetRevisionRepository(revisions)
+
+	initial := WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Revision:    2,
+		Services:    []WorkbenchComposeService{{ServiceName: "api", Image: "nginx:1.25"}},
+	}
+	if err := svc.saveWorkbenchSnapshot(context.Background(), "demo", initial); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+
+	snapshots.revisionErr = errors.New("disk I/O error")
+	if _, _, err := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "redis"}); err == nil {
+		t.Fatal("expected mutation to fail when the revision row cannot be written")
+	}
+	stored, _, err := svc.loadStoredWorkbenchSnapshot(context.Background(), "demo")
+	if err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if stored.Revision != initial.Revision || len(stored.ManagedServices) != 0 {
+		t.Fatalf("expected snapshot to stay at revision %d, got %#v", initial.Revision, stored)
+	}
+	if len(revisions.entries) != 0 {
+		t.Fatalf("expected no revision rows, got %#v", revisions.entries)
+	}
+
+	snapshots.revisionErr = nil
+	mutated, _, err := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "redis"})
+	if err != nil {
+		t.Fatalf("add redis: %v", err)
+	}
+	if len(revisions.entries) != 1 || revisions.entries[0].Revision != mutated.Revision {
+		t.Fatalf("expected one revision row for revision %d, got %#v", mutated.Revision, revisions.entries)
+	}
+}
+
+func TestDiffWorkbenchSnapshotsReportsChangedEntries(t *testing.T) {
+	t.Parallel()
+
+	before := WorkbenchStackSnapshot{
+		Services:  []WorkbenchComposeService{{ServiceName: "api", Image: "nginx:1.25"}},
+		Resources: []WorkbenchComposeResource{{ServiceName: "api", LimitMemory: "256m"}},
+	}
+	after := WorkbenchStackSnapshot{
+		Services: []WorkbenchComposeService{{ServiceName: "api", Image: "nginx:1.26"}},
+	}
+
+	diff := diffWorkbenchSnapshots(before, after)
+	if len(diff) != 2 {
+		t.Fatalf("expected 2 diff entries, got %#v", diff)
+	}
+	if !workbenchRevisionDiffHas(diff, "services", workbenchRevisionDiffChanged, "api") {
+		t.Fatalf("expected changed service entry, got %#v", diff)
+	}
+	if !workbenchRevisionDiffHas(diff, "resources", workbenchRevisionDiffRemoved, "api") {
+		t.Fatalf("expected removed resource entry, got %#v", diff)
+	}
+}
+
+func workbenchRevisionDiffHas(diff []WorkbenchRevisionDiffEntry, section, op, key string) bool {
+	for _, entry := range diff {
+		if entry.Section == section && entry.Op == op && entry.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -13,11 +13,15 @@ import (
 )
 
 const (
-	workbenchModelVersion = 1
+	// Version 2 added top-level network definitions. Older snapshots never
+	// recorded them, so apply leaves their declared networks untouched.
+	workbenchModelVersion = 2
 
 	workbenchImportReasonManual       = "manual"
 	workbenchImportReasonAutoDeploy   = "auto_deploy"
 	workbenchImportReasonAutoRedeploy = "auto_redeploy"
+	workbenchImportReasonBundle       = "bundle"
+	workbenchImportReasonClone        = "clone"
 )
 
 type WorkbenchStackModule struct {
@@ -28,6 +32,7 @@ type WorkbenchStackModule struct {
 type WorkbenchManagedService struct {
 	EntryKey    string `json:"entryKey"`
 	ServiceName string `json:"serviceName"`
+	AppService  string `json:"appService,omitempty"`
 }
 
 type WorkbenchStackSnapshot struct {
@@ -41,12 +46,14 @@ type WorkbenchStackSnapshot struct {
 	Dependencies      []WorkbenchComposeDependency `json:"dependencies"`
 	Ports             []WorkbenchComposePort       `json:"ports"`
 	Resources         []WorkbenchComposeResource   `json:"resources"`
+	Networks          []WorkbenchComposeNetwork    `json:"networks"`
 	NetworkRefs       []WorkbenchComposeNetworkRef `json:"networkRefs"`
 	VolumeRefs        []WorkbenchComposeVolumeRef  `json:"volumeRefs"`
 	EnvRefs           []WorkbenchComposeEnvRef     `json:"envRefs"`
 	ManagedServices   []WorkbenchManagedService    `json:"managedServices"`
 	Modules           []WorkbenchStackModule       `json:"modules"`
 	Warnings          []WorkbenchComposeWarning    `json:"warnings"`
+	PolicyOverrides   []WorkbenchPolicyOverride    `json:"policyOverrides,omitempty"`
 }
 
 type workbenchStoredSnapshot = WorkbenchStackSnapshot
@@ -60,15 +67,30 @@ func (s *WorkbenchService) ImportComposeSnapshot(
 	if err != nil {
 		return WorkbenchStackSnapshot{}, false, err
 	}
-	if _, err := normalizeWorkbenchImportReason(reason); err != nil {
+	normalizedReason, err := normalizeWorkbenchImportReason(reason)
+	if err != nil {
 		return WorkbenchStackSnapshot{}, false, err
 	}
 
-	source, release, err := s.ResolveComposeSourceWithLock(ctx, normalizedProject)
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
 	if err != nil {
 		return WorkbenchStackSnapshot{}, false, err
 	}
 	defer release()
+	return s.importComposeSnapshotLocked(ctx, normalizedProject, normalizedReason)
+}
+
+// importComposeSnapshotLocked is ImportComposeSnapshot for callers that
+// already hold the project lock.
+func (s *WorkbenchService) importComposeSnapshotLocked(
+	ctx context.Context,
+	normalizedProject string,
+	normalizedReason string,
+) (WorkbenchStackSnapshot, bool, error) {
+	source, err := s.ResolveComposeSource(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, false, err
+	}
 
 	parsed, err := s.ParseComposeCoreFromSource(source)
 	if err != nil {
@@ -80,6 +102,7 @@ func (s *WorkbenchService) ImportComposeSnapshot(
 		return WorkbenchStackSnapshot{}, false, err
 	}
 	if exists && current.SourceFingerprint == parsed.SourceFingerprint {
+		s.storeWorkbenchComposeBase(ctx, source.ProjectDir, []byte(source.Normalized))
 		return current, false, nil
 	}
 
@@ -87,10 +110,14 @@ func (s *WorkbenchService) ImportComposeSnapshot(
 	if exists && current.Revision > 0 {
 		next.Revision = current.Revision + 1
 	}
+	if exists {
+		next.PolicyOverrides = current.PolicyOverrides
+	}
 
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, next); err != nil {
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, current, next, WorkbenchMutationImport, normalizedReason); err != nil {
 		return WorkbenchStackSnapshot{}, false, err
 	}
+	s.storeWorkbenchComposeBase(ctx, source.ProjectDir, []byte(source.Normalized))
 	return next, true, nil
 }
 
@@ -100,7 +127,7 @@ func normalizeWorkbenchImportReason(reason string) (string, error) {
 		return workbenchImportReasonManual, nil
 	}
 	switch normalized {
-	case workbenchImportReasonManual, workbenchImportReasonAutoDeploy, workbenchImportReasonAutoRedeploy:
+	case workbenchImportReasonManual, workbenchImportReasonAutoDeploy, workbenchImportReasonAutoRedeploy, workbenchImportReasonBundle, workbenchImportReasonClone:
 		return normalized, nil
 	default:
 		return "", errs.New(errs.CodeProjectInvalidBody, fmt.Sprintf("invalid workbench import reason %q", reason))
@@ -119,6 +146,7 @@ func snapshotFromParsedCompose(parsed WorkbenchComposeParseResult) WorkbenchStac
 		Dependencies:      append([]WorkbenchComposeDependency{}, parsed.Dependencies...),
 		Ports:             append([]WorkbenchComposePort{}, parsed.Ports...),
 		Resources:         append([]WorkbenchComposeResource{}, parsed.Resources...),
+		Networks:          append([]WorkbenchComposeNetwork{}, parsed.Networks...),
 		NetworkRefs:       append([]WorkbenchComposeNetworkRef{}, parsed.NetworkRefs...),
 		VolumeRefs:        append([]WorkbenchComposeVolumeRef{}, parsed.VolumeRefs...),
 		EnvRefs:           append([]WorkbenchComposeEnvRef{}, parsed.EnvRefs...),
@@ -153,6 +181,9 @@ func normalizeWorkbenchStackSnapshot(snapshot WorkbenchStackSnapshot) WorkbenchS
 	if normalized.Resources == nil {
 		normalized.Resources = []WorkbenchComposeResource{}
 	}
+	if normalized.Networks == nil {
+		normalized.Networks = []WorkbenchComposeNetwork{}
+	}
 	if normalized.NetworkRefs == nil {
 		normalized.NetworkRefs = []WorkbenchComposeNetworkRef{}
 	}
@@ -172,6 +203,8 @@ func normalizeWorkbenchStackSnapshot(snapshot WorkbenchStackSnapshot) WorkbenchS
 		normalized.Warnings = []WorkbenchComposeWarning{}
 	}
 
+	normalized.PolicyOverrides = normalizeWorkbenchPolicyOverrides(normalized.PolicyOverrides)
+
 	for idx := range normalized.Ports {
 		normalized.Ports[idx] = normalizeWorkbenchComposePort(normalized.Ports[idx])
 	}
@@ -186,6 +219,7 @@ func normalizeWorkbenchStackSnapshot(snapshot WorkbenchStackSnapshot) WorkbenchS
 			cleaned = append(cleaned, WorkbenchManagedService{
 				EntryKey:    entryKey,
 				ServiceName: serviceName,
+				AppService:  strings.TrimSpace(managedService.AppService),
 			})
 		}
 		normalized.ManagedServices = cleaned
@@ -203,6 +237,13 @@ func normalizeWorkbenchStackSnapshot(snapshot WorkbenchStackSnapshot) WorkbenchS
 	sort.SliceStable(normalized.Resources, func(i, j int) bool {
 		return workbenchComposeResourceLess(normalized.Resources[i], normalized.Resources[j])
 	})
+	for idx := range normalized.Networks {
+		normalized.Networks[idx].Name = strings.TrimSpace(normalized.Networks[idx].Name)
+		normalized.Networks[idx].Driver = strings.ToLower(strings.TrimSpace(normalized.Networks[idx].Driver))
+	}
+	sort.SliceStable(normalized.Networks, func(i, j int) bool {
+		return normalized.Networks[i].Name < normalized.Networks[j].Name
+	})
 	sort.SliceStable(normalized.NetworkRefs, func(i, j int) bool {
 		return workbenchComposeNetworkRefLess(normalized.NetworkRefs[i], normalized.NetworkRefs[j])
 	})
@@ -272,6 +313,9 @@ func (s *WorkbenchService) loadStoredWorkbenchSnapshot(
 	ctx context.Context,
 	projectName string,
 ) (WorkbenchStackSnapshot, bool, error) {
+	if s.snapshots != nil {
+		return s.loadTableWorkbenchSnapshot(ctx, projectName)
+	}
 	if s.settings == nil {
 		return WorkbenchStackSnapshot{}, false, workbenchStorageError(projectName, "workbench settings storage is unavailable", nil)
 	}
@@ -310,6 +354,22 @@ func (s *WorkbenchService) saveWorkbenchSnapshot(
 	projectName string,
 	snapshot WorkbenchStackSnapshot,
 ) error {
+	return s.saveWorkbenchSnapshotExpected(ctx, projectName, snapshot, workbenchSnapshotAnyRevision)
+}
+
+// saveWorkbenchSnapshotExpected persists snapshot only if the stored revision
+// still equals expectedRevision (0 when no snapshot exists yet), so writers in
+// other processes cannot silently overwrite each other.
+func (s *WorkbenchService) saveWorkbenchSnapshotExpected(
+	ctx context.Context,
+	projectName string,
+	snapshot WorkbenchStackSnapshot,
+	expectedRevision int,
+) error {
+	if s.snapshots != nil {
+		return s.saveTableWorkbenchSnapshot(ctx, projectName, snapshot, expectedRevision, nil)
+	}
+
 	settingsWriteLock.Lock()
 	defer settingsWriteLock.Unlock()
 
@@ -335,6 +395,15 @@ func (s *WorkbenchService) saveWorkbenchSnapshot(
 	if payload.Workbench == nil {
 		payload.Workbench = map[string]workbenchStoredSnapshot{}
 	}
+	if expectedRevision >= 0 {
+		storedRevision := 0
+		if current, ok := payload.Workbench[projectName]; ok {
+			storedRevision = normalizeWorkbenchStackSnapshot(current).Revision
+		}
+		if storedRevision != expectedRevision {
+			return workbenchSnapshotConflictError(projectName, expectedRevision, storedRevision)
+		}
+	}
 	normalized := normalizeWorkbenchStackSnapshot(snapshot)
 	normalized.ProjectName = projectName
 	payload.Workbench[projectName] = normalized
@@ -351,6 +420,54 @@ func (s *WorkbenchService) saveWorkbenchSnapshot(
 	return nil
 }
 
+// deleteWorkbenchSnapshot drops the stored snapshot of projectName, if any.
+func (s *WorkbenchService) deleteWorkbenchSnapshot(ctx context.Context, projectName string) error {
+	if s.snapshots != nil {
+		if err := s.snapshots.Delete(ctx, projectName); err != nil {
+			return workbenchStorageError(projectName, "failed to delete workbench snapshot", err)
+		}
+		return nil
+	}
+
+	settingsWriteLock.Lock()
+	defer settingsWriteLock.Unlock()
+
+	if s.settings == nil {
+		return workbenchStorageError(projectName, "workbench settings storage is unavailable", nil)
+	}
+	stored, err := s.settings.Get(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return workbenchStorageError(projectName, "failed to load workbench settings payload", err)
+	}
+	if stored == nil {
+		return nil
+	}
+	payload, err := loadSettingsEncryptedPayload(s.sessionSecret, stored.NetBirdConfigEncrypted)
+	if err != nil {
+		if isSettingsPayloadDecryptMismatch(err) {
+			return nil
+		}
+		return workbenchStorageError(projectName, "failed to decode workbench settings payload", err)
+	}
+	if _, ok := payload.Workbench[projectName]; !ok {
+		return nil
+	}
+	delete(payload.Workbench, projectName)
+
+	encoded, err := encodeSettingsEncryptedPayload(s.sessionSecret, payload)
+	if err != nil {
+		return workbenchStorageError(projectName, "failed to encode workbench settings payload", err)
+	}
+	stored.NetBirdConfigEncrypted = encoded
+	if err := s.settings.Save(ctx, stored); err != nil {
+		return workbenchStorageError(projectName, "failed to persist workbench settings payload", err)
+	}
+	return nil
+}
+
 func workbenchStorageError(projectName, message string, cause error) error {
 	details := map[string]any{
 		"project": strings.ToLower(strings.TrimSpace(projectName)),
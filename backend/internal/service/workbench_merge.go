@@ -0,0 +1,447 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-notes/internal/errs"
+)
+
+const (
+	workbenchComposeBaseRelative = ".gungnr/workbench/compose-base.yml"
+
+	workbenchMergeConflictBothModified      = "both_modified"
+	workbenchMergeConflictBothAdded         = "both_added"
+	workbenchMergeConflictDeletedOnDisk     = "deleted_on_disk"
+	workbenchMergeConflictDeletedInSnapshot = "deleted_in_snapshot"
+)
+
+// WorkbenchMergeConflict is one entry (or one field of an entry) that was
+// changed both in the compose file on disk and in the workbench snapshot since
+// the imported base.
+type WorkbenchMergeConflict struct {
+	Section  string          `json:"section"`
+	Key      string          `json:"key"`
+	Service  string          `json:"service,omitempty"`
+	Field    string          `json:"field,omitempty"`
+	Kind     string          `json:"kind"`
+	Base     json.RawMessage `json:"base,omitempty"`
+	Disk     json.RawMessage `json:"disk,omitempty"`
+	Snapshot json.RawMessage `json:"snapshot,omitempty"`
+}
+
+// WorkbenchMergeReport describes a three-way merge between the imported base,
+// the compose file on disk and the stored snapshot. Merged lists the on-disk
+// changes that were folded into the snapshot.
+type WorkbenchMergeReport struct {
+	BaseFingerprint string                       `json:"baseFingerprint"`
+	DiskFingerprint string                       `json:"diskFingerprint"`
+	BaseAvailable   bool                         `json:"baseAvailable"`
+	Merged          []WorkbenchRevisionDiffEntry `json:"merged"`
+	Conflicts       []WorkbenchMergeConflict     `json:"conflicts"`
+}
+
+func (r *WorkbenchMergeReport) Clean() bool {
+	return r != nil && r.BaseAvailable && len(r.Conflicts) == 0
+}
+
+// threeWayMergeDrift folds on-disk compose edits into snapshot when the file
+// no longer matches the stored fingerprint. The report is nil when there is no
+// drift; callers decide whether an unclean report blocks the operation.
+func (s *WorkbenchService) threeWayMergeDrift(
+	snapshot WorkbenchStackSnapshot,
+	source WorkbenchComposeSource,
+) (WorkbenchStackSnapshot, *WorkbenchMergeReport, error) {
+	baseFingerprint := strings.TrimSpace(snapshot.SourceFingerprint)
+	if baseFingerprint == strings.TrimSpace(source.Fingerprint) {
+		return snapshot, nil, nil
+	}
+
+	report := &WorkbenchMergeReport{
+		BaseFingerprint: baseFingerprint,
+		DiskFingerprint: strings.TrimSpace(source.Fingerprint),
+		Merged:          []WorkbenchRevisionDiffEntry{},
+		Conflicts:       []WorkbenchMergeConflict{},
+	}
+
+	baseSource, ok := loadWorkbenchComposeBase(source.ProjectDir, baseFingerprint)
+	if !ok {
+		return snapshot, report, nil
+	}
+	parsedBase, err := ParseWorkbenchComposeCore(baseSource)
+	if err != nil {
+		return snapshot, report, nil
+	}
+	parsedDisk, err := s.ParseComposeCoreFromSource(source)
+	if err != nil {
+		return snapshot, nil, err
+	}
+	report.BaseAvailable = true
+
+	merged, conflicts := mergeWorkbenchSnapshotsThreeWay(
+		snapshotFromParsedCompose(parsedBase),
+		snapshotFromParsedCompose(parsedDisk),
+		snapshot,
+	)
+	merged.SourceFingerprint = report.DiskFingerprint
+	report.Conflicts = conflicts
+	report.Merged = diffWorkbenchSnapshots(snapshot, merged)
+	return merged, report, nil
+}
+
+// mergeWorkbenchSnapshotsThreeWay merges the compose-derived sections of disk
+// into ours relative to base. Workbench-only state (managed services, modules,
+// policy overrides) always comes from ours.
+func mergeWorkbenchSnapshotsThreeWay(base, disk, ours WorkbenchStackSnapshot) (WorkbenchStackSnapshot, []WorkbenchMergeConflict) {
+	merged := ours
+	conflicts := []WorkbenchMergeConflict{}
+	collect := func(sectionConflicts []WorkbenchMergeConflict) {
+		conflicts = append(conflicts, sectionConflicts...)
+	}
+
+	var sectionConflicts []WorkbenchMergeConflict
+	merged.Services, sectionConflicts = workbenchThreeWayMergeSection("services", base.Services, disk.Services, ours.Services, func(item WorkbenchComposeService) string {
+		return item.ServiceName
+	})
+	collect(sectionConflicts)
+	merged.Dependencies, sectionConflicts = workbenchThreeWayMergeSection("dependencies", base.Dependencies, disk.Dependencies, ours.Dependencies, func(item WorkbenchComposeDependency) string {
+		return item.ServiceName + "->" + item.DependsOn
+	})
+	collect(sectionConflicts)
+	merged.Ports, sectionConflicts = workbenchThreeWayMergeSection("ports", base.Ports, disk.Ports, ours.Ports, func(item WorkbenchComposePort) string {
+		return fmt.Sprintf("%s:%d/%s", item.ServiceName, item.ContainerPort, item.Protocol)
+	})
+	collect(sectionConflicts)
+	merged.Resources, sectionConflicts = workbenchThreeWayMergeSection("resources", base.Resources, disk.Resources, ours.Resources, func(item WorkbenchComposeResource) string {
+		return item.ServiceName
+	})
+	collect(sectionConflicts)
+	oursNetworks := ours.Networks
+	if ours.ModelVersion < workbenchModelVersion {
+		// Older snapshots never tracked network definitions; treat them as
+		// unchanged from base rather than deleted.
+		oursNetworks = base.Networks
+	}
+	merged.Networks, sectionConflicts = workbenchThreeWayMergeSection("networks", base.Networks, disk.Networks, oursNetworks, func(item WorkbenchComposeNetwork) string {
+		return item.Name
+	})
+	collect(sectionConflicts)
+	merged.NetworkRefs, sectionConflicts = workbenchThreeWayMergeSection("networkRefs", base.NetworkRefs, disk.NetworkRefs, ours.NetworkRefs, func(item WorkbenchComposeNetworkRef) string {
+		return item.ServiceName + "/" + item.NetworkName
+	})
+	collect(sectionConflicts)
+	merged.VolumeRefs, sectionConflicts = workbenchThreeWayMergeSection("volumeRefs", base.VolumeRefs, disk.VolumeRefs, ours.VolumeRefs, func(item WorkbenchComposeVolumeRef) string {
+		return item.ServiceName + "/" + item.VolumeName
+	})
+	collect(sectionConflicts)
+	merged.EnvRefs, sectionConflicts = workbenchThreeWayMergeSection("envRefs", base.EnvRefs, disk.EnvRefs, ours.EnvRefs, func(item WorkbenchComposeEnvRef) string {
+		return item.ServiceName + ":" + item.Path + ":" + item.Variable
+	})
+	collect(sectionConflicts)
+	merged.Warnings = append([]WorkbenchComposeWarning{}, disk.Warnings...)
+
+	return normalizeWorkbenchStackSnapshot(merged), conflicts
+}
+
+func workbenchThreeWayMergeSection[T any](
+	section string,
+	base, disk, ours []T,
+	key func(T) string,
+) ([]T, []WorkbenchMergeConflict) {
+	baseByKey := workbenchDiffIndex(base, key)
+	diskByKey := workbenchDiffIndex(disk, key)
+	oursByKey := workbenchDiffIndex(ours, key)
+
+	keySet := map[string]struct{}{}
+	for _, indexed := range []map[string]json.RawMessage{baseByKey, diskByKey, oursByKey} {
+		for itemKey := range indexed {
+			keySet[itemKey] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for itemKey := range keySet {
+		keys = append(keys, itemKey)
+	}
+	sort.Strings(keys)
+
+	merged := make([]T, 0, len(keys))
+	conflicts := []WorkbenchMergeConflict{}
+	keep := func(raw json.RawMessage) {
+		var item T
+		if err := json.Unmarshal(raw, &item); err == nil {
+			merged = append(merged, item)
+		}
+	}
+
+	for _, itemKey := range keys {
+		baseValue, inBase := baseByKey[itemKey]
+		diskValue, inDisk := diskByKey[itemKey]
+		oursValue, inOurs := oursByKey[itemKey]
+
+		var result json.RawMessage
+		var present bool
+		switch {
+		case workbenchMergeSameValue(diskValue, inDisk, baseValue, inBase):
+			result, present = oursValue, inOurs
+		case workbenchMergeSameValue(oursValue, inOurs, baseValue, inBase):
+			result, present = diskValue, inDisk
+		case workbenchMergeSameValue(diskValue, inDisk, oursValue, inOurs):
+			result, present = oursValue, inOurs
+		case inBase && inDisk && inOurs:
+			fieldMerged, fieldConflicts := workbenchThreeWayMergeFields(section, itemKey, baseValue, diskValue, oursValue)
+			if len(fieldConflicts) > 0 {
+				conflicts = append(conflicts, fieldConflicts...)
+				result, present = oursValue, true
+				break
+			}
+			result, present = fieldMerged, true
+		default:
+			conflicts = append(conflicts, WorkbenchMergeConflict{
+				Section:  section,
+				Key:      itemKey,
+				Service:  workbenchMergeServiceName(diskValue, oursValue, baseValue),
+				Kind:     workbenchMergeEntryConflictKind(inBase, inDisk, inOurs),
+				Base:     baseValue,
+				Disk:     diskValue,
+				Snapshot: oursValue,
+			})
+			result, present = oursValue, inOurs
+		}
+		if present {
+			keep(result)
+		}
+	}
+	return merged, conflicts
+}
+
+// workbenchThreeWayMergeFields retries a both-sides-modified entry field by
+// field so that edits to different attributes of the same service still merge.
+func workbenchThreeWayMergeFields(
+	section string,
+	itemKey string,
+	baseValue, diskValue, oursValue json.RawMessage,
+) (json.RawMessage, []WorkbenchMergeConflict) {
+	var baseFields, diskFields, oursFields map[string]json.RawMessage
+	if json.Unmarshal(baseValue, &baseFields) != nil ||
+		json.Unmarshal(diskValue, &diskFields) != nil ||
+		json.Unmarshal(oursValue, &oursFields) != nil {
+		return nil, []WorkbenchMergeConflict{{
+			Section:  section,
+			Key:      itemKey,
+			Kind:     workbenchMergeConflictBothModified,
+			Base:     baseValue,
+			Disk:     diskValue,
+			Snapshot: oursValue,
+		}}
+	}
+
+	fieldSet := map[string]struct{}{}
+	for _, fields := range []map[string]json.RawMessage{baseFields, diskFields, oursFields} {
+		for field := range fields {
+			fieldSet[field] = struct{}{}
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	service := workbenchMergeServiceName(diskValue, oursValue, baseValue)
+	merged := map[string]json.RawMessage{}
+	conflicts := []WorkbenchMergeConflict{}
+	for _, field := range fields {
+		baseField, inBase := baseFields[field]
+		diskField, inDisk := diskFields[field]
+		oursField, inOurs := oursFields[field]
+
+		switch {
+		case workbenchMergeSameValue(diskField, inDisk, baseField, inBase),
+			workbenchMergeSameValue(diskField, inDisk, oursField, inOurs):
+			if inOurs {
+				merged[field] = oursField
+			}
+		case workbenchMergeSameValue(oursField, inOurs, baseField, inBase):
+			if inDisk {
+				merged[field] = diskField
+			}
+		default:
+			conflicts = append(conflicts, WorkbenchMergeConflict{
+				Section:  section,
+				Key:      itemKey,
+				Service:  service,
+				Field:    field,
+				Kind:     workbenchMergeEntryConflictKind(inBase, inDisk, inOurs),
+				Base:     baseField,
+				Disk:     diskField,
+				Snapshot: oursField,
+			})
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return nil, []WorkbenchMergeConflict{{
+			Section:  section,
+			Key:      itemKey,
+			Service:  service,
+			Kind:     workbenchMergeConflictBothModified,
+			Base:     baseValue,
+			Disk:     diskValue,
+			Snapshot: oursValue,
+		}}
+	}
+	return encoded, nil
+}
+
+func workbenchMergeSameValue(left json.RawMessage, inLeft bool, right json.RawMessage, inRight bool) bool {
+	if inLeft != inRight {
+		return false
+	}
+	return !inLeft || bytes.Equal(left, right)
+}
+
+func workbenchMergeEntryConflictKind(inBase, inDisk, inOurs bool) string {
+	switch {
+	case !inBase:
+		return workbenchMergeConflictBothAdded
+	case !inDisk:
+		return workbenchMergeConflictDeletedOnDisk
+	case !inOurs:
+		return workbenchMergeConflictDeletedInSnapshot
+	default:
+		return workbenchMergeConflictBothModified
+	}
+}
+
+func workbenchMergeServiceName(values ...json.RawMessage) string {
+	for _, value := range values {
+		if len(value) == 0 {
+			continue
+		}
+		var item struct {
+			ServiceName string `json:"serviceName"`
+		}
+		if err := json.Unmarshal(value, &item); err == nil && strings.TrimSpace(item.ServiceName) != "" {
+			return strings.TrimSpace(item.ServiceName)
+		}
+	}
+	return ""
+}
+
+// storeWorkbenchComposeBase keeps the compose content the snapshot was last
+// imported from or applied to, so later drift can be merged against it. A
+// failed write only disables merging, it does not fail the caller.
+func (s *WorkbenchService) storeWorkbenchComposeBase(ctx context.Context, projectDir string, content []byte) {
+	basePath, err := resolveWorkbenchComposeBackupArtifactPath(projectDir, workbenchComposeBaseRelative)
+	if err != nil {
+		log.Printf("warn: workbench compose base path invalid for %q: %v", projectDir, err)
+		return
+	}
+	if existing, readErr := os.ReadFile(basePath); readErr == nil && bytes.Equal(existing, content) {
+		return
+	}
+	if err := s.writeWorkbenchFileAtomically(ctx, projectDir, basePath, content, 0o600, true); err != nil {
+		log.Printf("warn: workbench compose base write failed for %q: %v", projectDir, err)
+	}
+}
+
+func (s *WorkbenchService) dropWorkbenchComposeBase(ctx context.Context, projectDir string) {
+	basePath, err := resolveWorkbenchComposeBackupArtifactPath(projectDir, workbenchComposeBaseRelative)
+	if err != nil {
+		return
+	}
+	if err := s.removeWorkbenchPath(ctx, projectDir, basePath, true); err != nil {
+		log.Printf("warn: workbench compose base removal failed for %q: %v", projectDir, err)
+	}
+}
+
+// loadWorkbenchComposeBase returns the stored base only when it still hashes
+// to fingerprint; a stale or missing base means no merge is possible.
+func loadWorkbenchComposeBase(projectDir, fingerprint string) (string, bool) {
+	if strings.TrimSpace(fingerprint) == "" {
+		return "", false
+	}
+	basePath, err := resolveWorkbenchComposeBackupArtifactPath(projectDir, workbenchComposeBaseRelative)
+	if err != nil {
+		return "", false
+	}
+	raw, err := os.ReadFile(basePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("warn: workbench compose base read failed for %q: %v", projectDir, err)
+		}
+		return "", false
+	}
+	normalized, baseFingerprint := WorkbenchSourceFingerprint(raw)
+	if baseFingerprint != fingerprint {
+		return "", false
+	}
+	return normalized, true
+}
+
+func workbenchDriftMergeError(
+	snapshot WorkbenchStackSnapshot,
+	source WorkbenchComposeSource,
+	report *WorkbenchMergeReport,
+) error {
+	issues := make([]WorkbenchValidationIssue, 0, len(report.Conflicts)+2)
+	issues = append(issues, WorkbenchValidationIssue{
+		Class:   workbenchValidationClassSchema,
+		Code:    "WB-DRIFT-COMPOSE-SOURCE-MISMATCH",
+		Path:    "$.composeSource",
+		Message: fmt.Sprintf("compose source fingerprint %q does not match stored workbench fingerprint %q", report.DiskFingerprint, report.BaseFingerprint),
+	})
+	if !report.BaseAvailable {
+		issues = append(issues, WorkbenchValidationIssue{
+			Class:   workbenchValidationClassSchema,
+			Code:    "WB-DRIFT-MERGE-BASE-MISSING",
+			Path:    "$.composeSource",
+			Message: "imported compose base is not available for a three-way merge; re-import the project compose",
+		})
+	}
+	for _, conflict := range report.Conflicts {
+		path := fmt.Sprintf("$.%s[%q]", conflict.Section, conflict.Key)
+		subject := conflict.Section + " entry " + strconv.Quote(conflict.Key)
+		if conflict.Field != "" {
+			path += "." + conflict.Field
+			subject = fmt.Sprintf("field %q of %s", conflict.Field, subject)
+		}
+		issues = append(issues, WorkbenchValidationIssue{
+			Class:   workbenchValidationClassSchema,
+			Code:    "WB-DRIFT-MERGE-CONFLICT",
+			Path:    path,
+			Service: conflict.Service,
+			Message: fmt.Sprintf("%s changed on disk and in the workbench snapshot (%s)", subject, conflict.Kind),
+		})
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return workbenchValidationIssueLess(issues[i], issues[j])
+	})
+
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchDriftDetected, "workbench apply blocked by compose drift"),
+		map[string]any{
+			"project":                  strings.TrimSpace(snapshot.ProjectName),
+			"composePath":              strings.TrimSpace(source.ComposePath),
+			"projectPath":              strings.TrimSpace(source.ProjectDir),
+			"revision":                 snapshot.Revision,
+			"sourceFingerprint":        report.BaseFingerprint,
+			"currentSourceFingerprint": report.DiskFingerprint,
+			"merge":                    report,
+			"issueCount":               len(issues),
+			"issues":                   issues,
+		},
+	)
+}
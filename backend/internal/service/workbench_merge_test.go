@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-notes/internal/errs"
+)
+
+func TestWorkbenchApplyMergesNonConflictingDiskEdits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	composePath := filepath.Join(projectDir, "docker-compose.yml")
+	source := "services:\n  api:\n    image: nginx:1.25\n    restart: always\n  worker:\n    image: busybox:1.36\n"
+	if err := os.WriteFile(composePath, []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	revisions := &fakeWorkbenchRevisionRepo{}
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	svc.SetRevisionRepository(revisions)
+	imported, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual")
+	if err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+
+	imported.Services[0].Image = "nginx:1.26"
+	if err := svc.saveWorkbenchSnapshot(ctx, "demo", imported); err != nil {
+		t.Fatalf("save mutated snapshot: %v", err)
+	}
+
+	edited := "services:\n  api:\n    image: nginx:1.25\n    restart: unless-stopped\n  worker:\n    image: busybox:1.37\n"
+	if err := os.WriteFile(composePath, []byte(edited), 0o644); err != nil {
+		t.Fatalf("write external compose change: %v", err)
+	}
+
+	status, _, err := svc.CheckDrift(ctx, "demo")
+	if err != nil {
+		t.Fatalf("check drift: %v", err)
+	}
+	if !status.Drifted || !status.Mergeable || status.ConflictCount != 0 {
+		t.Fatalf("expected mergeable drift, got %#v", status)
+	}
+
+	expectedRevision := imported.Revision
+	result, err := svc.ApplyComposeFromStoredSnapshot(ctx, "demo", WorkbenchComposeApplyRequest{
+		ExpectedRevision:          &expectedRevision,
+		ExpectedSourceFingerprint: imported.SourceFingerprint,
+	})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if result.Merge == nil || !workbenchRevisionDiffHas(result.Merge.Merged, "services", workbenchRevisionDiffChanged, "worker") {
+		t.Fatalf("expected worker edit in merge report, got %#v", result.Merge)
+	}
+	if result.Metadata.Revision != imported.Revision+1 {
+		t.Fatalf("expected merge to create revision %d, got %d", imported.Revision+1, result.Metadata.Revision)
+	}
+
+	written, err := os.ReadFile(composePath)
+	if err != nil {
+		t.Fatalf("read compose: %v", err)
+	}
+	for _, want := range []string{"nginx:1.26", "restart: unless-stopped", "busybox:1.37"} {
+		if !strings.Contains(string(written), want) {
+			t.Fatalf("expected merged compose to contain %q, got:\n%s", want, string(written))
+		}
+	}
+
+	stored := loadWorkbenchSnapshotForTest(t, svc, ctx, "demo")
+	if stored.SourceFingerprint != result.Metadata.SourceFingerprint {
+		t.Fatalf("expected stored fingerprint %q, got %q", result.Metadata.SourceFingerprint, stored.SourceFingerprint)
+	}
+	latest, err := svc.ListRevisions(ctx, "demo", 1)
+	if err != nil || len(latest) != 1 || latest[0].MutationType != WorkbenchMutationDriftMerge {
+		t.Fatalf("expected drift merge revision, got %#v err=%v", latest, err)
+	}
+
+	status, _, err = svc.CheckDrift(ctx, "demo")
+	if err != nil || status.Drifted {
+		t.Fatalf("expected no drift after apply, got %#v err=%v", status, err)
+	}
+}
+
+func TestWorkbenchApplyBlocksDriftWithoutBase(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	composePath := filepath.Join(projectDir, "docker-compose.yml")
+	if err := os.WriteFile(composePath, []byte("services:\n  api:\n    image: nginx:1.25\n"), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	imported, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual")
+	if err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+	if err := os.Remove(filepath.Join(projectDir, filepath.FromSlash(workbenchComposeBaseRelative))); err != nil {
+		t.Fatalf("remove compose base: %v", err)
+	}
+	if err := os.WriteFile(composePath, []byte("services:\n  api:\n    image: nginx:1.27\n"), 0o644); err != nil {
+		t.Fatalf("write external compose change: %v", err)
+	}
+
+	expectedRevision := imported.Revision
+	_, err = svc.ApplyComposeFromStoredSnapshot(ctx, "demo", WorkbenchComposeApplyRequest{
+		ExpectedRevision:          &expectedRevision,
+		ExpectedSourceFingerprint: imported.SourceFingerprint,
+	})
+	typed, ok := errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchDriftDetected {
+		t.Fatalf("expected %q, got %v", errs.CodeWorkbenchDriftDetected, err)
+	}
+	details, _ := typed.Details.(map[string]any)
+	found := false
+	for _, issue := range extractWorkbenchValidationIssues(t, details) {
+		if issue.Code == "WB-DRIFT-MERGE-BASE-MISSING" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected base-missing issue, got %#v", details["issues"])
+	}
+}
+
+func TestWorkbenchScanDriftFlagsChangedProjects(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	svc.SetSnapshotRepository(&fakeWorkbenchSnapshotRepo{})
+
+	for _, project := range []string{"alpha", "bravo"} {
+		projectDir := filepath.Join(templatesDir, project)
+		if err := os.MkdirAll(projectDir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", project, err)
+		}
+		if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte("services:\n  api:\n    image: nginx:1.25\n"), 0o644); err != nil {
+			t.Fatalf("write %s compose: %v", project, err)
+		}
+		if _, _, err := svc.ImportComposeSnapshot(ctx, project, "manual"); err != nil {
+			t.Fatalf("import %s: %v", project, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(templatesDir, "bravo", "docker-compose.yml"), []byte("services:\n  api:\n    image: nginx:1.27\n"), 0o644); err != nil {
+		t.Fatalf("write bravo drift: %v", err)
+	}
+
+	if _, ok := svc.LastDriftScan(); ok {
+		t.Fatal("expected no drift scan before the first run")
+	}
+	report, err := svc.ScanDrift(ctx)
+	if err != nil {
+		t.Fatalf("scan drift: %v", err)
+	}
+	if len(report.Projects) != 2 || report.DriftedCount != 1 {
+		t.Fatalf("unexpected scan report: %#v", report)
+	}
+	if report.Projects[0].Project != "alpha" || report.Projects[0].Drifted {
+		t.Fatalf("expected alpha in sync, got %#v", report.Projects[0])
+	}
+	if report.Projects[1].Project != "bravo" || !report.Projects[1].Drifted || !report.Projects[1].Mergeable {
+		t.Fatalf("expected bravo drifted and mergeable, got %#v", report.Projects[1])
+	}
+
+	cached, ok := svc.LastDriftScan()
+	if !ok || cached.DriftedCount != 1 {
+		t.Fatalf("expected cached scan, got %#v ok=%v", cached, ok)
+	}
+}
+
+func TestMergeWorkbenchSnapshotsThreeWayReportsFieldConflicts(t *testing.T) {
+	t.Parallel()
+
+	base := WorkbenchStackSnapshot{
+		Services:  []WorkbenchComposeService{{ServiceName: "api", Image: "nginx:1.25", RestartPolicy: "always"}},
+		Resources: []WorkbenchComposeResource{{ServiceName: "api", LimitMemory: "256m"}},
+	}
+	disk := WorkbenchStackSnapshot{
+		Services:  []WorkbenchComposeService{{ServiceName: "api", Image: "nginx:1.27", RestartPolicy: "always"}},
+		Resources: []WorkbenchComposeResource{{ServiceName: "api", LimitMemory: "256m", LimitCPUs: "0.5"}},
+	}
+	ours := WorkbenchStackSnapshot{
+		Services:  []WorkbenchComposeService{{ServiceName: "api", Image: "nginx:1.26", RestartPolicy: "no"}},
+		Resources: []WorkbenchComposeResource{{ServiceName: "api", LimitMemory: "512m"}},
+	}
+
+	merged, conflicts := mergeWorkbenchSnapshotsThreeWay(base, disk, ours)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %#v", conflicts)
+	}
+	conflict := conflicts[0]
+	if conflict.Section != "services" || conflict.Service != "api" || conflict.Field != "image" || conflict.Kind != workbenchMergeConflictBothModified {
+		t.Fatalf("unexpected conflict: %#v", conflict)
+	}
+	if len(merged.Resources) != 1 || merged.Resources[0].LimitMemory != "512m" || merged.Resources[0].LimitCPUs != "0.5" {
+		t.Fatalf("expected field-level resource merge, got %#v", merged.Resources)
+	}
+}
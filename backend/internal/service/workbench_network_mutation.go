@@ -0,0 +1,654 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"go-notes/internal/errs"
+)
+
+const (
+	workbenchNetworkMutationActionCreate = "create"
+	workbenchNetworkMutationActionUpdate = "update"
+	workbenchNetworkMutationActionDelete = "delete"
+	workbenchNetworkMutationActionAttach = "attach"
+
+	// defaultWorkbenchEdgeNetwork is the Docker name of the panel's edge
+	// network, which cloudflared joins to reach published services; anything
+	// attached to it is one hop from ingress.
+	defaultWorkbenchEdgeNetwork = "gungnr_edge"
+)
+
+// workbenchEdgeNetwork holds the configured edge network name. It is process
+// wide, like the optional-service catalog, because compose validation runs
+// without a service instance.
+var workbenchEdgeNetwork atomic.Value
+
+// SetWorkbenchEdgeNetwork sets the Docker name of the network cloudflared
+// shares with the panel for every workbench check in the process. An empty
+// name keeps the default.
+func SetWorkbenchEdgeNetwork(name string) {
+	if name = strings.TrimSpace(name); name == "" {
+		name = defaultWorkbenchEdgeNetwork
+	}
+	workbenchEdgeNetwork.Store(name)
+}
+
+func workbenchEdgeNetworkName() string {
+	if name, ok := workbenchEdgeNetwork.Load().(string); ok && name != "" {
+		return name
+	}
+	return defaultWorkbenchEdgeNetwork
+}
+
+// workbenchNetworkDockerName is the name Docker gives the network the project
+// refers to as key: its name: override, the key itself for an external
+// network, and otherwise the key prefixed with the compose project.
+func workbenchNetworkDockerName(snapshot WorkbenchStackSnapshot, key string) string {
+	key = strings.TrimSpace(key)
+	if index := workbenchFindNetwork(snapshot.Networks, key); index >= 0 {
+		network := snapshot.Networks[index]
+		if name := strings.TrimSpace(network.DockerName); name != "" && !strings.Contains(name, "${") {
+			return name
+		}
+		if network.External {
+			return key
+		}
+	}
+	return workbenchComposeProjectName(nil, snapshot.ProjectName) + "_" + key
+}
+
+// workbenchIsEdgeNetwork reports whether the project's network key resolves to
+// the edge network cloudflared joins.
+func workbenchIsEdgeNetwork(snapshot WorkbenchStackSnapshot, key string) bool {
+	return workbenchNetworkDockerName(snapshot, key) == workbenchEdgeNetworkName()
+}
+
+var workbenchNetworkNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+var workbenchNetworkDrivers = []string{"bridge", "overlay", "macvlan", "ipvlan"}
+
+type WorkbenchNetworkCreateRequest struct {
+	Name     string `json:"name"`
+	Driver   string `json:"driver,omitempty"`
+	Internal bool   `json:"internal,omitempty"`
+}
+
+type WorkbenchNetworkUpdateRequest struct {
+	Driver   *string `json:"driver,omitempty"`
+	Internal *bool   `json:"internal,omitempty"`
+}
+
+type WorkbenchServiceNetworksRequest struct {
+	Attach []string `json:"attach,omitempty"`
+	Detach []string `json:"detach,omitempty"`
+}
+
+type WorkbenchNetworkMutationSummary struct {
+	Changed     bool                     `json:"changed"`
+	Action      string                   `json:"action"`
+	Network     string                   `json:"network,omitempty"`
+	ServiceName string                   `json:"serviceName,omitempty"`
+	Before      *WorkbenchComposeNetwork `json:"before,omitempty"`
+	After       *WorkbenchComposeNetwork `json:"after,omitempty"`
+	Attached    []string                 `json:"attached,omitempty"`
+	Detached    []string                 `json:"detached,omitempty"`
+	Networks    []string                 `json:"networks,omitempty"`
+	Notes       []string                 `json:"notes,omitempty"`
+}
+
+func (s *WorkbenchService) CreateNetwork(
+	ctx context.Context,
+	projectName string,
+	input WorkbenchNetworkCreateRequest,
+) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, error) {
+	summary := WorkbenchNetworkMutationSummary{
+		Action:  workbenchNetworkMutationActionCreate,
+		Network: strings.TrimSpace(input.Name),
+	}
+	network := WorkbenchComposeNetwork{
+		Name:     summary.Network,
+		Driver:   strings.ToLower(strings.TrimSpace(input.Driver)),
+		Internal: input.Internal,
+	}
+	issues := validateWorkbenchNetworkName(summary.Network, summary.Action)
+	issues = append(issues, validateWorkbenchNetworkDriver(network.Driver, summary.Action)...)
+	if len(issues) > 0 {
+		return WorkbenchStackSnapshot{}, summary, workbenchNetworkMutationValidationError(WorkbenchStackSnapshot{}, summary, issues)
+	}
+
+	return s.mutateWorkbenchNetworks(ctx, projectName, summary, func(snapshot WorkbenchStackSnapshot, summary WorkbenchNetworkMutationSummary) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, []WorkbenchMutationIssue) {
+		if index := workbenchFindNetwork(snapshot.Networks, network.Name); index >= 0 {
+			return snapshot, summary, []WorkbenchMutationIssue{{
+				Class:   workbenchMutationIssueClassConflict,
+				Code:    "WB-NETWORK-EXISTS",
+				Path:    "$.name",
+				Message: fmt.Sprintf("network %q is already defined in the stored snapshot", network.Name),
+				Action:  summary.Action,
+			}}
+		}
+		snapshot.Networks = append(snapshot.Networks, network)
+		created := network
+		summary.After = &created
+		return snapshot, summary, nil
+	})
+}
+
+func (s *WorkbenchService) UpdateNetwork(
+	ctx context.Context,
+	projectName string,
+	networkName string,
+	input WorkbenchNetworkUpdateRequest,
+) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, error) {
+	summary := WorkbenchNetworkMutationSummary{
+		Action:  workbenchNetworkMutationActionUpdate,
+		Network: strings.TrimSpace(networkName),
+	}
+	issues := validateWorkbenchNetworkName(summary.Network, summary.Action)
+	if input.Driver != nil {
+		issues = append(issues, validateWorkbenchNetworkDriver(strings.ToLower(strings.TrimSpace(*input.Driver)), summary.Action)...)
+	}
+	if input.Driver == nil && input.Internal == nil {
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:   workbenchMutationIssueClassSchema,
+			Code:    "WB-NETWORK-UPDATE-EMPTY",
+			Path:    "$",
+			Message: "at least one of driver or internal is required",
+			Action:  summary.Action,
+		})
+	}
+	if len(issues) > 0 {
+		return WorkbenchStackSnapshot{}, summary, workbenchNetworkMutationValidationError(WorkbenchStackSnapshot{}, summary, issues)
+	}
+
+	return s.mutateWorkbenchNetworks(ctx, projectName, summary, func(snapshot WorkbenchStackSnapshot, summary WorkbenchNetworkMutationSummary) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, []WorkbenchMutationIssue) {
+		index := workbenchFindNetwork(snapshot.Networks, summary.Network)
+		if index < 0 {
+			return snapshot, summary, []WorkbenchMutationIssue{workbenchNetworkNotFoundIssue(summary.Network, summary.Action)}
+		}
+		before := snapshot.Networks[index]
+		after := before
+		if input.Driver != nil {
+			after.Driver = strings.ToLower(strings.TrimSpace(*input.Driver))
+		}
+		if input.Internal != nil {
+			after.Internal = *input.Internal
+		}
+		snapshot.Networks[index] = after
+		summary.Before = &before
+		summary.After = &after
+		return snapshot, summary, nil
+	})
+}
+
+// DeleteNetwork removes a network definition. Networks that still have
+// services attached are rejected so apply never leaves a dangling reference.
+func (s *WorkbenchService) DeleteNetwork(
+	ctx context.Context,
+	projectName string,
+	networkName string,
+) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, error) {
+	summary := WorkbenchNetworkMutationSummary{
+		Action:  workbenchNetworkMutationActionDelete,
+		Network: strings.TrimSpace(networkName),
+	}
+	if issues := validateWorkbenchNetworkName(summary.Network, summary.Action); len(issues) > 0 {
+		return WorkbenchStackSnapshot{}, summary, workbenchNetworkMutationValidationError(WorkbenchStackSnapshot{}, summary, issues)
+	}
+
+	return s.mutateWorkbenchNetworks(ctx, projectName, summary, func(snapshot WorkbenchStackSnapshot, summary WorkbenchNetworkMutationSummary) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, []WorkbenchMutationIssue) {
+		index := workbenchFindNetwork(snapshot.Networks, summary.Network)
+		if index < 0 {
+			return snapshot, summary, []WorkbenchMutationIssue{workbenchNetworkNotFoundIssue(summary.Network, summary.Action)}
+		}
+		attached := []string{}
+		for _, networkRef := range snapshot.NetworkRefs {
+			if strings.TrimSpace(networkRef.NetworkName) == summary.Network {
+				attached = append(attached, strings.TrimSpace(networkRef.ServiceName))
+			}
+		}
+		if len(attached) > 0 {
+			sort.Strings(attached)
+			return snapshot, summary, []WorkbenchMutationIssue{{
+				Class:   workbenchMutationIssueClassConflict,
+				Code:    "WB-NETWORK-IN-USE",
+				Path:    "$.network",
+				Message: fmt.Sprintf("network %q is still attached to %s", summary.Network, strings.Join(attached, ", ")),
+				Action:  summary.Action,
+			}}
+		}
+		before := snapshot.Networks[index]
+		summary.Before = &before
+		snapshot.Networks = append(append([]WorkbenchComposeNetwork{}, snapshot.Networks[:index]...), snapshot.Networks[index+1:]...)
+		return snapshot, summary, nil
+	})
+}
+
+// MutateServiceNetworks attaches and detaches one service's networks. Data
+// stores may not join the edge network; see workbenchServiceIsDataStore.
+func (s *WorkbenchService) MutateServiceNetworks(
+	ctx context.Context,
+	projectName string,
+	serviceName string,
+	input WorkbenchServiceNetworksRequest,
+) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, error) {
+	summary := WorkbenchNetworkMutationSummary{
+		Action:      workbenchNetworkMutationActionAttach,
+		ServiceName: strings.TrimSpace(serviceName),
+	}
+	attach := normalizeWorkbenchNetworkNames(input.Attach)
+	detach := normalizeWorkbenchNetworkNames(input.Detach)
+	issues := []WorkbenchMutationIssue{}
+	if summary.ServiceName == "" {
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:   workbenchMutationIssueClassSchema,
+			Code:    "WB-NETWORK-SERVICE-REQUIRED",
+			Path:    "$.serviceName",
+			Message: "serviceName is required",
+			Action:  summary.Action,
+		})
+	}
+	if len(attach) == 0 && len(detach) == 0 {
+		issues = append(issues, WorkbenchMutationIssue{
+			Class:   workbenchMutationIssueClassSchema,
+			Code:    "WB-NETWORK-ATTACH-EMPTY",
+			Path:    "$",
+			Message: "at least one network to attach or detach is required",
+			Service: summary.ServiceName,
+			Action:  summary.Action,
+		})
+	}
+	for _, name := range attach {
+		for _, other := range detach {
+			if name == other {
+				issues = append(issues, WorkbenchMutationIssue{
+					Class:   workbenchMutationIssueClassSchema,
+					Code:    "WB-NETWORK-ATTACH-CONFLICT",
+					Path:    "$.detach",
+					Message: fmt.Sprintf("network %q cannot be attached and detached in the same request", name),
+					Service: summary.ServiceName,
+					Action:  summary.Action,
+				})
+			}
+		}
+	}
+	if len(issues) > 0 {
+		return WorkbenchStackSnapshot{}, summary, workbenchNetworkMutationValidationError(WorkbenchStackSnapshot{}, summary, issues)
+	}
+
+	return s.mutateWorkbenchNetworks(ctx, projectName, summary, func(snapshot WorkbenchStackSnapshot, summary WorkbenchNetworkMutationSummary) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, []WorkbenchMutationIssue) {
+		service, ok := workbenchFindComposeServiceForNetworks(snapshot, summary.ServiceName)
+		if !ok {
+			return snapshot, summary, []WorkbenchMutationIssue{{
+				Class:   workbenchMutationIssueClassSchema,
+				Code:    "WB-NETWORK-SERVICE-UNKNOWN",
+				Path:    "$.serviceName",
+				Message: fmt.Sprintf("service %q is not present in the stored snapshot", summary.ServiceName),
+				Service: summary.ServiceName,
+				Action:  summary.Action,
+			}}
+		}
+		summary.ServiceName = service.ServiceName
+
+		issues := []WorkbenchMutationIssue{}
+		current := make(map[string]struct{})
+		for _, networkRef := range snapshot.NetworkRefs {
+			if strings.TrimSpace(networkRef.ServiceName) == service.ServiceName {
+				current[strings.TrimSpace(networkRef.NetworkName)] = struct{}{}
+			}
+		}
+		for idx, name := range attach {
+			path := fmt.Sprintf("$.attach[%d]", idx)
+			if workbenchFindNetwork(snapshot.Networks, name) < 0 {
+				issue := workbenchNetworkNotFoundIssue(name, summary.Action)
+				issue.Path = path
+				issue.Service = service.ServiceName
+				issues = append(issues, issue)
+				continue
+			}
+			if workbenchIsEdgeNetwork(snapshot, name) && workbenchServiceIsDataStore(snapshot, service) {
+				issues = append(issues, WorkbenchMutationIssue{
+					Class:   workbenchMutationIssueClassConflict,
+					Code:    "WB-NETWORK-DB-ON-EDGE",
+					Path:    path,
+					Message: fmt.Sprintf("service %q looks like a database and may not join %q, the %s network", service.ServiceName, name, workbenchEdgeNetworkName()),
+					Service: service.ServiceName,
+					Action:  summary.Action,
+				})
+				continue
+			}
+			if _, exists := current[name]; exists {
+				continue
+			}
+			snapshot.NetworkRefs = append(snapshot.NetworkRefs, WorkbenchComposeNetworkRef{
+				ServiceName: service.ServiceName,
+				NetworkName: name,
+			})
+			current[name] = struct{}{}
+			summary.Attached = append(summary.Attached, name)
+		}
+		if len(issues) > 0 {
+			return snapshot, summary, issues
+		}
+
+		for _, name := range detach {
+			if _, exists := current[name]; !exists {
+				continue
+			}
+			filtered := make([]WorkbenchComposeNetworkRef, 0, len(snapshot.NetworkRefs))
+			for _, networkRef := range snapshot.NetworkRefs {
+				if strings.TrimSpace(networkRef.ServiceName) == service.ServiceName && strings.TrimSpace(networkRef.NetworkName) == name {
+					continue
+				}
+				filtered = append(filtered, networkRef)
+			}
+			snapshot.NetworkRefs = filtered
+			delete(current, name)
+			summary.Detached = append(summary.Detached, name)
+		}
+		if len(summary.Detached) > 0 && len(current) == 0 {
+			summary.Notes = append(summary.Notes, fmt.Sprintf("Service %q has no networks left and will fall back to the project default network.", service.ServiceName))
+		}
+		for name := range current {
+			summary.Networks = append(summary.Networks, name)
+		}
+		sort.Strings(summary.Networks)
+		return snapshot, summary, nil
+	})
+}
+
+type workbenchNetworkMutator func(
+	snapshot WorkbenchStackSnapshot,
+	summary WorkbenchNetworkMutationSummary,
+) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, []WorkbenchMutationIssue)
+
+func (s *WorkbenchService) mutateWorkbenchNetworks(
+	ctx context.Context,
+	projectName string,
+	summary WorkbenchNetworkMutationSummary,
+	mutate workbenchNetworkMutator,
+) (WorkbenchStackSnapshot, WorkbenchNetworkMutationSummary, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, summary, err
+	}
+
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, summary, err
+	}
+	defer release()
+
+	snapshot, exists, err := s.loadStoredWorkbenchSnapshot(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, summary, err
+	}
+	if !exists {
+		return WorkbenchStackSnapshot{}, summary, errs.WithDetails(
+			errs.New(errs.CodeWorkbenchSourceNotFound, fmt.Sprintf("workbench snapshot not found for project %q", normalizedProject)),
+			map[string]any{
+				"project": normalizedProject,
+			},
+		)
+	}
+
+	working := normalizeWorkbenchStackSnapshot(snapshot)
+	if working.ModelVersion < workbenchModelVersion {
+		working, err = s.backfillWorkbenchNetworksLocked(ctx, normalizedProject, working)
+		if err != nil {
+			return snapshot, summary, err
+		}
+	}
+	working.Networks = append([]WorkbenchComposeNetwork{}, working.Networks...)
+	working.NetworkRefs = append([]WorkbenchComposeNetworkRef{}, working.NetworkRefs...)
+
+	mutated, mutationSummary, issues := mutate(working, summary)
+	if len(issues) > 0 {
+		sort.SliceStable(issues, func(i, j int) bool {
+			return workbenchMutationIssueLess(issues[i], issues[j])
+		})
+		return snapshot, mutationSummary, workbenchNetworkMutationValidationError(snapshot, mutationSummary, issues)
+	}
+	mutated = normalizeWorkbenchStackSnapshot(mutated)
+	mutationSummary.Changed = mutated.ModelVersion != snapshot.ModelVersion ||
+		!reflect.DeepEqual(mutated.Networks, normalizeWorkbenchStackSnapshot(snapshot).Networks) ||
+		!reflect.DeepEqual(mutated.NetworkRefs, normalizeWorkbenchStackSnapshot(snapshot).NetworkRefs)
+	if !mutationSummary.Changed {
+		return snapshot, mutationSummary, nil
+	}
+
+	if mutated.Revision <= 0 {
+		mutated.Revision = 1
+	}
+	mutated.Revision++
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, mutated, WorkbenchMutationNetworkMutate, mutationSummary.Action); err != nil {
+		return snapshot, mutationSummary, err
+	}
+	return mutated, mutationSummary, nil
+}
+
+// backfillWorkbenchNetworksLocked upgrades a snapshot stored before network
+// definitions were tracked by reading them from the current compose file, so
+// the first network mutation cannot prune networks the snapshot never saw.
+func (s *WorkbenchService) backfillWorkbenchNetworksLocked(
+	ctx context.Context,
+	projectName string,
+	snapshot WorkbenchStackSnapshot,
+) (WorkbenchStackSnapshot, error) {
+	source, err := s.ResolveComposeSource(ctx, projectName)
+	if err != nil {
+		return snapshot, err
+	}
+	parsed, err := s.ParseComposeCoreFromSource(source)
+	if err != nil {
+		return snapshot, err
+	}
+
+	next := snapshot
+	next.Networks = append([]WorkbenchComposeNetwork{}, snapshot.Networks...)
+	for _, network := range parsed.Networks {
+		if workbenchFindNetwork(next.Networks, network.Name) >= 0 {
+			continue
+		}
+		next.Networks = append(next.Networks, network)
+	}
+	next.ModelVersion = workbenchModelVersion
+	return normalizeWorkbenchStackSnapshot(next), nil
+}
+
+// workbenchDataStoreEdgeNetworkIssues flags data stores attached to the edge
+// network. networkRefs is keyed by service name as in the generation model.
+func workbenchDataStoreEdgeNetworkIssues(
+	snapshot WorkbenchStackSnapshot,
+	services []WorkbenchComposeService,
+	networkRefs map[string][]string,
+) []WorkbenchValidationIssue {
+	issues := []WorkbenchValidationIssue{}
+	for _, service := range services {
+		edgeKey := ""
+		for _, networkName := range networkRefs[service.ServiceName] {
+			if workbenchIsEdgeNetwork(snapshot, networkName) {
+				edgeKey = networkName
+				break
+			}
+		}
+		if edgeKey == "" || !workbenchServiceIsDataStore(snapshot, service) {
+			continue
+		}
+		issues = append(issues, WorkbenchValidationIssue{
+			Class:   workbenchValidationClassNetwork,
+			Code:    "WB-VAL-NETWORK-DB-ON-EDGE",
+			Path:    fmt.Sprintf("$.networkRefs[%s]", service.ServiceName),
+			Message: fmt.Sprintf("service %q looks like a database and must not be attached to %q, the %s network", service.ServiceName, edgeKey, workbenchEdgeNetworkName()),
+			Service: service.ServiceName,
+		})
+	}
+	return issues
+}
+
+// workbenchServiceIsDataStore reports whether a service holds state that must
+// never be reachable from ingress. Catalog-managed services are classified by
+// catalog category; compose services by image and then by service name.
+func workbenchServiceIsDataStore(snapshot WorkbenchStackSnapshot, service WorkbenchComposeService) bool {
+	serviceName := strings.TrimSpace(service.ServiceName)
+	for _, managedService := range snapshot.ManagedServices {
+		if !strings.EqualFold(strings.TrimSpace(managedService.ServiceName), serviceName) {
+			continue
+		}
+		if definition, ok := workbenchOptionalServiceDefinitionByKey(managedService.EntryKey); ok {
+			switch definition.category {
+			case "database", "cache":
+				return true
+			}
+		}
+	}
+
+	image := strings.ToLower(strings.TrimSpace(service.Image))
+	if image != "" {
+		// Only the repository name; registry hosts and tags can contain anything.
+		if slash := strings.LastIndex(image, "/"); slash >= 0 {
+			image = image[slash+1:]
+		}
+		if colon := strings.Index(image, ":"); colon >= 0 {
+			image = image[:colon]
+		}
+		for _, keyword := range []string{"postgres", "postgis", "timescaledb", "mysql", "mariadb", "mongo", "redis", "valkey", "keydb", "cockroach", "couchdb", "cassandra", "clickhouse", "influxdb"} {
+			if strings.Contains(image, keyword) {
+				return true
+			}
+		}
+	}
+
+	switch strings.ToLower(serviceName) {
+	case "db", "database", "postgres", "postgresql", "mysql", "mariadb", "mongo", "mongodb", "redis":
+		return true
+	}
+	return false
+}
+
+func workbenchFindComposeServiceForNetworks(snapshot WorkbenchStackSnapshot, serviceName string) (WorkbenchComposeService, bool) {
+	if name := workbenchFindSnapshotServiceName(snapshot.Services, serviceName); name != "" {
+		for _, service := range snapshot.Services {
+			if strings.TrimSpace(service.ServiceName) == name {
+				return service, true
+			}
+		}
+	}
+	if index := workbenchFindManagedOptionalServiceByServiceName(snapshot.ManagedServices, serviceName); index >= 0 {
+		managedService := snapshot.ManagedServices[index]
+		service := WorkbenchComposeService{ServiceName: strings.TrimSpace(managedService.ServiceName)}
+		if definition, ok := workbenchOptionalServiceDefinitionByKey(managedService.EntryKey); ok {
+			service.Image = definition.suggestedImage
+		}
+		return service, true
+	}
+	return WorkbenchComposeService{}, false
+}
+
+func workbenchFindNetwork(networks []WorkbenchComposeNetwork, name string) int {
+	target := strings.TrimSpace(name)
+	for idx := range networks {
+		if strings.TrimSpace(networks[idx].Name) == target {
+			return idx
+		}
+	}
+	return -1
+}
+
+func normalizeWorkbenchNetworkNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	normalized := []string{}
+	for _, name := range names {
+		trimmed := strings.TrimSpace(name)
+		if trimmed == "" {
+			continue
+		}
+		if _, exists := seen[trimmed]; exists {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		normalized = append(normalized, trimmed)
+	}
+	return normalized
+}
+
+func validateWorkbenchNetworkName(name string, action string) []WorkbenchMutationIssue {
+	if name == "" {
+		return []WorkbenchMutationIssue{{
+			Class:   workbenchMutationIssueClassSchema,
+			Code:    "WB-NETWORK-NAME-REQUIRED",
+			Path:    "$.name",
+			Message: "network name is required",
+			Action:  action,
+		}}
+	}
+	if !workbenchNetworkNamePattern.MatchString(name) {
+		return []WorkbenchMutationIssue{{
+			Class:   workbenchMutationIssueClassValue,
+			Code:    "WB-NETWORK-NAME-INVALID",
+			Path:    "$.name",
+			Message: fmt.Sprintf("network name %q must start with a letter or digit and contain only letters, digits, '_', '.', or '-'", name),
+			Action:  action,
+		}}
+	}
+	return nil
+}
+
+func validateWorkbenchNetworkDriver(driver string, action string) []WorkbenchMutationIssue {
+	if driver == "" {
+		return nil
+	}
+	for _, allowed := range workbenchNetworkDrivers {
+		if driver == allowed {
+			return nil
+		}
+	}
+	return []WorkbenchMutationIssue{{
+		Class:   workbenchMutationIssueClassValue,
+		Code:    "WB-NETWORK-DRIVER-INVALID",
+		Path:    "$.driver",
+		Message: fmt.Sprintf("network driver %q is not supported; expected one of %s", driver, strings.Join(workbenchNetworkDrivers, ", ")),
+		Action:  action,
+	}}
+}
+
+func workbenchNetworkNotFoundIssue(name string, action string) WorkbenchMutationIssue {
+	return WorkbenchMutationIssue{
+		Class:   workbenchMutationIssueClassSchema,
+		Code:    "WB-NETWORK-NOT-FOUND",
+		Path:    "$.network",
+		Message: fmt.Sprintf("network %q is not defined in the stored snapshot", name),
+		Action:  action,
+	}
+}
+
+func workbenchNetworkMutationValidationError(
+	snapshot WorkbenchStackSnapshot,
+	summary WorkbenchNetworkMutationSummary,
+	issues []WorkbenchMutationIssue,
+) error {
+	normalizedIssues := append([]WorkbenchMutationIssue(nil), issues...)
+	sort.SliceStable(normalizedIssues, func(i, j int) bool {
+		return workbenchMutationIssueLess(normalizedIssues[i], normalizedIssues[j])
+	})
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchValidationFailed, "invalid workbench network mutation"),
+		map[string]any{
+			"project":           strings.TrimSpace(snapshot.ProjectName),
+			"composePath":       strings.TrimSpace(snapshot.ComposePath),
+			"sourceFingerprint": strings.TrimSpace(snapshot.SourceFingerprint),
+			"revision":          snapshot.Revision,
+			"action":            strings.TrimSpace(summary.Action),
+			"network":           strings.TrimSpace(summary.Network),
+			"serviceName":       strings.TrimSpace(summary.ServiceName),
+			"issueCount":        len(normalizedIssues),
+			"issues":            normalizedIssues,
+			"summary":           summary,
+		},
+	)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-notes/internal/errs"
+)
+
+func TestWorkbenchNetworkMutationsRoundTripThroughApply(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	composePath := filepath.Join(projectDir, "docker-compose.yml")
+	source := "services:\n  api:\n    image: nginx:1.25\n    networks:\n      - legacy\n  db:\n    image: postgres:16\nnetworks:\n  legacy:\n    ipam:\n      driver: default\n"
+	if err := os.WriteFile(composePath, []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	imported, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual")
+	if err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+	if len(imported.Networks) != 1 || imported.Networks[0].Name != "legacy" {
+		t.Fatalf("expected imported legacy network, got %#v", imported.Networks)
+	}
+
+	created, summary, err := svc.CreateNetwork(ctx, "demo", WorkbenchNetworkCreateRequest{Name: "data", Driver: "Bridge", Internal: true})
+	if err != nil {
+		t.Fatalf("create network: %v", err)
+	}
+	if !summary.Changed || created.Revision != imported.Revision+1 {
+		t.Fatalf("expected created network revision, got summary=%#v revision=%d", summary, created.Revision)
+	}
+	if _, _, err := svc.MutateServiceNetworks(ctx, "demo", "db", WorkbenchServiceNetworksRequest{Attach: []string{"data"}}); err != nil {
+		t.Fatalf("attach db: %v", err)
+	}
+	attached, _, err := svc.MutateServiceNetworks(ctx, "demo", "api", WorkbenchServiceNetworksRequest{Attach: []string{"data"}, Detach: []string{"legacy"}})
+	if err != nil {
+		t.Fatalf("move api networks: %v", err)
+	}
+	if _, _, err := svc.DeleteNetwork(ctx, "demo", "data"); !workbenchNetworkErrorHasIssue(t, err, "WB-NETWORK-IN-USE") {
+		t.Fatalf("expected in-use rejection, got %v", err)
+	}
+	deleted, _, err := svc.DeleteNetwork(ctx, "demo", "legacy")
+	if err != nil {
+		t.Fatalf("delete legacy network: %v", err)
+	}
+	if deleted.Revision != attached.Revision+1 {
+		t.Fatalf("expected delete revision %d, got %d", attached.Revision+1, deleted.Revision)
+	}
+
+	expectedRevision := deleted.Revision
+	if _, err := svc.ApplyComposeFromStoredSnapshot(ctx, "demo", WorkbenchComposeApplyRequest{
+		ExpectedRevision:          &expectedRevision,
+		ExpectedSourceFingerprint: imported.SourceFingerprint,
+	}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	written, err := os.ReadFile(composePath)
+	if err != nil {
+		t.Fatalf("read compose: %v", err)
+	}
+	content := string(written)
+	if !strings.Contains(content, "networks:\n  data:\n    driver: bridge\n    internal: true") {
+		t.Fatalf("expected internal data network, got:\n%s", content)
+	}
+	if strings.Contains(content, "legacy") {
+		t.Fatalf("expected legacy network pruned, got:\n%s", content)
+	}
+}
+
+func TestWorkbenchNetworkMutationsKeepDataStoresOffEdge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	// ingress is the panel's edge network under another key; the project's own
+	// edge network is scoped to the project and is not.
+	source := "services:\n  api:\n    image: nginx:1.25\n  store:\n    image: docker.io/library/postgres:16\nnetworks:\n  edge: {}\n  ingress:\n    external: true\n    name: " + defaultWorkbenchEdgeNetwork + "\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	imported, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual")
+	if err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+	if _, _, err := svc.MutateServiceNetworks(ctx, "demo", "api", WorkbenchServiceNetworksRequest{Attach: []string{"ingress"}}); err != nil {
+		t.Fatalf("attach api to ingress: %v", err)
+	}
+	if _, _, err := svc.MutateServiceNetworks(ctx, "demo", "store", WorkbenchServiceNetworksRequest{Attach: []string{"ingress"}}); !workbenchNetworkErrorHasIssue(t, err, "WB-NETWORK-DB-ON-EDGE") {
+		t.Fatalf("expected db-on-edge rejection, got %v", err)
+	}
+	if _, _, err := svc.MutateServiceNetworks(ctx, "demo", "store", WorkbenchServiceNetworksRequest{Attach: []string{"edge"}}); err != nil {
+		t.Fatalf("attach store to the project-scoped edge network: %v", err)
+	}
+
+	// Snapshots that reach the generator some other way are caught there too.
+	imported.NetworkRefs = append(imported.NetworkRefs, WorkbenchComposeNetworkRef{ServiceName: "store", NetworkName: "ingress"})
+	_, err = generateWorkbenchCompose(imported)
+	typed, ok := errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchValidationFailed {
+		t.Fatalf("expected %q, got %v", errs.CodeWorkbenchValidationFailed, err)
+	}
+	details, _ := typed.Details.(map[string]any)
+	found := false
+	for _, issue := range extractWorkbenchValidationIssues(t, details) {
+		if issue.Code == "WB-VAL-NETWORK-DB-ON-EDGE" && issue.Class == workbenchValidationClassNetwork && issue.Service == "store" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected db-on-edge validation issue, got %#v", details["issues"])
+	}
+}
+
+func TestWorkbenchNetworkMutationBackfillsLegacySnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	source := "services:\n  api:\n    image: nginx:1.25\nnetworks:\n  shared:\n    driver: overlay\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	imported, _, err := svc.ImportComposeSnapshot(ctx, "demo", "manual")
+	if err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+	imported.ModelVersion = 1
+	imported.Networks = nil
+	if err := svc.saveWorkbenchSnapshot(ctx, "demo", imported); err != nil {
+		t.Fatalf("save legacy snapshot: %v", err)
+	}
+
+	created, _, err := svc.CreateNetwork(ctx, "demo", WorkbenchNetworkCreateRequest{Name: "cache"})
+	if err != nil {
+		t.Fatalf("create network: %v", err)
+	}
+	if created.ModelVersion != workbenchModelVersion {
+		t.Fatalf("expected modelVersion %d, got %d", workbenchModelVersion, created.ModelVersion)
+	}
+	if len(created.Networks) != 2 || created.Networks[1].Name != "shared" || created.Networks[1].Driver != "overlay" {
+		t.Fatalf("expected backfilled shared network, got %#v", created.Networks)
+	}
+}
+
+func workbenchNetworkErrorHasIssue(t *testing.T, err error, code string) bool {
+	t.Helper()
+	typed, ok := errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchValidationFailed {
+		return false
+	}
+	details, _ := typed.Details.(map[string]any)
+	issues, _ := details["issues"].([]WorkbenchMutationIssue)
+	for _, issue := range issues {
+		if issue.Code == code {
+			return true
+		}
+	}
+	return false
+}
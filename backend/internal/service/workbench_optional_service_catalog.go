@@ -23,6 +23,8 @@ const (
 	workbenchOptionalServiceTargetStateCatalogManaged                = "catalog_managed"
 	workbenchOptionalServiceMatchReasonServiceName                   = "service_name"
 	workbenchOptionalServiceMatchReasonImageRepository               = "image_repository"
+	workbenchOptionalServiceSourceBuiltin                            = "builtin"
+	workbenchOptionalServiceSourceFile                               = "file"
 )
 
 // WorkbenchOptionalServiceCatalog freezes the transition contract for the
@@ -38,15 +40,18 @@ type WorkbenchOptionalServiceCatalog struct {
 }
 
 type WorkbenchOptionalServiceCatalogEntry struct {
-	Key                  string                               `json:"key"`
-	DisplayName          string                               `json:"displayName"`
-	Description          string                               `json:"description"`
-	Category             string                               `json:"category"`
-	DefaultServiceName   string                               `json:"defaultServiceName"`
-	SuggestedImage       string                               `json:"suggestedImage"`
-	DefaultContainerPort int                                  `json:"defaultContainerPort"`
-	Availability         WorkbenchOptionalServiceAvailability `json:"availability"`
-	Transition           WorkbenchOptionalServiceTransition   `json:"transition"`
+	Key                  string                                     `json:"key"`
+	DisplayName          string                                     `json:"displayName"`
+	Description          string                                     `json:"description"`
+	Category             string                                     `json:"category"`
+	DefaultServiceName   string                                     `json:"defaultServiceName"`
+	SuggestedImage       string                                     `json:"suggestedImage"`
+	DefaultContainerPort int                                        `json:"defaultContainerPort"`
+	Source               string                                     `json:"source"`
+	DependsOn            []string                                   `json:"dependsOn"`
+	ConnectionOutputs    []WorkbenchOptionalServiceConnectionOutput `json:"connectionOutputs"`
+	Availability         WorkbenchOptionalServiceAvailability       `json:"availability"`
+	Transition           WorkbenchOptionalServiceTransition         `json:"transition"`
 }
 
 type WorkbenchOptionalServiceAvailability struct {
@@ -92,10 +97,13 @@ type workbenchOptionalServiceDefinition struct {
 	serviceNameHints     []string
 	imageNameHints       []string
 	legacyModuleType     string
+	source               string
+	dependsOn            []string
+	connectionOutputs    []workbenchOptionalServiceConnectionOutputDefinition
 	runtime              workbenchOptionalServiceRuntimeDefinition
 }
 
-var workbenchOptionalServiceDefinitions = []workbenchOptionalServiceDefinition{
+var workbenchBuiltinOptionalServiceDefinitions = []workbenchOptionalServiceDefinition{
 	{
 		key:                  "redis",
 		displayName:          "Redis",
@@ -107,12 +115,19 @@ var workbenchOptionalServiceDefinitions = []workbenchOptionalServiceDefinition{
 		serviceNameHints:     []string{"redis"},
 		imageNameHints:       []string{"redis"},
 		legacyModuleType:     "redis",
+		source:               workbenchOptionalServiceSourceBuiltin,
+		connectionOutputs: []workbenchOptionalServiceConnectionOutputDefinition{
+			{key: "REDIS_URL", value: "redis://{{serviceName}}:6379"},
+		},
 		runtime: workbenchOptionalServiceRuntimeDefinition{
 			image:         "redis:7-alpine",
 			restartPolicy: "unless-stopped",
 			ports: []workbenchOptionalServicePortDefinition{
 				{containerPort: 6379, protocol: "tcp"},
 			},
+			volumes: []workbenchOptionalServiceVolumeDefinition{
+				{name: "redis-data", target: "/data"},
+			},
 		},
 	},
 	{
@@ -125,6 +140,7 @@ var workbenchOptionalServiceDefinitions = []workbenchOptionalServiceDefinition{
 		defaultContainerPort: 80,
 		serviceNameHints:     []string{"nginx"},
 		imageNameHints:       []string{"nginx"},
+		source:               workbenchOptionalServiceSourceBuiltin,
 		runtime: workbenchOptionalServiceRuntimeDefinition{
 			image:         "nginx:stable-alpine",
 			restartPolicy: "unless-stopped",
@@ -143,12 +159,19 @@ var workbenchOptionalServiceDefinitions = []workbenchOptionalServiceDefinition{
 		defaultContainerPort: 9090,
 		serviceNameHints:     []string{"prometheus"},
 		imageNameHints:       []string{"prometheus"},
+		source:               workbenchOptionalServiceSourceBuiltin,
+		connectionOutputs: []workbenchOptionalServiceConnectionOutputDefinition{
+			{key: "PROMETHEUS_URL", value: "http://{{serviceName}}:9090"},
+		},
 		runtime: workbenchOptionalServiceRuntimeDefinition{
 			image:         "prom/prometheus:latest",
 			restartPolicy: "unless-stopped",
 			ports: []workbenchOptionalServicePortDefinition{
 				{containerPort: 9090, protocol: "tcp"},
 			},
+			volumes: []workbenchOptionalServiceVolumeDefinition{
+				{name: "prometheus-data", target: "/prometheus"},
+			},
 		},
 	},
 	{
@@ -161,6 +184,10 @@ var workbenchOptionalServiceDefinitions = []workbenchOptionalServiceDefinition{
 		defaultContainerPort: 9000,
 		serviceNameHints:     []string{"minio"},
 		imageNameHints:       []string{"minio"},
+		source:               workbenchOptionalServiceSourceBuiltin,
+		connectionOutputs: []workbenchOptionalServiceConnectionOutputDefinition{
+			{key: "S3_ENDPOINT", value: "http://{{serviceName}}:9000"},
+		},
 		runtime: workbenchOptionalServiceRuntimeDefinition{
 			image:         "minio/minio:latest",
 			restartPolicy: "unless-stopped",
@@ -173,6 +200,9 @@ var workbenchOptionalServiceDefinitions = []workbenchOptionalServiceDefinition{
 				{containerPort: 9000, protocol: "tcp"},
 				{containerPort: 9001, protocol: "tcp"},
 			},
+			volumes: []workbenchOptionalServiceVolumeDefinition{
+				{name: "minio-data", target: "/data"},
+			},
 		},
 	},
 }
@@ -186,6 +216,7 @@ func (s *WorkbenchService) GetOptionalServiceCatalog(
 		return WorkbenchOptionalServiceCatalog{}, err
 	}
 
+	definitions := workbenchActiveOptionalServiceDefinitions()
 	normalizedProject := strings.ToLower(strings.TrimSpace(snapshot.ProjectName))
 	legacyPath := "/api/v1/projects/" + url.PathEscape(normalizedProject) + "/workbench/modules"
 	legacyRecords := workbenchNormalizeLegacyModuleRecords(snapshot.Modules)
@@ -195,7 +226,7 @@ func (s *WorkbenchService) GetOptionalServiceCatalog(
 		SnapshotImported:  snapshot.Revision > 0 && strings.TrimSpace(snapshot.SourceFingerprint) != "",
 		SnapshotRevision:  snapshot.Revision,
 		SourceFingerprint: strings.TrimSpace(snapshot.SourceFingerprint),
-		Entries:           make([]WorkbenchOptionalServiceCatalogEntry, 0, len(workbenchOptionalServiceDefinitions)),
+		Entries:           make([]WorkbenchOptionalServiceCatalogEntry, 0, len(definitions)),
 		LegacyModules: WorkbenchLegacyModuleCatalog{
 			Status:               workbenchOptionalServiceLegacyModulesStatusEmpty,
 			SupportedModuleTypes: []string{"redis"},
@@ -211,13 +242,13 @@ func (s *WorkbenchService) GetOptionalServiceCatalog(
 		catalog.LegacyModules.Status = workbenchOptionalServiceLegacyModulesStatusPresent
 	}
 
-	for _, definition := range workbenchOptionalServiceDefinitions {
+	for _, definition := range definitions {
 		composeMatches := workbenchMatchOptionalServiceCompose(snapshot.Services, definition)
 		legacyMatches := workbenchMatchOptionalServiceLegacyModules(legacyRecords, definition)
 		managedMatches := workbenchMatchOptionalManagedServices(snapshot.ManagedServices, definition)
 		transitionNotes := []string{
 			"Catalog add/remove mutations are available against the stored snapshot.",
-			"Compose preview/apply now renders catalog-managed services from frozen backend catalog definitions.",
+			"Compose preview/apply now renders catalog-managed services from the active backend catalog definitions (built-ins merged with file-loaded entries).",
 			"Port resolution now includes baseline container-port planning for catalog-managed services.",
 			"Legacy /workbench/modules is compatibility-only and maps to catalog-managed mutations for supported legacy module types.",
 		}
@@ -254,6 +285,9 @@ func (s *WorkbenchService) GetOptionalServiceCatalog(
 			DefaultServiceName:   definition.defaultServiceName,
 			SuggestedImage:       definition.suggestedImage,
 			DefaultContainerPort: definition.defaultContainerPort,
+			Source:               definition.source,
+			DependsOn:            append([]string{}, definition.dependsOn...),
+			ConnectionOutputs:    workbenchRenderOptionalServiceConnectionOutputs(definition, definition.defaultServiceName),
 			Availability: WorkbenchOptionalServiceAvailability{
 				Status:          workbenchOptionalServiceAvailabilityStatus(composeMatches, legacyMatches, managedMatches),
 				ComposeServices: composeMatches,
@@ -322,7 +356,7 @@ func workbenchNormalizeLegacyModuleRecords(modules []WorkbenchStackModule) []Wor
 
 func workbenchOptionalServiceDefinitionByKey(key string) (workbenchOptionalServiceDefinition, bool) {
 	normalizedKey := strings.ToLower(strings.TrimSpace(key))
-	for _, definition := range workbenchOptionalServiceDefinitions {
+	for _, definition := range workbenchActiveOptionalServiceDefinitions() {
 		if definition.key == normalizedKey {
 			return definition, true
 		}
@@ -332,7 +366,7 @@ func workbenchOptionalServiceDefinitionByKey(key string) (workbenchOptionalServi
 
 func workbenchOptionalServiceDefinitionByServiceName(serviceName string) (workbenchOptionalServiceDefinition, bool) {
 	normalizedServiceName := strings.ToLower(strings.TrimSpace(serviceName))
-	for _, definition := range workbenchOptionalServiceDefinitions {
+	for _, definition := range workbenchActiveOptionalServiceDefinitions() {
 		if strings.ToLower(strings.TrimSpace(definition.defaultServiceName)) == normalizedServiceName {
 			return definition, true
 		}
@@ -342,7 +376,7 @@ func workbenchOptionalServiceDefinitionByServiceName(serviceName string) (workbe
 
 func workbenchOptionalServiceDefinitionByLegacyModuleType(moduleType string) (workbenchOptionalServiceDefinition, bool) {
 	normalizedModuleType := strings.ToLower(strings.TrimSpace(moduleType))
-	for _, definition := range workbenchOptionalServiceDefinitions {
+	for _, definition := range workbenchActiveOptionalServiceDefinitions() {
 		if strings.ToLower(strings.TrimSpace(definition.legacyModuleType)) == normalizedModuleType {
 			return definition, true
 		}
@@ -411,6 +445,7 @@ func workbenchMatchOptionalManagedServices(
 		matches = append(matches, WorkbenchManagedService{
 			EntryKey:    strings.ToLower(strings.TrimSpace(managedService.EntryKey)),
 			ServiceName: strings.TrimSpace(managedService.ServiceName),
+			AppService:  strings.TrimSpace(managedService.AppService),
 		})
 	}
 	return matches
@@ -0,0 +1,708 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-notes/internal/errs"
+	"gopkg.in/yaml.v3"
+)
+
+const workbenchOptionalServiceCatalogMaxFileBytes = 64 * 1024
+
+var (
+	workbenchOptionalServiceKeyPattern         = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	workbenchOptionalServiceServiceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,62}$`)
+	workbenchOptionalServiceEnvKeyPattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	workbenchOptionalServiceVolumeNamePattern  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,127}$`)
+	workbenchOptionalServiceDurationPattern    = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?(us|ms|s|m|h))+$`)
+)
+
+// WorkbenchOptionalServiceCatalogStatus describes the active optional-service
+// catalog after a startup load or an admin-triggered reload.
+type WorkbenchOptionalServiceCatalogStatus struct {
+	SourceDir    string                                     `json:"sourceDir,omitempty"`
+	LoadedAt     time.Time                                  `json:"loadedAt"`
+	BuiltinCount int                                        `json:"builtinCount"`
+	FileCount    int                                        `json:"fileCount"`
+	Entries      []WorkbenchOptionalServiceCatalogStatusKey `json:"entries"`
+}
+
+type WorkbenchOptionalServiceCatalogStatusKey struct {
+	Key        string `json:"key"`
+	Source     string `json:"source"`
+	SourceFile string `json:"sourceFile,omitempty"`
+	Overrides  bool   `json:"overrides,omitempty"`
+}
+
+// WorkbenchOptionalServiceCatalogIssue reports one invalid catalog definition
+// file so operators can fix it without guessing which entry was rejected.
+type WorkbenchOptionalServiceCatalogIssue struct {
+	File    string `json:"file"`
+	Path    string `json:"path"`
+	Key     string `json:"key,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type workbenchOptionalServiceCatalogFileDefinition struct {
+	Key          string                                          `yaml:"key"`
+	DisplayName  string                                          `yaml:"displayName"`
+	Description  string                                          `yaml:"description"`
+	Category     string                                          `yaml:"category"`
+	ServiceName  string                                          `yaml:"serviceName"`
+	Image        string                                          `yaml:"image"`
+	Restart      string                                          `yaml:"restart"`
+	Command      []string                                        `yaml:"command"`
+	Environment  map[string]string                               `yaml:"environment"`
+	Ports        []workbenchOptionalServiceCatalogFilePort       `yaml:"ports"`
+	Volumes      []workbenchOptionalServiceCatalogFileVolume     `yaml:"volumes"`
+	Healthcheck  *workbenchOptionalServiceCatalogFileHealthcheck `yaml:"healthcheck"`
+	DependsOn    []string                                        `yaml:"dependsOn"`
+	Connections  []workbenchOptionalServiceCatalogFileConnection `yaml:"connectionOutputs"`
+	ServiceHints []string                                        `yaml:"serviceNameHints"`
+	ImageHints   []string                                        `yaml:"imageNameHints"`
+}
+
+type workbenchOptionalServiceCatalogFilePort struct {
+	ContainerPort int    `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol"`
+	HostIP        string `yaml:"hostIp"`
+}
+
+type workbenchOptionalServiceCatalogFileVolume struct {
+	Name   string `yaml:"name"`
+	Target string `yaml:"target"`
+}
+
+type workbenchOptionalServiceCatalogFileConnection struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+type workbenchOptionalServiceCatalogFileHealthcheck struct {
+	Test        []string `yaml:"test"`
+	Interval    string   `yaml:"interval"`
+	Timeout     string   `yaml:"timeout"`
+	Retries     int      `yaml:"retries"`
+	StartPeriod string   `yaml:"startPeriod"`
+}
+
+type workbenchOptionalServiceCatalogState struct {
+	mu          sync.RWMutex
+	definitions []workbenchOptionalServiceDefinition
+	status      WorkbenchOptionalServiceCatalogStatus
+}
+
+// workbenchOptionalServiceCatalog is process-wide because the compose
+// generator, port resolver and mutation paths all resolve catalog entries
+// through package-level lookups.
+var workbenchOptionalServiceCatalog = newWorkbenchOptionalServiceCatalogState()
+
+func newWorkbenchOptionalServiceCatalogState() *workbenchOptionalServiceCatalogState {
+	definitions, status := workbenchMergeOptionalServiceDefinitions(nil, nil, "")
+	return &workbenchOptionalServiceCatalogState{
+		definitions: definitions,
+		status:      status,
+	}
+}
+
+func workbenchActiveOptionalServiceDefinitions() []workbenchOptionalServiceDefinition {
+	workbenchOptionalServiceCatalog.mu.RLock()
+	defer workbenchOptionalServiceCatalog.mu.RUnlock()
+	return workbenchOptionalServiceCatalog.definitions
+}
+
+func (c *workbenchOptionalServiceCatalogState) replace(
+	definitions []workbenchOptionalServiceDefinition,
+	status WorkbenchOptionalServiceCatalogStatus,
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.definitions = definitions
+	c.status = status
+}
+
+func (c *workbenchOptionalServiceCatalogState) currentStatus() WorkbenchOptionalServiceCatalogStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	status := c.status
+	status.Entries = append([]WorkbenchOptionalServiceCatalogStatusKey(nil), c.status.Entries...)
+	return status
+}
+
+// LoadOptionalServiceCatalog validates the definition files in dir, merges them
+// with the built-in entries and activates the result. An empty dir keeps only
+// the built-ins. On error the previously active catalog stays in place.
+func (s *WorkbenchService) LoadOptionalServiceCatalog(dir string) (WorkbenchOptionalServiceCatalogStatus, error) {
+	s.catalogMu.Lock()
+	defer s.catalogMu.Unlock()
+	s.catalogDir = strings.TrimSpace(dir)
+	return s.loadOptionalServiceCatalog()
+}
+
+// ReloadOptionalServiceCatalog re-reads the directory configured through
+// LoadOptionalServiceCatalog.
+func (s *WorkbenchService) ReloadOptionalServiceCatalog(ctx context.Context) (WorkbenchOptionalServiceCatalogStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return WorkbenchOptionalServiceCatalogStatus{}, err
+	}
+	s.catalogMu.Lock()
+	defer s.catalogMu.Unlock()
+	return s.loadOptionalServiceCatalog()
+}
+
+// OptionalServiceCatalogStatus reports which entries are active and where they came from.
+func (s *WorkbenchService) OptionalServiceCatalogStatus() WorkbenchOptionalServiceCatalogStatus {
+	return workbenchOptionalServiceCatalog.currentStatus()
+}
+
+// loadOptionalServiceCatalog must be called with catalogMu held, which also
+// keeps a slow reload from replacing the result of a newer one.
+func (s *WorkbenchService) loadOptionalServiceCatalog() (WorkbenchOptionalServiceCatalogStatus, error) {
+	fileDefinitions, fileNames, err := loadWorkbenchOptionalServiceCatalogDir(s.catalogDir)
+	if err != nil {
+		return WorkbenchOptionalServiceCatalogStatus{}, err
+	}
+
+	definitions, status := workbenchMergeOptionalServiceDefinitions(fileDefinitions, fileNames, s.catalogDir)
+	if issues := workbenchValidateOptionalServiceDependencies(definitions, fileNames); len(issues) > 0 {
+		return WorkbenchOptionalServiceCatalogStatus{}, workbenchOptionalServiceCatalogInvalidError(s.catalogDir, issues)
+	}
+	status.LoadedAt = s.nowFn().UTC()
+	workbenchOptionalServiceCatalog.replace(definitions, status)
+	return status, nil
+}
+
+func loadWorkbenchOptionalServiceCatalogDir(dir string) ([]workbenchOptionalServiceDefinition, map[string]string, error) {
+	trimmedDir := strings.TrimSpace(dir)
+	fileNames := map[string]string{}
+	if trimmedDir == "" {
+		return nil, fileNames, nil
+	}
+
+	entries, err := os.ReadDir(trimmedDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fileNames, nil
+		}
+		return nil, nil, errs.WithDetails(
+			errs.Wrap(errs.CodeWorkbenchCatalogInvalid, "failed to read optional-service catalog directory", err),
+			map[string]any{
+				"sourceDir": trimmedDir,
+				"cause":     err.Error(),
+			},
+		)
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".yaml", ".yml":
+			paths = append(paths, filepath.Join(trimmedDir, name))
+		}
+	}
+	sort.Strings(paths)
+
+	definitions := make([]workbenchOptionalServiceDefinition, 0, len(paths))
+	issues := []WorkbenchOptionalServiceCatalogIssue{}
+	for _, path := range paths {
+		fileName := filepath.Base(path)
+		definition, fileIssues := loadWorkbenchOptionalServiceCatalogFile(path)
+		if len(fileIssues) > 0 {
+			issues = append(issues, fileIssues...)
+			continue
+		}
+		if previous, exists := fileNames[definition.key]; exists {
+			issues = append(issues, WorkbenchOptionalServiceCatalogIssue{
+				File:    fileName,
+				Path:    "$.key",
+				Key:     definition.key,
+				Code:    "WB-CATALOG-KEY-DUPLICATE",
+				Message: fmt.Sprintf("catalog key %q is already defined in %s", definition.key, previous),
+			})
+			continue
+		}
+		fileNames[definition.key] = fileName
+		definitions = append(definitions, definition)
+	}
+
+	if len(issues) > 0 {
+		return nil, nil, workbenchOptionalServiceCatalogInvalidError(trimmedDir, issues)
+	}
+	return definitions, fileNames, nil
+}
+
+func loadWorkbenchOptionalServiceCatalogFile(path string) (workbenchOptionalServiceDefinition, []WorkbenchOptionalServiceCatalogIssue) {
+	fileName := filepath.Base(path)
+	fail := func(code, message string) (workbenchOptionalServiceDefinition, []WorkbenchOptionalServiceCatalogIssue) {
+		return workbenchOptionalServiceDefinition{}, []WorkbenchOptionalServiceCatalogIssue{{
+			File:    fileName,
+			Path:    "$",
+			Code:    code,
+			Message: message,
+		}}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fail("WB-CATALOG-FILE-READ", fmt.Sprintf("failed to read catalog file: %v", err))
+	}
+	defer file.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(file, workbenchOptionalServiceCatalogMaxFileBytes+1))
+	if err != nil {
+		return fail("WB-CATALOG-FILE-READ", fmt.Sprintf("failed to read catalog file: %v", err))
+	}
+	if len(raw) > workbenchOptionalServiceCatalogMaxFileBytes {
+		return fail("WB-CATALOG-FILE-TOO-LARGE", fmt.Sprintf("catalog file exceeds %d bytes", workbenchOptionalServiceCatalogMaxFileBytes))
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	var parsed workbenchOptionalServiceCatalogFileDefinition
+	if err := decoder.Decode(&parsed); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fail("WB-CATALOG-FILE-EMPTY", "catalog file is empty")
+		}
+		return fail("WB-CATALOG-FILE-PARSE", fmt.Sprintf("failed to parse catalog file: %v", err))
+	}
+
+	return normalizeWorkbenchOptionalServiceCatalogFileDefinition(fileName, parsed)
+}
+
+func normalizeWorkbenchOptionalServiceCatalogFileDefinition(
+	fileName string,
+	parsed workbenchOptionalServiceCatalogFileDefinition,
+) (workbenchOptionalServiceDefinition, []WorkbenchOptionalServiceCatalogIssue) {
+	key := strings.ToLower(strings.TrimSpace(parsed.Key))
+	issues := []WorkbenchOptionalServiceCatalogIssue{}
+	addIssue := func(path, code, message string) {
+		issues = append(issues, WorkbenchOptionalServiceCatalogIssue{
+			File:    fileName,
+			Path:    path,
+			Key:     key,
+			Code:    code,
+			Message: message,
+		})
+	}
+
+	if !workbenchOptionalServiceKeyPattern.MatchString(key) {
+		addIssue("$.key", "WB-CATALOG-KEY-INVALID", "key is required and must be lowercase alphanumerics or dashes")
+	}
+
+	serviceName := strings.TrimSpace(parsed.ServiceName)
+	if serviceName == "" {
+		serviceName = key
+	}
+	if !workbenchOptionalServiceServiceNamePattern.MatchString(serviceName) {
+		addIssue("$.serviceName", "WB-CATALOG-SERVICE-NAME-INVALID", fmt.Sprintf("service name %q is not a valid compose service name", serviceName))
+	}
+
+	image := strings.TrimSpace(parsed.Image)
+	if image == "" {
+		addIssue("$.image", "WB-CATALOG-IMAGE-REQUIRED", "image is required")
+	} else if strings.ContainsAny(image, " \t\r\n") {
+		addIssue("$.image", "WB-CATALOG-IMAGE-INVALID", fmt.Sprintf("image %q must not contain whitespace", image))
+	}
+
+	restart := strings.TrimSpace(parsed.Restart)
+	if restart == "" {
+		restart = "unless-stopped"
+	}
+	switch restart {
+	case "no", "always", "unless-stopped", "on-failure":
+	default:
+		addIssue("$.restart", "WB-CATALOG-RESTART-INVALID", fmt.Sprintf("restart policy %q is not supported", restart))
+	}
+
+	ports := make([]workbenchOptionalServicePortDefinition, 0, len(parsed.Ports))
+	seenPorts := map[string]struct{}{}
+	for idx, port := range parsed.Ports {
+		path := fmt.Sprintf("$.ports[%d]", idx)
+		if port.ContainerPort < 1 || port.ContainerPort > 65535 {
+			addIssue(path+".containerPort", "WB-CATALOG-PORT-RANGE", fmt.Sprintf("containerPort %d is out of range", port.ContainerPort))
+			continue
+		}
+		protocol := strings.ToLower(strings.TrimSpace(port.Protocol))
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		if protocol != "tcp" && protocol != "udp" {
+			addIssue(path+".protocol", "WB-CATALOG-PORT-PROTOCOL", fmt.Sprintf("protocol %q must be tcp or udp", protocol))
+			continue
+		}
+		portKey := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+		if _, exists := seenPorts[portKey]; exists {
+			addIssue(path, "WB-CATALOG-PORT-DUPLICATE", fmt.Sprintf("port %s is declared more than once", portKey))
+			continue
+		}
+		seenPorts[portKey] = struct{}{}
+		ports = append(ports, workbenchOptionalServicePortDefinition{
+			containerPort: port.ContainerPort,
+			protocol:      protocol,
+			hostIP:        strings.TrimSpace(port.HostIP),
+		})
+	}
+
+	environment := make(map[string]string, len(parsed.Environment))
+	for envKey, value := range parsed.Environment {
+		trimmedKey := strings.TrimSpace(envKey)
+		if !workbenchOptionalServiceEnvKeyPattern.MatchString(trimmedKey) {
+			addIssue("$.environment."+trimmedKey, "WB-CATALOG-ENV-KEY-INVALID", fmt.Sprintf("environment key %q is invalid", envKey))
+			continue
+		}
+		environment[trimmedKey] = value
+	}
+
+	volumes := make([]workbenchOptionalServiceVolumeDefinition, 0, len(parsed.Volumes))
+	seenVolumeTargets := map[string]struct{}{}
+	for idx, volume := range parsed.Volumes {
+		path := fmt.Sprintf("$.volumes[%d]", idx)
+		name := strings.TrimSpace(volume.Name)
+		target := strings.TrimSpace(volume.Target)
+		if !workbenchOptionalServiceVolumeNamePattern.MatchString(name) {
+			addIssue(path+".name", "WB-CATALOG-VOLUME-NAME-INVALID", fmt.Sprintf("volume name %q must be a named volume, not a host path", name))
+			continue
+		}
+		if !strings.HasPrefix(target, "/") || strings.Contains(target, ":") {
+			addIssue(path+".target", "WB-CATALOG-VOLUME-TARGET-INVALID", fmt.Sprintf("volume target %q must be an absolute container path", target))
+			continue
+		}
+		if _, exists := seenVolumeTargets[target]; exists {
+			addIssue(path+".target", "WB-CATALOG-VOLUME-TARGET-DUPLICATE", fmt.Sprintf("volume target %q is mounted more than once", target))
+			continue
+		}
+		seenVolumeTargets[target] = struct{}{}
+		volumes = append(volumes, workbenchOptionalServiceVolumeDefinition{name: name, target: target})
+	}
+
+	var healthcheck *workbenchOptionalServiceHealthcheckDefinition
+	if parsed.Healthcheck != nil {
+		test := make([]string, 0, len(parsed.Healthcheck.Test))
+		for _, part := range parsed.Healthcheck.Test {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				test = append(test, trimmed)
+			}
+		}
+		if len(test) == 0 {
+			addIssue("$.healthcheck.test", "WB-CATALOG-HEALTHCHECK-TEST-REQUIRED", "healthcheck test is required")
+		} else {
+			switch test[0] {
+			case "CMD", "CMD-SHELL", "NONE":
+			default:
+				addIssue("$.healthcheck.test", "WB-CATALOG-HEALTHCHECK-TEST-INVALID", "healthcheck test must start with CMD, CMD-SHELL or NONE")
+			}
+		}
+		for field, value := range map[string]string{
+			"interval":    parsed.Healthcheck.Interval,
+			"timeout":     parsed.Healthcheck.Timeout,
+			"startPeriod": parsed.Healthcheck.StartPeriod,
+		} {
+			trimmed := strings.TrimSpace(value)
+			if trimmed != "" && !workbenchOptionalServiceDurationPattern.MatchString(trimmed) {
+				addIssue("$.healthcheck."+field, "WB-CATALOG-HEALTHCHECK-DURATION-INVALID", fmt.Sprintf("healthcheck %s %q is not a valid duration", field, trimmed))
+			}
+		}
+		if parsed.Healthcheck.Retries < 0 {
+			addIssue("$.healthcheck.retries", "WB-CATALOG-HEALTHCHECK-RETRIES-INVALID", "healthcheck retries must not be negative")
+		}
+		healthcheck = &workbenchOptionalServiceHealthcheckDefinition{
+			test:        test,
+			interval:    strings.TrimSpace(parsed.Healthcheck.Interval),
+			timeout:     strings.TrimSpace(parsed.Healthcheck.Timeout),
+			retries:     parsed.Healthcheck.Retries,
+			startPeriod: strings.TrimSpace(parsed.Healthcheck.StartPeriod),
+		}
+	}
+
+	dependsOn := make([]string, 0, len(parsed.DependsOn))
+	for idx, dependency := range parsed.DependsOn {
+		normalizedDependency := strings.ToLower(strings.TrimSpace(dependency))
+		if !workbenchOptionalServiceKeyPattern.MatchString(normalizedDependency) {
+			addIssue(fmt.Sprintf("$.dependsOn[%d]", idx), "WB-CATALOG-DEPENDENCY-INVALID", fmt.Sprintf("dependency %q is not a valid catalog key", dependency))
+			continue
+		}
+		if normalizedDependency == key {
+			addIssue(fmt.Sprintf("$.dependsOn[%d]", idx), "WB-CATALOG-DEPENDENCY-SELF", "entry cannot depend on itself")
+			continue
+		}
+		dependsOn = append(dependsOn, normalizedDependency)
+	}
+
+	connectionOutputs := make([]workbenchOptionalServiceConnectionOutputDefinition, 0, len(parsed.Connections))
+	seenOutputKeys := map[string]struct{}{}
+	for idx, output := range parsed.Connections {
+		path := fmt.Sprintf("$.connectionOutputs[%d]", idx)
+		outputKey := strings.TrimSpace(output.Key)
+		outputValue := strings.TrimSpace(output.Value)
+		if !workbenchOptionalServiceEnvKeyPattern.MatchString(outputKey) {
+			addIssue(path+".key", "WB-CATALOG-CONNECTION-KEY-INVALID", fmt.Sprintf("connection output key %q is invalid", output.Key))
+			continue
+		}
+		if outputValue == "" || strings.ContainsAny(outputValue, "\r\n") {
+			addIssue(path+".value", "WB-CATALOG-CONNECTION-VALUE-INVALID", fmt.Sprintf("connection output %q needs a single-line value", outputKey))
+			continue
+		}
+		if _, exists := seenOutputKeys[outputKey]; exists {
+			addIssue(path+".key", "WB-CATALOG-CONNECTION-KEY-DUPLICATE", fmt.Sprintf("connection output %q is declared more than once", outputKey))
+			continue
+		}
+		seenOutputKeys[outputKey] = struct{}{}
+		connectionOutputs = append(connectionOutputs, workbenchOptionalServiceConnectionOutputDefinition{key: outputKey, value: outputValue})
+	}
+
+	if len(issues) > 0 {
+		return workbenchOptionalServiceDefinition{}, issues
+	}
+
+	displayName := strings.TrimSpace(parsed.DisplayName)
+	if displayName == "" {
+		displayName = key
+	}
+	category := strings.ToLower(strings.TrimSpace(parsed.Category))
+	if category == "" {
+		category = "custom"
+	}
+	defaultContainerPort := 0
+	if len(ports) > 0 {
+		defaultContainerPort = ports[0].containerPort
+	}
+	serviceNameHints := workbenchOptionalServiceNormalizeHints(append([]string{serviceName}, parsed.ServiceHints...))
+	imageNameHints := workbenchOptionalServiceNormalizeHints(append([]string{workbenchOptionalServiceImageName(image)}, parsed.ImageHints...))
+	command := make([]string, 0, len(parsed.Command))
+	for _, part := range parsed.Command {
+		command = append(command, strings.TrimSpace(part))
+	}
+
+	return workbenchOptionalServiceDefinition{
+		key:                  key,
+		displayName:          displayName,
+		description:          strings.TrimSpace(parsed.Description),
+		category:             category,
+		defaultServiceName:   serviceName,
+		suggestedImage:       image,
+		defaultContainerPort: defaultContainerPort,
+		serviceNameHints:     serviceNameHints,
+		imageNameHints:       imageNameHints,
+		source:               workbenchOptionalServiceSourceFile,
+		dependsOn:            dependsOn,
+		connectionOutputs:    connectionOutputs,
+		runtime: workbenchOptionalServiceRuntimeDefinition{
+			image:         image,
+			restartPolicy: restart,
+			command:       command,
+			environment:   environment,
+			ports:         ports,
+			volumes:       volumes,
+			healthcheck:   healthcheck,
+		},
+	}, nil
+}
+
+// workbenchMergeOptionalServiceDefinitions overlays file definitions on the
+// built-ins: a file entry with a built-in key replaces it, but keeps the
+// built-in legacy module mapping so /workbench/modules stays compatible.
+func workbenchMergeOptionalServiceDefinitions(
+	fileDefinitions []workbenchOptionalServiceDefinition,
+	fileNames map[string]string,
+	sourceDir string,
+) ([]workbenchOptionalServiceDefinition, WorkbenchOptionalServiceCatalogStatus) {
+	byKey := make(map[string]workbenchOptionalServiceDefinition, len(fileDefinitions))
+	for _, definition := range fileDefinitions {
+		byKey[definition.key] = definition
+	}
+
+	merged := make([]workbenchOptionalServiceDefinition, 0, len(workbenchBuiltinOptionalServiceDefinitions)+len(fileDefinitions))
+	status := WorkbenchOptionalServiceCatalogStatus{
+		SourceDir: strings.TrimSpace(sourceDir),
+		Entries:   []WorkbenchOptionalServiceCatalogStatusKey{},
+	}
+	overridden := map[string]struct{}{}
+	for _, builtin := range workbenchBuiltinOptionalServiceDefinitions {
+		override, ok := byKey[builtin.key]
+		if !ok {
+			merged = append(merged, builtin)
+			status.BuiltinCount++
+			status.Entries = append(status.Entries, WorkbenchOptionalServiceCatalogStatusKey{
+				Key:    builtin.key,
+				Source: workbenchOptionalServiceSourceBuiltin,
+			})
+			continue
+		}
+		override.legacyModuleType = builtin.legacyModuleType
+		merged = append(merged, override)
+		overridden[builtin.key] = struct{}{}
+		status.FileCount++
+		status.Entries = append(status.Entries, WorkbenchOptionalServiceCatalogStatusKey{
+			Key:        override.key,
+			Source:     workbenchOptionalServiceSourceFile,
+			SourceFile: fileNames[override.key],
+			Overrides:  true,
+		})
+	}
+
+	extras := make([]workbenchOptionalServiceDefinition, 0, len(fileDefinitions))
+	for _, definition := range fileDefinitions {
+		if _, ok := overridden[definition.key]; ok {
+			continue
+		}
+		extras = append(extras, definition)
+	}
+	sort.SliceStable(extras, func(i, j int) bool {
+		return extras[i].key < extras[j].key
+	})
+	for _, definition := range extras {
+		merged = append(merged, definition)
+		status.FileCount++
+		status.Entries = append(status.Entries, WorkbenchOptionalServiceCatalogStatusKey{
+			Key:        definition.key,
+			Source:     workbenchOptionalServiceSourceFile,
+			SourceFile: fileNames[definition.key],
+		})
+	}
+	return merged, status
+}
+
+func workbenchValidateOptionalServiceDependencies(
+	definitions []workbenchOptionalServiceDefinition,
+	fileNames map[string]string,
+) []WorkbenchOptionalServiceCatalogIssue {
+	byKey := make(map[string]workbenchOptionalServiceDefinition, len(definitions))
+	serviceNames := make(map[string]string, len(definitions))
+	issues := []WorkbenchOptionalServiceCatalogIssue{}
+	for _, definition := range definitions {
+		byKey[definition.key] = definition
+		normalizedServiceName := strings.ToLower(definition.defaultServiceName)
+		if owner, exists := serviceNames[normalizedServiceName]; exists {
+			issues = append(issues, WorkbenchOptionalServiceCatalogIssue{
+				File:    fileNames[definition.key],
+				Path:    "$.serviceName",
+				Key:     definition.key,
+				Code:    "WB-CATALOG-SERVICE-NAME-DUPLICATE",
+				Message: fmt.Sprintf("service name %q is already used by catalog entry %q", definition.defaultServiceName, owner),
+			})
+			continue
+		}
+		serviceNames[normalizedServiceName] = definition.key
+	}
+
+	for _, definition := range definitions {
+		for idx, dependency := range definition.dependsOn {
+			if _, ok := byKey[dependency]; ok {
+				continue
+			}
+			issues = append(issues, WorkbenchOptionalServiceCatalogIssue{
+				File:    fileNames[definition.key],
+				Path:    fmt.Sprintf("$.dependsOn[%d]", idx),
+				Key:     definition.key,
+				Code:    "WB-CATALOG-DEPENDENCY-UNKNOWN",
+				Message: fmt.Sprintf("dependency %q is not a known catalog entry", dependency),
+			})
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(definitions))
+	var visit func(key string) bool
+	visit = func(key string) bool {
+		switch state[key] {
+		case visiting:
+			return false
+		case visited:
+			return true
+		}
+		state[key] = visiting
+		for _, dependency := range byKey[key].dependsOn {
+			if _, ok := byKey[dependency]; !ok {
+				continue
+			}
+			if !visit(dependency) {
+				return false
+			}
+		}
+		state[key] = visited
+		return true
+	}
+	for _, definition := range definitions {
+		if state[definition.key] != unvisited {
+			continue
+		}
+		if !visit(definition.key) {
+			issues = append(issues, WorkbenchOptionalServiceCatalogIssue{
+				File:    fileNames[definition.key],
+				Path:    "$.dependsOn",
+				Key:     definition.key,
+				Code:    "WB-CATALOG-DEPENDENCY-CYCLE",
+				Message: fmt.Sprintf("catalog entry %q is part of a dependency cycle", definition.key),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Code < issues[j].Code
+	})
+	return issues
+}
+
+func workbenchOptionalServiceNormalizeHints(hints []string) []string {
+	normalized := make([]string, 0, len(hints))
+	seen := make(map[string]struct{}, len(hints))
+	for _, hint := range hints {
+		trimmed := strings.ToLower(strings.TrimSpace(hint))
+		if trimmed == "" {
+			continue
+		}
+		if _, exists := seen[trimmed]; exists {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		normalized = append(normalized, trimmed)
+	}
+	return normalized
+}
+
+func workbenchOptionalServiceCatalogInvalidError(sourceDir string, issues []WorkbenchOptionalServiceCatalogIssue) error {
+	message := "invalid optional-service catalog definitions"
+	if len(issues) > 0 {
+		message = fmt.Sprintf("%s: %s %s: %s", message, issues[0].File, issues[0].Path, issues[0].Message)
+		if len(issues) > 1 {
+			message = fmt.Sprintf("%s (and %d more)", message, len(issues)-1)
+		}
+	}
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchCatalogInvalid, message),
+		map[string]any{
+			"sourceDir":  strings.TrimSpace(sourceDir),
+			"issueCount": len(issues),
+			"issues":     issues,
+		},
+	)
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-notes/internal/errs"
+)
+
+const workbenchCatalogTestWorkerDefinition = `key: queue-worker
+displayName: Queue Worker
+description: Background worker consuming the Redis queue.
+category: worker
+serviceName: worker
+image: ghcr.io/example/worker:1.4.2
+environment:
+  QUEUE_NAME: default
+ports:
+  - containerPort: 9000
+volumes:
+  - name: worker-data
+    target: /var/lib/worker
+healthcheck:
+  test: ["CMD", "worker", "ping"]
+  interval: 10s
+  timeout: 3s
+  retries: 5
+dependsOn:
+  - redis
+`
+
+func TestLoadWorkbenchOptionalServiceCatalogDirParsesDefinitions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeWorkbenchCatalogTestFile(t, dir, "worker.yaml", workbenchCatalogTestWorkerDefinition)
+	writeWorkbenchCatalogTestFile(t, dir, "README.md", "not a definition")
+
+	definitions, fileNames, err := loadWorkbenchOptionalServiceCatalogDir(dir)
+	if err != nil {
+		t.Fatalf("load catalog dir: %v", err)
+	}
+	if got, want := len(definitions), 1; got != want {
+		t.Fatalf("expected %d definition, got %d", want, got)
+	}
+	definition := definitions[0]
+	if definition.key != "queue-worker" || definition.defaultServiceName != "worker" {
+		t.Fatalf("unexpected definition identity: %#v", definition)
+	}
+	if definition.source != workbenchOptionalServiceSourceFile {
+		t.Fatalf("expected file source, got %q", definition.source)
+	}
+	if fileNames["queue-worker"] != "worker.yaml" {
+		t.Fatalf("expected source file worker.yaml, got %#v", fileNames)
+	}
+	if definition.runtime.restartPolicy != "unless-stopped" {
+		t.Fatalf("expected default restart policy, got %q", definition.runtime.restartPolicy)
+	}
+	if len(definition.runtime.ports) != 1 || definition.runtime.ports[0].protocol != "tcp" {
+		t.Fatalf("unexpected ports: %#v", definition.runtime.ports)
+	}
+	if len(definition.runtime.volumes) != 1 || definition.runtime.volumes[0] != (workbenchOptionalServiceVolumeDefinition{name: "worker-data", target: "/var/lib/worker"}) {
+		t.Fatalf("unexpected volumes: %#v", definition.runtime.volumes)
+	}
+	if definition.runtime.healthcheck == nil || definition.runtime.healthcheck.retries != 5 {
+		t.Fatalf("unexpected healthcheck: %#v", definition.runtime.healthcheck)
+	}
+	if len(definition.dependsOn) != 1 || definition.dependsOn[0] != "redis" {
+		t.Fatalf("unexpected dependsOn: %#v", definition.dependsOn)
+	}
+}
+
+func TestLoadWorkbenchOptionalServiceCatalogDirRejectsInvalidDefinitions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeWorkbenchCatalogTestFile(t, dir, "a-unknown-field.yaml", "key: alpha\nimage: alpine:3\nprivileged: true\n")
+	writeWorkbenchCatalogTestFile(t, dir, "b-bad-port.yaml", "key: bravo\nimage: alpine:3\nports:\n  - containerPort: 70000\n")
+	writeWorkbenchCatalogTestFile(t, dir, "c-bind-mount.yaml", "key: charlie\nimage: alpine:3\nvolumes:\n  - name: /srv/data\n    target: /data\n")
+	writeWorkbenchCatalogTestFile(t, dir, "d-first.yaml", "key: delta\nimage: alpine:3\n")
+	writeWorkbenchCatalogTestFile(t, dir, "e-duplicate.yml", "key: delta\nimage: alpine:3\n")
+
+	_, _, err := loadWorkbenchOptionalServiceCatalogDir(dir)
+	if err == nil {
+		t.Fatal("expected invalid catalog error")
+	}
+	issues := workbenchCatalogTestIssues(t, err)
+	gotCodes := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		gotCodes = append(gotCodes, issue.File+":"+issue.Code)
+	}
+	wantCodes := []string{
+		"a-unknown-field.yaml:WB-CATALOG-FILE-PARSE",
+		"b-bad-port.yaml:WB-CATALOG-PORT-RANGE",
+		"c-bind-mount.yaml:WB-CATALOG-VOLUME-NAME-INVALID",
+		"e-duplicate.yml:WB-CATALOG-KEY-DUPLICATE",
+	}
+	if strings.Join(gotCodes, ",") != strings.Join(wantCodes, ",") {
+		t.Fatalf("unexpected issues\nwant=%v\ngot=%v", wantCodes, gotCodes)
+	}
+}
+
+func TestWorkbenchValidateOptionalServiceDependenciesRejectsUnknownAndCycles(t *testing.T) {
+	t.Parallel()
+
+	definitions := []workbenchOptionalServiceDefinition{
+		{key: "alpha", defaultServiceName: "alpha", dependsOn: []string{"bravo"}},
+		{key: "bravo", defaultServiceName: "bravo", dependsOn: []string{"alpha"}},
+		{key: "charlie", defaultServiceName: "charlie", dependsOn: []string{"missing"}},
+	}
+
+	issues := workbenchValidateOptionalServiceDependencies(definitions, map[string]string{})
+	codes := map[string]bool{}
+	for _, issue := range issues {
+		codes[issue.Code] = true
+	}
+	if !codes["WB-CATALOG-DEPENDENCY-CYCLE"] {
+		t.Fatalf("expected dependency cycle issue, got %#v", issues)
+	}
+	if !codes["WB-CATALOG-DEPENDENCY-UNKNOWN"] {
+		t.Fatalf("expected unknown dependency issue, got %#v", issues)
+	}
+}
+
+// Tests below swap the process-wide catalog, so they must not run in parallel.
+
+func TestWorkbenchLoadOptionalServiceCatalogMergesFileEntries(t *testing.T) {
+	restoreWorkbenchOptionalServiceCatalog(t)
+
+	catalogDir := t.TempDir()
+	writeWorkbenchCatalogTestFile(t, catalogDir, "worker.yaml", workbenchCatalogTestWorkerDefinition)
+	writeWorkbenchCatalogTestFile(t, catalogDir, "nginx.yaml", "key: nginx\ndisplayName: Hardened NGINX\nimage: nginxinc/nginx-unprivileged:1.27\nports:\n  - containerPort: 8080\n")
+
+	svc := NewWorkbenchServiceWithStorage(t.TempDir(), nil, &fakeSettingsRepo{}, "test-session-secret")
+	status, err := svc.LoadOptionalServiceCatalog(catalogDir)
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	if status.BuiltinCount != 3 || status.FileCount != 2 {
+		t.Fatalf("unexpected catalog counts: %#v", status)
+	}
+
+	nginx, ok := workbenchOptionalServiceDefinitionByKey("nginx")
+	if !ok {
+		t.Fatal("expected nginx entry to stay available")
+	}
+	if nginx.source != workbenchOptionalServiceSourceFile || nginx.defaultContainerPort != 8080 {
+		t.Fatalf("expected nginx file override, got %#v", nginx)
+	}
+	if nginx.legacyModuleType != "" {
+		t.Fatalf("expected nginx override to keep built-in legacy mapping, got %q", nginx.legacyModuleType)
+	}
+	if _, ok := workbenchOptionalServiceDefinitionByKey("queue-worker"); !ok {
+		t.Fatal("expected file-only entry to be active")
+	}
+
+	writeWorkbenchCatalogTestFile(t, catalogDir, "broken.yaml", "key: broken\n")
+	if _, err := svc.ReloadOptionalServiceCatalog(context.Background()); err == nil {
+		t.Fatal("expected reload to fail on invalid definition")
+	} else if typed, ok := errs.From(err); !ok || typed.Code != errs.CodeWorkbenchCatalogInvalid {
+		t.Fatalf("expected %q, got %v", errs.CodeWorkbenchCatalogInvalid, err)
+	}
+	if _, ok := workbenchOptionalServiceDefinitionByKey("queue-worker"); !ok {
+		t.Fatal("expected failed reload to keep the previous catalog active")
+	}
+}
+
+func TestWorkbenchOptionalServiceCatalogConcurrentLoadsKeepLastDirectory(t *testing.T) {
+	restoreWorkbenchOptionalServiceCatalog(t)
+
+	catalogDir := t.TempDir()
+	writeWorkbenchCatalogTestFile(t, catalogDir, "worker.yaml", workbenchCatalogTestWorkerDefinition)
+
+	svc := NewWorkbenchServiceWithStorage(t.TempDir(), nil, &fakeSettingsRepo{}, "test-session-secret")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.LoadOptionalServiceCatalog(catalogDir); err != nil {
+				t.Errorf("load catalog: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := svc.ReloadOptionalServiceCatalog(context.Background()); err != nil {
+				t.Errorf("reload catalog: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	status, err := svc.ReloadOptionalServiceCatalog(context.Background())
+	if err != nil {
+		t.Fatalf("reload catalog: %v", err)
+	}
+	if status.SourceDir != catalogDir || status.FileCount != 1 {
+		t.Fatalf("expected reload to use %s, got %#v", catalogDir, status)
+	}
+}
+
+func TestWorkbenchOptionalServiceDependenciesGuardMutations(t *testing.T) {
+	restoreWorkbenchOptionalServiceCatalog(t)
+
+	catalogDir := t.TempDir()
+	writeWorkbenchCatalogTestFile(t, catalogDir, "worker.yaml", workbenchCatalogTestWorkerDefinition)
+
+	svc := NewWorkbenchServiceWithStorage(t.TempDir(), nil, &fakeSettingsRepo{}, "test-session-secret")
+	if _, err := svc.LoadOptionalServiceCatalog(catalogDir); err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	initial := WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Revision:    1,
+		Services: []WorkbenchComposeService{
+			{ServiceName: "api", Image: "ghcr.io/example/api:1.0.0"},
+		},
+	}
+	if err := svc.saveWorkbenchSnapshot(context.Background(), "demo", initial); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+
+	_, _, err := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "queue-worker"})
+	assertWorkbenchOptionalServiceIssueCode(t, err, "WB-OPTIONAL-SERVICE-DEPENDENCY-MISSING")
+
+	if _, _, err := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "redis"}); err != nil {
+		t.Fatalf("add redis: %v", err)
+	}
+	snapshot, _, err := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "queue-worker"})
+	if err != nil {
+		t.Fatalf("add worker: %v", err)
+	}
+
+	compose, err := generateWorkbenchCompose(snapshot)
+	if err != nil {
+		t.Fatalf("generate compose: %v", err)
+	}
+	for _, fragment := range []string{
+		"worker-data:/var/lib/worker",
+		"healthcheck:",
+		"depends_on:\n      - redis",
+		"  worker-data: {}",
+	} {
+		if !strings.Contains(compose, fragment) {
+			t.Fatalf("expected generated compose to contain %q\n%s", fragment, compose)
+		}
+	}
+
+	_, _, err = svc.RemoveOptionalService(context.Background(), "demo", "redis")
+	assertWorkbenchOptionalServiceIssueCode(t, err, "WB-OPTIONAL-SERVICE-DEPENDED-ON")
+}
+
+func restoreWorkbenchOptionalServiceCatalog(t *testing.T) {
+	t.Helper()
+
+	definitions := workbenchActiveOptionalServiceDefinitions()
+	status := workbenchOptionalServiceCatalog.currentStatus()
+	t.Cleanup(func() {
+		workbenchOptionalServiceCatalog.replace(definitions, status)
+	})
+}
+
+func writeWorkbenchCatalogTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func workbenchCatalogTestIssues(t *testing.T, opErr error) []WorkbenchOptionalServiceCatalogIssue {
+	t.Helper()
+
+	typed, ok := errs.From(opErr)
+	if !ok {
+		t.Fatalf("expected typed error, got %T", opErr)
+	}
+	if typed.Code != errs.CodeWorkbenchCatalogInvalid {
+		t.Fatalf("expected code %q, got %q", errs.CodeWorkbenchCatalogInvalid, typed.Code)
+	}
+	details, ok := typed.Details.(map[string]any)
+	if !ok {
+		t.Fatalf("expected details map, got %T", typed.Details)
+	}
+	issues, ok := details["issues"].([]WorkbenchOptionalServiceCatalogIssue)
+	if !ok {
+		t.Fatalf("expected []WorkbenchOptionalServiceCatalogIssue, got %T", details["issues"])
+	}
+	return issues
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"go-notes/internal/errs"
+)
+
+const workbenchConnectionOutputServicePlaceholder = "{{serviceName}}"
+
+type workbenchOptionalServiceConnectionOutputDefinition struct {
+	key   string
+	value string
+}
+
+// WorkbenchOptionalServiceConnectionOutput is an env var an app service needs
+// to reach a managed optional service, rendered for its compose service name.
+type WorkbenchOptionalServiceConnectionOutput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func workbenchRenderOptionalServiceConnectionOutputs(
+	definition workbenchOptionalServiceDefinition,
+	serviceName string,
+) []WorkbenchOptionalServiceConnectionOutput {
+	outputs := make([]WorkbenchOptionalServiceConnectionOutput, 0, len(definition.connectionOutputs))
+	for _, output := range definition.connectionOutputs {
+		outputs = append(outputs, WorkbenchOptionalServiceConnectionOutput{
+			Key:   output.key,
+			Value: strings.ReplaceAll(output.value, workbenchConnectionOutputServicePlaceholder, strings.TrimSpace(serviceName)),
+		})
+	}
+	return outputs
+}
+
+// writeOptionalServiceConnectionEnv appends connection outputs to the project
+// .env. Keys the project already defines are left untouched so operator
+// overrides survive re-adding a service. The returned restore func puts the
+// previous .env back; it is nil when nothing was written.
+func (s *WorkbenchService) writeOptionalServiceConnectionEnv(
+	ctx context.Context,
+	projectName string,
+	outputs []WorkbenchOptionalServiceConnectionOutput,
+) ([]string, []string, func(context.Context) error, error) {
+	added := []string{}
+	existing := []string{}
+	if len(outputs) == 0 {
+		return added, existing, nil, nil
+	}
+
+	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	envPath := resolved.EnvPath
+	if !isPathWithinBase(resolved.ProjectDir, envPath) {
+		return nil, nil, nil, errs.New(errs.CodeProjectEnvWriteFailed, "unsafe .env path")
+	}
+	if info, statErr := os.Lstat(envPath); statErr == nil && info.Mode()&os.ModeSymlink != 0 {
+		return nil, nil, nil, errs.New(errs.CodeProjectEnvWriteFailed, "refusing to write through symlinked .env")
+	}
+
+	content := ""
+	envExisted := false
+	raw, err := os.ReadFile(envPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil, errs.Wrap(errs.CodeProjectEnvReadFailed, "failed to read .env", err)
+	}
+	if err == nil {
+		content = string(raw)
+		envExisted = true
+	}
+	if int64(len(content)) > defaultProjectEnvMaxBytes {
+		return nil, nil, nil, errs.New(errs.CodeProjectEnvTooLarge, ".env exceeds max size")
+	}
+
+	declared := workbenchEnvFileKeys(content)
+	var builder strings.Builder
+	builder.WriteString(content)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		builder.WriteString("\n")
+	}
+	for _, output := range outputs {
+		if _, ok := declared[output.Key]; ok {
+			existing = append(existing, output.Key)
+			continue
+		}
+		declared[output.Key] = struct{}{}
+		builder.WriteString(output.Key)
+		builder.WriteString("=")
+		builder.WriteString(output.Value)
+		builder.WriteString("\n")
+		added = append(added, output.Key)
+	}
+	if len(added) == 0 {
+		return added, existing, nil, nil
+	}
+
+	if err := s.writeWorkbenchFileAtomically(ctx, resolved.ProjectDir, envPath, []byte(builder.String()), 0o600, true); err != nil {
+		return nil, nil, nil, errs.Wrap(errs.CodeProjectEnvWriteFailed, "failed to write connection outputs to .env", err)
+	}
+	restore := func(ctx context.Context) error {
+		if !envExisted {
+			return s.removeWorkbenchPath(ctx, resolved.ProjectDir, envPath, true)
+		}
+		return s.writeWorkbenchFileAtomically(ctx, resolved.ProjectDir, envPath, []byte(content), 0o600, true)
+	}
+	return added, existing, restore, nil
+}
+
+func workbenchEnvFileKeys(content string) map[string]struct{} {
+	keys := map[string]struct{}{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, _, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if trimmed := strings.TrimSpace(key); trimmed != "" {
+			keys[trimmed] = struct{}{}
+		}
+	}
+	return keys
+}
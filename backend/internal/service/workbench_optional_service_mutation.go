@@ -17,17 +17,24 @@ const (
 
 type WorkbenchOptionalServiceAddRequest struct {
 	EntryKey string `json:"entryKey"`
+	// AppService optionally names the imported compose service that consumes
+	// the new service; it gets a depends_on edge and the connection outputs.
+	AppService string `json:"appService,omitempty"`
 }
 
 type WorkbenchOptionalServiceMutationSummary struct {
-	Changed                bool     `json:"changed"`
-	Action                 string   `json:"action"`
-	EntryKey               string   `json:"entryKey,omitempty"`
-	ServiceName            string   `json:"serviceName,omitempty"`
-	PreviousCount          int      `json:"previousCount"`
-	CurrentCount           int      `json:"currentCount"`
-	ComposeGenerationReady bool     `json:"composeGenerationReady"`
-	Notes                  []string `json:"notes,omitempty"`
+	Changed                bool                                       `json:"changed"`
+	Action                 string                                     `json:"action"`
+	EntryKey               string                                     `json:"entryKey,omitempty"`
+	ServiceName            string                                     `json:"serviceName,omitempty"`
+	AppService             string                                     `json:"appService,omitempty"`
+	PreviousCount          int                                        `json:"previousCount"`
+	CurrentCount           int                                        `json:"currentCount"`
+	ComposeGenerationReady bool                                       `json:"composeGenerationReady"`
+	ConnectionOutputs      []WorkbenchOptionalServiceConnectionOutput `json:"connectionOutputs,omitempty"`
+	EnvKeysAdded           []string                                   `json:"envKeysAdded,omitempty"`
+	EnvKeysExisting        []string                                   `json:"envKeysExisting,omitempty"`
+	Notes                  []string                                   `json:"notes,omitempty"`
 }
 
 func (s *WorkbenchService) AddOptionalService(
@@ -35,7 +42,7 @@ func (s *WorkbenchService) AddOptionalService(
 	projectName string,
 	input WorkbenchOptionalServiceAddRequest,
 ) (WorkbenchStackSnapshot, WorkbenchOptionalServiceMutationSummary, error) {
-	return s.mutateOptionalService(ctx, projectName, workbenchOptionalServiceMutationActionAdd, strings.TrimSpace(input.EntryKey))
+	return s.mutateOptionalService(ctx, projectName, workbenchOptionalServiceMutationActionAdd, strings.TrimSpace(input.EntryKey), strings.TrimSpace(input.AppService))
 }
 
 func (s *WorkbenchService) RemoveOptionalService(
@@ -43,7 +50,7 @@ func (s *WorkbenchService) RemoveOptionalService(
 	projectName string,
 	serviceName string,
 ) (WorkbenchStackSnapshot, WorkbenchOptionalServiceMutationSummary, error) {
-	return s.mutateOptionalService(ctx, projectName, workbenchOptionalServiceMutationActionRemove, strings.TrimSpace(serviceName))
+	return s.mutateOptionalService(ctx, projectName, workbenchOptionalServiceMutationActionRemove, strings.TrimSpace(serviceName), "")
 }
 
 func (s *WorkbenchService) mutateOptionalService(
@@ -51,6 +58,7 @@ func (s *WorkbenchService) mutateOptionalService(
 	projectName string,
 	action string,
 	target string,
+	appService string,
 ) (WorkbenchStackSnapshot, WorkbenchOptionalServiceMutationSummary, error) {
 	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
 	if err != nil {
@@ -59,6 +67,7 @@ func (s *WorkbenchService) mutateOptionalService(
 
 	normalizedAction := strings.ToLower(strings.TrimSpace(action))
 	summary, issues := normalizeWorkbenchOptionalServiceMutation(normalizedAction, target)
+	summary.AppService = strings.TrimSpace(appService)
 	if len(issues) > 0 {
 		return WorkbenchStackSnapshot{}, summary, workbenchOptionalServiceMutationValidationError(WorkbenchStackSnapshot{}, summary, issues)
 	}
@@ -90,11 +99,32 @@ func (s *WorkbenchService) mutateOptionalService(
 		return mutated, mutationSummary, nil
 	}
 
+	var restoreEnv func(context.Context) error
+	if mutationSummary.AppService != "" && len(mutationSummary.ConnectionOutputs) > 0 {
+		added, existing, restore, envErr := s.writeOptionalServiceConnectionEnv(ctx, normalizedProject, mutationSummary.ConnectionOutputs)
+		if envErr != nil {
+			return mutated, mutationSummary, envErr
+		}
+		mutationSummary.EnvKeysAdded = added
+		mutationSummary.EnvKeysExisting = existing
+		restoreEnv = restore
+	}
+
 	if mutated.Revision <= 0 {
 		mutated.Revision = 1
 	}
 	mutated.Revision++
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, mutated); err != nil {
+	mutationType := WorkbenchMutationOptionalServiceAdd
+	if normalizedAction == workbenchOptionalServiceMutationActionRemove {
+		mutationType = WorkbenchMutationOptionalServiceRemove
+	}
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, mutated, mutationType, ""); err != nil {
+		// Without the saved snapshot the new .env keys would belong to no service.
+		if restoreEnv != nil {
+			if restoreErr := restoreEnv(ctx); restoreErr != nil {
+				return mutated, mutationSummary, fmt.Errorf("%w (restoring .env also failed: %v)", err, restoreErr)
+			}
+		}
 		return mutated, mutationSummary, err
 	}
 	return mutated, mutationSummary, nil
@@ -108,7 +138,7 @@ func normalizeWorkbenchOptionalServiceMutation(
 		Action:                 action,
 		ComposeGenerationReady: true,
 		Notes: []string{
-			"Compose preview/apply now renders catalog-managed services from frozen backend catalog definitions.",
+			"Compose preview/apply now renders catalog-managed services from the active backend catalog definitions (built-ins merged with file-loaded entries).",
 			"Port resolution now includes baseline container-port planning for catalog-managed services.",
 		},
 	}
@@ -214,9 +244,53 @@ func mutateWorkbenchOptionalService(
 			break
 		}
 
+		missingDependency := false
+		for _, dependency := range definition.dependsOn {
+			if workbenchCountManagedOptionalServicesByEntryKey(normalizedSnapshot.ManagedServices, dependency) > 0 {
+				continue
+			}
+			missingDependency = true
+			issues = append(issues, WorkbenchMutationIssue{
+				Class:    workbenchMutationIssueClassConflict,
+				Code:     "WB-OPTIONAL-SERVICE-DEPENDENCY-MISSING",
+				Path:     "$.entryKey",
+				Message:  fmt.Sprintf("optional service %q requires catalog entry %q to be added first", definition.key, dependency),
+				EntryKey: definition.key,
+				Service:  definition.defaultServiceName,
+				Action:   summary.Action,
+			})
+		}
+		if missingDependency {
+			break
+		}
+
+		appService := ""
+		if summary.AppService != "" {
+			appService = workbenchFindSnapshotServiceName(normalizedSnapshot.Services, summary.AppService)
+			if appService == "" {
+				issues = append(issues, WorkbenchMutationIssue{
+					Class:    workbenchMutationIssueClassSchema,
+					Code:     "WB-OPTIONAL-SERVICE-APP-SERVICE-NOT-FOUND",
+					Path:     "$.appService",
+					Message:  fmt.Sprintf("app service %q is not an imported compose service", summary.AppService),
+					EntryKey: definition.key,
+					Service:  summary.AppService,
+					Action:   summary.Action,
+				})
+				break
+			}
+			summary.AppService = appService
+			summary.ConnectionOutputs = workbenchRenderOptionalServiceConnectionOutputs(definition, definition.defaultServiceName)
+			next.Dependencies = append(next.Dependencies, WorkbenchComposeDependency{
+				ServiceName: appService,
+				DependsOn:   definition.defaultServiceName,
+			})
+		}
+
 		next.ManagedServices = append(next.ManagedServices, WorkbenchManagedService{
 			EntryKey:    definition.key,
 			ServiceName: definition.defaultServiceName,
+			AppService:  appService,
 		})
 	case workbenchOptionalServiceMutationActionRemove:
 		index := workbenchFindManagedOptionalServiceByServiceName(normalizedSnapshot.ManagedServices, summary.ServiceName)
@@ -242,6 +316,18 @@ func mutateWorkbenchOptionalService(
 		summary.EntryKey = target.EntryKey
 		summary.ServiceName = target.ServiceName
 		summary.PreviousCount = workbenchCountManagedOptionalServicesByServiceName(normalizedSnapshot.ManagedServices, target.ServiceName)
+		if dependents := workbenchManagedOptionalServiceDependents(normalizedSnapshot.ManagedServices, target.EntryKey); len(dependents) > 0 {
+			issues = append(issues, WorkbenchMutationIssue{
+				Class:    workbenchMutationIssueClassConflict,
+				Code:     "WB-OPTIONAL-SERVICE-DEPENDED-ON",
+				Path:     "$.serviceName",
+				Message:  fmt.Sprintf("catalog-managed service %q is required by %s", target.ServiceName, strings.Join(dependents, ", ")),
+				EntryKey: target.EntryKey,
+				Service:  target.ServiceName,
+				Action:   summary.Action,
+			})
+			break
+		}
 
 		filtered := make([]WorkbenchManagedService, 0, len(next.ManagedServices))
 		for _, managedService := range next.ManagedServices {
@@ -254,6 +340,11 @@ func mutateWorkbenchOptionalService(
 		next.Ports = workbenchFilterPortsByServiceName(next.Ports, target.ServiceName)
 		next.Resources = workbenchFilterResourcesByServiceName(next.Resources, target.ServiceName)
 		next.Dependencies = workbenchFilterDependenciesByServiceName(next.Dependencies, target.ServiceName)
+		next.Dependencies = workbenchFilterDependenciesByDependsOn(next.Dependencies, target.ServiceName)
+		summary.AppService = target.AppService
+		if target.AppService != "" {
+			summary.Notes = append(summary.Notes, fmt.Sprintf("Connection env values written to .env for %q are left in place; remove them manually if no longer needed.", target.AppService))
+		}
 		next.NetworkRefs = workbenchFilterNetworkRefsByServiceName(next.NetworkRefs, target.ServiceName)
 		next.VolumeRefs = workbenchFilterVolumeRefsByServiceName(next.VolumeRefs, target.ServiceName)
 		next.EnvRefs = workbenchFilterEnvRefsByServiceName(next.EnvRefs, target.ServiceName)
@@ -313,6 +404,17 @@ func workbenchFilterDependenciesByServiceName(dependencies []WorkbenchComposeDep
 	return filtered
 }
 
+func workbenchFilterDependenciesByDependsOn(dependencies []WorkbenchComposeDependency, serviceName string) []WorkbenchComposeDependency {
+	filtered := make([]WorkbenchComposeDependency, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		if strings.EqualFold(strings.TrimSpace(dependency.DependsOn), strings.TrimSpace(serviceName)) {
+			continue
+		}
+		filtered = append(filtered, dependency)
+	}
+	return filtered
+}
+
 func workbenchFilterNetworkRefsByServiceName(networkRefs []WorkbenchComposeNetworkRef, serviceName string) []WorkbenchComposeNetworkRef {
 	filtered := make([]WorkbenchComposeNetworkRef, 0, len(networkRefs))
 	for _, networkRef := range networkRefs {
@@ -379,6 +481,43 @@ func workbenchFindManagedOptionalServiceByServiceName(services []WorkbenchManage
 	return -1
 }
 
+func workbenchFindSnapshotServiceName(services []WorkbenchComposeService, serviceName string) string {
+	target := strings.TrimSpace(serviceName)
+	if target == "" {
+		return ""
+	}
+	for _, service := range services {
+		if strings.EqualFold(strings.TrimSpace(service.ServiceName), target) {
+			return strings.TrimSpace(service.ServiceName)
+		}
+	}
+	return ""
+}
+
+// workbenchManagedOptionalServiceDependents lists managed service names whose
+// catalog definition depends on entryKey.
+func workbenchManagedOptionalServiceDependents(services []WorkbenchManagedService, entryKey string) []string {
+	target := strings.ToLower(strings.TrimSpace(entryKey))
+	if target == "" {
+		return nil
+	}
+	dependents := []string{}
+	for _, managedService := range services {
+		definition, ok := workbenchOptionalServiceDefinitionByKey(managedService.EntryKey)
+		if !ok {
+			continue
+		}
+		for _, dependency := range definition.dependsOn {
+			if dependency == target {
+				dependents = append(dependents, strings.TrimSpace(managedService.ServiceName))
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
 func workbenchOptionalServiceMutationValidationError(
 	snapshot WorkbenchStackSnapshot,
 	summary WorkbenchOptionalServiceMutationSummary,
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"go-notes/internal/errs"
@@ -152,6 +156,122 @@ func TestWorkbenchRemoveOptionalServiceUpdatesManagedStateAndRejectsMissing(t *t
 	assertWorkbenchOptionalServiceIssueCode(t, missingErr, "WB-OPTIONAL-SERVICE-NOT-FOUND")
 }
 
+func TestWorkbenchAddOptionalServiceRestoresEnvWhenSnapshotSaveFails(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte("services:\n  api:\n    image: ghcr.io/example/api:1.0.0\n"), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".env"), []byte("APP_ENV=prod\n"), 0o600); err != nil {
+		t.Fatalf("write env: %v", err)
+	}
+
+	settingsRepo := &fakeSettingsRepo{}
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, settingsRepo, "test-session-secret")
+	initial := WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Revision:    2,
+		Services: []WorkbenchComposeService{
+			{ServiceName: "api", Image: "ghcr.io/example/api:1.0.0"},
+		},
+	}
+	if err := svc.saveWorkbenchSnapshot(context.Background(), "demo", initial); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+	settingsRepo.saveErr = errors.New("database is locked")
+
+	if _, _, err := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "redis", AppService: "api"}); err == nil {
+		t.Fatal("expected snapshot save failure")
+	}
+
+	envContent, err := os.ReadFile(filepath.Join(projectDir, ".env"))
+	if err != nil {
+		t.Fatalf("read env: %v", err)
+	}
+	if string(envContent) != "APP_ENV=prod\n" {
+		t.Fatalf("expected .env to be restored after failed save, got %q", string(envContent))
+	}
+}
+
+func TestWorkbenchAddOptionalServiceWiresAppServiceConnection(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte("services:\n  api:\n    image: ghcr.io/example/api:1.0.0\n"), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".env"), []byte("APP_ENV=prod"), 0o600); err != nil {
+		t.Fatalf("write env: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	initial := WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Revision:    2,
+		Services: []WorkbenchComposeService{
+			{ServiceName: "api", Image: "ghcr.io/example/api:1.0.0"},
+		},
+	}
+	if err := svc.saveWorkbenchSnapshot(context.Background(), "demo", initial); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+
+	_, _, missingErr := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "redis", AppService: "worker"})
+	assertWorkbenchOptionalServiceIssueCode(t, missingErr, "WB-OPTIONAL-SERVICE-APP-SERVICE-NOT-FOUND")
+
+	snapshot, summary, err := svc.AddOptionalService(context.Background(), "demo", WorkbenchOptionalServiceAddRequest{EntryKey: "redis", AppService: "API"})
+	if err != nil {
+		t.Fatalf("AddOptionalService: %v", err)
+	}
+	if summary.AppService != "api" {
+		t.Fatalf("expected canonical app service api, got %q", summary.AppService)
+	}
+	if !reflect.DeepEqual(summary.EnvKeysAdded, []string{"REDIS_URL"}) {
+		t.Fatalf("expected REDIS_URL to be added to .env, got %#v", summary.EnvKeysAdded)
+	}
+	if snapshot.ManagedServices[0] != (WorkbenchManagedService{EntryKey: "redis", ServiceName: "redis", AppService: "api"}) {
+		t.Fatalf("unexpected managed service record: %#v", snapshot.ManagedServices[0])
+	}
+	if !reflect.DeepEqual(snapshot.Dependencies, []WorkbenchComposeDependency{{ServiceName: "api", DependsOn: "redis"}}) {
+		t.Fatalf("expected api -> redis dependency, got %#v", snapshot.Dependencies)
+	}
+
+	envContent, err := os.ReadFile(filepath.Join(projectDir, ".env"))
+	if err != nil {
+		t.Fatalf("read env: %v", err)
+	}
+	if string(envContent) != "APP_ENV=prod\nREDIS_URL=redis://redis:6379\n" {
+		t.Fatalf("unexpected .env content %q", string(envContent))
+	}
+
+	compose, err := generateWorkbenchCompose(snapshot)
+	if err != nil {
+		t.Fatalf("generate compose: %v", err)
+	}
+	for _, fragment := range []string{"REDIS_URL: ${REDIS_URL}", "- redis-data:/data", "  redis-data: {}"} {
+		if !strings.Contains(compose, fragment) {
+			t.Fatalf("expected generated compose to contain %q\n%s", fragment, compose)
+		}
+	}
+
+	removed, _, err := svc.RemoveOptionalService(context.Background(), "demo", "redis")
+	if err != nil {
+		t.Fatalf("RemoveOptionalService: %v", err)
+	}
+	if len(removed.Dependencies) != 0 {
+		t.Fatalf("expected app dependency edge to be removed, got %#v", removed.Dependencies)
+	}
+}
+
 func assertWorkbenchOptionalServiceIssueCode(t *testing.T, opErr error, expectedCode string) {
 	t.Helper()
 
@@ -12,12 +12,27 @@ type workbenchOptionalServicePortDefinition struct {
 	hostIP        string
 }
 
+type workbenchOptionalServiceVolumeDefinition struct {
+	name   string
+	target string
+}
+
+type workbenchOptionalServiceHealthcheckDefinition struct {
+	test        []string
+	interval    string
+	timeout     string
+	retries     int
+	startPeriod string
+}
+
 type workbenchOptionalServiceRuntimeDefinition struct {
 	image         string
 	restartPolicy string
 	command       []string
 	environment   map[string]string
 	ports         []workbenchOptionalServicePortDefinition
+	volumes       []workbenchOptionalServiceVolumeDefinition
+	healthcheck   *workbenchOptionalServiceHealthcheckDefinition
 }
 
 type workbenchComposeEnvironmentEntry struct {
@@ -25,10 +40,28 @@ type workbenchComposeEnvironmentEntry struct {
 	Value string
 }
 
+type workbenchComposeVolumeMount struct {
+	Name   string
+	Target string
+}
+
+type workbenchComposeHealthcheck struct {
+	Test        []string
+	Interval    string
+	Timeout     string
+	Retries     int
+	StartPeriod string
+}
+
 type workbenchComposeServiceExtras struct {
 	Managed     bool
 	Command     []string
 	Environment []workbenchComposeEnvironmentEntry
+	Volumes     []workbenchComposeVolumeMount
+	Healthcheck *workbenchComposeHealthcheck
+	// ConnectionEnvironment holds env references an imported app service
+	// receives from the managed services wired to it.
+	ConnectionEnvironment []workbenchComposeEnvironmentEntry
 }
 
 type workbenchManagedServiceModel struct {
@@ -36,6 +69,9 @@ type workbenchManagedServiceModel struct {
 	ServiceName string
 	Service     WorkbenchComposeService
 	Ports       []WorkbenchComposePort
+	DependsOn   []string
+	AppService  string
+	Connections []WorkbenchOptionalServiceConnectionOutput
 	Extras      workbenchComposeServiceExtras
 }
 
@@ -131,6 +167,20 @@ func workbenchBuildManagedServiceModels(snapshot WorkbenchStackSnapshot) ([]work
 			continue
 		}
 
+		appService := strings.TrimSpace(managedService.AppService)
+		if appService != "" {
+			if _, exists := importedServiceNames[strings.ToLower(appService)]; !exists {
+				issues = append(issues, workbenchManagedServiceIssue{
+					Code:     "WB-MANAGED-SERVICE-APP-SERVICE-MISSING",
+					Path:     path + ".appService",
+					Message:  fmt.Sprintf("managed service %q is wired to app service %q, which is not an imported compose service", serviceName, appService),
+					EntryKey: entryKey,
+					Service:  serviceName,
+				})
+				continue
+			}
+		}
+
 		seenEntryKeys[entryKey] = struct{}{}
 		seenServiceNames[normalizedServiceName] = struct{}{}
 
@@ -142,11 +192,37 @@ func workbenchBuildManagedServiceModels(snapshot WorkbenchStackSnapshot) ([]work
 				Image:         strings.TrimSpace(definition.runtime.image),
 				RestartPolicy: strings.TrimSpace(definition.runtime.restartPolicy),
 			},
-			Ports:  workbenchOptionalServicePorts(definition.runtime.ports, serviceName),
-			Extras: workbenchOptionalServiceExtras(definition.runtime),
+			Ports:       workbenchOptionalServicePorts(definition.runtime.ports, serviceName),
+			DependsOn:   append([]string(nil), definition.dependsOn...),
+			AppService:  appService,
+			Connections: workbenchRenderOptionalServiceConnectionOutputs(definition, serviceName),
+			Extras:      workbenchOptionalServiceExtras(definition.runtime),
 		})
 	}
 
+	managedServiceNamesByEntryKey := make(map[string]string, len(models))
+	for _, model := range models {
+		managedServiceNamesByEntryKey[model.EntryKey] = model.ServiceName
+	}
+	for idx := range models {
+		dependencyKeys := models[idx].DependsOn
+		models[idx].DependsOn = make([]string, 0, len(dependencyKeys))
+		for _, dependencyKey := range dependencyKeys {
+			dependencyServiceName, ok := managedServiceNamesByEntryKey[strings.ToLower(strings.TrimSpace(dependencyKey))]
+			if !ok {
+				issues = append(issues, workbenchManagedServiceIssue{
+					Code:     "WB-MANAGED-SERVICE-DEPENDENCY-MISSING",
+					Path:     "$.managedServices",
+					Message:  fmt.Sprintf("managed service %q requires catalog entry %q, which is not managed in the stored snapshot", models[idx].ServiceName, dependencyKey),
+					EntryKey: models[idx].EntryKey,
+					Service:  models[idx].ServiceName,
+				})
+				continue
+			}
+			models[idx].DependsOn = append(models[idx].DependsOn, dependencyServiceName)
+		}
+	}
+
 	sort.SliceStable(models, func(i, j int) bool {
 		leftService := strings.ToLower(strings.TrimSpace(models[i].ServiceName))
 		rightService := strings.ToLower(strings.TrimSpace(models[j].ServiceName))
@@ -173,6 +249,44 @@ func workbenchBuildManagedServiceModels(snapshot WorkbenchStackSnapshot) ([]work
 	return models, issues
 }
 
+// workbenchApplyManagedServiceConnections adds a ${KEY} reference for every
+// connection output to the app service each managed service is wired to. The
+// values themselves live in the project .env.
+func workbenchApplyManagedServiceConnections(
+	serviceExtras map[string]workbenchComposeServiceExtras,
+	models []workbenchManagedServiceModel,
+) {
+	for _, model := range models {
+		appService := strings.TrimSpace(model.AppService)
+		if appService == "" || len(model.Connections) == 0 {
+			continue
+		}
+		extras := serviceExtras[appService]
+		for _, output := range model.Connections {
+			if workbenchHasEnvironmentEntry(extras.ConnectionEnvironment, output.Key) {
+				continue
+			}
+			extras.ConnectionEnvironment = append(extras.ConnectionEnvironment, workbenchComposeEnvironmentEntry{
+				Key:   output.Key,
+				Value: "${" + output.Key + "}",
+			})
+		}
+		sort.SliceStable(extras.ConnectionEnvironment, func(i, j int) bool {
+			return extras.ConnectionEnvironment[i].Key < extras.ConnectionEnvironment[j].Key
+		})
+		serviceExtras[appService] = extras
+	}
+}
+
+func workbenchHasEnvironmentEntry(environment []workbenchComposeEnvironmentEntry, key string) bool {
+	for _, entry := range environment {
+		if entry.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
 func workbenchOptionalServicePorts(definitions []workbenchOptionalServicePortDefinition, serviceName string) []WorkbenchComposePort {
 	if len(definitions) == 0 {
 		return []WorkbenchComposePort{}
@@ -200,6 +314,24 @@ func workbenchOptionalServiceExtras(definition workbenchOptionalServiceRuntimeDe
 	if len(definition.command) > 0 {
 		extras.Command = append([]string(nil), definition.command...)
 	}
+	if len(definition.volumes) > 0 {
+		extras.Volumes = make([]workbenchComposeVolumeMount, 0, len(definition.volumes))
+		for _, volume := range definition.volumes {
+			extras.Volumes = append(extras.Volumes, workbenchComposeVolumeMount{
+				Name:   strings.TrimSpace(volume.name),
+				Target: strings.TrimSpace(volume.target),
+			})
+		}
+	}
+	if definition.healthcheck != nil && len(definition.healthcheck.test) > 0 {
+		extras.Healthcheck = &workbenchComposeHealthcheck{
+			Test:        append([]string(nil), definition.healthcheck.test...),
+			Interval:    strings.TrimSpace(definition.healthcheck.interval),
+			Timeout:     strings.TrimSpace(definition.healthcheck.timeout),
+			Retries:     definition.healthcheck.retries,
+			StartPeriod: strings.TrimSpace(definition.healthcheck.startPeriod),
+		}
+	}
 	if len(definition.environment) > 0 {
 		keys := make([]string, 0, len(definition.environment))
 		for key := range definition.environment {
@@ -24,6 +24,7 @@ type WorkbenchComposeParseResult struct {
 	Dependencies      []WorkbenchComposeDependency `json:"dependencies"`
 	Ports             []WorkbenchComposePort       `json:"ports"`
 	Resources         []WorkbenchComposeResource   `json:"resources"`
+	Networks          []WorkbenchComposeNetwork    `json:"networks"`
 	NetworkRefs       []WorkbenchComposeNetworkRef `json:"networkRefs"`
 	VolumeRefs        []WorkbenchComposeVolumeRef  `json:"volumeRefs"`
 	EnvRefs           []WorkbenchComposeEnvRef     `json:"envRefs"`
@@ -61,6 +62,18 @@ type WorkbenchComposeResource struct {
 	ReservationMemory string `json:"reservationMemory,omitempty"`
 }
 
+// WorkbenchComposeNetwork is a top-level network definition. Only the fields
+// the workbench manages are modelled; other options stay pass-through.
+type WorkbenchComposeNetwork struct {
+	Name     string `json:"name"`
+	Driver   string `json:"driver,omitempty"`
+	Internal bool   `json:"internal,omitempty"`
+	External bool   `json:"external,omitempty"`
+	// DockerName is the network's top-level name: override, the name it has
+	// in Docker instead of one prefixed with the compose project.
+	DockerName string `json:"dockerName,omitempty"`
+}
+
 type WorkbenchComposeNetworkRef struct {
 	ServiceName string `json:"serviceName"`
 	NetworkName string `json:"networkName"`
@@ -141,7 +154,7 @@ func ParseWorkbenchComposeCore(normalizedSource string) (WorkbenchComposeParseRe
 		case "services":
 			servicesNode = valueNode
 		case "networks":
-			parser.parseTopLevelReferenceSet("$.networks", valueNode, parser.topLevelNetworks, "network")
+			parser.parseTopLevelNetworks("$.networks", valueNode)
 		case "volumes":
 			parser.parseTopLevelReferenceSet("$.volumes", valueNode, parser.topLevelVolumes, "volume")
 		case "version", "name":
@@ -644,6 +657,94 @@ func (p *workbenchComposeCoreParser) parseTopLevelReferenceSet(
 	}
 }
 
+func (p *workbenchComposeCoreParser) parseTopLevelNetworks(path string, node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		p.warn(workbenchWarningInvalidType, path, "networks must be a mapping")
+		return
+	}
+
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		keyNode := node.Content[idx]
+		valueNode := node.Content[idx+1]
+		if keyNode == nil || keyNode.Kind != yaml.ScalarNode {
+			p.warn(workbenchWarningInvalidType, path, "network name must be a scalar")
+			continue
+		}
+
+		name := strings.TrimSpace(keyNode.Value)
+		if name == "" {
+			p.warn(workbenchWarningInvalidType, path, "network name is empty")
+			continue
+		}
+		p.topLevelNetworks[name] = struct{}{}
+		network := WorkbenchComposeNetwork{Name: name}
+
+		entryPath := path + "." + name
+		if valueNode == nil || isWorkbenchYAMLNull(valueNode) {
+			p.result.Networks = append(p.result.Networks, network)
+			continue
+		}
+		if valueNode.Kind != yaml.MappingNode {
+			p.warn(workbenchWarningInvalidType, entryPath, "top-level network definition must be a mapping or null")
+			p.result.Networks = append(p.result.Networks, network)
+			continue
+		}
+
+		passThrough := false
+		for fieldIdx := 0; fieldIdx+1 < len(valueNode.Content); fieldIdx += 2 {
+			fieldKey := valueNode.Content[fieldIdx]
+			fieldValue := valueNode.Content[fieldIdx+1]
+			if fieldKey == nil {
+				continue
+			}
+			fieldPath := entryPath + "." + strings.TrimSpace(fieldKey.Value)
+			switch strings.TrimSpace(fieldKey.Value) {
+			case "driver":
+				if fieldValue == nil || fieldValue.Kind != yaml.ScalarNode {
+					p.warn(workbenchWarningInvalidType, fieldPath, "driver must be a scalar")
+					continue
+				}
+				network.Driver = strings.ToLower(strings.TrimSpace(fieldValue.Value))
+			case "name":
+				if fieldValue == nil || fieldValue.Kind != yaml.ScalarNode {
+					p.warn(workbenchWarningInvalidType, fieldPath, "name must be a scalar")
+					continue
+				}
+				network.DockerName = strings.TrimSpace(fieldValue.Value)
+			case "internal", "external":
+				if fieldValue == nil || fieldValue.Kind != yaml.ScalarNode {
+					// external may be a legacy {name: ...} mapping; keep it as-is
+					// but still record the name it refers to.
+					if nameNode, ok := workbenchYAMLFindMapValue(fieldValue, "name"); ok && network.DockerName == "" {
+						network.DockerName = strings.TrimSpace(nameNode.Value)
+					}
+					passThrough = true
+					continue
+				}
+				enabled, err := strconv.ParseBool(strings.TrimSpace(fieldValue.Value))
+				if err != nil {
+					p.warn(workbenchWarningInvalidType, fieldPath, fmt.Sprintf("%s must be a boolean", strings.TrimSpace(fieldKey.Value)))
+					continue
+				}
+				if strings.TrimSpace(fieldKey.Value) == "internal" {
+					network.Internal = enabled
+				} else {
+					network.External = enabled
+				}
+			default:
+				passThrough = true
+			}
+		}
+		if passThrough {
+			p.warnPassThrough(entryPath, "top-level network options are pass-through and not parsed")
+		}
+		p.result.Networks = append(p.result.Networks, network)
+	}
+}
+
 func (p *workbenchComposeCoreParser) parseDeployResources(serviceName, path string, node *yaml.Node) {
 	if node == nil {
 		return
@@ -1169,6 +1270,9 @@ func (p *workbenchComposeCoreParser) sort() {
 	sort.Slice(p.result.Resources, func(i, j int) bool {
 		return workbenchComposeResourceLess(p.result.Resources[i], p.result.Resources[j])
 	})
+	sort.Slice(p.result.Networks, func(i, j int) bool {
+		return p.result.Networks[i].Name < p.result.Networks[j].Name
+	})
 	sort.Slice(p.result.NetworkRefs, func(i, j int) bool {
 		return workbenchComposeNetworkRefLess(p.result.NetworkRefs[i], p.result.NetworkRefs[j])
 	})
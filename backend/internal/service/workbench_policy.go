@@ -0,0 +1,507 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-notes/internal/errs"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	workbenchValidationClassPolicy = "policy"
+
+	WorkbenchPolicySeverityError   = "error"
+	WorkbenchPolicySeverityWarning = "warning"
+	WorkbenchPolicySeverityInfo    = "info"
+	WorkbenchPolicySeverityOff     = "off"
+
+	workbenchPolicyRuleImageLatest         = "WB-POLICY-IMAGE-LATEST"
+	workbenchPolicyRuleResourceLimits      = "WB-POLICY-RESOURCE-LIMITS-MISSING"
+	workbenchPolicyRulePortPublicBind      = "WB-POLICY-PORT-PUBLIC-BIND"
+	workbenchPolicyRulePrivileged          = "WB-POLICY-PRIVILEGED"
+	workbenchPolicyRuleDockerSocket        = "WB-POLICY-DOCKER-SOCKET"
+	workbenchPolicyRuleHealthcheckMissing  = "WB-POLICY-HEALTHCHECK-MISSING"
+	workbenchPolicyOverrideRuleUnknown     = "WB-POLICY-OVERRIDE-RULE-UNKNOWN"
+	workbenchPolicyOverrideSeverityInvalid = "WB-POLICY-OVERRIDE-SEVERITY-INVALID"
+	workbenchPolicyOverrideRuleDuplicate   = "WB-POLICY-OVERRIDE-RULE-DUPLICATE"
+	workbenchPolicyDockerSocketPath        = "/var/run/docker.sock"
+	workbenchPolicyDockerSocketRunPath     = "/run/docker.sock"
+)
+
+type workbenchPolicyRule struct {
+	code            string
+	title           string
+	defaultSeverity string
+}
+
+var workbenchPolicyRules = []workbenchPolicyRule{
+	{code: workbenchPolicyRuleDockerSocket, title: "Docker socket is mounted into a container", defaultSeverity: WorkbenchPolicySeverityError},
+	{code: workbenchPolicyRuleHealthcheckMissing, title: "Service has no healthcheck", defaultSeverity: WorkbenchPolicySeverityInfo},
+	{code: workbenchPolicyRuleImageLatest, title: "Image uses the latest tag or no tag", defaultSeverity: WorkbenchPolicySeverityWarning},
+	{code: workbenchPolicyRulePortPublicBind, title: "Port is published on all interfaces instead of loopback", defaultSeverity: WorkbenchPolicySeverityWarning},
+	{code: workbenchPolicyRulePrivileged, title: "Container runs privileged", defaultSeverity: WorkbenchPolicySeverityError},
+	{code: workbenchPolicyRuleResourceLimits, title: "Service has no CPU or memory limits", defaultSeverity: WorkbenchPolicySeverityWarning},
+}
+
+// WorkbenchPolicyOverride changes the severity of one policy rule for a
+// single project. Severity "off" disables the rule.
+type WorkbenchPolicyOverride struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+}
+
+type WorkbenchPolicyRuleStatus struct {
+	Rule            string `json:"rule"`
+	Title           string `json:"title"`
+	DefaultSeverity string `json:"defaultSeverity"`
+	Severity        string `json:"severity"`
+	Overridden      bool   `json:"overridden"`
+}
+
+type WorkbenchPolicyReport struct {
+	Rules        []WorkbenchPolicyRuleStatus `json:"rules"`
+	Issues       []WorkbenchValidationIssue  `json:"issues"`
+	ErrorCount   int                         `json:"errorCount"`
+	WarningCount int                         `json:"warningCount"`
+	InfoCount    int                         `json:"infoCount"`
+	Blocking     bool                        `json:"blocking"`
+}
+
+type WorkbenchPolicyUpdateRequest struct {
+	ExpectedRevision *int              `json:"expectedRevision,omitempty"`
+	Overrides        map[string]string `json:"overrides"`
+}
+
+// GetPolicyReport lints the compose file an apply would currently write.
+func (s *WorkbenchService) GetPolicyReport(
+	ctx context.Context,
+	projectName string,
+) (WorkbenchStackSnapshot, WorkbenchPolicyReport, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchPolicyReport{}, err
+	}
+
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchPolicyReport{}, err
+	}
+	defer release()
+
+	snapshot, err := s.loadStoredSnapshotForComposeLocked(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchPolicyReport{}, err
+	}
+	currentSource, err := s.ResolveComposeSource(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchPolicyReport{}, err
+	}
+	compose, err := mergeWorkbenchSnapshotIntoComposeSource(snapshot, currentSource)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchPolicyReport{}, err
+	}
+
+	report, err := lintWorkbenchCompose(compose, snapshot.PolicyOverrides)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, WorkbenchPolicyReport{}, workbenchComposeGenerateError(snapshot, "failed to lint compose output", err)
+	}
+	return snapshot, report, nil
+}
+
+// UpdatePolicyOverrides replaces the project's rule severity overrides. An
+// empty map resets every rule to its default severity.
+func (s *WorkbenchService) UpdatePolicyOverrides(
+	ctx context.Context,
+	projectName string,
+	input WorkbenchPolicyUpdateRequest,
+) (WorkbenchStackSnapshot, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+
+	overrides, issues := normalizeWorkbenchPolicyOverrideInput(input.Overrides)
+	if len(issues) > 0 {
+		return WorkbenchStackSnapshot{}, errs.WithDetails(
+			errs.New(errs.CodeWorkbenchValidationFailed, "invalid workbench policy overrides"),
+			map[string]any{
+				"project":    normalizedProject,
+				"issueCount": len(issues),
+				"issues":     issues,
+			},
+		)
+	}
+
+	release, err := s.AcquireProjectLock(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	defer release()
+
+	snapshot, err := s.loadStoredSnapshotForComposeLocked(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	if input.ExpectedRevision != nil && snapshot.Revision != *input.ExpectedRevision {
+		return WorkbenchStackSnapshot{}, workbenchApplyStaleRevisionError(snapshot, *input.ExpectedRevision)
+	}
+
+	updated := snapshot
+	updated.PolicyOverrides = overrides
+	updated.Revision = snapshot.Revision + 1
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, updated, WorkbenchMutationPolicyUpdate, ""); err != nil {
+		return WorkbenchStackSnapshot{}, err
+	}
+	return normalizeWorkbenchStackSnapshot(updated), nil
+}
+
+func normalizeWorkbenchPolicyOverrideInput(input map[string]string) ([]WorkbenchPolicyOverride, []WorkbenchValidationIssue) {
+	overrides := make([]WorkbenchPolicyOverride, 0, len(input))
+	issues := []WorkbenchValidationIssue{}
+	seen := map[string]struct{}{}
+
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		rule := strings.ToUpper(strings.TrimSpace(key))
+		path := fmt.Sprintf("$.overrides[%q]", key)
+		if _, ok := workbenchPolicyRuleByCode(rule); !ok {
+			issues = append(issues, WorkbenchValidationIssue{
+				Class:   workbenchValidationClassPolicy,
+				Code:    workbenchPolicyOverrideRuleUnknown,
+				Path:    path,
+				Message: fmt.Sprintf("unknown policy rule %q", key),
+			})
+			continue
+		}
+		if _, exists := seen[rule]; exists {
+			issues = append(issues, WorkbenchValidationIssue{
+				Class:   workbenchValidationClassPolicy,
+				Code:    workbenchPolicyOverrideRuleDuplicate,
+				Path:    path,
+				Message: fmt.Sprintf("policy rule %q is overridden more than once", rule),
+			})
+			continue
+		}
+		seen[rule] = struct{}{}
+
+		severity, ok := normalizeWorkbenchPolicySeverity(input[key])
+		if !ok {
+			issues = append(issues, WorkbenchValidationIssue{
+				Class:   workbenchValidationClassPolicy,
+				Code:    workbenchPolicyOverrideSeverityInvalid,
+				Path:    path,
+				Message: fmt.Sprintf("severity %q must be one of error, warning, info, off", input[key]),
+			})
+			continue
+		}
+		overrides = append(overrides, WorkbenchPolicyOverride{Rule: rule, Severity: severity})
+	}
+	return normalizeWorkbenchPolicyOverrides(overrides), issues
+}
+
+func normalizeWorkbenchPolicyOverrides(overrides []WorkbenchPolicyOverride) []WorkbenchPolicyOverride {
+	if len(overrides) == 0 {
+		return nil
+	}
+	byRule := make(map[string]string, len(overrides))
+	for _, override := range overrides {
+		rule := strings.ToUpper(strings.TrimSpace(override.Rule))
+		if _, ok := workbenchPolicyRuleByCode(rule); !ok {
+			continue
+		}
+		severity, ok := normalizeWorkbenchPolicySeverity(override.Severity)
+		if !ok {
+			continue
+		}
+		byRule[rule] = severity
+	}
+	if len(byRule) == 0 {
+		return nil
+	}
+	normalized := make([]WorkbenchPolicyOverride, 0, len(byRule))
+	for rule, severity := range byRule {
+		normalized = append(normalized, WorkbenchPolicyOverride{Rule: rule, Severity: severity})
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return normalized[i].Rule < normalized[j].Rule
+	})
+	return normalized
+}
+
+func normalizeWorkbenchPolicySeverity(value string) (string, bool) {
+	switch severity := strings.ToLower(strings.TrimSpace(value)); severity {
+	case WorkbenchPolicySeverityError, WorkbenchPolicySeverityWarning, WorkbenchPolicySeverityInfo, WorkbenchPolicySeverityOff:
+		return severity, true
+	default:
+		return "", false
+	}
+}
+
+func workbenchPolicyRuleByCode(code string) (workbenchPolicyRule, bool) {
+	for _, rule := range workbenchPolicyRules {
+		if rule.code == code {
+			return rule, true
+		}
+	}
+	return workbenchPolicyRule{}, false
+}
+
+func workbenchPolicyRuleStatuses(overrides []WorkbenchPolicyOverride) []WorkbenchPolicyRuleStatus {
+	byRule := make(map[string]string, len(overrides))
+	for _, override := range normalizeWorkbenchPolicyOverrides(overrides) {
+		byRule[override.Rule] = override.Severity
+	}
+	statuses := make([]WorkbenchPolicyRuleStatus, 0, len(workbenchPolicyRules))
+	for _, rule := range workbenchPolicyRules {
+		status := WorkbenchPolicyRuleStatus{
+			Rule:            rule.code,
+			Title:           rule.title,
+			DefaultSeverity: rule.defaultSeverity,
+			Severity:        rule.defaultSeverity,
+		}
+		if severity, ok := byRule[rule.code]; ok {
+			status.Severity = severity
+			status.Overridden = severity != rule.defaultSeverity
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// lintWorkbenchCompose runs the policy rules against rendered compose YAML
+// and returns the findings with their effective per-project severity.
+func lintWorkbenchCompose(compose string, overrides []WorkbenchPolicyOverride) (WorkbenchPolicyReport, error) {
+	report := WorkbenchPolicyReport{
+		Rules:  workbenchPolicyRuleStatuses(overrides),
+		Issues: []WorkbenchValidationIssue{},
+	}
+	severities := make(map[string]string, len(report.Rules))
+	for _, status := range report.Rules {
+		severities[status.Rule] = status.Severity
+	}
+
+	parsed, err := ParseWorkbenchComposeCore(compose)
+	if err != nil {
+		return WorkbenchPolicyReport{}, err
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(compose), &document); err != nil {
+		return WorkbenchPolicyReport{}, err
+	}
+	servicesNode, _ := workbenchYAMLFindMapValue(workbenchDocumentRoot(&document), "services")
+
+	addIssue := func(issue WorkbenchValidationIssue) {
+		severity := severities[issue.Code]
+		if severity == "" || severity == WorkbenchPolicySeverityOff {
+			return
+		}
+		issue.Class = workbenchValidationClassPolicy
+		issue.Severity = severity
+		report.Issues = append(report.Issues, issue)
+	}
+
+	resources := make(map[string]WorkbenchComposeResource, len(parsed.Resources))
+	for _, resource := range parsed.Resources {
+		resources[resource.ServiceName] = resource
+	}
+
+	for _, service := range parsed.Services {
+		name := service.ServiceName
+		path := "$.services." + name
+		serviceNode, _ := workbenchYAMLFindMapValue(servicesNode, name)
+
+		if image := strings.TrimSpace(service.Image); image != "" && workbenchPolicyImageIsFloating(image) {
+			addIssue(WorkbenchValidationIssue{
+				Code:    workbenchPolicyRuleImageLatest,
+				Path:    path + ".image",
+				Message: fmt.Sprintf("image %q is not pinned to a version tag or digest", image),
+				Service: name,
+			})
+		}
+
+		resource := resources[name]
+		if strings.TrimSpace(resource.LimitCPUs) == "" && strings.TrimSpace(resource.LimitMemory) == "" &&
+			!workbenchPolicyHasLegacyLimits(serviceNode) {
+			addIssue(WorkbenchValidationIssue{
+				Code:    workbenchPolicyRuleResourceLimits,
+				Path:    path + ".deploy.resources.limits",
+				Message: "service does not set CPU or memory limits",
+				Service: name,
+			})
+		}
+
+		if workbenchPolicyScalarIsTrue(serviceNode, "privileged") {
+			addIssue(WorkbenchValidationIssue{
+				Code:    workbenchPolicyRulePrivileged,
+				Path:    path + ".privileged",
+				Message: "service runs with privileged: true",
+				Service: name,
+			})
+		}
+
+		for _, mount := range workbenchPolicyDockerSocketMounts(serviceNode) {
+			addIssue(WorkbenchValidationIssue{
+				Code:    workbenchPolicyRuleDockerSocket,
+				Path:    path + ".volumes",
+				Message: fmt.Sprintf("service mounts the Docker socket %q", mount),
+				Service: name,
+			})
+		}
+
+		if !workbenchPolicyHasHealthcheck(serviceNode) {
+			addIssue(WorkbenchValidationIssue{
+				Code:    workbenchPolicyRuleHealthcheckMissing,
+				Path:    path + ".healthcheck",
+				Message: "service does not define a healthcheck",
+				Service: name,
+			})
+		}
+	}
+
+	for _, port := range parsed.Ports {
+		if port.HostPort == nil && strings.TrimSpace(port.HostPortRaw) == "" {
+			continue
+		}
+		switch strings.TrimSpace(port.HostIP) {
+		case "", "0.0.0.0", "::":
+		default:
+			continue
+		}
+		hostPort := strings.TrimSpace(port.HostPortRaw)
+		if port.HostPort != nil {
+			hostPort = fmt.Sprintf("%d", *port.HostPort)
+		}
+		addIssue(WorkbenchValidationIssue{
+			Code:     workbenchPolicyRulePortPublicBind,
+			Path:     "$.services." + port.ServiceName + ".ports",
+			Message:  fmt.Sprintf("port %s/%s is published on all interfaces; bind it to 127.0.0.1", hostPort, port.Protocol),
+			Service:  port.ServiceName,
+			Protocol: port.Protocol,
+			HostIP:   port.HostIP,
+			HostPort: hostPort,
+		})
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return workbenchValidationIssueLess(report.Issues[i], report.Issues[j])
+	})
+	for _, issue := range report.Issues {
+		switch issue.Severity {
+		case WorkbenchPolicySeverityError:
+			report.ErrorCount++
+		case WorkbenchPolicySeverityWarning:
+			report.WarningCount++
+		case WorkbenchPolicySeverityInfo:
+			report.InfoCount++
+		}
+	}
+	report.Blocking = report.ErrorCount > 0
+	return report, nil
+}
+
+func workbenchPolicyBlockedError(snapshot WorkbenchStackSnapshot, report WorkbenchPolicyReport) error {
+	blocking := make([]WorkbenchValidationIssue, 0, report.ErrorCount)
+	for _, issue := range report.Issues {
+		if issue.Severity == WorkbenchPolicySeverityError {
+			blocking = append(blocking, issue)
+		}
+	}
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchValidationFailed, "workbench apply blocked by policy errors"),
+		map[string]any{
+			"project":           strings.TrimSpace(snapshot.ProjectName),
+			"composePath":       strings.TrimSpace(snapshot.ComposePath),
+			"sourceFingerprint": strings.TrimSpace(snapshot.SourceFingerprint),
+			"revision":          snapshot.Revision,
+			"issueCount":        len(blocking),
+			"issues":            blocking,
+		},
+	)
+}
+
+func workbenchPolicyImageIsFloating(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	name := image
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	colon := strings.LastIndex(name, ":")
+	if colon < 0 {
+		return true
+	}
+	return strings.EqualFold(strings.TrimSpace(name[colon+1:]), "latest")
+}
+
+func workbenchPolicyHasLegacyLimits(serviceNode *yaml.Node) bool {
+	for _, key := range []string{"mem_limit", "cpus"} {
+		if value, ok := workbenchYAMLFindMapValue(serviceNode, key); ok && value != nil && strings.TrimSpace(value.Value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func workbenchPolicyScalarIsTrue(serviceNode *yaml.Node, key string) bool {
+	value, ok := workbenchYAMLFindMapValue(serviceNode, key)
+	if !ok || value == nil || value.Kind != yaml.ScalarNode {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(value.Value), "true")
+}
+
+func workbenchPolicyHasHealthcheck(serviceNode *yaml.Node) bool {
+	healthcheck, ok := workbenchYAMLFindMapValue(serviceNode, "healthcheck")
+	if !ok || healthcheck == nil || healthcheck.Kind != yaml.MappingNode {
+		return false
+	}
+	if workbenchPolicyScalarIsTrue(healthcheck, "disable") {
+		return false
+	}
+	test, ok := workbenchYAMLFindMapValue(healthcheck, "test")
+	if !ok || test == nil {
+		return false
+	}
+	switch test.Kind {
+	case yaml.ScalarNode:
+		return strings.TrimSpace(test.Value) != "" && !strings.EqualFold(strings.TrimSpace(test.Value), "NONE")
+	case yaml.SequenceNode:
+		return len(test.Content) > 0 && !strings.EqualFold(strings.TrimSpace(test.Content[0].Value), "NONE")
+	default:
+		return false
+	}
+}
+
+func workbenchPolicyDockerSocketMounts(serviceNode *yaml.Node) []string {
+	volumes, ok := workbenchYAMLFindMapValue(serviceNode, "volumes")
+	if !ok || volumes == nil || volumes.Kind != yaml.SequenceNode {
+		return nil
+	}
+	mounts := []string{}
+	for _, entry := range volumes.Content {
+		source := ""
+		switch entry.Kind {
+		case yaml.ScalarNode:
+			source, _, _ = strings.Cut(strings.TrimSpace(entry.Value), ":")
+		case yaml.MappingNode:
+			if value, ok := workbenchYAMLFindMapValue(entry, "source"); ok && value != nil {
+				source = value.Value
+			}
+		}
+		source = strings.TrimSpace(source)
+		if source == workbenchPolicyDockerSocketPath || source == workbenchPolicyDockerSocketRunPath {
+			mounts = append(mounts, source)
+		}
+	}
+	return mounts
+}
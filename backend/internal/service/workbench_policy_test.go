@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-notes/internal/errs"
+)
+
+const workbenchPolicyTestCompose = `services:
+  api:
+    image: ghcr.io/example/api
+    privileged: true
+    ports:
+      - "8080:80"
+      - "127.0.0.1:9090:90"
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock
+  worker:
+    image: ghcr.io/example/worker:1.2.0
+    mem_limit: 256m
+    healthcheck:
+      test: ["CMD", "worker", "ping"]
+`
+
+func TestLintWorkbenchComposeReportsPolicyFindings(t *testing.T) {
+	t.Parallel()
+
+	report, err := lintWorkbenchCompose(workbenchPolicyTestCompose, nil)
+	if err != nil {
+		t.Fatalf("lint compose: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, issue := range report.Issues {
+		if issue.Class != workbenchValidationClassPolicy {
+			t.Fatalf("expected policy class, got %#v", issue)
+		}
+		got[issue.Service+":"+issue.Code] = issue.Severity
+	}
+	want := map[string]string{
+		"api:" + workbenchPolicyRuleImageLatest:        WorkbenchPolicySeverityWarning,
+		"api:" + workbenchPolicyRuleResourceLimits:     WorkbenchPolicySeverityWarning,
+		"api:" + workbenchPolicyRulePortPublicBind:     WorkbenchPolicySeverityWarning,
+		"api:" + workbenchPolicyRulePrivileged:         WorkbenchPolicySeverityError,
+		"api:" + workbenchPolicyRuleDockerSocket:       WorkbenchPolicySeverityError,
+		"api:" + workbenchPolicyRuleHealthcheckMissing: WorkbenchPolicySeverityInfo,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected issues\nwant=%v\ngot=%v", want, got)
+	}
+	for key, severity := range want {
+		if got[key] != severity {
+			t.Fatalf("expected %s with severity %q, got %q (all=%v)", key, severity, got[key], got)
+		}
+	}
+	if report.ErrorCount != 2 || report.WarningCount != 3 || report.InfoCount != 1 || !report.Blocking {
+		t.Fatalf("unexpected report counts: %#v", report)
+	}
+}
+
+func TestLintWorkbenchComposeAppliesSeverityOverrides(t *testing.T) {
+	t.Parallel()
+
+	report, err := lintWorkbenchCompose(workbenchPolicyTestCompose, []WorkbenchPolicyOverride{
+		{Rule: workbenchPolicyRulePrivileged, Severity: WorkbenchPolicySeverityWarning},
+		{Rule: workbenchPolicyRuleDockerSocket, Severity: WorkbenchPolicySeverityOff},
+	})
+	if err != nil {
+		t.Fatalf("lint compose: %v", err)
+	}
+	if report.Blocking || report.ErrorCount != 0 {
+		t.Fatalf("expected overrides to clear blocking errors, got %#v", report)
+	}
+	for _, issue := range report.Issues {
+		if issue.Code == workbenchPolicyRuleDockerSocket {
+			t.Fatalf("expected disabled rule to produce no issues, got %#v", issue)
+		}
+	}
+	for _, status := range report.Rules {
+		if status.Rule == workbenchPolicyRulePrivileged && (!status.Overridden || status.Severity != WorkbenchPolicySeverityWarning) {
+			t.Fatalf("expected privileged rule override in status, got %#v", status)
+		}
+	}
+}
+
+func TestWorkbenchUpdatePolicyOverridesRejectsUnknownRulesAndSeverities(t *testing.T) {
+	t.Parallel()
+
+	svc := NewWorkbenchServiceWithStorage(t.TempDir(), nil, &fakeSettingsRepo{}, "test-session-secret")
+	_, err := svc.UpdatePolicyOverrides(context.Background(), "demo", WorkbenchPolicyUpdateRequest{
+		Overrides: map[string]string{
+			"WB-POLICY-NOPE":              "warning",
+			workbenchPolicyRulePrivileged: "fatal",
+		},
+	})
+	typed, ok := errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchValidationFailed {
+		t.Fatalf("expected %q, got %v", errs.CodeWorkbenchValidationFailed, err)
+	}
+	issues := extractWorkbenchValidationIssues(t, typed.Details.(map[string]any))
+	if len(issues) != 2 ||
+		issues[0].Code != workbenchPolicyOverrideRuleUnknown ||
+		issues[1].Code != workbenchPolicyOverrideSeverityInvalid {
+		t.Fatalf("unexpected issues: %#v", issues)
+	}
+}
+
+func TestWorkbenchApplyComposeBlockedByPolicyErrorsUntilOverridden(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	projectDir := filepath.Join(templatesDir, "demo")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir project: %v", err)
+	}
+	composePath := filepath.Join(projectDir, "docker-compose.yml")
+	source := "services:\n  api:\n    image: nginx:1.25\n    privileged: true\n"
+	if err := os.WriteFile(composePath, []byte(source), 0o644); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	svc := NewWorkbenchServiceWithStorage(templatesDir, nil, &fakeSettingsRepo{}, "test-session-secret")
+	imported, _, err := svc.ImportComposeSnapshot(context.Background(), "demo", "manual")
+	if err != nil {
+		t.Fatalf("import snapshot: %v", err)
+	}
+
+	expectedRevision := imported.Revision
+	_, err = svc.ApplyComposeFromStoredSnapshot(context.Background(), "demo", WorkbenchComposeApplyRequest{
+		ExpectedRevision:          &expectedRevision,
+		ExpectedSourceFingerprint: imported.SourceFingerprint,
+	})
+	typed, ok := errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchValidationFailed {
+		t.Fatalf("expected policy block %q, got %v", errs.CodeWorkbenchValidationFailed, err)
+	}
+	issues := extractWorkbenchValidationIssues(t, typed.Details.(map[string]any))
+	if len(issues) != 1 || issues[0].Code != workbenchPolicyRulePrivileged {
+		t.Fatalf("expected only the privileged error to block, got %#v", issues)
+	}
+
+	updated, err := svc.UpdatePolicyOverrides(context.Background(), "demo", WorkbenchPolicyUpdateRequest{
+		ExpectedRevision: &expectedRevision,
+		Overrides:        map[string]string{workbenchPolicyRulePrivileged: "warning"},
+	})
+	if err != nil {
+		t.Fatalf("update overrides: %v", err)
+	}
+	if updated.Revision != imported.Revision+1 {
+		t.Fatalf("expected revision bump, got %d", updated.Revision)
+	}
+
+	expectedRevision = updated.Revision
+	if _, err := svc.ApplyComposeFromStoredSnapshot(context.Background(), "demo", WorkbenchComposeApplyRequest{
+		ExpectedRevision:          &expectedRevision,
+		ExpectedSourceFingerprint: updated.SourceFingerprint,
+	}); err != nil {
+		t.Fatalf("expected apply after override, got %v", err)
+	}
+
+	reimported, _, err := svc.ImportComposeSnapshot(context.Background(), "demo", "manual")
+	if err != nil {
+		t.Fatalf("re-import snapshot: %v", err)
+	}
+	if len(reimported.PolicyOverrides) != 1 || reimported.PolicyOverrides[0].Rule != workbenchPolicyRulePrivileged {
+		t.Fatalf("expected overrides to survive re-import, got %#v", reimported.PolicyOverrides)
+	}
+}
@@ -146,7 +146,7 @@ func (s *WorkbenchService) MutateStoredSnapshotPort(
 		mutated.Revision = 1
 	}
 	mutated.Revision++
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, mutated); err != nil {
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, mutated, WorkbenchMutationPortMutate, ""); err != nil {
 		return mutated, mutationSummary, err
 	}
 	return mutated, mutationSummary, nil
@@ -77,6 +77,18 @@ type workbenchPortResolveCandidate struct {
 func (s *WorkbenchService) ResolveStoredSnapshotPorts(
 	ctx context.Context,
 	projectName string,
+) (WorkbenchStackSnapshot, WorkbenchPortResolutionSummary, error) {
+	return s.resolveStoredSnapshotPorts(ctx, projectName, nil)
+}
+
+// resolveStoredSnapshotPorts treats occupiedHostPorts as already reserved, so
+// auto ports skip them and manual ports on them conflict. Only callers whose
+// stack is not running yet may pass them; a live stack would see its own
+// published ports as occupied.
+func (s *WorkbenchService) resolveStoredSnapshotPorts(
+	ctx context.Context,
+	projectName string,
+	occupiedHostPorts map[int]struct{},
 ) (WorkbenchStackSnapshot, WorkbenchPortResolutionSummary, error) {
 	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
 	if err != nil {
@@ -102,7 +114,7 @@ func (s *WorkbenchService) ResolveStoredSnapshotPorts(
 		)
 	}
 
-	resolved, summary, err := resolveWorkbenchSnapshotPorts(snapshot)
+	resolved, summary, err := resolveWorkbenchSnapshotPortsAround(snapshot, occupiedHostPorts)
 	if err != nil {
 		return resolved, summary, err
 	}
@@ -116,7 +128,7 @@ func (s *WorkbenchService) ResolveStoredSnapshotPorts(
 	}
 	resolved.Revision++
 
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, resolved); err != nil {
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, resolved, WorkbenchMutationPortResolve, ""); err != nil {
 		return resolved, summary, err
 	}
 	return resolved, summary, nil
@@ -124,6 +136,13 @@ func (s *WorkbenchService) ResolveStoredSnapshotPorts(
 
 func resolveWorkbenchSnapshotPorts(
 	snapshot WorkbenchStackSnapshot,
+) (WorkbenchStackSnapshot, WorkbenchPortResolutionSummary, error) {
+	return resolveWorkbenchSnapshotPortsAround(snapshot, nil)
+}
+
+func resolveWorkbenchSnapshotPortsAround(
+	snapshot WorkbenchStackSnapshot,
+	occupiedHostPorts map[int]struct{},
 ) (WorkbenchStackSnapshot, WorkbenchPortResolutionSummary, error) {
 	normalizedSnapshot := normalizeWorkbenchStackSnapshot(snapshot)
 	outcomes := make([]WorkbenchPortResolveOutcome, 0, len(normalizedSnapshot.Ports))
@@ -174,7 +193,15 @@ func resolveWorkbenchSnapshotPorts(
 		}
 	}
 
-	reservedBindings := []workbenchHostBinding{}
+	reservedBindings := make([]workbenchHostBinding, 0, 2*len(occupiedHostPorts))
+	for hostPort := range occupiedHostPorts {
+		for _, protocol := range []string{"tcp", "udp"} {
+			reservedBindings = append(reservedBindings, workbenchHostBinding{
+				hostPort: strconv.Itoa(hostPort),
+				protocol: protocol,
+			})
+		}
+	}
 	for idx, port := range resolvedPorts {
 		path := fmt.Sprintf("$.ports[%d]", idx)
 		serviceName := strings.TrimSpace(port.ServiceName)
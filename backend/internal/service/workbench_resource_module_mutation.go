@@ -137,7 +137,7 @@ func (s *WorkbenchService) MutateStoredSnapshotResource(
 		mutated.Revision = 1
 	}
 	mutated.Revision++
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, mutated); err != nil {
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, mutated, WorkbenchMutationResourceMutate, ""); err != nil {
 		return mutated, mutationSummary, err
 	}
 	return mutated, mutationSummary, nil
@@ -193,7 +193,7 @@ func (s *WorkbenchService) MutateStoredSnapshotModule(
 		mutated.Revision = 1
 	}
 	mutated.Revision++
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, mutated); err != nil {
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, mutated, WorkbenchMutationModuleMutate, ""); err != nil {
 		return mutated, mutationSummary, err
 	}
 	return mutated, mutationSummary, nil
@@ -275,7 +275,7 @@ func (s *WorkbenchService) MutateLegacyModuleCompatibility(
 		mutated.Revision = 1
 	}
 	mutated.Revision++
-	if err := s.saveWorkbenchSnapshot(ctx, normalizedProject, mutated); err != nil {
+	if err := s.saveWorkbenchSnapshotRevision(ctx, normalizedProject, snapshot, mutated, WorkbenchMutationModuleMutate, ""); err != nil {
 		return mutated, mutationSummary, err
 	}
 	return mutated, mutationSummary, nil
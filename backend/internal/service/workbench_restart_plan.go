@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-notes/internal/errs"
+)
+
+const (
+	WorkbenchServiceRestartModeRestart  = "restart"
+	WorkbenchServiceRestartModeRedeploy = "redeploy"
+)
+
+// WorkbenchRestartPlan lists a service and everything that depends on it,
+// grouped into tiers. Every service in a tier only depends on services in
+// earlier tiers (or outside the plan), so tiers can be restarted in order with
+// a health wait between them.
+type WorkbenchRestartPlan struct {
+	ProjectName string     `json:"projectName"`
+	Revision    int        `json:"revision"`
+	ServiceName string     `json:"serviceName"`
+	Tiers       [][]string `json:"tiers"`
+	Services    []string   `json:"services"`
+	// OneShot lists planned services with no restart policy, which are
+	// expected to run to completion instead of staying up.
+	OneShot []string `json:"oneShot,omitempty"`
+}
+
+func (s *WorkbenchService) PlanServiceRestart(
+	ctx context.Context,
+	projectName string,
+	serviceName string,
+) (WorkbenchRestartPlan, error) {
+	normalizedProject, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return WorkbenchRestartPlan{}, err
+	}
+	snapshot, exists, err := s.loadStoredWorkbenchSnapshot(ctx, normalizedProject)
+	if err != nil {
+		return WorkbenchRestartPlan{}, err
+	}
+	if !exists {
+		return WorkbenchRestartPlan{}, errs.WithDetails(
+			errs.New(errs.CodeWorkbenchSourceNotFound, "workbench snapshot not found; import the project compose first"),
+			map[string]any{"project": normalizedProject},
+		)
+	}
+	return buildWorkbenchRestartPlan(snapshot, serviceName)
+}
+
+func buildWorkbenchRestartPlan(snapshot WorkbenchStackSnapshot, serviceName string) (WorkbenchRestartPlan, error) {
+	normalized := normalizeWorkbenchStackSnapshot(snapshot)
+	serviceNames := workbenchGraphServiceNames(normalized)
+	indexByService := make(map[string]string, len(serviceNames))
+	for _, name := range serviceNames {
+		indexByService[strings.ToLower(name)] = name
+	}
+
+	target, known := indexByService[strings.ToLower(strings.TrimSpace(serviceName))]
+	if !known {
+		return WorkbenchRestartPlan{}, workbenchRestartPlanError(normalized, serviceName, []WorkbenchMutationIssue{{
+			Class:   workbenchMutationIssueClassConflict,
+			Code:    "WB-RESTART-SERVICE-UNKNOWN",
+			Path:    "$.serviceName",
+			Message: fmt.Sprintf("service %q is not part of the stack", strings.TrimSpace(serviceName)),
+			Service: strings.TrimSpace(serviceName),
+		}})
+	}
+
+	dependsOn := make(map[string][]string, len(serviceNames))
+	dependents := make(map[string][]string, len(serviceNames))
+	for _, dependency := range normalized.Dependencies {
+		from := workbenchResolveGraphServiceName(indexByService, dependency.ServiceName)
+		to := workbenchResolveGraphServiceName(indexByService, dependency.DependsOn)
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		dependsOn[from] = append(dependsOn[from], to)
+		dependents[to] = append(dependents[to], from)
+	}
+
+	included := map[string]struct{}{target: {}}
+	queue := []string{target}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[current] {
+			if _, seen := included[dependent]; seen {
+				continue
+			}
+			included[dependent] = struct{}{}
+			queue = append(queue, dependent)
+		}
+	}
+
+	// Kahn's algorithm restricted to the included services. Dependencies
+	// outside the plan are already running and are not waited on.
+	pending := make(map[string]int, len(included))
+	for name := range included {
+		count := 0
+		for _, upstream := range dependsOn[name] {
+			if _, ok := included[upstream]; ok && name != target {
+				count++
+			}
+		}
+		pending[name] = count
+	}
+
+	tiers := [][]string{}
+	ordered := make([]string, 0, len(included))
+	current := []string{target}
+	delete(pending, target)
+	for len(current) > 0 {
+		sort.Slice(current, func(i, j int) bool {
+			return strings.ToLower(current[i]) < strings.ToLower(current[j])
+		})
+		tiers = append(tiers, current)
+		ordered = append(ordered, current...)
+
+		next := []string{}
+		for _, done := range current {
+			for _, dependent := range dependents[done] {
+				remaining, waiting := pending[dependent]
+				if !waiting {
+					continue
+				}
+				remaining--
+				pending[dependent] = remaining
+				if remaining == 0 {
+					delete(pending, dependent)
+					next = append(next, dependent)
+				}
+			}
+		}
+		current = next
+	}
+
+	if len(pending) > 0 {
+		cycle := make([]string, 0, len(pending))
+		for name := range pending {
+			cycle = append(cycle, name)
+		}
+		sort.Strings(cycle)
+		return WorkbenchRestartPlan{}, workbenchRestartPlanError(normalized, target, []WorkbenchMutationIssue{{
+			Class:   workbenchMutationIssueClassConflict,
+			Code:    "WB-RESTART-DEPENDENCY-CYCLE",
+			Path:    "$.dependencies",
+			Message: fmt.Sprintf("depends_on cycle between %s; restart order cannot be determined", strings.Join(cycle, ", ")),
+			Service: target,
+		}})
+	}
+
+	oneShot := []string{}
+	for _, service := range normalized.Services {
+		name := workbenchResolveGraphServiceName(indexByService, service.ServiceName)
+		if _, ok := included[name]; !ok {
+			continue
+		}
+		if policy := strings.ToLower(strings.TrimSpace(service.RestartPolicy)); policy == "" || policy == "no" {
+			oneShot = append(oneShot, name)
+		}
+	}
+	sort.Strings(oneShot)
+
+	return WorkbenchRestartPlan{
+		ProjectName: normalized.ProjectName,
+		Revision:    normalized.Revision,
+		ServiceName: target,
+		Tiers:       tiers,
+		Services:    ordered,
+		OneShot:     oneShot,
+	}, nil
+}
+
+func workbenchRestartPlanError(snapshot WorkbenchStackSnapshot, serviceName string, issues []WorkbenchMutationIssue) error {
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchValidationFailed, "invalid service restart plan"),
+		map[string]any{
+			"project":     strings.TrimSpace(snapshot.ProjectName),
+			"revision":    snapshot.Revision,
+			"serviceName": strings.TrimSpace(serviceName),
+			"issueCount":  len(issues),
+			"issues":      issues,
+		},
+	)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"go-notes/internal/errs"
+)
+
+func TestBuildWorkbenchRestartPlanOrdersDependentsIntoTiers(t *testing.T) {
+	t.Parallel()
+
+	snapshot := WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Revision:    4,
+		Services: []WorkbenchComposeService{
+			{ServiceName: "db"},
+			{ServiceName: "cache"},
+			{ServiceName: "api"},
+			{ServiceName: "worker"},
+			{ServiceName: "web"},
+		},
+		Dependencies: []WorkbenchComposeDependency{
+			{ServiceName: "api", DependsOn: "db"},
+			{ServiceName: "api", DependsOn: "cache"},
+			{ServiceName: "worker", DependsOn: "db"},
+			{ServiceName: "worker", DependsOn: "api"},
+			{ServiceName: "web", DependsOn: "api"},
+		},
+	}
+
+	plan, err := buildWorkbenchRestartPlan(snapshot, "DB")
+	if err != nil {
+		t.Fatalf("build plan: %v", err)
+	}
+	expected := [][]string{{"db"}, {"api"}, {"web", "worker"}}
+	if !reflect.DeepEqual(plan.Tiers, expected) {
+		t.Fatalf("expected tiers %v, got %v", expected, plan.Tiers)
+	}
+	if plan.ServiceName != "db" || plan.Revision != 4 {
+		t.Fatalf("unexpected plan header %#v", plan)
+	}
+
+	// Dependencies outside the plan (db for api) are not restarted.
+	plan, err = buildWorkbenchRestartPlan(snapshot, "cache")
+	if err != nil {
+		t.Fatalf("build plan: %v", err)
+	}
+	expected = [][]string{{"cache"}, {"api"}, {"web", "worker"}}
+	if !reflect.DeepEqual(plan.Tiers, expected) {
+		t.Fatalf("expected tiers %v, got %v", expected, plan.Tiers)
+	}
+
+	plan, err = buildWorkbenchRestartPlan(snapshot, "web")
+	if err != nil {
+		t.Fatalf("build plan: %v", err)
+	}
+	if !reflect.DeepEqual(plan.Tiers, [][]string{{"web"}}) {
+		t.Fatalf("expected leaf service alone, got %v", plan.Tiers)
+	}
+}
+
+func TestBuildWorkbenchRestartPlanListsOneShotServices(t *testing.T) {
+	t.Parallel()
+
+	snapshot := WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Services: []WorkbenchComposeService{
+			{ServiceName: "db", RestartPolicy: "unless-stopped"},
+			{ServiceName: "migrate"},
+			{ServiceName: "seed", RestartPolicy: "no"},
+			{ServiceName: "api", RestartPolicy: "always"},
+			{ServiceName: "docs"},
+		},
+		Dependencies: []WorkbenchComposeDependency{
+			{ServiceName: "migrate", DependsOn: "db"},
+			{ServiceName: "seed", DependsOn: "migrate"},
+			{ServiceName: "api", DependsOn: "migrate"},
+		},
+	}
+
+	plan, err := buildWorkbenchRestartPlan(snapshot, "db")
+	if err != nil {
+		t.Fatalf("build plan: %v", err)
+	}
+	if !reflect.DeepEqual(plan.OneShot, []string{"migrate", "seed"}) {
+		t.Fatalf("expected one-shot services [migrate seed], got %v", plan.OneShot)
+	}
+}
+
+func TestBuildWorkbenchRestartPlanRejectsUnknownServiceAndCycles(t *testing.T) {
+	t.Parallel()
+
+	snapshot := WorkbenchStackSnapshot{
+		ProjectName: "demo",
+		Services: []WorkbenchComposeService{
+			{ServiceName: "a"},
+			{ServiceName: "b"},
+			{ServiceName: "c"},
+		},
+		Dependencies: []WorkbenchComposeDependency{
+			{ServiceName: "b", DependsOn: "a"},
+			{ServiceName: "b", DependsOn: "c"},
+			{ServiceName: "c", DependsOn: "b"},
+		},
+	}
+
+	for serviceName, code := range map[string]string{
+		"missing": "WB-RESTART-SERVICE-UNKNOWN",
+		"a":       "WB-RESTART-DEPENDENCY-CYCLE",
+	} {
+		_, err := buildWorkbenchRestartPlan(snapshot, serviceName)
+		typed, ok := errs.From(err)
+		if !ok || typed.Code != errs.CodeWorkbenchValidationFailed {
+			t.Fatalf("%s: expected %q, got %v", serviceName, errs.CodeWorkbenchValidationFailed, err)
+		}
+		details, _ := typed.Details.(map[string]any)
+		issues, _ := details["issues"].([]WorkbenchMutationIssue)
+		if len(issues) != 1 || issues[0].Code != code {
+			t.Fatalf("%s: expected %s, got %#v", serviceName, code, issues)
+		}
+	}
+}
@@ -34,6 +34,8 @@ type WorkbenchService struct {
 	templatesDir      string
 	projects          repository.ProjectRepository
 	settings          repository.SettingsRepository
+	snapshots         repository.WorkbenchSnapshotRepository
+	revisions         repository.WorkbenchRevisionRepository
 	sessionSecret     string
 	hostPortScanner   workbenchHostPortScanner
 	runtimeMetaClient infraDockerMetadataClient
@@ -43,6 +45,10 @@ type WorkbenchService struct {
 	backupMaxCount    int
 	backupMaxAge      time.Duration
 	nowFn             func() time.Time
+	catalogMu         sync.Mutex
+	catalogDir        string
+	driftMu           sync.Mutex
+	driftScan         *WorkbenchDriftScanReport
 }
 
 func NewWorkbenchService(templatesDir string, projects repository.ProjectRepository) *WorkbenchService {
@@ -91,6 +97,16 @@ func (s *WorkbenchService) AcquireProjectLock(ctx context.Context, projectName s
 	return s.lockManager.Acquire(ctx, normalized, s.lockWaitTimeout)
 }
 
+// AcquireProjectLockWait is AcquireProjectLock with a caller-chosen wait, for
+// jobs that should queue behind one another instead of failing fast.
+func (s *WorkbenchService) AcquireProjectLockWait(ctx context.Context, projectName string, wait time.Duration) (func(), error) {
+	normalized, err := normalizeWorkbenchProjectName(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return s.lockManager.Acquire(ctx, normalized, wait)
+}
+
 func (s *WorkbenchService) ResolveComposeSource(ctx context.Context, projectName string) (WorkbenchComposeSource, error) {
 	resolved, err := resolveProjectPath(ctx, s.projects, s.templatesDir, projectName, s.runtimeMetaClient)
 	if err != nil {
@@ -927,3 +927,5 @@ func (fakeWorkbenchProjectRepo) GetByName(context.Context, string) (*models.Proj
 }
 
 func (fakeWorkbenchProjectRepo) Update(context.Context, *models.Project) error { return nil }
+
+func (fakeWorkbenchProjectRepo) Delete(context.Context, uint) error { return nil }
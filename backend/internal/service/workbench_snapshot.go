@@ -38,6 +38,7 @@ func (s *WorkbenchService) GetSnapshot(
 		Dependencies:      []WorkbenchComposeDependency{},
 		Ports:             []WorkbenchComposePort{},
 		Resources:         []WorkbenchComposeResource{},
+		Networks:          []WorkbenchComposeNetwork{},
 		NetworkRefs:       []WorkbenchComposeNetworkRef{},
 		VolumeRefs:        []WorkbenchComposeVolumeRef{},
 		EnvRefs:           []WorkbenchComposeEnvRef{},
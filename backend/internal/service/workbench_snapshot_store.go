@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+// workbenchSnapshotAnyRevision skips the optimistic revision check. It is only
+// used for seeding and migration writes.
+const workbenchSnapshotAnyRevision = -1
+
+// SetSnapshotRepository moves snapshot storage from the shared settings blob to
+// one row per project. Snapshots hold compose structure and env variable names
+// only, so rows are stored as plain JSON.
+func (s *WorkbenchService) SetSnapshotRepository(snapshots repository.WorkbenchSnapshotRepository) {
+	s.snapshots = snapshots
+}
+
+func (s *WorkbenchService) loadTableWorkbenchSnapshot(
+	ctx context.Context,
+	projectName string,
+) (WorkbenchStackSnapshot, bool, error) {
+	stored, err := s.snapshots.Get(ctx, projectName)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return WorkbenchStackSnapshot{}, false, nil
+		}
+		return WorkbenchStackSnapshot{}, false, workbenchStorageError(projectName, "failed to load workbench snapshot", err)
+	}
+
+	var snapshot WorkbenchStackSnapshot
+	if err := json.Unmarshal([]byte(stored.Payload), &snapshot); err != nil {
+		return WorkbenchStackSnapshot{}, false, workbenchStorageError(projectName, "failed to decode workbench snapshot", err)
+	}
+	normalized := normalizeWorkbenchStackSnapshot(snapshot)
+	normalized.Revision = stored.Revision
+	return normalized, true, nil
+}
+
+func (s *WorkbenchService) saveTableWorkbenchSnapshot(
+	ctx context.Context,
+	projectName string,
+	snapshot WorkbenchStackSnapshot,
+	expectedRevision int,
+	revision *models.WorkbenchSnapshotRevision,
+) error {
+	normalized := normalizeWorkbenchStackSnapshot(snapshot)
+	normalized.ProjectName = projectName
+	payload, err := json.Marshal(normalized)
+	if err != nil {
+		return workbenchStorageError(projectName, "failed to encode workbench snapshot", err)
+	}
+
+	row := &models.WorkbenchSnapshot{
+		ProjectName: projectName,
+		Revision:    normalized.Revision,
+		Payload:     string(payload),
+	}
+	if revision != nil {
+		err = s.snapshots.SaveWithRevision(ctx, row, expectedRevision, revision)
+	} else {
+		err = s.snapshots.Save(ctx, row, expectedRevision)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			storedRevision := 0
+			if current, getErr := s.snapshots.Get(ctx, projectName); getErr == nil {
+				storedRevision = current.Revision
+			}
+			return workbenchSnapshotConflictError(projectName, expectedRevision, storedRevision)
+		}
+		return workbenchStorageError(projectName, "failed to persist workbench snapshot", err)
+	}
+	return nil
+}
+
+// MigrateLegacySnapshots copies snapshots still held in the settings blob into
+// the snapshot table and then drops them from the blob. Projects that already
+// have a row keep it. Safe to run on every start.
+func (s *WorkbenchService) MigrateLegacySnapshots(ctx context.Context) (int, error) {
+	if s.snapshots == nil || s.settings == nil {
+		return 0, nil
+	}
+
+	settingsWriteLock.Lock()
+	defer settingsWriteLock.Unlock()
+
+	stored, err := s.settings.Get(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, workbenchStorageError("", "failed to load workbench settings payload", err)
+	}
+	if stored == nil {
+		return 0, nil
+	}
+	payload, err := loadSettingsEncryptedPayload(s.sessionSecret, stored.NetBirdConfigEncrypted)
+	if err != nil {
+		if isSettingsPayloadDecryptMismatch(err) {
+			return 0, nil
+		}
+		return 0, workbenchStorageError("", "failed to decode workbench settings payload", err)
+	}
+	if len(payload.Workbench) == 0 {
+		return 0, nil
+	}
+
+	migrated := 0
+	for projectName, snapshot := range payload.Workbench {
+		if _, err := s.snapshots.Get(ctx, projectName); err == nil {
+			continue
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return migrated, workbenchStorageError(projectName, "failed to check workbench snapshot table", err)
+		}
+		if err := s.saveTableWorkbenchSnapshot(ctx, projectName, snapshot, 0, nil); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	payload.Workbench = nil
+	encoded, err := encodeSettingsEncryptedPayload(s.sessionSecret, payload)
+	if err != nil {
+		return migrated, workbenchStorageError("", "failed to encode workbench settings payload", err)
+	}
+	stored.NetBirdConfigEncrypted = encoded
+	if err := s.settings.Save(ctx, stored); err != nil {
+		return migrated, workbenchStorageError("", "failed to clear migrated workbench snapshots from settings", err)
+	}
+	return migrated, nil
+}
+
+func workbenchSnapshotConflictError(projectName string, expectedRevision, storedRevision int) error {
+	return errs.WithDetails(
+		errs.New(errs.CodeWorkbenchStaleRevision, fmt.Sprintf("workbench snapshot for project %q was changed by another writer", projectName)),
+		map[string]any{
+			"project":          projectName,
+			"expectedRevision": expectedRevision,
+			"revision":         storedRevision,
+		},
+	)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go-notes/internal/errs"
+	"go-notes/internal/models"
+	"go-notes/internal/repository"
+)
+
+type fakeWorkbenchSnapshotRepo struct {
+	mu   sync.Mutex
+	rows map[string]models.WorkbenchSnapshot
+	// revisions receives the rows written through SaveWithRevision;
+	// revisionErr fails those writes without storing either row.
+	revisions   *fakeWorkbenchRevisionRepo
+	revisionErr error
+}
+
+func (r *fakeWorkbenchSnapshotRepo) Get(_ context.Context, projectName string) (*models.WorkbenchSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.rows[projectName]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &row, nil
+}
+
+func (r *fakeWorkbenchSnapshotRepo) List(_ context.Context) ([]models.WorkbenchSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rows := make([]models.WorkbenchSnapshot, 0, len(r.rows))
+	for _, row := range r.rows {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (r *fakeWorkbenchSnapshotRepo) Save(_ context.Context, snapshot *models.WorkbenchSnapshot, expectedRevision int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkRevision(snapshot, expectedRevision); err != nil {
+		return err
+	}
+	r.rows[snapshot.ProjectName] = *snapshot
+	return nil
+}
+
+func (r *fakeWorkbenchSnapshotRepo) SaveWithRevision(
+	ctx context.Context,
+	snapshot *models.WorkbenchSnapshot,
+	expectedRevision int,
+	revision *models.WorkbenchSnapshotRevision,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkRevision(snapshot, expectedRevision); err != nil {
+		return err
+	}
+	if r.revisionErr != nil {
+		return r.revisionErr
+	}
+	if r.revisions != nil {
+		if err := r.revisions.Create(ctx, revision); err != nil {
+			return err
+		}
+	}
+	r.rows[snapshot.ProjectName] = *snapshot
+	return nil
+}
+
+func (r *fakeWorkbenchSnapshotRepo) Delete(_ context.Context, projectName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rows, projectName)
+	return nil
+}
+
+func (r *fakeWorkbenchSnapshotRepo) checkRevision(snapshot *models.WorkbenchSnapshot, expectedRevision int) error {
+	if r.rows == nil {
+		r.rows = map[string]models.WorkbenchSnapshot{}
+	}
+	current, exists := r.rows[snapshot.ProjectName]
+	switch {
+	case expectedRevision == 0 && exists:
+		return repository.ErrConflict
+	case expectedRevision > 0 && (!exists || current.Revision != expectedRevision):
+		return repository.ErrConflict
+	}
+	return nil
+}
+
+func TestWorkbenchSnapshotTableStoresProjectsIndependently(t *testing.T) {
+	t.Parallel()
+
+	settingsRepo := &fakeSettingsRepo{}
+	snapshots := &fakeWorkbenchSnapshotRepo{}
+	svc := NewWorkbenchServiceWithStorage(t.TempDir(), nil, settingsRepo, "test-session-secret")
+	svc.SetSnapshotRepository(snapshots)
+
+	for _, project := range []string{"alpha", "bravo"} {
+		if err := svc.saveWorkbenchSnapshot(context.Background(), project, WorkbenchStackSnapshot{
+			ProjectName: project,
+			Revision:    3,
+			Services:    []WorkbenchComposeService{{ServiceName: "api", Image: "nginx:1.25"}},
+		}); err != nil {
+			t.Fatalf("save %s: %v", project, err)
+		}
+	}
+	if settingsRepo.settings != nil {
+		t.Fatal("expected snapshot writes to leave the settings blob untouched")
+	}
+
+	loaded, exists, err := svc.loadStoredWorkbenchSnapshot(context.Background(), "alpha")
+	if err != nil || !exists {
+		t.Fatalf("load alpha: exists=%v err=%v", exists, err)
+	}
+	if loaded.Revision != 3 || len(loaded.Services) != 1 {
+		t.Fatalf("unexpected loaded snapshot: %#v", loaded)
+	}
+
+	loaded.Revision++
+	if err := svc.saveWorkbenchSnapshotExpected(context.Background(), "alpha", loaded, 3); err != nil {
+		t.Fatalf("save with current revision: %v", err)
+	}
+
+	err = svc.saveWorkbenchSnapshotExpected(context.Background(), "alpha", loaded, 3)
+	typed, ok := errs.From(err)
+	if !ok || typed.Code != errs.CodeWorkbenchStaleRevision {
+		t.Fatalf("expected %q for stale write, got %v", errs.CodeWorkbenchStaleRevision, err)
+	}
+	details, _ := typed.Details.(map[string]any)
+	if details["revision"] != 4 || details["expectedRevision"] != 3 {
+		t.Fatalf("unexpected conflict details: %#v", details)
+	}
+
+	bravo, _, err := svc.loadStoredWorkbenchSnapshot(context.Background(), "bravo")
+	if err != nil || bravo.Revision != 3 {
+		t.Fatalf("expected bravo to be unaffected, got %#v err=%v", bravo, err)
+	}
+}
+
+func TestWorkbenchMigrateLegacySnapshotsMovesBlobEntriesToTable(t *testing.T) {
+	t.Parallel()
+
+	settingsRepo := &fakeSettingsRepo{}
+	legacy := NewWorkbenchServiceWithStorage(t.TempDir(), nil, settingsRepo, "test-session-secret")
+	for _, project := range []string{"alpha", "bravo"} {
+		if err := legacy.saveWorkbenchSnapshot(context.Background(), project, WorkbenchStackSnapshot{
+			ProjectName: project,
+			Revision:    2,
+		}); err != nil {
+			t.Fatalf("seed legacy %s: %v", project, err)
+		}
+	}
+
+	snapshots := &fakeWorkbenchSnapshotRepo{rows: map[string]models.WorkbenchSnapshot{
+		"bravo": {ProjectName: "bravo", Revision: 9, Payload: `{"projectName":"bravo","revision":9}`},
+	}}
+	svc := NewWorkbenchServiceWithStorage(t.TempDir(), nil, settingsRepo, "test-session-secret")
+	svc.SetSnapshotRepository(snapshots)
+
+	migrated, err := svc.MigrateLegacySnapshots(context.Background())
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if migrated != 1 {
+		t.Fatalf("expected 1 migrated snapshot, got %d", migrated)
+	}
+	if snapshots.rows["alpha"].Revision != 2 {
+		t.Fatalf("expected alpha row at revision 2, got %#v", snapshots.rows["alpha"])
+	}
+	if snapshots.rows["bravo"].Revision != 9 {
+		t.Fatalf("expected existing bravo row to win, got %#v", snapshots.rows["bravo"])
+	}
+
+	payload, err := loadSettingsEncryptedPayload("test-session-secret", settingsRepo.settings.NetBirdConfigEncrypted)
+	if err != nil {
+		t.Fatalf("decode settings payload: %v", err)
+	}
+	if len(payload.Workbench) != 0 {
+		t.Fatalf("expected migrated snapshots to be cleared from settings, got %#v", payload.Workbench)
+	}
+
+	again, err := svc.MigrateLegacySnapshots(context.Background())
+	if err != nil || again != 0 {
+		t.Fatalf("expected second migration to be a no-op, got %d err=%v", again, err)
+	}
+}
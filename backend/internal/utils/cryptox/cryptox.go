@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"strings"
@@ -85,6 +86,16 @@ func DecryptWithSecret(secret, value string) (string, error) {
 	return string(plaintext), nil
 }
 
+// KeyID returns a short fingerprint of secret that can be stored next to
+// ciphertext to tell which key sealed it. It does not reveal the key.
+func KeyID(secret string) string {
+	if strings.TrimSpace(secret) == "" {
+		return ""
+	}
+	sum := sha256.Sum256(append([]byte("gungnr-key-id:"), deriveKey(secret)...))
+	return hex.EncodeToString(sum[:8])
+}
+
 func deriveKey(secret string) []byte {
 	sum := sha256.Sum256([]byte(secret))
 	return sum[:]
@@ -14,6 +14,8 @@ var (
 	domainRe        = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)+$`)
 	safeRefRe       = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
 	containerNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	gitRefRe        = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_./-]*$`)
+	routePathRe     = regexp.MustCompile(`^(?:/[a-zA-Z0-9_.~-]+)+/?$`)
 )
 
 // ProjectName validates a project name: lowercase alphanumerics or dashes, 3-63 chars.
@@ -101,6 +103,32 @@ func ContainerName(name string) error {
 	return nil
 }
 
+// GitRef validates a branch, tag, or commit name passed to git. It is stricter
+// than git-check-ref-format so a ref can never be read as a command-line flag.
+func GitRef(ref string) error {
+	if ref == "" {
+		return errs.New(errs.CodeValidationGitRef, "git ref is required")
+	}
+	if len(ref) > 255 || !gitRefRe.MatchString(ref) ||
+		strings.Contains(ref, "..") || strings.Contains(ref, "//") ||
+		strings.HasSuffix(ref, "/") || strings.HasSuffix(ref, ".") || strings.HasSuffix(ref, ".lock") {
+		return errs.New(errs.CodeValidationGitRef, "git ref must be a valid branch, tag, or commit name")
+	}
+	return nil
+}
+
+// RoutePath validates a path prefix routed to a project port. An empty path or
+// "/" is the catch-all route for the hostname.
+func RoutePath(path string) error {
+	if path == "" || path == "/" {
+		return nil
+	}
+	if len(path) > 200 || !routePathRe.MatchString(path) || strings.Contains(path, "/../") || strings.HasSuffix(path, "/..") {
+		return errs.New(errs.CodeValidationRoutePath, "route path must start with '/' and use letters, numbers, '.', '_', '~' or '-' per segment")
+	}
+	return nil
+}
+
 // UserRole validates a user role assignment. Only "admin" and "user" are assignable.
 func UserRole(role string) error {
 	normalized := strings.ToLower(strings.TrimSpace(role))
@@ -1,7 +1,15 @@
 import { api } from '@/services/api'
-import type { CloudflarePreflight, CloudflareZonesResponse } from '@/types/cloudflare'
+import type {
+  CloudflarePreflight,
+  CloudflareReconcileCleanupResponse,
+  CloudflareReconcileReport,
+  CloudflareZonesResponse,
+} from '@/types/cloudflare'
 
 export const cloudflareApi = {
   preflight: () => api.get<CloudflarePreflight>('/api/v1/cloudflare/preflight'),
   zones: () => api.get<CloudflareZonesResponse>('/api/v1/cloudflare/zones'),
+  reconcile: () => api.get<CloudflareReconcileReport>('/api/v1/cloudflare/reconcile'),
+  reconcileCleanup: (findingIds: string[]) =>
+    api.post<CloudflareReconcileCleanupResponse>('/api/v1/cloudflare/reconcile/cleanup', { findingIds }),
 }
@@ -0,0 +1,14 @@
+import { api } from '@/services/api'
+import type { DNSRecordRequest, DNSZoneRecord, DNSZoneRecords } from '@/types/dns'
+
+const dnsRecordPath = (zoneId: string, recordId: string) =>
+  `/api/v1/dns/${encodeURIComponent(zoneId)}/${encodeURIComponent(recordId)}`
+
+export const dnsApi = {
+  list: (zone?: string) =>
+    api.get<{ zones: DNSZoneRecords[] }>(zone ? `/api/v1/dns?zone=${encodeURIComponent(zone)}` : '/api/v1/dns'),
+  create: (payload: DNSRecordRequest) => api.post<{ record: DNSZoneRecord }>('/api/v1/dns', payload),
+  update: (zoneId: string, recordId: string, payload: DNSRecordRequest) =>
+    api.put<{ record: DNSZoneRecord }>(dnsRecordPath(zoneId, recordId), payload),
+  remove: (zoneId: string, recordId: string) => api.delete<{ record: DNSZoneRecord }>(dnsRecordPath(zoneId, recordId)),
+}
@@ -1,5 +1,5 @@
 import { api, getApiBaseUrl } from '@/services/api'
-import type { DockerContainer, DockerReadDiagnostic, DockerUsageSummary, HostRuntimeSnapshot } from '@/types/host'
+import type { DockerContainer, DockerReadDiagnostic, DockerUsageSummary, HostRuntimeSnapshot, QuickServiceImageUpdateReport } from '@/types/host'
 import type { Job } from '@/types/jobs'
 
 const restartProjectTimeoutMs = 10 * 60 * 1000
@@ -11,6 +11,8 @@ export const hostApi = {
     api.get<{ summary: DockerUsageSummary; diagnostics?: DockerReadDiagnostic[] }>('/api/v1/host/docker/usage', {
       params: project ? { project } : undefined,
     }),
+  quickServiceImageUpdates: () =>
+    api.get<{ report: QuickServiceImageUpdateReport }>('/api/v1/host/images/updates'),
   runtimeSnapshot: () =>
     api.get<{ snapshot: HostRuntimeSnapshot }>('/api/v1/host/stats'),
   runtimeStatsStreamUrl: () => `${getApiBaseUrl().replace(/\/$/, '')}/api/v1/host/stats/stream`,
@@ -1,12 +1,36 @@
 import { api, getApiBaseUrl } from '@/services/api'
 import type {
   LocalProject,
+  ProjectAccessHostname,
+  ProjectAccessRules,
+  ProjectAccessToggle,
+  ProjectArchivePlanIngressRule,
+  ProjectIngressOptions,
+  ProjectIngressUpdate,
   Project,
+  ProjectBlueGreenPlan,
   ProjectArchiveOptions,
   ProjectArchivePlan,
   ProjectDetail,
+  ProjectEnvDiff,
   ProjectEnvRead,
+  ProjectEnvRestore,
+  ProjectEnvValidation,
+  ProjectEnvVersion,
   ProjectEnvWrite,
+  ProjectClonePlan,
+  ProjectHostnamePlan,
+  ProjectRestorePlan,
+  ProjectBackupRetention,
+  ProjectDatabase,
+  ProjectDatabaseDump,
+  ProjectVolumeBackup,
+  ProjectRoute,
+  ProjectRouteInput,
+  ProjectRoutesPlan,
+  ProjectTunnelPlan,
+  ProjectImageUpdateReport,
+  ProjectImageUpdateTarget,
 } from '@/types/projects'
 import type { Job, JobListResponse } from '@/types/jobs'
 
@@ -21,7 +45,76 @@ export const projectsApi = {
     api.get<{ plan: ProjectArchivePlan }>(`/api/v1/projects/${encodeURIComponent(name)}/archive/plan`),
   archiveProject: (name: string, payload: Partial<ProjectArchiveOptions>) =>
     api.post<{ job: Job; plan: ProjectArchivePlan }>(`/api/v1/projects/${encodeURIComponent(name)}/archive`, payload),
+  restoreProject: (name: string) =>
+    api.post<{ job: Job; plan: ProjectRestorePlan }>(`/api/v1/projects/${encodeURIComponent(name)}/restore`),
+  listBackups: (name: string) =>
+    api.get<{ backups: ProjectVolumeBackup[]; retention: ProjectBackupRetention }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/backups`,
+    ),
+  createBackup: (name: string) =>
+    api.post<{ job: Job; volumes: string[] }>(`/api/v1/projects/${encodeURIComponent(name)}/backups`),
+  restoreBackup: (name: string, backupId: string, volumes: string[] = []) =>
+    api.post<{ job: Job; backup: ProjectVolumeBackup }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/backups/${encodeURIComponent(backupId)}/restore`,
+      { volumes },
+    ),
+  updateBackupPolicy: (name: string, retention: number) =>
+    api.put<{ project: Project }>(`/api/v1/projects/${encodeURIComponent(name)}/backups/policy`, { retention }),
+  getDatabases: (name: string) =>
+    api.get<{ databases: ProjectDatabase[]; dumps: ProjectDatabaseDump[] }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/databases`,
+    ),
+  dumpDatabases: (name: string, services: string[] = []) =>
+    api.post<{ job: Job; databases: { service: string; engine: string }[] }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/databases/dumps`,
+      { services },
+    ),
+  restoreDatabaseDump: (name: string, dumpId: string, payload: { targetProject?: string; services?: string[] } = {}) =>
+    api.post<{ job: Job; dump: ProjectDatabaseDump }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/databases/dumps/${encodeURIComponent(dumpId)}/restore`,
+      payload,
+    ),
+  changeHostnames: (name: string, payload: { subdomain: string; domain?: string }) =>
+    api.patch<{ job: Job; plan: ProjectHostnamePlan }>(`/api/v1/projects/${encodeURIComponent(name)}/hostnames`, payload),
+  cloneProject: (name: string, payload: { name: string; subdomain?: string; domain?: string; copyVolumes?: boolean }) =>
+    api.post<{ job: Job; plan: ProjectClonePlan }>(`/api/v1/projects/${encodeURIComponent(name)}/clone`, payload),
+  getRoutes: (name: string) =>
+    api.get<{ routes: ProjectRoute[] }>(`/api/v1/projects/${encodeURIComponent(name)}/routes`),
+  updateRoutes: (name: string, routes: ProjectRouteInput[]) =>
+    api.put<{ job: Job; plan: ProjectRoutesPlan }>(`/api/v1/projects/${encodeURIComponent(name)}/routes`, { routes }),
+  getAccess: (name: string) =>
+    api.get<{ hostnames: ProjectAccessHostname[] }>(`/api/v1/projects/${encodeURIComponent(name)}/access`),
+  updateAccess: (name: string, payload: Partial<ProjectAccessRules> & { hostname?: string }) =>
+    api.put<{ hostnames: ProjectAccessHostname[] }>(`/api/v1/projects/${encodeURIComponent(name)}/access`, payload),
+  removeAccess: (name: string, hostname?: string) =>
+    api.delete<{ hostnames: ProjectAccessHostname[] }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/access${hostname ? `?${new URLSearchParams({ hostname }).toString()}` : ''}`,
+    ),
+  getIngress: (name: string) =>
+    api.get<{ ingressRules: ProjectArchivePlanIngressRule[]; warnings: string[] }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/ingress`,
+    ),
+  updateIngress: (name: string, payload: ProjectIngressUpdate) =>
+    api.put<{ job: Job }>(`/api/v1/projects/${encodeURIComponent(name)}/ingress`, payload),
+  updateTunnel: (name: string, tunnel: string) =>
+    api.put<{ job: Job; plan: ProjectTunnelPlan }>(`/api/v1/projects/${encodeURIComponent(name)}/tunnel`, { tunnel }),
   listLocal: () => api.get<{ projects: LocalProject[] }>('/api/v1/projects/local'),
+  imageUpdates: (name: string) =>
+    api.get<{ report: ProjectImageUpdateReport }>(`/api/v1/projects/${encodeURIComponent(name)}/images/updates`),
+  updateImages: (name: string, payload: { images?: string[]; healthTimeoutSeconds?: number } = {}) =>
+    api.post<{ job: Job; targets: ProjectImageUpdateTarget[] }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/images/update`,
+      payload,
+    ),
+  blueGreenDeploy: (name: string, payload: { healthTimeoutSeconds?: number } = {}) =>
+    api.post<{ job: Job; plan: ProjectBlueGreenPlan }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/deploy/blue-green`,
+      payload,
+    ),
+  gitRedeploy: (name: string, payload: { ref?: string } = {}) =>
+    api.post<{ job: Job }>(`/api/v1/projects/${encodeURIComponent(name)}/deploy/git`, payload),
+  updateGitDeploySettings: (name: string, payload: { branch: string; autoDeploy: boolean }) =>
+    api.put<{ project: Project }>(`/api/v1/projects/${encodeURIComponent(name)}/deploy/git/settings`, payload),
   restartStack: (name: string) =>
     api.post<{ job: Job }>(
       `/api/v1/projects/${encodeURIComponent(name)}/stack/restart`,
@@ -55,6 +148,20 @@ export const projectsApi = {
       content,
       createBackup,
     }),
+  validateEnv: (name: string, content: string) =>
+    api.post<{ validation: ProjectEnvValidation }>(`/api/v1/projects/${encodeURIComponent(name)}/env/validate`, {
+      content,
+    }),
+  envHistory: (name: string) =>
+    api.get<{ versions: ProjectEnvVersion[] }>(`/api/v1/projects/${encodeURIComponent(name)}/env/history`),
+  envDiff: (name: string, from: string, to = 'current') =>
+    api.get<{ diff: ProjectEnvDiff }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/env/diff?${new URLSearchParams({ from, to }).toString()}`,
+    ),
+  restoreEnv: (name: string, versionId: string) =>
+    api.post<{ env: ProjectEnvRestore }>(
+      `/api/v1/projects/${encodeURIComponent(name)}/env/history/${encodeURIComponent(versionId)}/restore`,
+    ),
   createFromTemplate: (payload: {
     name: string
     subdomain?: string
@@ -62,10 +169,28 @@ export const projectsApi = {
     proxyPort?: number
     dbPort?: number
     template?: string
+    access?: ProjectAccessToggle
+    ingress?: ProjectIngressOptions
+    tunnel?: string
   }) => api.post<{ job: Job }>('/api/v1/projects/template', payload),
-  deployExisting: (payload: { name: string; subdomain: string; domain?: string; port?: number }) =>
+  deployExisting: (payload: {
+    name: string
+    subdomain: string
+    domain?: string
+    port?: number
+    access?: ProjectAccessToggle
+    ingress?: ProjectIngressOptions
+    tunnel?: string
+  }) =>
     api.post<{ job: Job }>('/api/v1/projects/existing', payload),
-  forwardLocal: (payload: { name: string; subdomain: string; domain?: string; port?: number }) =>
+  forwardLocal: (payload: {
+    name: string
+    subdomain: string
+    domain?: string
+    port?: number
+    ingress?: ProjectIngressOptions
+    tunnel?: string
+  }) =>
     api.post<{ job: Job }>('/api/v1/projects/forward', payload),
   quickService: (payload: {
     subdomain: string
@@ -0,0 +1,19 @@
+import { api } from '@/services/api'
+import type { SecretMetadata, SecretsStatus } from '@/types/secrets'
+
+const projectSecretsPath = (project: string) => `/api/v1/projects/${encodeURIComponent(project)}/secrets`
+
+export const secretsApi = {
+  status: () => api.get<{ secrets: SecretsStatus }>('/api/v1/secrets'),
+  listProject: (project: string) => api.get<{ secrets: SecretMetadata[] }>(projectSecretsPath(project)),
+  setProject: (project: string, name: string, value: string) =>
+    api.put<{ secret: SecretMetadata }>(`${projectSecretsPath(project)}/${encodeURIComponent(name)}`, { value }),
+  deleteProject: (project: string, name: string) =>
+    api.delete<{ deleted: string }>(`${projectSecretsPath(project)}/${encodeURIComponent(name)}`),
+  projectVersions: (project: string, name: string) =>
+    api.get<{ versions: SecretMetadata[] }>(`${projectSecretsPath(project)}/${encodeURIComponent(name)}/versions`),
+  restoreProjectVersion: (project: string, name: string, version: number) =>
+    api.post<{ secret: SecretMetadata }>(
+      `${projectSecretsPath(project)}/${encodeURIComponent(name)}/versions/${version}/restore`,
+    ),
+}
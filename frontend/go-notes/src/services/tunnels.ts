@@ -0,0 +1,11 @@
+import { api } from '@/services/api'
+import type { Tunnel, TunnelRequest } from '@/types/tunnels'
+
+const tunnelPath = (name: string) => `/api/v1/tunnels/${encodeURIComponent(name)}`
+
+export const tunnelsApi = {
+  list: () => api.get<{ tunnels: Tunnel[] }>('/api/v1/tunnels'),
+  create: (payload: TunnelRequest & { name: string }) => api.post<{ tunnel: Tunnel }>('/api/v1/tunnels', payload),
+  update: (name: string, payload: TunnelRequest) => api.put<{ tunnel: Tunnel }>(tunnelPath(name), payload),
+  remove: (name: string) => api.delete<{ tunnel: Tunnel }>(tunnelPath(name)),
+}
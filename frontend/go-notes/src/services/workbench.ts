@@ -1,5 +1,8 @@
 import { api } from '@/services/api'
 import type {
+  WorkbenchBundleExportResponse,
+  WorkbenchBundleImportRequest,
+  WorkbenchBundleImportResponse,
   WorkbenchComposeApplyRequest,
   WorkbenchComposeApplyResponse,
   WorkbenchComposeBackupsResponse,
@@ -15,11 +18,18 @@ import type {
   WorkbenchImportResponse,
   WorkbenchModuleMutationRequest,
   WorkbenchModuleMutationResponse,
+  WorkbenchNetworkCreateRequest,
+  WorkbenchNetworkMutationResponse,
+  WorkbenchNetworkUpdateRequest,
   WorkbenchPortMutationRequest,
   WorkbenchPortMutationResponse,
   WorkbenchPortResolveResponse,
   WorkbenchResourceMutationRequest,
   WorkbenchResourceMutationResponse,
+  WorkbenchRestartPlanResponse,
+  WorkbenchServiceRestartRequest,
+  WorkbenchServiceRestartResponse,
+  WorkbenchServiceNetworksRequest,
   WorkbenchPortSuggestionRequest,
   WorkbenchPortSuggestionResponse,
   WorkbenchSnapshotResponse,
@@ -34,6 +44,15 @@ export const workbenchApi = {
     api.get<WorkbenchSnapshotResponse>(workbenchProjectPath(projectName)),
   getGraph: (projectName: string) =>
     api.get<WorkbenchDependencyGraphResponse>(`${workbenchProjectPath(projectName)}/graph`),
+  getServiceRestartPlan: (projectName: string, serviceName: string) =>
+    api.get<WorkbenchRestartPlanResponse>(
+      `${workbenchProjectPath(projectName)}/graph/services/${encodeURIComponent(serviceName)}/restart-plan`,
+    ),
+  restartService: (projectName: string, serviceName: string, payload: WorkbenchServiceRestartRequest = {}) =>
+    api.post<WorkbenchServiceRestartResponse>(
+      `${workbenchProjectPath(projectName)}/graph/services/${encodeURIComponent(serviceName)}/restart`,
+      payload,
+    ),
   getCatalog: (projectName: string) =>
     api.get<WorkbenchOptionalServiceCatalogResponse>(`${workbenchProjectPath(projectName)}/catalog`),
   addOptionalService: (projectName: string, payload: WorkbenchOptionalServiceAddRequest) =>
@@ -47,6 +66,10 @@ export const workbenchApi = {
     ),
   importSnapshot: (projectName: string, reason: WorkbenchImportReason) =>
     api.post<WorkbenchImportResponse>(`${workbenchProjectPath(projectName)}/import`, { reason }),
+  exportBundle: (projectName: string) =>
+    api.get<WorkbenchBundleExportResponse>(`${workbenchProjectPath(projectName)}/export`),
+  importBundle: (projectName: string, payload: WorkbenchBundleImportRequest) =>
+    api.post<WorkbenchBundleImportResponse>(`${workbenchProjectPath(projectName)}/import-bundle`, payload),
   resolvePorts: (projectName: string) =>
     api.post<WorkbenchPortResolveResponse>(`${workbenchProjectPath(projectName)}/ports/resolve`, {}),
   mutatePort: (projectName: string, payload: WorkbenchPortMutationRequest) =>
@@ -60,6 +83,26 @@ export const workbenchApi = {
       `${workbenchProjectPath(projectName)}/services/${encodeURIComponent(serviceName)}/resources`,
       payload,
     ),
+  mutateServiceNetworks: (
+    projectName: string,
+    serviceName: string,
+    payload: WorkbenchServiceNetworksRequest,
+  ) =>
+    api.post<WorkbenchNetworkMutationResponse>(
+      `${workbenchProjectPath(projectName)}/services/${encodeURIComponent(serviceName)}/networks`,
+      payload,
+    ),
+  createNetwork: (projectName: string, payload: WorkbenchNetworkCreateRequest) =>
+    api.post<WorkbenchNetworkMutationResponse>(`${workbenchProjectPath(projectName)}/networks`, payload),
+  updateNetwork: (projectName: string, networkName: string, payload: WorkbenchNetworkUpdateRequest) =>
+    api.patch<WorkbenchNetworkMutationResponse>(
+      `${workbenchProjectPath(projectName)}/networks/${encodeURIComponent(networkName)}`,
+      payload,
+    ),
+  deleteNetwork: (projectName: string, networkName: string) =>
+    api.delete<WorkbenchNetworkMutationResponse>(
+      `${workbenchProjectPath(projectName)}/networks/${encodeURIComponent(networkName)}`,
+    ),
   mutateModule: (projectName: string, payload: WorkbenchModuleMutationRequest) =>
     api.post<WorkbenchModuleMutationResponse>(`${workbenchProjectPath(projectName)}/modules`, payload),
   suggestPorts: (projectName: string, payload: WorkbenchPortSuggestionRequest) =>
@@ -1,3 +1,5 @@
+import type { Job } from '@/types/jobs'
+
 export type CloudflarePreflightStatus =
   | 'ok'
   | 'warning'
@@ -27,3 +29,54 @@ export interface CloudflareZone {
 export interface CloudflareZonesResponse {
   zones: CloudflareZone[]
 }
+
+export type CloudflareReconcileFindingKind =
+  | 'dns_without_ingress'
+  | 'ingress_without_project'
+  | 'dns_points_elsewhere'
+
+export interface CloudflareReconcileFinding {
+  id: string
+  kind: CloudflareReconcileFindingKind
+  hostname: string
+  zoneId?: string
+  recordId?: string
+  recordType?: string
+  content?: string
+  path?: string
+  service?: string
+  source?: 'local' | 'remote'
+  tunnel?: string
+  project?: string
+  detail: string
+  cleanable: boolean
+  skipReason?: string
+}
+
+export interface CloudflareReconcileZone {
+  id: string
+  name: string
+  records: number
+}
+
+export interface CloudflareReconcileTunnel {
+  name: string
+  target: string
+  ingressRules: number
+}
+
+export interface CloudflareReconcileReport {
+  generatedAt: string
+  tunnelTarget: string
+  tunnels: CloudflareReconcileTunnel[]
+  zones: CloudflareReconcileZone[]
+  ingressRules: number
+  knownHostnames: number
+  findings: CloudflareReconcileFinding[]
+  warnings: string[]
+}
+
+export interface CloudflareReconcileCleanupResponse {
+  job: Job
+  findings: CloudflareReconcileFinding[]
+}
@@ -0,0 +1,36 @@
+export type DNSRecordType = 'A' | 'AAAA' | 'CNAME' | 'MX' | 'TXT'
+
+export interface DNSRecord {
+  id: string
+  type: DNSRecordType
+  name: string
+  content: string
+  proxied: boolean
+  ttl?: number
+  priority?: number
+  comment?: string
+  managed: boolean
+}
+
+export interface DNSZoneRecords {
+  id: string
+  name: string
+  records: DNSRecord[]
+}
+
+export interface DNSZoneRecord {
+  zoneId: string
+  zoneName: string
+  record: DNSRecord
+}
+
+export interface DNSRecordRequest {
+  zone?: string
+  type: DNSRecordType
+  name: string
+  content: string
+  ttl?: number
+  proxied: boolean
+  priority?: number
+  note?: string
+}
@@ -29,12 +29,14 @@ export interface DockerNetworkGuardrailsHealthRef {
 }
 
 export interface TunnelHealth {
+  name?: string
   status: HealthStatus
   detail?: string
   tunnel?: string
   connections?: number
   configPath?: string
   diagnostics?: TunnelDiagnostics
+  tunnels?: TunnelHealth[]
 }
 
 export interface TunnelDiagnostics {
@@ -1,3 +1,5 @@
+import type { ImageDigestStatus } from '@/types/projects'
+
 export interface DockerPortBinding {
   hostIp: string
   hostPort: number
@@ -17,6 +19,7 @@ export interface DockerContainer {
   service: string
   project: string
   portBindings: DockerPortBinding[]
+  quickService?: boolean
 }
 
 export interface DockerReadDiagnostic {
@@ -123,3 +126,13 @@ export interface HostRuntimeStreamSample {
   projectsByName?: Record<string, HostRuntimeWorkloadStreamUsage>
   warnings?: string[]
 }
+
+export interface QuickServiceImageUpdate extends ImageDigestStatus {
+  containers: string[]
+}
+
+export interface QuickServiceImageUpdateReport {
+  checkedAt: string
+  updatesAvailable: number
+  images: QuickServiceImageUpdate[]
+}
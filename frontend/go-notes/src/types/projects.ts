@@ -8,6 +8,12 @@ export interface Project {
   status: string
   createdAt: string
   updatedAt: string
+  gitBranch?: string
+  autoDeploy: boolean
+  deployedCommit?: string
+  deployedAt?: string
+  backupRetention: number
+  tunnel?: string
 }
 
 export interface LocalProject {
@@ -80,12 +86,39 @@ export interface ProjectDetail {
   diagnostics?: ProjectDetailDiagnostic[]
 }
 
+export interface ProjectEnvIssue {
+  line: number
+  key?: string
+  message: string
+}
+
+export interface ProjectEnvMissingKey {
+  key: string
+  references: string[]
+}
+
+export interface ProjectEnvValidation {
+  valid: boolean
+  keys: string[]
+  issues: ProjectEnvIssue[]
+  missingKeys: ProjectEnvMissingKey[]
+  warnings: string[]
+}
+
+export interface ProjectEnvKeyChanges {
+  added: string[]
+  removed: string[]
+  changed: string[]
+}
+
 export interface ProjectEnvRead {
   path: string
   exists: boolean
   sizeBytes: number
   updatedAt?: string
   content: string
+  validation: ProjectEnvValidation
+  managedKeys: string[]
 }
 
 export interface ProjectEnvWrite {
@@ -93,6 +126,26 @@ export interface ProjectEnvWrite {
   sizeBytes: number
   updatedAt: string
   backupPath?: string
+  versionId?: string
+  changes: ProjectEnvKeyChanges
+  missingKeys: ProjectEnvMissingKey[]
+}
+
+export interface ProjectEnvVersion {
+  id: string
+  createdAt: string
+  sizeBytes: number
+  keyCount: number
+}
+
+export interface ProjectEnvDiff extends ProjectEnvKeyChanges {
+  from: string
+  to: string
+  unchanged: number
+}
+
+export interface ProjectEnvRestore extends ProjectEnvWrite {
+  restoredVersion: string
 }
 
 export interface ProjectArchiveOptions {
@@ -100,6 +153,8 @@ export interface ProjectArchiveOptions {
   removeVolumes: boolean
   removeIngress: boolean
   removeDns: boolean
+  removeAccess: boolean
+  backupVolumes?: boolean
 }
 
 export interface ProjectArchivePlanProject {
@@ -127,8 +182,10 @@ export interface ProjectArchivePlanServiceExposure {
 
 export interface ProjectArchivePlanIngressRule {
   hostname: string
+  path?: string
   service: string
   source: 'local' | 'remote' | string
+  origin?: ProjectIngressOrigin
 }
 
 export interface ProjectArchivePlanDNSRecord {
@@ -142,6 +199,15 @@ export interface ProjectArchivePlanDNSRecord {
   skipReason?: string
 }
 
+export interface ProjectArchivePlanAccessApp {
+  id: string
+  hostname: string
+  name: string
+  rules: ProjectAccessRules
+  deleteEligible: boolean
+  skipReason?: string
+}
+
 export interface ProjectArchivePlan {
   project: ProjectArchivePlanProject
   defaults: ProjectArchiveOptions
@@ -150,5 +216,228 @@ export interface ProjectArchivePlan {
   serviceExposures: ProjectArchivePlanServiceExposure[]
   ingressRules: ProjectArchivePlanIngressRule[]
   dnsRecords: ProjectArchivePlanDNSRecord[]
+  accessApps: ProjectArchivePlanAccessApp[]
   warnings: string[]
 }
+
+export interface ProjectAccessRules {
+  emails: string[]
+  emailDomains: string[]
+  githubOrgs: string[]
+}
+
+export interface ProjectAccessApplication {
+  id: string
+  name: string
+  domain: string
+  aud: string
+  managed: boolean
+  policyId?: string
+  rules: ProjectAccessRules
+}
+
+export interface ProjectAccessHostname {
+  hostname: string
+  protected: boolean
+  application?: ProjectAccessApplication
+}
+
+export interface ProjectAccessToggle extends Partial<ProjectAccessRules> {
+  enabled: boolean
+}
+
+export type ProjectIngressScheme = 'http' | 'https' | 'tcp' | 'ssh'
+
+export interface ProjectIngressOrigin {
+  noTLSVerify?: boolean
+  http2Origin?: boolean
+  connectTimeout?: number
+  httpHostHeader?: string
+  access?: {
+    required: boolean
+    teamName: string
+    audTag: string[]
+  }
+}
+
+export interface ProjectIngressOptions {
+  scheme?: ProjectIngressScheme
+  origin?: ProjectIngressOrigin
+}
+
+export interface ProjectIngressUpdate extends ProjectIngressOptions {
+  hostname: string
+  path?: string
+}
+
+export interface ProjectRoute {
+  subdomain: string
+  domain: string
+  hostname: string
+  path: string
+  port: number
+}
+
+export interface ProjectRouteInput {
+  subdomain: string
+  domain?: string
+  path?: string
+  port?: number
+}
+
+export interface ProjectRoutesPlan {
+  project: string
+  routes: ProjectRoute[]
+  ingressRules: ProjectArchivePlanIngressRule[]
+  dnsRecords: ProjectArchivePlanDNSRecord[]
+  warnings: string[]
+}
+
+export interface ProjectRestoreRoute {
+  hostname: string
+  path?: string
+  port: number
+}
+
+export interface ProjectTunnelRule {
+  hostname: string
+  path?: string
+  port: number
+  scheme?: ProjectIngressScheme
+  origin?: ProjectIngressOrigin
+  zoneId?: string
+}
+
+export interface ProjectTunnelPlan {
+  project: string
+  from: string
+  to: string
+  rules: ProjectTunnelRule[]
+  warnings: string[]
+}
+
+export interface ProjectRestorePlan {
+  project: string
+  archivedAt: string
+  composeFiles: string[]
+  routes: ProjectRestoreRoute[]
+  dnsRecords: { hostname: string; zoneId: string }[]
+  access: { hostname: string; rules: ProjectAccessRules }[]
+  restoreEnv: boolean
+  volumeBackupId?: string
+  unrestorable: string[]
+}
+
+export interface ProjectVolumeBackupArchive {
+  volume: string
+  file: string
+  sizeBytes: number
+  sha256: string
+  labels?: Record<string, string>
+}
+
+export interface ProjectVolumeBackup {
+  project: string
+  backupId: string
+  createdAt: string
+  reason?: string
+  sizeBytes: number
+  volumes: ProjectVolumeBackupArchive[]
+}
+
+export type ProjectDatabaseEngine = 'postgres' | 'mysql'
+
+export interface ProjectDatabase {
+  service: string
+  engine: ProjectDatabaseEngine
+  image: string
+  container?: string
+}
+
+export interface ProjectDatabaseDumpArchive {
+  service: string
+  engine: ProjectDatabaseEngine
+  container: string
+  file: string
+  sizeBytes: number
+  sha256: string
+}
+
+export interface ProjectDatabaseDump {
+  project: string
+  dumpId: string
+  createdAt: string
+  sizeBytes: number
+  dumps: ProjectDatabaseDumpArchive[]
+}
+
+export interface ProjectBackupRetention {
+  project: number
+  default: number
+}
+
+export interface ProjectHostnamePlan {
+  project: string
+  hostname: string
+  subdomain: string
+  domain: string
+  port: number
+  previousHostnames: string[]
+  ingressRules: ProjectArchivePlanIngressRule[]
+  dnsRecords: ProjectArchivePlanDNSRecord[]
+  warnings: string[]
+}
+
+export interface ProjectClonePlan {
+  project: string
+  name: string
+  hostname: string
+  subdomain: string
+  domain: string
+  copyVolumes: boolean
+  volumes: string[]
+  warnings: string[]
+}
+
+export type ImageDigestStatusValue = 'up_to_date' | 'update_available' | 'not_pulled' | 'pinned' | 'unknown'
+
+export interface ImageDigestStatus {
+  image: string
+  status: ImageDigestStatusValue
+  localDigest?: string
+  localImageId?: string
+  remoteDigest?: string
+  error?: string
+}
+
+export interface ProjectImageUpdate extends ImageDigestStatus {
+  services: string[]
+}
+
+export interface ProjectImageUpdateReport {
+  project: string
+  revision: number
+  checkedAt: string
+  updatesAvailable: number
+  images: ProjectImageUpdate[]
+  servicesWithoutImage: string[]
+}
+
+export interface ProjectImageUpdateTarget {
+  image: string
+  services: string[]
+  previousImageId: string
+  remoteDigest?: string
+}
+
+export interface ProjectBlueGreenPlan {
+  projectName: string
+  revision: number
+  composeProject: string
+  proxyPort: number
+  proxyService: string
+  proxyContainerPort: number
+  proxyHostIp?: string
+  services: string[]
+  pinned: string[]
+}
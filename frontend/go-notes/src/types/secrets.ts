@@ -0,0 +1,16 @@
+export interface SecretMetadata {
+  name: string
+  version: number
+  keyId: string
+  updatedAt: string
+  updatedBy?: string
+}
+
+export interface SecretsStatus {
+  enabled: boolean
+  keyId?: string
+  previousKeyId?: string
+  staleVersions: number
+  panelSecrets: SecretMetadata[]
+  projectSecrets: Record<string, number>
+}
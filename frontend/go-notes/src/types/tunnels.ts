@@ -0,0 +1,22 @@
+export interface Tunnel {
+  name: string
+  default: boolean
+  accountId?: string
+  zoneId?: string
+  tunnel?: string
+  configPath?: string
+  metricsAddress: string
+  tokenSet: boolean
+  projects: string[]
+}
+
+export interface TunnelRequest {
+  name?: string
+  accountId?: string
+  zoneId?: string
+  tunnel: string
+  configPath: string
+  metricsAddress: string
+  token?: string
+  clearToken?: boolean
+}
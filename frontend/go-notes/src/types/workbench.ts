@@ -1,4 +1,6 @@
-export type WorkbenchImportReason = 'manual' | 'auto_deploy' | 'auto_redeploy'
+import type { Job } from '@/types/jobs'
+
+export type WorkbenchImportReason = 'manual' | 'auto_deploy' | 'auto_redeploy' | 'bundle'
 
 export interface WorkbenchStackService {
   serviceName: string
@@ -31,6 +33,13 @@ export interface WorkbenchStackResource {
   reservationMemory?: string
 }
 
+export interface WorkbenchStackNetwork {
+  name: string
+  driver?: string
+  internal?: boolean
+  external?: boolean
+}
+
 export interface WorkbenchStackNetworkRef {
   serviceName: string
   networkName: string
@@ -56,6 +65,7 @@ export interface WorkbenchStackModule {
 export interface WorkbenchManagedService {
   entryKey: string
   serviceName: string
+  appService?: string
 }
 
 export interface WorkbenchStackWarning {
@@ -75,12 +85,14 @@ export interface WorkbenchStackSnapshot {
   dependencies: WorkbenchStackDependency[]
   ports: WorkbenchStackPort[]
   resources: WorkbenchStackResource[]
+  networks: WorkbenchStackNetwork[]
   networkRefs: WorkbenchStackNetworkRef[]
   volumeRefs: WorkbenchStackVolumeRef[]
   envRefs: WorkbenchStackEnvRef[]
   managedServices: WorkbenchManagedService[]
   modules: WorkbenchStackModule[]
   warnings: WorkbenchStackWarning[]
+  policyOverrides?: WorkbenchPolicyOverride[]
 }
 
 export interface WorkbenchSnapshotResponse {
@@ -120,6 +132,31 @@ export interface WorkbenchDependencyGraphResponse {
   graph: WorkbenchDependencyGraph
 }
 
+export type WorkbenchServiceRestartMode = 'restart' | 'redeploy'
+
+export interface WorkbenchRestartPlan {
+  projectName: string
+  revision: number
+  serviceName: string
+  tiers: string[][]
+  services: string[]
+  oneShot?: string[]
+}
+
+export interface WorkbenchRestartPlanResponse {
+  plan: WorkbenchRestartPlan
+}
+
+export interface WorkbenchServiceRestartRequest {
+  mode?: WorkbenchServiceRestartMode
+  healthTimeoutSeconds?: number
+}
+
+export interface WorkbenchServiceRestartResponse {
+  job: Job
+  plan: WorkbenchRestartPlan
+}
+
 export interface WorkbenchOptionalServiceComposeMatch {
   serviceName: string
   image?: string
@@ -144,6 +181,11 @@ export interface WorkbenchOptionalServiceTransition {
   notes: string[]
 }
 
+export interface WorkbenchOptionalServiceConnectionOutput {
+  key: string
+  value: string
+}
+
 export interface WorkbenchOptionalServiceCatalogEntry {
   key: string
   displayName: string
@@ -152,6 +194,9 @@ export interface WorkbenchOptionalServiceCatalogEntry {
   defaultServiceName: string
   suggestedImage: string
   defaultContainerPort: number
+  source: 'builtin' | 'file'
+  dependsOn: string[]
+  connectionOutputs: WorkbenchOptionalServiceConnectionOutput[]
   availability: WorkbenchOptionalServiceAvailability
   transition: WorkbenchOptionalServiceTransition
 }
@@ -181,6 +226,7 @@ export type WorkbenchOptionalServiceMutationAction = 'add' | 'remove'
 
 export interface WorkbenchOptionalServiceAddRequest {
   entryKey: string
+  appService?: string
 }
 
 export interface WorkbenchOptionalServiceMutationSummary {
@@ -188,9 +234,13 @@ export interface WorkbenchOptionalServiceMutationSummary {
   action: WorkbenchOptionalServiceMutationAction
   entryKey?: string
   serviceName?: string
+  appService?: string
   previousCount: number
   currentCount: number
   composeGenerationReady: boolean
+  connectionOutputs?: WorkbenchOptionalServiceConnectionOutput[]
+  envKeysAdded?: string[]
+  envKeysExisting?: string[]
   notes: string[]
 }
 
@@ -219,6 +269,7 @@ export interface WorkbenchPortSelector {
 export interface WorkbenchMutationIssue {
   class: string
   code: string
+  severity?: WorkbenchPolicySeverity
   path: string
   message: string
   service?: string
@@ -263,6 +314,55 @@ export interface WorkbenchPortResolveResponse {
   resolve: WorkbenchPortResolutionSummary
 }
 
+export interface WorkbenchBundlePortAssignment {
+  serviceName: string
+  containerPort: number
+  protocol: string
+  hostIp?: string
+  hostPort?: number
+  assignmentStrategy?: string
+}
+
+export interface WorkbenchBundle {
+  format: string
+  version: number
+  exportedAt: string
+  sourceProject: string
+  composeFile: string
+  compose: string
+  snapshot: WorkbenchStackSnapshot
+  envTemplate?: string
+  redactedEnvKeys: string[]
+  optionalServices: WorkbenchManagedService[]
+  portAssignments: WorkbenchBundlePortAssignment[]
+}
+
+export interface WorkbenchBundleExportResponse {
+  bundle: WorkbenchBundle
+}
+
+export interface WorkbenchBundleImportRequest {
+  bundle: WorkbenchBundle
+  keepManualPorts?: boolean
+}
+
+export interface WorkbenchBundleImportResult {
+  project: string
+  projectDir: string
+  sourceProject: string
+  stack: WorkbenchStackSnapshot
+  portResolution: WorkbenchPortResolutionSummary
+  portIssues?: WorkbenchPortResolutionIssue[]
+  applied: boolean
+  applyErrorCode?: string
+  envKeysToFill: string[]
+  notes?: string[]
+}
+
+export interface WorkbenchBundleImportResponse {
+  import: WorkbenchBundleImportResult
+}
+
 export type WorkbenchPortMutationAction = 'set_manual' | 'clear_manual'
 
 export interface WorkbenchPortMutationRequest {
@@ -353,6 +453,42 @@ export interface WorkbenchResourceMutationResponse {
   mutation: WorkbenchResourceMutationSummary
 }
 
+export type WorkbenchNetworkDriver = 'bridge' | 'overlay' | 'macvlan' | 'ipvlan'
+
+export interface WorkbenchNetworkCreateRequest {
+  name: string
+  driver?: WorkbenchNetworkDriver
+  internal?: boolean
+}
+
+export interface WorkbenchNetworkUpdateRequest {
+  driver?: WorkbenchNetworkDriver | ''
+  internal?: boolean
+}
+
+export interface WorkbenchServiceNetworksRequest {
+  attach?: string[]
+  detach?: string[]
+}
+
+export interface WorkbenchNetworkMutationSummary {
+  changed: boolean
+  action: 'create' | 'update' | 'delete' | 'attach'
+  network?: string
+  serviceName?: string
+  before?: WorkbenchStackNetwork
+  after?: WorkbenchStackNetwork
+  attached?: string[]
+  detached?: string[]
+  networks?: string[]
+  notes?: string[]
+}
+
+export interface WorkbenchNetworkMutationResponse {
+  stack: WorkbenchStackSnapshot
+  mutation: WorkbenchNetworkMutationSummary
+}
+
 export type WorkbenchModuleMutationAction = 'add' | 'remove'
 
 export interface WorkbenchModuleSelector {
@@ -387,9 +523,45 @@ export interface WorkbenchComposePreviewMetadata {
   sourceFingerprint: string
 }
 
+export type WorkbenchPolicySeverity = 'error' | 'warning' | 'info' | 'off'
+
+export interface WorkbenchPolicyOverride {
+  rule: string
+  severity: WorkbenchPolicySeverity
+}
+
+export interface WorkbenchPolicyRuleStatus {
+  rule: string
+  title: string
+  defaultSeverity: WorkbenchPolicySeverity
+  severity: WorkbenchPolicySeverity
+  overridden: boolean
+}
+
+export interface WorkbenchPolicyReport {
+  rules: WorkbenchPolicyRuleStatus[]
+  issues: WorkbenchMutationIssue[]
+  errorCount: number
+  warningCount: number
+  infoCount: number
+  blocking: boolean
+}
+
+export interface WorkbenchPolicyResponse {
+  revision: number
+  policy: WorkbenchPolicyReport
+}
+
+export interface WorkbenchPolicyUpdateRequest {
+  expectedRevision?: number
+  overrides: Record<string, WorkbenchPolicySeverity>
+}
+
 export interface WorkbenchComposePreviewResult {
   compose: string
   metadata: WorkbenchComposePreviewMetadata
+  policy: WorkbenchPolicyReport
+  merge?: WorkbenchMergeReport
 }
 
 export interface WorkbenchComposePreviewResponse {
@@ -426,6 +598,7 @@ export interface WorkbenchComposeApplyResult {
   composeBytes: number
   backup: WorkbenchComposeBackupMetadata
   retention: WorkbenchComposeBackupRetentionInfo
+  merge?: WorkbenchMergeReport
 }
 
 export interface WorkbenchComposeApplyResponse {
@@ -467,3 +640,85 @@ export function buildWorkbenchPortSelectorKey(selector: WorkbenchPortSelector):
 export function buildWorkbenchModuleSelectorKey(selector: WorkbenchModuleSelector): string {
   return `${selector.serviceName.trim()}::${selector.moduleType.trim().toLowerCase()}`
 }
+
+export interface WorkbenchRevisionDiffEntry {
+  section: string
+  op: 'added' | 'removed' | 'changed'
+  key: string
+  before?: unknown
+  after?: unknown
+}
+
+export interface WorkbenchRevisionSummary {
+  revision: number
+  userId?: number
+  userLogin: string
+  mutationType: string
+  note?: string
+  createdAt: string
+  diff: WorkbenchRevisionDiffEntry[]
+}
+
+export interface WorkbenchRevisionDetail extends WorkbenchRevisionSummary {
+  snapshot: WorkbenchStackSnapshot
+}
+
+export interface WorkbenchRevisionsResponse {
+  revisions: WorkbenchRevisionSummary[]
+}
+
+export interface WorkbenchRevisionResponse {
+  revision: WorkbenchRevisionDetail
+}
+
+export interface WorkbenchRevertRequest {
+  expectedRevision?: number
+}
+
+export interface WorkbenchMergeConflict {
+  section: string
+  key: string
+  service?: string
+  field?: string
+  kind: 'both_modified' | 'both_added' | 'deleted_on_disk' | 'deleted_in_snapshot'
+  base?: unknown
+  disk?: unknown
+  snapshot?: unknown
+}
+
+export interface WorkbenchMergeReport {
+  baseFingerprint: string
+  diskFingerprint: string
+  baseAvailable: boolean
+  merged: WorkbenchRevisionDiffEntry[]
+  conflicts: WorkbenchMergeConflict[]
+}
+
+export interface WorkbenchDriftStatus {
+  project: string
+  revision: number
+  sourceFingerprint: string
+  currentFingerprint?: string
+  drifted: boolean
+  baseAvailable: boolean
+  mergeable: boolean
+  mergedCount: number
+  conflictCount: number
+  error?: string
+  checkedAt: string
+}
+
+export interface WorkbenchDriftResponse {
+  drift: WorkbenchDriftStatus
+  merge: WorkbenchMergeReport | null
+}
+
+export interface WorkbenchDriftScanReport {
+  scannedAt: string
+  driftedCount: number
+  projects: WorkbenchDriftStatus[]
+}
+
+export interface WorkbenchDriftScanResponse {
+  scan: WorkbenchDriftScanReport
+}